@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+func (i *Interpreter) defineArgparseFuncs() {
+	className := "argparse"
+	argparseClass := NewLoxClass(className, nil, false)
+	argparseFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native argparse fn %v at %p>", name, &s)
+		}
+		argparseClass.classProperties[name] = s
+	}
+
+	argparseFunc("new", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		//The program name defaults to the name of the script being run
+		prog := filepath.Base(os.Args[0])
+		if scriptArgs := scriptArgs(); len(scriptArgs) > 0 {
+			prog = filepath.Base(scriptArgs[0])
+		}
+		parser := NewLoxArgParser(prog)
+		switch len(args) {
+		case 0:
+		case 1:
+			options, ok := args[0].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'argparse.new' must be a dictionary.")
+			}
+			if err := parser.setOptions(options, "argparse.new", nil); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+		}
+		return parser, nil
+	})
+
+	i.globals.Define(className, argparseClass)
+}
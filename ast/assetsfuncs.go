@@ -0,0 +1,152 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var (
+	embeddedAssets fs.FS
+	projectAssets  map[string]string
+)
+
+// SetEmbeddedAssets sets the file system of assets that are embedded
+// inside the interpreter executable.
+func SetEmbeddedAssets(fsys fs.FS) {
+	embeddedAssets = fsys
+}
+
+// SetProjectAssets sets the assets declared in a project manifest, which
+// is a map of asset names to file paths on disk.
+func SetProjectAssets(assets map[string]string) {
+	projectAssets = assets
+}
+
+func readAsset(name string) ([]byte, error) {
+	name = path.Clean(name)
+	if filePath, ok := projectAssets[name]; ok {
+		return os.ReadFile(filePath)
+	}
+	if embeddedAssets != nil {
+		bytes, err := fs.ReadFile(embeddedAssets, name)
+		if err == nil {
+			return bytes, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("Asset '%v' does not exist.", name)
+}
+
+func assetExists(name string) bool {
+	name = path.Clean(name)
+	if _, ok := projectAssets[name]; ok {
+		return true
+	}
+	if embeddedAssets != nil {
+		info, err := fs.Stat(embeddedAssets, name)
+		return err == nil && !info.IsDir()
+	}
+	return false
+}
+
+func listAssets() ([]string, error) {
+	names := make(map[string]struct{})
+	for name := range projectAssets {
+		names[name] = struct{}{}
+	}
+	if embeddedAssets != nil {
+		walkErr := fs.WalkDir(embeddedAssets, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				names[name] = struct{}{}
+			}
+			return nil
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (i *Interpreter) defineAssetsFuncs() {
+	className := "assets"
+	assetsClass := NewLoxClass(className, nil, false)
+	assetsFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native assets fn %v at %p>", name, &s)
+		}
+		assetsClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'assets.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	assetsFunc("exists", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			return assetExists(loxStr.str), nil
+		}
+		return argMustBeType(in.callToken, "exists", "string")
+	})
+	assetsFunc("list", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		names, err := listAssets()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		namesList := list.NewListCap[any](int64(len(names)))
+		for _, name := range names {
+			namesList.Add(NewLoxStringQuote(name))
+		}
+		return NewLoxList(namesList), nil
+	})
+	assetsFunc("read", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			bytes, err := readAsset(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(string(bytes)), nil
+		}
+		return argMustBeType(in.callToken, "read", "string")
+	})
+	assetsFunc("readBin", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			bytes, err := readAsset(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			buffer := EmptyLoxBufferCap(int64(len(bytes)))
+			for _, element := range bytes {
+				bufErr := buffer.add(int64(element))
+				if bufErr != nil {
+					return nil, loxerror.RuntimeError(in.callToken, bufErr.Error())
+				}
+			}
+			return buffer, nil
+		}
+		return argMustBeType(in.callToken, "readBin", "string")
+	})
+
+	i.globals.Define(className, assetsClass)
+}
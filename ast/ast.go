@@ -8,14 +8,25 @@ import (
 type Expr interface{}
 type Stmt interface{}
 
+// Where a local variable lives at runtime, as computed by the resolver.
+// Depth is the number of environments between the variable's use and its
+// declaration, and Slot is the variable's index in that environment.
+// Variables that are not local are looked up in the global environment
+type Resolution struct {
+	Depth   int
+	Slot    int
+	IsLocal bool
+}
+
 type Assert struct {
 	Value       Expr
 	AssertToken *token.Token
 }
 
 type Assign struct {
-	Name  *token.Token
-	Value Expr
+	Name       *token.Token
+	Value      Expr
+	Resolution *Resolution
 }
 
 type BigNum struct {
@@ -27,9 +38,14 @@ type Binary struct {
 	Left     Expr
 	Operator *token.Token
 	Right    Expr
+	Compound bool //True if this was desugared from "a op= b"
 }
 
-type Break struct{}
+type BlankLine struct{}
+
+type Break struct {
+	Keyword *token.Token
+}
 
 type Block struct {
 	Statements list.List[Stmt]
@@ -41,6 +57,17 @@ type Call struct {
 	Arguments list.List[Expr]
 }
 
+type CatchClause struct {
+	Name       *token.Token
+	ErrorClass *Variable
+	Block      Stmt
+}
+
+type Comment struct {
+	Text     string
+	Trailing bool
+}
+
 type Class struct {
 	Name           *token.Token
 	SuperClass     *Variable
@@ -51,7 +78,9 @@ type Class struct {
 	CanInstantiate bool
 }
 
-type Continue struct{}
+type Continue struct {
+	Keyword *token.Token
+}
 
 type Dict struct {
 	Entries   list.List[Expr]
@@ -95,13 +124,16 @@ type Function struct {
 
 type FunctionExpr struct {
 	Params    list.List[*token.Token]
+	Defaults  list.List[Expr] //Default value of each parameter, or nil if the parameter has no default value
 	Body      list.List[Stmt]
 	VarArgPos int
+	Start     *token.Token //Name of the function, or the "fun" keyword if it has no name
 }
 
 type Get struct {
-	Object Expr
-	Name   *token.Token
+	Object   Expr
+	Name     *token.Token
+	Optional bool
 }
 
 type Grouping struct {
@@ -126,6 +158,7 @@ type Index struct {
 	Index        Expr
 	IndexEnd     Expr
 	IsSlice      bool
+	Optional     bool
 }
 
 type List struct {
@@ -133,7 +166,8 @@ type List struct {
 }
 
 type Literal struct {
-	Value any
+	Value  any
+	Lexeme string //Source text of number literals
 }
 
 type Logical struct {
@@ -147,6 +181,11 @@ type Loop struct {
 	LoopToken *token.Token
 }
 
+type NamedArgument struct {
+	Name  *token.Token
+	Value Expr
+}
+
 type Print struct {
 	Expression Expr
 	NewLine    bool
@@ -185,8 +224,9 @@ type String struct {
 }
 
 type Super struct {
-	Keyword *token.Token
-	Method  *token.Token
+	Keyword    *token.Token
+	Method     *token.Token
+	Resolution *Resolution
 }
 
 type Ternary struct {
@@ -196,7 +236,8 @@ type Ternary struct {
 }
 
 type This struct {
-	Keyword *token.Token
+	Keyword    *token.Token
+	Resolution *Resolution
 }
 
 type Throw struct {
@@ -206,8 +247,7 @@ type Throw struct {
 
 type TryCatchFinally struct {
 	TryBlock     Stmt
-	CatchName    *token.Token
-	CatchBlock   Stmt
+	CatchClauses list.List[CatchClause]
 	FinallyBlock Stmt
 }
 
@@ -222,7 +262,8 @@ type Var struct {
 }
 
 type Variable struct {
-	Name *token.Token
+	Name       *token.Token
+	Resolution *Resolution
 }
 
 type While struct {
@@ -0,0 +1,181 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const (
+	base58BitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base58FlickrAlphabet  = "123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+func base58Encode(src []byte, alphabet string) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	//log(256) / log(58) is approximately 1.37
+	digits := make([]byte, (len(src)-zeros)*138/100+1)
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		for j := len(digits) - 1; j >= 0; j-- {
+			carry += 256 * int(digits[j])
+			digits[j] = byte(carry % 58)
+			carry /= 58
+		}
+	}
+	start := 0
+	for start < len(digits) && digits[start] == 0 {
+		start++
+	}
+	var builder strings.Builder
+	builder.Grow(zeros + len(digits) - start)
+	for range zeros {
+		builder.WriteByte(alphabet[0])
+	}
+	for _, digit := range digits[start:] {
+		builder.WriteByte(alphabet[digit])
+	}
+	return builder.String()
+}
+
+func base58Decode(src string, alphabet string) ([]byte, error) {
+	var indexes [256]int
+	for index := range indexes {
+		indexes[index] = -1
+	}
+	for index := 0; index < len(alphabet); index++ {
+		indexes[alphabet[index]] = index
+	}
+	zeros := 0
+	for zeros < len(src) && src[zeros] == alphabet[0] {
+		zeros++
+	}
+	//log(58) / log(256) is approximately 0.733
+	bytes := make([]byte, (len(src)-zeros)*733/1000+1)
+	for index := zeros; index < len(src); index++ {
+		carry := indexes[src[index]]
+		if carry < 0 {
+			return nil, loxerror.Error(
+				fmt.Sprintf("illegal base58 data at input byte %v", index))
+		}
+		for j := len(bytes) - 1; j >= 0; j-- {
+			carry += 58 * int(bytes[j])
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+	}
+	start := 0
+	for start < len(bytes) && bytes[start] == 0 {
+		start++
+	}
+	result := make([]byte, zeros, zeros+len(bytes)-start)
+	return append(result, bytes[start:]...), nil
+}
+
+// Reports whether the specified string can be used as a base58 alphabet,
+// which must consist of 58 distinct ASCII characters
+func base58ValidAlphabet(alphabet string) bool {
+	if len(alphabet) != 58 {
+		return false
+	}
+	var seen [128]bool
+	for index := 0; index < len(alphabet); index++ {
+		c := alphabet[index]
+		if c >= 128 || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+func (i *Interpreter) defineBase58Funcs() {
+	className := "base58"
+	base58Class := NewLoxClass(className, nil, false)
+	base58Func := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native base58 fn %v at %p>", name, &s)
+		}
+		base58Class.classProperties[name] = s
+	}
+	//Returns the alphabet from the optional second argument, which is the
+	//Bitcoin alphabet by default
+	alphabetArg := func(callToken *token.Token, name string, args list.List[any]) (string, error) {
+		argsLen := len(args)
+		switch argsLen {
+		case 1:
+			return base58BitcoinAlphabet, nil
+		case 2:
+			loxStr, ok := args[1].(*LoxString)
+			if !ok {
+				return "", loxerror.RuntimeError(callToken,
+					fmt.Sprintf("Second argument to 'base58.%v' must be a string.", name))
+			}
+			if !base58ValidAlphabet(loxStr.str) {
+				return "", loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					"Base58 alphabet must consist of 58 distinct ASCII characters.")
+			}
+			return loxStr.str, nil
+		}
+		return "", loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+	}
+	decode := func(callToken *token.Token, name string, args list.List[any]) ([]byte, error) {
+		alphabet, err := alphabetArg(callToken, name, args)
+		if err != nil {
+			return nil, err
+		}
+		loxStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First argument to 'base58.%v' must be a string.", name))
+		}
+		result, decodeErr := base58Decode(loxStr.str, alphabet)
+		if decodeErr != nil {
+			return nil, loxerror.RuntimeError(callToken, decodeErr.Error())
+		}
+		return result, nil
+	}
+
+	base58Class.classProperties["BITCOIN"] = NewLoxStringQuote(base58BitcoinAlphabet)
+	base58Class.classProperties["FLICKR"] = NewLoxStringQuote(base58FlickrAlphabet)
+	base58Func("decode", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		result, err := decode(in.callToken, "decode", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(string(result)), nil
+	})
+	base58Func("decodeToBuf", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		result, err := decode(in.callToken, "decodeToBuf", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxBufferFromBytes(result), nil
+	})
+	base58Func("encode", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		alphabet, err := alphabetArg(in.callToken, "encode", args)
+		if err != nil {
+			return nil, err
+		}
+		switch arg := args[0].(type) {
+		case *LoxString:
+			return NewLoxString(base58Encode([]byte(arg.str), alphabet), '\''), nil
+		case *LoxBuffer:
+			return NewLoxString(base58Encode(arg.bytes(), alphabet), '\''), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			"First argument to 'base58.encode' must be a string or buffer.")
+	})
+
+	i.globals.Define(className, base58Class)
+}
@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"encoding/ascii85"
+	"fmt"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func base85Decode(src string) ([]byte, error) {
+	//Strip the optional Adobe delimiters
+	src = strings.TrimSpace(src)
+	if trimmed, ok := strings.CutPrefix(src, "<~"); ok {
+		src, ok = strings.CutSuffix(trimmed, "~>")
+		if !ok {
+			return nil, loxerror.Error("Base85 data starting with '<~' must end with '~>'.")
+		}
+	}
+	//Each group of 5 characters decodes to at most 4 bytes, and each 'z'
+	//character decodes to 4 bytes
+	dst := make([]byte, 4*len(src))
+	numBytes, _, err := ascii85.Decode(dst, []byte(src), true)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:numBytes], nil
+}
+
+func base85Encode(src []byte) string {
+	dst := make([]byte, ascii85.MaxEncodedLen(len(src)))
+	return string(dst[:ascii85.Encode(dst, src)])
+}
+
+func (i *Interpreter) defineBase85Funcs() {
+	className := "base85"
+	base85Class := NewLoxClass(className, nil, false)
+	base85Func := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native base85 fn %v at %p>", name, &s)
+		}
+		base85Class.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'base85.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	base85Func("decode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			result, decodeErr := base85Decode(loxStr.str)
+			if decodeErr != nil {
+				return nil, loxerror.RuntimeError(in.callToken, decodeErr.Error())
+			}
+			return NewLoxStringQuote(string(result)), nil
+		}
+		return argMustBeType(in.callToken, "decode", "string")
+	})
+	base85Func("decodeToBuf", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			result, decodeErr := base85Decode(loxStr.str)
+			if decodeErr != nil {
+				return nil, loxerror.RuntimeError(in.callToken, decodeErr.Error())
+			}
+			return NewLoxBufferFromBytes(result), nil
+		}
+		return argMustBeType(in.callToken, "decodeToBuf", "string")
+	})
+	base85Func("encode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch arg := args[0].(type) {
+		case *LoxString:
+			return NewLoxStringQuote(base85Encode([]byte(arg.str))), nil
+		case *LoxBuffer:
+			return NewLoxStringQuote(base85Encode(arg.bytes())), nil
+		}
+		return argMustBeType(in.callToken, "encode", "string or buffer")
+	})
+
+	i.globals.Define(className, base85Class)
+}
@@ -0,0 +1,209 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const (
+	bech32Charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32MaxLength = 90
+	//Constants that the checksums of bech32 and bech32m strings are XORed
+	//with, as defined in BIP 173 and BIP 350
+	bech32Const  = 1
+	bech32MConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generators := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, value := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(value)
+		for index, generator := range generators {
+			if (top>>index)&1 == 1 {
+				chk ^= generator
+			}
+		}
+	}
+	return chk
+}
+
+// Returns the human-readable part followed by the 5-bit data values that
+// the checksum is computed over
+func bech32ChecksumValues(hrp string, data []byte) []byte {
+	values := make([]byte, 0, len(hrp)*2+1+len(data)+6)
+	for index := 0; index < len(hrp); index++ {
+		values = append(values, hrp[index]>>5)
+	}
+	values = append(values, 0)
+	for index := 0; index < len(hrp); index++ {
+		values = append(values, hrp[index]&31)
+	}
+	return append(values, data...)
+}
+
+// Regroups the specified values from groups of fromBits bits to groups of
+// toBits bits. If pad is false, leftover bits must be zero padding that is
+// shorter than fromBits
+func bech32ConvertBits(data []byte, fromBits uint, toBits uint, pad bool) ([]byte, error) {
+	result := make([]byte, 0, (len(data)*int(fromBits)+int(toBits)-1)/int(toBits))
+	acc, numBits := uint(0), uint(0)
+	maxValue := uint(1)<<toBits - 1
+	for _, value := range data {
+		acc = acc<<fromBits | uint(value)
+		numBits += fromBits
+		for numBits >= toBits {
+			numBits -= toBits
+			result = append(result, byte(acc>>numBits&maxValue))
+		}
+	}
+	if pad {
+		if numBits > 0 {
+			result = append(result, byte(acc<<(toBits-numBits)&maxValue))
+		}
+	} else if numBits >= fromBits || acc<<(toBits-numBits)&maxValue != 0 {
+		return nil, loxerror.Error("Invalid padding in bech32 data.")
+	}
+	return result, nil
+}
+
+func bech32Encode(hrp string, data []byte, checksumConst uint32) (string, error) {
+	if len(hrp) < 1 {
+		return "", loxerror.Error("Bech32 human-readable part cannot be empty.")
+	}
+	for index := 0; index < len(hrp); index++ {
+		if hrp[index] < 33 || hrp[index] > 126 {
+			return "", loxerror.Error(
+				"Bech32 human-readable part must only contain ASCII characters between 33 and 126.")
+		}
+	}
+	hrp = strings.ToLower(hrp)
+	values, _ := bech32ConvertBits(data, 8, 5, true)
+	if len(hrp)+1+len(values)+6 > bech32MaxLength {
+		return "", loxerror.Error(
+			fmt.Sprintf("Bech32 string cannot be longer than %v characters.", bech32MaxLength))
+	}
+	polymod := bech32Polymod(append(bech32ChecksumValues(hrp, values), 0, 0, 0, 0, 0, 0)) ^ checksumConst
+	var builder strings.Builder
+	builder.Grow(len(hrp) + 1 + len(values) + 6)
+	builder.WriteString(hrp)
+	builder.WriteByte('1')
+	for _, value := range values {
+		builder.WriteByte(bech32Charset[value])
+	}
+	for index := 0; index < 6; index++ {
+		builder.WriteByte(bech32Charset[(polymod>>(5*(5-index)))&31])
+	}
+	return builder.String(), nil
+}
+
+func bech32Decode(str string, checksumConst uint32) (string, []byte, error) {
+	if len(str) > bech32MaxLength {
+		return "", nil, loxerror.Error(
+			fmt.Sprintf("Bech32 string cannot be longer than %v characters.", bech32MaxLength))
+	}
+	lower, upper := strings.ToLower(str), strings.ToUpper(str)
+	if str != lower && str != upper {
+		return "", nil, loxerror.Error("Bech32 string cannot contain both uppercase and lowercase characters.")
+	}
+	str = lower
+	separator := strings.LastIndexByte(str, '1')
+	if separator < 1 || separator+7 > len(str) {
+		return "", nil, loxerror.Error("Invalid position of separator '1' in bech32 string.")
+	}
+	hrp := str[:separator]
+	for index := 0; index < len(hrp); index++ {
+		if hrp[index] < 33 || hrp[index] > 126 {
+			return "", nil, loxerror.Error(
+				"Bech32 human-readable part must only contain ASCII characters between 33 and 126.")
+		}
+	}
+	values := make([]byte, 0, len(str)-separator-1)
+	for index := separator + 1; index < len(str); index++ {
+		value := strings.IndexByte(bech32Charset, str[index])
+		if value < 0 {
+			return "", nil, loxerror.Error(
+				fmt.Sprintf("Invalid bech32 character '%c' at index %v.", str[index], index))
+		}
+		values = append(values, byte(value))
+	}
+	if bech32Polymod(bech32ChecksumValues(hrp, values)) != checksumConst {
+		return "", nil, loxerror.Error("Invalid bech32 checksum.")
+	}
+	data, err := bech32ConvertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+func (i *Interpreter) defineBech32Funcs() {
+	className := "bech32"
+	bech32Class := NewLoxClass(className, nil, false)
+	bech32Func := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native bech32 fn %v at %p>", name, &s)
+		}
+		bech32Class.classProperties[name] = s
+	}
+	decode := func(callToken *token.Token, name string, arg any, checksumConst uint32) (any, error) {
+		loxStr, ok := arg.(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Argument to 'bech32.%v' must be a string.", name))
+		}
+		hrp, data, err := bech32Decode(loxStr.str, checksumConst)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken, err.Error())
+		}
+		result := list.NewListCap[any](2)
+		result.Add(NewLoxStringQuote(hrp))
+		result.Add(NewLoxBufferFromBytes(data))
+		return NewLoxList(result), nil
+	}
+	encode := func(callToken *token.Token, name string, args list.List[any], checksumConst uint32) (any, error) {
+		hrp, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First argument to 'bech32.%v' must be a string.", name))
+		}
+		var data []byte
+		switch arg := args[1].(type) {
+		case *LoxString:
+			data = []byte(arg.str)
+		case *LoxBuffer:
+			data = arg.bytes()
+		default:
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Second argument to 'bech32.%v' must be a string or buffer.", name))
+		}
+		result, err := bech32Encode(hrp.str, data, checksumConst)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken, err.Error())
+		}
+		return NewLoxString(result, '\''), nil
+	}
+
+	bech32Func("decode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		return decode(in.callToken, "decode", args[0], bech32Const)
+	})
+	bech32Func("decodeM", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		return decode(in.callToken, "decodeM", args[0], bech32MConst)
+	})
+	bech32Func("encode", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		return encode(in.callToken, "encode", args, bech32Const)
+	})
+	bech32Func("encodeM", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		return encode(in.callToken, "encodeM", args, bech32MConst)
+	})
+
+	i.globals.Define(className, bech32Class)
+}
@@ -0,0 +1,138 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineBitsetFuncs() {
+	className := "bitset"
+	bitsetClass := NewLoxClass(className, nil, false)
+	bitsetFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native bitset fn %v at %p>", name, &s)
+		}
+		bitsetClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'bitset.%v' must be %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	newBitset := func(callToken *token.Token, name string, size int64) (*LoxBitset, error) {
+		if size < 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Bitset size in 'bitset.%v' cannot be negative.", name))
+		}
+		if limitErr := checkSizeLimit(callToken, (size+7)/8); limitErr != nil {
+			return nil, limitErr
+		}
+		return NewLoxBitset(size), nil
+	}
+
+	bitsetFunc("fromBuffer", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		buffer, ok := args[0].(*LoxBuffer)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'bitset.fromBuffer' must be a buffer.")
+		}
+		size := int64(len(buffer.elements)) * 8
+		if argsLen == 2 {
+			sizeArg, ok := args[1].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'bitset.fromBuffer' must be an integer.")
+			}
+			if sizeArg < 0 || sizeArg > size {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("Second argument to 'bitset.fromBuffer' must be between 0 and %v.", size))
+			}
+			size = sizeArg
+		}
+		return NewLoxBitsetFromBytes(buffer.bytes(), size), nil
+	})
+	bitsetFunc("fromIndexes", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		size, ok := args[0].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'bitset.fromIndexes' must be an integer.")
+		}
+		iterable, ok := args[1].(interfaces.Iterable)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'bitset.fromIndexes' must be an iterable.")
+		}
+		bitset, err := newBitset(in.callToken, "fromIndexes", size)
+		if err != nil {
+			return nil, err
+		}
+		it := iterable.Iterator()
+		for it.HasNext() {
+			index, ok := it.Next().(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Indexes in 'bitset.fromIndexes' must be integers.")
+			}
+			if index < 0 || index >= size {
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, in.callToken,
+					fmt.Sprintf("Bitset index %v out of range.", index))
+			}
+			bitset.set(index)
+		}
+		return bitset, nil
+	})
+	bitsetFunc("fromList", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxList, ok := args[0].(*LoxList); ok {
+			bitset, err := newBitset(in.callToken, "fromList", int64(len(loxList.elements)))
+			if err != nil {
+				return nil, err
+			}
+			for index, element := range loxList.elements {
+				if in.isTruthy(element) {
+					bitset.set(int64(index))
+				}
+			}
+			return bitset, nil
+		}
+		return argMustBeType(in.callToken, "fromList", "a list")
+	})
+	bitsetFunc("fromString", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			bitset, err := newBitset(in.callToken, "fromString", int64(len(loxStr.str)))
+			if err != nil {
+				return nil, err
+			}
+			for index, c := range []byte(loxStr.str) {
+				switch c {
+				case '0':
+				case '1':
+					bitset.set(int64(index))
+				default:
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+						"String in 'bitset.fromString' must only contain the characters '0' and '1'.")
+				}
+			}
+			return bitset, nil
+		}
+		return argMustBeType(in.callToken, "fromString", "a string")
+	})
+	bitsetFunc("new", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if size, ok := args[0].(int64); ok {
+			return newBitset(in.callToken, "new", size)
+		}
+		return argMustBeType(in.callToken, "new", "an integer")
+	})
+
+	i.globals.Define(className, bitsetClass)
+}
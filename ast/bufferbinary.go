@@ -0,0 +1,160 @@
+package ast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A numeric type that can be read from or written to a buffer by the
+// buffer.readX and buffer.writeX methods
+type bufferNumType struct {
+	size  int
+	kind  byte //'i' for signed integers, 'u' for unsigned integers, 'f' for floats
+	order binary.ByteOrder
+}
+
+// Maps the suffixes of the buffer.readX and buffer.writeX method names to
+// their numeric types
+var bufferNumTypes = map[string]bufferNumType{
+	"Int8":      {1, 'i', binary.LittleEndian},
+	"Uint8":     {1, 'u', binary.LittleEndian},
+	"Int16LE":   {2, 'i', binary.LittleEndian},
+	"Int16BE":   {2, 'i', binary.BigEndian},
+	"Uint16LE":  {2, 'u', binary.LittleEndian},
+	"Uint16BE":  {2, 'u', binary.BigEndian},
+	"Int32LE":   {4, 'i', binary.LittleEndian},
+	"Int32BE":   {4, 'i', binary.BigEndian},
+	"Uint32LE":  {4, 'u', binary.LittleEndian},
+	"Uint32BE":  {4, 'u', binary.BigEndian},
+	"Int64LE":   {8, 'i', binary.LittleEndian},
+	"Int64BE":   {8, 'i', binary.BigEndian},
+	"Uint64LE":  {8, 'u', binary.LittleEndian},
+	"Uint64BE":  {8, 'u', binary.BigEndian},
+	"Float32LE": {4, 'f', binary.LittleEndian},
+	"Float32BE": {4, 'f', binary.BigEndian},
+	"Float64LE": {8, 'f', binary.LittleEndian},
+	"Float64BE": {8, 'f', binary.BigEndian},
+}
+
+// Returns the numeric type of the specified buffer.readX or buffer.writeX
+// method name, along with whether the method reads from the buffer
+func bufferNumMethod(methodName string) (bufferNumType, bool, bool) {
+	if suffix, ok := strings.CutPrefix(methodName, "read"); ok {
+		numType, ok := bufferNumTypes[suffix]
+		return numType, true, ok
+	}
+	if suffix, ok := strings.CutPrefix(methodName, "write"); ok {
+		numType, ok := bufferNumTypes[suffix]
+		return numType, false, ok
+	}
+	return bufferNumType{}, false, false
+}
+
+// Returns the minimum and maximum values of the current integer type
+func (b bufferNumType) intRange() (*big.Int, *big.Int) {
+	one := big.NewInt(1)
+	numBits := uint(b.size * 8)
+	if b.kind == 'i' {
+		limit := new(big.Int).Lsh(one, numBits-1)
+		return new(big.Int).Neg(limit), limit.Sub(limit, one)
+	}
+	limit := new(big.Int).Lsh(one, numBits)
+	return new(big.Int), limit.Sub(limit, one)
+}
+
+// Decodes a value of the current type from the specified bytes, which must
+// be exactly the size of the type
+func (b bufferNumType) decode(bytes []byte) any {
+	var bits uint64
+	switch b.size {
+	case 1:
+		bits = uint64(bytes[0])
+	case 2:
+		bits = uint64(b.order.Uint16(bytes))
+	case 4:
+		bits = uint64(b.order.Uint32(bytes))
+	case 8:
+		bits = b.order.Uint64(bytes)
+	}
+	switch b.kind {
+	case 'f':
+		if b.size == 4 {
+			return float64(math.Float32frombits(uint32(bits)))
+		}
+		return math.Float64frombits(bits)
+	case 'i':
+		//Sign-extend the value from the size of the type to 64 bits
+		shift := uint(64 - b.size*8)
+		return int64(bits<<shift) >> shift
+	}
+	if bits > math.MaxInt64 {
+		return new(big.Int).SetUint64(bits)
+	}
+	return int64(bits)
+}
+
+// Encodes the specified value as bytes of the current type
+func (b bufferNumType) encode(callToken *token.Token, methodName string, value any) ([]byte, error) {
+	bytes := make([]byte, b.size)
+	var bits uint64
+	if b.kind == 'f' {
+		var num float64
+		switch value := value.(type) {
+		case int64:
+			num = float64(value)
+		case float64:
+			num = value
+		default:
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Second argument to 'buffer.%v' must be an integer or float.", methodName))
+		}
+		if b.size == 4 {
+			if !math.IsInf(num, 0) && math.IsInf(float64(float32(num)), 0) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					fmt.Sprintf("Float is too large for 'buffer.%v'.", methodName))
+			}
+			bits = uint64(math.Float32bits(float32(num)))
+		} else {
+			bits = math.Float64bits(num)
+		}
+	} else {
+		var num *big.Int
+		switch value := value.(type) {
+		case int64:
+			num = big.NewInt(value)
+		case *big.Int:
+			num = value
+		default:
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Second argument to 'buffer.%v' must be an integer or bigint.", methodName))
+		}
+		min, max := b.intRange()
+		if num.Cmp(min) < 0 || num.Cmp(max) > 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Value %v out of range for 'buffer.%v', which must be between %v and %v.",
+					num, methodName, min, max))
+		}
+		if num.Sign() < 0 {
+			bits = uint64(num.Int64())
+		} else {
+			bits = num.Uint64()
+		}
+	}
+	switch b.size {
+	case 1:
+		bytes[0] = byte(bits)
+	case 2:
+		b.order.PutUint16(bytes, uint16(bits))
+	case 4:
+		b.order.PutUint32(bytes, uint32(bits))
+	case 8:
+		b.order.PutUint64(bytes, bits)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,22 @@
+package ast
+
+import (
+	"io"
+
+	"github.com/dsnet/compress/bzip2"
+)
+
+func (i *Interpreter) defineBzip2Funcs() {
+	i.defineCompressionClass(compressionFormat{
+		name:         "bzip2",
+		minLevel:     bzip2.BestSpeed,
+		maxLevel:     bzip2.BestCompression,
+		defaultLevel: bzip2.DefaultCompression,
+		newReader: func(reader io.Reader) (io.ReadCloser, error) {
+			return bzip2.NewReader(reader, nil)
+		},
+		newWriter: func(writer io.Writer, level int) (io.WriteCloser, error) {
+			return bzip2.NewWriter(writer, &bzip2.WriterConfig{Level: level})
+		},
+	})
+}
@@ -0,0 +1,434 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func calendarIsLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func calendarDaysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Returns the number of leap years in the range [year1, year2)
+func calendarLeapDays(year1 int, year2 int) int {
+	leapsBefore := func(year int) int {
+		year--
+		return calendarFloorDiv(year, 4) - calendarFloorDiv(year, 100) + calendarFloorDiv(year, 400)
+	}
+	return leapsBefore(year2) - leapsBefore(year1)
+}
+
+func calendarFloorDiv(a int, b int) int {
+	quotient := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		quotient--
+	}
+	return quotient
+}
+
+// Returns the weeks of the specified month, where each week is a list of
+// seven day numbers starting on the specified weekday and days outside of
+// the month are 0
+func calendarMonthWeeks(year int, month time.Month, firstWeekday time.Weekday) [][7]int {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(first.Weekday()) - int(firstWeekday) + 7) % 7
+	numDays := calendarDaysInMonth(year, month)
+	weeks := [][7]int{}
+	for day := 1 - offset; day <= numDays; day += 7 {
+		var week [7]int
+		for index := range week {
+			if value := day + index; value >= 1 && value <= numDays {
+				week[index] = value
+			}
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+// Returns a text calendar of the specified month in the style of the cal
+// command
+func calendarMonthString(year int, month time.Month, firstWeekday time.Weekday) string {
+	const width = 20
+	var builder strings.Builder
+	title := fmt.Sprintf("%v %v", month, year)
+	padding := (width - len(title)) / 2
+	builder.WriteString(strings.TrimRight(strings.Repeat(" ", max(padding, 0))+title, " "))
+	builder.WriteByte('\n')
+	for index := 0; index < 7; index++ {
+		if index > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(((firstWeekday + time.Weekday(index)) % 7).String()[:2])
+	}
+	for _, week := range calendarMonthWeeks(year, month, firstWeekday) {
+		builder.WriteByte('\n')
+		var line strings.Builder
+		for index, day := range week {
+			if index > 0 {
+				line.WriteByte(' ')
+			}
+			if day == 0 {
+				line.WriteString("  ")
+			} else {
+				fmt.Fprintf(&line, "%2d", day)
+			}
+		}
+		builder.WriteString(strings.TrimRight(line.String(), " "))
+	}
+	return builder.String()
+}
+
+// A set of holiday dates, compared by year, month, and day
+type calendarHolidays map[[3]int]struct{}
+
+func (c calendarHolidays) contains(date time.Time) bool {
+	year, month, day := date.Date()
+	_, ok := c[[3]int{year, int(month), day}]
+	return ok
+}
+
+func calendarIsWorkday(date time.Time, holidays calendarHolidays) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !holidays.contains(date)
+}
+
+func (i *Interpreter) defineCalendarFuncs() {
+	className := "calendar"
+	calendarClass := NewLoxClass(className, nil, false)
+	calendarFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native calendar fn %v at %p>", name, &s)
+		}
+		calendarClass.classProperties[name] = s
+	}
+	weekdays := map[string]time.Weekday{
+		"sunday":    time.Sunday,
+		"monday":    time.Monday,
+		"tuesday":   time.Tuesday,
+		"wednesday": time.Wednesday,
+		"thursday":  time.Thursday,
+		"friday":    time.Friday,
+		"saturday":  time.Saturday,
+	}
+	for key, value := range weekdays {
+		calendarClass.classProperties[key] = int64(value) + 1
+	}
+	argsMustBeIntegers := func(callToken *token.Token, name string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Arguments to 'calendar.%v' must be integers.", name))
+	}
+	//Returns the year and month arguments, which must be integers
+	yearMonthArgs := func(callToken *token.Token, name string, args list.List[any]) (int, time.Month, error) {
+		year, ok := args[0].(int64)
+		if !ok {
+			return 0, 0, argsMustBeIntegers(callToken, name)
+		}
+		month, ok := args[1].(int64)
+		if !ok {
+			return 0, 0, argsMustBeIntegers(callToken, name)
+		}
+		if month < 1 || month > 12 {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Month argument to 'calendar.%v' must be between 1 and 12.", name))
+		}
+		return int(year), time.Month(month), nil
+	}
+	//Returns the date specified by the year, month, and day arguments
+	dateArgs := func(callToken *token.Token, name string, args list.List[any]) (time.Time, error) {
+		year, month, err := yearMonthArgs(callToken, name, args)
+		if err != nil {
+			return time.Time{}, err
+		}
+		day, ok := args[2].(int64)
+		if !ok {
+			return time.Time{}, argsMustBeIntegers(callToken, name)
+		}
+		if numDays := calendarDaysInMonth(year, month); day < 1 || day > int64(numDays) {
+			return time.Time{}, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Day argument to 'calendar.%v' must be between 1 and %v.", name, numDays))
+		}
+		return time.Date(year, month, int(day), 0, 0, 0, 0, time.UTC), nil
+	}
+	//Returns the optional first weekday argument at the specified index,
+	//which defaults to Sunday
+	firstWeekdayArg := func(callToken *token.Token, name string, args list.List[any], index int) (time.Weekday, error) {
+		if len(args) <= index {
+			return time.Sunday, nil
+		}
+		weekday, ok := args[index].(int64)
+		if !ok {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First weekday argument to 'calendar.%v' must be an integer.", name))
+		}
+		if weekday < 1 || weekday > 7 {
+			return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("First weekday argument to 'calendar.%v' must be between 1 and 7.", name))
+		}
+		return time.Weekday(weekday - 1), nil
+	}
+	//Returns the optional list of holiday dates at the specified index
+	holidaysArg := func(callToken *token.Token, name string, args list.List[any], index int) (calendarHolidays, error) {
+		holidays := calendarHolidays{}
+		if len(args) <= index {
+			return holidays, nil
+		}
+		errMsg := fmt.Sprintf("Holidays argument to 'calendar.%v' must be a list of dates.", name)
+		loxList, ok := args[index].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken, errMsg)
+		}
+		for _, element := range loxList.elements {
+			loxDate, ok := element.(*LoxDate)
+			if !ok {
+				return nil, loxerror.RuntimeError(callToken, errMsg)
+			}
+			year, month, day := loxDate.date.Date()
+			holidays[[3]int{year, int(month), day}] = struct{}{}
+		}
+		return holidays, nil
+	}
+	argCountError := func(callToken *token.Token, minArgs int, argsLen int) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Expected %v or %v arguments but got %v.", minArgs, minArgs+1, argsLen))
+	}
+	weeksToList := func(weeks [][7]int) *LoxList {
+		weeksList := list.NewListCap[any](int64(len(weeks)))
+		for _, week := range weeks {
+			weekList := list.NewListCap[any](7)
+			for _, day := range week {
+				weekList.Add(int64(day))
+			}
+			weeksList.Add(NewLoxList(weekList))
+		}
+		return NewLoxList(weeksList)
+	}
+	//Returns the first or last workday of the month, depending on step
+	monthWorkday := func(in *Interpreter, name string, args list.List[any], step int) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, argCountError(in.callToken, 2, argsLen)
+		}
+		year, month, err := yearMonthArgs(in.callToken, name, args)
+		if err != nil {
+			return nil, err
+		}
+		holidays, err := holidaysArg(in.callToken, name, args, 2)
+		if err != nil {
+			return nil, err
+		}
+		date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		if step < 0 {
+			date = date.AddDate(0, 1, -1)
+		}
+		for date.Month() == month {
+			if calendarIsWorkday(date, holidays) {
+				return NewLoxDate(date), nil
+			}
+			date = date.AddDate(0, 0, step)
+		}
+		return nil, nil
+	}
+
+	calendarFunc("addWorkdays", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, argCountError(in.callToken, 2, argsLen)
+		}
+		loxDate, ok := args[0].(*LoxDate)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'calendar.addWorkdays' must be a date.")
+		}
+		count, ok := args[1].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'calendar.addWorkdays' must be an integer.")
+		}
+		holidays, err := holidaysArg(in.callToken, "addWorkdays", args, 2)
+		if err != nil {
+			return nil, err
+		}
+		step := 1
+		if count < 0 {
+			step = -1
+			count = -count
+		}
+		date := loxDate.date
+		for count > 0 {
+			date = date.AddDate(0, 0, step)
+			if calendarIsWorkday(date, holidays) {
+				count--
+			}
+		}
+		return NewLoxDate(date), nil
+	})
+	calendarFunc("daysInMonth", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		year, month, err := yearMonthArgs(in.callToken, "daysInMonth", args)
+		if err != nil {
+			return nil, err
+		}
+		return int64(calendarDaysInMonth(year, month)), nil
+	})
+	calendarFunc("daysInYear", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if year, ok := args[0].(int64); ok {
+			if calendarIsLeapYear(int(year)) {
+				return int64(366), nil
+			}
+			return int64(365), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			"Argument to 'calendar.daysInYear' must be an integer.")
+	})
+	calendarFunc("firstWorkday", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		return monthWorkday(in, "firstWorkday", args, 1)
+	})
+	calendarFunc("isLeapYear", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if year, ok := args[0].(int64); ok {
+			return calendarIsLeapYear(int(year)), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			"Argument to 'calendar.isLeapYear' must be an integer.")
+	})
+	calendarFunc("isoWeek", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		date, err := dateArgs(in.callToken, "isoWeek", args)
+		if err != nil {
+			return nil, err
+		}
+		year, week := date.ISOWeek()
+		result := list.NewListCap[any](2)
+		result.Add(int64(year))
+		result.Add(int64(week))
+		return NewLoxList(result), nil
+	})
+	calendarFunc("isoWeeksInYear", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if year, ok := args[0].(int64); ok {
+			//December 28 is always in the last ISO week of its year
+			_, week := time.Date(int(year), time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+			return int64(week), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			"Argument to 'calendar.isoWeeksInYear' must be an integer.")
+	})
+	calendarFunc("isWorkday", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argCountError(in.callToken, 1, argsLen)
+		}
+		loxDate, ok := args[0].(*LoxDate)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'calendar.isWorkday' must be a date.")
+		}
+		holidays, err := holidaysArg(in.callToken, "isWorkday", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return calendarIsWorkday(loxDate.date, holidays), nil
+	})
+	calendarFunc("lastWorkday", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		return monthWorkday(in, "lastWorkday", args, -1)
+	})
+	calendarFunc("leapDays", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		year1, ok := args[0].(int64)
+		if !ok {
+			return nil, argsMustBeIntegers(in.callToken, "leapDays")
+		}
+		year2, ok := args[1].(int64)
+		if !ok {
+			return nil, argsMustBeIntegers(in.callToken, "leapDays")
+		}
+		return int64(calendarLeapDays(int(year1), int(year2))), nil
+	})
+	calendarFunc("monthMatrix", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, argCountError(in.callToken, 2, argsLen)
+		}
+		year, month, err := yearMonthArgs(in.callToken, "monthMatrix", args)
+		if err != nil {
+			return nil, err
+		}
+		firstWeekday, err := firstWeekdayArg(in.callToken, "monthMatrix", args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return weeksToList(calendarMonthWeeks(year, month, firstWeekday)), nil
+	})
+	calendarFunc("monthString", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, argCountError(in.callToken, 2, argsLen)
+		}
+		year, month, err := yearMonthArgs(in.callToken, "monthString", args)
+		if err != nil {
+			return nil, err
+		}
+		firstWeekday, err := firstWeekdayArg(in.callToken, "monthString", args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(calendarMonthString(year, month, firstWeekday)), nil
+	})
+	calendarFunc("nextWorkday", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argCountError(in.callToken, 1, argsLen)
+		}
+		loxDate, ok := args[0].(*LoxDate)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'calendar.nextWorkday' must be a date.")
+		}
+		holidays, err := holidaysArg(in.callToken, "nextWorkday", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		date := loxDate.date.AddDate(0, 0, 1)
+		for !calendarIsWorkday(date, holidays) {
+			date = date.AddDate(0, 0, 1)
+		}
+		return NewLoxDate(date), nil
+	})
+	calendarFunc("weekday", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		date, err := dateArgs(in.callToken, "weekday", args)
+		if err != nil {
+			return nil, err
+		}
+		return int64(date.Weekday()) + 1, nil
+	})
+	calendarFunc("yearMatrix", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argCountError(in.callToken, 1, argsLen)
+		}
+		year, ok := args[0].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'calendar.yearMatrix' must be an integer.")
+		}
+		firstWeekday, err := firstWeekdayArg(in.callToken, "yearMatrix", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		months := list.NewListCap[any](12)
+		for month := time.January; month <= time.December; month++ {
+			months.Add(weeksToList(calendarMonthWeeks(int(year), month, firstWeekday)))
+		}
+		return NewLoxList(months), nil
+	})
+
+	i.globals.Define(className, calendarClass)
+}
@@ -64,6 +64,7 @@ func (i *Interpreter) defineClassCalledLox() {
 	})
 	classCalledLox.classProperties["ranloxcode"] = !util.DisableLoxCode
 	classCalledLox.classProperties["unsafe"] = util.UnsafeMode
+	classCalledLox.classProperties["version"] = NewLoxStringQuote(util.Version)
 
 	i.globals.Define(className, classCalledLox)
 }
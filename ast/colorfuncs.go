@@ -0,0 +1,208 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineColorFuncs() {
+	className := "color"
+	colorClass := NewLoxClass(className, nil, false)
+	colorFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native color fn %v at %p>", name, &s)
+		}
+		colorClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'color.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	numberArgs := func(in *Interpreter, name string, args list.List[any]) ([]float64, error) {
+		argsLen := len(args)
+		if argsLen != 3 && argsLen != 4 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 3 or 4 arguments but got %v.", argsLen))
+		}
+		ordinals := []string{"First", "Second", "Third", "Fourth"}
+		nums := make([]float64, 0, 4)
+		for index, arg := range args {
+			num, ok := colorArgToFloat(arg)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("%v argument to 'color.%v' must be a number.", ordinals[index], name))
+			}
+			nums = append(nums, num)
+		}
+		if argsLen == 3 {
+			nums = append(nums, 1)
+		}
+		return nums, nil
+	}
+	colorList := func(colors ...*LoxColor) *LoxList {
+		colorsList := list.NewListCap[any](int64(len(colors)))
+		for _, color := range colors {
+			colorsList.Add(color)
+		}
+		return NewLoxList(colorsList)
+	}
+
+	colorFunc("contrast", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxColor); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'color.contrast' must be a color.")
+		}
+		if _, ok := args[1].(*LoxColor); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'color.contrast' must be a color.")
+		}
+		return args[0].(*LoxColor).contrast(args[1].(*LoxColor)), nil
+	})
+	colorFunc("gradient", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxColor); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'color.gradient' must be a color.")
+		}
+		if _, ok := args[1].(*LoxColor); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'color.gradient' must be a color.")
+		}
+		if _, ok := args[2].(int64); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Third argument to 'color.gradient' must be an integer.")
+		}
+		start := args[0].(*LoxColor)
+		end := args[1].(*LoxColor)
+		steps := args[2].(int64)
+		if steps < 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Third argument to 'color.gradient' cannot be less than 2.")
+		}
+		colors := make([]*LoxColor, 0, steps)
+		for i := int64(0); i < steps; i++ {
+			colors = append(colors, start.mix(end, float64(i)/float64(steps-1)))
+		}
+		return colorList(colors...), nil
+	})
+	colorFunc("hsl", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, err := numberArgs(in, "hsl", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxColorHSL(nums[0], nums[1], nums[2], nums[3]), nil
+	})
+	colorFunc("hsv", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, err := numberArgs(in, "hsv", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxColorHSV(nums[0], nums[1], nums[2], nums[3]), nil
+	})
+	colorFunc("palette", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		if _, ok := args[0].(*LoxColor); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'color.palette' must be a color.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'color.palette' must be a string.")
+		}
+		count := int64(5)
+		if argsLen == 3 {
+			countArg, ok := args[2].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'color.palette' must be an integer.")
+			}
+			if countArg < 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'color.palette' cannot be less than 1.")
+			}
+			count = countArg
+		}
+		base := args[0].(*LoxColor)
+		rotations := func(degrees ...float64) *LoxList {
+			colors := make([]*LoxColor, 0, len(degrees))
+			for _, degree := range degrees {
+				colors = append(colors, base.rotate(degree))
+			}
+			return colorList(colors...)
+		}
+		switch scheme := args[1].(*LoxString).str; scheme {
+		case "analogous":
+			colors := make([]*LoxColor, 0, count)
+			for i := int64(0); i < count; i++ {
+				colors = append(colors, base.rotate(float64(i-count/2)*30))
+			}
+			return colorList(colors...), nil
+		case "complementary":
+			return rotations(0, 180), nil
+		case "monochromatic":
+			h, s, _ := base.hsl()
+			colors := make([]*LoxColor, 0, count)
+			for i := int64(0); i < count; i++ {
+				colors = append(colors, NewLoxColorHSL(h, s, float64(i+1)/float64(count+1), base.alpha))
+			}
+			return colorList(colors...), nil
+		case "splitComplementary":
+			return rotations(0, 150, 210), nil
+		case "tetradic":
+			return rotations(0, 90, 180, 270), nil
+		case "triadic":
+			return rotations(0, 120, 240), nil
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Unknown palette scheme '%v'.", scheme))
+		}
+	})
+	colorFunc("parse", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			color, err := ParseLoxColor(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return color, nil
+		}
+		return argMustBeType(in.callToken, "parse", "string")
+	})
+	colorFunc("rgb", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, err := numberArgs(in, "rgb", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxColor(
+			clampColorChannel(nums[0]),
+			clampColorChannel(nums[1]),
+			clampColorChannel(nums[2]),
+			clampUnit(nums[3]),
+		), nil
+	})
+	colorFunc("swatches", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxList, ok := args[0].(*LoxList); ok {
+			var str string
+			for _, element := range loxList.elements {
+				color, ok := element.(*LoxColor)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"List passed to 'color.swatches' must only contain colors.")
+				}
+				str += color.swatch(" " + color.hex() + " ")
+			}
+			return NewLoxStringQuote(str), nil
+		}
+		return argMustBeType(in.callToken, "swatches", "list")
+	})
+
+	i.globals.Define(className, colorClass)
+}
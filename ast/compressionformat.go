@@ -0,0 +1,235 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const (
+	COMPRESSION_USE_BUFFER = 1 + iota
+)
+
+// A compression format that is defined as a built-in class whose methods
+// compress and decompress data in that format, such as zstd, bzip2, and xz
+type compressionFormat struct {
+	name         string
+	minLevel     int
+	maxLevel     int
+	defaultLevel int
+	newReader    func(reader io.Reader) (io.ReadCloser, error)
+	newWriter    func(writer io.Writer, level int) (io.WriteCloser, error)
+}
+
+// Returns a reader of the uncompressed data in the specified buffer, file,
+// or string, which is streamed from the file instead of being read at once
+func compressionSource(callToken *token.Token, fnName string, argPos string, arg any) (io.Reader, error) {
+	switch arg := arg.(type) {
+	case *LoxBuffer:
+		return bytes.NewReader(arg.bytes()), nil
+	case *LoxFile:
+		if !arg.isRead() {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("File argument to '%v' must be in read mode.", fnName))
+		}
+		return arg.file, nil
+	case *LoxString:
+		return strings.NewReader(arg.str), nil
+	}
+	return nil, loxerror.RuntimeError(callToken,
+		fmt.Sprintf("%v argument to '%v' must be a buffer, file, or string.", argPos, fnName))
+}
+
+// Returns the compressed data source for the specified buffer or file
+func compressedSource(callToken *token.Token, fnName string, arg any) (io.Reader, error) {
+	switch arg := arg.(type) {
+	case *LoxBuffer:
+		return bytes.NewReader(arg.bytes()), nil
+	case *LoxFile:
+		if !arg.isRead() {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("File argument to '%v' must be in read mode.", fnName))
+		}
+		return arg.file, nil
+	}
+	return nil, loxerror.RuntimeError(callToken,
+		fmt.Sprintf("Argument to '%v' must be a buffer or file.", fnName))
+}
+
+func (f compressionFormat) level(callToken *token.Token, fnName string, argPos string, arg any) (int, error) {
+	level, ok := arg.(int64)
+	if !ok {
+		return 0, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("%v argument to '%v' must be an integer.", argPos, fnName))
+	}
+	if level < int64(f.minLevel) || level > int64(f.maxLevel) {
+		return 0, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("%v compression level must be between %v and %v.", f.name, f.minLevel, f.maxLevel))
+	}
+	return int(level), nil
+}
+
+// Compresses everything from source into destination
+func (f compressionFormat) compress(destination io.Writer, source io.Reader, level int) error {
+	writer, err := f.newWriter(destination, level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, source); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (i *Interpreter) defineCompressionClass(format compressionFormat) {
+	className := format.name
+	compressionClass := NewLoxClass(className, nil, false)
+	compressionFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native %v fn %v at %p>", className, name, &s)
+		}
+		compressionClass.classProperties[name] = s
+	}
+	fullName := func(name string) string {
+		return className + "." + name
+	}
+
+	compressionClass.classProperties["bestCompression"] = int64(format.maxLevel)
+	compressionClass.classProperties["bestSpeed"] = int64(format.minLevel)
+	compressionClass.classProperties["defaultCompression"] = int64(format.defaultLevel)
+	compressionClass.classProperties["USE_BUFFER"] = int64(COMPRESSION_USE_BUFFER)
+	compressionFunc("compress", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		source, err := compressionSource(in.callToken, fullName("compress"), "First", args[0])
+		if err != nil {
+			return nil, err
+		}
+		level := format.defaultLevel
+		if argsLen == 2 {
+			level, err = format.level(in.callToken, fullName("compress"), "Second", args[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		bytesBuffer := new(bytes.Buffer)
+		if err := format.compress(bytesBuffer, source, level); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxBufferFromBytes(bytesBuffer.Bytes()), nil
+	})
+	compressionFunc("decompress", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		source, err := compressedSource(in.callToken, fullName("decompress"), args[0])
+		if err != nil {
+			return nil, err
+		}
+		reader, err := format.newReader(source)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxBufferFromBytes(data), nil
+	})
+	compressionFunc("reader", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		source, err := compressedSource(in.callToken, fullName("reader"), args[0])
+		if err != nil {
+			return nil, err
+		}
+		reader, err := format.newReader(source)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxCompressionReader(className, reader), nil
+	})
+	compressionFunc("write", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		loxFile, ok := args[0].(*LoxFile)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("First argument to '%v' must be a file.", fullName("write")))
+		}
+		if !loxFile.isWrite() && !loxFile.isAppend() {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("First file argument to '%v' must be in write or append mode.", fullName("write")))
+		}
+		source, err := compressionSource(in.callToken, fullName("write"), "Second", args[1])
+		if err != nil {
+			return nil, err
+		}
+		level := format.defaultLevel
+		if argsLen == 3 {
+			level, err = format.level(in.callToken, fullName("write"), "Third", args[2])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := format.compress(loxFile.file, source, level); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return nil, nil
+	})
+	compressionFunc("writer", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		var destination io.Writer
+		var bytesBuffer *bytes.Buffer
+		switch arg := args[0].(type) {
+		case *LoxFile:
+			if !arg.isWrite() && !arg.isAppend() {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Cannot create %v writer for file not in write or append mode.", className))
+			}
+			destination = arg.file
+		case int64:
+			if arg != COMPRESSION_USE_BUFFER {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Integer argument to '%v' must be equal to the field '%v'.",
+						fullName("writer"), fullName("USE_BUFFER")))
+			}
+			bytesBuffer = new(bytes.Buffer)
+			destination = bytesBuffer
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("First argument to '%v' must be a file or the field '%v'.",
+					fullName("writer"), fullName("USE_BUFFER")))
+		}
+		level := format.defaultLevel
+		if argsLen == 2 {
+			var err error
+			level, err = format.level(in.callToken, fullName("writer"), "Second", args[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		writer, err := format.newWriter(destination, level)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxCompressionWriter(className, writer, bytesBuffer), nil
+	})
+
+	i.globals.Define(className, compressionClass)
+}
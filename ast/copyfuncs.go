@@ -0,0 +1,225 @@
+package ast
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+)
+
+// Returns a deep copy of the specified value, where copies maps values
+// that have already been copied to their copies so that values that
+// contain themselves are copied correctly. Values that can't be changed,
+// such as numbers, strings, functions, and classes, aren't copied, and
+// neither are dictionary keys and set elements, since copying a key that
+// is compared by identity, such as an instance, would create a different
+// key
+func deepCopy(value any, copies map[any]any) any {
+	switch value.(type) {
+	case *LoxBuffer, *LoxDeque, *LoxDict, *LoxInstance, *LoxList,
+		*LoxQueue, *LoxRecord, *LoxSet, *LoxSortedList, *LoxTreeMap:
+		if valueCopy, ok := copies[value]; ok {
+			return valueCopy
+		}
+	}
+	switch value := value.(type) {
+	case *LoxBuffer:
+		buffer := NewLoxBuffer(slices.Clone(value.elements))
+		copies[value] = buffer
+		return buffer
+	case *LoxDeque:
+		deque := NewLoxDeque()
+		copies[value] = deque
+		for e := value.elements.Front(); e != nil; e = e.Next() {
+			deque.elements.PushBack(deepCopy(e.Value, copies))
+		}
+		return deque
+	case *LoxDict:
+		dict := EmptyLoxDict()
+		copies[value] = dict
+		for _, entry := range value.entries() {
+			dict.setKeyValue(entry.key, deepCopy(entry.value, copies))
+		}
+		dict.frozen = value.frozen
+		return dict
+	case *LoxInstance:
+		instance := NewLoxInstance(value.class)
+		copies[value] = instance
+		for name, field := range value.fields {
+			instance.fields[name] = deepCopy(field, copies)
+		}
+		return instance
+	case *LoxList:
+		elements := list.NewListCap[any](int64(len(value.elements)))
+		loxList := NewLoxList(elements)
+		copies[value] = loxList
+		for _, element := range value.elements {
+			loxList.elements.Add(deepCopy(element, copies))
+		}
+		loxList.frozen = value.frozen
+		return loxList
+	case *LoxQueue:
+		queue := NewLoxQueue()
+		copies[value] = queue
+		for e := value.elements.Front(); e != nil; e = e.Next() {
+			queue.elements.PushBack(deepCopy(e.Value, copies))
+		}
+		return queue
+	case *LoxRecord:
+		values := make([]any, len(value.values))
+		record := NewLoxRecord(value.recordType, values)
+		copies[value] = record
+		for index, element := range value.values {
+			values[index] = deepCopy(element, copies)
+		}
+		return record
+	case *LoxSet:
+		set := EmptyLoxSet()
+		copies[value] = set
+		for element := range value.elements {
+			set.elements[element] = true
+		}
+		set.frozen = value.frozen
+		return set
+	case *LoxSortedList:
+		sortedList := NewLoxSortedList(value.compareFn)
+		copies[value] = sortedList
+		for index, node := range value.tree.nodes() {
+			sortedList.tree.insertAt(index, deepCopy(node.key, copies), nil)
+		}
+		return sortedList
+	case *LoxTreeMap:
+		treeMap := NewLoxTreeMap(value.compareFn)
+		copies[value] = treeMap
+		for index, node := range value.tree.nodes() {
+			treeMap.tree.insertAt(index, deepCopy(node.key, copies), deepCopy(node.value, copies))
+		}
+		return treeMap
+	}
+	return value
+}
+
+// Reports whether the specified values are deeply equal, where compared
+// holds the pairs of values that are currently being compared. A pair of
+// values that is reached again while it's being compared is treated as
+// equal, so that values that contain themselves can be compared
+func deepEquals(first any, second any, compared map[valuesEqualPair]bool) bool {
+	switch first.(type) {
+	case *LoxBuffer, *LoxDeque, *LoxDict, *LoxInstance, *LoxList,
+		*LoxQueue, *LoxRecord, *LoxSet, *LoxSortedList, *LoxTreeMap:
+		if first == second {
+			return true
+		}
+		pair := valuesEqualPair{first, second}
+		if compared[pair] {
+			return true
+		}
+		compared[pair] = true
+		defer delete(compared, pair)
+	}
+	// Compares the elements of two iterables in order
+	iterablesEqual := func(first []any, second []any) bool {
+		if len(first) != len(second) {
+			return false
+		}
+		for index, element := range first {
+			if !deepEquals(element, second[index], compared) {
+				return false
+			}
+		}
+		return true
+	}
+	switch first := first.(type) {
+	case *LoxBuffer:
+		second, ok := second.(*LoxBuffer)
+		return ok && first.Equals(second)
+	case *LoxDeque:
+		second, ok := second.(*LoxDeque)
+		return ok && iterablesEqual(deepEqualsElements(first), deepEqualsElements(second))
+	case *LoxDict:
+		second, ok := second.(*LoxDict)
+		if !ok || len(first.indexes) != len(second.indexes) {
+			return false
+		}
+		for _, entry := range first.entries() {
+			value, ok := second.getValueByKey(entry.key)
+			if !ok || !deepEquals(entry.value, value, compared) {
+				return false
+			}
+		}
+		return true
+	case *LoxInstance:
+		second, ok := second.(*LoxInstance)
+		if !ok || first.class != second.class || len(first.fields) != len(second.fields) {
+			return false
+		}
+		for name, field := range first.fields {
+			value, ok := second.fields[name]
+			if !ok || !deepEquals(field, value, compared) {
+				return false
+			}
+		}
+		return true
+	case *LoxList:
+		second, ok := second.(*LoxList)
+		return ok && iterablesEqual(first.elements, second.elements)
+	case *LoxQueue:
+		second, ok := second.(*LoxQueue)
+		return ok && iterablesEqual(deepEqualsElements(first), deepEqualsElements(second))
+	case *LoxRecord:
+		second, ok := second.(*LoxRecord)
+		return ok && first.recordType == second.recordType &&
+			iterablesEqual(first.values, second.values)
+	case *LoxSet:
+		second, ok := second.(*LoxSet)
+		return ok && first.Equals(second)
+	case *LoxSortedList:
+		second, ok := second.(*LoxSortedList)
+		return ok && iterablesEqual(deepEqualsElements(first), deepEqualsElements(second))
+	case *LoxTreeMap:
+		second, ok := second.(*LoxTreeMap)
+		if !ok || first.tree.len() != second.tree.len() {
+			return false
+		}
+		secondNodes := second.tree.nodes()
+		for index, node := range first.tree.nodes() {
+			if !deepEquals(node.key, secondNodes[index].key, compared) ||
+				!deepEquals(node.value, secondNodes[index].value, compared) {
+				return false
+			}
+		}
+		return true
+	}
+	return valuesEqual(first, second)
+}
+
+// Returns the elements of the specified iterable as a slice
+func deepEqualsElements(iterable interfaces.Iterable) []any {
+	elements := []any{}
+	it := iterable.Iterator()
+	for it.HasNext() {
+		elements = append(elements, it.Next())
+	}
+	return elements
+}
+
+func (i *Interpreter) defineCopyFuncs() {
+	className := "copy"
+	copyClass := NewLoxClass(className, nil, false)
+	copyFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native copy fn %v at %p>", name, &s)
+		}
+		copyClass.classProperties[name] = s
+	}
+
+	copyFunc("deep", 1, func(_ *Interpreter, args list.List[any]) (any, error) {
+		return deepCopy(args[0], make(map[any]any)), nil
+	})
+
+	i.globals.Define(className, copyClass)
+}
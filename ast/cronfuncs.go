@@ -0,0 +1,328 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type cronJob struct {
+	id        int64
+	schedule  *cronSchedule
+	callback  *LoxFunction
+	nextRun   time.Time
+	runs      int64
+	cancelled bool
+}
+
+type cronRunner struct {
+	jobs    map[int64]*cronJob
+	nextID  int64
+	onError *LoxFunction
+	running bool
+	stopped bool
+	wake    chan struct{}
+	mutex   sync.Mutex
+}
+
+func newCronRunner() *cronRunner {
+	return &cronRunner{
+		jobs:   make(map[int64]*cronJob),
+		nextID: 1,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func (c *cronRunner) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *cronRunner) add(schedule *cronSchedule, callback *LoxFunction) (int64, bool) {
+	nextRun, ok := schedule.next(time.Now())
+	if !ok {
+		return 0, false
+	}
+	c.mutex.Lock()
+	id := c.nextID
+	c.nextID++
+	c.jobs[id] = &cronJob{
+		id:       id,
+		schedule: schedule,
+		callback: callback,
+		nextRun:  nextRun,
+	}
+	c.mutex.Unlock()
+	c.notify()
+	return id, true
+}
+
+func (c *cronRunner) cancel(id int64) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.jobs[id]
+	if !ok {
+		return false
+	}
+	job.cancelled = true
+	delete(c.jobs, id)
+	return true
+}
+
+func (c *cronRunner) earliest() *cronJob {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var chosen *cronJob
+	for _, job := range c.jobs {
+		if chosen == nil || job.nextRun.Before(chosen.nextRun) ||
+			(job.nextRun.Equal(chosen.nextRun) && job.id < chosen.id) {
+			chosen = job
+		}
+	}
+	return chosen
+}
+
+func (c *cronRunner) runJob(in *Interpreter, job *cronJob) error {
+	scheduled := job.nextRun
+	argList := getArgList(job.callback, 2)
+	argList[0] = job.id
+	argList[1] = NewLoxDate(scheduled)
+	result, resultErr := job.callback.call(in, argList)
+
+	c.mutex.Lock()
+	job.runs++
+	if !job.cancelled {
+		//Runs that were missed while the callback was running are skipped
+		after := scheduled
+		if now := time.Now(); now.After(after) {
+			after = now
+		}
+		nextRun, ok := job.schedule.next(after)
+		if ok {
+			job.nextRun = nextRun
+		} else {
+			delete(c.jobs, job.id)
+		}
+	}
+	onError := c.onError
+	c.mutex.Unlock()
+
+	if resultErr != nil && result == nil {
+		if onError == nil {
+			c.cancel(job.id)
+			return resultErr
+		}
+		msg := resultErr.Error()
+		if index := strings.LastIndex(msg, "\n"); index > 0 {
+			msg = msg[:index]
+		}
+		errArgList := getArgList(onError, 2)
+		errArgList[0] = NewLoxStringQuote(msg)
+		errArgList[1] = job.id
+		result, resultErr := onError.call(in, errArgList)
+		if resultErr != nil && result == nil {
+			return resultErr
+		}
+	}
+	return nil
+}
+
+func (c *cronRunner) run(in *Interpreter) error {
+	c.running = true
+	c.stopped = false
+	defer func() {
+		c.running = false
+		c.stopped = false
+	}()
+	for !c.stopped {
+		job := c.earliest()
+		if job == nil {
+			return nil
+		}
+		if wait := time.Until(job.nextRun); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-c.wake:
+				timer.Stop()
+			}
+			continue
+		}
+		if err := c.runJob(in, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cronRunner) jobList() *LoxList {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ids := make([]int64, 0, len(c.jobs))
+	for id := range c.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		return ids[a] < ids[b]
+	})
+	jobs := list.NewListCap[any](int64(len(ids)))
+	for _, id := range ids {
+		job := c.jobs[id]
+		jobDict := EmptyLoxDict()
+		jobDict.setKeyValue(NewLoxStringQuote("id"), job.id)
+		jobDict.setKeyValue(NewLoxStringQuote("expression"), NewLoxStringQuote(job.schedule.expression))
+		jobDict.setKeyValue(NewLoxStringQuote("nextRun"), NewLoxDate(job.nextRun))
+		jobDict.setKeyValue(NewLoxStringQuote("runs"), job.runs)
+		jobs.Add(jobDict)
+	}
+	return NewLoxList(jobs)
+}
+
+func (i *Interpreter) defineCronFuncs() {
+	className := "cron"
+	cronClass := NewLoxClass(className, nil, false)
+	cronFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native cron fn %v at %p>", name, &s)
+		}
+		cronClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'cron.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	//Returns the schedule specified by either a cron expression object or
+	//a cron expression string with an optional time zone argument
+	scheduleArg := func(callToken *token.Token, fnName string, value any, timezone any) (*cronSchedule, error) {
+		location := time.Local
+		if timezone != nil {
+			var err error
+			location, err = timezoneArg(callToken, "cron."+fnName, timezone)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch value := value.(type) {
+		case *LoxCronExpression:
+			if timezone != nil {
+				return parseCronSchedule(value.schedule.expression, location)
+			}
+			return value.schedule, nil
+		case *LoxString:
+			schedule, err := parseCronSchedule(value.str, location)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken, err.Error())
+			}
+			return schedule, nil
+		}
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("First argument to 'cron.%v' must be a string or cron expression.", fnName))
+	}
+
+	runner := newCronRunner()
+	cronFunc("cancel", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if id, ok := args[0].(int64); ok {
+			return runner.cancel(id), nil
+		}
+		return argMustBeType(in.callToken, "cancel", "integer")
+	})
+	cronFunc("isRunning", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return runner.running, nil
+	})
+	cronFunc("isValid", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if expression, ok := args[0].(*LoxString); ok {
+			_, err := parseCronSchedule(expression.str, time.Local)
+			return err == nil, nil
+		}
+		return argMustBeType(in.callToken, "isValid", "string")
+	})
+	cronFunc("jobs", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return runner.jobList(), nil
+	})
+	cronFunc("onError", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch callback := args[0].(type) {
+		case *LoxFunction:
+			runner.mutex.Lock()
+			runner.onError = callback
+			runner.mutex.Unlock()
+			return nil, nil
+		case nil:
+			runner.mutex.Lock()
+			runner.onError = nil
+			runner.mutex.Unlock()
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "onError", "function or nil")
+	})
+	cronFunc("parse", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'cron.parse' must be a string.")
+		}
+		var timezone any
+		if argsLen == 2 {
+			timezone = args[1]
+		}
+		schedule, err := scheduleArg(in.callToken, "parse", args[0], timezone)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxCronExpression(schedule), nil
+	})
+	cronFunc("run", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if runner.running {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cron is already running.")
+		}
+		return nil, runner.run(in)
+	})
+	cronFunc("schedule", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		callback, ok := args[1].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'cron.schedule' must be a function.")
+		}
+		var timezone any
+		if argsLen == 3 {
+			timezone = args[2]
+		}
+		schedule, err := scheduleArg(in.callToken, "schedule", args[0], timezone)
+		if err != nil {
+			return nil, err
+		}
+		id, ok := runner.add(schedule, callback)
+		if !ok {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("Cron expression '%v' never matches any date.", schedule.expression))
+		}
+		return id, nil
+	})
+	cronFunc("stop", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		runner.stopped = true
+		runner.notify()
+		return nil, nil
+	})
+
+	i.globals.Define(className, cronClass)
+}
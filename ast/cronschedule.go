@@ -0,0 +1,256 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronMacros = map[string]string{
+	"@annually": "0 0 1 1 *",
+	"@yearly":   "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+const cronSearchYears = 30 //Long enough to find schedules such as February 29 on a Monday
+
+type cronSchedule struct {
+	expression string
+	seconds    uint64
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	daysStar   bool //Whether the day of month field is "*" or "?"
+	weekStar   bool //Whether the day of week field is "*" or "?"
+	hasSeconds bool
+	location   *time.Location
+}
+
+func parseCronField(field string, minValue int, maxValue int, names map[string]int) (uint64, bool, error) {
+	var bits uint64
+	isStar := field == "*" || field == "?"
+	parseValue := func(str string) (int, error) {
+		if value, ok := names[strings.ToLower(str)]; ok {
+			return value, nil
+		}
+		value, err := strconv.Atoi(str)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value '%v'", str)
+		}
+		if value < minValue || value > maxValue {
+			return 0, fmt.Errorf("value %v is out of range %v-%v", value, minValue, maxValue)
+		}
+		return value, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		rangeStr, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return 0, false, fmt.Errorf("invalid step '%v'", stepStr)
+			}
+		}
+		var start, end int
+		switch {
+		case rangeStr == "*" || rangeStr == "?":
+			start, end = minValue, maxValue
+		case strings.Contains(rangeStr, "-"):
+			startStr, endStr, _ := strings.Cut(rangeStr, "-")
+			var err error
+			if start, err = parseValue(startStr); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseValue(endStr); err != nil {
+				return 0, false, err
+			}
+			if start > end {
+				return 0, false, fmt.Errorf("invalid range '%v'", rangeStr)
+			}
+		default:
+			var err error
+			if start, err = parseValue(rangeStr); err != nil {
+				return 0, false, err
+			}
+			end = start
+			if hasStep {
+				end = maxValue
+			}
+		}
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, isStar, nil
+}
+
+func parseCronSchedule(expression string, location *time.Location) (*cronSchedule, error) {
+	trimmed := strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(trimmed)]; ok {
+		trimmed = macro
+	}
+	fields := strings.Fields(trimmed)
+	if len(fields) != 5 && len(fields) != 6 {
+		return nil, fmt.Errorf("invalid cron expression '%v': expected 5 or 6 fields but got %v",
+			expression, len(fields))
+	}
+	c := &cronSchedule{
+		expression: expression,
+		seconds:    1,
+		hasSeconds: len(fields) == 6,
+		location:   location,
+	}
+	if c.hasSeconds {
+		var err error
+		if c.seconds, _, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%v': second field: %v", expression, err)
+		}
+		fields = fields[1:]
+	}
+	var err error
+	errorf := func(fieldName string) error {
+		return fmt.Errorf("invalid cron expression '%v': %v field: %v", expression, fieldName, err)
+	}
+	if c.minutes, _, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, errorf("minute")
+	}
+	if c.hours, _, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, errorf("hour")
+	}
+	if c.days, c.daysStar, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, errorf("day of month")
+	}
+	if c.months, _, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, errorf("month")
+	}
+	if c.weekdays, c.weekStar, err = parseCronField(fields[4], 0, 7, cronWeekdayNames); err != nil {
+		return nil, errorf("day of week")
+	}
+	//Both 0 and 7 are Sunday
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays = c.weekdays&^(1<<7) | 1
+	}
+	return c, nil
+}
+
+func (c *cronSchedule) dayMatches(date time.Time) bool {
+	dayMatch := c.days&(1<<uint(date.Day())) != 0
+	weekMatch := c.weekdays&(1<<uint(date.Weekday())) != 0
+	if !c.daysStar && !c.weekStar {
+		//Like in most cron implementations, a date can match either field
+		return dayMatch || weekMatch
+	}
+	return dayMatch && weekMatch
+}
+
+func (c *cronSchedule) matches(date time.Time) bool {
+	date = date.In(c.location)
+	return c.seconds&(1<<uint(date.Second())) != 0 &&
+		c.minutes&(1<<uint(date.Minute())) != 0 &&
+		c.hours&(1<<uint(date.Hour())) != 0 &&
+		c.months&(1<<uint(date.Month())) != 0 &&
+		c.dayMatches(date)
+}
+
+func (c *cronSchedule) unit() time.Duration {
+	if c.hasSeconds {
+		return time.Second
+	}
+	return time.Minute
+}
+
+func (c *cronSchedule) isRepeat(date time.Time) bool {
+	//Wall clock times that occur twice when clocks fall back only match once
+	year, month, day := date.Date()
+	first := time.Date(year, month, day, date.Hour(), date.Minute(), date.Second(), 0, c.location)
+	return first.Before(date.Truncate(time.Second)) &&
+		first.Hour() == date.Hour() && first.Minute() == date.Minute()
+}
+
+func (c *cronSchedule) next(after time.Time) (time.Time, bool) {
+	unit := c.unit()
+	date := after.In(c.location).Truncate(unit).Add(unit)
+	limit := date.Year() + cronSearchYears
+	for date.Year() <= limit {
+		year, month, day := date.Date()
+		previous := date
+		switch {
+		case c.months&(1<<uint(month)) == 0:
+			date = time.Date(year, month+1, 1, 0, 0, 0, 0, c.location)
+		case !c.dayMatches(date):
+			date = time.Date(year, month, day+1, 0, 0, 0, 0, c.location)
+		case c.hours&(1<<uint(date.Hour())) == 0:
+			date = time.Date(year, month, day, date.Hour()+1, 0, 0, 0, c.location)
+		case c.minutes&(1<<uint(date.Minute())) == 0:
+			date = date.Truncate(time.Minute).Add(time.Minute)
+		case c.seconds&(1<<uint(date.Second())) == 0:
+			date = date.Add(time.Second)
+		case c.isRepeat(date):
+			date = date.Add(unit)
+		default:
+			return date, true
+		}
+		//Wall clock times that don't exist because of daylight saving time
+		//can be normalized to an earlier time, so make sure the search
+		//always moves forward
+		if !date.After(previous) {
+			date = previous.Truncate(time.Hour).Add(time.Hour)
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c *cronSchedule) prev(before time.Time) (time.Time, bool) {
+	unit := c.unit()
+	date := before.In(c.location)
+	if truncated := date.Truncate(unit); truncated.Equal(date) {
+		date = date.Add(-unit)
+	} else {
+		date = truncated
+	}
+	limit := date.Year() - cronSearchYears
+	for date.Year() >= limit {
+		year, month, day := date.Date()
+		previous := date
+		switch {
+		case c.months&(1<<uint(month)) == 0:
+			date = time.Date(year, month, 1, 0, 0, 0, 0, c.location).Add(-unit)
+		case !c.dayMatches(date):
+			date = time.Date(year, month, day, 0, 0, 0, 0, c.location).Add(-unit)
+		case c.hours&(1<<uint(date.Hour())) == 0:
+			date = time.Date(year, month, day, date.Hour(), 0, 0, 0, c.location).Add(-unit)
+		case c.minutes&(1<<uint(date.Minute())) == 0:
+			date = date.Truncate(time.Minute).Add(-unit)
+		case c.seconds&(1<<uint(date.Second())) == 0:
+			date = date.Add(-time.Second)
+		case c.isRepeat(date):
+			date = date.Add(-unit)
+		default:
+			return date, true
+		}
+		//Wall clock times that occur twice because of daylight saving time
+		//can be normalized to a later time, so make sure the search always
+		//moves backward
+		if !date.Before(previous) {
+			date = previous.Truncate(time.Hour).Add(-unit)
+		}
+	}
+	return time.Time{}, false
+}
@@ -8,9 +8,13 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"hash"
+	"hash/adler32"
+	"hash/crc32"
+	"hash/crc64"
 	"math/big"
 
 	"github.com/AlanLuu/lox/list"
@@ -18,15 +22,38 @@ import (
 	"github.com/AlanLuu/lox/token"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
 )
 
 var LoxCryptoHashes = map[string]crypto.Hash{
-	"md5":    crypto.MD5,
-	"sha1":   crypto.SHA1,
-	"sha224": crypto.SHA224,
-	"sha256": crypto.SHA256,
-	"sha384": crypto.SHA384,
-	"sha512": crypto.SHA512,
+	"blake2b256": crypto.BLAKE2b_256,
+	"blake2b384": crypto.BLAKE2b_384,
+	"blake2b512": crypto.BLAKE2b_512,
+	"blake2s256": crypto.BLAKE2s_256,
+	"md5":        crypto.MD5,
+	"sha1":       crypto.SHA1,
+	"sha224":     crypto.SHA224,
+	"sha256":     crypto.SHA256,
+	"sha384":     crypto.SHA384,
+	"sha3_224":   crypto.SHA3_224,
+	"sha3_256":   crypto.SHA3_256,
+	"sha3_384":   crypto.SHA3_384,
+	"sha3_512":   crypto.SHA3_512,
+	"sha512":     crypto.SHA512,
+}
+
+var crc32CastagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+var crc64ECMATable = crc64.MakeTable(crc64.ECMA)
+
+// Returns a function that creates an unkeyed BLAKE2 hash from the specified
+// BLAKE2 constructor, which only fails if a key is passed to it
+func blake2HashFunc(newHash func(key []byte) (hash.Hash, error)) func() hash.Hash {
+	return func() hash.Hash {
+		theHash, _ := newHash(nil)
+		return theHash
+	}
 }
 
 func (i *Interpreter) defineCryptoFuncs() {
@@ -50,6 +77,132 @@ func (i *Interpreter) defineCryptoFuncs() {
 		return nil, loxerror.RuntimeError(callToken, errStr)
 	}
 
+	passwordHashFunc := func(fnName string, isScrypt bool) {
+		cryptoFunc(fnName, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			var password []byte
+			switch arg := args[0].(type) {
+			case *LoxBuffer:
+				password = arg.bytes()
+			case *LoxString:
+				password = []byte(arg.str)
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'crypto.%v' must be a buffer or string.", fnName))
+			}
+			params := defaultArgon2Params
+			if isScrypt {
+				params = defaultScryptParams
+			}
+			if argsLen == 2 {
+				options, ok := args[1].(*LoxDict)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Second argument to 'crypto.%v' must be a dictionary.", fnName))
+				}
+				if err := params.setOptions(options, isScrypt); err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+			}
+			var hash string
+			var err error
+			if isScrypt {
+				hash, err = scryptHash(password, params)
+			} else {
+				hash, err = argon2Hash(password, params)
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(hash), nil
+		})
+		verifyName := fnName + "Verify"
+		cryptoFunc(verifyName, 2, func(in *Interpreter, args list.List[any]) (any, error) {
+			var password []byte
+			switch arg := args[0].(type) {
+			case *LoxBuffer:
+				password = arg.bytes()
+			case *LoxString:
+				password = []byte(arg.str)
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'crypto.%v' must be a buffer or string.", verifyName))
+			}
+			hash, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Second argument to 'crypto.%v' must be a string.", verifyName))
+			}
+			var matches bool
+			var err error
+			if isScrypt {
+				matches, err = scryptVerify(password, hash.str)
+			} else {
+				matches, err = argon2Verify(password, hash.str)
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return matches, nil
+		})
+	}
+
+	//Defines a function that returns a hash object that is initialized with
+	//the specified data if it is passed in
+	hashObjFunc := func(name string, newHash func() hash.Hash) {
+		cryptoFunc(name, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen > 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+			hashObj := newHash()
+			if argsLen == 1 {
+				if err := writeHashData(in.callToken, "crypto."+name, hashObj, args[0]); err != nil {
+					return nil, err
+				}
+			}
+			return NewLoxHash(hashObj, newHash, name), nil
+		})
+	}
+	//Defines a function that returns a hash object and a function that
+	//returns the hexadecimal digest of the specified data, such as
+	//crypto.blake2b256 and crypto.blake2b256sum
+	hashFuncs := func(name string, newHash func() hash.Hash) {
+		hashObjFunc(name, newHash)
+		cryptoFunc(name+"sum", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+			hashObj := newHash()
+			if err := writeHashData(in.callToken, "crypto."+name+"sum", hashObj, args[0]); err != nil {
+				return nil, err
+			}
+			return NewLoxStringQuote(hex.EncodeToString(hashObj.Sum(nil))), nil
+		})
+	}
+	//Same as hashFuncs, except that the sum function returns the checksum
+	//as an integer instead of a hexadecimal string
+	checksumFuncs := func(name string, newHash func() hash.Hash) {
+		hashObjFunc(name, newHash)
+		cryptoFunc(name+"sum", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+			hashObj := newHash()
+			if err := writeHashData(in.callToken, "crypto."+name+"sum", hashObj, args[0]); err != nil {
+				return nil, err
+			}
+			switch hashObj := hashObj.(type) {
+			case hash.Hash32:
+				return int64(hashObj.Sum32()), nil
+			case hash.Hash64:
+				return uintToLoxValue(hashObj.Sum64()), nil
+			}
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Unknown checksum type '%v'.", name))
+		})
+	}
+
+	checksumFuncs("adler32", func() hash.Hash { return adler32.New() })
 	cryptoFunc("aescbc", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var aesCBC *LoxAESCBC
 		var err error
@@ -134,6 +287,44 @@ func (i *Interpreter) defineCryptoFuncs() {
 		}
 		return argMustBeType(in.callToken, "aescfbhex", "string")
 	})
+	cryptoFunc("aesgcm", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var aesGCM *LoxAEAD
+		var err error
+		switch arg := args[0].(type) {
+		case int64:
+			aesGCM, err = NewLoxAESGCM(int(arg))
+		case *LoxBuffer:
+			aesGCM, err = NewLoxAESGCMBytes(arg.bytes())
+		case *LoxString:
+			keyBytes, decodeErr := LoxAESDecode(arg.str)
+			if decodeErr != nil {
+				return nil, loxerror.RuntimeError(in.callToken, decodeErr.Error())
+			}
+			aesGCM, err = NewLoxAESGCMBytes(keyBytes)
+		default:
+			return argMustBeType(in.callToken, "aesgcm", "buffer, integer, or string")
+		}
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"crypto.aesgcm: "+err.Error())
+		}
+		return aesGCM, nil
+	})
+	cryptoFunc("aesgcmhex", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			keyBytes, decodeErr := hex.DecodeString(loxStr.str)
+			if decodeErr != nil {
+				return nil, loxerror.RuntimeError(in.callToken, decodeErr.Error())
+			}
+			aesGCM, err := NewLoxAESGCMBytes(keyBytes)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"crypto.aesgcmhex: "+err.Error())
+			}
+			return aesGCM, nil
+		}
+		return argMustBeType(in.callToken, "aesgcmhex", "string")
+	})
 	cryptoFunc("ageasym", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var result *LoxAgeAsymmetric
 		var err error
@@ -185,6 +376,7 @@ func (i *Interpreter) defineCryptoFuncs() {
 				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
 		}
 	})
+	passwordHashFunc("argon2", false)
 	cryptoFunc("bcrypt", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var password []byte
 		var cost int
@@ -230,6 +422,65 @@ func (i *Interpreter) defineCryptoFuncs() {
 		hash := []byte(args[1].(*LoxString).str)
 		return bcrypt.CompareHashAndPassword(hash, password) == nil, nil
 	})
+	hashFuncs("blake2b256", blake2HashFunc(blake2b.New256))
+	hashFuncs("blake2b384", blake2HashFunc(blake2b.New384))
+	hashFuncs("blake2b512", blake2HashFunc(blake2b.New512))
+	hashFuncs("blake2s256", blake2HashFunc(blake2s.New256))
+	cryptoFunc("chacha20poly1305", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var chacha *LoxAEAD
+		var err error
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+			chacha, err = NewLoxChaCha20Poly1305()
+		case 1:
+			switch arg := args[0].(type) {
+			case *LoxBuffer:
+				chacha, err = NewLoxChaCha20Poly1305Bytes(arg.bytes())
+			case *LoxString:
+				keyBytes, decodeErr := LoxAESDecode(arg.str)
+				if decodeErr != nil {
+					return nil, loxerror.RuntimeError(in.callToken, decodeErr.Error())
+				}
+				chacha, err = NewLoxChaCha20Poly1305Bytes(keyBytes)
+			default:
+				return argMustBeType(in.callToken, "chacha20poly1305", "buffer or string")
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"crypto.chacha20poly1305: "+err.Error())
+		}
+		return chacha, nil
+	})
+	checksumFuncs("crc32", func() hash.Hash { return crc32.NewIEEE() })
+	checksumFuncs("crc32c", func() hash.Hash { return crc32.New(crc32CastagnoliTable) })
+	checksumFuncs("crc64", func() hash.Hash { return crc64.New(crc64ECMATable) })
+	cryptoFunc("compareDigest", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		toBytes := func(arg any) ([]byte, bool) {
+			switch arg := arg.(type) {
+			case *LoxBuffer:
+				return arg.bytes(), true
+			case *LoxString:
+				return []byte(arg.str), true
+			}
+			return nil, false
+		}
+		a, ok := toBytes(args[0])
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'crypto.compareDigest' must be a buffer or string.")
+		}
+		b, ok := toBytes(args[1])
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'crypto.compareDigest' must be a buffer or string.")
+		}
+		return subtle.ConstantTimeCompare(a, b) == 1, nil
+	})
 	cryptoFunc("ed25519", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		keyPair, err := NewLoxEd25519()
 		if err != nil {
@@ -398,6 +649,8 @@ func (i *Interpreter) defineCryptoFuncs() {
 				"Function argument to 'crypto.hmac' must return a hash object.")
 		}
 	})
+	hashFuncs("keccak256", sha3.NewLegacyKeccak256)
+	hashFuncs("keccak512", sha3.NewLegacyKeccak512)
 	cryptoFunc("md5", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var hashObj hash.Hash
 		argsLen := len(args)
@@ -444,6 +697,22 @@ func (i *Interpreter) defineCryptoFuncs() {
 		hexDigest := fmt.Sprintf("%x", hashObj.Sum(nil))
 		return NewLoxString(hexDigest, '\''), nil
 	})
+	cryptoFunc("pemkey", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var data []byte
+		switch arg := args[0].(type) {
+		case *LoxBuffer:
+			data = arg.bytes()
+		case *LoxString:
+			data = []byte(arg.str)
+		default:
+			return argMustBeType(in.callToken, "pemkey", "buffer or string")
+		}
+		key, err := loxKeyFromPEM(data)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, "crypto.pemkey: "+err.Error())
+		}
+		return key, nil
+	})
 	cryptoFunc("prime", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if numBits, ok := args[0].(int64); ok {
 			if numBits < 2 {
@@ -458,6 +727,31 @@ func (i *Interpreter) defineCryptoFuncs() {
 		}
 		return argMustBeTypeAn(in.callToken, "prime", "integer")
 	})
+	cryptoFunc("randBelow", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch n := args[0].(type) {
+		case int64:
+			if n <= 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'crypto.randBelow' must be greater than 0.")
+			}
+			result, err := crand.Int(crand.Reader, big.NewInt(n))
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return result.Int64(), nil
+		case *big.Int:
+			if n.Sign() <= 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'crypto.randBelow' must be greater than 0.")
+			}
+			result, err := crand.Int(crand.Reader, n)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return result, nil
+		}
+		return argMustBeTypeAn(in.callToken, "randBelow", "integer or bigint")
+	})
 	cryptoFunc("randomUUID", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		randUUID, err := uuid.NewRandom()
 		if err != nil {
@@ -528,6 +822,7 @@ func (i *Interpreter) defineCryptoFuncs() {
 		E := int(args[1].(int64))
 		return NewLoxRSAPubKey(N, E), nil
 	})
+	passwordHashFunc("scrypt", true)
 	cryptoFunc("sha1", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var hashObj hash.Hash
 		argsLen := len(args)
@@ -712,6 +1007,10 @@ func (i *Interpreter) defineCryptoFuncs() {
 		hexDigest := fmt.Sprintf("%x", hashObj.Sum(nil))
 		return NewLoxString(hexDigest, '\''), nil
 	})
+	hashFuncs("sha3_224", sha3.New224)
+	hashFuncs("sha3_256", sha3.New256)
+	hashFuncs("sha3_384", sha3.New384)
+	hashFuncs("sha3_512", sha3.New512)
 	cryptoFunc("sha512", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var hashObj hash.Hash
 		argsLen := len(args)
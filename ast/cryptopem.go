@@ -0,0 +1,59 @@
+package ast
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+// Returns the PEM encoding of the specified DER bytes as a string
+func encodePEM(blockType string, der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  blockType,
+		Bytes: der,
+	}))
+}
+
+// Returns an RSA or Ed25519 keypair or public key object from the first
+// PEM block in the specified data
+func loxKeyFromPEM(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, loxerror.Error("No PEM data found.")
+	}
+	if len(block.Headers) > 0 {
+		return nil, loxerror.Error("Encrypted PEM keys are not supported.")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, loxerror.Error(fmt.Sprintf("Unsupported PEM block type '%v'.", block.Type))
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return NewLoxRSAPrivKey(key), nil
+	case *rsa.PublicKey:
+		return NewLoxRSAPubKey(key.N, key.E), nil
+	case ed25519.PrivateKey:
+		return NewLoxEd25519PrivKey(key)
+	case ed25519.PublicKey:
+		return NewLoxEd25519PubKey(key)
+	}
+	return nil, loxerror.Error("PEM key must be an RSA or Ed25519 key.")
+}
@@ -0,0 +1,283 @@
+package ast
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/util"
+)
+
+// The characters and settings used to read and write CSV data, where a
+// quote or comment character of 0 means that the feature is disabled
+type csvDialect struct {
+	delimiter        rune
+	quote            rune
+	comment          rune
+	trimLeadingSpace bool
+	lazyQuotes       bool
+}
+
+var defaultCSVDialect = csvDialect{delimiter: ',', quote: '"'}
+
+func (d csvDialect) validate() error {
+	isValid := func(r rune) bool {
+		return r != 0 && r != '\r' && r != '\n' && r != utf8.RuneError
+	}
+	if !isValid(d.delimiter) {
+		return loxerror.Error("CSV delimiter is not valid.")
+	}
+	if d.quote != 0 && (!isValid(d.quote) || d.quote == d.delimiter) {
+		return loxerror.Error("CSV quote character is not valid or is the same as the delimiter.")
+	}
+	if d.comment != 0 && (!isValid(d.comment) || d.comment == d.delimiter || d.comment == d.quote) {
+		return loxerror.Error("CSV comment character is not valid or is the same as another CSV character.")
+	}
+	return nil
+}
+
+// Reads CSV records either with encoding/csv or with csvQuoteReader
+type csvRecordReader interface {
+	Read() ([]string, error)
+	ReadAll() ([][]string, error)
+}
+
+// Writes CSV records either with encoding/csv or with csvQuoteWriter
+type csvRecordWriter interface {
+	Write(record []string) error
+	WriteAll(records [][]string) error
+	Flush()
+	Error() error
+}
+
+// Returns a CSV reader for this dialect. The reader from encoding/csv is
+// used whenever possible since it only supports '"' as the quote character
+func (d csvDialect) newReader(reader io.Reader) csvRecordReader {
+	if d.quote == '"' {
+		csvReader := csv.NewReader(reader)
+		csvReader.Comma = d.delimiter
+		csvReader.Comment = d.comment
+		csvReader.TrimLeadingSpace = d.trimLeadingSpace
+		csvReader.LazyQuotes = d.lazyQuotes
+		csvReader.FieldsPerRecord = -1
+		return csvReader
+	}
+	return &csvQuoteReader{reader: bufio.NewReader(reader), dialect: d}
+}
+
+func (d csvDialect) newWriter(writer io.Writer) csvRecordWriter {
+	if d.quote == '"' {
+		csvWriter := csv.NewWriter(writer)
+		csvWriter.Comma = d.delimiter
+		if util.IsWindows() {
+			csvWriter.UseCRLF = true
+		}
+		return csvWriter
+	}
+	return &csvQuoteWriter{writer: bufio.NewWriter(writer), dialect: d}
+}
+
+// A CSV reader that supports any quote character or no quote character,
+// following the same rules as the reader from encoding/csv otherwise
+type csvQuoteReader struct {
+	reader  *bufio.Reader
+	dialect csvDialect
+	lineNum int
+}
+
+func (r *csvQuoteReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if len(line) > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	r.lineNum++
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, nil
+}
+
+func (r *csvQuoteReader) Read() ([]string, error) {
+	var line string
+	for {
+		var err error
+		line, err = r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		if r.dialect.comment != 0 && strings.HasPrefix(line, string(r.dialect.comment)) {
+			continue
+		}
+		break
+	}
+	startLine := r.lineNum
+	delimiter := string(r.dialect.delimiter)
+	quote := string(r.dialect.quote)
+	fields := []string{}
+	for {
+		if r.dialect.trimLeadingSpace {
+			line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		}
+		if r.dialect.quote == 0 || !strings.HasPrefix(line, quote) {
+			//Unquoted field
+			end := strings.Index(line, delimiter)
+			field := line
+			if end >= 0 {
+				field = line[:end]
+			}
+			if !r.dialect.lazyQuotes && r.dialect.quote != 0 && strings.Contains(field, quote) {
+				return nil, fmt.Errorf("record on line %v: bare %v in non-quoted field", r.lineNum, quote)
+			}
+			fields = append(fields, field)
+			if end < 0 {
+				return fields, nil
+			}
+			line = line[end+len(delimiter):]
+			continue
+		}
+		//Quoted field, which may continue onto the next lines
+		line = line[len(quote):]
+		var field strings.Builder
+		for {
+			index := strings.Index(line, quote)
+			if index < 0 {
+				field.WriteString(line)
+				nextLine, err := r.readLine()
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						return nil, fmt.Errorf("record on line %v: extraneous or missing %v in quoted field",
+							startLine, quote)
+					}
+					return nil, err
+				}
+				field.WriteByte('\n')
+				line = nextLine
+				continue
+			}
+			field.WriteString(line[:index])
+			line = line[index+len(quote):]
+			if strings.HasPrefix(line, quote) {
+				//Two quotes in a row are an escaped quote
+				field.WriteString(quote)
+				line = line[len(quote):]
+				continue
+			}
+			break
+		}
+		switch {
+		case line == "":
+			fields = append(fields, field.String())
+			return fields, nil
+		case strings.HasPrefix(line, delimiter):
+			fields = append(fields, field.String())
+			line = line[len(delimiter):]
+		case r.dialect.lazyQuotes:
+			//Treat the rest of the field as if it were inside the quotes
+			end := strings.Index(line, delimiter)
+			if end < 0 {
+				field.WriteString(quote + line)
+				fields = append(fields, field.String())
+				return fields, nil
+			}
+			field.WriteString(quote + line[:end])
+			fields = append(fields, field.String())
+			line = line[end+len(delimiter):]
+		default:
+			return nil, fmt.Errorf("record on line %v: extraneous or missing %v in quoted field",
+				r.lineNum, quote)
+		}
+	}
+}
+
+func (r *csvQuoteReader) ReadAll() ([][]string, error) {
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// A CSV writer that supports any quote character or no quote character
+type csvQuoteWriter struct {
+	writer  *bufio.Writer
+	dialect csvDialect
+	err     error
+}
+
+func (w *csvQuoteWriter) Write(record []string) error {
+	lineEnd := "\n"
+	if util.IsWindows() {
+		lineEnd = "\r\n"
+	}
+	delimiter := string(w.dialect.delimiter)
+	quote := string(w.dialect.quote)
+	for index, field := range record {
+		if index > 0 {
+			if _, err := w.writer.WriteString(delimiter); err != nil {
+				return err
+			}
+		}
+		needsQuotes := strings.ContainsAny(field, delimiter+"\r\n")
+		if w.dialect.quote != 0 {
+			needsQuotes = needsQuotes || strings.Contains(field, quote) ||
+				(field != "" && (field[0] == ' ' || field[0] == '\t'))
+		}
+		if needsQuotes {
+			if w.dialect.quote == 0 {
+				return fmt.Errorf("cannot write CSV field %q without a quote character", field)
+			}
+			field = quote + strings.ReplaceAll(field, quote, quote+quote) + quote
+		}
+		if _, err := w.writer.WriteString(field); err != nil {
+			return err
+		}
+	}
+	_, err := w.writer.WriteString(lineEnd)
+	return err
+}
+
+func (w *csvQuoteWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (w *csvQuoteWriter) Flush() {
+	w.err = w.writer.Flush()
+}
+
+func (w *csvQuoteWriter) Error() error {
+	return w.err
+}
+
+// Returns the string that is written to a CSV file for the specified value
+func csvFieldStr(value any) string {
+	switch value := value.(type) {
+	case *LoxString:
+		return value.str
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprint(value)
+}
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"unicode/utf8"
 
@@ -10,6 +11,107 @@ import (
 	"github.com/AlanLuu/lox/token"
 )
 
+// Returns the character specified by a CSV option, where nil and the empty
+// string mean that the character is not used if allowNone is true
+func csvCharOption(name string, value any, allowNone bool) (rune, error) {
+	switch value := value.(type) {
+	case nil:
+		if allowNone {
+			return 0, nil
+		}
+	case *LoxString:
+		if value.str == "" && allowNone {
+			return 0, nil
+		}
+		if utf8.RuneCountInString(value.str) == 1 {
+			return []rune(value.str)[0], nil
+		}
+	}
+	if allowNone {
+		return 0, loxerror.Error(fmt.Sprintf(
+			"CSV option '%v' must be a single-character string, an empty string, or nil.", name))
+	}
+	return 0, loxerror.Error(fmt.Sprintf("CSV option '%v' must be a single-character string.", name))
+}
+
+// Sets the fields of dialect from the specified dictionary of options.
+// Options that are not dialect options are passed to extra if it is not nil,
+// which returns false if it does not recognize the option either
+func csvOptions(options *LoxDict, dialect *csvDialect, extra func(name string, value any) (bool, error)) error {
+	err := forEachOption(options, func(name string, value any) error {
+		var err error
+		switch name {
+		case "delimiter":
+			dialect.delimiter, err = csvCharOption(name, value, false)
+		case "quote":
+			dialect.quote, err = csvCharOption(name, value, true)
+		case "comment":
+			dialect.comment, err = csvCharOption(name, value, true)
+		case "trimLeadingSpace", "lazyQuotes":
+			boolValue, ok := value.(bool)
+			if !ok {
+				return loxerror.Error(fmt.Sprintf("CSV option '%v' must be a boolean.", name))
+			}
+			if name == "trimLeadingSpace" {
+				dialect.trimLeadingSpace = boolValue
+			} else {
+				dialect.lazyQuotes = boolValue
+			}
+		default:
+			handled := false
+			if extra != nil {
+				handled, err = extra(name, value)
+			}
+			if err == nil && !handled {
+				err = loxerror.Error(fmt.Sprintf("Unknown CSV option '%v'.", name))
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return dialect.validate()
+}
+
+// Returns the CSV dialect specified by an argument that is either a
+// single-character delimiter string or a dictionary of options
+func csvDialectArg(callToken *token.Token, fnName string, argPos string, arg any) (csvDialect, error) {
+	dialect := defaultCSVDialect
+	switch arg := arg.(type) {
+	case *LoxString:
+		if utf8.RuneCountInString(arg.str) != 1 {
+			return dialect, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("%v argument to 'csv.%v' must be a single-character string.", argPos, fnName))
+		}
+		dialect.delimiter = []rune(arg.str)[0]
+		if err := dialect.validate(); err != nil {
+			return dialect, loxerror.RuntimeError(callToken, err.Error())
+		}
+	case *LoxDict:
+		if err := csvOptions(arg, &dialect, nil); err != nil {
+			return dialect, loxerror.RuntimeError(callToken, err.Error())
+		}
+	default:
+		return dialect, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("%v argument to 'csv.%v' must be a string or dictionary.", argPos, fnName))
+	}
+	return dialect, nil
+}
+
+// Returns the field names in the specified list
+func csvFieldNames(fieldNames *LoxList) ([]string, error) {
+	names := make([]string, 0, len(fieldNames.elements))
+	for _, element := range fieldNames.elements {
+		name, ok := element.(*LoxString)
+		if !ok {
+			return nil, loxerror.Error("CSV field names must be strings.")
+		}
+		names = append(names, name.str)
+	}
+	return names, nil
+}
+
 func (i *Interpreter) defineCSVFuncs() {
 	className := "csv"
 	csvClass := NewLoxClass(className, nil, false)
@@ -27,13 +129,105 @@ func (i *Interpreter) defineCSVFuncs() {
 		return nil, loxerror.RuntimeError(callToken, errStr)
 	}
 
+	csvFunc("dictReader", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v", argsLen))
+		}
+		var reader io.Reader
+		switch arg := args[0].(type) {
+		case *LoxFile:
+			if !arg.isRead() {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Cannot create CSV dict reader for file not in read mode.")
+			}
+			if arg.isBinary {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Cannot create CSV dict reader for file in binary read mode.")
+			}
+			reader = arg.file
+		case *LoxString:
+			reader = strings.NewReader(arg.str)
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'csv.dictReader' must be a file or string.")
+		}
+		dialect := defaultCSVDialect
+		var fieldNames []string
+		if argsLen == 2 {
+			options, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'csv.dictReader' must be a dictionary.")
+			}
+			err := csvOptions(options, &dialect, func(name string, value any) (bool, error) {
+				if name != "fieldNames" {
+					return false, nil
+				}
+				switch value := value.(type) {
+				case nil:
+				case *LoxList:
+					var err error
+					fieldNames, err = csvFieldNames(value)
+					if err != nil {
+						return true, err
+					}
+				default:
+					return true, loxerror.Error("CSV option 'fieldNames' must be a list or nil.")
+				}
+				return true, nil
+			})
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		}
+		return NewLoxCSVDictReader(reader, dialect, fieldNames), nil
+	})
+	csvFunc("dictWriter", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v", argsLen))
+		}
+		file, ok := args[0].(*LoxFile)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'csv.dictWriter' must be a file.")
+		}
+		if !file.isWrite() && !file.isAppend() {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot create CSV dict writer for file not in write mode.")
+		}
+		if file.isBinary {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot create CSV dict writer for file in binary write mode.")
+		}
+		fieldNamesList, ok := args[1].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'csv.dictWriter' must be a list.")
+		}
+		fieldNames, err := csvFieldNames(fieldNamesList)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		dialect := defaultCSVDialect
+		if argsLen == 3 {
+			dialect, err = csvDialectArg(in.callToken, "dictWriter", "Third", args[2])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewLoxCSVDictWriter(file.file, dialect, fieldNames), nil
+	})
 	csvFunc("reader", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		argsLen := len(args)
 		if argsLen != 1 && argsLen != 2 {
 			return nil, loxerror.RuntimeError(in.callToken,
 				fmt.Sprintf("Expected 1 or 2 arguments but got %v", argsLen))
 		}
-		delimiter := ','
+		dialect := defaultCSVDialect
 		if argsLen == 2 {
 			switch args[0].(type) {
 			case *LoxFile:
@@ -42,15 +236,10 @@ func (i *Interpreter) defineCSVFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"First argument to 'csv.reader' must be a file or string.")
 			}
-			if loxStr, ok := args[1].(*LoxString); ok {
-				if utf8.RuneCountInString(loxStr.str) != 1 {
-					return nil, loxerror.RuntimeError(in.callToken,
-						"Second argument to 'csv.reader' must be a single-character string.")
-				}
-				delimiter = []rune(loxStr.str)[0]
-			} else {
-				return nil, loxerror.RuntimeError(in.callToken,
-					"Second argument to 'csv.reader' must be a string.")
+			var err error
+			dialect, err = csvDialectArg(in.callToken, "reader", "Second", args[1])
+			if err != nil {
+				return nil, err
 			}
 		}
 		switch arg := args[0].(type) {
@@ -63,9 +252,9 @@ func (i *Interpreter) defineCSVFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Cannot create CSV reader for file in binary read mode.")
 			}
-			return NewLoxCSVReaderDelimiter(arg.file, delimiter), nil
+			return NewLoxCSVReaderDialect(arg.file, dialect), nil
 		case *LoxString:
-			return NewLoxCSVReaderDelimiter(strings.NewReader(arg.str), delimiter), nil
+			return NewLoxCSVReaderDialect(strings.NewReader(arg.str), dialect), nil
 		}
 		return argMustBeType(in.callToken, "reader", "file or string")
 	})
@@ -75,7 +264,7 @@ func (i *Interpreter) defineCSVFuncs() {
 			return nil, loxerror.RuntimeError(in.callToken,
 				fmt.Sprintf("Expected 1 or 2 arguments but got %v", argsLen))
 		}
-		delimiter := ','
+		dialect := defaultCSVDialect
 		if argsLen == 2 {
 			switch args[0].(type) {
 			case *LoxFile:
@@ -83,15 +272,10 @@ func (i *Interpreter) defineCSVFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"First argument to 'csv.writer' must be a file.")
 			}
-			if loxStr, ok := args[1].(*LoxString); ok {
-				if utf8.RuneCountInString(loxStr.str) != 1 {
-					return nil, loxerror.RuntimeError(in.callToken,
-						"Second argument to 'csv.writer' must be a single-character string.")
-				}
-				delimiter = []rune(loxStr.str)[0]
-			} else {
-				return nil, loxerror.RuntimeError(in.callToken,
-					"Second argument to 'csv.writer' must be a string.")
+			var err error
+			dialect, err = csvDialectArg(in.callToken, "writer", "Second", args[1])
+			if err != nil {
+				return nil, err
 			}
 		}
 		switch arg := args[0].(type) {
@@ -104,7 +288,7 @@ func (i *Interpreter) defineCSVFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Cannot create CSV writer for file in binary write mode.")
 			}
-			return NewLoxCSVWriterDelimiter(arg.file, delimiter), nil
+			return NewLoxCSVWriterDialect(arg.file, dialect), nil
 		}
 		return argMustBeType(in.callToken, "writer", "file")
 	})
@@ -160,6 +160,22 @@ func (i *Interpreter) defineDateFuncs() {
 	dateFunc("dateNow", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 		return NewLoxDate(time.Now()), nil
 	})
+	dateFunc("fixedZone", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'Date.fixedZone' must be a string.")
+		}
+		if _, ok := args[1].(int64); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'Date.fixedZone' must be an integer.")
+		}
+		name := args[0].(*LoxString).str
+		offset := int(args[1].(int64))
+		return NewLoxTimezone(time.FixedZone(name, offset)), nil
+	})
+	dateFunc("localTimezone", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxTimezone(time.Local), nil
+	})
 	dateFunc("loopUntil", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(*LoxDate); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -217,12 +233,43 @@ func (i *Interpreter) defineDateFuncs() {
 		}
 		layout := args[0].(*LoxString).str
 		dateStr := args[1].(*LoxString).str
-		date, err := time.Parse(layout, dateStr)
+		var date time.Time
+		var err error
+		if isStrftimeFormat(layout) {
+			date, err = strptime(dateStr, layout, time.UTC)
+		} else {
+			date, err = time.Parse(layout, dateStr)
+		}
 		if err != nil {
 			return nil, loxerror.RuntimeError(in.callToken, err.Error())
 		}
 		return NewLoxDate(date), nil
 	})
+	dateFunc("parseAny", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		dateStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'Date.parseAny' must be a string.")
+		}
+		location := time.UTC
+		if argsLen == 2 {
+			var err error
+			location, err = timezoneArg(in.callToken, "Date.parseAny", args[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		date, err := parseAnyDate(dateStr.str, location)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+		}
+		return NewLoxDate(date), nil
+	})
 	dateFunc("parseDefault", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if dateStr, ok := args[0].(*LoxString); ok {
 			date, err := time.Parse(LoxDateDefaultFormat, dateStr.str)
@@ -233,6 +280,16 @@ func (i *Interpreter) defineDateFuncs() {
 		}
 		return argMustBeType(in.callToken, "parseDefault", "string")
 	})
+	dateFunc("parseZoned", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if dateStr, ok := args[0].(*LoxString); ok {
+			date, err := parseZonedDate(dateStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxDate(date), nil
+		}
+		return argMustBeType(in.callToken, "parseZoned", "string")
+	})
 	dateFunc("sleepUntil", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if loxDate, ok := args[0].(*LoxDate); ok {
 			time.Sleep(time.Until(loxDate.date))
@@ -240,6 +297,36 @@ func (i *Interpreter) defineDateFuncs() {
 		}
 		return argMustBeType(in.callToken, "sleepUntil", "date")
 	})
+	dateFunc("strptime", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'Date.strptime' must be a string.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'Date.strptime' must be a string.")
+		}
+		location := time.UTC
+		if argsLen == 3 {
+			var err error
+			location, err = timezoneArg(in.callToken, "Date.strptime", args[2])
+			if err != nil {
+				return nil, err
+			}
+		}
+		dateStr := args[0].(*LoxString).str
+		format := args[1].(*LoxString).str
+		date, err := strptime(dateStr, format, location)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+		}
+		return NewLoxDate(date), nil
+	})
 	dateFunc("time", 3, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(int64); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -286,6 +373,16 @@ func (i *Interpreter) defineDateFuncs() {
 		)
 		return NewLoxDate(date), nil
 	})
+	dateFunc("timezone", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if name, ok := args[0].(*LoxString); ok {
+			location, err := loadTimezone(name.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxTimezone(location), nil
+		}
+		return argMustBeType(in.callToken, "timezone", "string")
+	})
 	dateFunc("unix", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if seconds, ok := args[0].(int64); ok {
 			return NewLoxDate(time.Unix(seconds, 0)), nil
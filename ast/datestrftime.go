@@ -0,0 +1,500 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Returns true if the specified format string contains C-style directives
+// such as "%Y" rather than being a Go reference layout
+func isStrftimeFormat(format string) bool {
+	index := strings.IndexByte(format, '%')
+	return index >= 0 && index < len(format)-1
+}
+
+// Formats a date according to C-style directives such as "%Y-%m-%d"
+func strftime(date time.Time, format string) (string, error) {
+	var builder strings.Builder
+	for index := 0; index < len(format); index++ {
+		c := format[index]
+		if c != '%' {
+			builder.WriteByte(c)
+			continue
+		}
+		index++
+		if index >= len(format) {
+			return "", fmt.Errorf("format '%v' ends with an incomplete directive", format)
+		}
+		directive := format[index]
+		if directive == ':' && index+1 < len(format) && format[index+1] == 'z' {
+			index++
+			builder.WriteString(date.Format("-07:00"))
+			continue
+		}
+		switch directive {
+		case 'a':
+			builder.WriteString(date.Format("Mon"))
+		case 'A':
+			builder.WriteString(date.Format("Monday"))
+		case 'b', 'h':
+			builder.WriteString(date.Format("Jan"))
+		case 'B':
+			builder.WriteString(date.Format("January"))
+		case 'c':
+			builder.WriteString(date.Format("Mon Jan _2 15:04:05 2006"))
+		case 'C':
+			fmt.Fprintf(&builder, "%02d", date.Year()/100)
+		case 'd':
+			fmt.Fprintf(&builder, "%02d", date.Day())
+		case 'D':
+			builder.WriteString(date.Format("01/02/06"))
+		case 'e':
+			fmt.Fprintf(&builder, "%2d", date.Day())
+		case 'f':
+			fmt.Fprintf(&builder, "%06d", date.Nanosecond()/1000)
+		case 'F':
+			builder.WriteString(date.Format("2006-01-02"))
+		case 'G':
+			year, _ := date.ISOWeek()
+			fmt.Fprintf(&builder, "%04d", year)
+		case 'H':
+			fmt.Fprintf(&builder, "%02d", date.Hour())
+		case 'I':
+			fmt.Fprintf(&builder, "%02d", (date.Hour()+11)%12+1)
+		case 'j':
+			fmt.Fprintf(&builder, "%03d", date.YearDay())
+		case 'L':
+			fmt.Fprintf(&builder, "%03d", date.Nanosecond()/1e6)
+		case 'm':
+			fmt.Fprintf(&builder, "%02d", int(date.Month()))
+		case 'M':
+			fmt.Fprintf(&builder, "%02d", date.Minute())
+		case 'n':
+			builder.WriteByte('\n')
+		case 'N':
+			fmt.Fprintf(&builder, "%09d", date.Nanosecond())
+		case 'p':
+			builder.WriteString(date.Format("PM"))
+		case 'R':
+			builder.WriteString(date.Format("15:04"))
+		case 's':
+			builder.WriteString(strconv.FormatInt(date.Unix(), 10))
+		case 'S':
+			fmt.Fprintf(&builder, "%02d", date.Second())
+		case 't':
+			builder.WriteByte('\t')
+		case 'T':
+			builder.WriteString(date.Format("15:04:05"))
+		case 'u':
+			weekday := int(date.Weekday())
+			if weekday == 0 {
+				weekday = 7
+			}
+			builder.WriteString(strconv.Itoa(weekday))
+		case 'V':
+			_, week := date.ISOWeek()
+			fmt.Fprintf(&builder, "%02d", week)
+		case 'w':
+			builder.WriteString(strconv.Itoa(int(date.Weekday())))
+		case 'x':
+			builder.WriteString(date.Format("01/02/06"))
+		case 'X':
+			builder.WriteString(date.Format("15:04:05"))
+		case 'y':
+			fmt.Fprintf(&builder, "%02d", date.Year()%100)
+		case 'Y':
+			fmt.Fprintf(&builder, "%04d", date.Year())
+		case 'z':
+			builder.WriteString(date.Format("-0700"))
+		case 'Z':
+			builder.WriteString(date.Format("MST"))
+		case '%':
+			builder.WriteByte('%')
+		default:
+			return "", fmt.Errorf("unknown directive '%%%c' in format '%v'", directive, format)
+		}
+	}
+	return builder.String(), nil
+}
+
+// The state of a date being parsed by strptime
+type strptimeState struct {
+	str       string
+	format    string
+	pos       int
+	year      int
+	month     int
+	day       int
+	yearDay   int
+	hour      int
+	minute    int
+	second    int
+	nanos     int
+	pm        int //0 if there is no AM/PM, 1 for AM, and 2 for PM
+	location  *time.Location
+	zoneAbbr  string
+	unixTime  int64
+	hasUnix   bool
+	hasOffset bool
+}
+
+func (s *strptimeState) errorf(format string, args ...any) error {
+	return fmt.Errorf("date string '%v' does not match format '%v': %v",
+		s.str, s.format, fmt.Sprintf(format, args...))
+}
+
+// Parses an integer with between minDigits and maxDigits digits, which may
+// be preceded by spaces if padded is true
+func (s *strptimeState) number(name string, minDigits int, maxDigits int, padded bool) (int, error) {
+	if padded {
+		for s.pos < len(s.str) && s.str[s.pos] == ' ' {
+			s.pos++
+		}
+	}
+	start := s.pos
+	for s.pos < len(s.str) && s.pos-start < maxDigits && s.str[s.pos] >= '0' && s.str[s.pos] <= '9' {
+		s.pos++
+	}
+	if s.pos-start < minDigits {
+		return 0, s.errorf("expected %v at position %v", name, start)
+	}
+	value, _ := strconv.Atoi(s.str[start:s.pos])
+	return value, nil
+}
+
+// Parses one of the specified names in any case, returning its index
+func (s *strptimeState) name(kind string, names ...[]string) (int, error) {
+	rest := strings.ToLower(s.str[s.pos:])
+	for _, nameList := range names {
+		for index, name := range nameList {
+			if strings.HasPrefix(rest, strings.ToLower(name)) {
+				s.pos += len(name)
+				return index, nil
+			}
+		}
+	}
+	return 0, s.errorf("expected %v at position %v", kind, s.pos)
+}
+
+// Parses a UTC offset such as "+0530", "+05:30", "+05", or "Z"
+func (s *strptimeState) offset() error {
+	if s.pos < len(s.str) && (s.str[s.pos] == 'Z' || s.str[s.pos] == 'z') {
+		s.pos++
+		s.location = time.UTC
+		s.hasOffset = true
+		return nil
+	}
+	if s.pos >= len(s.str) || (s.str[s.pos] != '+' && s.str[s.pos] != '-') {
+		return s.errorf("expected UTC offset at position %v", s.pos)
+	}
+	start := s.pos
+	sign := 1
+	if s.str[s.pos] == '-' {
+		sign = -1
+	}
+	s.pos++
+	hours, err := s.number("UTC offset", 2, 2, false)
+	if err != nil {
+		return err
+	}
+	if s.pos < len(s.str) && s.str[s.pos] == ':' {
+		s.pos++
+	}
+	minutes := 0
+	if s.pos < len(s.str) && s.str[s.pos] >= '0' && s.str[s.pos] <= '9' {
+		if minutes, err = s.number("UTC offset", 2, 2, false); err != nil {
+			return err
+		}
+	}
+	offset := sign * (hours*3600 + minutes*60)
+	if offset == 0 {
+		s.location = time.UTC
+	} else {
+		s.location = time.FixedZone(s.str[start:s.pos], offset)
+	}
+	s.hasOffset = true
+	return nil
+}
+
+var (
+	strptimeLongMonths  = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	strptimeShortMonths = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	strptimeLongDays    = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	strptimeShortDays   = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+)
+
+func (s *strptimeState) directive(directive byte) error {
+	var err error
+	switch directive {
+	case 'a', 'A':
+		_, err = s.name("weekday name", strptimeLongDays, strptimeShortDays)
+	case 'b', 'B', 'h':
+		var month int
+		month, err = s.name("month name", strptimeLongMonths, strptimeShortMonths)
+		s.month = month + 1
+	case 'C':
+		var century int
+		century, err = s.number("century", 1, 2, false)
+		s.year = century*100 + s.year%100
+	case 'd', 'e':
+		s.day, err = s.number("day", 1, 2, true)
+	case 'D', 'x':
+		return s.sequence("%m/%d/%y")
+	case 'f', 'L', 'N':
+		start := s.pos
+		var fraction int
+		fraction, err = s.number("fractional seconds", 1, 9, false)
+		digits := s.pos - start
+		for ; digits < 9; digits++ {
+			fraction *= 10
+		}
+		s.nanos = fraction
+	case 'F':
+		return s.sequence("%Y-%m-%d")
+	case 'H':
+		s.hour, err = s.number("hour", 1, 2, true)
+	case 'I':
+		s.hour, err = s.number("hour", 1, 2, true)
+		if err == nil && (s.hour < 1 || s.hour > 12) {
+			return s.errorf("hour %v is out of range for '%%I'", s.hour)
+		}
+	case 'j':
+		s.yearDay, err = s.number("day of year", 1, 3, false)
+	case 'm':
+		s.month, err = s.number("month", 1, 2, true)
+	case 'M':
+		s.minute, err = s.number("minute", 1, 2, false)
+	case 'n', 't':
+		s.whitespace()
+	case 'p':
+		var pm int
+		pm, err = s.name("AM or PM", []string{"AM", "PM"})
+		s.pm = pm + 1
+	case 'R':
+		return s.sequence("%H:%M")
+	case 's':
+		start := s.pos
+		if s.pos < len(s.str) && s.str[s.pos] == '-' {
+			s.pos++
+		}
+		if _, err = s.number("Unix timestamp", 1, 19, false); err == nil {
+			s.unixTime, _ = strconv.ParseInt(s.str[start:s.pos], 10, 64)
+			s.hasUnix = true
+		}
+	case 'S':
+		s.second, err = s.number("second", 1, 2, false)
+	case 'T', 'X':
+		return s.sequence("%H:%M:%S")
+	case 'u', 'w':
+		_, err = s.number("weekday number", 1, 1, false)
+	case 'y':
+		var year int
+		year, err = s.number("year", 2, 2, false)
+		//Two-digit years from 69 to 99 are in the 1900s, like in C
+		if year >= 69 {
+			s.year = 1900 + year
+		} else {
+			s.year = 2000 + year
+		}
+	case 'Y':
+		start := s.pos
+		if s.pos < len(s.str) && s.str[s.pos] == '-' {
+			s.pos++
+		}
+		if _, err = s.number("year", 4, 4, false); err == nil {
+			s.year, _ = strconv.Atoi(s.str[start:s.pos])
+		}
+	case 'z':
+		return s.offset()
+	case 'Z':
+		start := s.pos
+		for s.pos < len(s.str) && unicode.IsLetter(rune(s.str[s.pos])) {
+			s.pos++
+		}
+		if s.pos == start {
+			return s.errorf("expected time zone abbreviation at position %v", start)
+		}
+		s.zoneAbbr = s.str[start:s.pos]
+	case '%':
+		if s.pos >= len(s.str) || s.str[s.pos] != '%' {
+			return s.errorf("expected '%%' at position %v", s.pos)
+		}
+		s.pos++
+	default:
+		return fmt.Errorf("unknown directive '%%%c' in format '%v'", directive, s.format)
+	}
+	return err
+}
+
+func (s *strptimeState) whitespace() {
+	for s.pos < len(s.str) && unicode.IsSpace(rune(s.str[s.pos])) {
+		s.pos++
+	}
+}
+
+// Parses the string against the specified format from the current position
+func (s *strptimeState) sequence(format string) error {
+	for index := 0; index < len(format); index++ {
+		c := format[index]
+		switch {
+		case c == '%':
+			index++
+			if index >= len(format) {
+				return fmt.Errorf("format '%v' ends with an incomplete directive", s.format)
+			}
+			if format[index] == ':' && index+1 < len(format) && format[index+1] == 'z' {
+				index++
+				if err := s.offset(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.directive(format[index]); err != nil {
+				return err
+			}
+		case unicode.IsSpace(rune(c)):
+			//Whitespace in the format matches any amount of whitespace
+			s.whitespace()
+		default:
+			if s.pos >= len(s.str) || unicode.ToLower(rune(s.str[s.pos])) != unicode.ToLower(rune(c)) {
+				if s.pos >= len(s.str) {
+					return s.errorf("expected '%c' at end of string", c)
+				}
+				return s.errorf("expected '%c' at position %v but found '%c'", c, s.pos, s.str[s.pos])
+			}
+			s.pos++
+		}
+	}
+	return nil
+}
+
+// Parses a date string according to C-style directives such as "%Y-%m-%d".
+// Dates without a UTC offset or time zone abbreviation are in the
+// specified location
+func strptime(str string, format string, location *time.Location) (time.Time, error) {
+	s := &strptimeState{str: str, format: format, year: 1900, month: 1, day: 1}
+	if err := s.sequence(format); err != nil {
+		return time.Time{}, err
+	}
+	if s.pos < len(s.str) {
+		return time.Time{}, s.errorf("unexpected text '%v' at position %v", s.str[s.pos:], s.pos)
+	}
+	if s.hasUnix {
+		return time.Unix(s.unixTime, 0).In(location), nil
+	}
+	if s.pm != 0 {
+		if s.hour > 12 {
+			return time.Time{}, s.errorf("hour %v cannot be used with AM or PM", s.hour)
+		}
+		s.hour %= 12
+		if s.pm == 2 {
+			s.hour += 12
+		}
+	}
+	if s.month < 1 || s.month > 12 {
+		return time.Time{}, s.errorf("month %v is out of range", s.month)
+	}
+	if s.hour > 23 || s.minute > 59 || s.second > 60 {
+		return time.Time{}, s.errorf("time %02d:%02d:%02d is out of range", s.hour, s.minute, s.second)
+	}
+	if s.yearDay > 0 {
+		if s.yearDay > 366 {
+			return time.Time{}, s.errorf("day of year %v is out of range", s.yearDay)
+		}
+		date := time.Date(s.year, time.January, s.yearDay, 0, 0, 0, 0, time.UTC)
+		if date.Year() != s.year {
+			return time.Time{}, s.errorf("day of year %v is out of range", s.yearDay)
+		}
+		s.month, s.day = int(date.Month()), date.Day()
+	}
+	daysInMonth := time.Date(s.year, time.Month(s.month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if s.day < 1 || s.day > daysInMonth {
+		return time.Time{}, s.errorf("day %v is out of range", s.day)
+	}
+	if !s.hasOffset {
+		if s.zoneAbbr != "" {
+			zoneLocation, err := strptimeZone(s, location)
+			if err != nil {
+				return time.Time{}, err
+			}
+			location = zoneLocation
+		}
+		return time.Date(s.year, time.Month(s.month), s.day,
+			s.hour, s.minute, s.second, s.nanos, location), nil
+	}
+	return time.Date(s.year, time.Month(s.month), s.day,
+		s.hour, s.minute, s.second, s.nanos, s.location), nil
+}
+
+// Returns the location that the parsed time zone abbreviation refers to,
+// which is UTC for "UTC" and "GMT" or the specified location if it uses
+// the abbreviation at the parsed date
+func strptimeZone(s *strptimeState, location *time.Location) (*time.Location, error) {
+	upper := strings.ToUpper(s.zoneAbbr)
+	if upper == "UTC" || upper == "GMT" || upper == "Z" {
+		return time.UTC, nil
+	}
+	date := time.Date(s.year, time.Month(s.month), s.day, s.hour, s.minute, s.second, 0, location)
+	if abbreviation, _ := date.Zone(); strings.EqualFold(abbreviation, s.zoneAbbr) {
+		return location, nil
+	}
+	return nil, s.errorf("unknown time zone abbreviation '%v' in time zone '%v'", s.zoneAbbr, location)
+}
+
+// The layouts tried in order by parseAnyDate
+var fuzzyDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"20060102",
+	"2006-01",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"January 2, 2006 15:04:05",
+	"January 2, 2006",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"Monday, January 2, 2006",
+	"Mon Jan 2 2006",
+}
+
+// Parses a date string in any of a number of common formats, including
+// ISO 8601, RFC 3339, and RFC 2822. Dates without a UTC offset are in the
+// specified location
+func parseAnyDate(str string, location *time.Location) (time.Time, error) {
+	trimmed := strings.TrimSpace(str)
+	for _, layout := range fuzzyDateLayouts {
+		if date, err := time.ParseInLocation(layout, trimmed, location); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date string '%v' is not in a recognized format", str)
+}
@@ -0,0 +1,137 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineDecimalFuncs() {
+	className := "decimal"
+	decimalClass := NewLoxClass(className, nil, false)
+	decimalFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native decimal fn %v at %p>", name, &s)
+		}
+		decimalClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'decimal.%v' must be %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	//Converts the specified value to a decimal, rounding rationals to the
+	//current precision
+	valueToDecimal := func(callToken *token.Token, fnName string, value any) (*LoxDecimal, error) {
+		switch value := value.(type) {
+		case *LoxString:
+			decimal, ok := parseDecimal(value.str)
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					fmt.Sprintf("Failed to convert '%v' to decimal.", value.str))
+			}
+			return decimal, nil
+		case *LoxRational:
+			decimal := decimalFromRat(value.rat, decimalPrecision.Load(),
+				decimalRounding(decimalDefaultRounding.Load()))
+			return decimal.trimTo(0), nil
+		}
+		decimal, ok := toDecimal(value)
+		if ok {
+			return decimal, nil
+		}
+		switch value.(type) {
+		case float64, *big.Float:
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Cannot convert %v to decimal.", getResult(value, value, true)))
+		}
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Argument to '%v' must be an integer, bigint, float, bigfloat, rational, decimal, or string.", fnName))
+	}
+
+	roundingModes := list.NewListCap[any](int64(len(decimalRoundingNames)))
+	for _, name := range decimalRoundingNames {
+		roundingModes.Add(NewLoxStringQuote(name))
+	}
+	decimalClass.classProperties["roundingModes"] = NewLoxList(roundingModes)
+	decimalFunc("new", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen < 1 || argsLen > 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 to 3 arguments but got %v.", argsLen))
+		}
+		decimal, err := valueToDecimal(in.callToken, "decimal.new", args[0])
+		if err != nil {
+			return nil, err
+		}
+		if argsLen == 1 {
+			return decimal, nil
+		}
+		places, ok := args[1].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'decimal.new' must be an integer.")
+		}
+		if places < 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Second argument to 'decimal.new' cannot be negative.")
+		}
+		rounding := decimalRounding(decimalDefaultRounding.Load())
+		if argsLen == 3 {
+			rounding, err = decimalRoundingArg(in.callToken, "decimal.new", args[2])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return decimal.round(places, rounding), nil
+	})
+	decimalFunc("precision", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return decimalPrecision.Load(), nil
+	})
+	decimalFunc("rounding", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxStringQuote(decimalRoundingNames[decimalDefaultRounding.Load()]), nil
+	})
+	decimalFunc("setPrecision", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if places, ok := args[0].(int64); ok {
+			if places < 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					"Argument to 'decimal.setPrecision' cannot be negative.")
+			}
+			decimalPrecision.Store(places)
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "setPrecision", "an integer")
+	})
+	decimalFunc("setRounding", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		rounding, err := decimalRoundingArg(in.callToken, "decimal.setRounding", args[0])
+		if err != nil {
+			return nil, err
+		}
+		decimalDefaultRounding.Store(int64(rounding))
+		return nil, nil
+	})
+	decimalFunc("sum", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		iterable, ok := args[0].(interfaces.Iterable)
+		if !ok {
+			return argMustBeType(in.callToken, "sum", "an iterable")
+		}
+		total := NewLoxDecimal(new(big.Int), 0)
+		it := iterable.Iterator()
+		for it.HasNext() {
+			decimal, err := valueToDecimal(in.callToken, "decimal.sum", it.Next())
+			if err != nil {
+				return nil, err
+			}
+			total = total.add(decimal)
+		}
+		return total, nil
+	})
+
+	i.globals.Define(className, decimalClass)
+}
@@ -0,0 +1,198 @@
+package ast
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A DNS lookup method that is defined both in the dns class and on DNS
+// resolver objects, whose arguments are all strings
+type dnsLookup struct {
+	argNames []string
+	lookup   func(ctx context.Context, resolver *net.Resolver, args []string) (any, error)
+}
+
+func dnsStrList(strs []string) *LoxList {
+	result := list.NewListCap[any](int64(len(strs)))
+	for _, str := range strs {
+		result.Add(NewLoxStringQuote(str))
+	}
+	return NewLoxList(result)
+}
+
+var dnsLookups = map[string]dnsLookup{
+	"lookupAddr": {[]string{"address"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		names, err := resolver.LookupAddr(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return dnsStrList(names), nil
+	}},
+	"lookupCNAME": {[]string{"host"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		cname, err := resolver.LookupCNAME(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(cname), nil
+	}},
+	"lookupHost": {[]string{"host"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		addrs, err := resolver.LookupHost(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return dnsStrList(addrs), nil
+	}},
+	"lookupMX": {[]string{"domain"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		records, err := resolver.LookupMX(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		result := list.NewListCap[any](int64(len(records)))
+		for _, record := range records {
+			dict := EmptyLoxDict()
+			dict.setKeyValue(NewLoxString("host", '\''), NewLoxStringQuote(record.Host))
+			dict.setKeyValue(NewLoxString("pref", '\''), int64(record.Pref))
+			result.Add(dict)
+		}
+		return NewLoxList(result), nil
+	}},
+	"lookupNS": {[]string{"domain"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		records, err := resolver.LookupNS(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		hosts := make([]string, 0, len(records))
+		for _, record := range records {
+			hosts = append(hosts, record.Host)
+		}
+		return dnsStrList(hosts), nil
+	}},
+	"lookupSRV": {[]string{"service", "proto", "name"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		_, records, err := resolver.LookupSRV(ctx, args[0], args[1], args[2])
+		if err != nil {
+			return nil, err
+		}
+		result := list.NewListCap[any](int64(len(records)))
+		for _, record := range records {
+			dict := EmptyLoxDict()
+			dict.setKeyValue(NewLoxString("target", '\''), NewLoxStringQuote(record.Target))
+			dict.setKeyValue(NewLoxString("port", '\''), int64(record.Port))
+			dict.setKeyValue(NewLoxString("priority", '\''), int64(record.Priority))
+			dict.setKeyValue(NewLoxString("weight", '\''), int64(record.Weight))
+			result.Add(dict)
+		}
+		return NewLoxList(result), nil
+	}},
+	"lookupTXT": {[]string{"domain"}, func(ctx context.Context, resolver *net.Resolver, args []string) (any, error) {
+		records, err := resolver.LookupTXT(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return dnsStrList(records), nil
+	}},
+}
+
+// Returns a native function that calls the DNS lookup method with the
+// specified name using the specified resolver, which fails if the lookup
+// takes longer than the specified timeout unless it is 0
+func dnsLookupFunc(
+	fnName string,
+	typeName string,
+	resolver *net.Resolver,
+	timeout time.Duration,
+	callToken func(in *Interpreter) *token.Token,
+) *struct{ ProtoLoxCallable } {
+	method := dnsLookups[fnName]
+	s := &struct{ ProtoLoxCallable }{}
+	s.arityMethod = func() int { return len(method.argNames) }
+	s.callMethod = func(in *Interpreter, args list.List[any]) (any, error) {
+		strArgs := make([]string, len(args))
+		for index, arg := range args {
+			str, ok := arg.(*LoxString)
+			if !ok {
+				if len(args) == 1 {
+					return nil, loxerror.RuntimeError(callToken(in),
+						fmt.Sprintf("Argument to '%v.%v' must be a string.", typeName, fnName))
+				}
+				ordinals := []string{"First", "Second", "Third"}
+				return nil, loxerror.RuntimeError(callToken(in),
+					fmt.Sprintf("%v argument to '%v.%v' must be a string.",
+						ordinals[index], typeName, fnName))
+			}
+			strArgs[index] = str.str
+		}
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		result, err := method.lookup(ctx, resolver, strArgs)
+		if err != nil {
+			return nil, loxerror.RuntimeError(callToken(in), err.Error())
+		}
+		return result, nil
+	}
+	s.stringMethod = func() string {
+		return fmt.Sprintf("<native %v fn %v at %p>", typeName, fnName, s)
+	}
+	return s
+}
+
+func (i *Interpreter) defineDNSFuncs() {
+	className := "dns"
+	dnsClass := NewLoxClass(className, nil, false)
+	dnsFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native dns fn %v at %p>", name, &s)
+		}
+		dnsClass.classProperties[name] = s
+	}
+
+	for name := range dnsLookups {
+		dnsClass.classProperties[name] = dnsLookupFunc(name, className, net.DefaultResolver, 0,
+			func(in *Interpreter) *token.Token {
+				return in.callToken
+			})
+	}
+	dnsFunc("resolver", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		address, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'dns.resolver' must be a string.")
+		}
+		timeout := dnsDefaultTimeout
+		if argsLen == 2 {
+			switch seconds := args[1].(type) {
+			case int64:
+				timeout = time.Duration(seconds) * time.Second
+			case float64:
+				timeout = time.Duration(seconds * float64(time.Second))
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'dns.resolver' must be an integer or float.")
+			}
+			if timeout <= 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'dns.resolver' must be positive.")
+			}
+		}
+		return NewLoxDNSResolver(address.str, timeout), nil
+	})
+
+	i.globals.Define(className, dnsClass)
+}
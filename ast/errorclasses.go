@@ -0,0 +1,140 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The error that is returned when an instance of an error class is thrown,
+// which keeps the instance so that catch clauses can check its class
+type thrownError struct {
+	instance *LoxInstance
+	err      error
+}
+
+func (t *thrownError) Error() string {
+	return t.err.Error()
+}
+
+func (t *thrownError) Unwrap() error {
+	return t.err
+}
+
+// Returns true if the specified class is the specified ancestor class or
+// inherits from it
+func isSubclassOf(class *LoxClass, ancestor *LoxClass) bool {
+	for cls := class; cls != nil; cls = cls.superClass {
+		if cls == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Interpreter) isErrorClass(class *LoxClass) bool {
+	return isSubclassOf(class, i.errorClasses["Error"])
+}
+
+// Returns the error class that the specified runtime error belongs to
+func (i *Interpreter) errorClassOf(err error) *LoxClass {
+	var thrown *thrownError
+	if errors.As(err, &thrown) {
+		return thrown.instance.class
+	}
+	var kindErr *loxerror.KindError
+	if errors.As(err, &kindErr) {
+		if class, ok := i.errorClasses[kindErr.Kind]; ok {
+			return class
+		}
+	}
+	return i.errorClasses["Error"]
+}
+
+// Returns the value that a catch clause binds to its variable for the
+// specified runtime error. Thrown instances of error classes are bound as
+// they are, and other runtime errors are bound as error objects, unless the
+// catch clause is typed, in which case they are bound as new instances of
+// the error class that they belong to.
+func (i *Interpreter) caughtErrorValue(err error, typed bool) any {
+	var thrown *thrownError
+	if errors.As(err, &thrown) {
+		return thrown.instance
+	}
+	if !typed {
+		return NewLoxError(err)
+	}
+	instance := NewLoxInstance(i.errorClassOf(err))
+	instance.fields["message"] = NewLoxString(loxerror.Message(err), '\'')
+	return instance
+}
+
+func (i *Interpreter) defineErrorClasses() {
+	i.errorClasses = make(map[string]*LoxClass)
+	errorClass := NewLoxClass("Error", nil, true)
+	errorClass.isBuiltin = true
+	errorInit := &struct{ ProtoLoxCallable }{}
+	errorInit.arityMethod = func() int { return -1 }
+	errorInit.callMethod = func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args) - 1
+		instance := args[0].(*LoxInstance)
+		switch argsLen {
+		case 0:
+			instance.fields["message"] = EmptyLoxString()
+		case 1:
+			message, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, in.callToken,
+					fmt.Sprintf("Argument to '%v' must be a string.", instance.class.name))
+			}
+			instance.fields["message"] = message
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		return nil, nil
+	}
+	errorInit.stringMethod = func() string {
+		return fmt.Sprintf("<fn init at %p>", errorInit)
+	}
+	errorClass.instanceFields["init"] = errorInit
+	i.errorClasses[errorClass.name] = errorClass
+	i.globals.Define(errorClass.name, errorClass)
+
+	for _, name := range []string{
+		loxerror.AssertionError,
+		loxerror.AuthError,
+		loxerror.IOError,
+		loxerror.IndexError,
+		loxerror.KeyError,
+		loxerror.NameError,
+		loxerror.RecursionError,
+		loxerror.TypeError,
+		loxerror.ValueError,
+		loxerror.ZeroDivisionError,
+	} {
+		class := NewLoxClass(name, errorClass, true)
+		i.errorClasses[name] = class
+		i.globals.Define(name, class)
+	}
+}
+
+// Returns the error to throw for the specified instance of an error class,
+// whose message is the name of the instance's class followed by the
+// instance's message field
+func newThrownError(throwToken *token.Token, instance *LoxInstance) error {
+	message := instance.class.name
+	if field, ok := instance.fields["message"]; ok {
+		fieldStr := getResult(field, field, true)
+		if fieldStr != "" {
+			message += ": " + fieldStr
+		}
+	}
+	return &thrownError{
+		instance: instance,
+		err:      loxerror.RuntimeError(throwToken, message),
+	}
+}
@@ -0,0 +1,257 @@
+package ast
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var fakerFirstNames = []string{
+	"Aaron", "Abigail", "Adam", "Alice", "Amelia", "Andrew", "Anna", "Benjamin",
+	"Brian", "Caleb", "Charlotte", "Chloe", "Daniel", "David", "Dylan", "Elijah",
+	"Elizabeth", "Emily", "Emma", "Ethan", "Evelyn", "Gabriel", "Grace", "Hannah",
+	"Henry", "Isaac", "Isabella", "Jack", "James", "Jasmine", "John", "Joseph",
+	"Julia", "Kevin", "Laura", "Liam", "Lucas", "Lucy", "Madison", "Maria",
+	"Mason", "Mia", "Michael", "Natalie", "Noah", "Olivia", "Owen", "Rachel",
+	"Ryan", "Samuel", "Sarah", "Sophia", "Thomas", "Victoria", "William", "Zoe",
+}
+
+var fakerLastNames = []string{
+	"Adams", "Allen", "Anderson", "Baker", "Brown", "Campbell", "Carter", "Clark",
+	"Collins", "Davis", "Edwards", "Evans", "Garcia", "Gonzalez", "Green", "Hall",
+	"Harris", "Hernandez", "Hill", "Jackson", "Johnson", "Jones", "King", "Lee",
+	"Lewis", "Lopez", "Martin", "Martinez", "Miller", "Mitchell", "Moore", "Nelson",
+	"Nguyen", "Parker", "Perez", "Phillips", "Roberts", "Robinson", "Rodriguez", "Scott",
+	"Smith", "Taylor", "Thomas", "Thompson", "Turner", "Walker", "White", "Williams",
+	"Wilson", "Wright", "Young",
+}
+
+var fakerStreetNames = []string{
+	"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake",
+	"Hill", "Park", "Sunset", "Lincoln", "Jackson", "Church", "River", "Spring",
+	"Highland", "Forest", "Meadow", "Willow",
+}
+
+var fakerStreetSuffixes = []string{
+	"Street", "Avenue", "Road", "Boulevard", "Lane", "Drive", "Court", "Way", "Place",
+}
+
+var fakerCities = []string{
+	"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton",
+	"Fairview", "Salem", "Madison", "Georgetown", "Arlington", "Ashland",
+	"Burlington", "Manchester", "Oxford", "Milton", "Newport", "Dover",
+	"Hudson", "Kingston",
+}
+
+var fakerStates = []string{
+	"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "FL", "GA",
+	"HI", "ID", "IL", "IN", "IA", "KS", "KY", "LA", "ME", "MD",
+	"MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ",
+	"NM", "NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC",
+	"SD", "TN", "TX", "UT", "VT", "VA", "WA", "WV", "WI", "WY",
+}
+
+var fakerCountries = []string{
+	"Argentina", "Australia", "Brazil", "Canada", "Chile", "China", "Egypt",
+	"France", "Germany", "India", "Ireland", "Italy", "Japan", "Kenya",
+	"Mexico", "Netherlands", "New Zealand", "Nigeria", "Norway", "Poland",
+	"Portugal", "South Korea", "Spain", "Sweden", "United Kingdom", "United States",
+}
+
+var fakerCompanySuffixes = []string{
+	"Inc.", "LLC", "Group", "and Sons", "Ltd.", "Co.", "Industries", "Holdings",
+}
+
+var fakerDomains = []string{
+	"example.com", "example.net", "example.org",
+}
+
+var fakerLoremWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+	"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore",
+	"magna", "aliqua", "enim", "ad", "minim", "veniam", "quis", "nostrud",
+	"exercitation", "ullamco", "laboris", "nisi", "aliquip", "ex", "ea", "commodo",
+	"consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+	"velit", "esse", "cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint",
+	"occaecat", "cupidatat", "non", "proident", "sunt", "culpa", "qui", "officia",
+	"deserunt", "mollit", "anim", "id", "est", "laborum",
+}
+
+func (i *Interpreter) defineFakerFuncs() {
+	className := "faker"
+	fakerClass := NewLoxClass(className, nil, false)
+	fakerFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native faker fn %v at %p>", name, &s)
+		}
+		fakerClass.classProperties[name] = s
+	}
+	argMustBeTypeAn := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'faker.%v' must be an %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	pick := func(choices []string) string {
+		return choices[random.Intn(len(choices))]
+	}
+	digits := func(n int) string {
+		var builder strings.Builder
+		for i := 0; i < n; i++ {
+			builder.WriteByte(byte('0' + random.Intn(10)))
+		}
+		return builder.String()
+	}
+	capitalize := func(str string) string {
+		if len(str) == 0 {
+			return str
+		}
+		return strings.ToUpper(str[:1]) + str[1:]
+	}
+	words := func(n int) []string {
+		result := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			result = append(result, pick(fakerLoremWords))
+		}
+		return result
+	}
+	sentence := func() string {
+		return capitalize(strings.Join(words(4+random.Intn(9)), " ")) + "."
+	}
+	paragraph := func() string {
+		sentences := make([]string, 0, 5)
+		for i := 3 + random.Intn(3); i > 0; i-- {
+			sentences = append(sentences, sentence())
+		}
+		return strings.Join(sentences, " ")
+	}
+	streetAddress := func() string {
+		return fmt.Sprintf("%v %v %v",
+			1+random.Intn(9999),
+			pick(fakerStreetNames),
+			pick(fakerStreetSuffixes),
+		)
+	}
+	countFunc := func(name string, generate func(int) any) {
+		fakerFunc(name, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			switch argsLen {
+			case 0:
+				return generate(-1), nil
+			case 1:
+				if count, ok := args[0].(int64); ok {
+					if count < 0 {
+						return nil, loxerror.RuntimeError(in.callToken,
+							fmt.Sprintf("Argument to 'faker.%v' cannot be negative.", name))
+					}
+					return generate(int(count)), nil
+				}
+				return argMustBeTypeAn(in.callToken, name, "integer")
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+		})
+	}
+	stringFunc := func(name string, generate func() string) {
+		fakerFunc(name, 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(generate()), nil
+		})
+	}
+
+	stringFunc("address", func() string {
+		return fmt.Sprintf("%v, %v, %v %v",
+			streetAddress(),
+			pick(fakerCities),
+			pick(fakerStates),
+			digits(5),
+		)
+	})
+	fakerFunc("bool", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return random.Intn(2) == 1, nil
+	})
+	stringFunc("city", func() string {
+		return pick(fakerCities)
+	})
+	stringFunc("company", func() string {
+		return pick(fakerLastNames) + " " + pick(fakerCompanySuffixes)
+	})
+	stringFunc("country", func() string {
+		return pick(fakerCountries)
+	})
+	stringFunc("email", func() string {
+		return fmt.Sprintf("%v.%v%v@%v",
+			strings.ToLower(pick(fakerFirstNames)),
+			strings.ToLower(pick(fakerLastNames)),
+			random.Intn(100),
+			pick(fakerDomains),
+		)
+	})
+	stringFunc("firstName", func() string {
+		return pick(fakerFirstNames)
+	})
+	stringFunc("ipv4", func() string {
+		return fmt.Sprintf("%v.%v.%v.%v",
+			1+random.Intn(254), random.Intn(256), random.Intn(256), 1+random.Intn(254))
+	})
+	stringFunc("lastName", func() string {
+		return pick(fakerLastNames)
+	})
+	countFunc("lorem", func(count int) any {
+		if count < 0 {
+			count = 3
+		}
+		paragraphs := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			paragraphs = append(paragraphs, paragraph())
+		}
+		return NewLoxStringQuote(strings.Join(paragraphs, "\n\n"))
+	})
+	stringFunc("name", func() string {
+		return pick(fakerFirstNames) + " " + pick(fakerLastNames)
+	})
+	stringFunc("paragraph", paragraph)
+	stringFunc("phone", func() string {
+		return fmt.Sprintf("(%v%v) %v%v-%v",
+			2+random.Intn(8), digits(2), 2+random.Intn(8), digits(2), digits(4))
+	})
+	fakerFunc("seed", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if seed, ok := args[0].(int64); ok {
+			random.Seed(seed)
+			return nil, nil
+		}
+		return argMustBeTypeAn(in.callToken, "seed", "integer")
+	})
+	stringFunc("sentence", sentence)
+	stringFunc("state", func() string {
+		return pick(fakerStates)
+	})
+	stringFunc("streetAddress", streetAddress)
+	stringFunc("username", func() string {
+		return strings.ToLower(pick(fakerFirstNames)) + "_" +
+			strings.ToLower(pick(fakerLastNames)) + digits(2)
+	})
+	countFunc("words", func(count int) any {
+		if count < 0 {
+			count = 3
+		}
+		wordsList := list.NewListCap[any](int64(count))
+		for _, word := range words(count) {
+			wordsList.Add(NewLoxStringQuote(word))
+		}
+		return NewLoxList(wordsList)
+	})
+	stringFunc("zipCode", func() string {
+		return digits(5)
+	})
+
+	i.globals.Define(className, fakerClass)
+}
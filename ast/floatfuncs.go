@@ -37,7 +37,7 @@ func (i *Interpreter) defineFloatFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			result, resultErr := strconv.ParseFloat(loxStr.str, 64)
 			if resultErr != nil {
-				return nil, loxerror.RuntimeError(in.callToken,
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
 					fmt.Sprintf("Failed to convert '%v' to float.", loxStr.str))
 			}
 			return result, nil
@@ -0,0 +1,514 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/scanner"
+	"github.com/AlanLuu/lox/token"
+)
+
+const formatIndent = "    "
+
+type formatter struct {
+	buf          bytes.Buffer
+	indent       int
+	classMembers map[*token.Token][]classMember
+}
+
+func Format(source string) (string, error) {
+	sc := scanner.NewScanner(source)
+	sc.KeepComments = true
+	scanErr := sc.ScanTokens()
+	if scanErr != nil {
+		return "", scanErr
+	}
+	parser := newFormatParser(sc.Tokens)
+	statements, parseErr := parser.Parse()
+	if parseErr != nil {
+		return "", parseErr
+	}
+
+	f := &formatter{classMembers: parser.classMembers}
+	if strings.HasPrefix(source, "#!") {
+		shebang, _, _ := strings.Cut(source, "\n")
+		f.buf.WriteString(strings.TrimRight(shebang, " \t\r") + "\n")
+	}
+	f.statements(statements)
+	return f.buf.String(), nil
+}
+
+func (f *formatter) write(strs ...string) {
+	for _, str := range strs {
+		f.buf.WriteString(str)
+	}
+}
+
+func (f *formatter) writeIndent() {
+	for i := 0; i < f.indent; i++ {
+		f.buf.WriteString(formatIndent)
+	}
+}
+
+func (f *formatter) statements(statements list.List[Stmt]) {
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case BlankLine:
+			f.buf.WriteByte('\n')
+		case Comment:
+			if statement.Trailing && f.buf.Len() > 0 {
+				f.buf.Truncate(f.buf.Len() - 1)
+				f.write(" ", statement.Text, "\n")
+			} else {
+				f.writeIndent()
+				f.write(statement.Text, "\n")
+			}
+		default:
+			f.writeIndent()
+			f.stmt(statement)
+			f.buf.WriteByte('\n')
+		}
+	}
+}
+
+func (f *formatter) block(statements list.List[Stmt]) {
+	if len(statements) == 0 {
+		f.write("{}")
+		return
+	}
+	f.write("{\n")
+	f.indent++
+	f.statements(statements)
+	f.indent--
+	f.writeIndent()
+	f.write("}")
+}
+
+func (f *formatter) stmt(statement Stmt) {
+	switch statement := statement.(type) {
+	case Assert:
+		f.write("assert ")
+		f.expr(statement.Value)
+		f.write(";")
+	case Block:
+		f.block(statement.Statements)
+	case Break:
+		f.write("break;")
+	case Class:
+		f.class(statement)
+	case Continue:
+		f.write("continue;")
+	case DoWhile:
+		f.write("do ")
+		f.stmt(statement.Body)
+		f.write(" while (")
+		f.expr(statement.Condition)
+		f.write(");")
+	case Enum:
+		f.write("enum ", statement.Name.Lexeme, " {")
+		if len(statement.Members) > 0 {
+			f.write("\n")
+			f.indent++
+			for index, member := range statement.Members {
+				f.writeIndent()
+				f.write(member.Lexeme)
+				if index < len(statement.Members)-1 {
+					f.write(",")
+				}
+				f.write("\n")
+			}
+			f.indent--
+			f.writeIndent()
+		}
+		f.write("}")
+	case Expression:
+		f.expr(statement.Expression)
+		f.write(";")
+	case For:
+		f.write("for (")
+		if statement.Initializer != nil {
+			f.stmt(statement.Initializer)
+		} else {
+			f.write(";")
+		}
+		if statement.Condition != nil {
+			f.write(" ")
+			f.expr(statement.Condition)
+		}
+		f.write(";")
+		if statement.Increment != nil {
+			f.write(" ")
+			f.expr(statement.Increment)
+		}
+		f.write(") ")
+		f.stmt(statement.Body)
+	case ForEach:
+		f.write("foreach (var ", statement.VariableName.Lexeme, " in ")
+		f.expr(statement.Iterable)
+		f.write(") ")
+		f.stmt(statement.Body)
+	case Function:
+		f.write("fun ", statement.Name.Lexeme)
+		f.function(statement.Function)
+	case If:
+		f.write("if (")
+		f.expr(statement.Condition)
+		f.write(") ")
+		f.stmt(statement.ThenBranch)
+		if statement.ElseBranch != nil {
+			f.write(" else ")
+			f.stmt(statement.ElseBranch)
+		}
+	case Import:
+		f.write("import ")
+		f.expr(statement.ImportFile)
+		if statement.ImportNamespace != "" {
+			f.write(" as ", statement.ImportNamespace)
+		}
+		f.write(";")
+	case Loop:
+		f.write("loop ")
+		f.stmt(statement.LoopBlock)
+	case Print:
+		if statement.NewLine {
+			f.write("print ")
+		} else {
+			f.write("put ")
+		}
+		f.expr(statement.Expression)
+		f.write(";")
+	case Repeat:
+		f.write("repeat (")
+		f.expr(statement.Expression)
+		f.write(") ")
+		f.stmt(statement.Body)
+	case Return:
+		f.write("return")
+		if statement.Value != nil {
+			f.write(" ")
+			f.expr(statement.Value)
+		}
+		f.write(";")
+	case Throw:
+		f.write("throw ")
+		f.expr(statement.Value)
+		f.write(";")
+	case TryCatchFinally:
+		f.write("try ")
+		f.stmt(statement.TryBlock)
+		for _, catchClause := range statement.CatchClauses {
+			f.write(" catch ")
+			if catchClause.Name != nil {
+				f.write("(", catchClause.Name.Lexeme)
+				if catchClause.ErrorClass != nil {
+					f.write(": ", catchClause.ErrorClass.Name.Lexeme)
+				}
+				f.write(") ")
+			}
+			f.stmt(catchClause.Block)
+		}
+		if statement.FinallyBlock != nil {
+			f.write(" finally ")
+			f.stmt(statement.FinallyBlock)
+		}
+	case Var:
+		f.write("var ", statement.Name.Lexeme)
+		if statement.Initializer != nil {
+			f.write(" = ")
+			f.expr(statement.Initializer)
+		}
+		f.write(";")
+	case While:
+		f.write("while (")
+		f.expr(statement.Condition)
+		f.write(") ")
+		f.stmt(statement.Body)
+	default:
+		panic(fmt.Sprintf("formatter: unknown statement type %T", statement))
+	}
+}
+
+func (f *formatter) class(class Class) {
+	if !class.CanInstantiate {
+		f.write("static ")
+	}
+	f.write("class ", class.Name.Lexeme)
+	if class.SuperClass != nil {
+		f.write(" < ", class.SuperClass.Name.Lexeme)
+	}
+	f.write(" {")
+
+	members, ok := f.classMembers[class.Name]
+	if !ok {
+		members = sortedClassMembers(class)
+	}
+	if len(members) == 1 && len(members[0].comments) == 0 {
+		f.write("}")
+		return
+	}
+	f.write("\n")
+	f.indent++
+	for _, member := range members {
+		f.statements(member.comments)
+		if member.name == "" {
+			continue
+		}
+		f.writeIndent()
+		if member.isStatic {
+			f.write("static ")
+		}
+		f.write(member.name)
+		switch {
+		case member.isField && member.isStatic:
+			f.write(" = ")
+			f.expr(class.ClassFields[member.name])
+			f.write(";")
+		case member.isField:
+			f.write(" = ")
+			f.expr(class.InstanceFields[member.name])
+			f.write(";")
+		case member.isStatic:
+			f.function(class.ClassMethods[member.method].Function)
+		default:
+			f.function(class.Methods[member.method].Function)
+		}
+		f.write("\n")
+	}
+	f.indent--
+	f.writeIndent()
+	f.write("}")
+}
+
+func sortedClassMembers(class Class) []classMember {
+	members := []classMember{}
+	fields := func(fieldMap map[string]Expr, isStatic bool) {
+		names := make([]string, 0, len(fieldMap))
+		for name := range fieldMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			members = append(members, classMember{name: name, isStatic: isStatic, isField: true})
+		}
+	}
+	fields(class.ClassFields, true)
+	fields(class.InstanceFields, false)
+	for index, method := range class.ClassMethods {
+		members = append(members, classMember{name: method.Name.Lexeme, isStatic: true, method: index})
+	}
+	for index, method := range class.Methods {
+		members = append(members, classMember{name: method.Name.Lexeme, method: index})
+	}
+	return append(members, classMember{})
+}
+
+func (f *formatter) function(function FunctionExpr) {
+	f.write("(")
+	for index, param := range function.Params {
+		if index > 0 {
+			f.write(", ")
+		}
+		if index == function.VarArgPos {
+			f.write("...")
+		}
+		f.write(param.Lexeme)
+		if defaultValue := function.Defaults[index]; defaultValue != nil {
+			f.write(" = ")
+			f.expr(defaultValue)
+		}
+	}
+	f.write(") ")
+	if len(function.Body) == 1 {
+		//Arrow functions are parsed into a single return statement
+		//without a "return" keyword
+		if returnStmt, ok := function.Body[0].(Return); ok && returnStmt.Keyword == nil {
+			f.write("=> ")
+			f.expr(returnStmt.Value)
+			return
+		}
+	}
+	f.block(function.Body)
+}
+
+func (f *formatter) exprs(exprs list.List[Expr]) {
+	for index, expr := range exprs {
+		if index > 0 {
+			f.write(", ")
+		}
+		f.expr(expr)
+	}
+}
+
+func (f *formatter) assignValue(value Expr) {
+	if binary, ok := value.(Binary); ok && binary.Compound {
+		f.write(" ", binary.Operator.Lexeme, "= ")
+		f.expr(binary.Right)
+		return
+	}
+	f.write(" = ")
+	f.expr(value)
+}
+
+func (f *formatter) expr(expr Expr) {
+	switch expr := expr.(type) {
+	case Assign:
+		f.write(expr.Name.Lexeme)
+		f.assignValue(expr.Value)
+	case BigNum:
+		f.write(expr.NumStr, "n")
+	case Binary:
+		f.expr(expr.Left)
+		f.write(" ", expr.Operator.Lexeme, " ")
+		f.expr(expr.Right)
+	case Call:
+		f.expr(expr.Callee)
+		f.write("(")
+		f.exprs(expr.Arguments)
+		f.write(")")
+	case Dict:
+		f.write("{")
+		for index := 0; index < len(expr.Entries); index++ {
+			if index > 0 {
+				f.write(", ")
+			}
+			if spread, ok := expr.Entries[index].(Spread); ok {
+				f.expr(spread)
+				continue
+			}
+			f.expr(expr.Entries[index])
+			f.write(": ")
+			index++
+			f.expr(expr.Entries[index])
+		}
+		f.write("}")
+	case FunctionExpr:
+		f.write("fun")
+		f.function(expr)
+	case Get:
+		f.expr(expr.Object)
+		if expr.Optional {
+			f.write("?.")
+		} else {
+			f.write(".")
+		}
+		f.write(expr.Name.Lexeme)
+	case Grouping:
+		f.write("(")
+		f.expr(expr.Expression)
+		f.write(")")
+	case Index:
+		f.expr(expr.IndexElement)
+		if expr.Optional {
+			f.write("?.")
+		}
+		f.write("[")
+		if expr.Index != nil {
+			f.expr(expr.Index)
+		}
+		if expr.IsSlice {
+			f.write(":")
+			if expr.IndexEnd != nil {
+				f.expr(expr.IndexEnd)
+			}
+		}
+		f.write("]")
+	case List:
+		f.write("[")
+		f.exprs(expr.Elements)
+		f.write("]")
+	case Literal:
+		f.write(formatLiteral(expr))
+	case Logical:
+		f.expr(expr.Left)
+		f.write(" ", expr.Operator.Lexeme, " ")
+		f.expr(expr.Right)
+	case NamedArgument:
+		f.write(expr.Name.Lexeme, ": ")
+		f.expr(expr.Value)
+	case Set:
+		f.expr(expr.Object)
+		f.write(".", expr.Name.Lexeme)
+		f.assignValue(expr.Value)
+	case SetObject:
+		f.expr(expr.Object)
+		f.assignValue(expr.Value)
+	case Spread:
+		f.write("...")
+		f.expr(expr.Iterable)
+	case String:
+		f.write(formatString(expr.Str))
+	case Super:
+		f.write("super.", expr.Method.Lexeme)
+	case Ternary:
+		f.expr(expr.Condition)
+		f.write(" ? ")
+		f.expr(expr.TrueExpr)
+		f.write(" : ")
+		f.expr(expr.FalseExpr)
+	case This:
+		f.write("this")
+	case Unary:
+		f.write(expr.Operator.Lexeme)
+		f.expr(expr.Right)
+	case Variable:
+		f.write(expr.Name.Lexeme)
+	default:
+		panic(fmt.Sprintf("formatter: unknown expression type %T", expr))
+	}
+}
+
+func formatLiteral(literal Literal) string {
+	if literal.Lexeme != "" {
+		return literal.Lexeme
+	}
+	switch value := literal.Value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		switch {
+		case math.IsInf(value, 1):
+			return "Infinity"
+		case math.IsNaN(value):
+			return "NaN"
+		}
+	}
+	return fmt.Sprint(literal.Value)
+}
+
+func formatString(str string) string {
+	quote := '"'
+	if strings.ContainsRune(str, '"') && !strings.ContainsRune(str, '\'') {
+		quote = '\''
+	}
+	var builder strings.Builder
+	builder.WriteRune(quote)
+	for _, c := range str {
+		switch c {
+		case quote, '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(c)
+		case '\a':
+			builder.WriteString(`\a`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\v':
+			builder.WriteString(`\v`)
+		default:
+			builder.WriteRune(c)
+		}
+	}
+	builder.WriteRune(quote)
+	return builder.String()
+}
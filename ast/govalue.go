@@ -0,0 +1,161 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+// ToLoxValue converts a Go value into the Lox value that represents it.
+func ToLoxValue(value any) (any, error) {
+	switch value := value.(type) {
+	case nil, bool, int64, float64, *big.Int, *big.Float:
+		return value, nil
+	case int:
+		return int64(value), nil
+	case int8:
+		return int64(value), nil
+	case int16:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case uint:
+		return uintToLoxValue(uint64(value)), nil
+	case uint8:
+		return int64(value), nil
+	case uint16:
+		return int64(value), nil
+	case uint32:
+		return int64(value), nil
+	case uint64:
+		return uintToLoxValue(value), nil
+	case float32:
+		return float64(value), nil
+	case string:
+		return NewLoxStringQuote(value), nil
+	case []byte:
+		buffer := EmptyLoxBufferCap(int64(len(value)))
+		for _, b := range value {
+			buffer.elements.Add(int64(b))
+		}
+		return buffer, nil
+	case []any:
+		elements := list.NewListCap[any](int64(len(value)))
+		for _, element := range value {
+			loxValue, err := ToLoxValue(element)
+			if err != nil {
+				return nil, err
+			}
+			elements.Add(loxValue)
+		}
+		return NewLoxList(elements), nil
+	case map[string]any:
+		dict := EmptyLoxDict()
+		for key, element := range value {
+			loxValue, err := ToLoxValue(element)
+			if err != nil {
+				return nil, err
+			}
+			dict.setKeyValue(NewLoxStringQuote(key), loxValue)
+		}
+		return dict, nil
+	case interfaces.Type:
+		return value, nil
+	case interfaces.Iterator:
+		return NewLoxIterator(value), nil
+	case interfaces.Iterable:
+		return value, nil
+	}
+	return nil, fmt.Errorf("cannot convert Go value of type %T to a Lox value", value)
+}
+
+// FromLoxValue converts a Lox value into a Go value, reversing ToLoxValue.
+func FromLoxValue(value any) any {
+	switch value := value.(type) {
+	case *LoxString:
+		return value.str
+	case *LoxBuffer:
+		bytes := make([]byte, len(value.elements))
+		for index, element := range value.elements {
+			bytes[index] = byte(element.(int64))
+		}
+		return bytes
+	case *LoxList:
+		elements := make([]any, len(value.elements))
+		for index, element := range value.elements {
+			elements[index] = FromLoxValue(element)
+		}
+		return elements
+	case *LoxDict:
+		pairs := [][2]any{}
+		allStrings := true
+		it := value.Iterator()
+		for it.HasNext() {
+			pair := it.Next().(*LoxList).elements
+			if _, ok := pair[0].(*LoxString); !ok {
+				allStrings = false
+			}
+			pairs = append(pairs, [2]any{pair[0], FromLoxValue(pair[1])})
+		}
+		if allStrings {
+			dict := make(map[string]any, len(pairs))
+			for _, pair := range pairs {
+				dict[pair[0].(*LoxString).str] = pair[1]
+			}
+			return dict
+		}
+		dict := make(map[any]any, len(pairs))
+		for _, pair := range pairs {
+			key := pair[0]
+			if keyStr, ok := key.(*LoxString); ok {
+				key = keyStr.str
+			}
+			dict[key] = pair[1]
+		}
+		return dict
+	}
+	return value
+}
+
+// NewGoFunction returns a Lox function that calls the specified Go function,
+// where an arity of -1 accepts any number of arguments.
+func NewGoFunction(name string, arity int, fn func(args []any) (any, error)) LoxCallable {
+	s := &struct{ ProtoLoxCallable }{}
+	s.arityMethod = func() int { return arity }
+	s.callMethod = func(in *Interpreter, args list.List[any]) (any, error) {
+		goArgs := make([]any, len(args))
+		for index, arg := range args {
+			goArgs[index] = FromLoxValue(arg)
+		}
+		result, err := fn(goArgs)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		loxValue, err := ToLoxValue(result)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return loxValue, nil
+	}
+	s.stringMethod = func() string {
+		return fmt.Sprintf("<native fn %v at %p>", name, &s)
+	}
+	return s
+}
+
+func uintToLoxValue(value uint64) any {
+	if value > 1<<63-1 {
+		return new(big.Int).SetUint64(value)
+	}
+	return int64(value)
+}
+
+func iteratorErr(iterator interfaces.Iterator) error {
+	if iteratorErr, ok := iterator.(interfaces.IteratorErr); ok {
+		return iteratorErr.Err()
+	}
+	return nil
+}
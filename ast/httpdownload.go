@@ -0,0 +1,225 @@
+package ast
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The options of a call to http.download
+type httpDownloadOptions struct {
+	client        *LoxHTTPClient
+	headers       http.Header
+	progress      *LoxFunction
+	resume        bool
+	checksumType  string
+	checksumValue string
+}
+
+// Parses an expected checksum of the form "type:hex", such as "sha256:..."
+func (o *httpDownloadOptions) setChecksum(checksum string) error {
+	checksumType, checksumValue, ok := strings.Cut(checksum, ":")
+	checksumType = strings.ToLower(checksumType)
+	if !ok || checksumValue == "" {
+		return loxerror.Error("Checksum in 'http.download' must have the form 'type:hex', such as 'sha256:...'.")
+	}
+	hashType, ok := LoxCryptoHashes[checksumType]
+	if !ok || !hashType.Available() {
+		return loxerror.Error(fmt.Sprintf("Unknown checksum type '%v' in 'http.download'.", checksumType))
+	}
+	if _, err := hex.DecodeString(checksumValue); err != nil {
+		return loxerror.Error("Checksum in 'http.download' must be a hexadecimal string after its type.")
+	}
+	o.checksumType = checksumType
+	o.checksumValue = strings.ToLower(checksumValue)
+	return nil
+}
+
+// Returns the total size of the resource from a Content-Range header of the
+// form "bytes start-end/total", along with the start of the range, where the
+// total is -1 if it is unknown
+func httpContentRange(contentRange string) (int64, int64, bool) {
+	rangeStr, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	span, totalStr, ok := strings.Cut(rangeStr, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	startStr, _, ok := strings.Cut(span, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if totalStr == "*" {
+		return start, -1, true
+	}
+	total, err := strconv.ParseInt(totalStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// Downloads the resource at the specified URL into the file at the
+// specified path, returning the size of the file in bytes
+func httpDownload(in *Interpreter, callToken *token.Token, urlStr string, path string, options httpDownloadOptions) (int64, error) {
+	client := options.client
+	if client == nil {
+		client = NewLoxHTTPClient()
+	}
+	var offset int64
+	if options.resume {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			offset = info.Size()
+		}
+	}
+
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return 0, loxerror.RuntimeError(callToken, err.Error())
+	}
+	for key, values := range client.options.headers {
+		req.Header[key] = values
+	}
+	for key, values := range options.headers {
+		req.Header[key] = values
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-", offset))
+	}
+	res, err := client.options.do(client.client, req)
+	if err != nil {
+		return 0, loxerror.RuntimeError(callToken, err.Error())
+	}
+	defer res.Body.Close()
+
+	total := int64(-1)
+	fileFlags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	isComplete := false
+	switch {
+	case offset > 0 && res.StatusCode == http.StatusPartialContent:
+		start, rangeTotal, ok := httpContentRange(res.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Server sent an invalid range when resuming the download of '%v'.", urlStr))
+		}
+		total = rangeTotal
+		fileFlags = os.O_WRONLY | os.O_APPEND
+	case offset > 0 && res.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		//The file already has all of the bytes of the resource
+		total = offset
+		isComplete = true
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		offset = 0
+		total = res.ContentLength
+	default:
+		return 0, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Failed to download '%v': server responded with status %v.", urlStr, res.StatusCode))
+	}
+
+	var hasher hash.Hash
+	if options.checksumType != "" {
+		hasher = LoxCryptoHashes[options.checksumType].New()
+		if offset > 0 {
+			existing, err := os.Open(path)
+			if err != nil {
+				return 0, loxerror.RuntimeError(callToken, err.Error())
+			}
+			_, err = io.CopyN(hasher, existing, offset)
+			existing.Close()
+			if err != nil {
+				return 0, loxerror.RuntimeError(callToken, err.Error())
+			}
+		}
+	}
+
+	downloaded := offset
+	var progressArgs list.List[any]
+	if options.progress != nil {
+		progressArgs = getArgList(options.progress, 2)
+		defer progressArgs.Clear()
+	}
+	reportProgress := func() error {
+		if options.progress == nil {
+			return nil
+		}
+		progressArgs[0] = downloaded
+		if total >= 0 {
+			progressArgs[1] = total
+		} else {
+			progressArgs[1] = nil
+		}
+		result, err := options.progress.call(in, progressArgs)
+		if _, ok := result.(Return); !ok && err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if !isComplete {
+		file, err := os.OpenFile(path, fileFlags, 0644)
+		if err != nil {
+			return 0, loxerror.RuntimeError(callToken, err.Error())
+		}
+		buffer := make([]byte, 32*1024)
+		for {
+			numRead, readErr := res.Body.Read(buffer)
+			if numRead > 0 {
+				if _, err := file.Write(buffer[:numRead]); err != nil {
+					file.Close()
+					return 0, loxerror.RuntimeError(callToken, err.Error())
+				}
+				if hasher != nil {
+					hasher.Write(buffer[:numRead])
+				}
+				downloaded += int64(numRead)
+				if err := reportProgress(); err != nil {
+					file.Close()
+					return 0, err
+				}
+			}
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			if readErr != nil {
+				file.Close()
+				return 0, loxerror.RuntimeError(callToken, readErr.Error())
+			}
+		}
+		if err := file.Close(); err != nil {
+			return 0, loxerror.RuntimeError(callToken, err.Error())
+		}
+	} else if err := reportProgress(); err != nil {
+		return 0, err
+	}
+
+	if total >= 0 && downloaded != total {
+		return 0, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Download of '%v' ended after %v of %v bytes.", urlStr, downloaded, total))
+	}
+	if hasher != nil {
+		actual := hex.EncodeToString(hasher.Sum(nil))
+		if actual != options.checksumValue {
+			os.Remove(path)
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Checksum mismatch for '%v': expected %v but got %v. The file was removed.",
+					path, options.checksumValue, actual))
+		}
+	}
+	return downloaded, nil
+}
@@ -15,6 +15,43 @@ import (
 	"github.com/AlanLuu/lox/token"
 )
 
+// Converts the specified dictionary into a JSON string using JSON.stringify
+func jsonDictToStr(in *Interpreter, dict *LoxDict) (string, error) {
+	jsonClassErrStr := "Could not find JSON class for stringifying JSON dictionary."
+	jsonClassAny, jsonClassErr := in.globals.GetFromStr("JSON")
+	if jsonClassErr != nil {
+		return "", loxerror.RuntimeError(in.callToken, jsonClassErrStr)
+	}
+	if _, ok := jsonClassAny.(*LoxClass); !ok {
+		return "", loxerror.RuntimeError(in.callToken, jsonClassErrStr)
+	}
+
+	jsonClass := jsonClassAny.(*LoxClass)
+	jsonStringifyErrStr := "Could not find 'stringify' method in JSON class for stringifying JSON dictionary."
+	jsonStringifyFuncAny, foundJsonFunc := jsonClass.classProperties["stringify"]
+	if !foundJsonFunc {
+		return "", loxerror.RuntimeError(in.callToken, jsonStringifyErrStr)
+	}
+	if _, ok := jsonStringifyFuncAny.(LoxCallable); !ok {
+		return "", loxerror.RuntimeError(in.callToken, jsonStringifyErrStr)
+	}
+
+	jsonStringifyFunc := jsonStringifyFuncAny.(LoxCallable)
+	argList := list.NewList[any]()
+	argList.Add(dict)
+	result, resultErr := jsonStringifyFunc.call(in, argList)
+	if resultErr != nil {
+		errMsg := resultErr.Error()
+		index := strings.LastIndex(errMsg, "\n")
+		if index > 0 {
+			errMsg = errMsg[:index]
+		}
+		return "", loxerror.RuntimeError(in.callToken,
+			"Error occurred when stringifying JSON dictionary:\n"+errMsg)
+	}
+	return result.(*LoxString).str, nil
+}
+
 func (i *Interpreter) defineHTTPFuncs() {
 	className := "http"
 	httpClass := NewLoxClass(className, nil, false)
@@ -35,41 +72,6 @@ func (i *Interpreter) defineHTTPFuncs() {
 		errStr := fmt.Sprintf("Argument to 'http.%v' must be an %v.", name, theType)
 		return nil, loxerror.RuntimeError(callToken, errStr)
 	}
-	jsonDictToStr := func(in *Interpreter, dict *LoxDict) (string, error) {
-		jsonClassErrStr := "Could not find JSON class for stringifying JSON dictionary."
-		jsonClassAny, jsonClassErr := in.globals.GetFromStr("JSON")
-		if jsonClassErr != nil {
-			return "", loxerror.RuntimeError(in.callToken, jsonClassErrStr)
-		}
-		if _, ok := jsonClassAny.(*LoxClass); !ok {
-			return "", loxerror.RuntimeError(in.callToken, jsonClassErrStr)
-		}
-
-		jsonClass := jsonClassAny.(*LoxClass)
-		jsonStringifyErrStr := "Could not find 'stringify' method in JSON class for stringifying JSON dictionary."
-		jsonStringifyFuncAny, foundJsonFunc := jsonClass.classProperties["stringify"]
-		if !foundJsonFunc {
-			return "", loxerror.RuntimeError(in.callToken, jsonStringifyErrStr)
-		}
-		if _, ok := jsonStringifyFuncAny.(LoxCallable); !ok {
-			return "", loxerror.RuntimeError(in.callToken, jsonStringifyErrStr)
-		}
-
-		jsonStringifyFunc := jsonStringifyFuncAny.(LoxCallable)
-		argList := list.NewList[any]()
-		argList.Add(dict)
-		result, resultErr := jsonStringifyFunc.call(in, argList)
-		if resultErr != nil {
-			errMsg := resultErr.Error()
-			index := strings.LastIndex(errMsg, "\n")
-			if index > 0 {
-				errMsg = errMsg[:index]
-			}
-			return "", loxerror.RuntimeError(in.callToken,
-				"Error occurred when stringifying JSON dictionary:\n"+errMsg)
-		}
-		return result.(*LoxString).str, nil
-	}
 	populateHeaders := func(in *Interpreter, headers *LoxDict, req *http.Request, name string) error {
 		errMsg := "Headers dictionary in 'http." + name + "' must only have strings."
 		it := headers.Iterator()
@@ -93,6 +95,104 @@ func (i *Interpreter) defineHTTPFuncs() {
 		return nil
 	}
 
+	httpFunc("client", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 1 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		client := NewLoxHTTPClient()
+		if argsLen == 1 {
+			options, ok := args[0].(*LoxDict)
+			if !ok {
+				return argMustBeType(in.callToken, "client", "dictionary")
+			}
+			err := forEachOption(options, func(key string, value any) error {
+				return client.setOption(in.callToken, key, value)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return client, nil
+	})
+	httpFunc("download", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		urlStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'http.download' must be a string.")
+		}
+		path, ok := args[1].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'http.download' must be a string.")
+		}
+		var options httpDownloadOptions
+		if argsLen == 3 {
+			optionsDict, ok := args[2].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'http.download' must be a dictionary.")
+			}
+			err := forEachOption(optionsDict, func(key string, value any) error {
+				switch key {
+				case "checksum":
+					checksum, ok := value.(*LoxString)
+					if !ok {
+						return httpOptionErr(in.callToken, "http.download", key, "a string")
+					}
+					if err := options.setChecksum(checksum.str); err != nil {
+						return loxerror.RuntimeError(in.callToken, err.Error())
+					}
+				case "client":
+					client, ok := value.(*LoxHTTPClient)
+					if !ok {
+						return httpOptionErr(in.callToken, "http.download", key, "an http client")
+					}
+					options.client = client
+				case "headers":
+					headers, ok := value.(*LoxDict)
+					if !ok {
+						return httpOptionErr(in.callToken, "http.download", key, "a dictionary")
+					}
+					options.headers = http.Header{}
+					if !httpStringListDict(headers, options.headers.Add) {
+						return loxerror.RuntimeError(in.callToken,
+							"Headers dictionary in 'http.download' must only have strings or lists of strings.")
+					}
+				case "progress":
+					progress, ok := value.(*LoxFunction)
+					if !ok {
+						return httpOptionErr(in.callToken, "http.download", key, "a function")
+					}
+					options.progress = progress
+				case "resume":
+					resume, ok := value.(bool)
+					if !ok {
+						return httpOptionErr(in.callToken, "http.download", key, "a boolean")
+					}
+					options.resume = resume
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown option '%v' in 'http.download'.", key))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		size, err := httpDownload(in, in.callToken, urlStr.str, path.str, options)
+		if err != nil {
+			return nil, err
+		}
+		return size, nil
+	})
 	httpFunc("get", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		argsLen := len(args)
 		switch argsLen {
@@ -707,6 +807,41 @@ func (i *Interpreter) defineHTTPFuncs() {
 				fmt.Sprintf("Expected 3 or 4 arguments but got %v.", argsLen))
 		}
 	})
+	httpFunc("response", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen < 1 || argsLen > 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1, 2, or 3 arguments but got %v.", argsLen))
+		}
+		status, ok := args[0].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'http.response' must be an integer.")
+		}
+		if status < 100 || status > 999 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"HTTP status code must be between 100 and 999.")
+		}
+		response := &LoxHTTPServerResponse{status: int(status)}
+		if argsLen >= 2 {
+			switch body := args[1].(type) {
+			case nil, *LoxBuffer, *LoxDict, *LoxString:
+				response.body = body
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'http.response' must be a buffer, dictionary, string, or nil.")
+			}
+		}
+		if argsLen == 3 {
+			headers, ok := args[2].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'http.response' must be a dictionary.")
+			}
+			response.headers = headers
+		}
+		return response, nil
+	})
 	httpFunc("serve", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		var dir string
 		var port int64
@@ -787,5 +922,9 @@ func (i *Interpreter) defineHTTPFuncs() {
 		return nil, nil
 	})
 
+	httpFunc("server", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxHTTPServer(), nil
+	})
+
 	i.globals.Define(className, httpClass)
 }
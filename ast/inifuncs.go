@@ -0,0 +1,60 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+func (i *Interpreter) defineINIFuncs() {
+	className := "ini"
+	iniClass := NewLoxClass(className, nil, false)
+	iniFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ini fn %v at %p>", name, &s)
+		}
+		iniClass.classProperties[name] = s
+	}
+
+	iniFunc("new", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxINIFile(), nil
+	})
+	iniFunc("parse", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		iniStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'ini.parse' must be a string.")
+		}
+		iniFile, err := ParseLoxINIFile(iniStr.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return iniFile, nil
+	})
+	iniFunc("parseFile", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		path, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'ini.parseFile' must be a string.")
+		}
+		if sandboxErr := checkSandbox(in.callToken, "Reading files"); sandboxErr != nil {
+			return nil, sandboxErr
+		}
+		data, err := os.ReadFile(path.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		iniFile, err := ParseLoxINIFile(string(data))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return iniFile, nil
+	})
+
+	i.globals.Define(className, iniClass)
+}
@@ -0,0 +1,326 @@
+package ast
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/mattn/go-isatty"
+)
+
+var inputSc *bufio.Scanner
+
+func isInputTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// Reads a line from standard input after printing the specified prompt.
+// If standard input is a terminal, the line can be edited and is added to
+// the history, and it isn't echoed if password is true. Otherwise, the
+// prompt isn't printed. Returns false if the end of input was reached
+func readInputLine(callToken *token.Token, prompt string, password bool) (string, bool, error) {
+	if isInputTerminal() {
+		var userInput string
+		var readError error
+		if password {
+			userInput, readError = readlinePassword(prompt)
+		} else {
+			userInput, readError = readlineInput(prompt)
+		}
+		switch readError {
+		case errInputInterrupt:
+			return "", false, loxerror.RuntimeError(callToken, "Keyboard interrupt")
+		case io.EOF:
+			return "", false, nil
+		}
+		return userInput, true, nil
+	}
+	if inputSc == nil {
+		inputSc = bufio.NewScanner(os.Stdin)
+	}
+	if !inputSc.Scan() {
+		return "", false, nil
+	}
+	return inputSc.Text(), true, nil
+}
+
+func newInputString(userInput string) *LoxString {
+	if strings.Contains(userInput, "'") {
+		return NewLoxString(userInput, '"')
+	}
+	return NewLoxString(userInput, '\'')
+}
+
+func (i *Interpreter) defineInputFuncs() {
+	className := "input"
+	inputClass := NewLoxClass(className, nil, false)
+	inputFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native input fn %v at %p>", name, &s)
+		}
+		inputClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'input.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	//Calling the class itself reads a line with an optional prompt, which
+	//can be of any type
+	inputClass.callFunc = func(in *Interpreter, args list.List[any]) (any, error) {
+		var prompt any = ""
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+		case 1:
+			prompt = args[0]
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		userInput, ok, err := readInputLine(in.callToken, getResult(prompt, prompt, true), false)
+		if !ok {
+			return nil, err
+		}
+		return newInputString(userInput), nil
+	}
+
+	inputFunc("addHistory", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			if err := readlineAddHistory(loxStr.str); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "addHistory", "string")
+	})
+	inputFunc("clearHistory", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		readlineClearHistory()
+		return nil, nil
+	})
+	inputFunc("confirm", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		message, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'input.confirm' must be a string.")
+		}
+		suffix := "[y/n]"
+		var defaultValue any
+		if argsLen == 2 {
+			switch arg := args[1].(type) {
+			case bool:
+				defaultValue = arg
+				if arg {
+					suffix = "[Y/n]"
+				} else {
+					suffix = "[y/N]"
+				}
+			case nil:
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'input.confirm' must be a boolean or nil.")
+			}
+		}
+		prompt := message.str + " " + suffix + " "
+		for {
+			userInput, ok, err := readInputLine(in.callToken, prompt, false)
+			if !ok {
+				return nil, err
+			}
+			switch strings.ToLower(strings.TrimSpace(userInput)) {
+			case "y", "yes":
+				return true, nil
+			case "n", "no":
+				return false, nil
+			case "":
+				if defaultValue != nil {
+					return defaultValue, nil
+				}
+			}
+			fmt.Println("Please enter 'y' or 'n'.")
+		}
+	})
+	inputFunc("password", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		prompt := ""
+		switch len(args) {
+		case 0:
+		case 1:
+			message, ok := args[0].(*LoxString)
+			if !ok {
+				return argMustBeType(in.callToken, "password", "string")
+			}
+			prompt = message.str
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+		}
+		userInput, ok, err := readInputLine(in.callToken, prompt, true)
+		if !ok {
+			return nil, err
+		}
+		return newInputString(userInput), nil
+	})
+	inputFunc("prompt", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		message, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'input.prompt' must be a string.")
+		}
+		var defaultValue *LoxString
+		var validate *LoxFunction
+		if argsLen == 2 {
+			optionsDict, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'input.prompt' must be a dictionary.")
+			}
+			optionErr := func(key string, theType string) error {
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option '%v' in '%v' must be %v.", key, "input.prompt", theType))
+			}
+			err := forEachOption(optionsDict, func(key string, value any) error {
+				switch key {
+				case "default":
+					defaultStr, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(key, "a string")
+					}
+					defaultValue = defaultStr
+				case "validate":
+					callback, ok := value.(*LoxFunction)
+					if !ok {
+						return optionErr(key, "a function")
+					}
+					validate = callback
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown option '%v' in '%v'.", key, "input.prompt"))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		prompt := message.str
+		if defaultValue != nil {
+			prompt = fmt.Sprintf("%v[%v] ", prompt, defaultValue.str)
+		}
+		for {
+			userInput, ok, err := readInputLine(in.callToken, prompt, false)
+			if !ok {
+				return nil, err
+			}
+			result := newInputString(userInput)
+			if userInput == "" && defaultValue != nil {
+				result = defaultValue
+			}
+			if validate == nil {
+				return result, nil
+			}
+			argList := getArgList(validate, 1)
+			argList[0] = result
+			validResult, err := validate.call(in, argList)
+			if validReturn, ok := validResult.(Return); ok {
+				validResult = validReturn.FinalValue
+			} else if err != nil {
+				return nil, err
+			}
+			//The validation function returns true to accept the input or
+			//an error message string to reject it
+			if errMsg, ok := validResult.(*LoxString); ok {
+				fmt.Println(errMsg.str)
+			} else if in.isTruthy(validResult) {
+				return result, nil
+			} else {
+				fmt.Println("Invalid input.")
+			}
+		}
+	})
+	inputFunc("select", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		message, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'input.select' must be a string.")
+		}
+		choices, ok := args[1].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'input.select' must be a list.")
+		}
+		numChoices := int64(len(choices.elements))
+		if numChoices == 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"List argument to 'input.select' cannot be empty.")
+		}
+		defaultIndex := int64(-1)
+		if argsLen == 3 {
+			index, ok := args[2].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'input.select' must be an integer.")
+			}
+			if index < 0 || index >= numChoices {
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, in.callToken,
+					"Default index in 'input.select' is out of range.")
+			}
+			defaultIndex = index
+		}
+		fmt.Println(message.str)
+		for index, choice := range choices.elements {
+			fmt.Printf("  %v) %v\n", index+1, getResult(choice, choice, true))
+		}
+		prompt := fmt.Sprintf("Enter a number [1-%v]: ", numChoices)
+		if defaultIndex >= 0 {
+			prompt = fmt.Sprintf("Enter a number [1-%v] (default %v): ", numChoices, defaultIndex+1)
+		}
+		for {
+			userInput, ok, err := readInputLine(in.callToken, prompt, false)
+			if !ok {
+				return nil, err
+			}
+			userInput = strings.TrimSpace(userInput)
+			if userInput == "" && defaultIndex >= 0 {
+				return choices.elements[defaultIndex], nil
+			}
+			choice, err := strconv.ParseInt(userInput, 10, 64)
+			if err == nil && choice >= 1 && choice <= numChoices {
+				return choices.elements[choice-1], nil
+			}
+			fmt.Printf("Please enter a number from 1 to %v.\n", numChoices)
+		}
+	})
+	inputFunc("setHistoryFile", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			readlineSetHistoryFile(loxStr.str)
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "setHistoryFile", "string")
+	})
+
+	i.globals.Define(className, inputClass)
+}
@@ -0,0 +1,72 @@
+//go:build !js
+
+package ast
+
+import "github.com/chzyer/readline"
+
+var (
+	inputReadline    *readline.Instance
+	inputHistoryFile string
+)
+
+var errInputInterrupt = readline.ErrInterrupt
+
+func CloseInputFuncReadline() {
+	if inputReadline != nil {
+		inputReadline.Close()
+	}
+}
+
+func getInputReadline(prompt string) *readline.Instance {
+	if inputReadline == nil {
+		inputReadline, _ = readline.NewEx(&readline.Config{
+			Prompt:          prompt,
+			InterruptPrompt: "^C",
+			HistoryFile:     inputHistoryFile,
+		})
+	} else {
+		inputReadline.SetPrompt(prompt)
+	}
+	return inputReadline
+}
+
+func readlineInput(prompt string) (string, error) {
+	return getInputReadline(prompt).Readline()
+}
+
+type hiddenPainter struct{}
+
+func (hiddenPainter) Paint(line []rune, pos int) []rune {
+	return nil
+}
+
+func readlinePassword(prompt string) (string, error) {
+	instance := getInputReadline("")
+	cfg := instance.GenPasswordConfig()
+	cfg.Prompt = prompt
+	cfg.EnableMask = false
+	cfg.Painter = hiddenPainter{}
+	password, err := instance.ReadPasswordWithConfig(cfg)
+	if err == nil {
+		//The newline from pressing Enter is hidden along with the line
+		instance.Write([]byte("\n"))
+	}
+	return string(password), err
+}
+
+func readlineAddHistory(line string) error {
+	return getInputReadline("").SaveHistory(line)
+}
+
+func readlineClearHistory() {
+	getInputReadline("").ResetHistory()
+}
+
+func readlineSetHistoryFile(path string) {
+	inputHistoryFile = path
+	//The history file of an existing instance can't be reopened
+	if inputReadline != nil {
+		inputReadline.Close()
+		inputReadline = nil
+	}
+}
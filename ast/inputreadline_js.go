@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"errors"
+	"io"
+)
+
+// The error returned by readlineInput when Ctrl+C is pressed
+var errInputInterrupt = errors.New("interrupt")
+
+func CloseInputFuncReadline() {}
+
+// Returns io.EOF, since there is no terminal to read from in a browser
+func readlineInput(prompt string) (string, error) {
+	return "", io.EOF
+}
+
+func readlinePassword(prompt string) (string, error) {
+	return "", io.EOF
+}
+
+func readlineAddHistory(line string) error {
+	return nil
+}
+
+func readlineClearHistory() {}
+
+func readlineSetHistoryFile(path string) {}
@@ -0,0 +1,59 @@
+package ast
+
+import "sync"
+
+const loxStringInternMaxLen = 64
+
+const loxStringInternMaxCount = 1 << 16
+
+var loxStringInternTable = struct {
+	strings map[LoxStringStr]*LoxString
+	mu      sync.RWMutex
+}{
+	strings: make(map[LoxStringStr]*LoxString),
+}
+
+func InternLoxString(str string, quote byte) *LoxString {
+	if len(str) > loxStringInternMaxLen {
+		return NewLoxString(str, quote)
+	}
+	key := LoxStringStr{str, quote}
+	table := &loxStringInternTable
+	table.mu.RLock()
+	loxStr, ok := table.strings[key]
+	table.mu.RUnlock()
+	if ok {
+		return loxStr
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	if loxStr, ok := table.strings[key]; ok {
+		return loxStr
+	}
+	loxStr = NewLoxString(str, quote)
+	loxStr.interned = true
+	if len(table.strings) < loxStringInternMaxCount {
+		table.strings[key] = loxStr
+	}
+	return loxStr
+}
+
+const (
+	smallIntCacheMin = -128
+	smallIntCacheMax = 1023
+)
+
+var smallIntCache = func() []any {
+	cache := make([]any, smallIntCacheMax-smallIntCacheMin+1)
+	for i := range cache {
+		cache[i] = int64(i + smallIntCacheMin)
+	}
+	return cache
+}()
+
+func boxInt(num int64) any {
+	if num >= smallIntCacheMin && num <= smallIntCacheMax {
+		return smallIntCache[num-smallIntCacheMin]
+	}
+	return num
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -8,8 +9,12 @@ import (
 	"math/big"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"unicode/utf8"
 
 	"github.com/AlanLuu/lox/bignum/bigfloat"
@@ -18,60 +23,123 @@ import (
 	"github.com/AlanLuu/lox/interfaces"
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
-	"github.com/AlanLuu/lox/loxsignal"
 	"github.com/AlanLuu/lox/scanner"
 	"github.com/AlanLuu/lox/token"
 	"github.com/AlanLuu/lox/util"
 )
 
 type Interpreter struct {
-	environment *env.Environment
-	globals     *env.Environment
-	locals      map[any]int
-	blockDepth  int
-	callToken   *token.Token
+	environment   *env.Environment
+	globals       *env.Environment
+	blockDepth    int
+	callToken     *token.Token
+	interrupted   *atomic.Bool
+	interruptOnce *sync.Once
+	errorClasses  map[string]*LoxClass
+	profileStack  *profileStack
+	tests         *testSuite
+	callDepth     int
+	//The error thrown by a signal handler, which stops the current loop
+	signalErr       error
+	inSignalHandler bool
 }
 
 func NewInterpreter() *Interpreter {
 	interpreter := &Interpreter{
-		globals:    env.NewEnvironment(),
-		locals:     make(map[any]int),
-		blockDepth: 0,
-		callToken:  nil,
+		globals:       env.NewEnvironment(),
+		blockDepth:    0,
+		callToken:     nil,
+		interrupted:   &atomic.Bool{},
+		interruptOnce: &sync.Once{},
 	}
 	interpreter.environment = interpreter.globals
+	if p := activeProfiler.Load(); p != nil {
+		interpreter.profileStack = p.newStack(true)
+	}
+	interpreter.defineArgparseFuncs()   //Defined in argparsefuncs.go
+	interpreter.defineAssetsFuncs()     //Defined in assetsfuncs.go
 	interpreter.defineBase32Funcs()     //Defined in base32funcs.go
+	interpreter.defineBase58Funcs()     //Defined in base58funcs.go
 	interpreter.defineBase64Funcs()     //Defined in base64funcs.go
+	interpreter.defineBase85Funcs()     //Defined in base85funcs.go
+	interpreter.defineBech32Funcs()     //Defined in bech32funcs.go
 	interpreter.defineBigFloatFuncs()   //Defined in bigfloatfuncs.go
 	interpreter.defineBigIntFuncs()     //Defined in bigintfuncs.go
 	interpreter.defineBigMathFuncs()    //Defined in bigmathfuncs.go
+	interpreter.defineBitsetFuncs()     //Defined in bitsetfuncs.go
+	interpreter.defineBzip2Funcs()      //Defined in bzip2funcs.go
+	interpreter.defineCalendarFuncs()   //Defined in calendarfuncs.go
 	interpreter.defineClassCalledLox()  //Defined in classcalledlox.go
+	interpreter.defineColorFuncs()      //Defined in colorfuncs.go
+	interpreter.defineCopyFuncs()       //Defined in copyfuncs.go
+	interpreter.defineCronFuncs()       //Defined in cronfuncs.go
 	interpreter.defineCryptoFuncs()     //Defined in cryptofuncs.go
 	interpreter.defineCSVFuncs()        //Defined in csvfuncs.go
 	interpreter.defineDateFuncs()       //Defined in datefuncs.go
+	interpreter.defineDecimalFuncs()    //Defined in decimalfuncs.go
+	interpreter.defineDNSFuncs()        //Defined in dnsfuncs.go
 	interpreter.defineDurationFuncs()   //Defined in durationfuncs.go
+	interpreter.defineErrorClasses()    //Defined in errorclasses.go
+	interpreter.defineFakerFuncs()      //Defined in fakerfuncs.go
 	interpreter.defineFloatFuncs()      //Defined in floatfuncs.go
 	interpreter.defineGzipFuncs()       //Defined in gzipfuncs.go
 	interpreter.defineHexFuncs()        //Defined in hexfuncs.go
 	interpreter.defineHTMLFuncs()       //Defined in htmlfuncs.go
 	interpreter.defineHTTPFuncs()       //Defined in httpfuncs.go
+	interpreter.defineINIFuncs()        //Defined in inifuncs.go
+	interpreter.defineInputFuncs()      //Defined in inputfuncs.go
 	interpreter.defineIntFuncs()        //Defined in intfuncs.go
+	interpreter.defineIPAddrFuncs()     //Defined in ipaddrfuncs.go
 	interpreter.defineIteratorFuncs()   //Defined in iteratorfuncs.go
 	interpreter.defineJSONFuncs()       //Defined in jsonfuncs.go
+	interpreter.defineJWTFuncs()        //Defined in jwtfuncs.go
 	interpreter.defineLogFuncs()        //Defined in logfuncs.go
+	interpreter.defineMarkdownFuncs()   //Defined in markdownfuncs.go
 	interpreter.defineMathFuncs()       //Defined in mathfuncs.go
+	interpreter.defineMatrixFuncs()     //Defined in matrixfuncs.go
+	interpreter.defineMimeFuncs()       //Defined in mimefuncs.go
+	interpreter.defineMsgpackFuncs()    //Defined in msgpackfuncs.go
 	interpreter.defineNativeFuncs()     //Defined in nativefuncs.go
+	interpreter.defineNetFuncs()        //Defined in netfuncs.go
+	interpreter.defineOCRFuncs()        //Defined in ocrfuncs.go
+	interpreter.defineOTPFuncs()        //Defined in otpfuncs.go
 	interpreter.defineOSFuncs()         //Defined in osfuncs.go
+	interpreter.definePasswordFuncs()   //Defined in passwordfuncs.go
+	interpreter.definePathFuncs()       //Defined in pathfuncs.go
 	interpreter.defineProcessFuncs()    //Defined in processfuncs.go
+	interpreter.defineProgressFuncs()   //Defined in progressfuncs.go
 	interpreter.defineRandFuncs()       //Defined in randfuncs.go
+	interpreter.defineRationalFuncs()   //Defined in rationalfuncs.go
 	interpreter.defineRegexFuncs()      //Defined in regexfuncs.go
+	interpreter.defineRPCFuncs()        //Defined in rpcfuncs.go
+	interpreter.defineSchedulerFuncs()  //Defined in schedulerfuncs.go
+	interpreter.defineScreenFuncs()     //Defined in screenfuncs.go
+	interpreter.defineSMTPFuncs()       //Defined in smtpfuncs.go
 	interpreter.defineStringFuncs()     //Defined in stringfuncs.go
+	interpreter.defineStructFuncs()     //Defined in structfuncs.go
+	interpreter.defineSubprocessFuncs() //Defined in subprocessfuncs.go
+	interpreter.defineSyncFuncs()       //Defined in syncfuncs.go
+	interpreter.defineSysInfoFuncs()    //Defined in sysinfofuncs.go
 	interpreter.defineTarFuncs()        //Defined in tarfuncs.go
+	interpreter.defineTaskFuncs()       //Defined in taskfuncs.go
+	interpreter.defineTemplateFuncs()   //Defined in templatefuncs.go
+	interpreter.defineTermFuncs()       //Defined in termfuncs.go
+	interpreter.defineTestFuncs()       //Defined in testfuncs.go
+	interpreter.defineTimeFuncs()       //Defined in timefuncs.go
+	interpreter.defineTLSFuncs()        //Defined in tlsfuncs.go
+	interpreter.defineTOMLFuncs()       //Defined in tomlfuncs.go
+	interpreter.defineULIDFuncs()       //Defined in uuidfuncs.go
 	interpreter.defineUnsafeFuncs()     //Defined in unsafefuncs.go
 	interpreter.defineUUIDFuncs()       //Defined in uuidfuncs.go
+	interpreter.defineWatchFuncs()      //Defined in watchfuncs.go
 	interpreter.defineWebBrowserFuncs() //Defined in webbrowserfuncs.go
 	interpreter.defineWindowsFuncs()    //Defined in windowsfuncs_windows.go
+	interpreter.defineXzFuncs()         //Defined in xzfuncs.go
 	interpreter.defineZipFuncs()        //Defined in zipfuncs.go
+	interpreter.defineZlibFuncs()       //Defined in zlibfuncs.go
+	interpreter.defineZstdFuncs()       //Defined in zstdfuncs.go
+	interpreter.defineNativeModules()   //Defined in nativemodules.go
+	interpreter.defineSandbox()         //Defined in sandbox.go
 	return interpreter
 }
 
@@ -194,30 +262,110 @@ func getType(element any) string {
 	}
 }
 
-func (i *Interpreter) Interpret(statements list.List[Stmt], makeHandler bool) error {
-	interrupted := false
-	if util.StdinFromTerminal() && makeHandler {
+// DefineGlobal defines a global variable with the specified name and value,
+// which allows Go code that embeds this interpreter to pass values to Lox
+// code. The value is converted using ToLoxValue.
+func (i *Interpreter) DefineGlobal(name string, value any) error {
+	loxValue, err := ToLoxValue(value)
+	if err != nil {
+		return err
+	}
+	i.globals.Define(name, loxValue)
+	return nil
+}
+
+// LookupGlobal returns the value of the global variable with the specified
+// name and whether it exists.
+func (i *Interpreter) LookupGlobal(name string) (any, bool) {
+	return i.globals.Lookup(name)
+}
+
+// CallGlobal calls the function or class stored in the global variable
+// with the specified name and returns its result, which allows Go code that
+// embeds this interpreter to call functions defined in Lox code. The
+// arguments are converted using ToLoxValue.
+func (i *Interpreter) CallGlobal(name string, args ...any) (any, error) {
+	callToken := &token.Token{TokenType: token.IDENTIFIER, Lexeme: name}
+	callee, ok := i.globals.Lookup(name)
+	if !ok {
+		return nil, loxerror.RuntimeErrorKind(loxerror.NameError, callToken,
+			"undefined variable '"+name+"'.")
+	}
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, callToken,
+			"Can only call functions and classes.")
+	}
+	arguments := list.NewListCap[any](int64(len(args) + 1))
+	for _, arg := range args {
+		loxValue, err := ToLoxValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		arguments.Add(loxValue)
+	}
+	arityErr := checkCallArity(function, len(arguments), callToken)
+	if arityErr != nil {
+		return nil, arityErr
+	}
+	if builtin, ok := function.(LoxBuiltInProtoCallable); ok {
+		arguments.AddAt(0, builtin.instance)
+	}
+	prevToken := i.callToken
+	defer func() {
+		i.callToken = prevToken
+	}()
+	i.callToken = callToken
+	result, err := function.call(i, arguments)
+	if resultReturn, ok := result.(Return); ok {
+		return resultReturn.FinalValue, nil
+	}
+	return result, err
+}
+
+// Installs a handler for Ctrl+C that is shared by every loop run by this
+// interpreter. The first Ctrl+C sets the interrupt flag, which loops check
+// on every iteration. The loop that sees the flag clears it and throws a
+// "loop interrupted" error, and the top-level statement loop only stops the
+// program if the flag is still set between statements because no loop
+// handled it. A second Ctrl+C while the flag is still set exits the process
+// in case the program is stuck outside of a loop. Ctrl+C is left alone
+// while Lox code is handling or ignoring SIGINT with os.onSignal or
+// os.ignoreSignal.
+func (i *Interpreter) installInterruptHandler() {
+	i.interruptOnce.Do(func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt)
-		defer func() {
-			if !interrupted {
-				sigChan <- loxsignal.LoopSignal{}
-				signal.Stop(sigChan)
-			}
-		}()
 		go func() {
-			sig := <-sigChan
-			switch sig {
-			case os.Interrupt:
-				interrupted = true
-				signal.Stop(sigChan)
+			for range sigChan {
+				if isSignalTrapped(syscall.SIGINT) {
+					continue
+				}
+				if i.interrupted.Swap(true) {
+					exitLox(130)
+				}
 			}
 		}()
+	})
+}
+
+func (i *Interpreter) Interpret(statements list.List[Stmt], makeHandler bool) error {
+	if makeHandler && util.StdinFromTerminal() {
+		i.installInterruptHandler()
+		i.interrupted.Store(false)
 	}
 	for _, statement := range statements {
-		if interrupted {
+		if signalsPending.Load() {
+			if err := i.runSignalHandlers(); err != nil {
+				return err
+			}
+		}
+		if i.interrupted.Load() {
 			return nil
 		}
+		if message := limitExceeded(); message != "" {
+			return loxerror.Error(message)
+		}
 		value, evalErr := i.evaluate(statement)
 		if evalErr != nil {
 			if value != nil {
@@ -271,6 +419,10 @@ func (i *Interpreter) isTruthy(obj any) bool {
 		return !bigint.IsZero(obj)
 	case *big.Float:
 		return !bigfloat.IsZero(obj)
+	case *LoxRational:
+		return obj.rat.Sign() != 0
+	case *LoxDecimal:
+		return obj.coef.Sign() != 0
 	case interfaces.Length:
 		return obj.Length() > 0
 	}
@@ -355,6 +507,8 @@ func getResult(source any, originalSource any, isPrintStmt bool) string {
 				return fmt.Sprintf("%c%v%c", source.quote, sourceStr, source.quote)
 			}
 		}
+	case LoxFrozenKey:
+		return getResult(source.value(), originalSource, isPrintStmt)
 	case LoxStringStr:
 		if len(source.str) == 0 {
 			if isPrintStmt {
@@ -386,11 +540,12 @@ func getResult(source any, originalSource any, isPrintStmt bool) string {
 		bufferStr.WriteByte(']')
 		return bufferStr.String()
 	case *LoxDict:
-		sourceLen := len(source.entries)
+		sourceLen := len(source.indexes)
 		var dictStr strings.Builder
 		dictStr.WriteByte('{')
 		i := 0
-		for key, value := range source.entries {
+		for _, entry := range source.entries() {
+			key, value := entry.key, entry.value
 			if key == originalSource {
 				dictStr.WriteString(selfReferential(originalSource))
 			} else {
@@ -461,6 +616,62 @@ func getResult(source any, originalSource any, isPrintStmt bool) string {
 		}
 		dequeStr.WriteByte(']')
 		return dequeStr.String()
+	case *LoxSortedList:
+		nodes := source.tree.nodes()
+		var sortedListStr strings.Builder
+		sortedListStr.WriteString("SortedList [")
+		for i, node := range nodes {
+			if node.key == originalSource {
+				sortedListStr.WriteString(selfReferential(originalSource))
+			} else {
+				sortedListStr.WriteString(getResult(node.key, originalSource, false))
+			}
+			if i < len(nodes)-1 {
+				sortedListStr.WriteString(", ")
+			}
+		}
+		sortedListStr.WriteByte(']')
+		return sortedListStr.String()
+	case *LoxTreeMap:
+		nodes := source.tree.nodes()
+		var treeMapStr strings.Builder
+		treeMapStr.WriteString("TreeMap {")
+		for i, node := range nodes {
+			if node.key == originalSource {
+				treeMapStr.WriteString(selfReferential(originalSource))
+			} else {
+				treeMapStr.WriteString(getResult(node.key, originalSource, false))
+			}
+			treeMapStr.WriteString(": ")
+			if node.value == originalSource {
+				treeMapStr.WriteString(selfReferential(originalSource))
+			} else {
+				treeMapStr.WriteString(getResult(node.value, originalSource, false))
+			}
+			if i < len(nodes)-1 {
+				treeMapStr.WriteString(", ")
+			}
+		}
+		treeMapStr.WriteByte('}')
+		return treeMapStr.String()
+	case *LoxRecord:
+		var recordStr strings.Builder
+		recordStr.WriteString(source.recordType.name)
+		recordStr.WriteByte('(')
+		for i, field := range source.recordType.fields {
+			recordStr.WriteString(field)
+			recordStr.WriteByte('=')
+			if value := source.values[i]; value == originalSource {
+				recordStr.WriteString(selfReferential(originalSource))
+			} else {
+				recordStr.WriteString(getResult(value, originalSource, false))
+			}
+			if i < len(source.recordType.fields)-1 {
+				recordStr.WriteString(", ")
+			}
+		}
+		recordStr.WriteByte(')')
+		return recordStr.String()
 	case *LoxSet:
 		if len(source.elements) == 0 {
 			return "∅"
@@ -493,24 +704,13 @@ func printResultExpressionStmt(source any) {
 	}
 }
 
-func (i *Interpreter) Resolve(expr Expr, depth int) {
-	switch expr := expr.(type) {
-	case Assign:
-		i.locals[expr.Name] = depth
-	case Variable:
-		i.locals[expr.Name] = depth
-	default:
-		i.locals[expr] = depth
-	}
-}
-
 func (i *Interpreter) visitAssertStmt(stmt Assert) (any, error) {
 	assertValue, assertValueErr := i.evaluate(stmt.Value)
 	if assertValueErr != nil {
 		return nil, assertValueErr
 	}
 	if !i.isTruthy(assertValue) {
-		return nil, loxerror.RuntimeError(stmt.AssertToken, "AssertionError")
+		return nil, loxerror.RuntimeErrorKind(loxerror.AssertionError, stmt.AssertToken, "AssertionError")
 	}
 	return nil, nil
 }
@@ -520,9 +720,11 @@ func (i *Interpreter) visitAssignExpr(expr Assign) (any, error) {
 	if valueErr != nil {
 		return nil, valueErr
 	}
-	distance, ok := i.locals[expr.Name]
-	if ok {
-		i.environment.AssignAt(distance, expr.Name, value)
+	if expr.Resolution != nil && expr.Resolution.IsLocal {
+		assignErr := i.environment.AssignSlot(expr.Resolution.Depth, expr.Resolution.Slot, expr.Name, value)
+		if assignErr != nil {
+			return nil, assignErr
+		}
 	} else {
 		assignErr := i.globals.Assign(expr.Name, value)
 		if assignErr != nil {
@@ -550,13 +752,29 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 	}
 	unknownOpStr := "unknown operator"
 	unknownOp := func() error {
-		return runtimeErrorWrapper(fmt.Sprintf("%v '%v'.", unknownOpStr, expr.Operator.Lexeme))
+		return loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Operator,
+			fmt.Sprintf("%v '%v'.", unknownOpStr, expr.Operator.Lexeme))
 	}
 	unknownOpOn := func(str string) error {
-		return runtimeErrorWrapper(
+		return loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Operator,
 			fmt.Sprintf("%v '%v' on %v.", unknownOpStr, expr.Operator.Lexeme, str),
 		)
 	}
+	//Returns the result of the comparison operator given the result of
+	//comparing the operands, or false if the operator is not a comparison
+	compareOp := func(cmp int) (bool, bool) {
+		switch expr.Operator.TokenType {
+		case token.LESS:
+			return cmp < 0, true
+		case token.LESS_EQUAL:
+			return cmp <= 0, true
+		case token.GREATER:
+			return cmp > 0, true
+		case token.GREATER_EQUAL:
+			return cmp >= 0, true
+		}
+		return false, false
+	}
 	handleNumString := func(left float64, right *LoxString) (any, error) {
 		switch expr.Operator.TokenType {
 		case token.PLUS:
@@ -571,6 +789,9 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 				return EmptyLoxString(), nil
 			}
 			if util.FloatIsInt(left) {
+				if limitErr := checkSizeLimit(expr.Operator, int64(len(right.str))*int64(left)); limitErr != nil {
+					return nil, limitErr
+				}
 				return right.NewLoxString(strings.Repeat(right.str, int(left))), nil
 			}
 		}
@@ -674,12 +895,12 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return new(big.Int).Mul(left, right), nil
 		case token.SLASH:
 			if bigint.IsZero(right) {
-				return nil, runtimeErrorWrapper(divideByZeroMsg)
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
 			}
 			return new(big.Int).Div(left, right), nil
 		case token.PERCENT:
 			if bigint.IsZero(right) {
-				return nil, runtimeErrorWrapper(divideByZeroMsg)
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
 			}
 			return new(big.Int).Rem(left, right), nil
 		case token.DOUBLE_STAR:
@@ -795,29 +1016,178 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return nil, unknownOpOn("bigfloats")
 		}
 	}
+	handleTwoRationals := func(left *big.Rat, right *big.Rat) (any, error) {
+		divideByZeroMsg := "Cannot divide rational by 0."
+		switch expr.Operator.TokenType {
+		case token.PLUS:
+			return NewLoxRational(new(big.Rat).Add(left, right)), nil
+		case token.MINUS:
+			return NewLoxRational(new(big.Rat).Sub(left, right)), nil
+		case token.STAR:
+			return NewLoxRational(new(big.Rat).Mul(left, right)), nil
+		case token.SLASH:
+			if right.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
+			}
+			return NewLoxRational(new(big.Rat).Quo(left, right)), nil
+		case token.PERCENT:
+			if right.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
+			}
+			return NewLoxRational(ratMod(left, right)), nil
+		case token.DOUBLE_STAR:
+			if right.IsInt() && right.Num().IsInt64() {
+				result, ok := ratPow(left, right.Num().Int64())
+				if !ok {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator,
+						"Cannot raise rational 0 to a negative power.")
+				}
+				return NewLoxRational(result), nil
+			}
+			//Non-integer powers are generally irrational
+			leftFloat, _ := left.Float64()
+			rightFloat, _ := right.Float64()
+			return math.Pow(leftFloat, rightFloat), nil
+		case token.LESS:
+			return left.Cmp(right) < 0, nil
+		case token.LESS_EQUAL:
+			return left.Cmp(right) <= 0, nil
+		case token.GREATER:
+			return left.Cmp(right) > 0, nil
+		case token.GREATER_EQUAL:
+			return left.Cmp(right) >= 0, nil
+		default:
+			return nil, unknownOpOn("rationals")
+		}
+	}
+	handleTwoDecimals := func(left *LoxDecimal, right *LoxDecimal) (any, error) {
+		divideByZeroMsg := "Cannot divide decimal by 0."
+		switch expr.Operator.TokenType {
+		case token.PLUS:
+			return left.add(right), nil
+		case token.MINUS:
+			return left.sub(right), nil
+		case token.STAR:
+			return left.mul(right), nil
+		case token.SLASH:
+			if right.coef.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
+			}
+			return left.div(right, decimalPrecision.Load(), decimalRounding(decimalDefaultRounding.Load())), nil
+		case token.PERCENT:
+			if right.coef.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator, divideByZeroMsg)
+			}
+			return left.mod(right), nil
+		case token.DOUBLE_STAR:
+			if exponent := right.trimTo(0); exponent.scale == 0 && exponent.coef.IsInt64() {
+				result, ok := left.pow(exponent.coef.Int64())
+				if !ok {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, expr.Operator,
+						"Cannot raise decimal 0 to a negative power.")
+				}
+				return result, nil
+			}
+			//Non-integer powers are generally irrational
+			leftFloat, _ := left.rat().Float64()
+			rightFloat, _ := right.rat().Float64()
+			return math.Pow(leftFloat, rightFloat), nil
+		case token.LESS:
+			return left.cmp(right) < 0, nil
+		case token.LESS_EQUAL:
+			return left.cmp(right) <= 0, nil
+		case token.GREATER:
+			return left.cmp(right) > 0, nil
+		case token.GREATER_EQUAL:
+			return left.cmp(right) >= 0, nil
+		default:
+			return nil, unknownOpOn("decimals")
+		}
+	}
+	//Floats and bigfloats are converted to decimals using their shortest
+	//decimal representation, which fails for NaN and infinity
+	handleDecimalFloat := func(left any, right any) (any, error) {
+		leftDecimal, ok := toDecimal(left)
+		if !ok {
+			return math.NaN(), nil
+		}
+		rightDecimal, ok := toDecimal(right)
+		if !ok {
+			return math.NaN(), nil
+		}
+		return handleTwoDecimals(leftDecimal, rightDecimal)
+	}
+	handleTwoMatrices := func(left *LoxMatrix, right *LoxMatrix) (any, error) {
+		var result *LoxMatrix
+		var err error
+		switch expr.Operator.TokenType {
+		case token.PLUS:
+			result, err = left.zipWith(right, func(a float64, b float64) float64 { return a + b })
+		case token.MINUS:
+			result, err = left.zipWith(right, func(a float64, b float64) float64 { return a - b })
+		case token.STAR:
+			result, err = left.matmul(right)
+		default:
+			return nil, unknownOpOn("matrices")
+		}
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, expr.Operator, err.Error())
+		}
+		return result, nil
+	}
+	//Applies the operator to each element of the matrix and the number, where
+	//matrixOnLeft is whether the matrix is the left operand
+	handleMatrixNum := func(matrix *LoxMatrix, num float64, matrixOnLeft bool) (any, error) {
+		var fn func(a float64, b float64) float64
+		switch expr.Operator.TokenType {
+		case token.PLUS:
+			fn = func(a float64, b float64) float64 { return a + b }
+		case token.MINUS:
+			fn = func(a float64, b float64) float64 { return a - b }
+		case token.STAR:
+			fn = func(a float64, b float64) float64 { return a * b }
+		case token.SLASH:
+			fn = func(a float64, b float64) float64 { return a / b }
+		case token.DOUBLE_STAR:
+			if matrixOnLeft && util.FloatIsInt(num) {
+				result, err := matrix.pow(int64(num))
+				if err != nil {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, expr.Operator, err.Error())
+				}
+				return result, nil
+			}
+			return nil, unknownOpOn("matrices")
+		default:
+			return nil, unknownOpOn("matrices")
+		}
+		if matrixOnLeft {
+			return matrix.mapValues(func(value float64) float64 { return fn(value, num) }), nil
+		}
+		return matrix.mapValues(func(value float64) float64 { return fn(num, value) }), nil
+	}
 	handleTwoInts := func(left int64, right int64) (any, error) {
 		var result any
 		switch expr.Operator.TokenType {
 		case token.PLUS:
-			result = left + right
+			result = boxInt(left + right)
 		case token.MINUS:
-			result = left - right
+			result = boxInt(left - right)
 		case token.STAR:
-			result = left * right
+			result = boxInt(left * right)
 		case token.SLASH:
 			divResult := float64(left) / float64(right)
 			if util.FloatIsInt(divResult) {
-				result = int64(divResult)
+				result = boxInt(int64(divResult))
 			} else {
 				result = divResult
 			}
 		case token.PERCENT:
-			result = left % right
+			result = boxInt(left % right)
 		case token.DOUBLE_STAR:
-			result = int64(math.Pow(float64(left), float64(right)))
+			result = boxInt(int64(math.Pow(float64(left), float64(right))))
 		case token.DOUBLE_LESS:
 			if right >= 0 {
-				result = left << right
+				result = boxInt(left << right)
 			} else {
 				result = math.NaN()
 			}
@@ -827,7 +1197,7 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			result = left <= right
 		case token.DOUBLE_GREATER:
 			if right >= 0 {
-				result = left >> right
+				result = boxInt(left >> right)
 			} else {
 				result = math.NaN()
 			}
@@ -836,11 +1206,11 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 		case token.GREATER_EQUAL:
 			result = left >= right
 		case token.AMPERSAND:
-			result = left & right
+			result = boxInt(left & right)
 		case token.PIPE:
-			result = left | right
+			result = boxInt(left | right)
 		case token.CARET:
-			result = left ^ right
+			result = boxInt(left ^ right)
 		default:
 			return nil, unknownOp()
 		}
@@ -915,6 +1285,12 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 		if leftIsEquatable {
 			return leftEquatable.Equals(right), nil
 		}
+		if rightRational, ok := right.(*LoxRational); ok {
+			return rightRational.Equals(left), nil
+		}
+		if rightDecimal, ok := right.(*LoxDecimal); ok {
+			return rightDecimal.Equals(left), nil
+		}
 		switch left := left.(type) {
 		case int64:
 			switch right := right.(type) {
@@ -974,6 +1350,12 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 		if leftIsEquatable {
 			return !leftEquatable.Equals(right), nil
 		}
+		if rightRational, ok := right.(*LoxRational); ok {
+			return !rightRational.Equals(left), nil
+		}
+		if rightDecimal, ok := right.(*LoxDecimal); ok {
+			return !rightDecimal.Equals(left), nil
+		}
 		switch left := left.(type) {
 		case int64:
 			switch right := right.(type) {
@@ -1029,13 +1411,17 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 		return left != right, nil
 	}
 
+	//Strings are left as is so that concatenating them can append to their
+	//storage in place
 	if leftAsStringer, ok := left.(fmt.Stringer); ok {
-		if _, ok := right.(*LoxString); ok && expr.Operator.TokenType == token.PLUS {
+		_, leftIsString := left.(*LoxString)
+		if _, ok := right.(*LoxString); ok && !leftIsString && expr.Operator.TokenType == token.PLUS {
 			left = NewLoxStringQuote(leftAsStringer.String())
 		}
 	}
 	if rightAsStringer, ok := right.(fmt.Stringer); ok {
-		if _, ok := left.(*LoxString); ok && expr.Operator.TokenType == token.PLUS {
+		_, rightIsString := right.(*LoxString)
+		if _, ok := left.(*LoxString); ok && !rightIsString && expr.Operator.TokenType == token.PLUS {
 			right = NewLoxStringQuote(rightAsStringer.String())
 		}
 	}
@@ -1050,6 +1436,12 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigInts(big.NewInt(left), right)
 		case *big.Float:
 			return handleTwoBigFloats(bigfloat.New(float64(left)), right)
+		case *LoxRational:
+			return handleTwoRationals(new(big.Rat).SetInt64(left), right.rat)
+		case *LoxDecimal:
+			return handleTwoDecimals(NewLoxDecimal(big.NewInt(left), 0), right)
+		case *LoxMatrix:
+			return handleMatrixNum(right, float64(left), false)
 		case bool:
 			return handleTwoInts(left, boolMapInt[right])
 		case *LoxString:
@@ -1071,6 +1463,13 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigFloats(bigfloat.New(left), new(big.Float).SetInt(right))
 		case *big.Float:
 			return handleTwoBigFloats(bigfloat.New(left), right)
+		case *LoxRational:
+			rightFloat, _ := right.rat.Float64()
+			return handleTwoFloats(left, rightFloat)
+		case *LoxDecimal:
+			return handleDecimalFloat(left, right)
+		case *LoxMatrix:
+			return handleMatrixNum(right, left, false)
 		case bool:
 			return handleTwoFloats(left, boolMap[right])
 		case *LoxString:
@@ -1091,6 +1490,10 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigInts(left, right)
 		case *big.Float:
 			return handleTwoBigFloats(new(big.Float).SetInt(left), right)
+		case *LoxRational:
+			return handleTwoRationals(new(big.Rat).SetInt(left), right.rat)
+		case *LoxDecimal:
+			return handleTwoDecimals(NewLoxDecimalInt(left), right)
 		case bool:
 			return handleTwoBigInts(left, bigint.BoolMap[right])
 		case *LoxString:
@@ -1112,11 +1515,123 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigFloats(left, new(big.Float).SetInt(right))
 		case *big.Float:
 			return handleTwoBigFloats(left, right)
+		case *LoxRational:
+			return handleTwoBigFloats(left, new(big.Float).SetRat(right.rat))
+		case *LoxDecimal:
+			return handleDecimalFloat(left, right)
 		case bool:
 			return handleTwoBigFloats(left, bigfloat.BoolMap[right])
 		case nil:
 			return handleTwoBigFloats(left, bigfloat.BoolMap[false])
 		}
+	case *LoxRational:
+		switch right := right.(type) {
+		case int64:
+			return handleTwoRationals(left.rat, new(big.Rat).SetInt64(right))
+		case float64:
+			leftFloat, _ := left.rat.Float64()
+			return handleTwoFloats(leftFloat, right)
+		case *big.Int:
+			return handleTwoRationals(left.rat, new(big.Rat).SetInt(right))
+		case *big.Float:
+			return handleTwoBigFloats(new(big.Float).SetRat(left.rat), right)
+		case *LoxRational:
+			return handleTwoRationals(left.rat, right.rat)
+		case *LoxDecimal:
+			return handleTwoRationals(left.rat, right.rat())
+		case bool:
+			return handleTwoRationals(left.rat, new(big.Rat).SetInt64(boolMapInt[right]))
+		case nil:
+			return handleTwoRationals(left.rat, new(big.Rat))
+		}
+	case *LoxDecimal:
+		switch right := right.(type) {
+		case int64:
+			return handleTwoDecimals(left, NewLoxDecimal(big.NewInt(right), 0))
+		case float64:
+			return handleDecimalFloat(left, right)
+		case *big.Int:
+			return handleTwoDecimals(left, NewLoxDecimalInt(right))
+		case *big.Float:
+			return handleDecimalFloat(left, right)
+		case *LoxRational:
+			return handleTwoRationals(left.rat(), right.rat)
+		case *LoxDecimal:
+			return handleTwoDecimals(left, right)
+		case bool:
+			return handleTwoDecimals(left, NewLoxDecimal(big.NewInt(boolMapInt[right]), 0))
+		case nil:
+			return handleTwoDecimals(left, NewLoxDecimal(new(big.Int), 0))
+		}
+	case *LoxMatrix:
+		switch right := right.(type) {
+		case int64:
+			return handleMatrixNum(left, float64(right), true)
+		case float64:
+			return handleMatrixNum(left, right, true)
+		case *LoxMatrix:
+			return handleTwoMatrices(left, right)
+		}
+	case *LoxBitset:
+		switch right := right.(type) {
+		case *LoxBitset:
+			switch expr.Operator.TokenType {
+			case token.AMPERSAND:
+				return left.combine(right, func(a uint64, b uint64) uint64 { return a & b }), nil
+			case token.PIPE:
+				return left.combine(right, func(a uint64, b uint64) uint64 { return a | b }), nil
+			case token.CARET:
+				return left.combine(right, func(a uint64, b uint64) uint64 { return a ^ b }), nil
+			case token.MINUS:
+				return left.combine(right, func(a uint64, b uint64) uint64 { return a &^ b }), nil
+			}
+		}
+	case *LoxIPAddress:
+		handleIPAddrNum := func(num *big.Int) (any, error) {
+			switch expr.Operator.TokenType {
+			case token.PLUS:
+			case token.MINUS:
+				num = new(big.Int).Neg(num)
+			default:
+				return nil, unknownOp()
+			}
+			result, err := left.add(num)
+			if err != nil {
+				return nil, runtimeErrorWrapper(err.Error())
+			}
+			return result, nil
+		}
+		switch right := right.(type) {
+		case int64:
+			return handleIPAddrNum(big.NewInt(right))
+		case *big.Int:
+			return handleIPAddrNum(right)
+		case *LoxIPAddress:
+			switch expr.Operator.TokenType {
+			case token.MINUS:
+				if left.version() != right.version() {
+					return nil, runtimeErrorWrapper("Cannot subtract IP addresses of different versions.")
+				}
+				return bigIntOrInt(new(big.Int).Sub(ipAddrToBig(left.addr), ipAddrToBig(right.addr))), nil
+			}
+			if result, ok := compareOp(left.addr.Compare(right.addr)); ok {
+				return result, nil
+			}
+		}
+	case *LoxULID:
+		switch right := right.(type) {
+		case *LoxULID:
+			if result, ok := compareOp(bytes.Compare(left.ulid[:], right.ulid[:])); ok {
+				return result, nil
+			}
+		}
+	case *LoxUUID:
+		switch right := right.(type) {
+		case *LoxUUID:
+			if result, ok := compareOp(bytes.Compare(left.uuid[:], right.uuid[:])); ok {
+				return result, nil
+			}
+		}
 	case bool:
 		switch right := right.(type) {
 		case int64:
@@ -1127,6 +1642,10 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigInts(bigint.BoolMap[left], right)
 		case *big.Float:
 			return handleTwoBigFloats(bigfloat.BoolMap[left], right)
+		case *LoxRational:
+			return handleTwoRationals(new(big.Rat).SetInt64(boolMapInt[left]), right.rat)
+		case *LoxDecimal:
+			return handleTwoDecimals(NewLoxDecimal(big.NewInt(boolMapInt[left]), 0), right)
 		case bool:
 			return handleTwoInts(boolMapInt[left], boolMapInt[right])
 		case *LoxString:
@@ -1148,35 +1667,35 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 		case token.PLUS:
 			switch right := right.(type) {
 			case int64:
-				return left.NewLoxString(left.str + util.FormatFloat(float64(right))), nil
+				return left.concat(util.FormatFloat(float64(right))), nil
 			case float64:
 				if math.IsInf(right, 1) {
 					return left.NewLoxString("Infinity" + left.str), nil
 				} else if math.IsInf(right, -1) {
 					return left.NewLoxString("-Infinity" + left.str), nil
 				}
-				return left.NewLoxString(left.str + util.FormatFloat(right)), nil
+				return left.concat(util.FormatFloat(right)), nil
 			case bool:
-				return left.NewLoxString(left.str + strconv.FormatBool(right)), nil
+				return left.concat(strconv.FormatBool(right)), nil
 			case *LoxString:
-				if left.quote == '"' || right.quote == '"' {
-					return NewLoxString(left.str+right.str, '"'), nil
-				}
-				return NewLoxString(left.str+right.str, '\''), nil
+				return left.concat(right.str), nil
 			case *LoxBuffer:
-				return left.NewLoxString(left.str + right.String()), nil
+				return left.concat(right.String()), nil
 			case *LoxDict:
-				return left.NewLoxString(left.str + right.String()), nil
+				return left.concat(right.String()), nil
 			case *LoxList:
-				return left.NewLoxString(left.str + right.String()), nil
+				return left.concat(right.String()), nil
 			case nil:
-				return left.NewLoxString(left.str + "nil"), nil
+				return left.concat("nil"), nil
 			}
 		case token.STAR:
 			repeat := func(left *LoxString, right int64) (*LoxString, error) {
 				if right <= 0 {
 					return EmptyLoxString(), nil
 				}
+				if limitErr := checkSizeLimit(expr.Operator, int64(len(left.str))*right); limitErr != nil {
+					return nil, limitErr
+				}
 				return left.NewLoxString(strings.Repeat(left.str, int(right))), nil
 			}
 			switch right := right.(type) {
@@ -1218,6 +1737,9 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 				if right <= 0 || len(left.elements) == 0 {
 					return EmptyLoxBuffer(), nil
 				}
+				if limitErr := checkSizeLimit(expr.Operator, int64(len(left.elements))*right*8); limitErr != nil {
+					return nil, limitErr
+				}
 				newBuffer := EmptyLoxBufferCap(int64(len(left.elements)) * right)
 				for i := int64(0); i < right; i++ {
 					for _, element := range left.elements {
@@ -1251,11 +1773,11 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			switch right := right.(type) {
 			case *LoxDict:
 				newDict := NewLoxDict(make(map[any]any))
-				for key, value := range left.entries {
-					newDict.setKeyValue(key, value)
+				for _, entry := range left.entries() {
+					newDict.setKeyValue(entry.key, entry.value)
 				}
-				for key, value := range right.entries {
-					newDict.setKeyValue(key, value)
+				for _, entry := range right.entries() {
+					newDict.setKeyValue(entry.key, entry.value)
 				}
 				return newDict, nil
 			}
@@ -1282,6 +1804,9 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 				if right <= 0 || len(left.elements) == 0 {
 					return EmptyLoxList(), nil
 				}
+				if limitErr := checkSizeLimit(expr.Operator, int64(len(left.elements))*right*16); limitErr != nil {
+					return nil, limitErr
+				}
 				newList := list.NewListCap[any](int64(len(left.elements)) * right)
 				for i := int64(0); i < right; i++ {
 					for _, element := range left.elements {
@@ -1333,6 +1858,10 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 			return handleTwoBigInts(bigint.BoolMap[false], right)
 		case *big.Float:
 			return handleTwoBigFloats(bigfloat.BoolMap[false], right)
+		case *LoxRational:
+			return handleTwoRationals(new(big.Rat), right.rat)
+		case *LoxDecimal:
+			return handleTwoDecimals(NewLoxDecimal(new(big.Int), 0), right)
 		case bool:
 			return handleTwoInts(0, boolMapInt[right])
 		case *LoxString:
@@ -1360,14 +1889,59 @@ func (i *Interpreter) visitBinaryExpr(expr Binary) (any, error) {
 	return math.NaN(), nil
 }
 
+// Returns an error if the specified callable can't be called with the
+// specified number of arguments
+func checkCallArity(function LoxCallable, argsLen int, callToken *token.Token) error {
+	arity := function.arity()
+	if arity >= 0 && argsLen != arity {
+		if arity == 1 {
+			return loxerror.RuntimeErrorKind(loxerror.TypeError, callToken,
+				fmt.Sprintf("Expected %v argument but got %v.", arity, argsLen),
+			)
+		}
+		return loxerror.RuntimeErrorKind(loxerror.TypeError, callToken,
+			fmt.Sprintf("Expected %v arguments but got %v.", arity, argsLen),
+		)
+	}
+	if function, ok := function.(LoxArityRangeCallable); ok && arity < 0 {
+		minArity, maxArity := function.arityRange()
+		if argsLen < minArity || (maxArity >= 0 && argsLen > maxArity) {
+			var errMsg string
+			switch {
+			case maxArity < 0:
+				errMsg = fmt.Sprintf("Expected at least %v arguments but got %v.", minArity, argsLen)
+			case maxArity == minArity+1:
+				errMsg = fmt.Sprintf("Expected %v or %v arguments but got %v.", minArity, maxArity, argsLen)
+			default:
+				errMsg = fmt.Sprintf("Expected %v to %v arguments but got %v.", minArity, maxArity, argsLen)
+			}
+			return loxerror.RuntimeErrorKind(loxerror.TypeError, callToken, errMsg)
+		}
+	}
+	return nil
+}
+
 func (i *Interpreter) visitCallExpr(expr Call) (any, error) {
 	callee, calleeErr := i.evaluate(expr.Callee)
 	if calleeErr != nil {
 		return nil, calleeErr
 	}
 	arguments := list.NewListCap[any](int64(len(expr.Arguments)))
+	var namedArgNames []*token.Token
+	var namedArgs map[string]any
 	for _, argument := range expr.Arguments {
 		switch argument := argument.(type) {
+		case NamedArgument:
+			result, resultErr := i.evaluate(argument.Value)
+			if resultErr != nil {
+				arguments.Clear()
+				return nil, resultErr
+			}
+			if namedArgs == nil {
+				namedArgs = make(map[string]any)
+			}
+			namedArgNames = append(namedArgNames, argument.Name)
+			namedArgs[argument.Name.Lexeme] = result
 		case Spread:
 			result, resultErr := i.evaluate(argument.Iterable)
 			if resultErr != nil {
@@ -1395,17 +1969,38 @@ func (i *Interpreter) visitCallExpr(expr Call) (any, error) {
 		}
 	}
 	if function, ok := callee.(LoxCallable); ok {
-		argsLen := len(arguments)
-		arity := function.arity()
-		if arity >= 0 && argsLen != arity {
-			if arity == 1 {
-				return nil, loxerror.RuntimeError(expr.Paren,
-					fmt.Sprintf("Expected %v argument but got %v.", arity, argsLen),
-				)
-			}
-			return nil, loxerror.RuntimeError(expr.Paren,
-				fmt.Sprintf("Expected %v arguments but got %v.", arity, argsLen),
-			)
+		if len(namedArgNames) > 0 {
+			switch function := function.(type) {
+			case LoxNamedArgsCallable:
+				checkErr := function.checkNamedArgs(expr.Paren, len(arguments), namedArgNames)
+				if checkErr != nil {
+					return nil, checkErr
+				}
+				prevToken := i.callToken
+				defer func() {
+					i.callToken = prevToken
+				}()
+				i.callToken = expr.Paren
+				return function.callNamed(i, arguments, namedArgs)
+			case *struct{ ProtoLoxCallable }:
+				if function.paramNames == nil {
+					return nil, loxerror.RuntimeError(namedArgNames[0],
+						"Function cannot be called with named arguments.")
+				}
+				positional, positionalErr := namedArgsToPositional(
+					expr.Paren, function.paramNames, arguments, namedArgNames, namedArgs)
+				if positionalErr != nil {
+					return nil, positionalErr
+				}
+				arguments = positional
+			default:
+				return nil, loxerror.RuntimeError(namedArgNames[0],
+					"Function cannot be called with named arguments.")
+			}
+		}
+		arityErr := checkCallArity(function, len(arguments), expr.Paren)
+		if arityErr != nil {
+			return nil, arityErr
 		}
 		switch function := function.(type) {
 		case LoxBuiltInProtoCallable:
@@ -1418,7 +2013,7 @@ func (i *Interpreter) visitCallExpr(expr Call) (any, error) {
 		i.callToken = expr.Paren
 		return function.call(i, arguments)
 	}
-	return nil, loxerror.RuntimeError(expr.Paren, "Can only call functions and classes.")
+	return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Paren, "Can only call functions and classes.")
 }
 
 func (i *Interpreter) visitClassStmt(stmt Class) (any, error) {
@@ -1484,6 +2079,8 @@ func (i *Interpreter) visitClassStmt(stmt Class) (any, error) {
 		instanceFields,
 		stmt.CanInstantiate,
 		false,
+		false,
+		nil,
 	}
 	i.environment.Assign(stmt.Name, loxClass)
 	return nil, nil
@@ -1543,35 +2140,14 @@ func (i *Interpreter) visitDictExpr(expr Dict) (any, error) {
 
 func (i *Interpreter) visitDoWhileStmt(stmt DoWhile) (any, error) {
 	firstIteration := true
-	enteredLoop := false
-	loopInterrupted := false
 	var result any
 	var conditionErr error
 	for cond := true; cond; {
 		if conditionErr != nil {
 			return nil, conditionErr
 		}
-		if loopInterrupted {
-			return nil, loxerror.RuntimeError(stmt.DoToken, "loop interrupted")
-		}
-		if !firstIteration && !enteredLoop {
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			defer func() {
-				if !loopInterrupted {
-					sigChan <- loxsignal.LoopSignal{}
-					signal.Stop(sigChan)
-				}
-			}()
-			go func() {
-				sig := <-sigChan
-				switch sig {
-				case os.Interrupt:
-					loopInterrupted = true
-					signal.Stop(sigChan)
-				}
-			}()
-			enteredLoop = true
+		if i.isInterrupted() {
+			return nil, i.interruptError(stmt.DoToken)
 		}
 		value, evalErr := i.evaluate(stmt.Body)
 		if evalErr != nil {
@@ -1674,8 +2250,6 @@ func (i *Interpreter) visitEnumStmt(stmt Enum) (any, error) {
 }
 
 func (i *Interpreter) visitForStmt(stmt For) (any, error) {
-	enteredLoop := false
-	loopInterrupted := false
 
 	tempEnvironment := env.NewEnvironmentEnclosing(i.environment)
 	previous := i.environment
@@ -1690,32 +2264,16 @@ func (i *Interpreter) visitForStmt(stmt For) (any, error) {
 			return nil, initializerErr
 		}
 	}
+	if value, ok, err := i.runIntLoop(stmt.ForToken, stmt.Condition, stmt.Increment, stmt.Body); ok {
+		return value, err
+	}
 	if stmt.Condition != nil {
 		for result, conditionErr := i.evaluate(stmt.Condition); conditionErr != nil || i.isTruthy(result); {
 			if conditionErr != nil {
 				return nil, conditionErr
 			}
-			if loopInterrupted {
-				return nil, loxerror.RuntimeError(stmt.ForToken, "loop interrupted")
-			}
-			if !enteredLoop {
-				sigChan := make(chan os.Signal, 1)
-				signal.Notify(sigChan, os.Interrupt)
-				defer func() {
-					if !loopInterrupted {
-						sigChan <- loxsignal.LoopSignal{}
-						signal.Stop(sigChan)
-					}
-				}()
-				go func() {
-					sig := <-sigChan
-					switch sig {
-					case os.Interrupt:
-						loopInterrupted = true
-						signal.Stop(sigChan)
-					}
-				}()
-				enteredLoop = true
+			if i.isInterrupted() {
+				return nil, i.interruptError(stmt.ForToken)
 			}
 			value, evalErr := i.evaluate(stmt.Body)
 			if evalErr != nil {
@@ -1737,27 +2295,8 @@ func (i *Interpreter) visitForStmt(stmt For) (any, error) {
 		}
 	} else {
 		for {
-			if loopInterrupted {
-				return nil, loxerror.RuntimeError(stmt.ForToken, "loop interrupted")
-			}
-			if !enteredLoop {
-				sigChan := make(chan os.Signal, 1)
-				signal.Notify(sigChan, os.Interrupt)
-				defer func() {
-					if !loopInterrupted {
-						sigChan <- loxsignal.LoopSignal{}
-						signal.Stop(sigChan)
-					}
-				}()
-				go func() {
-					sig := <-sigChan
-					switch sig {
-					case os.Interrupt:
-						loopInterrupted = true
-						signal.Stop(sigChan)
-					}
-				}()
-				enteredLoop = true
+			if i.isInterrupted() {
+				return nil, i.interruptError(stmt.ForToken)
 			}
 			value, evalErr := i.evaluate(stmt.Body)
 			if evalErr != nil {
@@ -1804,30 +2343,9 @@ func (i *Interpreter) visitForEachStmt(stmt ForEach) (any, error) {
 		}()
 	}
 
-	enteredLoop := false
-	loopInterrupted := false
 	for iterator.HasNext() {
-		if loopInterrupted {
-			return nil, loxerror.RuntimeError(stmt.ForEachToken, "loop interrupted")
-		}
-		if !enteredLoop {
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			defer func() {
-				if !loopInterrupted {
-					sigChan <- loxsignal.LoopSignal{}
-					signal.Stop(sigChan)
-				}
-			}()
-			go func() {
-				sig := <-sigChan
-				switch sig {
-				case os.Interrupt:
-					loopInterrupted = true
-					signal.Stop(sigChan)
-				}
-			}()
-			enteredLoop = true
+		if i.isInterrupted() {
+			return nil, i.interruptError(stmt.ForEachToken)
 		}
 		tempEnvironment.Define(stmt.VariableName.Lexeme, iterator.Next())
 		var value any
@@ -1847,6 +2365,9 @@ func (i *Interpreter) visitForEachStmt(stmt ForEach) (any, error) {
 			}
 		}
 	}
+	if err := iteratorErr(iterator); err != nil {
+		return nil, loxerror.RuntimeError(stmt.ForEachToken, err.Error())
+	}
 	return nil, nil
 }
 
@@ -1865,6 +2386,9 @@ func (i *Interpreter) visitGetExpr(expr Get) (any, error) {
 	if objErr != nil {
 		return nil, objErr
 	}
+	if obj == nil && expr.Optional {
+		return nil, nil
+	}
 	if obj, ok := obj.(LoxObject); ok {
 		get, getErr := obj.Get(expr.Name)
 		switch get := get.(type) {
@@ -1876,7 +2400,7 @@ func (i *Interpreter) visitGetExpr(expr Get) (any, error) {
 		}
 		return get, getErr
 	}
-	return nil, loxerror.RuntimeError(expr.Name,
+	return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Name,
 		fmt.Sprintf("Type '%v' does not have properties.", getType(obj)))
 }
 
@@ -1922,8 +2446,20 @@ func (i *Interpreter) visitImportStmt(stmt Import) (any, error) {
 			"Import file must be a string.")
 	}
 
+	if sandboxErr := checkSandbox(stmt.ImportToken, "Importing files"); sandboxErr != nil {
+		return nil, sandboxErr
+	}
+
 	importFilePath := importFileObj.(*LoxString).str
 	importFile, openFileError := os.Open(importFilePath)
+	if openFileError != nil && !filepath.IsAbs(importFilePath) {
+		for _, importPath := range util.ImportPaths {
+			importFile, openFileError = os.Open(filepath.Join(importPath, importFilePath))
+			if openFileError == nil {
+				break
+			}
+		}
+	}
 	if openFileError != nil {
 		return nil, loxerror.RuntimeError(stmt.ImportToken,
 			fmt.Sprintf("Could not find file '%v'.", importFilePath))
@@ -1991,6 +2527,9 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 	if indexElementErr != nil {
 		return nil, indexElementErr
 	}
+	if indexElement == nil && expr.Optional {
+		return nil, nil
+	}
 
 	indexVal, indexValErr := i.evaluate(expr.Index)
 	if indexValErr != nil {
@@ -2026,7 +2565,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, StringIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, StringIndexMustBeWholeNum(indexVal))
 			}
 			switch indexEndVal := indexEndVal.(type) {
 			case int64:
@@ -2037,7 +2576,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexEndValInt = indexEndVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, StringIndexMustBeWholeNum(indexEndVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, StringIndexMustBeWholeNum(indexEndVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
@@ -2050,7 +2589,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				indexEndValInt = int64(utf8.RuneCountInString(indexElement.str))
 			}
 			if indexValInt < 0 {
-				return nil, loxerror.RuntimeError(expr.Bracket, StringIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, StringIndexOutOfRange(originalIndexValInt))
 			}
 			if indexValInt > indexEndValInt {
 				return EmptyLoxString(), nil
@@ -2067,14 +2606,14 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, StringIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, StringIndexMustBeWholeNum(indexVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
 				indexValInt += int64(utf8.RuneCountInString(indexElement.str))
 			}
 			if indexValInt < 0 || indexValInt >= int64(utf8.RuneCountInString(indexElement.str)) {
-				return nil, loxerror.RuntimeError(expr.Bracket, StringIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, StringIndexOutOfRange(originalIndexValInt))
 			}
 			str := string([]rune(indexElement.str)[indexValInt])
 			if str == "'" {
@@ -2101,7 +2640,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BufferIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BufferIndexMustBeWholeNum(indexVal))
 			}
 			switch indexEndVal := indexEndVal.(type) {
 			case int64:
@@ -2112,7 +2651,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexEndValInt = indexEndVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BufferIndexMustBeWholeNum(indexEndVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BufferIndexMustBeWholeNum(indexEndVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
@@ -2125,7 +2664,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				indexEndValInt = int64(len(indexElement.elements))
 			}
 			if indexValInt < 0 {
-				return nil, loxerror.RuntimeError(expr.Bracket, BufferIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, BufferIndexOutOfRange(originalIndexValInt))
 			}
 			capacity := indexEndValInt - indexValInt
 			if capacity < 0 {
@@ -2147,14 +2686,14 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BufferIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BufferIndexMustBeWholeNum(indexVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
 				indexValInt += int64(len(indexElement.elements))
 			}
 			if indexValInt < 0 || indexValInt >= int64(len(indexElement.elements)) {
-				return nil, loxerror.RuntimeError(expr.Bracket, BufferIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, BufferIndexOutOfRange(originalIndexValInt))
 			}
 			return indexElement.elements[indexValInt], nil
 		}
@@ -2164,7 +2703,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 		}
 		value, ok := indexElement.getValueByKey(indexVal)
 		if !ok {
-			return nil, loxerror.RuntimeError(expr.Bracket, UnknownDictKey(indexVal))
+			return nil, loxerror.RuntimeErrorKind(loxerror.KeyError, expr.Bracket, UnknownDictKey(indexVal))
 		}
 		return value, nil
 	case *LoxList:
@@ -2186,7 +2725,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, ListIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, ListIndexMustBeWholeNum(indexVal))
 			}
 			switch indexEndVal := indexEndVal.(type) {
 			case int64:
@@ -2197,7 +2736,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexEndValInt = indexEndVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, ListIndexMustBeWholeNum(indexEndVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, ListIndexMustBeWholeNum(indexEndVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
@@ -2210,7 +2749,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				indexEndValInt = int64(len(indexElement.elements))
 			}
 			if indexValInt < 0 {
-				return nil, loxerror.RuntimeError(expr.Bracket, ListIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, ListIndexOutOfRange(originalIndexValInt))
 			}
 			capacity := indexEndValInt - indexValInt
 			if capacity < 0 {
@@ -2232,17 +2771,42 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, ListIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, ListIndexMustBeWholeNum(indexVal))
 			}
 			originalIndexValInt := indexValInt
 			if indexValInt < 0 {
 				indexValInt += int64(len(indexElement.elements))
 			}
 			if indexValInt < 0 || indexValInt >= int64(len(indexElement.elements)) {
-				return nil, loxerror.RuntimeError(expr.Bracket, ListIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, ListIndexOutOfRange(originalIndexValInt))
 			}
 			return indexElement.elements[indexValInt], nil
 		}
+	case *LoxRecord:
+		if expr.IsSlice {
+			return nil, loxerror.RuntimeError(expr.Bracket, "Cannot use slice to index into record.")
+		}
+		var indexValInt int64
+		switch indexVal := indexVal.(type) {
+		case int64:
+			indexValInt = indexVal
+		case *big.Int:
+			if !indexVal.IsInt64() {
+				return invalidBigintErr(indexVal)
+			}
+			indexValInt = indexVal.Int64()
+		default:
+			return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, IndexMustBeWholeNum("Record", indexVal))
+		}
+		originalIndexValInt := indexValInt
+		if indexValInt < 0 {
+			indexValInt += indexElement.Length()
+		}
+		if indexValInt < 0 || indexValInt >= indexElement.Length() {
+			return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket,
+				fmt.Sprintf("Record index %v out of range.", originalIndexValInt))
+		}
+		return indexElement.values[indexValInt], nil
 	case *LoxRange:
 		if expr.IsSlice {
 			rangeLength := indexElement.Length()
@@ -2263,7 +2827,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, RangeIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, RangeIndexMustBeWholeNum(indexVal))
 			}
 			switch indexEndVal := indexEndVal.(type) {
 			case int64:
@@ -2274,7 +2838,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexEndValInt = indexEndVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, RangeIndexMustBeWholeNum(indexEndVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, RangeIndexMustBeWholeNum(indexEndVal))
 			}
 			if indexValInt < 0 {
 				indexValInt += rangeLength
@@ -2297,7 +2861,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				}
 				indexValInt = indexVal.Int64()
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, RangeIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, RangeIndexMustBeWholeNum(indexVal))
 			}
 			originalIndexValInt := indexValInt
 			rangeLength := indexElement.Length()
@@ -2305,7 +2869,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				indexValInt += rangeLength
 			}
 			if indexValInt < 0 || indexValInt >= rangeLength {
-				return nil, loxerror.RuntimeError(expr.Bracket, RangeIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, RangeIndexOutOfRange(originalIndexValInt))
 			}
 			return indexElement.get(indexValInt), nil
 		}
@@ -2332,7 +2896,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 					indexValInt = new(big.Int).Set(indexVal)
 				}
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
 			}
 			switch indexEndVal := indexEndVal.(type) {
 			case int64:
@@ -2344,7 +2908,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 					indexEndValInt = new(big.Int).Set(indexEndVal)
 				}
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
 			}
 			if indexValInt.Cmp(bigint.Zero) < 0 {
 				indexValInt.Add(indexValInt, rangeLength)
@@ -2364,7 +2928,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 			case *big.Int:
 				indexValInt = new(big.Int).Set(indexVal)
 			default:
-				return nil, loxerror.RuntimeError(expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Bracket, BigRangeIndexMustBeWholeNum(indexVal))
 			}
 			originalIndexValInt := new(big.Int).Set(indexValInt)
 			rangeLength := big.NewInt(indexElement.Length())
@@ -2372,7 +2936,7 @@ func (i *Interpreter) visitIndexExpr(expr Index) (any, error) {
 				indexValInt.Add(indexValInt, rangeLength)
 			}
 			if indexValInt.Cmp(bigint.Zero) < 0 || indexValInt.Cmp(rangeLength) >= 0 {
-				return nil, loxerror.RuntimeError(expr.Bracket, BigRangeIndexOutOfRange(originalIndexValInt))
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Bracket, BigRangeIndexOutOfRange(originalIndexValInt))
 			}
 			return indexElement.get(indexValInt), nil
 		}
@@ -2423,42 +2987,28 @@ func (i *Interpreter) visitLogicalExpr(expr Logical) (any, error) {
 	if leftErr != nil {
 		return nil, leftErr
 	}
-	if expr.Operator.TokenType == token.OR {
+	switch expr.Operator.TokenType {
+	case token.OR:
 		if i.isTruthy(left) {
 			return left, nil
 		}
-	} else if !i.isTruthy(left) {
-		return left, nil
+	case token.DOUBLE_QUESTION:
+		if left != nil {
+			return left, nil
+		}
+	default:
+		if !i.isTruthy(left) {
+			return left, nil
+		}
 	}
 	return i.evaluate(expr.Right)
 }
 
 func (i *Interpreter) visitLoopStmt(stmt Loop) (any, error) {
 	loopBlock := stmt.LoopBlock.(Block)
-	enteredLoop := false
-	loopInterrupted := false
 	for {
-		if !enteredLoop {
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			defer func() {
-				if !loopInterrupted {
-					sigChan <- loxsignal.LoopSignal{}
-					signal.Stop(sigChan)
-				}
-			}()
-			go func() {
-				sig := <-sigChan
-				switch sig {
-				case os.Interrupt:
-					loopInterrupted = true
-					signal.Stop(sigChan)
-				}
-			}()
-			enteredLoop = true
-		}
-		if loopInterrupted {
-			return nil, loxerror.RuntimeError(stmt.LoopToken, "loop interrupted")
+		if i.isInterrupted() {
+			return nil, i.interruptError(stmt.LoopToken)
 		}
 		value, evalErr := i.visitBlockStmt(loopBlock)
 		if evalErr != nil {
@@ -2509,33 +3059,12 @@ func (i *Interpreter) visitRepeatStmt(stmt Repeat) (any, error) {
 		return nil, loxerror.RuntimeError(stmt.RepeatToken,
 			"Repeat statement expression must be an integer or bigint.")
 	}
-	enteredLoop := false
-	loopInterrupted := false
 	if useBigInt {
 		times := repeatTimesBigInt
 		one := bigint.BoolMap[true]
 		for count := big.NewInt(0); count.Cmp(times) < 0; count.Add(count, one) {
-			if !enteredLoop {
-				sigChan := make(chan os.Signal, 1)
-				signal.Notify(sigChan, os.Interrupt)
-				defer func() {
-					if !loopInterrupted {
-						sigChan <- loxsignal.LoopSignal{}
-						signal.Stop(sigChan)
-					}
-				}()
-				go func() {
-					sig := <-sigChan
-					switch sig {
-					case os.Interrupt:
-						loopInterrupted = true
-						signal.Stop(sigChan)
-					}
-				}()
-				enteredLoop = true
-			}
-			if loopInterrupted {
-				return nil, loxerror.RuntimeError(stmt.RepeatToken, "loop interrupted")
+			if i.isInterrupted() {
+				return nil, i.interruptError(stmt.RepeatToken)
 			}
 			value, evalErr := i.evaluate(stmt.Body)
 			if evalErr != nil {
@@ -2550,27 +3079,8 @@ func (i *Interpreter) visitRepeatStmt(stmt Repeat) (any, error) {
 		}
 	} else {
 		for count := int64(0); count < repeatTimes; count++ {
-			if !enteredLoop {
-				sigChan := make(chan os.Signal, 1)
-				signal.Notify(sigChan, os.Interrupt)
-				defer func() {
-					if !loopInterrupted {
-						sigChan <- loxsignal.LoopSignal{}
-						signal.Stop(sigChan)
-					}
-				}()
-				go func() {
-					sig := <-sigChan
-					switch sig {
-					case os.Interrupt:
-						loopInterrupted = true
-						signal.Stop(sigChan)
-					}
-				}()
-				enteredLoop = true
-			}
-			if loopInterrupted {
-				return nil, loxerror.RuntimeError(stmt.RepeatToken, "loop interrupted")
+			if i.isInterrupted() {
+				return nil, i.interruptError(stmt.RepeatToken)
 			}
 			value, evalErr := i.evaluate(stmt.Body)
 			if evalErr != nil {
@@ -2605,6 +3115,11 @@ func (i *Interpreter) visitSetExpr(expr Set) (any, error) {
 	if objErr != nil {
 		return nil, objErr
 	}
+	if binary, ok := expr.Value.(Binary); ok && binary.Compound {
+		//Reuse the object so that "a.b op= c" only evaluates "a" once
+		binary.Left = Get{Object: Literal{Value: obj}, Name: expr.Name}
+		expr.Value = binary
+	}
 	evaluateAndSet := func(setFunc func(value any)) (any, error) {
 		value, valueErr := i.evaluate(expr.Value)
 		if valueErr != nil {
@@ -2623,6 +3138,9 @@ func (i *Interpreter) visitSetExpr(expr Set) (any, error) {
 			class.classProperties[expr.Name.Lexeme] = value
 		})
 	}
+	if _, ok := obj.(*LoxRecord); ok {
+		return nil, loxerror.RuntimeError(expr.Name, "Cannot set fields of records since records are immutable.")
+	}
 	return nil, loxerror.RuntimeError(expr.Name, "Only classes and instances have properties that can be set.")
 }
 
@@ -2645,6 +3163,12 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 	if variableErr != nil {
 		return nil, variableErr
 	}
+	if binary, ok := expr.Value.(Binary); ok && binary.Compound {
+		//Reuse the indexed value and indexes so that "a[b] op= c" only
+		//evaluates "a" and "b" once
+		binary.Left = compoundIndexTarget(binary.Left, variable, indexes)
+		expr.Value = binary
+	}
 	assignErrMsg := "Can only assign to buffer, dictionary, and list indexes."
 	switch variable := variable.(type) {
 	case *LoxBuffer:
@@ -2664,7 +3188,7 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 			}
 			return value, nil
 		default:
-			return nil, loxerror.RuntimeError(expr.Name, BufferIndexMustBeWholeNum(index))
+			return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Name, BufferIndexMustBeWholeNum(index))
 		}
 	case *LoxDict:
 		value, valueErr := i.evaluate(expr.Value)
@@ -2685,6 +3209,10 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 					return nil, loxerror.RuntimeError(expr.Name, assignErrMsg)
 				}
 			} else {
+				if variable.frozen {
+					return nil, loxerror.RuntimeError(expr.Name,
+						"Cannot set keys of frozen dictionaries since frozen dictionaries are immutable.")
+				}
 				canBeKey, keyErr := CanBeDictKeyCheck(index)
 				if !canBeKey {
 					return nil, loxerror.RuntimeError(expr.Name, keyErr)
@@ -2708,7 +3236,7 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 				}
 				if loopIndex > 0 {
 					if index < 0 || index >= int64(len(variable.elements)) {
-						return nil, loxerror.RuntimeError(expr.Name, ListIndexOutOfRange(originalIndex))
+						return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Name, ListIndexOutOfRange(originalIndex))
 					}
 					var ok bool
 					variable, ok = variable.elements[index].(*LoxList)
@@ -2716,13 +3244,17 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 						return nil, loxerror.RuntimeError(expr.Name, assignErrMsg)
 					}
 				} else {
+					if variable.frozen {
+						return nil, loxerror.RuntimeError(expr.Name,
+							"Cannot set elements of frozen lists since frozen lists are immutable.")
+					}
 					if index < 0 || index >= int64(len(variable.elements)) {
-						return nil, loxerror.RuntimeError(expr.Name, ListIndexOutOfRange(originalIndex))
+						return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, expr.Name, ListIndexOutOfRange(originalIndex))
 					}
 					variable.elements[index] = value
 				}
 			default:
-				return nil, loxerror.RuntimeError(expr.Name, ListIndexMustBeWholeNum(index))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, expr.Name, ListIndexMustBeWholeNum(index))
 			}
 		}
 		return value, nil
@@ -2730,12 +3262,22 @@ func (i *Interpreter) visitSetObjectExpr(expr SetObject) (any, error) {
 	return nil, loxerror.RuntimeError(expr.Name, assignErrMsg)
 }
 
+func compoundIndexTarget(expr Expr, variable any, indexes list.List[any]) Expr {
+	index, ok := expr.(Index)
+	if !ok {
+		return Literal{Value: variable}
+	}
+	index.IndexElement = compoundIndexTarget(index.IndexElement, variable, indexes[1:])
+	index.Index = Literal{Value: indexes[0]}
+	return index
+}
+
 func (i *Interpreter) visitStringExpr(expr String) (any, error) {
-	return NewLoxString(expr.Str, expr.Quote), nil
+	return InternLoxString(expr.Str, expr.Quote), nil
 }
 
 func (i *Interpreter) visitSuperExpr(expr Super) (any, error) {
-	distance := i.locals[expr]
+	distance := expr.Resolution.Depth
 	superClass := i.environment.GetAtStr(distance, "super").(*LoxClass)
 	object := i.environment.GetAtStr(distance-1, "this")
 	switch object := object.(type) {
@@ -2777,9 +3319,8 @@ func (i *Interpreter) visitTernaryExpr(expr Ternary) (any, error) {
 }
 
 func (i *Interpreter) visitThisExpr(expr This) (any, error) {
-	distance, ok := i.locals[expr]
-	if ok {
-		return i.environment.GetAt(distance, expr.Keyword)
+	if expr.Resolution != nil && expr.Resolution.IsLocal {
+		return i.environment.GetSlot(expr.Resolution.Depth, expr.Resolution.Slot, expr.Keyword)
 	} else {
 		return i.globals.Get(expr.Keyword)
 	}
@@ -2790,6 +3331,9 @@ func (i *Interpreter) visitThrowStmt(stmt Throw) (any, error) {
 	if throwValueErr != nil {
 		return nil, throwValueErr
 	}
+	if instance, ok := throwValue.(*LoxInstance); ok && i.isErrorClass(instance.class) {
+		return nil, newThrownError(stmt.ThrowToken, instance)
+	}
 	var throwValueStr string
 	switch throwValue := throwValue.(type) {
 	case *LoxError:
@@ -2799,12 +3343,12 @@ func (i *Interpreter) visitThrowStmt(stmt Throw) (any, error) {
 	default:
 		//Use string representation of throw expression as error message
 		result, _ := i.visitBinaryExpr(Binary{
-			Literal{NewLoxString("", '\'')},
-			&token.Token{
+			Left: Literal{Value: NewLoxString("", '\'')},
+			Operator: &token.Token{
 				TokenType: token.PLUS,
 				Lexeme:    "+",
 			},
-			Literal{throwValue},
+			Right: Literal{Value: throwValue},
 		})
 		throwValueStr = result.(*LoxString).str
 	}
@@ -2831,15 +3375,31 @@ func (i *Interpreter) visitTryCatchFinallyStmt(stmt TryCatchFinally) (any, error
 		case Break, Continue, Return:
 			return finallyBlock(tryValue, tryErr)
 		}
-		if stmt.CatchBlock != nil {
+		for _, catchClause := range stmt.CatchClauses {
+			if catchClause.ErrorClass != nil {
+				errorClass, errorClassErr := i.evaluate(*catchClause.ErrorClass)
+				if errorClassErr != nil {
+					return finallyBlock(nil, errorClassErr)
+				}
+				loxClass, ok := errorClass.(*LoxClass)
+				if !ok || !i.isErrorClass(loxClass) {
+					return finallyBlock(nil, loxerror.RuntimeErrorKind(loxerror.TypeError,
+						catchClause.ErrorClass.Name,
+						fmt.Sprintf("'%v' is not an error class.", catchClause.ErrorClass.Name.Lexeme)))
+				}
+				if !isSubclassOf(i.errorClassOf(tryErr), loxClass) {
+					continue
+				}
+			}
 			var catchValue any
 			var catchErr error
-			if stmt.CatchName != nil {
+			if catchClause.Name != nil {
 				catchBlockEnv := env.NewEnvironmentEnclosing(i.environment)
-				catchBlockEnv.Define(stmt.CatchName.Lexeme, NewLoxError(tryErr))
-				catchValue, catchErr = i.visitBlockStmtEnv(stmt.CatchBlock.(Block), catchBlockEnv)
+				catchBlockEnv.Define(catchClause.Name.Lexeme,
+					i.caughtErrorValue(tryErr, catchClause.ErrorClass != nil))
+				catchValue, catchErr = i.visitBlockStmtEnv(catchClause.Block.(Block), catchBlockEnv)
 			} else {
-				catchValue, catchErr = i.visitBlockStmt(stmt.CatchBlock.(Block))
+				catchValue, catchErr = i.visitBlockStmt(catchClause.Block.(Block))
 			}
 			if catchErr != nil {
 				switch catchValue := catchValue.(type) {
@@ -2848,9 +3408,9 @@ func (i *Interpreter) visitTryCatchFinallyStmt(stmt TryCatchFinally) (any, error
 				}
 				return finallyBlock(nil, catchErr)
 			}
-		} else {
-			return finallyBlock(nil, tryErr)
+			return finallyBlock(nil, nil)
 		}
+		return finallyBlock(nil, tryErr)
 	}
 	return finallyBlock(nil, nil)
 }
@@ -2871,6 +3431,12 @@ func (i *Interpreter) visitUnaryExpr(expr Unary) (any, error) {
 			return new(big.Int).Neg(right), nil
 		case *big.Float:
 			return new(big.Float).Neg(right), nil
+		case *LoxRational:
+			return NewLoxRational(new(big.Rat).Neg(right.rat)), nil
+		case *LoxDecimal:
+			return NewLoxDecimal(new(big.Int).Neg(right.coef), right.scale), nil
+		case *LoxMatrix:
+			return right.mapValues(func(value float64) float64 { return -value }), nil
 		case bool:
 			if right {
 				return int64(-1), nil
@@ -2890,6 +3456,8 @@ func (i *Interpreter) visitUnaryExpr(expr Unary) (any, error) {
 			return ^int64(right), nil
 		case *big.Int:
 			return new(big.Int).Not(right), nil
+		case *LoxBitset:
+			return right.complement(), nil
 		case *big.Float:
 			bigInt := &big.Int{}
 			right.Int(bigInt)
@@ -2924,9 +3492,8 @@ func (i *Interpreter) visitVarStmt(stmt Var) (any, error) {
 func (i *Interpreter) visitVariableExpr(expr Variable) (any, error) {
 	var variable any
 	var variableErr error
-	distance, ok := i.locals[expr.Name]
-	if ok {
-		variable, variableErr = i.environment.GetAt(distance, expr.Name)
+	if expr.Resolution != nil && expr.Resolution.IsLocal {
+		variable, variableErr = i.environment.GetSlot(expr.Resolution.Depth, expr.Resolution.Slot, expr.Name)
 	} else {
 		variable, variableErr = i.globals.Get(expr.Name)
 	}
@@ -2941,33 +3508,15 @@ func (i *Interpreter) visitVariableExpr(expr Variable) (any, error) {
 }
 
 func (i *Interpreter) visitWhileStmt(stmt While) (any, error) {
-	enteredLoop := false
-	loopInterrupted := false
+	if value, ok, err := i.runIntLoop(stmt.WhileToken, stmt.Condition, nil, stmt.Body); ok {
+		return value, err
+	}
 	for result, conditionErr := i.evaluate(stmt.Condition); conditionErr != nil || i.isTruthy(result); {
 		if conditionErr != nil {
 			return nil, conditionErr
 		}
-		if loopInterrupted {
-			return nil, loxerror.RuntimeError(stmt.WhileToken, "loop interrupted")
-		}
-		if !enteredLoop {
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			defer func() {
-				if !loopInterrupted {
-					sigChan <- loxsignal.LoopSignal{}
-					signal.Stop(sigChan)
-				}
-			}()
-			go func() {
-				sig := <-sigChan
-				switch sig {
-				case os.Interrupt:
-					loopInterrupted = true
-					signal.Stop(sigChan)
-				}
-			}()
-			enteredLoop = true
+		if i.isInterrupted() {
+			return nil, i.interruptError(stmt.WhileToken)
 		}
 		value, evalErr := i.evaluate(stmt.Body)
 		if evalErr != nil {
@@ -49,7 +49,7 @@ func (i *Interpreter) defineIntFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			result, resultErr := strconv.ParseInt(loxStr.str, 0, 64)
 			if resultErr != nil {
-				return nil, loxerror.RuntimeError(in.callToken,
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
 					fmt.Sprintf("Failed to convert '%v' to integer.", loxStr.str))
 			}
 			return result, nil
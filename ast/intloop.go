@@ -0,0 +1,471 @@
+package ast
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/env"
+	"github.com/AlanLuu/lox/token"
+)
+
+/*
+Loops whose condition and body only do integer arithmetic on variables
+are compiled into Go closures that operate on a slice of int64 slots
+instead of being walked by the interpreter. This avoids boxing every
+intermediate value in an interface and looking up every variable in an
+environment map on each iteration.
+
+A loop is only compiled if every statement inside of it is one of the
+following: a block, an if statement, a while or for loop, a break or
+continue statement, a variable declaration with an initializer, or an
+assignment to a variable. Every expression must be an integer literal,
+a variable, or a unary or binary operator whose result is always an
+integer or a boolean when its operands are integers. Division is not
+allowed since its result may be a float.
+
+Before a compiled loop runs, every variable from outside the loop that it
+refers to must hold an integer, otherwise the loop is run normally by the
+interpreter. Variables assigned to inside the loop are written back to
+their environments after the loop finishes.
+*/
+
+type intLoopControl int
+
+const (
+	intLoopNext intLoopControl = iota
+	intLoopBreak
+	intLoopContinue
+	intLoopInterrupted
+)
+
+type intLoopFrame struct {
+	in          *Interpreter
+	slots       []int64
+	interrupted *atomic.Bool
+}
+
+type intLoopIntExpr func(*intLoopFrame) int64
+type intLoopBoolExpr func(*intLoopFrame) bool
+type intLoopStmt func(*intLoopFrame) intLoopControl
+
+type intLoopVar struct {
+	distance int //-1 for global variables
+	name     string
+}
+
+type intLoopOuterVar struct {
+	intLoopVar
+	slot     int
+	assigned bool
+}
+
+type intLoop struct {
+	vars     []*intLoopOuterVar
+	numSlots int
+	run      intLoopStmt
+}
+
+type intLoopCompiler struct {
+	in       *Interpreter
+	vars     []*intLoopOuterVar
+	varMap   map[intLoopVar]*intLoopOuterVar
+	scopes   []map[string]int
+	numSlots int
+}
+
+var intLoops sync.Map
+
+func (c *intLoopCompiler) beginScope() {
+	c.scopes = append(c.scopes, make(map[string]int))
+}
+
+func (c *intLoopCompiler) endScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *intLoopCompiler) slot(name *token.Token, resolution *Resolution, assign bool) (int, bool) {
+	numScopes := len(c.scopes)
+	ok := resolution != nil && resolution.IsLocal
+	var distance int
+	if ok {
+		distance = resolution.Depth
+	}
+	if ok && distance < numScopes {
+		slot, ok := c.scopes[numScopes-1-distance][name.Lexeme]
+		return slot, ok
+	}
+	key := intLoopVar{-1, name.Lexeme}
+	if ok {
+		key.distance = distance - numScopes
+	}
+	outerVar, ok := c.varMap[key]
+	if !ok {
+		outerVar = &intLoopOuterVar{intLoopVar: key, slot: c.numSlots}
+		c.numSlots++
+		c.varMap[key] = outerVar
+		c.vars = append(c.vars, outerVar)
+	}
+	if assign {
+		outerVar.assigned = true
+	}
+	return outerVar.slot, true
+}
+
+func (c *intLoopCompiler) intExpr(expr Expr) (intLoopIntExpr, bool) {
+	switch expr := expr.(type) {
+	case Literal:
+		value, ok := expr.Value.(int64)
+		if !ok {
+			return nil, false
+		}
+		return func(*intLoopFrame) int64 { return value }, true
+	case Grouping:
+		return c.intExpr(expr.Expression)
+	case Variable:
+		slot, ok := c.slot(expr.Name, expr.Resolution, false)
+		if !ok {
+			return nil, false
+		}
+		return func(f *intLoopFrame) int64 { return f.slots[slot] }, true
+	case Unary:
+		right, ok := c.intExpr(expr.Right)
+		if !ok {
+			return nil, false
+		}
+		switch expr.Operator.TokenType {
+		case token.MINUS:
+			return func(f *intLoopFrame) int64 { return -right(f) }, true
+		case token.TILDE:
+			return func(f *intLoopFrame) int64 { return ^right(f) }, true
+		}
+	case Binary:
+		left, ok := c.intExpr(expr.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := c.intExpr(expr.Right)
+		if !ok {
+			return nil, false
+		}
+		switch expr.Operator.TokenType {
+		case token.PLUS:
+			return func(f *intLoopFrame) int64 { return left(f) + right(f) }, true
+		case token.MINUS:
+			return func(f *intLoopFrame) int64 { return left(f) - right(f) }, true
+		case token.STAR:
+			return func(f *intLoopFrame) int64 { return left(f) * right(f) }, true
+		case token.PERCENT:
+			return func(f *intLoopFrame) int64 { return left(f) % right(f) }, true
+		case token.DOUBLE_STAR:
+			return func(f *intLoopFrame) int64 {
+				return int64(math.Pow(float64(left(f)), float64(right(f))))
+			}, true
+		case token.AMPERSAND:
+			return func(f *intLoopFrame) int64 { return left(f) & right(f) }, true
+		case token.PIPE:
+			return func(f *intLoopFrame) int64 { return left(f) | right(f) }, true
+		case token.CARET:
+			return func(f *intLoopFrame) int64 { return left(f) ^ right(f) }, true
+		}
+	}
+	return nil, false
+}
+
+func (c *intLoopCompiler) boolExpr(expr Expr) (intLoopBoolExpr, bool) {
+	switch expr := expr.(type) {
+	case Literal:
+		if value, ok := expr.Value.(bool); ok {
+			return func(*intLoopFrame) bool { return value }, true
+		}
+	case Grouping:
+		return c.boolExpr(expr.Expression)
+	case Unary:
+		if expr.Operator.TokenType == token.BANG {
+			right, ok := c.boolExpr(expr.Right)
+			if !ok {
+				return nil, false
+			}
+			return func(f *intLoopFrame) bool { return !right(f) }, true
+		}
+	case Logical:
+		if expr.Operator.TokenType == token.DOUBLE_QUESTION {
+			return nil, false
+		}
+		left, ok := c.boolExpr(expr.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := c.boolExpr(expr.Right)
+		if !ok {
+			return nil, false
+		}
+		if expr.Operator.TokenType == token.OR {
+			return func(f *intLoopFrame) bool { return left(f) || right(f) }, true
+		}
+		return func(f *intLoopFrame) bool { return left(f) && right(f) }, true
+	case Binary:
+		switch expr.Operator.TokenType {
+		case token.LESS, token.LESS_EQUAL, token.GREATER, token.GREATER_EQUAL,
+			token.EQUAL_EQUAL, token.BANG_EQUAL:
+			left, ok := c.intExpr(expr.Left)
+			if !ok {
+				return nil, false
+			}
+			right, ok := c.intExpr(expr.Right)
+			if !ok {
+				return nil, false
+			}
+			switch expr.Operator.TokenType {
+			case token.LESS:
+				return func(f *intLoopFrame) bool { return left(f) < right(f) }, true
+			case token.LESS_EQUAL:
+				return func(f *intLoopFrame) bool { return left(f) <= right(f) }, true
+			case token.GREATER:
+				return func(f *intLoopFrame) bool { return left(f) > right(f) }, true
+			case token.GREATER_EQUAL:
+				return func(f *intLoopFrame) bool { return left(f) >= right(f) }, true
+			case token.EQUAL_EQUAL:
+				return func(f *intLoopFrame) bool { return left(f) == right(f) }, true
+			case token.BANG_EQUAL:
+				return func(f *intLoopFrame) bool { return left(f) != right(f) }, true
+			}
+		}
+	}
+
+	//Integers used as conditions are truthy if they are nonzero
+	value, ok := c.intExpr(expr)
+	if !ok {
+		return nil, false
+	}
+	return func(f *intLoopFrame) bool { return value(f) != 0 }, true
+}
+
+func (c *intLoopCompiler) assign(expr Expr) (intLoopStmt, bool) {
+	assign, ok := expr.(Assign)
+	if !ok {
+		return nil, false
+	}
+	value, ok := c.intExpr(assign.Value)
+	if !ok {
+		return nil, false
+	}
+	slot, ok := c.slot(assign.Name, assign.Resolution, true)
+	if !ok {
+		return nil, false
+	}
+	return func(f *intLoopFrame) intLoopControl {
+		f.slots[slot] = value(f)
+		return intLoopNext
+	}, true
+}
+
+func (c *intLoopCompiler) stmt(stmt Stmt) (intLoopStmt, bool) {
+	switch stmt := stmt.(type) {
+	case Break:
+		return func(*intLoopFrame) intLoopControl { return intLoopBreak }, true
+	case Continue:
+		return func(*intLoopFrame) intLoopControl { return intLoopContinue }, true
+	case Expression:
+		return c.assign(stmt.Expression)
+	case Var:
+		if len(c.scopes) == 0 || stmt.Initializer == nil {
+			return nil, false
+		}
+		value, ok := c.intExpr(stmt.Initializer)
+		if !ok {
+			return nil, false
+		}
+		slot := c.numSlots
+		c.numSlots++
+		c.scopes[len(c.scopes)-1][stmt.Name.Lexeme] = slot
+		return func(f *intLoopFrame) intLoopControl {
+			f.slots[slot] = value(f)
+			return intLoopNext
+		}, true
+	case Block:
+		c.beginScope()
+		defer c.endScope()
+		statements := make([]intLoopStmt, 0, len(stmt.Statements))
+		for _, statement := range stmt.Statements {
+			compiled, ok := c.stmt(statement)
+			if !ok {
+				return nil, false
+			}
+			switch statement.(type) {
+			case While, For:
+				//Breaking out of a loop only skips to the next statement in a block
+				loop := compiled
+				compiled = func(f *intLoopFrame) intLoopControl {
+					if control := loop(f); control == intLoopInterrupted {
+						return control
+					}
+					return intLoopNext
+				}
+			}
+			statements = append(statements, compiled)
+		}
+		return func(f *intLoopFrame) intLoopControl {
+			for _, statement := range statements {
+				if control := statement(f); control != intLoopNext {
+					return control
+				}
+			}
+			return intLoopNext
+		}, true
+	case If:
+		condition, ok := c.boolExpr(stmt.Condition)
+		if !ok {
+			return nil, false
+		}
+		thenBranch, ok := c.stmt(stmt.ThenBranch)
+		if !ok {
+			return nil, false
+		}
+		elseBranch := func(*intLoopFrame) intLoopControl { return intLoopNext }
+		if stmt.ElseBranch != nil {
+			elseBranch, ok = c.stmt(stmt.ElseBranch)
+			if !ok {
+				return nil, false
+			}
+		}
+		return func(f *intLoopFrame) intLoopControl {
+			if condition(f) {
+				return thenBranch(f)
+			}
+			return elseBranch(f)
+		}, true
+	case While:
+		return c.loop(stmt.Condition, nil, stmt.Body)
+	case For:
+		c.beginScope()
+		defer c.endScope()
+		var initializer intLoopStmt
+		switch stmt.Initializer.(type) {
+		case nil:
+		default:
+			var ok bool
+			initializer, ok = c.stmt(stmt.Initializer)
+			if !ok {
+				return nil, false
+			}
+		}
+		loop, ok := c.loop(stmt.Condition, stmt.Increment, stmt.Body)
+		if !ok {
+			return nil, false
+		}
+		if initializer == nil {
+			return loop, true
+		}
+		return func(f *intLoopFrame) intLoopControl {
+			initializer(f)
+			return loop(f)
+		}, true
+	}
+	return nil, false
+}
+
+func (c *intLoopCompiler) loop(conditionExpr Expr, incrementExpr Expr, bodyStmt Stmt) (intLoopStmt, bool) {
+	condition := func(*intLoopFrame) bool { return true }
+	if conditionExpr != nil {
+		var ok bool
+		condition, ok = c.boolExpr(conditionExpr)
+		if !ok {
+			return nil, false
+		}
+	}
+	increment := func(*intLoopFrame) intLoopControl { return intLoopNext }
+	if incrementExpr != nil {
+		var ok bool
+		increment, ok = c.assign(incrementExpr)
+		if !ok {
+			return nil, false
+		}
+	}
+	body, ok := c.stmt(bodyStmt)
+	if !ok {
+		return nil, false
+	}
+	return func(f *intLoopFrame) intLoopControl {
+		for condition(f) {
+			if f.interrupted.Load() || limitExceeded() != "" ||
+				(signalsPending.Load() && f.in.isInterrupted()) {
+				return intLoopInterrupted
+			}
+			switch body(f) {
+			case intLoopBreak:
+				return intLoopBreak
+			case intLoopInterrupted:
+				return intLoopInterrupted
+			}
+			increment(f)
+		}
+		return intLoopNext
+	}, true
+}
+
+func (i *Interpreter) compileIntLoop(loopToken *token.Token, condition Expr, increment Expr, body Stmt) *intLoop {
+	if compiled, ok := intLoops.Load(loopToken); ok {
+		return compiled.(*intLoop)
+	}
+	compiler := &intLoopCompiler{
+		in:     i,
+		varMap: make(map[intLoopVar]*intLoopOuterVar),
+	}
+	var compiled *intLoop
+	if run, ok := compiler.loop(condition, increment, body); ok {
+		compiled = &intLoop{
+			vars:     compiler.vars,
+			numSlots: compiler.numSlots,
+			run:      run,
+		}
+	}
+	intLoops.Store(loopToken, compiled)
+	return compiled
+}
+
+// Runs the specified loop using its compiled form if possible. The returned
+// boolean is false if the loop cannot be compiled or if any of the variables
+// it uses do not hold integers, in which case nothing has been run.
+func (i *Interpreter) runIntLoop(loopToken *token.Token, condition Expr, increment Expr, body Stmt) (any, bool, error) {
+	compiled := i.compileIntLoop(loopToken, condition, increment, body)
+	if compiled == nil {
+		return nil, false, nil
+	}
+
+	environments := make([]*env.Environment, len(compiled.vars))
+	frame := &intLoopFrame{
+		in:          i,
+		slots:       make([]int64, compiled.numSlots),
+		interrupted: i.interrupted,
+	}
+	for index, variable := range compiled.vars {
+		environment := i.globals
+		if variable.distance >= 0 {
+			environment = i.environment.Ancestor(variable.distance)
+		}
+		value, _ := environment.Lookup(variable.name)
+		intValue, ok := value.(int64)
+		if !ok {
+			return nil, false, nil
+		}
+		environments[index] = environment
+		frame.slots[variable.slot] = intValue
+	}
+
+	control := compiled.run(frame)
+
+	for index, variable := range compiled.vars {
+		if variable.assigned {
+			environments[index].Define(variable.name, frame.slots[variable.slot])
+		}
+	}
+	switch control {
+	case intLoopBreak:
+		return Break{}, true, errors.New("")
+	case intLoopInterrupted:
+		return nil, true, i.interruptError(loopToken)
+	}
+	return nil, true, nil
+}
@@ -0,0 +1,229 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Parses the specified network string, which is an address optionally
+// followed by a slash and either a prefix length or a netmask. If strict is
+// true, the address cannot have any host bits set
+func parseIPNetwork(str string, strict bool) (netip.Prefix, error) {
+	invalidErr := loxerror.Error(fmt.Sprintf("'%v' is not a valid IP network.", str))
+	addrStr, maskStr, hasMask := strings.Cut(str, "/")
+	addr, err := netip.ParseAddr(addrStr)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, invalidErr
+	}
+	bits := addr.BitLen()
+	if hasMask {
+		if !strings.ContainsAny(maskStr, ".:") {
+			num, err := strconv.Atoi(maskStr)
+			if err != nil || num < 0 || num > bits || maskStr[0] < '0' || maskStr[0] > '9' {
+				return netip.Prefix{}, invalidErr
+			}
+			bits = num
+		} else {
+			mask, err := netip.ParseAddr(maskStr)
+			if err != nil || mask.BitLen() != addr.BitLen() {
+				return netip.Prefix{}, invalidErr
+			}
+			var ok bool
+			bits, ok = ipMaskPrefix(mask)
+			if !ok {
+				return netip.Prefix{}, invalidErr
+			}
+		}
+	}
+	prefix := netip.PrefixFrom(addr, bits)
+	if strict && prefix.Masked() != prefix {
+		return netip.Prefix{}, loxerror.Error(fmt.Sprintf("'%v' has host bits set.", str))
+	}
+	return prefix.Masked(), nil
+}
+
+func (i *Interpreter) defineIPAddrFuncs() {
+	className := "ipaddr"
+	ipaddrClass := NewLoxClass(className, nil, false)
+	ipaddrFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ipaddr fn %v at %p>", name, &s)
+		}
+		ipaddrClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'ipaddr.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	//Returns the IP version from the optional argument at the specified
+	//index, which is defaultVersion if omitted
+	versionArg := func(callToken *token.Token, name string, args list.List[any], index int, defaultVersion int64) (int64, error) {
+		argsLen := len(args)
+		if argsLen != index && argsLen != index+1 {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Expected %v or %v arguments but got %v.", index, index+1, argsLen))
+		}
+		if argsLen == index {
+			return defaultVersion, nil
+		}
+		version, ok := args[index].(int64)
+		if !ok {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Version argument to 'ipaddr.%v' must be an integer.", name))
+		}
+		if version != 4 && version != 6 {
+			return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Version argument to 'ipaddr.%v' must be 4 or 6.", name))
+		}
+		return version, nil
+	}
+
+	ipaddrFunc("address", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) == 0 {
+			return nil, loxerror.RuntimeError(in.callToken, "Expected 1 or 2 arguments but got 0.")
+		}
+		var num *big.Int
+		switch arg := args[0].(type) {
+		case *LoxString:
+			if len(args) != 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 1 argument but got %v.", len(args)))
+			}
+			addr, err := netip.ParseAddr(arg.str)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("'%v' is not a valid IP address.", arg.str))
+			}
+			return NewLoxIPAddress(addr), nil
+		case *LoxBuffer:
+			if len(args) != 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 1 argument but got %v.", len(args)))
+			}
+			addr, ok := netip.AddrFromSlice(arg.bytes())
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					"Buffer argument to 'ipaddr.address' must have a length of 4 or 16.")
+			}
+			return NewLoxIPAddress(addr), nil
+		case int64:
+			num = big.NewInt(arg)
+		case *big.Int:
+			num = arg
+		default:
+			return argMustBeType(in.callToken, "address", "string, buffer, integer, or bigint")
+		}
+		//Integers that fit in 32 bits are IPv4 addresses by default
+		defaultVersion := int64(6)
+		if num.Sign() >= 0 && num.BitLen() <= 32 {
+			defaultVersion = 4
+		}
+		version, err := versionArg(in.callToken, "address", args, 1, defaultVersion)
+		if err != nil {
+			return nil, err
+		}
+		bitLen := 32
+		if version == 6 {
+			bitLen = 128
+		}
+		addr, ok := ipAddrFromBig(num, bitLen)
+		if !ok {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("Integer %v is out of range for IPv%v addresses.", num, version))
+		}
+		return NewLoxIPAddress(addr), nil
+	})
+	ipaddrFunc("isValid", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			_, err := netip.ParseAddr(loxStr.str)
+			return err == nil, nil
+		}
+		return argMustBeType(in.callToken, "isValid", "string")
+	})
+	ipaddrFunc("isValidNetwork", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			_, err := parseIPNetwork(loxStr.str, false)
+			return err == nil, nil
+		}
+		return argMustBeType(in.callToken, "isValidNetwork", "string")
+	})
+	ipaddrFunc("netmaskToPrefix", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var mask netip.Addr
+		switch arg := args[0].(type) {
+		case *LoxIPAddress:
+			mask = arg.addr
+		case *LoxString:
+			var err error
+			mask, err = netip.ParseAddr(arg.str)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("'%v' is not a valid IP address.", arg.str))
+			}
+		default:
+			return argMustBeType(in.callToken, "netmaskToPrefix", "string or IP address")
+		}
+		bits, ok := ipMaskPrefix(mask.WithZone(""))
+		if !ok {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("'%v' is not a valid netmask.", mask))
+		}
+		return int64(bits), nil
+	})
+	ipaddrFunc("network", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		loxStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'ipaddr.network' must be a string.")
+		}
+		strict := true
+		if argsLen == 2 {
+			strict, ok = args[1].(bool)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'ipaddr.network' must be a boolean.")
+			}
+		}
+		prefix, err := parseIPNetwork(loxStr.str, strict)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+		}
+		return NewLoxIPNetwork(prefix), nil
+	})
+	ipaddrFunc("prefixToNetmask", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		version, err := versionArg(in.callToken, "prefixToNetmask", args, 1, 4)
+		if err != nil {
+			return nil, err
+		}
+		bits, ok := args[0].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'ipaddr.prefixToNetmask' must be an integer.")
+		}
+		bitLen := int64(32)
+		if version == 6 {
+			bitLen = 128
+		}
+		if bits < 0 || bits > bitLen {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("Prefix length for IPv%v must be between 0 and %v.", version, bitLen))
+		}
+		return NewLoxIPAddress(ipMaskAddr(int(bits), int(bitLen))), nil
+	})
+
+	i.globals.Define(className, ipaddrClass)
+}
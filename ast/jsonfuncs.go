@@ -1,18 +1,216 @@
 package ast
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
 
+	"github.com/AlanLuu/lox/interfaces"
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
 	"github.com/AlanLuu/lox/token"
 	"github.com/AlanLuu/lox/util"
 )
 
+// A step of a JSONPath expression passed to JSON.path
+type jsonPathSegment struct {
+	key       string
+	index     int64
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// Parses a JSONPath expression that supports the root '$', child keys in
+// the form '.key' or ['key'], list indexes in the form [index], where
+// negative indexes count from the end, wildcards in the form '.*' or [*],
+// and recursive descent in the form '..key' or '..*'
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	invalidErr := func(reason string) ([]jsonPathSegment, error) {
+		return nil, loxerror.Error(fmt.Sprintf("Invalid JSON path '%v': %v.", path, reason))
+	}
+	if !strings.HasPrefix(path, "$") {
+		return invalidErr("path must start with '$'")
+	}
+	segments := []jsonPathSegment{}
+	for pos := 1; pos < len(path); {
+		segment := jsonPathSegment{}
+		switch path[pos] {
+		case '.':
+			pos++
+			if pos < len(path) && path[pos] == '.' {
+				segment.recursive = true
+				pos++
+			}
+			if pos < len(path) && path[pos] == '[' && segment.recursive {
+				break
+			}
+			end := pos
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			if end == pos {
+				return invalidErr(fmt.Sprintf("expected a key at position %v", pos))
+			}
+			if path[pos:end] == "*" {
+				segment.wildcard = true
+			} else {
+				segment.key = path[pos:end]
+			}
+			pos = end
+			segments = append(segments, segment)
+			continue
+		case '[':
+		default:
+			return invalidErr(fmt.Sprintf("unexpected character '%c' at position %v", path[pos], pos))
+		}
+		end := strings.IndexByte(path[pos:], ']')
+		if end < 0 {
+			return invalidErr("missing ']'")
+		}
+		inner := strings.TrimSpace(path[pos+1 : pos+end])
+		switch {
+		case inner == "*":
+			segment.wildcard = true
+		case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+			segment.key = inner[1 : len(inner)-1]
+		default:
+			index, err := strconv.ParseInt(inner, 10, 64)
+			if err != nil {
+				return invalidErr(fmt.Sprintf("'%v' is not a valid index", inner))
+			}
+			segment.index = index
+			segment.isIndex = true
+		}
+		pos += end + 1
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// Returns the children of the specified value that the specified segment
+// of a JSONPath expression selects, ignoring recursive descent
+func jsonPathChildren(value any, segment jsonPathSegment) []any {
+	switch value := value.(type) {
+	case *LoxDict:
+		if segment.wildcard {
+			children := []any{}
+			it := value.Iterator()
+			for it.HasNext() {
+				children = append(children, it.Next().(*LoxList).elements[1])
+			}
+			return children
+		}
+		if segment.isIndex {
+			return nil
+		}
+		for _, quote := range []byte{'\'', '"'} {
+			if child, ok := value.getValueByKey(NewLoxString(segment.key, quote)); ok {
+				return []any{child}
+			}
+		}
+	case *LoxList:
+		if segment.wildcard {
+			return append([]any{}, value.elements...)
+		}
+		if !segment.isIndex {
+			return nil
+		}
+		index := segment.index
+		if index < 0 {
+			index += int64(len(value.elements))
+		}
+		if index >= 0 && index < int64(len(value.elements)) {
+			return []any{value.elements[index]}
+		}
+	}
+	return nil
+}
+
+// Appends the specified value and all values nested inside it to the
+// specified slice, skipping lists and dictionaries that were already seen
+func jsonPathDescendants(value any, result []any, seen map[any]bool) []any {
+	switch value.(type) {
+	case *LoxDict, *LoxList:
+		if seen[value] {
+			return result
+		}
+		seen[value] = true
+	}
+	result = append(result, value)
+	wildcard := jsonPathSegment{wildcard: true}
+	for _, child := range jsonPathChildren(value, wildcard) {
+		result = jsonPathDescendants(child, result, seen)
+	}
+	return result
+}
+
+// A JSON object whose keys are kept in the order that they appear in the
+// JSON source, so that JSON.parse creates dictionaries in the same order
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+// A JSON value whose objects are decoded as jsonObjects instead of maps,
+// since maps don't keep the order of their keys
+type jsonOrderedValue struct {
+	value any
+}
+
+func (j *jsonOrderedValue) UnmarshalJSON(data []byte) error {
+	value, err := decodeOrderedJSON(json.NewDecoder(bytes.NewReader(data)))
+	if err != nil {
+		return err
+	}
+	j.value = value
+	return nil
+}
+
+func decodeOrderedJSON(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		object := &jsonObject{}
+		for decoder.More() {
+			keyTok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, keyTok.(string))
+			object.values = append(object.values, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	}
+	return tok, nil
+}
+
 func (i *Interpreter) defineJSONFuncs() {
 	className := "JSON"
 	jsonClass := NewLoxClass(className, nil, false)
@@ -44,7 +242,7 @@ func (i *Interpreter) defineJSONFuncs() {
 			jsonStrByteArr := []byte(jsonStr)
 			var jsonArr []any
 			var jsonBool bool
-			var jsonMap map[string]any
+			var jsonMap *jsonObject
 			var jsonNum float64
 			var finalJsonString string
 			var jsonErr error
@@ -52,12 +250,15 @@ func (i *Interpreter) defineJSONFuncs() {
 			setJsonBool := false
 			setJsonNum := false
 			switch jsonStr[0] {
-			case '{':
-				jsonMap = make(map[string]any)
-				jsonErr = json.Unmarshal(jsonStrByteArr, &jsonMap)
-			case '[':
-				jsonArr = make([]any, 0)
-				jsonErr = json.Unmarshal(jsonStrByteArr, &jsonArr)
+			case '{', '[':
+				var jsonValue jsonOrderedValue
+				jsonErr = json.Unmarshal(jsonStrByteArr, &jsonValue)
+				switch value := jsonValue.value.(type) {
+				case *jsonObject:
+					jsonMap = value
+				case []any:
+					jsonArr = value
+				}
 			default:
 				_, numErr := strconv.ParseFloat(jsonStr, 64)
 				if numErr == nil {
@@ -118,7 +319,7 @@ func (i *Interpreter) defineJSONFuncs() {
 				return value
 			}
 			var parseList func(*LoxList, *[]any)
-			var parseMap func(*LoxDict, *map[string]any)
+			var parseMap func(*LoxDict, *jsonObject)
 			parseList = func(jsonLoxList *LoxList, jsonList *[]any) {
 				for _, value := range *jsonList {
 					switch value := value.(type) {
@@ -126,25 +327,25 @@ func (i *Interpreter) defineJSONFuncs() {
 						innerLoxList := EmptyLoxList()
 						parseList(innerLoxList, &value)
 						jsonLoxList.elements.Add(innerLoxList)
-					case map[string]any:
+					case *jsonObject:
 						innerLoxDict := EmptyLoxDict()
-						parseMap(innerLoxDict, &value)
+						parseMap(innerLoxDict, value)
 						jsonLoxList.elements.Add(innerLoxDict)
 					default:
 						jsonLoxList.elements.Add(parseValue(value))
 					}
 				}
 			}
-			parseMap = func(jsonLoxDict *LoxDict, jsonMap *map[string]any) {
-				for key, value := range *jsonMap {
-					switch value := value.(type) {
+			parseMap = func(jsonLoxDict *LoxDict, jsonMap *jsonObject) {
+				for index, key := range jsonMap.keys {
+					switch value := jsonMap.values[index].(type) {
 					case []any:
 						innerLoxList := EmptyLoxList()
 						parseList(innerLoxList, &value)
 						jsonLoxDict.setKeyValue(processString(key), innerLoxList)
-					case map[string]any:
+					case *jsonObject:
 						innerLoxDict := EmptyLoxDict()
-						parseMap(innerLoxDict, &value)
+						parseMap(innerLoxDict, value)
 						jsonLoxDict.setKeyValue(processString(key), innerLoxDict)
 					default:
 						jsonLoxDict.setKeyValue(processString(key), parseValue(value))
@@ -159,7 +360,7 @@ func (i *Interpreter) defineJSONFuncs() {
 				return finalLoxList, nil
 			case jsonMap != nil:
 				finalLoxDict := EmptyLoxDict()
-				parseMap(finalLoxDict, &jsonMap)
+				parseMap(finalLoxDict, jsonMap)
 				return finalLoxDict, nil
 			case setJsonBool:
 				return jsonBool, nil
@@ -171,7 +372,106 @@ func (i *Interpreter) defineJSONFuncs() {
 		}
 		return argMustBeType(in.callToken, "parse", "string")
 	})
-	jsonFunc("stringify", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+	jsonFunc("path", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		pathStr, ok := args[1].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'JSON.path' must be a string.")
+		}
+		segments, err := parseJSONPath(pathStr.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		nodes := []any{args[0]}
+		definite := true
+		for _, segment := range segments {
+			if segment.recursive {
+				descendants := []any{}
+				seen := make(map[any]bool)
+				for _, node := range nodes {
+					descendants = jsonPathDescendants(node, descendants, seen)
+				}
+				nodes = descendants
+			}
+			if segment.recursive || segment.wildcard {
+				definite = false
+			}
+			children := []any{}
+			for _, node := range nodes {
+				children = append(children, jsonPathChildren(node, segment)...)
+			}
+			nodes = children
+		}
+		if definite {
+			if len(nodes) == 0 {
+				return nil, nil
+			}
+			return nodes[0], nil
+		}
+		return NewLoxList(list.List[any](nodes)), nil
+	})
+	jsonFunc("stream", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch arg := args[0].(type) {
+		case *LoxFile:
+			if !arg.isRead() {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Cannot create JSON stream for file not in read mode.")
+			}
+			return NewLoxJSONStream(arg.file), nil
+		case *LoxString:
+			return NewLoxJSONStream(strings.NewReader(arg.str)), nil
+		case interfaces.Iterable:
+			return NewLoxJSONStream(&jsonIteratorReader{iterator: arg.Iterator()}), nil
+		}
+		return argMustBeType(in.callToken, "stream", "file, string, or iterable")
+	})
+	jsonFunc("stringify", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		indent := ""
+		if argsLen == 2 {
+			switch arg := args[1].(type) {
+			case int64:
+				if arg < 0 || arg > 10 {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Second argument to 'JSON.stringify' must be between 0 and 10 if it is an integer.")
+				}
+				indent = strings.Repeat(" ", int(arg))
+			case *LoxString:
+				if strings.Trim(arg.str, " \t") != "" {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Second argument to 'JSON.stringify' must only contain spaces and tabs if it is a string.")
+				}
+				indent = arg.str
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'JSON.stringify' must be an integer or string.")
+			}
+		}
+		depth := 0
+		//Returns the separator that comes before the next element of a
+		//dictionary or list, or before its closing bracket if isEnd is true
+		separator := func(isFirst bool, isEnd bool) string {
+			var builder strings.Builder
+			if !isFirst && !isEnd {
+				builder.WriteByte(',')
+				if indent == "" {
+					builder.WriteByte(' ')
+				}
+			}
+			if indent != "" {
+				builder.WriteByte('\n')
+				level := depth
+				if isEnd {
+					level--
+				}
+				builder.WriteString(strings.Repeat(indent, level))
+			}
+			return builder.String()
+		}
 		escapeChars := map[rune]string{
 			'\a': "\\\\a",
 			'\n': "\\\\n",
@@ -233,11 +533,13 @@ func (i *Interpreter) defineJSONFuncs() {
 			case LoxStringStr:
 				return processString(source.str, true), nil
 			case *LoxDict:
-				sourceLen := len(source.entries)
 				var dictStr strings.Builder
 				dictStr.WriteByte('{')
+				depth++
 				i := 0
-				for key, value := range source.entries {
+				for _, entry := range source.entries() {
+					key, value := entry.key, entry.value
+					dictStr.WriteString(separator(i == 0, false))
 					if key == originalSource {
 						return selfReferentialErr(originalSource)
 					} else {
@@ -257,18 +559,20 @@ func (i *Interpreter) defineJSONFuncs() {
 						}
 						dictStr.WriteString(result)
 					}
-					if i < sourceLen-1 {
-						dictStr.WriteString(", ")
-					}
 					i++
 				}
+				if i > 0 {
+					dictStr.WriteString(separator(false, true))
+				}
+				depth--
 				dictStr.WriteByte('}')
 				return dictStr.String(), nil
 			case *LoxList:
-				sourceLen := len(source.elements)
 				var listStr strings.Builder
 				listStr.WriteByte('[')
+				depth++
 				for i, element := range source.elements {
+					listStr.WriteString(separator(i == 0, false))
 					if element == originalSource {
 						return selfReferentialErr(originalSource)
 					} else {
@@ -278,10 +582,11 @@ func (i *Interpreter) defineJSONFuncs() {
 						}
 						listStr.WriteString(result)
 					}
-					if i < sourceLen-1 {
-						listStr.WriteString(", ")
-					}
 				}
+				if len(source.elements) > 0 {
+					listStr.WriteString(separator(false, true))
+				}
+				depth--
 				listStr.WriteByte(']')
 				return listStr.String(), nil
 			default:
@@ -0,0 +1,556 @@
+package ast
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var jwtEncoding = base64.RawURLEncoding
+
+// The signing algorithms that are supported for each type of key, where the
+// first algorithm in each list is the default one for that type of key
+var (
+	jwtHMACAlgorithms    = []string{"HS256", "HS384", "HS512"}
+	jwtRSAAlgorithms     = []string{"RS256", "RS384", "RS512"}
+	jwtEd25519Algorithms = []string{"EdDSA"}
+)
+
+type jwtDecodeOptions struct {
+	algorithms []string
+	audience   []string
+	issuer     *string
+	leeway     float64
+	time       float64
+}
+
+func jwtHashFunc(algorithm string) (func() hash.Hash, crypto.Hash) {
+	switch algorithm[2:] {
+	case "384":
+		return sha512.New384, crypto.SHA384
+	case "512":
+		return sha512.New, crypto.SHA512
+	}
+	return sha256.New, crypto.SHA256
+}
+
+// Returns the algorithms that can be used with the specified key, or nil if
+// the key cannot be used to sign or verify JWTs
+func jwtKeyAlgorithms(key any) []string {
+	switch key.(type) {
+	case *LoxBuffer, *LoxString:
+		return jwtHMACAlgorithms
+	case *LoxRSA:
+		return jwtRSAAlgorithms
+	case *LoxEd25519:
+		return jwtEd25519Algorithms
+	}
+	return nil
+}
+
+func jwtHMACKey(key any) ([]byte, error) {
+	var keyBytes []byte
+	switch key := key.(type) {
+	case *LoxBuffer:
+		keyBytes = key.bytes()
+	case *LoxString:
+		keyBytes = []byte(key.str)
+	}
+	if len(keyBytes) == 0 {
+		return nil, loxerror.Error("JWT HMAC key cannot be empty.")
+	}
+	return keyBytes, nil
+}
+
+// Returns the signature of the signing input, which is the encoded header
+// and claims joined by a period
+func jwtSign(algorithm string, key any, signingInput []byte) ([]byte, error) {
+	hashFunc, cryptoHash := jwtHashFunc(algorithm)
+	switch key := key.(type) {
+	case *LoxBuffer, *LoxString:
+		keyBytes, err := jwtHMACKey(key)
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(hashFunc, keyBytes)
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	case *LoxRSA:
+		if !key.isKeyPair() {
+			return nil, loxerror.Error("Cannot sign JWT with an RSA public key.")
+		}
+		h := hashFunc()
+		h.Write(signingInput)
+		return rsa.SignPKCS1v15(crand.Reader, key.privKey, cryptoHash, h.Sum(nil))
+	case *LoxEd25519:
+		if !key.isKeyPair() {
+			return nil, loxerror.Error("Cannot sign JWT with an Ed25519 public key.")
+		}
+		return ed25519.Sign(key.privKey, signingInput), nil
+	}
+	return nil, loxerror.Error("Invalid JWT key.")
+}
+
+func jwtVerifySignature(algorithm string, key any, signingInput []byte, signature []byte) (bool, error) {
+	hashFunc, cryptoHash := jwtHashFunc(algorithm)
+	switch key := key.(type) {
+	case *LoxBuffer, *LoxString:
+		keyBytes, err := jwtHMACKey(key)
+		if err != nil {
+			return false, err
+		}
+		mac := hmac.New(hashFunc, keyBytes)
+		mac.Write(signingInput)
+		return hmac.Equal(mac.Sum(nil), signature), nil
+	case *LoxRSA:
+		h := hashFunc()
+		h.Write(signingInput)
+		return rsa.VerifyPKCS1v15(&key.pubKey, cryptoHash, h.Sum(nil), signature) == nil, nil
+	case *LoxEd25519:
+		return ed25519.Verify(key.pubKey, signingInput, signature), nil
+	}
+	return false, loxerror.Error("Invalid JWT key.")
+}
+
+// Converts the specified Lox value into a value that can be encoded as JSON
+// in a JWT header or claims set, where dates become Unix timestamps
+func jwtFromLox(value any, seen map[any]bool) (any, error) {
+	switch value := value.(type) {
+	case nil, bool, int64:
+		return value, nil
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, loxerror.Error("JWT claims cannot contain infinity or NaN.")
+		}
+		return value, nil
+	case *big.Int:
+		return json.Number(value.String()), nil
+	case *LoxString:
+		return value.str, nil
+	case LoxStringStr:
+		return value.str, nil
+	case *LoxDate:
+		return value.date.Unix(), nil
+	case *LoxList:
+		if seen[value] {
+			return nil, loxerror.Error("Cannot encode self-referential list in JWT.")
+		}
+		seen[value] = true
+		defer delete(seen, value)
+		elements := make([]any, 0, len(value.elements))
+		for _, element := range value.elements {
+			converted, err := jwtFromLox(element, seen)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, converted)
+		}
+		return elements, nil
+	case *LoxDict:
+		if seen[value] {
+			return nil, loxerror.Error("Cannot encode self-referential dictionary in JWT.")
+		}
+		seen[value] = true
+		defer delete(seen, value)
+		object := make(map[string]any, len(value.indexes))
+		it := value.Iterator()
+		for it.HasNext() {
+			pair := it.Next().(*LoxList).elements
+			key, ok := pair[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.Error("JWT dictionary keys must be strings.")
+			}
+			converted, err := jwtFromLox(pair[1], seen)
+			if err != nil {
+				return nil, err
+			}
+			object[key.str] = converted
+		}
+		return object, nil
+	}
+	return nil, loxerror.Error(
+		fmt.Sprintf("Type '%v' cannot be encoded in JWT.", getType(value)))
+}
+
+// Decodes one base64url encoded JSON object segment of a JWT
+func jwtDecodeSegment(segment string, segmentName string) (map[string]any, error) {
+	data, err := jwtEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, loxerror.Error(fmt.Sprintf("JWT %v is not valid base64url.", segmentName))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil || object == nil {
+		return nil, loxerror.Error(fmt.Sprintf("JWT %v is not a valid JSON object.", segmentName))
+	}
+	return object, nil
+}
+
+// Splits a JWT into its decoded header and claims, the signing input, and
+// the decoded signature
+func jwtParse(jwt string) (map[string]any, map[string]any, []byte, []byte, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, nil, loxerror.Error(
+			fmt.Sprintf("JWT must have 3 parts separated by periods, not %v.", len(parts)))
+	}
+	header, err := jwtDecodeSegment(parts[0], "header")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	claims, err := jwtDecodeSegment(parts[1], "claims")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	signature, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, nil, loxerror.Error("JWT signature is not valid base64url.")
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	return header, claims, signingInput, signature, nil
+}
+
+// Returns the numeric value of the specified registered claim and whether
+// the claim exists
+func jwtNumericClaim(claims map[string]any, name string) (float64, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return 0, false, nil
+	}
+	if num, ok := value.(json.Number); ok {
+		if f, err := num.Float64(); err == nil {
+			return f, true, nil
+		}
+	}
+	return 0, true, loxerror.Error(fmt.Sprintf("JWT '%v' claim must be a number.", name))
+}
+
+// Checks the registered exp, nbf, aud, and iss claims against the options
+func (o jwtDecodeOptions) validateClaims(claims map[string]any) error {
+	exp, ok, err := jwtNumericClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if ok && o.time >= exp+o.leeway {
+		return loxerror.Error(fmt.Sprintf("JWT has expired at %v.",
+			time.Unix(int64(exp), 0).UTC().Format(time.RFC3339)))
+	}
+	nbf, ok, err := jwtNumericClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && o.time < nbf-o.leeway {
+		return loxerror.Error(fmt.Sprintf("JWT is not valid until %v.",
+			time.Unix(int64(nbf), 0).UTC().Format(time.RFC3339)))
+	}
+	if o.audience != nil {
+		var tokenAudience []string
+		switch aud := claims["aud"].(type) {
+		case nil:
+			return loxerror.Error("JWT has no 'aud' claim but an audience was expected.")
+		case string:
+			tokenAudience = []string{aud}
+		case []any:
+			for _, element := range aud {
+				audStr, ok := element.(string)
+				if !ok {
+					return loxerror.Error("JWT 'aud' claim must be a string or list of strings.")
+				}
+				tokenAudience = append(tokenAudience, audStr)
+			}
+		default:
+			return loxerror.Error("JWT 'aud' claim must be a string or list of strings.")
+		}
+		matches := false
+		for _, aud := range tokenAudience {
+			if slices.Contains(o.audience, aud) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return loxerror.Error(fmt.Sprintf("JWT audience %v does not match the expected audience %v.",
+				strings.Join(tokenAudience, ", "), strings.Join(o.audience, ", ")))
+		}
+	}
+	if o.issuer != nil {
+		iss, ok := claims["iss"].(string)
+		if !ok {
+			return loxerror.Error("JWT has no 'iss' claim but an issuer was expected.")
+		}
+		if iss != *o.issuer {
+			return loxerror.Error(fmt.Sprintf("JWT issuer '%v' does not match the expected issuer '%v'.",
+				iss, *o.issuer))
+		}
+	}
+	return nil
+}
+
+func (i *Interpreter) defineJWTFuncs() {
+	className := "jwt"
+	jwtClass := NewLoxClass(className, nil, false)
+	jwtFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native jwt fn %v at %p>", name, &s)
+		}
+		jwtClass.classProperties[name] = s
+	}
+	authErr := func(callToken *token.Token, err error) error {
+		return loxerror.RuntimeErrorKind(loxerror.AuthError, callToken, err.Error())
+	}
+	stringListOption := func(key string, value any) ([]string, error) {
+		switch value := value.(type) {
+		case *LoxString:
+			return []string{value.str}, nil
+		case *LoxList:
+			strs := make([]string, 0, len(value.elements))
+			for _, element := range value.elements {
+				str, ok := element.(*LoxString)
+				if !ok {
+					return nil, loxerror.Error(
+						fmt.Sprintf("JWT option '%v' must be a string or list of strings.", key))
+				}
+				strs = append(strs, str.str)
+			}
+			return strs, nil
+		}
+		return nil, loxerror.Error(
+			fmt.Sprintf("JWT option '%v' must be a string or list of strings.", key))
+	}
+	numOption := func(key string, value any) (float64, error) {
+		switch value := value.(type) {
+		case int64:
+			return float64(value), nil
+		case float64:
+			return value, nil
+		case *LoxDate:
+			return float64(value.date.UnixNano()) / 1e9, nil
+		}
+		return 0, loxerror.Error(fmt.Sprintf("JWT option '%v' must be a number.", key))
+	}
+	tokenArg := func(in *Interpreter, arg any, fnName string) (string, error) {
+		if loxStr, ok := arg.(*LoxString); ok {
+			return loxStr.str, nil
+		}
+		return "", loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("First argument to 'jwt.%v' must be a string.", fnName))
+	}
+	keyTypesStr := "a buffer, string, RSA object, or Ed25519 object"
+
+	jwtFunc("decode", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		jwt, err := tokenArg(in, args[0], "decode")
+		if err != nil {
+			return nil, err
+		}
+		key := args[1]
+		keyAlgorithms := jwtKeyAlgorithms(key)
+		if keyAlgorithms == nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Second argument to 'jwt.decode' must be %v.", keyTypesStr))
+		}
+		options := jwtDecodeOptions{
+			algorithms: keyAlgorithms,
+			time:       float64(time.Now().UnixNano()) / 1e9,
+		}
+		if argsLen == 3 {
+			dict, ok := args[2].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'jwt.decode' must be a dictionary.")
+			}
+			err := forEachOption(dict, func(optionKey string, value any) error {
+				var err error
+				switch optionKey {
+				case "algorithms":
+					options.algorithms, err = stringListOption(optionKey, value)
+				case "audience":
+					options.audience, err = stringListOption(optionKey, value)
+				case "issuer":
+					issuer, ok := value.(*LoxString)
+					if !ok {
+						return loxerror.Error("JWT option 'issuer' must be a string.")
+					}
+					options.issuer = &issuer.str
+				case "leeway":
+					options.leeway, err = numOption(optionKey, value)
+					if err == nil && options.leeway < 0 {
+						err = loxerror.Error("JWT option 'leeway' cannot be negative.")
+					}
+				case "time":
+					options.time, err = numOption(optionKey, value)
+				default:
+					err = loxerror.Error(fmt.Sprintf("Unknown JWT option '%v'.", optionKey))
+				}
+				return err
+			})
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		}
+
+		header, claims, signingInput, signature, err := jwtParse(jwt)
+		if err != nil {
+			return nil, authErr(in.callToken, err)
+		}
+		algorithm, ok := header["alg"].(string)
+		if !ok {
+			return nil, authErr(in.callToken, loxerror.Error("JWT header has no 'alg' field."))
+		}
+		if !slices.Contains(options.algorithms, algorithm) {
+			return nil, authErr(in.callToken, loxerror.Error(
+				fmt.Sprintf("JWT algorithm '%v' is not allowed; expected one of %v.",
+					algorithm, strings.Join(options.algorithms, ", "))))
+		}
+		if !slices.Contains(keyAlgorithms, algorithm) {
+			return nil, authErr(in.callToken, loxerror.Error(
+				fmt.Sprintf("JWT algorithm '%v' cannot be used with a key of type '%v'.",
+					algorithm, getType(key))))
+		}
+		valid, err := jwtVerifySignature(algorithm, key, signingInput, signature)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		if !valid {
+			return nil, authErr(in.callToken, loxerror.Error("JWT signature verification failed."))
+		}
+		if err := options.validateClaims(claims); err != nil {
+			return nil, authErr(in.callToken, err)
+		}
+		return jsonToLox(claims), nil
+	})
+	jwtFunc("decodeUnverified", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		jwt, err := tokenArg(in, args[0], "decodeUnverified")
+		if err != nil {
+			return nil, err
+		}
+		_, claims, _, _, err := jwtParse(jwt)
+		if err != nil {
+			return nil, authErr(in.callToken, err)
+		}
+		return jsonToLox(claims), nil
+	})
+	jwtFunc("encode", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		claimsDict, ok := args[0].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'jwt.encode' must be a dictionary.")
+		}
+		key := args[1]
+		keyAlgorithms := jwtKeyAlgorithms(key)
+		if keyAlgorithms == nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Second argument to 'jwt.encode' must be %v.", keyTypesStr))
+		}
+		algorithm := keyAlgorithms[0]
+		header := map[string]any{}
+		if argsLen == 3 {
+			switch arg := args[2].(type) {
+			case *LoxString:
+				algorithm = arg.str
+			case *LoxDict:
+				err := forEachOption(arg, func(optionKey string, value any) error {
+					switch optionKey {
+					case "algorithm":
+						algStr, ok := value.(*LoxString)
+						if !ok {
+							return loxerror.Error("JWT option 'algorithm' must be a string.")
+						}
+						algorithm = algStr.str
+					case "header":
+						headerDict, ok := value.(*LoxDict)
+						if !ok {
+							return loxerror.Error("JWT option 'header' must be a dictionary.")
+						}
+						converted, err := jwtFromLox(headerDict, make(map[any]bool))
+						if err != nil {
+							return err
+						}
+						header = converted.(map[string]any)
+					default:
+						return loxerror.Error(fmt.Sprintf("Unknown JWT option '%v'.", optionKey))
+					}
+					return nil
+				})
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'jwt.encode' must be a dictionary or string.")
+			}
+		}
+		if !slices.Contains(keyAlgorithms, algorithm) {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("JWT algorithm '%v' cannot be used with a key of type '%v'; expected one of %v.",
+					algorithm, getType(key), strings.Join(keyAlgorithms, ", ")))
+		}
+		header["alg"] = algorithm
+		if _, ok := header["typ"]; !ok {
+			header["typ"] = "JWT"
+		}
+		claims, err := jwtFromLox(claimsDict, make(map[any]bool))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		headerJSON, err := json.Marshal(header)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		claimsJSON, err := json.Marshal(claims)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		signingInput := jwtEncoding.EncodeToString(headerJSON) + "." +
+			jwtEncoding.EncodeToString(claimsJSON)
+		signature, err := jwtSign(algorithm, key, []byte(signingInput))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxStringQuote(signingInput + "." + jwtEncoding.EncodeToString(signature)), nil
+	})
+	jwtFunc("header", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		jwt, err := tokenArg(in, args[0], "header")
+		if err != nil {
+			return nil, err
+		}
+		header, _, _, _, err := jwtParse(jwt)
+		if err != nil {
+			return nil, authErr(in.callToken, err)
+		}
+		return jsonToLox(header), nil
+	})
+
+	i.globals.Define(className, jwtClass)
+}
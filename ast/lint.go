@@ -0,0 +1,240 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A possible mistake found by the resolver when linting
+type lintWarning struct {
+	token   *token.Token
+	message string
+}
+
+// The minimum and maximum number of arguments that a declared function or
+// class can be called with, where a maximum of -1 means that there is no
+// maximum
+type lintCallable struct {
+	minArity int
+	maxArity int
+}
+
+// A call whose callee is a variable and whose arguments are all positional
+type lintCallSite struct {
+	name    *token.Token
+	numArgs int
+}
+
+func functionCallable(function FunctionExpr) *lintCallable {
+	minArity := 0
+	for index := range function.Params {
+		if index == function.VarArgPos {
+			break
+		}
+		if index < len(function.Defaults) && function.Defaults[index] != nil {
+			break
+		}
+		minArity++
+	}
+	if function.VarArgPos >= 0 {
+		return &lintCallable{minArity, -1}
+	}
+	return &lintCallable{minArity, len(function.Params)}
+}
+
+// Returns the number of arguments that the specified class can be
+// instantiated with, or nil if it isn't known because the class inherits
+// its initializer
+func classCallable(class Class) *lintCallable {
+	if !class.CanInstantiate {
+		return nil
+	}
+	var callable *lintCallable
+	for _, method := range class.Methods {
+		if method.Name.Lexeme == "init" {
+			callable = functionCallable(method.Function)
+		}
+	}
+	if callable == nil && class.SuperClass == nil {
+		callable = &lintCallable{0, 0}
+	}
+	return callable
+}
+
+// Resolves the specified statements and returns warnings about possible
+// mistakes in them, such as unused local variables, unreachable code,
+// shadowed variables, calls with the wrong number of arguments, and uses of
+// undefined global variables, without running them. The warnings are
+// sorted by where they occur in the source code.
+func (r *Resolver) Lint(statements list.List[Stmt]) ([]error, error) {
+	r.lint = true
+	r.globals = make(map[string]*scopeVariable)
+	r.assigned = make(map[string]bool)
+	resolveErr := r.Resolve(statements)
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	//Global variables can be declared after the functions that use them,
+	//so they are only checked once every statement has been resolved
+	for _, call := range r.calls {
+		global, ok := r.globals[call.name.Lexeme]
+		if ok && global.defined && !r.assigned[call.name.Lexeme] {
+			r.checkArity(global.callable, call)
+		}
+	}
+	if !r.imports {
+		for _, ref := range r.refs {
+			if _, ok := r.globals[ref.Lexeme]; ok {
+				continue
+			}
+			if _, ok := r.Interpreter.globals.Lookup(ref.Lexeme); ok {
+				continue
+			}
+			r.warn(ref, fmt.Sprintf("Undefined variable '%v'.", ref.Lexeme))
+		}
+	}
+
+	sort.SliceStable(r.warnings, func(a, b int) bool {
+		tokenA, tokenB := r.warnings[a].token, r.warnings[b].token
+		if tokenA.Line != tokenB.Line {
+			return tokenA.Line < tokenB.Line
+		}
+		return tokenA.Column < tokenB.Column
+	})
+	warnings := make([]error, 0, len(r.warnings))
+	for _, warning := range r.warnings {
+		warnings = append(warnings, loxerror.Warning(warning.token, warning.message))
+	}
+	return warnings, nil
+}
+
+func (r *Resolver) warn(theToken *token.Token, message string) {
+	r.warnings = append(r.warnings, lintWarning{theToken, message})
+}
+
+func (r *Resolver) checkArity(callable *lintCallable, call lintCallSite) {
+	if callable == nil {
+		return
+	}
+	minArity, maxArity := callable.minArity, callable.maxArity
+	if call.numArgs >= minArity && (maxArity < 0 || call.numArgs <= maxArity) {
+		return
+	}
+	var expected string
+	switch {
+	case maxArity < 0:
+		expected = fmt.Sprintf("at least %v arguments", minArity)
+	case maxArity == minArity && minArity == 1:
+		expected = "1 argument"
+	case maxArity == minArity:
+		expected = fmt.Sprintf("%v arguments", minArity)
+	case maxArity == minArity+1:
+		expected = fmt.Sprintf("%v or %v arguments", minArity, maxArity)
+	default:
+		expected = fmt.Sprintf("%v to %v arguments", minArity, maxArity)
+	}
+	r.warn(call.name, fmt.Sprintf("'%v' expects %v but is called with %v.",
+		call.name.Lexeme, expected, call.numArgs))
+}
+
+// Warns about declarations whose name is already used by a variable in an
+// enclosing scope or by a global variable declared earlier
+func (r *Resolver) checkShadowed(name *token.Token) {
+	for i := len(r.Scopes) - 2; i >= 0; i-- {
+		if variable, ok := r.Scopes[i][name.Lexeme]; ok && variable.name != nil {
+			r.warn(name, fmt.Sprintf("'%v' shadows a variable declared on line %v.",
+				name.Lexeme, variable.name.Line))
+			return
+		}
+	}
+	if global, ok := r.globals[name.Lexeme]; ok {
+		r.warn(name, fmt.Sprintf("'%v' shadows a global variable declared on line %v.",
+			name.Lexeme, global.name.Line))
+	}
+}
+
+func (r *Resolver) checkUnreachable(stmt Stmt) {
+	var keyword *token.Token
+	switch stmt := stmt.(type) {
+	case Break:
+		keyword = stmt.Keyword
+	case Continue:
+		keyword = stmt.Keyword
+	case Return:
+		keyword = stmt.Keyword
+	case Throw:
+		keyword = stmt.ThrowToken
+	}
+	if keyword != nil {
+		r.warn(keyword, fmt.Sprintf("Unreachable code after '%v'.", keyword.Lexeme))
+	}
+}
+
+func (r *Resolver) checkUnused(scope map[string]*scopeVariable) {
+	for name, variable := range scope {
+		if variable.checkUnused && !variable.used && !strings.HasPrefix(name, "_") {
+			r.warn(variable.name, fmt.Sprintf("'%v' is declared but never used.", name))
+		}
+	}
+}
+
+// Records a declaration of a global variable. Global variables that are
+// declared more than once are not marked as defined, since it isn't known
+// which declaration a call refers to.
+func (r *Resolver) declareGlobal(name *token.Token) {
+	if global, ok := r.globals[name.Lexeme]; ok {
+		global.defined = false
+		global.callable = nil
+		return
+	}
+	r.globals[name.Lexeme] = &scopeVariable{defined: true, name: name}
+}
+
+// Records what the variable that was just declared with the specified name
+// can be called with, and marks local variables to be checked for whether
+// they are used
+func (r *Resolver) declareCallable(name *token.Token, callable *lintCallable) {
+	if r.Scopes.IsEmpty() {
+		if global := r.globals[name.Lexeme]; global.defined {
+			global.callable = callable
+		}
+		return
+	}
+	variable := r.Scopes.Peek()[name.Lexeme]
+	variable.checkUnused = true
+	variable.callable = callable
+}
+
+func (r *Resolver) lintAssign(variable *scopeVariable, name *token.Token) {
+	if variable != nil {
+		variable.callable = nil
+		return
+	}
+	r.assigned[name.Lexeme] = true
+	r.refs = append(r.refs, name)
+}
+
+func (r *Resolver) lintCall(expr Call) {
+	callee, ok := expr.Callee.(Variable)
+	if !ok {
+		return
+	}
+	for _, argument := range expr.Arguments {
+		switch argument.(type) {
+		case NamedArgument, Spread:
+			return
+		}
+	}
+	call := lintCallSite{callee.Name, len(expr.Arguments)}
+	if variable := r.resolveLocal(nil, callee.Name); variable != nil {
+		r.checkArity(variable.callable, call)
+	} else {
+		r.calls = append(r.calls, call)
+	}
+}
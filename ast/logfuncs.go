@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
@@ -25,6 +26,19 @@ func defineLogFields(logClass *LoxClass) {
 	for key, value := range flags {
 		logClass.classProperties[key] = value
 	}
+	for level, name := range logLevelNames {
+		logClass.classProperties[name] = level
+	}
+}
+
+func logStreamArg(value any) (*logStreamOutput, bool) {
+	if loxFile, ok := value.(*LoxFile); ok {
+		return &logStreamOutput{loxFile.file}, true
+	}
+	if file, ok := progressStream(value); ok {
+		return &logStreamOutput{file}, true
+	}
+	return nil, false
 }
 
 func (i *Interpreter) defineLogFuncs() {
@@ -59,15 +73,274 @@ func (i *Interpreter) defineLogFuncs() {
 		return elements
 	}
 
+	optionErr := func(callToken *token.Token, fnName string, key string, theType string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Option '%v' in '%v' must be %v.", key, fnName, theType))
+	}
+	unknownOption := func(callToken *token.Token, fnName string, key string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+	}
+	//Sets the level or formatter of a handler from an option in a dictionary
+	//of handler options, returning false if the option isn't one of them
+	handlerOption := func(callToken *token.Token, fnName string, handler *LoxLogHandler, key string, value any) (bool, error) {
+		switch key {
+		case "formatter":
+			formatter, ok := logFormatterArg(value)
+			if !ok {
+				return true, optionErr(callToken, fnName, key, "a log formatter, function, 'text', or 'json'")
+			}
+			handler.formatter = formatter
+		case "level":
+			level, ok := logLevelArg(value)
+			if !ok {
+				return true, optionErr(callToken, fnName, key, "a level integer or level name")
+			}
+			handler.level = level
+		default:
+			return false, nil
+		}
+		return true, nil
+	}
+	//Applies the optional dictionary of handler options in args at the
+	//specified index, where other is called with options that aren't the
+	//level or formatter of the handler
+	handlerOptions := func(callToken *token.Token, fnName string, handler *LoxLogHandler, args list.List[any], index int, other func(key string, value any) error) error {
+		if len(args) <= index {
+			return nil
+		}
+		optionsDict, ok := args[index].(*LoxDict)
+		if !ok {
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Options argument to 'log.%v' must be a dictionary.", fnName))
+		}
+		return forEachOption(optionsDict, func(key string, value any) error {
+			if ok, err := handlerOption(callToken, "log."+fnName, handler, key, value); ok || err != nil {
+				return err
+			}
+			if other == nil {
+				return unknownOption(callToken, "log."+fnName, key)
+			}
+			return other(key, value)
+		})
+	}
+
 	defineLogFields(logClass)
+	logFunc("basicConfig", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) > 1 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+		}
+		fnName := "log.basicConfig"
+		level := int64(logLevelWarning)
+		template, timeFormat := logTextFormat, logTextTimeFormat
+		var formatter *LoxLogFormatter
+		var output logOutput = &logStreamOutput{os.Stderr}
+		kind := "stream"
+		if len(args) == 1 {
+			optionsDict, ok := args[0].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'log.basicConfig' must be a dictionary.")
+			}
+			var path string
+			err := forEachOption(optionsDict, func(key string, value any) error {
+				switch key {
+				case "file":
+					pathStr, ok := value.(*LoxString)
+					if !ok || pathStr.str == "" {
+						return optionErr(in.callToken, fnName, key, "a non-empty string")
+					}
+					path = pathStr.str
+				case "format":
+					templateStr, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(in.callToken, fnName, key, "a string")
+					}
+					template = templateStr.str
+					if template == "json" {
+						formatter = NewLoxLogJSONFormatter(time.RFC3339Nano)
+					}
+				case "level":
+					value, ok := logLevelArg(value)
+					if !ok || value <= 0 {
+						return optionErr(in.callToken, fnName, key, "a level integer or level name")
+					}
+					level = value
+				case "stream":
+					stream, ok := logStreamArg(value)
+					if !ok {
+						return optionErr(in.callToken, fnName, key, "'stdout', 'stderr', or a file")
+					}
+					output = stream
+				case "timeFormat":
+					timeFormatStr, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(in.callToken, fnName, key, "a string")
+					}
+					timeFormat = timeFormatStr.str
+				default:
+					return unknownOption(in.callToken, fnName, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if path != "" {
+				if sandboxErr := checkSandbox(in.callToken, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
+				fileOutput, err := newLogFileOutput(path, 0, "", 0, 0)
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				output = fileOutput
+				kind = "file"
+			}
+		}
+		if formatter == nil {
+			formatter = NewLoxLogTextFormatter(template, timeFormat)
+		} else if timeFormat != logTextTimeFormat {
+			formatter.timeFormat = timeFormat
+		}
+		handler := NewLoxLogHandler(kind, output)
+		handler.formatter = formatter
+		rootNamedLogger.mutex.Lock()
+		defer rootNamedLogger.mutex.Unlock()
+		rootNamedLogger.handlers = []*LoxLogHandler{handler}
+		rootNamedLogger.level = level
+		return handler, nil
+	})
 	logFunc("fatal", -1, func(_ *Interpreter, args list.List[any]) (any, error) {
 		log.Println(results(args)...)
 		fatal()
 		return nil, nil
 	})
+	logFunc("fileHandler", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		path, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'log.fileHandler' must be a string.")
+		}
+		fnName := "log.fileHandler"
+		handler := NewLoxLogHandler("file", nil)
+		var maxBytes, backupCount int64
+		interval := int64(1)
+		when := ""
+		err := handlerOptions(in.callToken, "fileHandler", handler, args, 1, func(key string, value any) error {
+			switch key {
+			case "backupCount", "interval", "maxBytes":
+				num, ok := value.(int64)
+				if !ok || num < 0 || (key == "interval" && num == 0) {
+					if key == "interval" {
+						return optionErr(in.callToken, fnName, key, "a positive integer")
+					}
+					return optionErr(in.callToken, fnName, key, "a non-negative integer")
+				}
+				switch key {
+				case "backupCount":
+					backupCount = num
+				case "interval":
+					interval = num
+				case "maxBytes":
+					maxBytes = num
+				}
+			case "when":
+				whenStr, ok := value.(*LoxString)
+				if ok {
+					_, ok = logRotationUnits[whenStr.str]
+				}
+				if !ok {
+					return optionErr(in.callToken, fnName, key, "one of 'S', 'M', 'H', 'D', or 'midnight'")
+				}
+				when = whenStr.str
+			default:
+				return unknownOption(in.callToken, fnName, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if maxBytes > 0 && when != "" {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Options 'maxBytes' and 'when' in 'log.fileHandler' cannot be used together.")
+		}
+		if sandboxErr := checkSandbox(in.callToken, "Writing files"); sandboxErr != nil {
+			return nil, sandboxErr
+		}
+		output, err := newLogFileOutput(path.str, maxBytes, when, interval, backupCount)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		handler.output = output
+		return handler, nil
+	})
 	logFunc("flags", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 		return int64(log.Flags()), nil
 	})
+	logFunc("funcHandler", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		callback, ok := args[0].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'log.funcHandler' must be a function.")
+		}
+		handler := NewLoxLogFuncHandler(callback)
+		if err := handlerOptions(in.callToken, "funcHandler", handler, args, 1, nil); err != nil {
+			return nil, err
+		}
+		return handler, nil
+	})
+	logFunc("getLogger", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch len(args) {
+		case 0:
+			return rootNamedLogger, nil
+		case 1:
+			name, ok := args[0].(*LoxString)
+			if !ok {
+				return argMustBeType(in.callToken, "getLogger", "string")
+			}
+			if strings.HasPrefix(name.str, ".") || strings.HasSuffix(name.str, ".") ||
+				strings.Contains(name.str, "..") {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("Invalid logger name '%v'.", name.str))
+			}
+			return getNamedLogger(name.str), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+	})
+	logFunc("jsonFormatter", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch len(args) {
+		case 0:
+			return NewLoxLogJSONFormatter(time.RFC3339Nano), nil
+		case 1:
+			timeFormat, ok := args[0].(*LoxString)
+			if !ok {
+				return argMustBeType(in.callToken, "jsonFormatter", "string")
+			}
+			return NewLoxLogJSONFormatter(timeFormat.str), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+	})
+	logFunc("levelName", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if level, ok := args[0].(int64); ok {
+			return NewLoxStringQuote(logLevelName(level)), nil
+		}
+		return argMustBeTypeAn(in.callToken, "levelName", "integer")
+	})
 	logFunc("logger", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		argsLen := len(args)
 		switch argsLen {
@@ -160,5 +433,93 @@ func (i *Interpreter) defineLogFuncs() {
 		return NewLoxStringQuote(strings.TrimRight(builder.String(), "\n")), nil
 	})
 
+	logFunc("streamHandler", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		output := &logStreamOutput{os.Stderr}
+		if argsLen > 0 {
+			var ok bool
+			output, ok = logStreamArg(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'log.streamHandler' must be 'stdout', 'stderr', or a file.")
+			}
+		}
+		handler := NewLoxLogHandler("stream", output)
+		if err := handlerOptions(in.callToken, "streamHandler", handler, args, 1, nil); err != nil {
+			return nil, err
+		}
+		return handler, nil
+	})
+	logFunc("syslogHandler", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) > 1 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+		}
+		fnName := "log.syslogHandler"
+		handler := NewLoxLogHandler("syslog", nil)
+		var network, address, tag string
+		err := handlerOptions(in.callToken, "syslogHandler", handler, args, 0, func(key string, value any) error {
+			str, ok := value.(*LoxString)
+			if !ok {
+				return optionErr(in.callToken, fnName, key, "a string")
+			}
+			switch key {
+			case "address":
+				address = str.str
+			case "network":
+				network = str.str
+			case "tag":
+				tag = str.str
+			default:
+				return unknownOption(in.callToken, fnName, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if (network == "") != (address == "") {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Options 'network' and 'address' in 'log.syslogHandler' must be used together.")
+		}
+		output, err := newLogSyslogOutput(network, address, tag)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		handler.output = output
+		//Syslog adds its own timestamp and tag to each message
+		handler.formatter = NewLoxLogTextFormatter("[{level}] {name}: {message}", logTextTimeFormat)
+		return handler, nil
+	})
+	logFunc("textFormatter", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		template, timeFormat := logTextFormat, logTextTimeFormat
+		if argsLen > 0 {
+			templateStr, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'log.textFormatter' must be a string.")
+			}
+			template = templateStr.str
+		}
+		if argsLen > 1 {
+			timeFormatStr, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'log.textFormatter' must be a string.")
+			}
+			timeFormat = timeFormatStr.str
+		}
+		return NewLoxLogTextFormatter(template, timeFormat), nil
+	})
+
 	i.globals.Define(className, logClass)
 }
@@ -0,0 +1,35 @@
+//go:build !windows && !js && !plan9
+
+package ast
+
+import "log/syslog"
+
+type logSyslogOutput struct {
+	writer *syslog.Writer
+}
+
+func newLogSyslogOutput(network string, address string, tag string) (logOutput, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &logSyslogOutput{writer}, nil
+}
+
+func (o *logSyslogOutput) write(level int64, line string) error {
+	switch {
+	case level >= logLevelCritical:
+		return o.writer.Crit(line)
+	case level >= logLevelError:
+		return o.writer.Err(line)
+	case level >= logLevelWarning:
+		return o.writer.Warning(line)
+	case level >= logLevelInfo:
+		return o.writer.Info(line)
+	}
+	return o.writer.Debug(line)
+}
+
+func (o *logSyslogOutput) close() error {
+	return o.writer.Close()
+}
@@ -0,0 +1,9 @@
+//go:build windows || js || plan9
+
+package ast
+
+import "errors"
+
+func newLogSyslogOutput(network string, address string, tag string) (logOutput, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
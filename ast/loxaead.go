@@ -0,0 +1,286 @@
+package ast
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// An authenticated encryption cipher, which is either AES-GCM or
+// ChaCha20-Poly1305 depending on the function that created it
+type LoxAEAD struct {
+	name    string
+	aead    cipher.AEAD
+	key     []byte
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxAESGCM(keyLenBits int) (*LoxAEAD, error) {
+	if keyLenBits != 128 && keyLenBits != 192 && keyLenBits != 256 {
+		return nil, loxerror.Error("AES integer argument must be 128, 192, or 256.")
+	}
+	key := make([]byte, keyLenBits/8)
+	if _, err := io.ReadFull(crand.Reader, key); err != nil {
+		return nil, loxerror.Error("Failed to generate random AES key.")
+	}
+	return NewLoxAESGCMBytes(key)
+}
+
+func NewLoxAESGCMBytes(key []byte) (*LoxAEAD, error) {
+	keyLenBytes := len(key)
+	if keyLenBytes != 16 && keyLenBytes != 24 && keyLenBytes != 32 {
+		return nil, loxerror.Error("Key length in bytes must be 16, 24, or 32.")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &LoxAEAD{
+		name:    "aes-gcm",
+		aead:    aead,
+		key:     key,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}, nil
+}
+
+func NewLoxChaCha20Poly1305() (*LoxAEAD, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(crand.Reader, key); err != nil {
+		return nil, loxerror.Error("Failed to generate random ChaCha20-Poly1305 key.")
+	}
+	return NewLoxChaCha20Poly1305Bytes(key)
+}
+
+func NewLoxChaCha20Poly1305Bytes(key []byte) (*LoxAEAD, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, loxerror.Error(
+			fmt.Sprintf("Key length in bytes must be %v.", chacha20poly1305.KeySize))
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &LoxAEAD{
+		name:    "chacha20-poly1305",
+		aead:    aead,
+		key:     key,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}, nil
+}
+
+func (l *LoxAEAD) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	aeadFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native %v fn %v at %p>", l.name, methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Returns the bytes of a buffer, file, or string argument, where
+	//strings are decoded from base64 if isCiphertext is true
+	dataArg := func(args list.List[any], isCiphertext bool) ([]byte, error) {
+		switch arg := args[0].(type) {
+		case *LoxBuffer:
+			return arg.bytes(), nil
+		case *LoxFile:
+			if !arg.isRead() {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("File argument to '%v.%v' must be in read mode.", l.name, methodName))
+			}
+			data, err := io.ReadAll(arg.file)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return data, nil
+		case *LoxString:
+			if !isCiphertext {
+				return []byte(arg.str), nil
+			}
+			data, err := LoxAESDecode(arg.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return data, nil
+		}
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("First argument to '%v.%v' must be a buffer, file, or string.", l.name, methodName))
+	}
+	//Returns the nonce argument, or nil if it is omitted or nil
+	nonceArg := func(args list.List[any]) ([]byte, error) {
+		if len(args) < 2 || args[1] == nil {
+			return nil, nil
+		}
+		nonce, ok := args[1].(*LoxBuffer)
+		if !ok {
+			return nil, loxerror.RuntimeError(name,
+				fmt.Sprintf("Second argument to '%v.%v' must be a buffer or nil.", l.name, methodName))
+		}
+		if len(nonce.elements) != l.aead.NonceSize() {
+			return nil, loxerror.RuntimeError(name,
+				fmt.Sprintf("Nonce length in bytes must be %v.", l.aead.NonceSize()))
+		}
+		return nonce.bytes(), nil
+	}
+	additionalDataArg := func(args list.List[any]) ([]byte, error) {
+		if len(args) < 3 {
+			return nil, nil
+		}
+		switch arg := args[2].(type) {
+		case *LoxBuffer:
+			return arg.bytes(), nil
+		case *LoxString:
+			return []byte(arg.str), nil
+		}
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Third argument to '%v.%v' must be a buffer or string.", l.name, methodName))
+	}
+	checkArgsLen := func(args list.List[any]) error {
+		argsLen := len(args)
+		if argsLen < 1 || argsLen > 3 {
+			return loxerror.RuntimeError(name,
+				fmt.Sprintf("Expected 1 to 3 arguments but got %v.", argsLen))
+		}
+		return nil
+	}
+	//Decrypts and authenticates the ciphertext, which starts with the nonce
+	//if no nonce argument is specified
+	decrypt := func(args list.List[any]) ([]byte, error) {
+		if err := checkArgsLen(args); err != nil {
+			return nil, err
+		}
+		ciphertext, err := dataArg(args, true)
+		if err != nil {
+			return nil, err
+		}
+		nonce, err := nonceArg(args)
+		if err != nil {
+			return nil, err
+		}
+		additionalData, err := additionalDataArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if nonce == nil {
+			nonceSize := l.aead.NonceSize()
+			if len(ciphertext) < nonceSize {
+				return nil, loxerror.RuntimeErrorKind(loxerror.AuthError, name,
+					fmt.Sprintf("%v: ciphertext size must be at least %v bytes.", l.typeName(), nonceSize))
+			}
+			nonce, ciphertext = ciphertext[:nonceSize], ciphertext[nonceSize:]
+		}
+		plaintext, err := l.aead.Open(nil, nonce, ciphertext, additionalData)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.AuthError, name,
+				fmt.Sprintf("%v: message authentication failed.", l.typeName()))
+		}
+		return plaintext, nil
+	}
+	switch methodName {
+	case "base64", "b64", "keyStr":
+		return aeadFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(LoxAESEncode(l.key)), nil
+		})
+	case "bytes", "key":
+		return aeadFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxBufferFromBytes(l.key), nil
+		})
+	case "decrypt":
+		return aeadFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			plaintext, err := decrypt(args)
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxBufferFromBytes(plaintext), nil
+		})
+	case "decryptToStr":
+		return aeadFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			plaintext, err := decrypt(args)
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxStringQuote(string(plaintext)), nil
+		})
+	case "encrypt":
+		return aeadFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if err := checkArgsLen(args); err != nil {
+				return nil, err
+			}
+			plaintext, err := dataArg(args, false)
+			if err != nil {
+				return nil, err
+			}
+			nonce, err := nonceArg(args)
+			if err != nil {
+				return nil, err
+			}
+			additionalData, err := additionalDataArg(args)
+			if err != nil {
+				return nil, err
+			}
+			if nonce != nil {
+				return NewLoxBufferFromBytes(l.aead.Seal(nil, nonce, plaintext, additionalData)), nil
+			}
+			//Prepend a random nonce to the ciphertext so that decrypt can find it
+			nonce = make([]byte, l.aead.NonceSize())
+			if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v: Failed to generate random nonce.", l.typeName()))
+			}
+			return NewLoxBufferFromBytes(l.aead.Seal(nonce, nonce, plaintext, additionalData)), nil
+		})
+	case "nonce":
+		return aeadFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			nonce := make([]byte, l.aead.NonceSize())
+			if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v: Failed to generate random nonce.", l.typeName()))
+			}
+			return NewLoxBufferFromBytes(nonce), nil
+		})
+	case "nonceSize":
+		return aeadFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(l.aead.NonceSize()), nil
+		})
+	case "overhead":
+		return aeadFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(l.aead.Overhead()), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, l.typeName()+" objects have no property called '"+methodName+"'.")
+}
+
+// Returns the name of the cipher as it is written in error messages
+func (l *LoxAEAD) typeName() string {
+	if l.name == "aes-gcm" {
+		return "AES-GCM"
+	}
+	return "ChaCha20-Poly1305"
+}
+
+func (l *LoxAEAD) String() string {
+	return fmt.Sprintf("<%v object at %p>", l.typeName(), l)
+}
+
+func (l *LoxAEAD) Type() string {
+	return l.name
+}
@@ -254,6 +254,9 @@ func (l *LoxAESCBC) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, ciphertext, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -466,6 +469,9 @@ func (l *LoxAESCBC) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, ciphertext, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -230,6 +230,9 @@ func (l *LoxAESCFB) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, ciphertext, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -383,6 +386,9 @@ func (l *LoxAESCFB) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, ciphertext, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -330,6 +330,9 @@ func (l *LoxAgeAsymmetric) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, bytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -471,6 +474,9 @@ func (l *LoxAgeAsymmetric) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, bytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -657,6 +663,9 @@ func (l *LoxAgeAsymmetric) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, bytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -730,6 +739,9 @@ func (l *LoxAgeAsymmetric) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, bytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -852,6 +864,9 @@ func (l *LoxAgeAsymmetric) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, getOutput(), 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -361,6 +361,9 @@ func (l *LoxAgeSymmetric) Get(name *token.Token) (any, error) {
 						return nil, loxerror.RuntimeError(name, err.Error())
 					}
 				case *LoxString:
+					if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+						return nil, sandboxErr
+					}
 					err := os.WriteFile(arg.str, bytes, 0666)
 					if err != nil {
 						return nil, loxerror.RuntimeError(name, err.Error())
@@ -553,6 +556,9 @@ func (l *LoxAgeSymmetric) Get(name *token.Token) (any, error) {
 						return nil, loxerror.RuntimeError(name, err.Error())
 					}
 				case *LoxString:
+					if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+						return nil, sandboxErr
+					}
 					err := os.WriteFile(arg.str, bytes, 0666)
 					if err != nil {
 						return nil, loxerror.RuntimeError(name, err.Error())
@@ -830,6 +836,9 @@ func (l *LoxAgeSymmetric) Get(name *token.Token) (any, error) {
 						return nil, loxerror.RuntimeError(name, err.Error())
 					}
 				case *LoxString:
+					if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+						return nil, sandboxErr
+					}
 					err := os.WriteFile(arg.str, bytes, 0666)
 					if err != nil {
 						return nil, loxerror.RuntimeError(name, err.Error())
@@ -932,6 +941,9 @@ func (l *LoxAgeSymmetric) Get(name *token.Token) (any, error) {
 						return nil, loxerror.RuntimeError(name, err.Error())
 					}
 				case *LoxString:
+					if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+						return nil, sandboxErr
+					}
 					err := os.WriteFile(arg.str, bytes, 0666)
 					if err != nil {
 						return nil, loxerror.RuntimeError(name, err.Error())
@@ -0,0 +1,961 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const argparseHelpPosition = 24
+
+type argparseArgument struct {
+	names        []string //Empty for positional arguments
+	dest         string
+	action       string
+	argType      any //A type name string or a function
+	defaultValue any
+	hasDefault   bool
+	help         string
+	required     bool
+	choices      []any
+	nargs        any //nil, an integer, or one of "?", "*", and "+"
+	metavar      string
+}
+
+func (a *argparseArgument) isPositional() bool {
+	return len(a.names) == 0
+}
+
+func (a *argparseArgument) takesValue() bool {
+	return a.action == "store" || a.action == "append"
+}
+
+func (a *argparseArgument) nargsRange() (int, int) {
+	switch nargs := a.nargs.(type) {
+	case int64:
+		return int(nargs), int(nargs)
+	case string:
+		switch nargs {
+		case "?":
+			return 0, 1
+		case "*":
+			return 0, -1
+		case "+":
+			return 1, -1
+		}
+	}
+	return 1, 1
+}
+
+func (a *argparseArgument) displayName() string {
+	if a.isPositional() {
+		return a.metavarName()
+	}
+	return strings.Join(a.names, "/")
+}
+
+func (a *argparseArgument) metavarName() string {
+	if a.metavar != "" {
+		return a.metavar
+	}
+	if len(a.choices) > 0 {
+		choiceStrs := make([]string, 0, len(a.choices))
+		for _, choice := range a.choices {
+			choiceStrs = append(choiceStrs, getResult(choice, choice, true))
+		}
+		return "{" + strings.Join(choiceStrs, ",") + "}"
+	}
+	if a.isPositional() {
+		return a.dest
+	}
+	return strings.ToUpper(strings.ReplaceAll(a.dest, "-", "_"))
+}
+
+func (a *argparseArgument) formatValues() string {
+	metavar := a.metavarName()
+	switch nargs := a.nargs.(type) {
+	case int64:
+		return strings.TrimSpace(strings.Repeat(metavar+" ", int(nargs)))
+	case string:
+		switch nargs {
+		case "?":
+			return "[" + metavar + "]"
+		case "*":
+			return "[" + metavar + " ...]"
+		case "+":
+			return metavar + " [" + metavar + " ...]"
+		}
+	}
+	return metavar
+}
+
+func (a *argparseArgument) initialValue() any {
+	if a.hasDefault {
+		return a.defaultValue
+	}
+	switch a.action {
+	case "storeTrue":
+		return false
+	case "storeFalse":
+		return true
+	case "count":
+		return int64(0)
+	case "append":
+		return EmptyLoxList()
+	}
+	if a.isPositional() && a.nargs == "*" {
+		return EmptyLoxList()
+	}
+	return nil
+}
+
+type argparseSubcommand struct {
+	name   string
+	help   string
+	parser *LoxArgParser
+}
+
+type argparseError struct {
+	parser *LoxArgParser
+	msg    string
+}
+
+func (e *argparseError) Error() string {
+	return e.msg
+}
+
+type argparseHelp struct {
+	parser *LoxArgParser
+}
+
+func (h *argparseHelp) Error() string {
+	return "help requested"
+}
+
+type LoxArgParser struct {
+	prog           string
+	description    string
+	epilog         string
+	exitOnError    bool
+	addHelp        bool
+	subcommandDest string
+	optionals      []*argparseArgument
+	positionals    []*argparseArgument
+	optionMap      map[string]*argparseArgument
+	subcommands    []*argparseSubcommand
+	methods        map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxArgParser(prog string) *LoxArgParser {
+	return &LoxArgParser{
+		prog:           prog,
+		exitOnError:    true,
+		addHelp:        true,
+		subcommandDest: "command",
+		optionMap:      make(map[string]*argparseArgument),
+		methods:        make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxArgParser) errorf(format string, a ...any) error {
+	return &argparseError{l, fmt.Sprintf(format, a...)}
+}
+
+func (l *LoxArgParser) findSubcommand(name string) *argparseSubcommand {
+	for _, subcommand := range l.subcommands {
+		if subcommand.name == name {
+			return subcommand
+		}
+	}
+	return nil
+}
+
+func (l *LoxArgParser) minPositionals(start int) int {
+	total := 0
+	for _, positional := range l.positionals[start:] {
+		minValues, _ := positional.nargsRange()
+		total += minValues
+	}
+	return total
+}
+
+func (l *LoxArgParser) isOption(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	if _, ok := l.optionMap[arg]; ok {
+		return true
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+func (l *LoxArgParser) convert(in *Interpreter, argument *argparseArgument, value string) (any, error) {
+	var result any
+	switch argType := argument.argType.(type) {
+	case nil:
+		result = NewLoxStringQuote(value)
+	case string:
+		var err error
+		switch argType {
+		case "string":
+			result = NewLoxStringQuote(value)
+		case "int":
+			result, err = strconv.ParseInt(value, 0, 64)
+		case "float":
+			result, err = strconv.ParseFloat(value, 64)
+		case "bool":
+			switch strings.ToLower(value) {
+			case "true", "yes", "on", "1":
+				result = true
+			case "false", "no", "off", "0":
+				result = false
+			default:
+				err = strconv.ErrSyntax
+			}
+		}
+		if err != nil {
+			return nil, l.errorf("argument %v: invalid %v value: '%v'",
+				argument.displayName(), argType, value)
+		}
+	case *LoxFunction:
+		argList := getArgList(argType, 1)
+		argList[0] = NewLoxStringQuote(value)
+		var err error
+		result, err = argType.call(in, argList)
+		if resultReturn, ok := result.(Return); ok {
+			result = resultReturn.FinalValue
+		} else if err != nil {
+			return nil, l.errorf("argument %v: invalid value: '%v'",
+				argument.displayName(), value)
+		}
+	}
+	if len(argument.choices) > 0 {
+		found := false
+		for _, choice := range argument.choices {
+			if equatable, ok := result.(interfaces.Equatable); ok {
+				found = equatable.Equals(choice)
+			} else {
+				found = result == choice
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			choiceStrs := make([]string, 0, len(argument.choices))
+			for _, choice := range argument.choices {
+				choiceStrs = append(choiceStrs, getResult(choice, choice, true))
+			}
+			return nil, l.errorf("argument %v: invalid choice: '%v' (choose from %v)",
+				argument.displayName(), value, strings.Join(choiceStrs, ", "))
+		}
+	}
+	return result, nil
+}
+
+func (l *LoxArgParser) convertAll(in *Interpreter, argument *argparseArgument, values []string) (any, error) {
+	if argument.nargs == nil || (argument.nargs == "?" && len(values) == 1) {
+		return l.convert(in, argument, values[0])
+	}
+	if argument.nargs == "?" || (argument.nargs == "*" && len(values) == 0) {
+		return argument.initialValue(), nil
+	}
+	converted := list.NewListCap[any](int64(len(values)))
+	for _, value := range values {
+		result, err := l.convert(in, argument, value)
+		if err != nil {
+			return nil, err
+		}
+		converted.Add(result)
+	}
+	return NewLoxList(converted), nil
+}
+
+func (l *LoxArgParser) expectedValuesMsg(argument *argparseArgument) string {
+	switch nargs := argument.nargs.(type) {
+	case int64:
+		if nargs != 1 {
+			return fmt.Sprintf("expected %v arguments", nargs)
+		}
+	case string:
+		switch nargs {
+		case "?":
+			return "expected at most one argument"
+		case "+":
+			return "expected at least one argument"
+		}
+	}
+	return "expected one argument"
+}
+
+func (l *LoxArgParser) applyFlag(argument *argparseArgument, values map[*argparseArgument]any) {
+	switch argument.action {
+	case "storeTrue":
+		values[argument] = true
+	case "storeFalse":
+		values[argument] = false
+	case "count":
+		count, ok := values[argument].(int64)
+		if !ok {
+			count, _ = argument.initialValue().(int64)
+		}
+		values[argument] = count + 1
+	}
+}
+
+func (l *LoxArgParser) parseOption(
+	in *Interpreter,
+	arg string,
+	rest []string,
+	values map[*argparseArgument]any,
+	seen map[*argparseArgument]bool,
+) (int, error) {
+	if l.addHelp && (arg == "-h" || arg == "--help") {
+		return 0, &argparseHelp{l}
+	}
+	name, value, hasValue := arg, "", false
+	if strings.HasPrefix(arg, "--") {
+		name, value, hasValue = strings.Cut(arg, "=")
+	}
+	option, ok := l.optionMap[name]
+	if !ok && !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+		//Short options can be combined, such as "-abc", or followed
+		//directly by their value, such as "-n5"
+		option, ok = l.optionMap[arg[:2]]
+		if ok {
+			if !option.takesValue() {
+				seen[option] = true
+				l.applyFlag(option, values)
+				return l.parseOption(in, "-"+arg[2:], rest, values, seen)
+			}
+			name, value, hasValue = arg[:2], arg[2:], true
+		}
+	}
+	if !ok {
+		return 0, l.errorf("unrecognized arguments: %v", arg)
+	}
+	seen[option] = true
+	if !option.takesValue() {
+		if hasValue {
+			return 0, l.errorf("argument %v: ignored explicit argument '%v'",
+				option.displayName(), value)
+		}
+		l.applyFlag(option, values)
+		return 0, nil
+	}
+
+	minValues, maxValues := option.nargsRange()
+	consumed := 0
+	var strs []string
+	if hasValue {
+		strs = []string{value}
+	} else {
+		for consumed < len(rest) && (maxValues < 0 || consumed < maxValues) &&
+			rest[consumed] != "--" && !l.isOption(rest[consumed]) {
+			consumed++
+		}
+		strs = rest[:consumed]
+	}
+	if len(strs) < minValues || (maxValues >= 0 && len(strs) > maxValues) {
+		return 0, l.errorf("argument %v: %v", option.displayName(), l.expectedValuesMsg(option))
+	}
+	result, err := l.convertAll(in, option, strs)
+	if err != nil {
+		return 0, err
+	}
+	if option.action == "append" {
+		appended, ok := values[option].(*LoxList)
+		if !ok {
+			appended = EmptyLoxList()
+			if initial, ok := option.initialValue().(*LoxList); ok {
+				appended.elements = append(appended.elements, initial.elements...)
+			}
+		}
+		appended.elements.Add(result)
+		values[option] = appended
+	} else {
+		values[option] = result
+	}
+	return consumed, nil
+}
+
+func (l *LoxArgParser) parseArgs(in *Interpreter, args []string, result *LoxDict) error {
+	values := make(map[*argparseArgument]any)
+	seen := make(map[*argparseArgument]bool)
+	var positionalArgs []string
+	var subcommand *argparseSubcommand
+	var subcommandArgs []string
+	onlyPositionals := false
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+		if !onlyPositionals {
+			if arg == "--" {
+				onlyPositionals = true
+				continue
+			}
+			if l.isOption(arg) {
+				consumed, err := l.parseOption(in, arg, args[index+1:], values, seen)
+				if err != nil {
+					return err
+				}
+				index += consumed
+				continue
+			}
+		}
+		//The subcommand is the first positional argument after the ones
+		//that this parser takes
+		if len(l.subcommands) > 0 && len(positionalArgs) >= l.minPositionals(0) {
+			subcommand = l.findSubcommand(arg)
+			if subcommand == nil {
+				names := make([]string, 0, len(l.subcommands))
+				for _, subcommand := range l.subcommands {
+					names = append(names, subcommand.name)
+				}
+				return l.errorf("argument %v: invalid choice: '%v' (choose from %v)",
+					l.subcommandDest, arg, strings.Join(names, ", "))
+			}
+			subcommandArgs = args[index+1:]
+			break
+		}
+		positionalArgs = append(positionalArgs, arg)
+	}
+
+	index := 0
+	for positionalIndex, positional := range l.positionals {
+		minValues, maxValues := positional.nargsRange()
+		available := len(positionalArgs) - index - l.minPositionals(positionalIndex+1)
+		if available < minValues {
+			var missing []string
+			for _, positional := range l.positionals[positionalIndex:] {
+				if minValues, _ := positional.nargsRange(); minValues > 0 {
+					missing = append(missing, positional.displayName())
+				}
+			}
+			return l.errorf("the following arguments are required: %v", strings.Join(missing, ", "))
+		}
+		take := available
+		if maxValues >= 0 && take > maxValues {
+			take = maxValues
+		}
+		value, err := l.convertAll(in, positional, positionalArgs[index:index+take])
+		if err != nil {
+			return err
+		}
+		result.setKeyValue(NewLoxStringQuote(positional.dest), value)
+		index += take
+	}
+	if index < len(positionalArgs) {
+		return l.errorf("unrecognized arguments: %v", strings.Join(positionalArgs[index:], " "))
+	}
+
+	var missing []string
+	for _, option := range l.optionals {
+		if option.required && !seen[option] {
+			missing = append(missing, option.displayName())
+		}
+	}
+	if len(missing) > 0 {
+		return l.errorf("the following arguments are required: %v", strings.Join(missing, ", "))
+	}
+	for _, option := range l.optionals {
+		value, ok := values[option]
+		if !ok {
+			//Options that share a destination shouldn't overwrite each
+			//other's values with their defaults
+			if _, ok := result.getValueByKey(NewLoxStringQuote(option.dest)); ok {
+				continue
+			}
+			value = option.initialValue()
+		}
+		result.setKeyValue(NewLoxStringQuote(option.dest), value)
+	}
+
+	if len(l.subcommands) > 0 {
+		if subcommand == nil {
+			result.setKeyValue(NewLoxStringQuote(l.subcommandDest), nil)
+			return nil
+		}
+		result.setKeyValue(NewLoxStringQuote(l.subcommandDest), NewLoxStringQuote(subcommand.name))
+		return subcommand.parser.parseArgs(in, subcommandArgs, result)
+	}
+	return nil
+}
+
+func (l *LoxArgParser) parse(in *Interpreter, callToken *token.Token, args []string) (*LoxDict, error) {
+	result := EmptyLoxDict()
+	err := l.parseArgs(in, args, result)
+	switch err := err.(type) {
+	case nil:
+		return result, nil
+	case *argparseHelp:
+		fmt.Print(err.parser.help())
+		exitLox(0)
+	case *argparseError:
+		return nil, l.fail(callToken, err.parser, err.msg)
+	}
+	return nil, err
+}
+
+func (l *LoxArgParser) fail(callToken *token.Token, parser *LoxArgParser, msg string) error {
+	if !l.exitOnError {
+		return loxerror.RuntimeError(callToken, fmt.Sprintf("%v: error: %v", parser.prog, msg))
+	}
+	fmt.Fprint(os.Stderr, parser.usage())
+	fmt.Fprintf(os.Stderr, "%v: error: %v\n", parser.prog, msg)
+	exitLox(2)
+	return nil
+}
+
+func (l *LoxArgParser) usage() string {
+	var parts []string
+	parts = append(parts, "usage: "+l.prog)
+	if l.addHelp {
+		parts = append(parts, "[-h]")
+	}
+	for _, option := range l.optionals {
+		part := option.names[0]
+		if option.takesValue() {
+			part += " " + option.formatValues()
+		}
+		if !option.required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	for _, positional := range l.positionals {
+		parts = append(parts, positional.formatValues())
+	}
+	if len(l.subcommands) > 0 {
+		parts = append(parts, l.subcommandNames()+" ...")
+	}
+	return strings.Join(parts, " ") + "\n"
+}
+
+func (l *LoxArgParser) subcommandNames() string {
+	names := make([]string, 0, len(l.subcommands))
+	for _, subcommand := range l.subcommands {
+		names = append(names, subcommand.name)
+	}
+	return "{" + strings.Join(names, ",") + "}"
+}
+
+func (l *LoxArgParser) help() string {
+	type helpRow struct {
+		invocation string
+		help       string
+	}
+	var positionalRows, optionRows []helpRow
+	for _, positional := range l.positionals {
+		positionalRows = append(positionalRows, helpRow{"  " + positional.metavarName(), positional.help})
+	}
+	if len(l.subcommands) > 0 {
+		positionalRows = append(positionalRows, helpRow{"  " + l.subcommandNames(), ""})
+		for _, subcommand := range l.subcommands {
+			positionalRows = append(positionalRows, helpRow{"    " + subcommand.name, subcommand.help})
+		}
+	}
+	if l.addHelp {
+		optionRows = append(optionRows, helpRow{"  -h, --help", "show this help message and exit"})
+	}
+	for _, option := range l.optionals {
+		invocations := make([]string, 0, len(option.names))
+		for _, name := range option.names {
+			if option.takesValue() {
+				name += " " + option.formatValues()
+			}
+			invocations = append(invocations, name)
+		}
+		optionRows = append(optionRows, helpRow{"  " + strings.Join(invocations, ", "), option.help})
+	}
+
+	helpPosition := 0
+	for _, row := range append(positionalRows, optionRows...) {
+		helpPosition = max(helpPosition, len(row.invocation)+2)
+	}
+	helpPosition = min(helpPosition, argparseHelpPosition)
+	var builder strings.Builder
+	writeRows := func(heading string, rows []helpRow) {
+		if len(rows) == 0 {
+			return
+		}
+		builder.WriteString("\n" + heading + ":\n")
+		for _, row := range rows {
+			builder.WriteString(row.invocation)
+			if row.help != "" {
+				if len(row.invocation)+2 > helpPosition {
+					builder.WriteString("\n" + strings.Repeat(" ", helpPosition))
+				} else {
+					builder.WriteString(strings.Repeat(" ", helpPosition-len(row.invocation)))
+				}
+				builder.WriteString(row.help)
+			}
+			builder.WriteByte('\n')
+		}
+	}
+
+	builder.WriteString(l.usage())
+	if l.description != "" {
+		builder.WriteString("\n" + l.description + "\n")
+	}
+	writeRows("positional arguments", positionalRows)
+	writeRows("options", optionRows)
+	if l.epilog != "" {
+		builder.WriteString("\n" + l.epilog + "\n")
+	}
+	return builder.String()
+}
+
+func (l *LoxArgParser) addArgument(names []string, options *LoxDict) error {
+	argument := &argparseArgument{action: "store"}
+	if len(names) == 1 && !strings.HasPrefix(names[0], "-") {
+		if names[0] == "" {
+			return loxerror.Error("Positional argument names cannot be empty.")
+		}
+		argument.dest = names[0]
+	} else {
+		for _, name := range names {
+			if len(name) < 2 || name[0] != '-' {
+				return loxerror.Error(
+					fmt.Sprintf("Invalid option name '%v': option names must start with a dash.", name))
+			}
+			if _, ok := l.optionMap[name]; ok || (l.addHelp && (name == "-h" || name == "--help")) {
+				return loxerror.Error(fmt.Sprintf("Conflicting option name '%v'.", name))
+			}
+		}
+		argument.names = names
+		//The destination is the first long option name without its dashes,
+		//or the first short option name if there aren't any long ones
+		argument.dest = strings.TrimLeft(names[0], "-")
+		for _, name := range names {
+			if strings.HasPrefix(name, "--") {
+				argument.dest = strings.TrimLeft(name, "-")
+				break
+			}
+		}
+	}
+
+	if options != nil {
+		optionErr := func(key string, theType string) error {
+			return loxerror.Error(
+				fmt.Sprintf("Option '%v' in '%v' must be %v.", key, "argument parser.addArgument", theType))
+		}
+		err := forEachOption(options, func(key string, value any) error {
+			switch key {
+			case "action":
+				action, ok := value.(*LoxString)
+				if !ok {
+					return optionErr(key, "a string")
+				}
+				switch action.str {
+				case "store", "storeTrue", "storeFalse", "count", "append":
+				default:
+					return optionErr(key, "one of 'store', 'storeTrue', 'storeFalse', 'count', or 'append'")
+				}
+				argument.action = action.str
+			case "choices":
+				choices, ok := value.(*LoxList)
+				if !ok {
+					return optionErr(key, "a list")
+				}
+				argument.choices = choices.elements
+			case "default":
+				argument.defaultValue = value
+				argument.hasDefault = true
+			case "dest", "help", "metavar":
+				str, ok := value.(*LoxString)
+				if !ok {
+					return optionErr(key, "a string")
+				}
+				switch key {
+				case "dest":
+					argument.dest = str.str
+				case "help":
+					argument.help = str.str
+				case "metavar":
+					argument.metavar = str.str
+				}
+			case "nargs":
+				switch nargs := value.(type) {
+				case int64:
+					if nargs < 1 {
+						return optionErr(key, "a positive integer or one of '?', '*', or '+'")
+					}
+				case *LoxString:
+					switch nargs.str {
+					case "?", "*", "+":
+						argument.nargs = nargs.str
+						return nil
+					}
+					return optionErr(key, "a positive integer or one of '?', '*', or '+'")
+				default:
+					return optionErr(key, "a positive integer or one of '?', '*', or '+'")
+				}
+				argument.nargs = value
+			case "required":
+				required, ok := value.(bool)
+				if !ok {
+					return optionErr(key, "a boolean")
+				}
+				argument.required = required
+			case "type":
+				switch argType := value.(type) {
+				case *LoxString:
+					switch argType.str {
+					case "string", "int", "float", "bool":
+						argument.argType = argType.str
+						return nil
+					}
+				case *LoxFunction:
+					argument.argType = argType
+					return nil
+				}
+				return optionErr(key, "a function or one of 'string', 'int', 'float', or 'bool'")
+			default:
+				return loxerror.Error(
+					fmt.Sprintf("Unknown option '%v' in '%v'.", key, "argument parser.addArgument"))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !argument.takesValue() && (argument.nargs != nil || argument.argType != nil || argument.choices != nil) {
+		return loxerror.Error(fmt.Sprintf(
+			"Options 'nargs', 'type', and 'choices' cannot be used with action '%v'.", argument.action))
+	}
+	if argument.isPositional() {
+		if argument.action != "store" {
+			return loxerror.Error("Positional arguments can only use the 'store' action.")
+		}
+		if argument.required {
+			return loxerror.Error("Option 'required' cannot be used with positional arguments.")
+		}
+		if len(l.subcommands) > 0 {
+			return loxerror.Error("Positional arguments cannot be added after subcommands.")
+		}
+		l.positionals = append(l.positionals, argument)
+	} else {
+		for _, name := range argument.names {
+			l.optionMap[name] = argument
+		}
+		l.optionals = append(l.optionals, argument)
+	}
+	return nil
+}
+
+func (l *LoxArgParser) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	parserFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native argument parser fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "addArgument":
+		return parserFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			var options *LoxDict
+			if len(args) > 0 {
+				if dict, ok := args[len(args)-1].(*LoxDict); ok {
+					options = dict
+					args = args[:len(args)-1]
+				}
+			}
+			if len(args) == 0 {
+				return nil, loxerror.RuntimeError(name,
+					"Expected at least 1 argument name but got 0.")
+			}
+			names := make([]string, 0, len(args))
+			for _, arg := range args {
+				argName, ok := arg.(*LoxString)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument names passed to 'argument parser.addArgument' must be strings.")
+				}
+				names = append(names, argName.str)
+			}
+			if err := l.addArgument(names, options); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "addSubcommand":
+		return parserFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			subcommandName, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'argument parser.addSubcommand' must be a string.")
+			}
+			if subcommandName.str == "" || strings.HasPrefix(subcommandName.str, "-") {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Subcommand names cannot be empty or start with a dash.")
+			}
+			if l.findSubcommand(subcommandName.str) != nil {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Subcommand '%v' already exists.", subcommandName.str))
+			}
+			parser := NewLoxArgParser(l.prog + " " + subcommandName.str)
+			parser.exitOnError = l.exitOnError
+			parser.addHelp = l.addHelp
+			subcommand := &argparseSubcommand{name: subcommandName.str, parser: parser}
+			if argsLen == 2 {
+				options, ok := args[1].(*LoxDict)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Second argument to 'argument parser.addSubcommand' must be a dictionary.")
+				}
+				err := parser.setOptions(options, "argument parser.addSubcommand", func(key string, value any) (bool, error) {
+					if key != "help" {
+						return false, nil
+					}
+					help, ok := value.(*LoxString)
+					if !ok {
+						return true, loxerror.Error(fmt.Sprintf(
+							"Option '%v' in '%v' must be %v.", key, "argument parser.addSubcommand", "a string"))
+					}
+					subcommand.help = help.str
+					return true, nil
+				})
+				if err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+			}
+			l.subcommands = append(l.subcommands, subcommand)
+			return parser, nil
+		})
+	case "description":
+		return NewLoxStringQuote(l.description), nil
+	case "error":
+		return parserFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if msg, ok := args[0].(*LoxString); ok {
+				return nil, l.fail(name, l, msg.str)
+			}
+			return nil, loxerror.RuntimeError(name,
+				"Argument to 'argument parser.error' must be a string.")
+		})
+	case "help":
+		return parserFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.help()), nil
+		})
+	case "parse":
+		return parserFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			var cmdArgs []string
+			switch len(args) {
+			case 0:
+				if scriptArgs := scriptArgs(); len(scriptArgs) > 0 {
+					cmdArgs = scriptArgs[1:]
+				}
+			case 1:
+				argList, ok := args[0].(*LoxList)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'argument parser.parse' must be a list.")
+				}
+				cmdArgs = make([]string, 0, len(argList.elements))
+				for _, element := range argList.elements {
+					str, ok := element.(*LoxString)
+					if !ok {
+						return nil, loxerror.RuntimeError(name,
+							"List argument to 'argument parser.parse' must only contain strings.")
+					}
+					cmdArgs = append(cmdArgs, str.str)
+				}
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			return l.parse(in, name, cmdArgs)
+		})
+	case "printHelp":
+		return parserFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			fmt.Print(l.help())
+			return nil, nil
+		})
+	case "printUsage":
+		return parserFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			fmt.Print(l.usage())
+			return nil, nil
+		})
+	case "prog":
+		return NewLoxStringQuote(l.prog), nil
+	case "usage":
+		return parserFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.usage()), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Argument parsers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxArgParser) setOptions(options *LoxDict, fnName string, extra func(key string, value any) (bool, error)) error {
+	return forEachOption(options, func(key string, value any) error {
+		switch key {
+		case "description", "epilog", "prog", "subcommandDest":
+			str, ok := value.(*LoxString)
+			if !ok {
+				return loxerror.Error(fmt.Sprintf("Option '%v' in '%v' must be %v.", key, fnName, "a string"))
+			}
+			switch key {
+			case "description":
+				l.description = str.str
+			case "epilog":
+				l.epilog = str.str
+			case "prog":
+				l.prog = str.str
+			case "subcommandDest":
+				l.subcommandDest = str.str
+			}
+		case "addHelp", "exitOnError":
+			boolValue, ok := value.(bool)
+			if !ok {
+				return loxerror.Error(fmt.Sprintf("Option '%v' in '%v' must be %v.", key, fnName, "a boolean"))
+			}
+			if key == "addHelp" {
+				l.addHelp = boolValue
+			} else {
+				l.exitOnError = boolValue
+			}
+		default:
+			handled := false
+			var err error
+			if extra != nil {
+				handled, err = extra(key, value)
+			}
+			if err == nil && !handled {
+				err = loxerror.Error(fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (l *LoxArgParser) String() string {
+	return fmt.Sprintf("<argument parser prog='%v' at %p>", l.prog, l)
+}
+
+func (l *LoxArgParser) Type() string {
+	return "argument parser"
+}
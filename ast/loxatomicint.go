@@ -0,0 +1,110 @@
+package ast
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxAtomicInt struct {
+	value        atomic.Int64
+	methods      map[string]*struct{ ProtoLoxCallable }
+	methodsMutex sync.Mutex
+}
+
+func NewLoxAtomicInt(value int64) *LoxAtomicInt {
+	atomicInt := &LoxAtomicInt{
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	atomicInt.value.Store(value)
+	return atomicInt
+}
+
+func (l *LoxAtomicInt) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.methodsMutex.Lock()
+	defer l.methodsMutex.Unlock()
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	atomicIntFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native atomic int fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeInt := func(callToken *token.Token, argNum string) (any, error) {
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("%vrgument to 'atomic int.%v' must be an integer.", argNum, methodName))
+	}
+	switch methodName {
+	case "add":
+		return atomicIntFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			delta, ok := args[0].(int64)
+			if !ok {
+				return argMustBeInt(in.callToken, "A")
+			}
+			return l.value.Add(delta), nil
+		})
+	case "compareAndSwap":
+		return atomicIntFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			old, ok := args[0].(int64)
+			if !ok {
+				return argMustBeInt(in.callToken, "First a")
+			}
+			newValue, ok := args[1].(int64)
+			if !ok {
+				return argMustBeInt(in.callToken, "Second a")
+			}
+			return l.value.CompareAndSwap(old, newValue), nil
+		})
+	case "decrement":
+		return atomicIntFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.value.Add(-1), nil
+		})
+	case "get":
+		return atomicIntFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.value.Load(), nil
+		})
+	case "increment":
+		return atomicIntFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.value.Add(1), nil
+		})
+	case "set":
+		return atomicIntFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			value, ok := args[0].(int64)
+			if !ok {
+				return argMustBeInt(in.callToken, "A")
+			}
+			l.value.Store(value)
+			return nil, nil
+		})
+	case "swap":
+		return atomicIntFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			value, ok := args[0].(int64)
+			if !ok {
+				return argMustBeInt(in.callToken, "A")
+			}
+			return l.value.Swap(value), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Atomic ints have no property called '"+methodName+"'.")
+}
+
+func (l *LoxAtomicInt) String() string {
+	return fmt.Sprintf("<atomic int %v at %p>", l.value.Load(), l)
+}
+
+func (l *LoxAtomicInt) Type() string {
+	return "atomic int"
+}
@@ -0,0 +1,428 @@
+package ast
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxBitsetIterator struct {
+	bitset *LoxBitset
+	next   int64
+}
+
+func (l *LoxBitsetIterator) HasNext() bool {
+	l.next = l.bitset.nextSet(l.next)
+	return l.next >= 0
+}
+
+func (l *LoxBitsetIterator) Next() any {
+	index := l.next
+	l.next++
+	return index
+}
+
+type LoxBitset struct {
+	words   []uint64
+	size    int64
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxBitset(size int64) *LoxBitset {
+	return &LoxBitset{
+		words:   make([]uint64, (size+63)/64),
+		size:    size,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Returns a new bitset from the specified bytes, where bit i is the bit at
+// position i % 8 of byte i / 8, counting from the least significant bit
+func NewLoxBitsetFromBytes(bytes []byte, size int64) *LoxBitset {
+	bitset := NewLoxBitset(size)
+	for index, b := range bytes {
+		if int64(index)*8 >= size {
+			break
+		}
+		bitset.words[index/8] |= uint64(b) << (uint(index%8) * 8)
+	}
+	bitset.trim()
+	return bitset
+}
+
+func (l *LoxBitset) test(index int64) bool {
+	return l.words[index/64]&(1<<uint(index%64)) != 0
+}
+
+func (l *LoxBitset) set(index int64) {
+	l.words[index/64] |= 1 << uint(index%64)
+}
+
+func (l *LoxBitset) clear(index int64) {
+	l.words[index/64] &^= 1 << uint(index%64)
+}
+
+func (l *LoxBitset) flip(index int64) {
+	l.words[index/64] ^= 1 << uint(index%64)
+}
+
+// Clears the unused bits after the last bit of the bitset
+func (l *LoxBitset) trim() {
+	if l.size%64 != 0 {
+		l.words[len(l.words)-1] &= (1 << uint(l.size%64)) - 1
+	}
+}
+
+func (l *LoxBitset) count() int64 {
+	count := 0
+	for _, word := range l.words {
+		count += bits.OnesCount64(word)
+	}
+	return int64(count)
+}
+
+func (l *LoxBitset) copy() *LoxBitset {
+	bitset := NewLoxBitset(l.size)
+	copy(bitset.words, l.words)
+	return bitset
+}
+
+// Returns a new bitset that is the result of applying op to each pair of
+// words in the two bitsets, where the shorter bitset is extended with zeros
+func (l *LoxBitset) combine(other *LoxBitset, op func(a uint64, b uint64) uint64) *LoxBitset {
+	bitset := NewLoxBitset(max(l.size, other.size))
+	for index := range bitset.words {
+		var a, b uint64
+		if index < len(l.words) {
+			a = l.words[index]
+		}
+		if index < len(other.words) {
+			b = other.words[index]
+		}
+		bitset.words[index] = op(a, b)
+	}
+	bitset.trim()
+	return bitset
+}
+
+func (l *LoxBitset) complement() *LoxBitset {
+	bitset := NewLoxBitset(l.size)
+	for index, word := range l.words {
+		bitset.words[index] = ^word
+	}
+	bitset.trim()
+	return bitset
+}
+
+func (l *LoxBitset) resize(size int64) {
+	numWords := (size + 63) / 64
+	if numWords <= int64(cap(l.words)) {
+		oldLen := len(l.words)
+		l.words = l.words[:numWords]
+		for index := oldLen; index < len(l.words); index++ {
+			l.words[index] = 0
+		}
+	} else {
+		words := make([]uint64, numWords)
+		copy(words, l.words)
+		l.words = words
+	}
+	l.size = size
+	l.trim()
+}
+
+// Returns the index of the first set bit at or after the specified index,
+// or -1 if there is no such bit
+func (l *LoxBitset) nextSet(from int64) int64 {
+	if from < 0 {
+		from = 0
+	}
+	if from >= l.size {
+		return -1
+	}
+	wordIndex := from / 64
+	word := l.words[wordIndex] >> uint(from%64)
+	if word != 0 {
+		return from + int64(bits.TrailingZeros64(word))
+	}
+	for wordIndex++; wordIndex < int64(len(l.words)); wordIndex++ {
+		if l.words[wordIndex] != 0 {
+			return wordIndex*64 + int64(bits.TrailingZeros64(l.words[wordIndex]))
+		}
+	}
+	return -1
+}
+
+// Returns the index of the first clear bit at or after the specified index,
+// or -1 if there is no such bit
+func (l *LoxBitset) nextClear(from int64) int64 {
+	if from < 0 {
+		from = 0
+	}
+	for index := from; index < l.size; {
+		word := ^l.words[index/64] >> uint(index%64)
+		if word != 0 {
+			result := index + int64(bits.TrailingZeros64(word))
+			if result >= l.size {
+				return -1
+			}
+			return result
+		}
+		index = (index/64 + 1) * 64
+	}
+	return -1
+}
+
+// Returns the bits of the bitset as a string of 0s and 1s, starting with
+// the bit at index 0
+func (l *LoxBitset) bitString() string {
+	var builder strings.Builder
+	builder.Grow(int(l.size))
+	for index := int64(0); index < l.size; index++ {
+		if l.test(index) {
+			builder.WriteByte('1')
+		} else {
+			builder.WriteByte('0')
+		}
+	}
+	return builder.String()
+}
+
+func (l *LoxBitset) bytes() []byte {
+	bytes := make([]byte, (l.size+7)/8)
+	for index := range bytes {
+		bytes[index] = byte(l.words[index/8] >> (uint(index%8) * 8))
+	}
+	return bytes
+}
+
+func (l *LoxBitset) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxBitset:
+		if l == obj {
+			return true
+		}
+		if l.size != obj.size {
+			return false
+		}
+		for index, word := range l.words {
+			if word != obj.words[index] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *LoxBitset) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	bitsetFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native bitset fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'bitset.%v' must be %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	indexArg := func(arg any) (int64, error) {
+		index, ok := arg.(int64)
+		if !ok {
+			return 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("Index argument to 'bitset.%v' must be an integer.", methodName))
+		}
+		if index < 0 || index >= l.size {
+			return 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+				fmt.Sprintf("Bitset index %v out of range.", index))
+		}
+		return index, nil
+	}
+	//Defines a method that changes the bit at the specified index
+	indexFunc := func(fn func(index int64)) (*struct{ ProtoLoxCallable }, error) {
+		return bitsetFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			index, err := indexArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			fn(index)
+			return nil, nil
+		})
+	}
+	//Defines a method that combines the current bitset with another bitset
+	combineFunc := func(op func(a uint64, b uint64) uint64) (*struct{ ProtoLoxCallable }, error) {
+		return bitsetFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if other, ok := args[0].(*LoxBitset); ok {
+				return l.combine(other, op), nil
+			}
+			return argMustBeType("a bitset")
+		})
+	}
+	//Defines a method that sets every word of the bitset
+	fillFunc := func(fn func(word uint64) uint64) (*struct{ ProtoLoxCallable }, error) {
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			for index, word := range l.words {
+				l.words[index] = fn(word)
+			}
+			l.trim()
+			return nil, nil
+		})
+	}
+	nextFunc := func(next func(from int64) int64) (*struct{ ProtoLoxCallable }, error) {
+		return bitsetFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if from, ok := args[0].(int64); ok {
+				return next(from), nil
+			}
+			return argMustBeType("an integer")
+		})
+	}
+	switch methodName {
+	case "all":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.count() == l.size, nil
+		})
+	case "any":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.nextSet(0) >= 0, nil
+		})
+	case "clear":
+		return indexFunc(l.clear)
+	case "clearAll":
+		return fillFunc(func(_ uint64) uint64 { return 0 })
+	case "complement":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.complement(), nil
+		})
+	case "copy":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.copy(), nil
+		})
+	case "count":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.count(), nil
+		})
+	case "difference":
+		return combineFunc(func(a uint64, b uint64) uint64 { return a &^ b })
+	case "flip":
+		return indexFunc(l.flip)
+	case "flipAll":
+		return fillFunc(func(word uint64) uint64 { return ^word })
+	case "intersection":
+		return combineFunc(func(a uint64, b uint64) uint64 { return a & b })
+	case "nextClear":
+		return nextFunc(l.nextClear)
+	case "nextSet":
+		return nextFunc(l.nextSet)
+	case "none":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.nextSet(0) < 0, nil
+		})
+	case "resize":
+		return bitsetFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			size, ok := args[0].(int64)
+			if !ok {
+				return argMustBeType("an integer")
+			}
+			if size < 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Argument to 'bitset.resize' cannot be negative.")
+			}
+			if limitErr := checkSizeLimit(name, (size+7)/8); limitErr != nil {
+				return nil, limitErr
+			}
+			l.resize(size)
+			return nil, nil
+		})
+	case "set":
+		return bitsetFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			index, err := indexArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if argsLen == 2 && !in.isTruthy(args[1]) {
+				l.clear(index)
+			} else {
+				l.set(index)
+			}
+			return nil, nil
+		})
+	case "setAll":
+		return fillFunc(func(_ uint64) uint64 { return ^uint64(0) })
+	case "setBits":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			setBits := list.NewListCap[any](l.count())
+			for index := l.nextSet(0); index >= 0; index = l.nextSet(index + 1) {
+				setBits.Add(index)
+			}
+			return NewLoxList(setBits), nil
+		})
+	case "size":
+		return l.size, nil
+	case "symmetricDifference":
+		return combineFunc(func(a uint64, b uint64) uint64 { return a ^ b })
+	case "test":
+		return bitsetFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			index, err := indexArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return l.test(index), nil
+		})
+	case "toBuffer":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxBufferFromBytes(l.bytes()), nil
+		})
+	case "toList":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			bools := list.NewListCap[any](l.size)
+			for index := int64(0); index < l.size; index++ {
+				bools.Add(l.test(index))
+			}
+			return NewLoxList(bools), nil
+		})
+	case "toString":
+		return bitsetFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.bitString()), nil
+		})
+	case "union":
+		return combineFunc(func(a uint64, b uint64) uint64 { return a | b })
+	}
+	return nil, loxerror.RuntimeError(name, "Bitsets have no property called '"+methodName+"'.")
+}
+
+func (l *LoxBitset) Iterator() interfaces.Iterator {
+	return &LoxBitsetIterator{l, 0}
+}
+
+func (l *LoxBitset) Length() int64 {
+	return l.size
+}
+
+func (l *LoxBitset) String() string {
+	return fmt.Sprintf("bitset('%v')", l.bitString())
+}
+
+func (l *LoxBitset) Type() string {
+	return "bitset"
+}
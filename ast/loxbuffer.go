@@ -1,6 +1,9 @@
 package ast
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -48,6 +51,15 @@ func EmptyLoxBufferCapDouble(cap int64) *LoxBuffer {
 	return NewLoxBuffer(list.NewListCapDouble[any](cap))
 }
 
+// Returns a new buffer containing the specified bytes
+func NewLoxBufferFromBytes(bytes []byte) *LoxBuffer {
+	elements := list.NewListCap[any](int64(len(bytes)))
+	for _, element := range bytes {
+		elements.Add(int64(element))
+	}
+	return NewLoxBuffer(elements)
+}
+
 func (l *LoxBuffer) Equals(obj any) bool {
 	switch obj := obj.(type) {
 	case *LoxBuffer:
@@ -123,6 +135,78 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 		}
 		return buffer, nil
 	}
+	ordinals := []string{"First", "Second", "Third"}
+	//Returns the start and stop indexes from the arguments starting at
+	//argIndex, where stop defaults to the length of the buffer
+	rangeArgs := func(args list.List[any], argIndex int) (int64, int64, error) {
+		start, stop := int64(0), int64(len(l.elements))
+		if len(args) > argIndex {
+			num, ok := args[argIndex].(int64)
+			if !ok {
+				return 0, 0, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v argument to 'buffer.%v' must be an integer.", ordinals[argIndex], methodName))
+			}
+			start = num
+		}
+		if len(args) > argIndex+1 {
+			num, ok := args[argIndex+1].(int64)
+			if !ok {
+				return 0, 0, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v argument to 'buffer.%v' must be an integer.", ordinals[argIndex+1], methodName))
+			}
+			stop = num
+		}
+		if start < 0 || start > int64(len(l.elements)) {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name, BufferIndexOutOfRange(start))
+		}
+		if stop < start || stop > int64(len(l.elements)) {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name, BufferIndexOutOfRange(stop))
+		}
+		return start, stop, nil
+	}
+	if numType, isRead, ok := bufferNumMethod(methodName); ok {
+		//Returns the offset argument, which must leave room for a value of
+		//numType at that offset in the buffer
+		offsetArg := func(arg any) (int64, error) {
+			offset, ok := arg.(int64)
+			if !ok {
+				return 0, loxerror.RuntimeError(name,
+					fmt.Sprintf("First argument to 'buffer.%v' must be an integer.", methodName))
+			}
+			if offset < 0 || offset > int64(len(l.elements))-int64(numType.size) {
+				return 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+					fmt.Sprintf("Buffer offset %v out of range for %v-byte value.", offset, numType.size))
+			}
+			return offset, nil
+		}
+		if isRead {
+			return bufferFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+				offset, err := offsetArg(args[0])
+				if err != nil {
+					return nil, err
+				}
+				data := make([]byte, numType.size)
+				for index := range data {
+					data[index] = byte(l.elements[offset+int64(index)].(int64))
+				}
+				return numType.decode(data), nil
+			})
+		}
+		return bufferFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			offset, err := offsetArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			data, err := numType.encode(name, methodName, args[1])
+			if err != nil {
+				return nil, err
+			}
+			for index, b := range data {
+				l.elements[offset+int64(index)] = int64(b)
+			}
+			return nil, nil
+		})
+	}
 	switch methodName {
 	case "append":
 		return bufferFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
@@ -143,6 +227,26 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeType("buffer")
 		})
+	case "fill":
+		return bufferFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen < 1 || argsLen > 3 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 to 3 arguments but got %v.", argsLen))
+			}
+			rangeErr := bufferElementRangeCheck(args[0])
+			if rangeErr != nil {
+				return nil, loxerror.RuntimeError(name, rangeErr.Error())
+			}
+			start, stop, err := rangeArgs(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			for index := start; index < stop; index++ {
+				l.elements[index] = args[0]
+			}
+			return nil, nil
+		})
 	case "filter":
 		return bufferFunc(1, func(i *Interpreter, args list.List[any]) (any, error) {
 			if callback, ok := args[0].(*LoxFunction); ok {
@@ -176,6 +280,39 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 			}
 			return NewLoxBuffer(newList), nil
 		})
+	case "indexOf":
+		return bufferFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			var sub []byte
+			switch arg := args[0].(type) {
+			case int64:
+				if bufferElementRangeCheck(arg) != nil {
+					return nil, loxerror.RuntimeError(name,
+						"Integer argument to 'buffer.indexOf' must be between 0 and 255.")
+				}
+				sub = []byte{byte(arg)}
+			case *LoxBuffer:
+				sub = arg.bytes()
+			case *LoxString:
+				sub = []byte(arg.str)
+			default:
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'buffer.indexOf' must be an integer, buffer, or string.")
+			}
+			start, _, err := rangeArgs(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			index := bytes.Index(l.bytes()[start:], sub)
+			if index < 0 {
+				return int64(-1), nil
+			}
+			return start + int64(index), nil
+		})
 	case "insert":
 		return bufferFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if index, ok := args[0].(int64); ok {
@@ -184,7 +321,7 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 					index += int64(len(l.elements))
 				}
 				if index < 0 || index > int64(len(l.elements)) {
-					return nil, loxerror.RuntimeError(name, BufferIndexOutOfRange(originalIndex))
+					return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name, BufferIndexOutOfRange(originalIndex))
 				}
 				newElement := args[1]
 				rangeErr := bufferElementRangeCheck(newElement)
@@ -194,7 +331,7 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 				l.elements.AddAt(index, newElement)
 				return nil, nil
 			}
-			return nil, loxerror.RuntimeError(name, BufferIndexMustBeWholeNum(args[0]))
+			return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, name, BufferIndexMustBeWholeNum(args[0]))
 		})
 	case "map":
 		return bufferFunc(1, func(i *Interpreter, args list.List[any]) (any, error) {
@@ -379,6 +516,14 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 			}
 			return memfrobCopy(start, stop)
 		})
+	case "toBase64":
+		return bufferFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(base64.StdEncoding.EncodeToString(l.bytes())), nil
+		})
+	case "toBase64URLSafe":
+		return bufferFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(base64.URLEncoding.EncodeToString(l.bytes())), nil
+		})
 	case "tobigint":
 		return bufferFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			elementsLen := len(l.elements)
@@ -399,6 +544,10 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 			}
 			return bigInt, nil
 		})
+	case "toHex":
+		return bufferFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(hex.EncodeToString(l.bytes())), nil
+		})
 	case "toList":
 		return bufferFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			newList := list.NewListCap[any](int64(len(l.elements)))
@@ -449,6 +598,21 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 			}
 			return NewLoxString(builder.String(), '\''), nil
 		})
+	case "view":
+		return bufferFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			start, stop, err := rangeArgs(args, 0)
+			if err != nil {
+				return nil, err
+			}
+			//Limiting the capacity of the view makes appending to the view
+			//copy its elements instead of overwriting elements of this buffer
+			return NewLoxBuffer(l.elements[start:stop:stop]), nil
+		})
 	case "with":
 		return bufferFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if newIndex, ok := args[0].(int64); ok {
@@ -457,7 +621,7 @@ func (l *LoxBuffer) Get(name *token.Token) (any, error) {
 					newIndex += int64(len(l.elements))
 				}
 				if newIndex < 0 || newIndex >= int64(len(l.elements)) {
-					return nil, loxerror.RuntimeError(name, BufferIndexOutOfRange(originalNewIndex))
+					return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name, BufferIndexOutOfRange(originalNewIndex))
 				}
 				newElement := args[1]
 				rangeErr := bufferElementRangeCheck(newElement)
@@ -518,6 +682,15 @@ func (l *LoxBuffer) add(element any) error {
 	return nil
 }
 
+// Returns the elements of the buffer as bytes
+func (l *LoxBuffer) bytes() []byte {
+	bytes := make([]byte, len(l.elements))
+	for index, element := range l.elements {
+		bytes[index] = byte(element.(int64))
+	}
+	return bytes
+}
+
 func (l *LoxBuffer) setIndex(index int64, element any) error {
 	if index < 0 || index > int64(len(l.elements)) {
 		return loxerror.Error(BufferIndexOutOfRange(index))
@@ -1,16 +1,74 @@
 package ast
 
-import "github.com/AlanLuu/lox/list"
+import (
+	"slices"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
 
 type LoxCallable interface {
 	arity() int
 	call(interpreter *Interpreter, arguments list.List[any]) (any, error)
 }
 
+// Implemented by callables whose arity is -1 but that still only accept a
+// certain range of arguments, such as functions with default parameter
+// values. A maximum of -1 means that there is no maximum.
+type LoxArityRangeCallable interface {
+	arityRange() (int, int)
+}
+
+// Implemented by callables that can be called with named arguments in
+// addition to positional arguments. Named arguments are checked against
+// the callable's parameters using checkNamedArgs before callNamed is called
+type LoxNamedArgsCallable interface {
+	checkNamedArgs(callToken *token.Token, numPositional int, names []*token.Token) error
+	callNamed(interpreter *Interpreter, arguments list.List[any], namedArgs map[string]any) (any, error)
+}
+
 type ProtoLoxCallable struct {
 	arityMethod  func() int
 	callMethod   func(interpreter *Interpreter, arguments list.List[any]) (any, error)
 	stringMethod func() string
+	paramNames   []string //Native functions that set this can be called with named arguments
+}
+
+// Converts named arguments into positional arguments for a native function
+// with the specified parameter names. Every parameter up to the last named
+// argument must be given a value.
+func namedArgsToPositional(
+	callToken *token.Token,
+	paramNames []string,
+	arguments list.List[any],
+	names []*token.Token,
+	namedArgs map[string]any,
+) (list.List[any], error) {
+	numArgs := len(arguments)
+	for _, name := range names {
+		index := slices.Index(paramNames, name.Lexeme)
+		if index < 0 {
+			return nil, loxerror.RuntimeError(name, "Unknown parameter '"+name.Lexeme+"'.")
+		}
+		if index < len(arguments) {
+			return nil, loxerror.RuntimeError(name, "Got multiple values for parameter '"+name.Lexeme+"'.")
+		}
+		numArgs = max(numArgs, index+1)
+	}
+	positional := list.NewListCap[any](int64(numArgs))
+	for _, argument := range arguments {
+		positional.Add(argument)
+	}
+	for index := len(arguments); index < numArgs; index++ {
+		value, ok := namedArgs[paramNames[index]]
+		if !ok {
+			positional.Clear()
+			return nil, loxerror.RuntimeError(callToken, "Missing argument for parameter '"+paramNames[index]+"'.")
+		}
+		positional.Add(value)
+	}
+	return positional, nil
 }
 
 func (l ProtoLoxCallable) arity() int {
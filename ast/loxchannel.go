@@ -0,0 +1,215 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxChannelIterator struct {
+	channel *LoxChannel
+	value   any
+	fetched bool
+	done    bool
+}
+
+func (l *LoxChannelIterator) HasNext() bool {
+	if l.done {
+		return false
+	}
+	if !l.fetched {
+		value, ok := <-l.channel.ch
+		if !ok {
+			l.done = true
+			return false
+		}
+		l.value = value
+		l.fetched = true
+	}
+	return true
+}
+
+func (l *LoxChannelIterator) Next() any {
+	if !l.HasNext() {
+		return nil
+	}
+	l.fetched = false
+	value := l.value
+	l.value = nil
+	return value
+}
+
+type LoxChannel struct {
+	ch           chan any
+	closed       atomic.Bool
+	methods      map[string]*struct{ ProtoLoxCallable }
+	methodsMutex sync.Mutex
+}
+
+func NewLoxChannel(capacity int64) *LoxChannel {
+	return &LoxChannel{
+		ch:      make(chan any, capacity),
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxChannel) close() (err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("Cannot close a channel that is already closed.")
+		}
+	}()
+	close(l.ch)
+	l.closed.Store(true)
+	return nil
+}
+
+func (l *LoxChannel) send(value any) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("Cannot send to a closed channel.")
+		}
+	}()
+	l.ch <- value
+	return nil
+}
+
+func (l *LoxChannel) trySend(value any) (sent bool, err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("Cannot send to a closed channel.")
+		}
+	}()
+	select {
+	case l.ch <- value:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Waits until a value can be received from any of the specified channels
+// and returns the channel along with the value. Channels that are closed
+// and have no values left are skipped. Returns a nil channel if every
+// channel is closed or if the timeout passes first; a negative timeout
+// means that there is no timeout.
+func selectChannels(channels []*LoxChannel, timeout time.Duration) (*LoxChannel, any) {
+	cases := make([]reflect.SelectCase, 0, len(channels)+1)
+	remaining := make([]*LoxChannel, 0, len(channels))
+	for _, channel := range channels {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(channel.ch),
+		})
+		remaining = append(remaining, channel)
+	}
+	if timeout >= 0 {
+		cases = append(cases, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(time.After(timeout)),
+		})
+	}
+	for len(remaining) > 0 {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen >= len(remaining) {
+			return nil, nil
+		}
+		if ok {
+			return remaining[chosen], value.Interface()
+		}
+		cases = append(cases[:chosen], cases[chosen+1:]...)
+		remaining = append(remaining[:chosen], remaining[chosen+1:]...)
+	}
+	return nil, nil
+}
+
+func (l *LoxChannel) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.methodsMutex.Lock()
+	defer l.methodsMutex.Unlock()
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	channelFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native channel fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "capacity":
+		return channelFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(cap(l.ch)), nil
+		})
+	case "close":
+		return channelFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.close(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return channelFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.closed.Load(), nil
+		})
+	case "length":
+		return channelFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(len(l.ch)), nil
+		})
+	case "receive":
+		return channelFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return <-l.ch, nil
+		})
+	case "send":
+		return channelFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := l.send(args[0]); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "tryReceive":
+		return channelFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			select {
+			case value := <-l.ch:
+				return value, nil
+			default:
+				return nil, nil
+			}
+		})
+	case "trySend":
+		return channelFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			sent, err := l.trySend(args[0])
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return sent, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Channels have no property called '"+methodName+"'.")
+}
+
+func (l *LoxChannel) Iterator() interfaces.Iterator {
+	return &LoxChannelIterator{channel: l}
+}
+
+func (l *LoxChannel) String() string {
+	return fmt.Sprintf("<channel at %p>", l)
+}
+
+func (l *LoxChannel) Type() string {
+	return "channel"
+}
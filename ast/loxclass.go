@@ -17,6 +17,10 @@ type LoxClass struct {
 	instanceFields      map[string]any
 	canInstantiate      bool
 	isBuiltin           bool
+	sandboxed           bool //Set for built-in classes that are disabled in sandbox mode
+	//Called when a built-in class that can't be instantiated is called
+	//like a function, such as the input class
+	callFunc func(*Interpreter, list.List[any]) (any, error)
 }
 
 type LoxBuiltInProtoCallable struct {
@@ -71,8 +75,39 @@ func (c *LoxClass) arity() int {
 	return 0
 }
 
+func (c *LoxClass) checkNamedArgs(callToken *token.Token, numPositional int, names []*token.Token) error {
+	if !c.canInstantiate {
+		return nil
+	}
+	if initializer, ok := c.findMethod("init"); ok {
+		return initializer.checkNamedArgs(callToken, numPositional, names)
+	}
+	return loxerror.RuntimeError(names[0],
+		fmt.Sprintf("Class '%v' cannot be called with named arguments.", c.name))
+}
+
+func (c *LoxClass) arityRange() (int, int) {
+	if c.canInstantiate {
+		if initializer, ok := c.findMethod("init"); ok {
+			return initializer.arityRange()
+		}
+	}
+	return 0, -1
+}
+
 func (c *LoxClass) call(interpreter *Interpreter, arguments list.List[any]) (any, error) {
+	return c.callNamed(interpreter, arguments, nil)
+}
+
+func (c *LoxClass) callNamed(interpreter *Interpreter, arguments list.List[any], namedArgs map[string]any) (any, error) {
 	if !c.canInstantiate {
+		if c.callFunc != nil {
+			if len(namedArgs) > 0 {
+				return nil, loxerror.RuntimeError(interpreter.callToken,
+					fmt.Sprintf("Class '%v' cannot be called with named arguments.", c.name))
+			}
+			return c.callFunc(interpreter, arguments)
+		}
 		return nil, loxerror.RuntimeError(interpreter.callToken,
 			fmt.Sprintf("Cannot instantiate class '%v'.", c.name))
 	}
@@ -91,7 +126,7 @@ func (c *LoxClass) call(interpreter *Interpreter, arguments list.List[any]) (any
 	}
 	initializer, ok := c.findMethod("init")
 	if ok {
-		call, callErr := initializer.bind(instance).call(interpreter, arguments)
+		call, callErr := initializer.bind(instance).callNamed(interpreter, arguments, namedArgs)
 		if callErr != nil && call == nil {
 			return nil, callErr
 		}
@@ -112,6 +147,10 @@ func (c *LoxClass) call(interpreter *Interpreter, arguments list.List[any]) (any
 }
 
 func (c *LoxClass) Get(name *token.Token) (any, error) {
+	if c.sandboxed {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("'%v' is not available in sandbox mode.", c.name))
+	}
 	staticMethod, foundMethod, methodDepth := c.findBindedStaticMethod(name.Lexeme)
 	if foundMethod && methodDepth == 0 {
 		return staticMethod, nil
@@ -0,0 +1,523 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxColor struct {
+	r, g, b uint8
+	alpha   float64
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxColor(r, g, b uint8, alpha float64) *LoxColor {
+	return &LoxColor{
+		r:       r,
+		g:       g,
+		b:       b,
+		alpha:   alpha,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func clampColorChannel(value float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, value))))
+}
+
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+func NewLoxColorHSL(h, s, l, alpha float64) *LoxColor {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clampUnit(s)
+	l = clampUnit(l)
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return NewLoxColor(
+		clampColorChannel((r+m)*255),
+		clampColorChannel((g+m)*255),
+		clampColorChannel((b+m)*255),
+		clampUnit(alpha),
+	)
+}
+
+func NewLoxColorHSV(h, s, v, alpha float64) *LoxColor {
+	s = clampUnit(s)
+	v = clampUnit(v)
+	l := v * (1 - s/2)
+	var sl float64
+	if l > 0 && l < 1 {
+		sl = (v - l) / math.Min(l, 1-l)
+	}
+	return NewLoxColorHSL(h, sl, l, alpha)
+}
+
+func parseColorFuncArgs(str string, prefix string) ([]string, bool) {
+	if !strings.HasPrefix(str, prefix+"(") || !strings.HasSuffix(str, ")") {
+		return nil, false
+	}
+	inner := str[len(prefix)+1 : len(str)-1]
+	inner = strings.ReplaceAll(inner, "/", ",")
+	var parts []string
+	if strings.Contains(inner, ",") {
+		parts = strings.Split(inner, ",")
+	} else {
+		parts = strings.Fields(inner)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts, true
+}
+
+func parseColorComponent(str string, max float64) (float64, error) {
+	if strings.HasSuffix(str, "%") {
+		num, err := strconv.ParseFloat(str[:len(str)-1], 64)
+		if err != nil {
+			return 0, err
+		}
+		return num / 100 * max, nil
+	}
+	return strconv.ParseFloat(strings.TrimSuffix(str, "deg"), 64)
+}
+
+func ParseLoxColor(str string) (*LoxColor, error) {
+	invalidErr := errors.New("Invalid color string '" + str + "'.")
+	str = strings.ToLower(strings.TrimSpace(str))
+	if strings.HasPrefix(str, "#") {
+		hex := str[1:]
+		switch len(hex) {
+		case 3, 4:
+			var expanded strings.Builder
+			for _, c := range hex {
+				expanded.WriteRune(c)
+				expanded.WriteRune(c)
+			}
+			hex = expanded.String()
+		case 6, 8:
+		default:
+			return nil, invalidErr
+		}
+		value, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return nil, invalidErr
+		}
+		alpha := 1.0
+		if len(hex) == 8 {
+			alpha = float64(value&0xff) / 255
+			value >>= 8
+		}
+		return NewLoxColor(uint8(value>>16), uint8(value>>8), uint8(value), alpha), nil
+	}
+	for _, prefix := range []string{"rgba", "rgb"} {
+		if parts, ok := parseColorFuncArgs(str, prefix); ok {
+			if len(parts) != 3 && len(parts) != 4 {
+				return nil, invalidErr
+			}
+			channels := [3]float64{}
+			for i := 0; i < 3; i++ {
+				num, err := parseColorComponent(parts[i], 255)
+				if err != nil {
+					return nil, invalidErr
+				}
+				channels[i] = num
+			}
+			alpha := 1.0
+			if len(parts) == 4 {
+				num, err := parseColorComponent(parts[3], 1)
+				if err != nil {
+					return nil, invalidErr
+				}
+				alpha = clampUnit(num)
+			}
+			return NewLoxColor(
+				clampColorChannel(channels[0]),
+				clampColorChannel(channels[1]),
+				clampColorChannel(channels[2]),
+				alpha,
+			), nil
+		}
+	}
+	for _, prefix := range []string{"hsla", "hsl", "hsva", "hsv"} {
+		if parts, ok := parseColorFuncArgs(str, prefix); ok {
+			if len(parts) != 3 && len(parts) != 4 {
+				return nil, invalidErr
+			}
+			h, err := parseColorComponent(parts[0], 360)
+			if err != nil {
+				return nil, invalidErr
+			}
+			s, err := parseColorComponent(parts[1], 1)
+			if err != nil {
+				return nil, invalidErr
+			}
+			l, err := parseColorComponent(parts[2], 1)
+			if err != nil {
+				return nil, invalidErr
+			}
+			alpha := 1.0
+			if len(parts) == 4 {
+				alpha, err = parseColorComponent(parts[3], 1)
+				if err != nil {
+					return nil, invalidErr
+				}
+			}
+			if strings.HasPrefix(prefix, "hsv") {
+				return NewLoxColorHSV(h, s, l, alpha), nil
+			}
+			return NewLoxColorHSL(h, s, l, alpha), nil
+		}
+	}
+	if hex, ok := namedColors[str]; ok {
+		return ParseLoxColor(hex)
+	}
+	return nil, invalidErr
+}
+
+var namedColors = map[string]string{
+	"black":   "#000000",
+	"blue":    "#0000ff",
+	"cyan":    "#00ffff",
+	"gray":    "#808080",
+	"green":   "#008000",
+	"grey":    "#808080",
+	"magenta": "#ff00ff",
+	"orange":  "#ffa500",
+	"purple":  "#800080",
+	"red":     "#ff0000",
+	"white":   "#ffffff",
+	"yellow":  "#ffff00",
+}
+
+func (l *LoxColor) hex() string {
+	if l.alpha < 1 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", l.r, l.g, l.b, clampColorChannel(l.alpha*255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", l.r, l.g, l.b)
+}
+
+func (l *LoxColor) hsl() (float64, float64, float64) {
+	r := float64(l.r) / 255
+	g := float64(l.g) / 255
+	b := float64(l.b) / 255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	lightness := (max + min) / 2
+	if max == min {
+		return 0, 0, lightness
+	}
+	d := max - min
+	var s float64
+	if lightness > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	var h float64
+	switch max {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, lightness
+}
+
+func (l *LoxColor) hsv() (float64, float64, float64) {
+	h, s, lightness := l.hsl()
+	v := lightness + s*math.Min(lightness, 1-lightness)
+	var sv float64
+	if v > 0 {
+		sv = 2 * (1 - lightness/v)
+	}
+	return h, sv, v
+}
+
+// luminance returns the relative luminance of this color as defined by WCAG 2.
+func (l *LoxColor) luminance() float64 {
+	linear := func(channel uint8) float64 {
+		c := float64(channel) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(l.r) + 0.7152*linear(l.g) + 0.0722*linear(l.b)
+}
+
+func (l *LoxColor) contrast(other *LoxColor) float64 {
+	l1 := l.luminance()
+	l2 := other.luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+func (l *LoxColor) mix(other *LoxColor, t float64) *LoxColor {
+	t = clampUnit(t)
+	lerp := func(a, b float64) float64 {
+		return a + (b-a)*t
+	}
+	return NewLoxColor(
+		clampColorChannel(lerp(float64(l.r), float64(other.r))),
+		clampColorChannel(lerp(float64(l.g), float64(other.g))),
+		clampColorChannel(lerp(float64(l.b), float64(other.b))),
+		lerp(l.alpha, other.alpha),
+	)
+}
+
+func (l *LoxColor) rotate(degrees float64) *LoxColor {
+	h, s, lightness := l.hsl()
+	return NewLoxColorHSL(h+degrees, s, lightness, l.alpha)
+}
+
+func (l *LoxColor) swatch(text string) string {
+	fg := "38;2;255;255;255"
+	if l.contrast(NewLoxColor(0, 0, 0, 1)) > l.contrast(NewLoxColor(255, 255, 255, 1)) {
+		fg = "38;2;0;0;0"
+	}
+	return fmt.Sprintf("\x1b[48;2;%v;%v;%vm\x1b[%vm%v\x1b[0m", l.r, l.g, l.b, fg, text)
+}
+
+func (l *LoxColor) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxColor:
+		return l.r == obj.r && l.g == obj.g && l.b == obj.b && l.alpha == obj.alpha
+	default:
+		return false
+	}
+}
+
+func colorArgToFloat(arg any) (float64, bool) {
+	switch arg := arg.(type) {
+	case int64:
+		return float64(arg), true
+	case float64:
+		return arg, true
+	}
+	return 0, false
+}
+
+func (l *LoxColor) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	colorFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native color fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'color.%v' must be a %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	floatList := func(values ...float64) *LoxList {
+		valuesList := list.NewListCap[any](int64(len(values)))
+		for _, value := range values {
+			valuesList.Add(value)
+		}
+		return NewLoxList(valuesList)
+	}
+	adjustLightness := func(amount float64) *LoxColor {
+		h, s, lightness := l.hsl()
+		return NewLoxColorHSL(h, s, lightness+amount, l.alpha)
+	}
+	adjustSaturation := func(amount float64) *LoxColor {
+		h, s, lightness := l.hsl()
+		return NewLoxColorHSL(h, s+amount, lightness, l.alpha)
+	}
+	switch methodName {
+	case "alpha":
+		return l.alpha, nil
+	case "b":
+		return int64(l.b), nil
+	case "g":
+		return int64(l.g), nil
+	case "r":
+		return int64(l.r), nil
+	case "complement":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.rotate(180), nil
+		})
+	case "contrast":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if other, ok := args[0].(*LoxColor); ok {
+				return l.contrast(other), nil
+			}
+			return argMustBeType("color")
+		})
+	case "darken":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if amount, ok := colorArgToFloat(args[0]); ok {
+				return adjustLightness(-amount), nil
+			}
+			return argMustBeType("number")
+		})
+	case "desaturate":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if amount, ok := colorArgToFloat(args[0]); ok {
+				return adjustSaturation(-amount), nil
+			}
+			return argMustBeType("number")
+		})
+	case "grayscale":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return adjustSaturation(-1), nil
+		})
+	case "hex":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.hex()), nil
+		})
+	case "hsl":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			h, s, lightness := l.hsl()
+			return floatList(h, s, lightness), nil
+		})
+	case "hsv":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			h, s, v := l.hsv()
+			return floatList(h, s, v), nil
+		})
+	case "invert":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxColor(255-l.r, 255-l.g, 255-l.b, l.alpha), nil
+		})
+	case "isDark":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.luminance() < 0.179, nil
+		})
+	case "isLight":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.luminance() >= 0.179, nil
+		})
+	case "lighten":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if amount, ok := colorArgToFloat(args[0]); ok {
+				return adjustLightness(amount), nil
+			}
+			return argMustBeType("number")
+		})
+	case "luminance":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.luminance(), nil
+		})
+	case "mix":
+		return colorFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			other, ok := args[0].(*LoxColor)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'color.mix' must be a color.")
+			}
+			t := 0.5
+			if argsLen == 2 {
+				t, ok = colorArgToFloat(args[1])
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Second argument to 'color.mix' must be a number.")
+				}
+			}
+			return l.mix(other, t), nil
+		})
+	case "rgb":
+		return colorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			rgbList := list.NewListCap[any](3)
+			rgbList.Add(int64(l.r))
+			rgbList.Add(int64(l.g))
+			rgbList.Add(int64(l.b))
+			return NewLoxList(rgbList), nil
+		})
+	case "rotate":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if degrees, ok := colorArgToFloat(args[0]); ok {
+				return l.rotate(degrees), nil
+			}
+			return argMustBeType("number")
+		})
+	case "saturate":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if amount, ok := colorArgToFloat(args[0]); ok {
+				return adjustSaturation(amount), nil
+			}
+			return argMustBeType("number")
+		})
+	case "swatch":
+		return colorFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			switch argsLen {
+			case 0:
+				return NewLoxStringQuote(l.swatch(" " + l.hex() + " ")), nil
+			case 1:
+				if loxStr, ok := args[0].(*LoxString); ok {
+					return NewLoxStringQuote(l.swatch(loxStr.str)), nil
+				}
+				return argMustBeType("string")
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+		})
+	case "withAlpha":
+		return colorFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if alpha, ok := colorArgToFloat(args[0]); ok {
+				return NewLoxColor(l.r, l.g, l.b, clampUnit(alpha)), nil
+			}
+			return argMustBeType("number")
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Colors have no property called '"+methodName+"'.")
+}
+
+func (l *LoxColor) String() string {
+	return fmt.Sprintf("<color: %v>", l.hex())
+}
+
+func (l *LoxColor) Type() string {
+	return "color"
+}
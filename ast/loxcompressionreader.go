@@ -0,0 +1,178 @@
+package ast
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A reader that decompresses data as it is read, returned from the reader
+// method of a compression format class such as zstd.reader
+type LoxCompressionReader struct {
+	format   string
+	closer   io.Closer
+	reader   *bufio.Reader
+	isClosed bool
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxCompressionReader(format string, reader io.ReadCloser) *LoxCompressionReader {
+	return &LoxCompressionReader{
+		format:   format,
+		closer:   reader,
+		reader:   bufio.NewReader(reader),
+		isClosed: false,
+		methods:  make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxCompressionReader) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	readerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native %v reader fn %v at %p>", l.format, methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	closedErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call '%v reader.%v' on closed %v reader objects.",
+				l.format, methodName, l.format))
+	}
+	switch methodName {
+	case "close":
+		return readerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.isClosed {
+				err := l.closer.Close()
+				if err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+				l.isClosed = true
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return readerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isClosed, nil
+		})
+	case "read":
+		return readerFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			var data []byte
+			var err error
+			argsLen := len(args)
+			switch argsLen {
+			case 0:
+				if l.isClosed {
+					return closedErr()
+				}
+				data, err = io.ReadAll(l.reader)
+			case 1:
+				numBytes, ok := args[0].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("Argument to '%v reader.read' must be an integer.", l.format))
+				}
+				if l.isClosed {
+					return closedErr()
+				}
+				if numBytes < 0 {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("Argument to '%v reader.read' cannot be negative.", l.format))
+				}
+				data = make([]byte, numBytes)
+				var numRead int
+				numRead, err = io.ReadFull(l.reader, data)
+				data = data[:numRead]
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+					err = nil
+				}
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxBufferFromBytes(data), nil
+		})
+	case "readToFile":
+		return readerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch arg := args[0].(type) {
+			case *LoxFile:
+				if l.isClosed {
+					return closedErr()
+				}
+				if !arg.isWrite() && !arg.isAppend() {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("File argument to '%v reader.readToFile' must be in write or append mode.",
+							l.format))
+				}
+				if _, err := io.Copy(arg.file, l.reader); err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+			case *LoxString:
+				if l.isClosed {
+					return closedErr()
+				}
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
+				file, err := os.Create(arg.str)
+				if err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+				_, err = io.Copy(file, l.reader)
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Argument to '%v reader.readToFile' must be a file or string.", l.format))
+			}
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name,
+		fmt.Sprintf("%v readers have no property called '%v'.", l.format, methodName))
+}
+
+func (l *LoxCompressionReader) Iterator() interfaces.Iterator {
+	iterator := ProtoIterator{}
+	next, err := l.reader.ReadByte()
+	iterator.hasNextMethod = func() bool {
+		return err == nil && !l.isClosed
+	}
+	iterator.nextMethod = func() any {
+		current := next
+		next, err = l.reader.ReadByte()
+		return int64(current)
+	}
+	return iterator
+}
+
+func (l *LoxCompressionReader) String() string {
+	return fmt.Sprintf("<%v reader at %p>", l.format, l)
+}
+
+func (l *LoxCompressionReader) Type() string {
+	return l.format + " reader"
+}
@@ -0,0 +1,136 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A writer that compresses data as it is written, returned from the writer
+// method of a compression format class such as zstd.writer
+type LoxCompressionWriter struct {
+	format      string
+	writer      io.WriteCloser
+	bytesBuffer *bytes.Buffer
+	isClosed    bool
+	methods     map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxCompressionWriter(format string, writer io.WriteCloser, bytesBuffer *bytes.Buffer) *LoxCompressionWriter {
+	return &LoxCompressionWriter{
+		format:      format,
+		writer:      writer,
+		bytesBuffer: bytesBuffer,
+		isClosed:    false,
+		methods:     make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxCompressionWriter) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	writerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native %v writer fn %v at %p>", l.format, methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	closedErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call '%v writer.%v' on closed %v writer objects.",
+				l.format, methodName, l.format))
+	}
+	switch methodName {
+	case "buffer":
+		return writerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.bytesBuffer == nil {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v data is not being written to a buffer.", l.format))
+			}
+			return NewLoxBufferFromBytes(l.bytesBuffer.Bytes()), nil
+		})
+	case "close":
+		return writerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.isClosed {
+				err := l.writer.Close()
+				if err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+				l.isClosed = true
+			}
+			return nil, nil
+		})
+	case "flush":
+		return writerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.isClosed {
+				return closedErr()
+			}
+			flusher, ok := l.writer.(interface{ Flush() error })
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("%v writers cannot be flushed before they are closed.", l.format))
+			}
+			if err := flusher.Flush(); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return l, nil
+		})
+	case "isBuffer":
+		return writerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.bytesBuffer != nil, nil
+		})
+	case "isClosed":
+		return writerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isClosed, nil
+		})
+	case "write":
+		return writerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			var source io.Reader
+			switch arg := args[0].(type) {
+			case *LoxBuffer:
+				source = bytes.NewReader(arg.bytes())
+			case *LoxFile:
+				if !arg.isRead() {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("File argument to '%v writer.write' must be in read mode.", l.format))
+				}
+				source = arg.file
+			case *LoxString:
+				source = strings.NewReader(arg.str)
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Argument to '%v writer.write' must be a buffer, file, or string.", l.format))
+			}
+			if l.isClosed {
+				return closedErr()
+			}
+			if _, err := io.Copy(l.writer, source); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return l, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name,
+		fmt.Sprintf("%v writers have no property called '%v'.", l.format, methodName))
+}
+
+func (l *LoxCompressionWriter) String() string {
+	return fmt.Sprintf("<%v writer at %p>", l.format, l)
+}
+
+func (l *LoxCompressionWriter) Type() string {
+	return l.format + " writer"
+}
@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxCronExpression struct {
+	schedule *cronSchedule
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxCronExpression(schedule *cronSchedule) *LoxCronExpression {
+	return &LoxCronExpression{
+		schedule: schedule,
+		methods:  make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxCronExpression) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	cronFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native cron expression fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Returns the optional date argument at the specified index, which
+	//defaults to the current date
+	dateArg := func(in *Interpreter, args list.List[any], index int) (time.Time, error) {
+		if len(args) > index+1 {
+			return time.Time{}, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected %v or %v arguments but got %v.", index, index+1, len(args)))
+		}
+		if len(args) <= index {
+			return time.Now(), nil
+		}
+		if loxDate, ok := args[index].(*LoxDate); ok {
+			return loxDate.date, nil
+		}
+		return time.Time{}, loxerror.RuntimeError(name,
+			fmt.Sprintf("Date argument to 'cron expression.%v' must be a date.", methodName))
+	}
+	switch methodName {
+	case "expression":
+		return NewLoxStringQuote(l.schedule.expression), nil
+	case "hasSeconds":
+		return l.schedule.hasSeconds, nil
+	case "matches":
+		return cronFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if loxDate, ok := args[0].(*LoxDate); ok {
+				return l.schedule.matches(loxDate.date), nil
+			}
+			return nil, loxerror.RuntimeError(name,
+				"Argument to 'cron expression.matches' must be a date.")
+		})
+	case "next", "prev":
+		return cronFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(in, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			var result time.Time
+			var ok bool
+			if methodName == "next" {
+				result, ok = l.schedule.next(date)
+			} else {
+				result, ok = l.schedule.prev(date)
+			}
+			if !ok {
+				return nil, nil
+			}
+			return NewLoxDate(result), nil
+		})
+	case "nextN":
+		return cronFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) == 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Expected 1 or 2 arguments but got 0.")
+			}
+			count, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'cron expression.nextN' must be an integer.")
+			}
+			if count < 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"First argument to 'cron expression.nextN' cannot be negative.")
+			}
+			date, err := dateArg(in, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			dates := list.NewList[any]()
+			for ; count > 0; count-- {
+				date, ok = l.schedule.next(date)
+				if !ok {
+					break
+				}
+				dates.Add(NewLoxDate(date))
+			}
+			return NewLoxList(dates), nil
+		})
+	case "timezone":
+		return cronFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxTimezone(l.schedule.location), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Cron expressions have no property called '"+methodName+"'.")
+}
+
+func (l *LoxCronExpression) String() string {
+	return fmt.Sprintf("<cron expression '%v' at %p>", l.schedule.expression, l)
+}
+
+func (l *LoxCronExpression) Type() string {
+	return "cron expression"
+}
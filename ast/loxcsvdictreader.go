@@ -0,0 +1,153 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A CSV reader returned from csv.dictReader that returns each row as a
+// dictionary whose keys are the field names from the header row
+type LoxCSVDictReader struct {
+	reader     csvRecordReader
+	fieldNames []string
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+// Returns a dictionary reader that uses the specified field names, or the
+// first row of the CSV data as field names if fieldNames is nil
+func NewLoxCSVDictReader(reader io.Reader, dialect csvDialect, fieldNames []string) *LoxCSVDictReader {
+	return &LoxCSVDictReader{
+		reader:     dialect.newReader(reader),
+		fieldNames: fieldNames,
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Reads the header row if the field names are not known yet
+func (l *LoxCSVDictReader) readFieldNames() error {
+	if l.fieldNames != nil {
+		return nil
+	}
+	fieldNames, err := l.reader.Read()
+	if errors.Is(err, io.EOF) {
+		l.fieldNames = []string{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	l.fieldNames = fieldNames
+	return nil
+}
+
+// Reads the next row as a dictionary, returning nil and io.EOF if there
+// are no more rows
+func (l *LoxCSVDictReader) readDict() (*LoxDict, error) {
+	if err := l.readFieldNames(); err != nil {
+		return nil, err
+	}
+	fields, err := l.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > len(l.fieldNames) {
+		return nil, loxerror.Error(fmt.Sprintf(
+			"CSV row has %v values but there are only %v field names.",
+			len(fields), len(l.fieldNames)))
+	}
+	dict := EmptyLoxDict()
+	for index, fieldName := range l.fieldNames {
+		var value any
+		if index < len(fields) {
+			value = NewLoxStringQuote(fields[index])
+		}
+		dict.setKeyValue(NewLoxStringQuote(fieldName), value)
+	}
+	return dict, nil
+}
+
+func (l *LoxCSVDictReader) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	dictReaderFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native csv dict reader fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "fieldNames":
+		return dictReaderFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.readFieldNames(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			fieldNames := list.NewListCap[any](int64(len(l.fieldNames)))
+			for _, fieldName := range l.fieldNames {
+				fieldNames.Add(NewLoxStringQuote(fieldName))
+			}
+			return NewLoxList(fieldNames), nil
+		})
+	case "read":
+		return dictReaderFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			dict, err := l.readDict()
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return dict, nil
+		})
+	case "readAll":
+		return dictReaderFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			rows := list.NewList[any]()
+			for {
+				dict, err := l.readDict()
+				if errors.Is(err, io.EOF) {
+					return NewLoxList(rows), nil
+				}
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				rows.Add(dict)
+			}
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "CSV dict readers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxCSVDictReader) Iterator() interfaces.Iterator {
+	next, err := l.readDict()
+	iterator := ProtoIterator{}
+	iterator.hasNextMethod = func() bool {
+		return err == nil
+	}
+	iterator.nextMethod = func() any {
+		current := next
+		next, err = l.readDict()
+		return current
+	}
+	return iterator
+}
+
+func (l *LoxCSVDictReader) String() string {
+	return fmt.Sprintf("<csv dict reader at %p>", l)
+}
+
+func (l *LoxCSVDictReader) Type() string {
+	return "csv dict reader"
+}
@@ -0,0 +1,152 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A CSV writer returned from csv.dictWriter that writes dictionaries as rows,
+// with the values ordered by the specified field names
+type LoxCSVDictWriter struct {
+	writer     csvRecordWriter
+	fieldNames []string
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxCSVDictWriter(writer io.Writer, dialect csvDialect, fieldNames []string) *LoxCSVDictWriter {
+	return &LoxCSVDictWriter{
+		writer:     dialect.newWriter(writer),
+		fieldNames: fieldNames,
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Converts the specified dictionary into a CSV record, where fields that are
+// missing from the dictionary are written as empty strings
+func (l *LoxCSVDictWriter) record(dict *LoxDict) ([]string, error) {
+	values := make(map[string]any)
+	it := dict.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		key, ok := pair[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.Error("Dictionary keys written by a CSV dict writer must be strings.")
+		}
+		values[key.str] = pair[1]
+	}
+	record := make([]string, len(l.fieldNames))
+	for index, fieldName := range l.fieldNames {
+		if value, ok := values[fieldName]; ok {
+			if value != nil {
+				record[index] = csvFieldStr(value)
+			}
+			delete(values, fieldName)
+		}
+	}
+	for key := range values {
+		return nil, loxerror.Error(
+			fmt.Sprintf("Dictionary key '%v' is not one of the CSV field names.", key))
+	}
+	return record, nil
+}
+
+func (l *LoxCSVDictWriter) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	dictWriterFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native csv dict writer fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'csv dict writer.%v' must be a %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	flush := func(in *Interpreter) (any, error) {
+		l.writer.Flush()
+		if err := l.writer.Error(); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return nil, nil
+	}
+	switch methodName {
+	case "fieldNames":
+		return dictWriterFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			fieldNames := list.NewListCap[any](int64(len(l.fieldNames)))
+			for _, fieldName := range l.fieldNames {
+				fieldNames.Add(NewLoxStringQuote(fieldName))
+			}
+			return NewLoxList(fieldNames), nil
+		})
+	case "flush":
+		return dictWriterFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			return flush(in)
+		})
+	case "writeHeader":
+		return dictWriterFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.writer.Write(l.fieldNames); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return flush(in)
+		})
+	case "writeRow":
+		return dictWriterFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if dict, ok := args[0].(*LoxDict); ok {
+				record, err := l.record(dict)
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				if err := l.writer.Write(record); err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				return flush(in)
+			}
+			return argMustBeType("dictionary")
+		})
+	case "writeRows":
+		return dictWriterFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if loxList, ok := args[0].(*LoxList); ok {
+				records := make([][]string, 0, len(loxList.elements))
+				for _, element := range loxList.elements {
+					dict, ok := element.(*LoxDict)
+					if !ok {
+						return nil, loxerror.RuntimeError(in.callToken,
+							"List argument to 'csv dict writer.writeRows' must only contain dictionaries.")
+					}
+					record, err := l.record(dict)
+					if err != nil {
+						return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					}
+					records = append(records, record)
+				}
+				if err := l.writer.WriteAll(records); err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				return nil, nil
+			}
+			return argMustBeType("list")
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "CSV dict writers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxCSVDictWriter) String() string {
+	return fmt.Sprintf("<csv dict writer at %p>", l)
+}
+
+func (l *LoxCSVDictWriter) Type() string {
+	return "csv dict writer"
+}
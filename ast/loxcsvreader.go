@@ -1,7 +1,6 @@
 package ast
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
@@ -13,7 +12,7 @@ import (
 )
 
 type LoxCSVReaderIterator struct {
-	reader  *csv.Reader
+	reader  csvRecordReader
 	current *LoxList
 	isAtEnd bool
 }
@@ -57,7 +56,7 @@ func (l *LoxCSVReaderIterator) Next() any {
 }
 
 type LoxCSVReader struct {
-	reader  *csv.Reader
+	reader  csvRecordReader
 	methods map[string]*struct{ ProtoLoxCallable }
 }
 
@@ -66,11 +65,14 @@ func NewLoxCSVReader(reader io.Reader) *LoxCSVReader {
 }
 
 func NewLoxCSVReaderDelimiter(reader io.Reader, delimiter rune) *LoxCSVReader {
-	csvReader := csv.NewReader(reader)
-	csvReader.Comma = delimiter
-	csvReader.FieldsPerRecord = -1
+	dialect := defaultCSVDialect
+	dialect.delimiter = delimiter
+	return NewLoxCSVReaderDialect(reader, dialect)
+}
+
+func NewLoxCSVReaderDialect(reader io.Reader, dialect csvDialect) *LoxCSVReader {
 	return &LoxCSVReader{
-		reader:  csvReader,
+		reader:  dialect.newReader(reader),
 		methods: make(map[string]*struct{ ProtoLoxCallable }),
 	}
 }
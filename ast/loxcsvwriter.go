@@ -1,7 +1,6 @@
 package ast
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
 
@@ -9,11 +8,10 @@ import (
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
 	"github.com/AlanLuu/lox/token"
-	"github.com/AlanLuu/lox/util"
 )
 
 type LoxCSVWriter struct {
-	writer  *csv.Writer
+	writer  csvRecordWriter
 	methods map[string]*struct{ ProtoLoxCallable }
 }
 
@@ -22,13 +20,14 @@ func NewLoxCSVWriter(writer io.Writer) *LoxCSVWriter {
 }
 
 func NewLoxCSVWriterDelimiter(writer io.Writer, delimiter rune) *LoxCSVWriter {
-	csvWriter := csv.NewWriter(writer)
-	csvWriter.Comma = delimiter
-	if util.IsWindows() {
-		csvWriter.UseCRLF = true
-	}
+	dialect := defaultCSVDialect
+	dialect.delimiter = delimiter
+	return NewLoxCSVWriterDialect(writer, dialect)
+}
+
+func NewLoxCSVWriterDialect(writer io.Writer, dialect csvDialect) *LoxCSVWriter {
 	return &LoxCSVWriter{
-		writer:  csvWriter,
+		writer:  dialect.newWriter(writer),
 		methods: make(map[string]*struct{ ProtoLoxCallable }),
 	}
 }
@@ -60,16 +59,7 @@ func (l *LoxCSVWriter) Get(name *token.Token) (any, error) {
 			if loxList, ok := args[0].(*LoxList); ok {
 				records := list.NewListCap[string](int64(len(loxList.elements)))
 				for _, element := range loxList.elements {
-					var record string
-					switch element := element.(type) {
-					case *LoxString:
-						record = element.str
-					case fmt.Stringer:
-						record = element.String()
-					default:
-						record = fmt.Sprint(element)
-					}
-					records.Add(record)
+					records.Add(csvFieldStr(element))
 				}
 				err := l.writer.Write(records)
 				if err != nil {
@@ -93,16 +83,7 @@ func (l *LoxCSVWriter) Get(name *token.Token) (any, error) {
 			if loxList, ok := args[0].(*LoxList); ok {
 				record := list.NewListCap[string](int64(len(loxList.elements)))
 				for _, element := range loxList.elements {
-					var value string
-					switch element := element.(type) {
-					case *LoxString:
-						value = element.str
-					case fmt.Stringer:
-						value = element.String()
-					default:
-						value = fmt.Sprint(element)
-					}
-					record.Add(value)
+					record.Add(csvFieldStr(element))
 				}
 				err := l.writer.Write(record)
 				if err != nil {
@@ -127,17 +108,7 @@ func (l *LoxCSVWriter) Get(name *token.Token) (any, error) {
 						record := []string{}
 						it := outer.Iterator()
 						for it.HasNext() {
-							inner := it.Next()
-							var value string
-							switch inner := inner.(type) {
-							case *LoxString:
-								value = inner.str
-							case fmt.Stringer:
-								value = inner.String()
-							default:
-								value = fmt.Sprint(inner)
-							}
-							record = append(record, value)
+							record = append(record, csvFieldStr(it.Next()))
 						}
 						records = append(records, record)
 					default:
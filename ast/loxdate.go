@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AlanLuu/lox/list"
@@ -27,6 +28,41 @@ func (l *LoxDate) defaultFormatStr() string {
 	return l.date.Format(LoxDateDefaultFormat)
 }
 
+// Returns the date formatted as RFC 3339 followed by the name of its time
+// zone in brackets, such as "2024-03-10T03:00:00-04:00[America/New_York]"
+func (l *LoxDate) zonedStr() string {
+	return l.date.Format(time.RFC3339Nano) + "[" + l.date.Location().String() + "]"
+}
+
+// Parses a date in the format returned by zonedStr, where the bracketed
+// time zone name is optional. If the offset in the string doesn't match
+// the offset of the time zone at that date, an error is returned
+func parseZonedDate(dateStr string) (time.Time, error) {
+	zoneName := ""
+	if strings.HasSuffix(dateStr, "]") {
+		index := strings.LastIndex(dateStr, "[")
+		if index < 0 {
+			return time.Time{}, fmt.Errorf("missing '[' in date string '%v'", dateStr)
+		}
+		dateStr, zoneName = dateStr[:index], dateStr[index+1:len(dateStr)-1]
+	}
+	date, err := time.Parse(time.RFC3339Nano, dateStr)
+	if err != nil || zoneName == "" {
+		return date, err
+	}
+	location, err := loadTimezone(zoneName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, offset := date.Zone()
+	date = date.In(location)
+	if _, zoneOffset := date.Zone(); zoneOffset != offset {
+		return time.Time{}, fmt.Errorf(
+			"offset in date string '%v' does not match time zone '%v'", dateStr, zoneName)
+	}
+	return date, nil
+}
+
 func (l *LoxDate) setDay(day int) {
 	l.date = time.Date(
 		l.date.Year(),
@@ -180,6 +216,13 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 	case "format":
 		return dateFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if format, ok := args[0].(*LoxString); ok {
+				if isStrftimeFormat(format.str) {
+					dateStr, err := strftime(l.date, format.str)
+					if err != nil {
+						return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name, err.Error())
+					}
+					return NewLoxStringQuote(dateStr), nil
+				}
 				return NewLoxStringQuote(l.date.Format(format.str)), nil
 			}
 			return argMustBeType("string")
@@ -196,6 +239,14 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxDate(l.date.In(time.UTC)), nil
 		})
+	case "inZone":
+		return dateFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			location, err := timezoneArg(name, "date.inZone", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxDate(l.date.In(location)), nil
+		})
 	case "isAfter":
 		return dateFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if loxDate, ok := args[0].(*LoxDate); ok {
@@ -343,6 +394,22 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 			time.Sleep(time.Until(l.date))
 			return nil, nil
 		})
+	case "startOfDay":
+		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			year, month, day := l.date.Date()
+			return NewLoxDate(time.Date(year, month, day, 0, 0, 0, 0, l.date.Location())), nil
+		})
+	case "strftime":
+		return dateFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if format, ok := args[0].(*LoxString); ok {
+				dateStr, err := strftime(l.date, format.str)
+				if err != nil {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name, err.Error())
+				}
+				return NewLoxStringQuote(dateStr), nil
+			}
+			return argMustBeType("string")
+		})
 	case "string":
 		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxString(l.defaultFormatStr(), '\''), nil
@@ -354,6 +421,10 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeType("date")
 		})
+	case "timezone":
+		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxTimezone(l.date.Location()), nil
+		})
 	case "unix":
 		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return l.date.Unix(), nil
@@ -382,6 +453,23 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxString(l.date.Weekday().String(), '\''), nil
 		})
+	case "withZone":
+		return dateFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			location, err := timezoneArg(name, "date.withZone", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxDate(time.Date(
+				l.date.Year(),
+				l.date.Month(),
+				l.date.Day(),
+				l.date.Hour(),
+				l.date.Minute(),
+				l.date.Second(),
+				l.date.Nanosecond(),
+				location,
+			)), nil
+		})
 	case "year":
 		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return int64(l.date.Year()), nil
@@ -406,6 +494,10 @@ func (l *LoxDate) Get(name *token.Token) (any, error) {
 			zoneBoundsList.Add(NewLoxDate(end))
 			return NewLoxList(zoneBoundsList), nil
 		})
+	case "zonedString":
+		return dateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.zonedStr()), nil
+		})
 	}
 	return nil, loxerror.RuntimeError(name, "Dates have no property called '"+methodName+"'.")
 }
@@ -0,0 +1,437 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type decimalRounding int64
+
+const (
+	decimalRoundHalfEven decimalRounding = iota
+	decimalRoundHalfUp
+	decimalRoundHalfDown
+	decimalRoundUp
+	decimalRoundDown
+	decimalRoundCeiling
+	decimalRoundFloor
+)
+
+var decimalRoundingNames = []string{
+	"halfEven",
+	"halfUp",
+	"halfDown",
+	"up",
+	"down",
+	"ceiling",
+	"floor",
+}
+
+func decimalRoundingFromName(name string) (decimalRounding, bool) {
+	for index, roundingName := range decimalRoundingNames {
+		if roundingName == name {
+			return decimalRounding(index), true
+		}
+	}
+	return 0, false
+}
+
+var decimalPrecision atomic.Int64
+var decimalDefaultRounding atomic.Int64
+
+const decimalDefaultPrecision = 28
+
+func init() {
+	decimalPrecision.Store(decimalDefaultPrecision)
+	decimalDefaultRounding.Store(int64(decimalRoundHalfEven))
+}
+
+var decimalStringRegex = regexp.MustCompile(`^([+-])?(\d*)(?:\.(\d*))?(?:[eE]([+-]?\d+))?$`)
+
+var decimalTen = big.NewInt(10)
+
+func decimalPow10(n int64) *big.Int {
+	return new(big.Int).Exp(decimalTen, big.NewInt(n), nil)
+}
+
+type LoxDecimal struct {
+	coef    *big.Int
+	scale   int64 //The value is coef * 10^-scale, and trailing zeros are kept
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxDecimal(coef *big.Int, scale int64) *LoxDecimal {
+	return &LoxDecimal{
+		coef:    coef,
+		scale:   scale,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func NewLoxDecimalInt(value *big.Int) *LoxDecimal {
+	return NewLoxDecimal(new(big.Int).Set(value), 0)
+}
+
+func parseDecimal(str string) (*LoxDecimal, bool) {
+	match := decimalStringRegex.FindStringSubmatch(strings.TrimSpace(str))
+	if match == nil || match[2]+match[3] == "" {
+		return nil, false
+	}
+	coef, _ := new(big.Int).SetString(match[2]+match[3], 10)
+	scale := int64(len(match[3]))
+	if match[4] != "" {
+		exponent, err := strconv.ParseInt(match[4], 10, 32)
+		if err != nil {
+			return nil, false
+		}
+		scale -= exponent
+	}
+	if scale < 0 {
+		coef.Mul(coef, decimalPow10(-scale))
+		scale = 0
+	}
+	if match[1] == "-" {
+		coef.Neg(coef)
+	}
+	return NewLoxDecimal(coef, scale), true
+}
+
+func decimalFromFloat(f float64) (*LoxDecimal, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return parseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func decimalFromRat(r *big.Rat, places int64, rounding decimalRounding) *LoxDecimal {
+	num := new(big.Int).Mul(r.Num(), decimalPow10(places))
+	quotient, remainder := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		sign := num.Sign()
+		//Compare the discarded fraction with one half
+		half := new(big.Int).Abs(remainder)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(r.Denom())
+		increment := false
+		switch rounding {
+		case decimalRoundHalfEven:
+			increment = cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1)
+		case decimalRoundHalfUp:
+			increment = cmp >= 0
+		case decimalRoundHalfDown:
+			increment = cmp > 0
+		case decimalRoundUp:
+			increment = true
+		case decimalRoundDown:
+			increment = false
+		case decimalRoundCeiling:
+			increment = sign > 0
+		case decimalRoundFloor:
+			increment = sign < 0
+		}
+		if increment {
+			quotient.Add(quotient, big.NewInt(int64(sign)))
+		}
+	}
+	return NewLoxDecimal(quotient, places)
+}
+
+func toDecimal(value any) (*LoxDecimal, bool) {
+	switch value := value.(type) {
+	case int64:
+		return NewLoxDecimal(big.NewInt(value), 0), true
+	case *big.Int:
+		return NewLoxDecimalInt(value), true
+	case float64:
+		return decimalFromFloat(value)
+	case *big.Float:
+		if value.IsInf() {
+			return nil, false
+		}
+		return parseDecimal(value.Text('f', -1))
+	case *LoxDecimal:
+		return value, true
+	}
+	return nil, false
+}
+
+func (l *LoxDecimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(l.coef, decimalPow10(l.scale))
+}
+
+func (l *LoxDecimal) coefAt(scale int64) *big.Int {
+	return new(big.Int).Mul(l.coef, decimalPow10(scale-l.scale))
+}
+
+func (l *LoxDecimal) add(other *LoxDecimal) *LoxDecimal {
+	scale := max(l.scale, other.scale)
+	return NewLoxDecimal(new(big.Int).Add(l.coefAt(scale), other.coefAt(scale)), scale)
+}
+
+func (l *LoxDecimal) sub(other *LoxDecimal) *LoxDecimal {
+	scale := max(l.scale, other.scale)
+	return NewLoxDecimal(new(big.Int).Sub(l.coefAt(scale), other.coefAt(scale)), scale)
+}
+
+func (l *LoxDecimal) mul(other *LoxDecimal) *LoxDecimal {
+	return NewLoxDecimal(new(big.Int).Mul(l.coef, other.coef), l.scale+other.scale)
+}
+
+func (l *LoxDecimal) div(other *LoxDecimal, places int64, rounding decimalRounding) *LoxDecimal {
+	quotient := decimalFromRat(new(big.Rat).Quo(l.rat(), other.rat()), places, rounding)
+	return quotient.trimTo(min(max(l.scale, other.scale), places))
+}
+
+func (l *LoxDecimal) mod(other *LoxDecimal) *LoxDecimal {
+	scale := max(l.scale, other.scale)
+	return NewLoxDecimal(new(big.Int).Rem(l.coefAt(scale), other.coefAt(scale)), scale)
+}
+
+func (l *LoxDecimal) pow(exponent int64) (*LoxDecimal, bool) {
+	if exponent < 0 {
+		if l.coef.Sign() == 0 {
+			return nil, false
+		}
+		result, _ := l.pow(-exponent)
+		one := NewLoxDecimal(big.NewInt(1), 0)
+		return one.div(result, decimalPrecision.Load(), decimalRounding(decimalDefaultRounding.Load())), true
+	}
+	bigExponent := big.NewInt(exponent)
+	return NewLoxDecimal(new(big.Int).Exp(l.coef, bigExponent, nil), l.scale*exponent), true
+}
+
+func (l *LoxDecimal) cmp(other *LoxDecimal) int {
+	scale := max(l.scale, other.scale)
+	return l.coefAt(scale).Cmp(other.coefAt(scale))
+}
+
+func (l *LoxDecimal) round(places int64, rounding decimalRounding) *LoxDecimal {
+	if places >= l.scale {
+		return NewLoxDecimal(l.coefAt(places), places)
+	}
+	return decimalFromRat(l.rat(), places, rounding)
+}
+
+func (l *LoxDecimal) trimTo(minScale int64) *LoxDecimal {
+	coef := new(big.Int).Set(l.coef)
+	scale := l.scale
+	remainder := new(big.Int)
+	for scale > minScale {
+		quotient, rem := new(big.Int).QuoRem(coef, decimalTen, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+		coef = quotient
+		scale--
+	}
+	return NewLoxDecimal(coef, scale)
+}
+
+func (l *LoxDecimal) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxRational:
+		return l.rat().Cmp(obj.rat) == 0
+	default:
+		other, ok := toDecimal(obj)
+		if !ok {
+			return false
+		}
+		return l.cmp(other) == 0
+	}
+}
+
+func (l *LoxDecimal) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	decimalFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native decimal fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'decimal.%v' must be %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	//Returns the decimal places and optional rounding mode arguments
+	placesRoundingArgs := func(in *Interpreter, args list.List[any]) (int64, decimalRounding, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return 0, 0, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		places, ok := args[0].(int64)
+		if !ok {
+			return 0, 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("First argument to 'decimal.%v' must be an integer.", methodName))
+		}
+		if places < 0 {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+				fmt.Sprintf("First argument to 'decimal.%v' cannot be negative.", methodName))
+		}
+		rounding := decimalRounding(decimalDefaultRounding.Load())
+		if argsLen == 2 {
+			var err error
+			rounding, err = decimalRoundingArg(name, "decimal."+methodName, args[1])
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+		return places, rounding, nil
+	}
+	switch methodName {
+	case "abs":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxDecimal(new(big.Int).Abs(l.coef), l.scale), nil
+		})
+	case "divide":
+		return decimalFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen < 2 || argsLen > 3 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+			}
+			divisor, ok := toDecimal(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'decimal.divide' must be an integer, bigint, float, or decimal.")
+			}
+			if divisor.coef.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, name,
+					"Cannot divide decimal by 0.")
+			}
+			places, rounding, err := placesRoundingArgs(in, args[1:])
+			if err != nil {
+				return nil, err
+			}
+			return decimalFromRat(new(big.Rat).Quo(l.rat(), divisor.rat()), places, rounding), nil
+		})
+	case "isInteger":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return new(big.Int).Rem(l.coef, decimalPow10(l.scale)).Sign() == 0, nil
+		})
+	case "isZero":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.coef.Sign() == 0, nil
+		})
+	case "normalize":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.trimTo(0), nil
+		})
+	case "round":
+		return decimalFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			places, rounding, err := placesRoundingArgs(in, args)
+			if err != nil {
+				return nil, err
+			}
+			return l.round(places, rounding), nil
+		})
+	case "scale":
+		return l.scale, nil
+	case "sign":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(l.coef.Sign()), nil
+		})
+	case "split":
+		return decimalFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			parts, ok := args[0].(int64)
+			if !ok {
+				return argMustBeType("an integer")
+			}
+			if parts < 1 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Argument to 'decimal.split' must be at least 1.")
+			}
+			//Spread the remainder one unit in the last place at a time over
+			//the first parts so that the parts add up to this decimal exactly
+			quotient, remainder := new(big.Int).QuoRem(l.coef, big.NewInt(parts), new(big.Int))
+			unit := big.NewInt(int64(remainder.Sign()))
+			extra := new(big.Int).Abs(remainder).Int64()
+			result := list.NewListCap[any](parts)
+			for index := int64(0); index < parts; index++ {
+				coef := new(big.Int).Set(quotient)
+				if index < extra {
+					coef.Add(coef, unit)
+				}
+				result.Add(NewLoxDecimal(coef, l.scale))
+			}
+			return NewLoxList(result), nil
+		})
+	case "toFixed":
+		return decimalFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			places, rounding, err := placesRoundingArgs(in, args)
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxStringQuote(l.round(places, rounding).String()), nil
+		})
+	case "toFloat":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			f, _ := strconv.ParseFloat(l.String(), 64)
+			return f, nil
+		})
+	case "toInt":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			//Truncates towards zero
+			return bigIntOrInt(new(big.Int).Quo(l.coef, decimalPow10(l.scale))), nil
+		})
+	case "toRational":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxRational(l.rat()), nil
+		})
+	case "toString":
+		return decimalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.String()), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Decimals have no property called '"+methodName+"'.")
+}
+
+func (l *LoxDecimal) String() string {
+	digits := new(big.Int).Abs(l.coef).String()
+	sign := ""
+	if l.coef.Sign() < 0 {
+		sign = "-"
+	}
+	if l.scale == 0 {
+		return sign + digits
+	}
+	if int64(len(digits)) <= l.scale {
+		digits = strings.Repeat("0", int(l.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(l.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (l *LoxDecimal) Type() string {
+	return "decimal"
+}
+
+func decimalRoundingArg(callToken *token.Token, fnName string, value any) (decimalRounding, error) {
+	if loxStr, ok := value.(*LoxString); ok {
+		if rounding, ok := decimalRoundingFromName(loxStr.str); ok {
+			return rounding, nil
+		}
+		return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+			fmt.Sprintf("Unknown rounding mode '%v' in '%v'.", loxStr.str, fnName))
+	}
+	return 0, loxerror.RuntimeError(callToken,
+		fmt.Sprintf("Rounding mode argument to '%v' must be a string.", fnName))
+}
@@ -3,7 +3,6 @@ package ast
 import (
 	"fmt"
 	"math/big"
-	"reflect"
 
 	"github.com/AlanLuu/lox/bignum/bigfloat"
 	"github.com/AlanLuu/lox/bignum/bigint"
@@ -15,9 +14,8 @@ import (
 )
 
 func CanBeDictKeyCheck(key any) (bool, string) {
-	switch key := key.(type) {
-	case *LoxBuffer, *LoxDeque, *LoxDict, *LoxList, *LoxQueue, *LoxSet:
-		return false, fmt.Sprintf("Type '%v' cannot be used as dictionary key.", getType(key))
+	if unhashable, ok := unhashableValue(key); ok {
+		return false, unhashableValueMsg(key, unhashable, "dictionary key")
 	}
 	return true, ""
 }
@@ -36,9 +34,28 @@ func UnknownDictKey(key any) string {
 	}
 }
 
+// A key-value pair in a dictionary, where the key is stored in the same
+// form that is used to look it up in the dictionary
+type loxDictEntry struct {
+	key     any
+	value   any
+	removed bool
+}
+
+// Dictionaries remember the order that their keys were first inserted in,
+// so that iterating over and printing a dictionary is deterministic.
+// Removed keys are marked as removed in the list of entries instead of
+// being deleted from it right away, and the list is compacted the next
+// time that it's iterated over. Frozen dictionaries can't be modified, so
+// they can be used as dictionary keys and set elements. The key field
+// caches the key of a frozen dictionary
 type LoxDict struct {
-	entries map[any]any
-	methods map[string]*struct{ ProtoLoxCallable }
+	indexes    map[any]int
+	entryList  []loxDictEntry
+	numRemoved int
+	frozen     bool
+	key        any
+	methods    map[string]*struct{ ProtoLoxCallable }
 }
 
 type LoxDictIterator struct {
@@ -57,23 +74,50 @@ func (l *LoxDictIterator) Next() any {
 }
 
 func NewLoxDict(entries map[any]any) *LoxDict {
-	return &LoxDict{
-		entries: entries,
-		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	dict := &LoxDict{
+		indexes:   make(map[any]int, len(entries)),
+		entryList: make([]loxDictEntry, 0, len(entries)),
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	for key, value := range entries {
+		dict.setKeyValue(key, value)
+	}
+	return dict
+}
+
+// Creates a dictionary whose entries are in the order of the specified keys,
+// which NewLoxDict can't do since Go maps have no order
+func NewLoxDictOrdered(keys []any, values []any) *LoxDict {
+	dict := &LoxDict{
+		indexes:   make(map[any]int, len(keys)),
+		entryList: make([]loxDictEntry, 0, len(keys)),
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	for index, key := range keys {
+		dict.setKeyValue(key, values[index])
 	}
+	return dict
 }
 
 func EmptyLoxDict() *LoxDict {
 	return NewLoxDict(make(map[any]any))
 }
 
-func (l *LoxDict) Equals(obj any) bool {
-	switch obj := obj.(type) {
-	case *LoxDict:
-		return reflect.DeepEqual(l.entries, obj.entries)
-	default:
+func (l *LoxDict) equals(other *LoxDict, compared map[valuesEqualPair]bool) bool {
+	if len(l.indexes) != len(other.indexes) {
 		return false
 	}
+	for _, entry := range l.entries() {
+		otherValue, ok := other.getValueByKey(entry.key)
+		if !ok || !valuesEqualCompared(entry.value, otherValue, compared) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LoxDict) Equals(obj any) bool {
+	return valuesEqual(l, obj)
 }
 
 func (l *LoxDict) Get(name *token.Token) (any, error) {
@@ -93,12 +137,16 @@ func (l *LoxDict) Get(name *token.Token) (any, error) {
 		}
 		return s, nil
 	}
+	if l.frozen {
+		switch methodName {
+		case "clear", "removeKey":
+			return nil, loxerror.RuntimeError(name, frozenMethodMsg("dictionary", methodName))
+		}
+	}
 	switch methodName {
 	case "clear":
 		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
-			for key := range l.entries {
-				delete(l.entries, key)
-			}
+			l.clear()
 			return nil, nil
 		})
 	case "containsKey":
@@ -109,11 +157,15 @@ func (l *LoxDict) Get(name *token.Token) (any, error) {
 	case "copy":
 		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			newDict := NewLoxDict(make(map[any]any))
-			for key, value := range l.entries {
-				newDict.setKeyValue(key, value)
+			for _, entry := range l.entries() {
+				newDict.setKeyValue(entry.key, entry.value)
 			}
 			return newDict, nil
 		})
+	case "freeze":
+		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.freeze(), nil
+		})
 	case "get":
 		return dictFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			argsLen := len(args)
@@ -135,7 +187,11 @@ func (l *LoxDict) Get(name *token.Token) (any, error) {
 		})
 	case "isEmpty":
 		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
-			return len(l.entries) == 0, nil
+			return len(l.indexes) == 0, nil
+		})
+	case "isFrozen":
+		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.frozen, nil
 		})
 	case "keys":
 		return dictFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
@@ -165,90 +221,120 @@ func (l *LoxDict) Get(name *token.Token) (any, error) {
 	return nil, loxerror.RuntimeError(name, "Dictionaries have no property called '"+methodName+"'.")
 }
 
-func (l *LoxDict) getValueByKey(key any) (any, bool) {
-	var value any
-	var ok bool
+// Returns the form of the specified key that is used to look it up in
+// the dictionary
+func dictKey(key any) any {
 	switch key := key.(type) {
 	case *big.Int:
-		value, ok = l.entries[NewLoxBigIntKey(key)]
+		return NewLoxBigIntKey(key)
 	case *big.Float:
-		value, ok = l.entries[NewLoxBigFloatKey(key)]
+		return NewLoxBigFloatKey(key)
+	case *LoxDecimal:
+		//Trailing zeros are removed so that 1.5 and 1.50 are the same key
+		trimmed := key.trimTo(0)
+		return newLoxFrozenKey(frozenDecimalKind, []any{trimmed.coef.String(), trimmed.scale})
+	case *LoxDict:
+		return key.dictKey()
+	case *LoxList:
+		return key.dictKey()
+	case *LoxRational:
+		return newLoxFrozenKey(frozenRationalKind, []any{key.rat.String()})
+	case *LoxRecord:
+		return key.dictKey()
+	case *LoxSet:
+		return key.dictKey()
 	case *LoxString:
-		value, ok = l.entries[LoxStringStr{key.str, key.quote}]
+		return key.dictKey()
 	case *LoxRange:
-		value, ok = l.entries[LoxRangeDictSetKey{key.start, key.stop, key.step}]
-	default:
-		value, ok = l.entries[key]
+		return LoxRangeDictSetKey{key.start, key.stop, key.step}
+	}
+	return key
+}
+
+func (l *LoxDict) clear() {
+	clear(l.indexes)
+	l.entryList = l.entryList[:0]
+	l.numRemoved = 0
+}
+
+func (l *LoxDict) compact() {
+	entryList := make([]loxDictEntry, 0, len(l.indexes))
+	for _, entry := range l.entryList {
+		if !entry.removed {
+			l.indexes[entry.key] = len(entryList)
+			entryList = append(entryList, entry)
+		}
+	}
+	l.entryList = entryList
+	l.numRemoved = 0
+}
+
+// Returns the entries of the dictionary in insertion order
+func (l *LoxDict) entries() []loxDictEntry {
+	if l.numRemoved == 0 {
+		return l.entryList
+	}
+	entryList := make([]loxDictEntry, 0, len(l.indexes))
+	for _, entry := range l.entryList {
+		if !entry.removed {
+			entryList = append(entryList, entry)
+		}
 	}
-	return value, ok
+	return entryList
+}
+
+func (l *LoxDict) getValueByKey(key any) (any, bool) {
+	index, ok := l.indexes[dictKey(key)]
+	if !ok {
+		return nil, false
+	}
+	return l.entryList[index].value, true
 }
 
 func (l *LoxDict) setKeyValue(key any, value any) {
-	switch key := key.(type) {
-	case *big.Int:
-		l.entries[NewLoxBigIntKey(key)] = value
-	case *big.Float:
-		l.entries[NewLoxBigFloatKey(key)] = value
-	case *LoxString:
-		l.entries[LoxStringStr{key.str, key.quote}] = value
-	case *LoxRange:
-		l.entries[LoxRangeDictSetKey{key.start, key.stop, key.step}] = value
-	default:
-		l.entries[key] = value
+	key = dictKey(key)
+	if index, ok := l.indexes[key]; ok {
+		l.entryList[index].value = value
+		return
 	}
+	l.indexes[key] = len(l.entryList)
+	l.entryList = append(l.entryList, loxDictEntry{key: key, value: value})
 }
 
 func (l *LoxDict) removeKey(key any) any {
-	keyItem := key
-	switch key := key.(type) {
-	case *big.Int:
-		keyItem = NewLoxBigIntKey(key)
-	case *big.Float:
-		keyItem = NewLoxBigFloatKey(key)
-	case *LoxString:
-		keyItem = LoxStringStr{key.str, key.quote}
-	case *LoxRange:
-		keyItem = LoxRangeDictSetKey{key.start, key.stop, key.step}
-	}
-	value, ok := l.entries[keyItem]
+	key = dictKey(key)
+	index, ok := l.indexes[key]
 	if !ok {
 		return nil
 	}
-	delete(l.entries, keyItem)
+	value := l.entryList[index].value
+	delete(l.indexes, key)
+	if len(l.indexes) == 0 {
+		l.clear()
+		return value
+	}
+	l.entryList[index] = loxDictEntry{removed: true}
+	l.numRemoved++
+	if l.numRemoved > len(l.indexes) {
+		l.compact()
+	}
 	return value
 }
 
 func (l *LoxDict) Iterator() interfaces.Iterator {
-	pairs := list.NewListCap[*LoxList](int64(len(l.entries)))
-	for key, value := range l.entries {
+	entries := l.entries()
+	pairs := list.NewListCap[*LoxList](int64(len(entries)))
+	for _, entry := range entries {
 		pair := list.NewListCap[any](2)
-		switch key := key.(type) {
-		case LoxBigNumKey:
-			pair.Add(key.getBigNum())
-		case LoxStringStr:
-			pair.Add(NewLoxString(key.str, key.quote))
-		case LoxRangeDictSetKey:
-			pair.Add(NewLoxRange(key.start, key.stop, key.step))
-		default:
-			pair.Add(key)
-		}
-		switch value := value.(type) {
-		case LoxBigNumKey:
-			pair.Add(value.getBigNum())
-		case LoxStringStr:
-			pair.Add(NewLoxString(value.str, value.quote))
-		case LoxRangeDictSetKey:
-			pair.Add(NewLoxRange(value.start, value.stop, value.step))
-		default:
-			pair.Add(value)
-		}
+		pair.Add(dictKeyValue(entry.key))
+		pair.Add(dictKeyValue(entry.value))
 		pairs.Add(NewLoxList(pair))
 	}
 	return &LoxDictIterator{pairs, 0}
 }
 
 func (l *LoxDict) Length() int64 {
-	return int64(len(l.entries))
+	return int64(len(l.indexes))
 }
 
 func (l *LoxDict) String() string {
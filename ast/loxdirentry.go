@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxDirEntry struct {
+	entry      fs.DirEntry
+	fullPath   string
+	info       fs.FileInfo
+	properties map[string]any
+}
+
+func NewLoxDirEntry(entry fs.DirEntry, dir string) *LoxDirEntry {
+	return &LoxDirEntry{
+		entry:      entry,
+		fullPath:   filepath.Join(dir, entry.Name()),
+		properties: make(map[string]any),
+	}
+}
+
+// Returns information about the entry, which is only retrieved once since
+// it requires a call to lstat on most operating systems
+func (l *LoxDirEntry) getInfo() (fs.FileInfo, error) {
+	if l.info == nil {
+		info, err := l.entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		l.info = info
+	}
+	return l.info, nil
+}
+
+func (l *LoxDirEntry) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if property, ok := l.properties[lexemeName]; ok {
+		return property, nil
+	}
+	dirEntryField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	dirEntryFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native dir entry fn %v at %p>", lexemeName, s)
+		}
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = s
+		}
+		return s, nil
+	}
+	infoFunc := func(method func(fs.FileInfo) any) (*struct{ ProtoLoxCallable }, error) {
+		return dirEntryFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			info, err := l.getInfo()
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return method(info), nil
+		})
+	}
+	switch lexemeName {
+	case "fullPath":
+		return dirEntryField(NewLoxStringQuote(l.fullPath))
+	case "isDir":
+		return dirEntryFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.entry.IsDir(), nil
+		})
+	case "isFile":
+		return dirEntryFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.entry.Type().IsRegular(), nil
+		})
+	case "isSymlink":
+		return dirEntryFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.entry.Type()&fs.ModeSymlink != 0, nil
+		})
+	case "mode":
+		return infoFunc(func(info fs.FileInfo) any {
+			return int64(info.Mode().Perm())
+		})
+	case "modTime":
+		return infoFunc(func(info fs.FileInfo) any {
+			return NewLoxDate(info.ModTime())
+		})
+	case "name":
+		return dirEntryField(NewLoxStringQuote(l.entry.Name()))
+	case "size":
+		return infoFunc(func(info fs.FileInfo) any {
+			return info.Size()
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Dir entries have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxDirEntry) String() string {
+	return fmt.Sprintf("<dir entry: %v at %p>", l.fullPath, l)
+}
+
+func (l *LoxDirEntry) Type() string {
+	return "dir entry"
+}
+
+// Returns an iterator that lazily reads the entries of the specified open
+// directory in batches, closing the directory once all entries are read
+func dirEntryIterator(dir *os.File, dirPath string) interfaces.Iterator {
+	const batchSize = 256
+	var entries []fs.DirEntry
+	readBatch := func() {
+		if dir == nil {
+			return
+		}
+		batch, err := dir.ReadDir(batchSize)
+		entries = append(entries, batch...)
+		if err != nil {
+			dir.Close()
+			dir = nil
+		}
+	}
+	return ProtoIterator{
+		hasNextMethod: func() bool {
+			if len(entries) == 0 {
+				readBatch()
+			}
+			return len(entries) > 0
+		},
+		nextMethod: func() any {
+			if len(entries) == 0 {
+				readBatch()
+			}
+			entry := entries[0]
+			entries = entries[1:]
+			return NewLoxDirEntry(entry, dirPath)
+		},
+	}
+}
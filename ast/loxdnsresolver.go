@@ -0,0 +1,72 @@
+package ast
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The timeout of DNS resolvers created by dns.resolver when no timeout is
+// specified
+const dnsDefaultTimeout = 5 * time.Second
+
+// A DNS resolver returned from dns.resolver that sends queries to a
+// specific DNS server
+type LoxDNSResolver struct {
+	address  string
+	timeout  time.Duration
+	resolver *net.Resolver
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxDNSResolver(address string, timeout time.Duration) *LoxDNSResolver {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	return &LoxDNSResolver{
+		address: address,
+		timeout: timeout,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				dialer := net.Dialer{Timeout: timeout}
+				return dialer.DialContext(ctx, network, address)
+			},
+		},
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxDNSResolver) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	switch methodName {
+	case "address":
+		return NewLoxStringQuote(l.address), nil
+	case "timeout":
+		return l.timeout.Seconds(), nil
+	}
+	if _, ok := dnsLookups[methodName]; ok {
+		method := dnsLookupFunc(methodName, "dns resolver", l.resolver, l.timeout,
+			func(_ *Interpreter) *token.Token {
+				return name
+			})
+		l.methods[methodName] = method
+		return method, nil
+	}
+	return nil, loxerror.RuntimeError(name, "DNS resolvers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxDNSResolver) String() string {
+	return fmt.Sprintf("<dns resolver %v at %p>", l.address, l)
+}
+
+func (l *LoxDNSResolver) Type() string {
+	return "dns resolver"
+}
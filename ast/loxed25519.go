@@ -2,6 +2,7 @@ package ast
 
 import (
 	"crypto/ed25519"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
@@ -231,6 +232,18 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeTypeAn("ed25519 keypair")
 		})
+	case "privKeyPEM":
+		return ed25519Func(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.isKeyPair() {
+				return nil, loxerror.RuntimeError(name,
+					"Can only call 'ed25519.privKeyPEM' on ed25519 keypairs.")
+			}
+			privKey, err := x509.MarshalPKCS8PrivateKey(l.privKey)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxStringQuote(encodePEM("PRIVATE KEY", privKey)), nil
+		})
 	case "privKeyStr":
 		return ed25519Func(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			if !l.isKeyPair() {
@@ -257,6 +270,14 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeTypeAn("ed25519 keypair or public key")
 		})
+	case "pubKeyPEM":
+		return ed25519Func(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			pubKey, err := x509.MarshalPKIXPublicKey(l.pubKey)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxStringQuote(encodePEM("PUBLIC KEY", pubKey)), nil
+		})
 	case "pubKeyStr":
 		return ed25519Func(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxStringQuote(LoxEd25519Encode(l.pubKey)), nil
@@ -401,6 +422,9 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 					Bytes: MarshalED25519PrivateKey(l.privKey, ""),
 				}
 				sshPrivKey := pem.EncodeToMemory(pemKey)
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				writeErr := os.WriteFile(filepath.Join(path, "id_ed25519"), sshPrivKey, 0600)
 				if writeErr != nil {
 					return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -411,6 +435,9 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 				return nil, loxerror.RuntimeError(name, sshPubKeyErr.Error())
 			}
 			authorizedKey := ssh.MarshalAuthorizedKey(sshPubKey)
+			if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
 			writeErr := os.WriteFile(filepath.Join(path, "id_ed25519.pub"), authorizedKey, 0644)
 			if writeErr != nil {
 				return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -462,6 +489,9 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 					Bytes: MarshalED25519PrivateKey(l.privKey, comment),
 				}
 				sshPrivKey := pem.EncodeToMemory(pemKey)
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				writeErr := os.WriteFile(filepath.Join(path, "id_ed25519"), sshPrivKey, 0600)
 				if writeErr != nil {
 					return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -472,6 +502,9 @@ func (l *LoxEd25519) Get(name *token.Token) (any, error) {
 				return nil, loxerror.RuntimeError(name, sshPubKeyErr.Error())
 			}
 			authorizedKey := ssh.MarshalAuthorizedKey(sshPubKey)
+			if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
 			writeErr := os.WriteFile(filepath.Join(path, "id_ed25519.pub"), authorizedKey, 0644)
 			if writeErr != nil {
 				return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -192,6 +192,9 @@ func (l *LoxFernet) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, decryptedBytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
@@ -328,6 +331,9 @@ func (l *LoxFernet) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, encryptedBytes, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
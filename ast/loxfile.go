@@ -216,7 +216,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 		return fileFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			err := l.file.Chdir()
 			if err != nil {
-				return nil, loxerror.RuntimeError(name, err.Error())
+				return nil, loxerror.RuntimeErrorIO(name, err)
 			}
 			return nil, nil
 		})
@@ -225,7 +225,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			if mode, ok := args[0].(int64); ok {
 				err := l.file.Chmod(os.FileMode(mode))
 				if err != nil {
-					return nil, loxerror.RuntimeError(name, err.Error())
+					return nil, loxerror.RuntimeErrorIO(name, err)
 				}
 				return nil, nil
 			}
@@ -244,7 +244,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 		return fileFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			flushErr := l.file.Sync()
 			if flushErr != nil {
-				return nil, loxerror.RuntimeError(name, flushErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, flushErr)
 			}
 			return nil, nil
 		})
@@ -266,7 +266,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			stat, statErr := l.file.Stat()
 			if statErr != nil {
 				if l.stat == nil {
-					return nil, loxerror.RuntimeError(name, statErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, statErr)
 				}
 			} else {
 				l.stat = stat
@@ -317,7 +317,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				case argsLen == 1 && (errors.Is(bufferErr, io.ErrUnexpectedEOF) ||
 					errors.Is(bufferErr, io.EOF)):
 				default:
-					return nil, loxerror.RuntimeError(name, bufferErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, bufferErr)
 				}
 			}
 			if l.isBinary {
@@ -326,7 +326,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 					for _, element := range buffer {
 						addErr := loxBuffer.add(int64(element))
 						if addErr != nil {
-							return nil, loxerror.RuntimeError(name, addErr.Error())
+							return nil, loxerror.RuntimeErrorIO(name, addErr)
 						}
 					}
 					return loxBuffer, nil
@@ -335,7 +335,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 					for i := 0; i < bufferSize; i++ {
 						addErr := loxBuffer.add(int64(buffer[i]))
 						if addErr != nil {
-							return nil, loxerror.RuntimeError(name, addErr.Error())
+							return nil, loxerror.RuntimeErrorIO(name, addErr)
 						}
 					}
 					return loxBuffer, nil
@@ -362,7 +362,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				if errors.Is(readErr, io.EOF) {
 					return nil, nil
 				}
-				return nil, loxerror.RuntimeError(name, readErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, readErr)
 			}
 			return int64(b[0]), nil
 		})
@@ -386,7 +386,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 					if errors.Is(readErr, io.EOF) {
 						return nil, nil
 					}
-					return nil, loxerror.RuntimeError(name, readErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, readErr)
 				}
 				if r, _ := utf8.DecodeRune(b[:i+1]); r != utf8.RuneError {
 					if r == '\'' {
@@ -416,7 +416,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			}
 			fileNames, err := l.file.Readdirnames(n)
 			if err != nil && !errors.Is(err, io.EOF) {
-				return nil, loxerror.RuntimeError(name, err.Error())
+				return nil, loxerror.RuntimeErrorIO(name, err)
 			}
 			fileNamesList := list.NewListCap[any](int64(len(fileNames)))
 			for _, fileName := range fileNames {
@@ -472,7 +472,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				}
 			}
 			if readErr != nil && !errors.Is(readErr, io.EOF) {
-				return nil, loxerror.RuntimeError(name, readErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, readErr)
 			}
 			return NewLoxString(builder.String(), quote), nil
 		})
@@ -546,7 +546,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			}
 			if readErr != nil && !errors.Is(readErr, io.EOF) {
 				lines.Clear()
-				return nil, loxerror.RuntimeError(name, readErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, readErr)
 			}
 			return NewLoxList(lines), nil
 		})
@@ -575,7 +575,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				_, readErr = l.file.Read(b)
 			}
 			if readErr != nil && !errors.Is(readErr, io.EOF) {
-				return nil, loxerror.RuntimeError(name, readErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, readErr)
 			}
 			if b[0] == '\n' {
 				builder.WriteByte('\n')
@@ -637,7 +637,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			}
 			if readErr != nil && !errors.Is(readErr, io.EOF) {
 				lines.Clear()
-				return nil, loxerror.RuntimeError(name, readErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, readErr)
 			}
 			return NewLoxList(lines), nil
 		})
@@ -663,7 +663,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 			}
 			position, seekErr := l.file.Seek(offset, whence)
 			if seekErr != nil {
-				return nil, loxerror.RuntimeError(name, seekErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, seekErr)
 			}
 			return position, nil
 		})
@@ -692,7 +692,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 		stat, statErr := l.file.Stat()
 		if statErr != nil {
 			if l.stat == nil {
-				return nil, loxerror.RuntimeError(name, statErr.Error())
+				return nil, loxerror.RuntimeErrorIO(name, statErr)
 			}
 		} else {
 			l.stat = stat
@@ -707,7 +707,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				}
 				err := l.file.Truncate(size)
 				if err != nil {
-					return nil, loxerror.RuntimeError(name, err.Error())
+					return nil, loxerror.RuntimeErrorIO(name, err)
 				}
 				return nil, nil
 			}
@@ -733,7 +733,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				}
 				numBytes, writeErr := l.file.Write([]byte(byteList))
 				if writeErr != nil {
-					return nil, loxerror.RuntimeError(name, writeErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, writeErr)
 				}
 				return int64(numBytes), nil
 			case *LoxString:
@@ -742,7 +742,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				}
 				numBytes, writeErr := l.file.WriteString(arg.str)
 				if writeErr != nil {
-					return nil, loxerror.RuntimeError(name, writeErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, writeErr)
 				}
 				return int64(numBytes), nil
 			default:
@@ -774,7 +774,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 				b[0] = byte(value)
 				_, writeErr := l.file.Write([]byte(b))
 				if writeErr != nil {
-					return nil, loxerror.RuntimeError(name, writeErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, writeErr)
 				}
 				return nil, nil
 			}
@@ -802,7 +802,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 					numBytes, writeErr = l.file.WriteString(loxStr.str + "\n")
 				}
 				if writeErr != nil {
-					return nil, loxerror.RuntimeError(name, writeErr.Error())
+					return nil, loxerror.RuntimeErrorIO(name, writeErr)
 				}
 				return int64(numBytes), nil
 			}
@@ -835,7 +835,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 					}
 					bytes, writeErr := l.file.WriteString(strToWrite)
 					if writeErr != nil {
-						return nil, loxerror.RuntimeError(name, writeErr.Error())
+						return nil, loxerror.RuntimeErrorIO(name, writeErr)
 					}
 					numBytes += bytes
 				}
@@ -876,7 +876,7 @@ func (l *LoxFile) Get(name *token.Token) (any, error) {
 						bytes, writeErr = l.file.WriteString(strToWrite + "\n")
 					}
 					if writeErr != nil {
-						return nil, loxerror.RuntimeError(name, writeErr.Error())
+						return nil, loxerror.RuntimeErrorIO(name, writeErr)
 					}
 					numBytes += bytes
 				}
@@ -0,0 +1,324 @@
+package ast
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type loxFrozenKind byte
+
+const (
+	frozenListKind loxFrozenKind = iota
+	frozenDictKind
+	frozenSetKind
+	frozenRationalKind
+	frozenDecimalKind
+	frozenRecordKind
+)
+
+// The form of a frozen list, dictionary, or set, or of a rational, decimal,
+// or record, that is used to look it up in a dictionary or set. The elements field holds an array of the keys
+// of the container's elements, which is comparable, so that frozen
+// containers with equal elements have equal keys. The elements of frozen
+// dictionaries and sets are sorted so that their order doesn't matter, and
+// each key of a frozen dictionary is followed by its value in the array
+type LoxFrozenKey struct {
+	kind     loxFrozenKind
+	elements any
+}
+
+func newLoxFrozenKey(kind loxFrozenKind, keys []any) LoxFrozenKey {
+	array := reflect.New(reflect.ArrayOf(len(keys), reflect.TypeFor[any]())).Elem()
+	for index, key := range keys {
+		if key != nil {
+			array.Index(index).Set(reflect.ValueOf(key))
+		}
+	}
+	return LoxFrozenKey{kind, array.Interface()}
+}
+
+func (k LoxFrozenKey) keys() []any {
+	array := reflect.ValueOf(k.elements)
+	keys := make([]any, array.Len())
+	for index := range keys {
+		keys[index] = array.Index(index).Interface()
+	}
+	return keys
+}
+
+// Returns a new frozen container that is equal to the container that
+// this key was created from
+func (k LoxFrozenKey) value() any {
+	keys := k.keys()
+	switch k.kind {
+	case frozenDictKind:
+		dict := EmptyLoxDict()
+		for index := 0; index < len(keys); index += 2 {
+			dict.setKeyValue(keys[index], dictKeyValue(keys[index+1]))
+		}
+		dict.frozen = true
+		dict.key = k
+		return dict
+	case frozenDecimalKind:
+		coef, _ := new(big.Int).SetString(keys[0].(string), 10)
+		return NewLoxDecimal(coef, keys[1].(int64))
+	case frozenRationalKind:
+		rat, _ := new(big.Rat).SetString(keys[0].(string))
+		return NewLoxRational(rat)
+	case frozenRecordKind:
+		//The first key is the record type
+		values := make([]any, len(keys)-1)
+		for index, key := range keys[1:] {
+			values[index] = dictKeyValue(key)
+		}
+		return NewLoxRecord(keys[0].(*LoxRecordType), values)
+	case frozenSetKind:
+		set := EmptyLoxSet()
+		for _, key := range keys {
+			set.elements[key] = true
+		}
+		set.frozen = true
+		set.key = k
+		return set
+	default:
+		elements := make([]any, len(keys))
+		for index, key := range keys {
+			elements[index] = dictKeyValue(key)
+		}
+		frozenList := NewLoxList(elements)
+		frozenList.frozen = true
+		frozenList.key = k
+		return frozenList
+	}
+}
+
+// Returns the value that the specified dictionary key or set element was
+// created from, which is the key itself for most values
+func dictKeyValue(key any) any {
+	switch key := key.(type) {
+	case LoxBigNumKey:
+		return key.getBigNum()
+	case LoxStringStr:
+		return NewLoxString(key.str, key.quote)
+	case LoxRangeDictSetKey:
+		return NewLoxRange(key.start, key.stop, key.step)
+	case LoxFrozenKey:
+		return key.value()
+	}
+	return key
+}
+
+// Compares two dictionary keys in an order that only depends on their
+// values, which is used to sort the elements of frozen dictionaries and
+// sets before creating their keys. Keys that are compared by identity,
+// such as instances, are ordered by their addresses
+func compareDictKeys(a any, b any) int {
+	if result := strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)); result != 0 {
+		return result
+	}
+	switch a := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		b := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case LoxBigNumKey:
+		b := b.(LoxBigNumKey)
+		if a.isFloat != b.isFloat {
+			if a.isFloat {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.str, b.str)
+	case LoxFrozenKey:
+		b := b.(LoxFrozenKey)
+		if a.kind != b.kind {
+			return cmp.Compare(a.kind, b.kind)
+		}
+		return slices.CompareFunc(a.keys(), b.keys(), compareDictKeys)
+	case LoxRangeDictSetKey:
+		b := b.(LoxRangeDictSetKey)
+		return cmp.Or(
+			cmp.Compare(a.start, b.start),
+			cmp.Compare(a.stop, b.stop),
+			cmp.Compare(a.step, b.step),
+		)
+	case LoxStringStr:
+		b := b.(LoxStringStr)
+		return cmp.Or(
+			strings.Compare(a.str, b.str),
+			cmp.Compare(a.quote, b.quote),
+		)
+	}
+	first, second := reflect.ValueOf(a), reflect.ValueOf(b)
+	if first.Kind() == reflect.Pointer && second.Kind() == reflect.Pointer {
+		return cmp.Compare(first.Pointer(), second.Pointer())
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// Returns the first value inside the specified value that can't be used
+// as a dictionary key or set element, which is the value itself unless
+// it's a frozen container. Frozen containers can only be used as keys if
+// all of their elements can be used as keys
+func unhashableValue(value any) (any, bool) {
+	switch value := value.(type) {
+	case *LoxBuffer, *LoxDeque, *LoxQueue, *LoxSortedList, *LoxTreeMap:
+		return value, true
+	case *LoxDict:
+		if !value.frozen {
+			return value, true
+		}
+		for _, entry := range value.entries() {
+			if unhashable, ok := unhashableValue(entry.value); ok {
+				return unhashable, true
+			}
+		}
+	case *LoxList:
+		if !value.frozen {
+			return value, true
+		}
+		for _, element := range value.elements {
+			if unhashable, ok := unhashableValue(element); ok {
+				return unhashable, true
+			}
+		}
+	case *LoxRecord:
+		for _, field := range value.values {
+			if unhashable, ok := unhashableValue(field); ok {
+				return unhashable, true
+			}
+		}
+	case *LoxSet:
+		if !value.frozen {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Returns the error message for the specified value that can't be used as
+// a dictionary key or set element, where usedAs describes how the value
+// was used
+func unhashableValueMsg(value any, unhashable any, usedAs string) string {
+	if value == unhashable {
+		return fmt.Sprintf("Type '%v' cannot be used as %v.", getType(value), usedAs)
+	}
+	if _, ok := value.(*LoxRecord); ok {
+		return fmt.Sprintf("Record containing type '%v' cannot be used as %v.",
+			getType(unhashable), usedAs)
+	}
+	return fmt.Sprintf("Frozen %v containing type '%v' cannot be used as %v.",
+		getType(value), getType(unhashable), usedAs)
+}
+
+// Returns the error message for calling a method that modifies a frozen
+// container
+func frozenMethodMsg(typeName string, methodName string) string {
+	return fmt.Sprintf("Cannot call '%v.%v' on frozen %v.", typeName, methodName, typeName)
+}
+
+func (l *LoxDict) dictKey() any {
+	if !l.frozen {
+		return l
+	}
+	if l.key == nil {
+		entries := slices.Clone(l.entries())
+		slices.SortFunc(entries, func(a loxDictEntry, b loxDictEntry) int {
+			return compareDictKeys(a.key, b.key)
+		})
+		keys := make([]any, 0, len(entries)*2)
+		for _, entry := range entries {
+			keys = append(keys, entry.key, dictKey(entry.value))
+		}
+		l.key = newLoxFrozenKey(frozenDictKind, keys)
+	}
+	return l.key
+}
+
+func (l *LoxDict) freeze() *LoxDict {
+	if l.frozen {
+		return l
+	}
+	frozenDict := EmptyLoxDict()
+	for _, entry := range l.entries() {
+		frozenDict.setKeyValue(entry.key, entry.value)
+	}
+	frozenDict.frozen = true
+	return frozenDict
+}
+
+func (l *LoxList) dictKey() any {
+	if !l.frozen {
+		return l
+	}
+	if l.key == nil {
+		keys := make([]any, len(l.elements))
+		for index, element := range l.elements {
+			keys[index] = dictKey(element)
+		}
+		l.key = newLoxFrozenKey(frozenListKind, keys)
+	}
+	return l.key
+}
+
+func (l *LoxList) freeze() *LoxList {
+	if l.frozen {
+		return l
+	}
+	frozenList := NewLoxList(slices.Clone(l.elements))
+	frozenList.frozen = true
+	return frozenList
+}
+
+func (l *LoxRecord) dictKey() any {
+	keys := make([]any, 0, len(l.values)+1)
+	keys = append(keys, l.recordType)
+	for _, value := range l.values {
+		keys = append(keys, dictKey(value))
+	}
+	return newLoxFrozenKey(frozenRecordKind, keys)
+}
+
+func (l *LoxSet) dictKey() any {
+	if !l.frozen {
+		return l
+	}
+	if l.key == nil {
+		keys := make([]any, 0, len(l.elements))
+		for element := range l.elements {
+			keys = append(keys, element)
+		}
+		slices.SortFunc(keys, compareDictKeys)
+		l.key = newLoxFrozenKey(frozenSetKind, keys)
+	}
+	return l.key
+}
+
+func (l *LoxSet) freeze() *LoxSet {
+	if l.frozen {
+		return l
+	}
+	frozenSet := EmptyLoxSet()
+	for element := range l.elements {
+		frozenSet.elements[element] = true
+	}
+	frozenSet.frozen = true
+	return frozenSet
+}
@@ -2,9 +2,12 @@ package ast
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/AlanLuu/lox/env"
 	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
 )
 
 type LoxFunction struct {
@@ -16,12 +19,31 @@ type LoxFunction struct {
 }
 
 func (f *LoxFunction) arity() int {
-	if f.hasVarArg() {
+	if f.hasVarArg() || f.hasDefaults() {
 		return -1
 	}
 	return len(f.declaration.Params)
 }
 
+// Returns the minimum and maximum number of arguments that this function
+// can be called with, where a maximum of -1 means that there is no maximum
+func (f *LoxFunction) arityRange() (int, int) {
+	minArity := 0
+	for index := range f.declaration.Params {
+		if index == f.varArgPos {
+			break
+		}
+		if f.defaultValue(index) != nil {
+			break
+		}
+		minArity++
+	}
+	if f.hasVarArg() {
+		return minArity, -1
+	}
+	return minArity, len(f.declaration.Params)
+}
+
 func (f *LoxFunction) bind(instance any) *LoxFunction {
 	environment := env.NewEnvironmentEnclosing(f.closure)
 	environment.Define("this", instance)
@@ -29,25 +51,43 @@ func (f *LoxFunction) bind(instance any) *LoxFunction {
 }
 
 func (f *LoxFunction) call(interpreter *Interpreter, arguments list.List[any]) (any, error) {
+	return f.callNamed(interpreter, arguments, nil)
+}
+
+func (f *LoxFunction) callNamed(interpreter *Interpreter, arguments list.List[any], namedArgs map[string]any) (any, error) {
+	if p := activeProfiler.Load(); p != nil {
+		defer p.enter(interpreter, f).pop()
+	}
+	if limitsErr := interpreter.enterCall(); limitsErr != nil {
+		return nil, limitsErr
+	}
+	interpreter.callDepth++
+	defer func() {
+		interpreter.callDepth--
+	}()
 	environment := env.NewEnvironmentEnclosing(f.closure)
-	if f.hasVarArg() {
-		for i := 0; i < len(f.declaration.Params); i++ {
-			if i > f.varArgPos {
-				environment.Define(f.declaration.Params[i].Lexeme, nil)
-			} else if i == f.varArgPos {
-				argsLen := len(arguments)
-				varArgs := list.NewListCap[any](int64(argsLen - i))
-				for j := i; j < argsLen; j++ {
-					varArgs.Add(arguments[j])
-				}
-				environment.Define(f.declaration.Params[i].Lexeme, NewLoxList(varArgs))
-			} else {
-				environment.Define(f.declaration.Params[i].Lexeme, arguments[i])
+	argsLen := len(arguments)
+	for i := 0; i < len(f.declaration.Params); i++ {
+		paramName := f.declaration.Params[i].Lexeme
+		switch {
+		case i == f.varArgPos:
+			varArgs := list.NewList[any]()
+			for j := i; j < argsLen; j++ {
+				varArgs.Add(arguments[j])
 			}
-		}
-	} else {
-		for i := 0; i < len(f.declaration.Params); i++ {
-			environment.Define(f.declaration.Params[i].Lexeme, arguments[i])
+			environment.Define(paramName, NewLoxList(varArgs))
+		case i < argsLen && (!f.hasVarArg() || i < f.varArgPos):
+			environment.Define(paramName, arguments[i])
+		default:
+			if value, ok := namedArgs[paramName]; ok {
+				environment.Define(paramName, value)
+				continue
+			}
+			value, valueErr := f.evalDefault(interpreter, environment, i)
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			environment.Define(paramName, value)
 		}
 	}
 	retValue, blockErr := interpreter.executeBlock(f.declaration.Body, environment)
@@ -67,6 +107,70 @@ func (f *LoxFunction) call(interpreter *Interpreter, arguments list.List[any]) (
 	return nil, nil
 }
 
+func (f *LoxFunction) checkNamedArgs(callToken *token.Token, numPositional int, names []*token.Token) error {
+	params := f.declaration.Params
+	if !f.hasVarArg() && numPositional > len(params) {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Expected at most %v positional arguments but got %v.", len(params), numPositional))
+	}
+	named := make(map[string]bool, len(names))
+	for _, name := range names {
+		index := slices.IndexFunc(params, func(param *token.Token) bool {
+			return param.Lexeme == name.Lexeme
+		})
+		switch {
+		case index < 0:
+			return loxerror.RuntimeError(name, "Unknown parameter '"+name.Lexeme+"'.")
+		case index == f.varArgPos:
+			return loxerror.RuntimeError(name, "Variadic parameter '"+name.Lexeme+"' can't be passed by name.")
+		case index < numPositional && (!f.hasVarArg() || index < f.varArgPos):
+			return loxerror.RuntimeError(name, "Got multiple values for parameter '"+name.Lexeme+"'.")
+		}
+		named[name.Lexeme] = true
+	}
+	for index, param := range params {
+		if index == f.varArgPos {
+			break
+		}
+		if index >= numPositional && !named[param.Lexeme] && f.defaultValue(index) == nil {
+			return loxerror.RuntimeError(callToken, "Missing argument for parameter '"+param.Lexeme+"'.")
+		}
+	}
+	return nil
+}
+
+// Evaluates the default value of the parameter at the specified index in
+// the function's environment, so that default values can refer to earlier
+// parameters. Parameters without a default value are nil.
+func (f *LoxFunction) evalDefault(interpreter *Interpreter, environment *env.Environment, index int) (any, error) {
+	defaultValue := f.defaultValue(index)
+	if defaultValue == nil {
+		return nil, nil
+	}
+	previous := interpreter.environment
+	interpreter.environment = environment
+	defer func() {
+		interpreter.environment = previous
+	}()
+	return interpreter.evaluate(defaultValue)
+}
+
+func (f *LoxFunction) defaultValue(index int) Expr {
+	if index >= len(f.declaration.Defaults) {
+		return nil
+	}
+	return f.declaration.Defaults[index]
+}
+
+func (f *LoxFunction) hasDefaults() bool {
+	for _, defaultValue := range f.declaration.Defaults {
+		if defaultValue != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *LoxFunction) hasVarArg() bool {
 	return f.varArgPos >= 0
 }
@@ -197,6 +197,9 @@ func (l *LoxGZIPReader) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, err.Error())
 				}
 			case *LoxString:
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				err := os.WriteFile(arg.str, data, 0666)
 				if err != nil {
 					return nil, loxerror.RuntimeError(name, err.Error())
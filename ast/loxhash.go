@@ -3,6 +3,7 @@ package ast
 import (
 	"fmt"
 	"hash"
+	"io"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
@@ -16,6 +17,29 @@ type LoxHash struct {
 	properties map[string]any
 }
 
+// Writes the specified buffer, file, or string to the hash, where files are
+// streamed from their current position instead of being read all at once
+func writeHashData(callToken *token.Token, fnName string, theHash hash.Hash, data any) error {
+	switch data := data.(type) {
+	case *LoxBuffer:
+		theHash.Write(data.bytes())
+	case *LoxFile:
+		if !data.isRead() {
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("File argument to '%v' must be in read mode.", fnName))
+		}
+		if _, err := io.Copy(theHash, data.file); err != nil {
+			return loxerror.RuntimeError(callToken, err.Error())
+		}
+	case *LoxString:
+		theHash.Write([]byte(data.str))
+	default:
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Argument to '%v' must be a buffer, file, or string.", fnName))
+	}
+	return nil
+}
+
 func NewLoxHash(theHash hash.Hash, hashFunc func() hash.Hash, hashType string) *LoxHash {
 	return &LoxHash{
 		hash:       theHash,
@@ -48,10 +72,6 @@ func (l *LoxHash) Get(name *token.Token) (any, error) {
 		}
 		return s, nil
 	}
-	argMustBeType := func(theType string) (any, error) {
-		errStr := fmt.Sprintf("Argument to 'hash.%v' must be a %v.", lexemeName, theType)
-		return nil, loxerror.RuntimeError(name, errStr)
-	}
 	switch lexemeName {
 	case "blockSize":
 		return int64(l.hash.BlockSize()), nil
@@ -84,18 +104,9 @@ func (l *LoxHash) Get(name *token.Token) (any, error) {
 	case "type":
 		return hashField(NewLoxString(l.hashType, '\''))
 	case "update":
-		return hashFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
-			switch arg := args[0].(type) {
-			case *LoxBuffer:
-				bytes := make([]byte, 0, len(arg.elements))
-				for _, element := range arg.elements {
-					bytes = append(bytes, byte(element.(int64)))
-				}
-				l.hash.Write(bytes)
-			case *LoxString:
-				l.hash.Write([]byte(arg.str))
-			default:
-				return argMustBeType("buffer or string")
+		return hashFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if err := writeHashData(name, "hash.update", l.hash, args[0]); err != nil {
+				return nil, err
 			}
 			return l, nil
 		})
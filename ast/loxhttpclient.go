@@ -0,0 +1,620 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The longest amount of time to wait between two attempts of a request
+const maxHTTPRetryDelay = time.Minute
+
+// Options that can be set on both HTTP clients and their individual
+// requests, where the options of a request override the ones of its client
+type httpRequestOptions struct {
+	timeout       time.Duration
+	retries       int64
+	backoff       time.Duration
+	retryStatuses []int64
+	headers       http.Header
+}
+
+func defaultHTTPRequestOptions() httpRequestOptions {
+	return httpRequestOptions{
+		backoff:       500 * time.Millisecond,
+		retryStatuses: []int64{429, 502, 503, 504},
+		headers:       http.Header{},
+	}
+}
+
+func httpOptionErr(callToken *token.Token, fnName string, key string, theType string) error {
+	return loxerror.RuntimeError(callToken,
+		fmt.Sprintf("Option '%v' in '%v' must be %v.", key, fnName, theType))
+}
+
+func httpSecondsOption(callToken *token.Token, fnName string, key string, value any) (time.Duration, error) {
+	var duration time.Duration
+	switch value := value.(type) {
+	case int64:
+		duration = time.Duration(value) * time.Second
+	case float64:
+		duration = time.Duration(value * float64(time.Second))
+	case *LoxDuration:
+		duration = value.duration
+	default:
+		return 0, httpOptionErr(callToken, fnName, key, "an integer, float, or duration")
+	}
+	if duration < 0 {
+		return 0, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Option '%v' in '%v' cannot be negative.", key, fnName))
+	}
+	return duration, nil
+}
+
+// Converts a dictionary whose keys are strings and whose values are strings
+// or lists of strings into the specified header or form values
+func httpStringListDict(dict *LoxDict, add func(key string, value string)) bool {
+	it := dict.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		key, ok := pair[0].(*LoxString)
+		if !ok {
+			return false
+		}
+		switch value := pair[1].(type) {
+		case *LoxString:
+			add(key.str, value.str)
+		case *LoxList:
+			for _, element := range value.elements {
+				elementStr, ok := element.(*LoxString)
+				if !ok {
+					return false
+				}
+				add(key.str, elementStr.str)
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Sets the specified option if it is one of the options that can be set on
+// both clients and requests, and returns whether the option was recognized
+func (o *httpRequestOptions) setOption(callToken *token.Token, fnName string, key string, value any) (bool, error) {
+	var err error
+	switch key {
+	case "backoff":
+		o.backoff, err = httpSecondsOption(callToken, fnName, key, value)
+	case "headers":
+		dict, ok := value.(*LoxDict)
+		if !ok {
+			return true, httpOptionErr(callToken, fnName, key, "a dictionary")
+		}
+		//Headers in the dictionary replace any existing values of the same
+		//headers instead of being added to them
+		headers := o.headers.Clone()
+		replaced := map[string]bool{}
+		if !httpStringListDict(dict, func(key string, value string) {
+			key = http.CanonicalHeaderKey(key)
+			if !replaced[key] {
+				headers.Del(key)
+				replaced[key] = true
+			}
+			headers.Add(key, value)
+		}) {
+			return true, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Headers dictionary in '%v' must only have strings or lists of strings.", fnName))
+		}
+		o.headers = headers
+	case "retries":
+		retries, ok := value.(int64)
+		if !ok {
+			return true, httpOptionErr(callToken, fnName, key, "an integer")
+		}
+		if retries < 0 {
+			return true, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Option 'retries' in '%v' cannot be negative.", fnName))
+		}
+		o.retries = retries
+	case "retryStatuses":
+		statusList, ok := value.(*LoxList)
+		if !ok {
+			return true, httpOptionErr(callToken, fnName, key, "a list of integers")
+		}
+		statuses := make([]int64, 0, len(statusList.elements))
+		for _, element := range statusList.elements {
+			status, ok := element.(int64)
+			if !ok {
+				return true, httpOptionErr(callToken, fnName, key, "a list of integers")
+			}
+			statuses = append(statuses, status)
+		}
+		o.retryStatuses = statuses
+	case "timeout":
+		o.timeout, err = httpSecondsOption(callToken, fnName, key, value)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// Returns the amount of time that a response with a status code of 429 or
+// 503 asks the client to wait before retrying, or 0 if it doesn't say
+func httpRetryAfter(res *http.Response) time.Duration {
+	retryAfter := res.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// Sends the request using the specified client, retrying it with
+// exponential backoff if it fails or the response has one of the retry
+// statuses, until the number of retries runs out
+func (o httpRequestOptions) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if o.timeout > 0 {
+		clientCopy := *client
+		clientCopy.Timeout = o.timeout
+		client = &clientCopy
+	}
+	for attempt := int64(0); ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		res, err := client.Do(req)
+		canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if attempt >= o.retries || !canReplay {
+			return res, err
+		}
+		if err == nil && !slices.Contains(o.retryStatuses, int64(res.StatusCode)) {
+			return res, nil
+		}
+		delay := maxHTTPRetryDelay
+		if attempt < 32 {
+			delay = min(o.backoff<<attempt, maxHTTPRetryDelay)
+		}
+		if res != nil {
+			delay = max(delay, min(httpRetryAfter(res), maxHTTPRetryDelay))
+			io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+			res.Body.Close()
+		}
+		time.Sleep(delay)
+	}
+}
+
+// A client that sends HTTP requests with its own cookie jar, default
+// headers, timeout, retry policy, and proxy settings
+type LoxHTTPClient struct {
+	client  *http.Client
+	options httpRequestOptions
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxHTTPClient() *LoxHTTPClient {
+	jar, _ := cookiejar.New(nil)
+	return &LoxHTTPClient{
+		client: &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+			Jar:       jar,
+		},
+		options: defaultHTTPRequestOptions(),
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxHTTPClient) transport() *http.Transport {
+	return l.client.Transport.(*http.Transport)
+}
+
+// Sets the specified client option, which is either an option that is
+// shared with requests or one that only applies to the client as a whole
+func (l *LoxHTTPClient) setOption(callToken *token.Token, key string, value any) error {
+	const fnName = "http.client"
+	if ok, err := l.options.setOption(callToken, fnName, key, value); ok {
+		return err
+	}
+	switch key {
+	case "cookies":
+		useCookies, ok := value.(bool)
+		if !ok {
+			return httpOptionErr(callToken, fnName, key, "a boolean")
+		}
+		if !useCookies {
+			l.client.Jar = nil
+		}
+	case "followRedirects":
+		followRedirects, ok := value.(bool)
+		if !ok {
+			return httpOptionErr(callToken, fnName, key, "a boolean")
+		}
+		if !followRedirects {
+			l.client.CheckRedirect = func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+		}
+	case "insecureSkipVerify":
+		insecure, ok := value.(bool)
+		if !ok {
+			return httpOptionErr(callToken, fnName, key, "a boolean")
+		}
+		l.transport().TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+	case "proxy":
+		switch proxy := value.(type) {
+		case *LoxString:
+			if proxy.str == "" {
+				l.transport().Proxy = nil
+				break
+			}
+			proxyURL, err := url.Parse(proxy.str)
+			if err != nil || proxyURL.Host == "" {
+				return loxerror.RuntimeError(callToken,
+					fmt.Sprintf("Invalid proxy URL '%v'.", proxy.str))
+			}
+			l.transport().Proxy = http.ProxyURL(proxyURL)
+		case nil:
+			l.transport().Proxy = http.ProxyFromEnvironment
+		default:
+			return httpOptionErr(callToken, fnName, key, "a string or nil")
+		}
+	default:
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+	}
+	return nil
+}
+
+// Writes the specified files and form fields into a multipart form body,
+// returning the body and its content type
+func httpMultipartBody(callToken *token.Token, fnName string, files *LoxDict, form url.Values) ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, values := range form {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, "", loxerror.RuntimeError(callToken, err.Error())
+			}
+		}
+	}
+	filesErr := loxerror.RuntimeError(callToken,
+		fmt.Sprintf("Files dictionary in '%v' must have string keys and buffer, file, or string values.", fnName))
+	it := files.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		fieldName, ok := pair[0].(*LoxString)
+		if !ok {
+			return nil, "", filesErr
+		}
+		var source io.Reader
+		var fileName string
+		switch value := pair[1].(type) {
+		case *LoxBuffer:
+			source = bytes.NewReader(value.bytes())
+			fileName = fieldName.str
+		case *LoxFile:
+			if !value.isRead() {
+				return nil, "", loxerror.RuntimeError(callToken,
+					fmt.Sprintf("File in files dictionary in '%v' must be in read mode.", fnName))
+			}
+			source = value.file
+			fileName = filepath.Base(value.name)
+		case *LoxString:
+			file, err := os.Open(value.str)
+			if err != nil {
+				return nil, "", loxerror.RuntimeError(callToken, err.Error())
+			}
+			defer file.Close()
+			source = file
+			fileName = filepath.Base(value.str)
+		default:
+			return nil, "", filesErr
+		}
+		part, err := writer.CreateFormFile(fieldName.str, fileName)
+		if err != nil {
+			return nil, "", loxerror.RuntimeError(callToken, err.Error())
+		}
+		if _, err := io.Copy(part, source); err != nil {
+			return nil, "", loxerror.RuntimeError(callToken, err.Error())
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", loxerror.RuntimeError(callToken, err.Error())
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// Sends a request with the specified method to the specified URL, where the
+// options dictionary can contain the request body, form data, files, and
+// options that override the ones of the client
+func (l *LoxHTTPClient) request(in *Interpreter, fnName string, method string, urlStr string, optionsDict *LoxDict) (*LoxHTTPResponse, error) {
+	options := l.options
+	var body any
+	var hasBody bool
+	var form url.Values
+	var files *LoxDict
+	if optionsDict != nil {
+		err := forEachOption(optionsDict, func(key string, value any) error {
+			if ok, err := options.setOption(in.callToken, fnName, key, value); ok {
+				return err
+			}
+			switch key {
+			case "body":
+				switch value.(type) {
+				case *LoxBuffer, *LoxDict, *LoxString, nil:
+				default:
+					return httpOptionErr(in.callToken, fnName, key, "a buffer, dictionary, string, or nil")
+				}
+				body = value
+				hasBody = value != nil
+			case "files":
+				dict, ok := value.(*LoxDict)
+				if !ok {
+					return httpOptionErr(in.callToken, fnName, key, "a dictionary")
+				}
+				files = dict
+			case "form":
+				dict, ok := value.(*LoxDict)
+				if !ok {
+					return httpOptionErr(in.callToken, fnName, key, "a dictionary")
+				}
+				form = url.Values{}
+				if !httpStringListDict(dict, form.Add) {
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Form dictionary in '%v' must only have strings or lists of strings.", fnName))
+				}
+			default:
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hasBody && (form != nil || files != nil) {
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Options 'body' and 'form' or 'files' in '%v' cannot be used together.", fnName))
+	}
+	if (method == "GET" || method == "HEAD") && (hasBody || form != nil || files != nil) {
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("%v requests in '%v' cannot have a body.", method, fnName))
+	}
+
+	var bodyBytes []byte
+	var contentType string
+	switch {
+	case files != nil:
+		var err error
+		bodyBytes, contentType, err = httpMultipartBody(in.callToken, fnName, files, form)
+		if err != nil {
+			return nil, err
+		}
+	case form != nil:
+		if len(form) > 0 {
+			bodyBytes = []byte(form.Encode())
+			contentType = "application/x-www-form-urlencoded"
+		}
+	default:
+		switch body := body.(type) {
+		case *LoxBuffer:
+			bodyBytes = body.bytes()
+			contentType = "application/octet-stream"
+		case *LoxDict:
+			jsonStr, err := jsonDictToStr(in, body)
+			if err != nil {
+				return nil, err
+			}
+			bodyBytes = []byte(jsonStr)
+			contentType = "application/json"
+		case *LoxString:
+			bodyBytes = []byte(body.str)
+			contentType = "text/plain"
+		}
+	}
+
+	var bodyReader io.Reader
+	if len(bodyBytes) > 0 {
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+	req, err := http.NewRequest(method, urlStr, bodyReader)
+	if err != nil {
+		return nil, loxerror.RuntimeError(in.callToken, err.Error())
+	}
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, values := range options.headers {
+		req.Header[key] = values
+	}
+	res, err := LoxHTTPResHelper(req.URL.String(), func() (*http.Response, error) {
+		return options.do(l.client, req)
+	})
+	if err != nil {
+		return nil, loxerror.RuntimeError(in.callToken, err.Error())
+	}
+	return res, nil
+}
+
+func (l *LoxHTTPClient) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	clientFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native http client fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	fnName := "http client." + methodName
+	//Returns the URL argument and the options dictionary argument if it is
+	//specified, starting from the specified argument index
+	urlAndOptions := func(in *Interpreter, args list.List[any], index int) (string, *LoxDict, error) {
+		ordinals := []string{"First", "Second", "Third"}
+		argsLen := len(args)
+		if argsLen != index+1 && argsLen != index+2 {
+			return "", nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected %v or %v arguments but got %v.", index+1, index+2, argsLen))
+		}
+		urlStr, ok := args[index].(*LoxString)
+		if !ok {
+			return "", nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("%v argument to '%v' must be a string.", ordinals[index], fnName))
+		}
+		if argsLen == index+1 {
+			return urlStr.str, nil, nil
+		}
+		options, ok := args[index+1].(*LoxDict)
+		if !ok {
+			return "", nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("%v argument to '%v' must be a dictionary.", ordinals[index+1], fnName))
+		}
+		return urlStr.str, options, nil
+	}
+	cookieURL := func(in *Interpreter, arg any) (*url.URL, error) {
+		if l.client.Jar == nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Cannot call '%v' on http clients without cookies.", fnName))
+		}
+		urlStr, ok := arg.(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("First argument to '%v' must be a string.", fnName))
+		}
+		cookieURL, err := url.Parse(urlStr.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return cookieURL, nil
+	}
+	switch methodName {
+	case "clearCookies":
+		return clientFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.client.Jar != nil {
+				l.client.Jar, _ = cookiejar.New(nil)
+			}
+			return nil, nil
+		})
+	case "close":
+		return clientFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.client.CloseIdleConnections()
+			return nil, nil
+		})
+	case "cookies":
+		return clientFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			cookieURL, err := cookieURL(in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			dict := EmptyLoxDict()
+			for _, cookie := range l.client.Jar.Cookies(cookieURL) {
+				dict.setKeyValue(NewLoxStringQuote(cookie.Name), NewLoxStringQuote(cookie.Value))
+			}
+			return dict, nil
+		})
+	case "delete", "get", "head", "patch", "post":
+		return clientFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			urlStr, options, err := urlAndOptions(in, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			return l.request(in, fnName, strings.ToUpper(methodName), urlStr, options)
+		})
+	case "headers":
+		return clientFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			dict := EmptyLoxDict()
+			for key, values := range l.options.headers {
+				valuesList := list.NewListCap[any](int64(len(values)))
+				for _, value := range values {
+					valuesList.Add(NewLoxStringQuote(value))
+				}
+				dict.setKeyValue(NewLoxStringQuote(key), NewLoxList(valuesList))
+			}
+			return dict, nil
+		})
+	case "request":
+		return clientFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) == 0 {
+				return nil, loxerror.RuntimeError(in.callToken, "Expected 2 or 3 arguments but got 0.")
+			}
+			method, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'http client.request' must be a string.")
+			}
+			urlStr, options, err := urlAndOptions(in, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return l.request(in, fnName, strings.ToUpper(method.str), urlStr, options)
+		})
+	case "setCookies":
+		return clientFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			cookieURL, err := cookieURL(in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			dict, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'http client.setCookies' must be a dictionary.")
+			}
+			var cookies []*http.Cookie
+			err = forEachOption(dict, func(key string, value any) error {
+				valueStr, ok := value.(*LoxString)
+				if !ok {
+					return loxerror.RuntimeError(in.callToken,
+						"Cookies dictionary in 'http client.setCookies' must only have strings.")
+				}
+				cookies = append(cookies, &http.Cookie{Name: key, Value: valueStr.str})
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			l.client.Jar.SetCookies(cookieURL, cookies)
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "HTTP clients have no property called '"+methodName+"'.")
+}
+
+func (l *LoxHTTPClient) String() string {
+	return fmt.Sprintf("<http client at %p>", l)
+}
+
+func (l *LoxHTTPClient) Type() string {
+	return "http client"
+}
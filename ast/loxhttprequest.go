@@ -0,0 +1,93 @@
+package ast
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// An HTTP request received by an HTTP server, which is passed to the route
+// handler that matches it
+type LoxHTTPRequest struct {
+	req        *http.Request
+	body       []byte
+	params     []string
+	properties map[string]any
+}
+
+func NewLoxHTTPRequest(req *http.Request, body []byte, params []string) *LoxHTTPRequest {
+	return &LoxHTTPRequest{
+		req:        req,
+		body:       body,
+		params:     params,
+		properties: make(map[string]any),
+	}
+}
+
+func (l *LoxHTTPRequest) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if field, ok := l.properties[lexemeName]; ok {
+		return field, nil
+	}
+	requestField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	switch lexemeName {
+	case "body":
+		return requestField(NewLoxStringQuote(string(l.body)))
+	case "headers":
+		dict := EmptyLoxDict()
+		for key, value := range l.req.Header {
+			valuesList := list.NewListCap[any](int64(len(value)))
+			for _, str := range value {
+				valuesList.Add(NewLoxStringQuote(str))
+			}
+			dict.setKeyValue(NewLoxString(key, '\''), NewLoxList(valuesList))
+		}
+		return requestField(dict)
+	case "method":
+		return requestField(NewLoxStringQuote(l.req.Method))
+	case "params":
+		dict := EmptyLoxDict()
+		for _, param := range l.params {
+			dict.setKeyValue(NewLoxString(param, '\''), NewLoxStringQuote(l.req.PathValue(param)))
+		}
+		return requestField(dict)
+	case "path":
+		return requestField(NewLoxStringQuote(l.req.URL.Path))
+	case "query":
+		dict := EmptyLoxDict()
+		for key, value := range l.req.URL.Query() {
+			dict.setKeyValue(NewLoxString(key, '\''), NewLoxStringQuote(value[0]))
+		}
+		return requestField(dict)
+	case "raw":
+		buffer := EmptyLoxBufferCap(int64(len(l.body)))
+		for _, element := range l.body {
+			addErr := buffer.add(int64(element))
+			if addErr != nil {
+				return nil, loxerror.RuntimeError(name, addErr.Error())
+			}
+		}
+		return requestField(buffer)
+	case "remoteAddr":
+		return requestField(NewLoxStringQuote(l.req.RemoteAddr))
+	case "url":
+		return requestField(NewLoxStringQuote(l.req.URL.String()))
+	}
+	return nil, loxerror.RuntimeError(name, "HTTP requests have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxHTTPRequest) String() string {
+	return fmt.Sprintf("<http request [%v %v] at %p>", l.req.Method, l.req.URL.Path, l)
+}
+
+func (l *LoxHTTPRequest) Type() string {
+	return "http request"
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AlanLuu/lox/interfaces"
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
 	"github.com/AlanLuu/lox/token"
@@ -100,6 +101,35 @@ func (l *LoxHTTPResponse) close() {
 	}
 }
 
+// Returns an iterator that reads the response body in buffers of up to
+// chunkSize bytes, closing the response once the body is exhausted
+func (l *LoxHTTPResponse) bodyIterator(chunkSize int) interfaces.Iterator {
+	var chunk []byte
+	readChunk := func() {
+		if l.isClosed {
+			chunk = nil
+			return
+		}
+		chunk = make([]byte, chunkSize)
+		numRead, err := io.ReadFull(l.res.Body, chunk)
+		chunk = chunk[:numRead]
+		if err != nil {
+			l.close()
+		}
+	}
+	readChunk()
+	return ProtoIterator{
+		hasNextMethod: func() bool {
+			return len(chunk) > 0
+		},
+		nextMethod: func() any {
+			buffer := NewLoxBufferFromBytes(chunk)
+			readChunk()
+			return buffer
+		},
+	}
+}
+
 func (l *LoxHTTPResponse) Get(name *token.Token) (any, error) {
 	lexemeName := name.Lexeme
 	if field, ok := l.properties[lexemeName]; ok {
@@ -159,6 +189,33 @@ func (l *LoxHTTPResponse) Get(name *token.Token) (any, error) {
 		return responseField(buffer)
 	case "status":
 		return responseField(int64(l.res.StatusCode))
+	case "stream":
+		return responseFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			chunkSize := int64(8192)
+			argsLen := len(args)
+			switch argsLen {
+			case 0:
+			case 1:
+				size, ok := args[0].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Argument to 'http response.stream' must be an integer.")
+				}
+				if size <= 0 {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Argument to 'http response.stream' must be positive.")
+				}
+				chunkSize = size
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+			if l.isClosed {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Cannot stream the body of a closed http response.")
+			}
+			return NewLoxIterator(l.bodyIterator(int(chunkSize))), nil
+		})
 	case "text":
 		bytes, err := io.ReadAll(l.res.Body)
 		if err != nil {
@@ -0,0 +1,368 @@
+package ast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The amount of time that a server waits for requests that are still being
+// handled to finish when it is shut down
+const httpServerShutdownTimeout = 10 * time.Second
+
+var httpPatternParamRegex = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(\.\.\.)?\}`)
+
+// Returns the names of the wildcards in the specified route pattern, such as
+// "id" in "GET /items/{id}"
+func httpPatternParams(pattern string) []string {
+	params := []string{}
+	for _, match := range httpPatternParamRegex.FindAllStringSubmatch(pattern, -1) {
+		params = append(params, match[1])
+	}
+	return params
+}
+
+// A response returned from an HTTP route handler that was created by
+// http.response
+type LoxHTTPServerResponse struct {
+	status  int
+	body    any
+	headers *LoxDict
+}
+
+func (l *LoxHTTPServerResponse) Get(name *token.Token) (any, error) {
+	switch name.Lexeme {
+	case "body":
+		return l.body, nil
+	case "headers":
+		if l.headers == nil {
+			return EmptyLoxDict(), nil
+		}
+		return l.headers, nil
+	case "status":
+		return int64(l.status), nil
+	}
+	return nil, loxerror.RuntimeError(name,
+		"HTTP server responses have no property called '"+name.Lexeme+"'.")
+}
+
+func (l *LoxHTTPServerResponse) String() string {
+	return fmt.Sprintf("<http server response [%v] at %p>", l.status, l)
+}
+
+func (l *LoxHTTPServerResponse) Type() string {
+	return "http server response"
+}
+
+// Writes the value returned from an HTTP route handler as the response to
+// the request that it handled
+func writeHTTPServerResponse(in *Interpreter, w http.ResponseWriter, result any) error {
+	status := http.StatusOK
+	body := result
+	if response, ok := result.(*LoxHTTPServerResponse); ok {
+		status, body = response.status, response.body
+		if response.headers != nil {
+			it := response.headers.Iterator()
+			for it.HasNext() {
+				pair := it.Next().(*LoxList).elements
+				key, ok := pair[0].(*LoxString)
+				if !ok {
+					return errors.New("HTTP response header names must be strings.")
+				}
+				switch value := pair[1].(type) {
+				case *LoxString:
+					w.Header().Set(key.str, value.str)
+				case *LoxList:
+					for _, element := range value.elements {
+						elementStr, ok := element.(*LoxString)
+						if !ok {
+							return errors.New("HTTP response header values must be strings or lists of strings.")
+						}
+						w.Header().Add(key.str, elementStr.str)
+					}
+				default:
+					return errors.New("HTTP response header values must be strings or lists of strings.")
+				}
+			}
+		}
+	} else if result == nil {
+		status = http.StatusNoContent
+	}
+
+	var contentType string
+	var bodyBytes []byte
+	switch body := body.(type) {
+	case nil:
+	case *LoxString:
+		contentType, bodyBytes = "text/plain; charset=utf-8", []byte(body.str)
+	case *LoxBuffer:
+		bodyBytes = make([]byte, 0, len(body.elements))
+		for _, element := range body.elements {
+			bodyBytes = append(bodyBytes, byte(element.(int64)))
+		}
+		contentType = "application/octet-stream"
+	case *LoxDict:
+		jsonStr, jsonErr := jsonDictToStr(in, body)
+		if jsonErr != nil {
+			return jsonErr
+		}
+		contentType, bodyBytes = "application/json", []byte(jsonStr)
+	default:
+		return fmt.Errorf("HTTP route handler cannot return type '%v'.", getType(body))
+	}
+	if contentType != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(status)
+	_, writeErr := w.Write(bodyBytes)
+	return writeErr
+}
+
+// An HTTP server created by http.server, which calls Lox functions to
+// handle requests that match the routes registered with server.route
+type LoxHTTPServer struct {
+	mux      *LoxServeMux
+	mutex    sync.Mutex //Guards server, shutdown, and methods
+	server   *http.Server
+	shutdown chan struct{}
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxHTTPServer() *LoxHTTPServer {
+	return &LoxHTTPServer{
+		mux:     NewLoxServeMux(),
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Registers the specified Lox function as the handler of requests that match
+// the specified pattern. Each request is handled on its own goroutine using a
+// copy of the interpreter.
+func (l *LoxHTTPServer) route(in *Interpreter, pattern string, handler *LoxFunction) (err error) {
+	params := httpPatternParams(pattern)
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, bodyErr := io.ReadAll(r.Body)
+		if bodyErr != nil {
+			http.Error(w, bodyErr.Error(), http.StatusBadRequest)
+			return
+		}
+		handlerIn := in.newTaskInterpreter()
+		argList := getArgList(handler, 1)
+		argList[0] = NewLoxHTTPRequest(r, body, params)
+		result, resultErr := handler.call(handlerIn, argList)
+		if resultReturn, ok := result.(Return); ok {
+			result, resultErr = resultReturn.FinalValue, nil
+		}
+		if resultErr == nil {
+			resultErr = writeHTTPServerResponse(handlerIn, w, result)
+		}
+		if resultErr != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Runtime error in HTTP handler for '%v': %v\n",
+				pattern,
+				strings.ReplaceAll(resultErr.Error(), "\n", " "),
+			)
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+		}
+	})
+
+	//http.ServeMux panics if the pattern is invalid or conflicts with
+	//another pattern
+	defer func() {
+		if r := recover(); r != nil {
+			message := fmt.Sprint(r)
+			if strings.Contains(message, "conflicts with") {
+				err = fmt.Errorf("Route '%v' conflicts with a route that was already registered.", pattern)
+			} else {
+				err = fmt.Errorf("Invalid route '%v': %v", pattern, message)
+			}
+		}
+	}()
+	l.mux.Handle(pattern, handlerFunc)
+	return nil
+}
+
+// Starts the server on the specified port using TLS if certFile isn't empty,
+// and blocks until the server is shut down by server.shutdown or Ctrl+C
+func (l *LoxHTTPServer) listen(port int64, certFile string, keyFile string) error {
+	l.mutex.Lock()
+	if l.server != nil {
+		l.mutex.Unlock()
+		return errors.New("HTTP server is already running.")
+	}
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: l.mux,
+	}
+	shutdownChan := make(chan struct{})
+	l.server, l.shutdown = srv, shutdownChan
+	l.mutex.Unlock()
+	defer func() {
+		l.mutex.Lock()
+		l.server, l.shutdown = nil, nil
+		l.mutex.Unlock()
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	defer signal.Stop(sigChan)
+
+	serveChan := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			serveChan <- srv.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			serveChan <- srv.ListenAndServe()
+		}
+	}()
+	if certFile != "" {
+		fmt.Printf("Listening at https://localhost:%d\n", port)
+	} else {
+		fmt.Printf("Listening at http://localhost:%d\n", port)
+	}
+	select {
+	case serveErr := <-serveChan:
+		return serveErr
+	case <-sigChan:
+	case <-shutdownChan:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpServerShutdownTimeout)
+	defer cancel()
+	shutdownErr := srv.Shutdown(ctx)
+	<-serveChan
+	return shutdownErr
+}
+
+// Tells the server to stop accepting new requests and returns immediately.
+// The call to server.listen returns once the requests that are still being
+// handled have finished.
+func (l *LoxHTTPServer) stop() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.shutdown == nil {
+		return false
+	}
+	close(l.shutdown)
+	l.shutdown = nil
+	return true
+}
+
+func (l *LoxHTTPServer) isRunning() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.server != nil
+}
+
+func (l *LoxHTTPServer) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.mutex.Lock()
+	method, ok := l.methods[methodName]
+	l.mutex.Unlock()
+	if ok {
+		return method, nil
+	}
+	serverFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native http server fn %v at %p>", methodName, s)
+		}
+		//Route handlers can get methods of the server on other goroutines
+		l.mutex.Lock()
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		l.mutex.Unlock()
+		return s, nil
+	}
+	switch methodName {
+	case "isRunning":
+		return serverFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isRunning(), nil
+		})
+	case "listen":
+		return serverFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			port, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'http server.listen' must be an integer.")
+			}
+			if err := l.listen(port, "", ""); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "listenTLS":
+		return serverFunc(3, func(in *Interpreter, args list.List[any]) (any, error) {
+			port, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'http server.listenTLS' must be an integer.")
+			}
+			certFile, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'http server.listenTLS' must be a string.")
+			}
+			keyFile, ok := args[2].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Third argument to 'http server.listenTLS' must be a string.")
+			}
+			if certFile.str == "" {
+				return nil, loxerror.RuntimeError(name,
+					"Certificate file path in 'http server.listenTLS' cannot be empty.")
+			}
+			if err := l.listen(port, certFile.str, keyFile.str); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "route":
+		return serverFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			pattern, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'http server.route' must be a string.")
+			}
+			handler, ok := args[1].(*LoxFunction)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'http server.route' must be a function.")
+			}
+			if err := l.route(in, pattern.str, handler); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "shutdown":
+		return serverFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.stop(), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "HTTP servers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxHTTPServer) String() string {
+	return fmt.Sprintf("<http server at %p>", l)
+}
+
+func (l *LoxHTTPServer) Type() string {
+	return "http server"
+}
@@ -0,0 +1,455 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+// A line of an INI file. Lines that are not key-value pairs, such as
+// comments and blank lines, are kept as is so that they are preserved when
+// the file is converted back into a string
+type iniLine struct {
+	raw     string
+	key     string
+	value   string
+	isKey   bool
+	changed bool
+}
+
+// A section of an INI file, where the section with an empty name holds the
+// keys that appear before the first section header
+type iniSection struct {
+	name   string
+	header string
+	lines  []*iniLine
+}
+
+func (s *iniSection) find(key string) *iniLine {
+	for index := len(s.lines) - 1; index >= 0; index-- {
+		if line := s.lines[index]; line.isKey && line.key == key {
+			return line
+		}
+	}
+	return nil
+}
+
+func (s *iniSection) keys() []string {
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, line := range s.lines {
+		if line.isKey && !seen[line.key] {
+			seen[line.key] = true
+			keys = append(keys, line.key)
+		}
+	}
+	return keys
+}
+
+func (s *iniSection) remove(key string) bool {
+	lines := s.lines[:0]
+	removed := false
+	for _, line := range s.lines {
+		if line.isKey && line.key == key {
+			removed = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	s.lines = lines
+	return removed
+}
+
+// An INI file returned from ini.parse, ini.parseFile, or ini.new
+type LoxINIFile struct {
+	sections []*iniSection
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxINIFile() *LoxINIFile {
+	return &LoxINIFile{
+		sections: []*iniSection{{name: ""}},
+		methods:  make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Parses the specified INI data, where comments start with ';' or '#' and
+// keys are separated from their values by '=' or ':'
+func ParseLoxINIFile(data string) (*LoxINIFile, error) {
+	iniFile := NewLoxINIFile()
+	current := iniFile.sections[0]
+	data = strings.TrimPrefix(data, "\ufeff")
+	for index, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+			current.lines = append(current.lines, &iniLine{raw: raw})
+		case trimmed[0] == '[':
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, fmt.Errorf("INI line %v: section header is missing ']'", index+1)
+			}
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if section := iniFile.section(name); section != nil && name != "" {
+				current = section
+			} else {
+				current = &iniSection{name: name, header: raw}
+				iniFile.sections = append(iniFile.sections, current)
+			}
+		default:
+			separator := strings.IndexAny(trimmed, "=:")
+			if separator <= 0 {
+				return nil, fmt.Errorf("INI line %v: expected 'key = value'", index+1)
+			}
+			current.lines = append(current.lines, &iniLine{
+				raw:   raw,
+				key:   strings.TrimSpace(trimmed[:separator]),
+				value: strings.TrimSpace(trimmed[separator+1:]),
+				isKey: true,
+			})
+		}
+	}
+	//Drop the empty line produced by a trailing newline
+	lastSection := iniFile.sections[len(iniFile.sections)-1]
+	if numLines := len(lastSection.lines); numLines > 0 &&
+		lastSection.lines[numLines-1].raw == "" {
+		lastSection.lines = lastSection.lines[:numLines-1]
+	}
+	return iniFile, nil
+}
+
+func (l *LoxINIFile) section(name string) *iniSection {
+	for _, section := range l.sections {
+		if section.name == name {
+			return section
+		}
+	}
+	return nil
+}
+
+func (l *LoxINIFile) get(section string, key string) (string, bool) {
+	if s := l.section(section); s != nil {
+		if line := s.find(key); line != nil {
+			return line.value, true
+		}
+	}
+	return "", false
+}
+
+func (l *LoxINIFile) set(section string, key string, value string) {
+	s := l.section(section)
+	if s == nil {
+		//Separate the new section from the previous one with a blank line
+		last := l.sections[len(l.sections)-1]
+		numLines := len(last.lines)
+		if numLines > 0 {
+			lastLine := last.lines[numLines-1]
+			if lastLine.isKey || strings.TrimSpace(lastLine.raw) != "" {
+				last.lines = append(last.lines, &iniLine{})
+			}
+		} else if last.name != "" {
+			last.lines = append(last.lines, &iniLine{})
+		}
+		s = &iniSection{name: section, header: "[" + section + "]"}
+		l.sections = append(l.sections, s)
+	}
+	if line := s.find(key); line != nil {
+		line.value = value
+		line.changed = true
+		return
+	}
+	//Insert new keys after the last key of the section so that they appear
+	//before any trailing blank lines or comments
+	insertIndex := 0
+	for index, line := range s.lines {
+		if line.isKey {
+			insertIndex = index + 1
+		}
+	}
+	newLine := &iniLine{key: key, value: value, isKey: true, changed: true}
+	s.lines = append(s.lines[:insertIndex], append([]*iniLine{newLine}, s.lines[insertIndex:]...)...)
+}
+
+func (l *LoxINIFile) removeSection(name string) bool {
+	for index, section := range l.sections {
+		if section.name == name {
+			if name == "" {
+				section.lines = nil
+			} else {
+				l.sections = append(l.sections[:index], l.sections[index+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (l *LoxINIFile) toString() string {
+	var builder strings.Builder
+	for _, section := range l.sections {
+		if section.name != "" {
+			builder.WriteString(section.header)
+			builder.WriteByte('\n')
+		}
+		for _, line := range section.lines {
+			if line.changed {
+				builder.WriteString(line.key + " = " + line.value)
+			} else {
+				builder.WriteString(line.raw)
+			}
+			builder.WriteByte('\n')
+		}
+	}
+	return builder.String()
+}
+
+func (l *LoxINIFile) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	iniFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ini file fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(ordinal string, theType string) (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("%v argument to 'ini file.%v' must be %v.", ordinal, methodName, theType))
+	}
+	argsLenErr := func(argsLen int) (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+	}
+	sectionAndKey := func(args list.List[any]) (string, string, error) {
+		section, ok := args[0].(*LoxString)
+		if !ok {
+			_, err := argMustBeType("First", "a string")
+			return "", "", err
+		}
+		key, ok := args[1].(*LoxString)
+		if !ok {
+			_, err := argMustBeType("Second", "a string")
+			return "", "", err
+		}
+		return section.str, key.str, nil
+	}
+	//Shared by get, getBool, getFloat, and getInt, which return the default
+	//value if the key is missing and throw an error if there is no default
+	getter := func(convert func(string) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		return iniFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 2 && argsLen != 3 {
+				return argsLenErr(argsLen)
+			}
+			section, key, err := sectionAndKey(args)
+			if err != nil {
+				return nil, err
+			}
+			value, ok := l.get(section, key)
+			if !ok {
+				if argsLen == 3 {
+					return args[2], nil
+				}
+				if l.section(section) == nil {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("INI file has no section called '%v'.", section))
+				}
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("INI section '%v' has no key called '%v'.", section, key))
+			}
+			result, err := convert(value)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Value of key '%v' in INI section '%v' %v.", key, section, err.Error()))
+			}
+			return result, nil
+		})
+	}
+	switch methodName {
+	case "get":
+		return getter(func(value string) (any, error) {
+			return NewLoxStringQuote(value), nil
+		})
+	case "getBool":
+		return getter(func(value string) (any, error) {
+			switch strings.ToLower(value) {
+			case "1", "on", "true", "yes":
+				return true, nil
+			case "0", "false", "no", "off":
+				return false, nil
+			}
+			return nil, fmt.Errorf("is not a boolean: '%v'", value)
+		})
+	case "getFloat":
+		return getter(func(value string) (any, error) {
+			result, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("is not a float: '%v'", value)
+			}
+			return result, nil
+		})
+	case "getInt":
+		return getter(func(value string) (any, error) {
+			result, err := strconv.ParseInt(value, 0, 64)
+			if err != nil {
+				return nil, fmt.Errorf("is not an integer: '%v'", value)
+			}
+			return result, nil
+		})
+	case "has":
+		return iniFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, key, err := sectionAndKey(args)
+			if err != nil {
+				return nil, err
+			}
+			_, ok := l.get(section, key)
+			return ok, nil
+		})
+	case "hasSection":
+		return iniFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'ini file.hasSection' must be a string.")
+			}
+			return l.section(section.str) != nil, nil
+		})
+	case "keys":
+		return iniFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'ini file.keys' must be a string.")
+			}
+			keys := list.NewList[any]()
+			if s := l.section(section.str); s != nil {
+				for _, key := range s.keys() {
+					keys.Add(NewLoxStringQuote(key))
+				}
+			}
+			return NewLoxList(keys), nil
+		})
+	case "removeKey":
+		return iniFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, key, err := sectionAndKey(args)
+			if err != nil {
+				return nil, err
+			}
+			if s := l.section(section); s != nil {
+				return s.remove(key), nil
+			}
+			return false, nil
+		})
+	case "removeSection":
+		return iniFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'ini file.removeSection' must be a string.")
+			}
+			return l.removeSection(section.str), nil
+		})
+	case "sections":
+		return iniFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			sections := list.NewList[any]()
+			for _, section := range l.sections {
+				if section.name != "" {
+					sections.Add(NewLoxStringQuote(section.name))
+				}
+			}
+			return NewLoxList(sections), nil
+		})
+	case "set":
+		return iniFunc(3, func(_ *Interpreter, args list.List[any]) (any, error) {
+			section, key, err := sectionAndKey(args)
+			if err != nil {
+				return nil, err
+			}
+			if strings.ContainsAny(section, "[]\r\n") {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Invalid INI section name '%v'.", section))
+			}
+			if key == "" || strings.ContainsAny(key, "=:[\r\n") ||
+				strings.TrimSpace(key) != key || key[0] == ';' || key[0] == '#' {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Invalid INI key '%v'.", key))
+			}
+			var value string
+			switch arg := args[2].(type) {
+			case *LoxString:
+				value = arg.str
+			case bool:
+				value = strconv.FormatBool(arg)
+			case int64:
+				value = strconv.FormatInt(arg, 10)
+			case float64:
+				value = util.FormatFloat(arg)
+			default:
+				return argMustBeType("Third", "a string, boolean, integer, or float")
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, loxerror.RuntimeError(name,
+					"INI values cannot contain newlines.")
+			}
+			l.set(section, key, strings.TrimSpace(value))
+			return nil, nil
+		})
+	case "toDict":
+		return iniFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			dict := EmptyLoxDict()
+			for _, section := range l.sections {
+				sectionDict := EmptyLoxDict()
+				for _, key := range section.keys() {
+					value, _ := l.get(section.name, key)
+					sectionDict.setKeyValue(NewLoxStringQuote(key), NewLoxStringQuote(value))
+				}
+				if section.name == "" && len(sectionDict.indexes) == 0 {
+					continue
+				}
+				dict.setKeyValue(NewLoxStringQuote(section.name), sectionDict)
+			}
+			return dict, nil
+		})
+	case "toString":
+		return iniFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.toString()), nil
+		})
+	case "write":
+		return iniFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			path, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'ini file.write' must be a string.")
+			}
+			if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
+			if err := os.WriteFile(path.str, []byte(l.toString()), 0666); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "INI files have no property called '"+methodName+"'.")
+}
+
+func (l *LoxINIFile) String() string {
+	return fmt.Sprintf("<ini file at %p>", l)
+}
+
+func (l *LoxINIFile) Type() string {
+	return "ini file"
+}
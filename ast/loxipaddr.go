@@ -0,0 +1,430 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Returns the specified address as an unsigned integer
+func ipAddrToBig(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+// Returns the address of the specified bit length whose unsigned integer
+// value is num, or false if num is out of range
+func ipAddrFromBig(num *big.Int, bitLen int) (netip.Addr, bool) {
+	if num.Sign() < 0 || num.BitLen() > bitLen {
+		return netip.Addr{}, false
+	}
+	addr, _ := netip.AddrFromSlice(num.FillBytes(make([]byte, bitLen/8)))
+	return addr, true
+}
+
+// Returns the address of the specified bit length whose first numOnes bits
+// are set and whose remaining bits are clear
+func ipMaskAddr(numOnes int, bitLen int) netip.Addr {
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(numOnes))
+	mask.Sub(mask, big.NewInt(1))
+	mask.Lsh(mask, uint(bitLen-numOnes))
+	addr, _ := ipAddrFromBig(mask, bitLen)
+	return addr
+}
+
+// Returns the number of leading set bits of the specified netmask, or false
+// if the set bits of the netmask are not contiguous
+func ipMaskPrefix(mask netip.Addr) (int, bool) {
+	num := ipAddrToBig(mask)
+	bitLen := mask.BitLen()
+	numOnes := 0
+	for numOnes < bitLen && num.Bit(bitLen-1-numOnes) == 1 {
+		numOnes++
+	}
+	return numOnes, ipMaskAddr(numOnes, bitLen) == mask
+}
+
+// Returns the last address of the specified prefix
+func ipPrefixLast(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Addr()
+	hostBits := addr.BitLen() - prefix.Bits()
+	hostMask := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+	hostMask.Sub(hostMask, big.NewInt(1))
+	last, _ := ipAddrFromBig(hostMask.Or(hostMask, ipAddrToBig(addr)), addr.BitLen())
+	return last
+}
+
+// Returns the number of addresses in the specified prefix as an integer if
+// it fits in one and as a bigint otherwise
+func ipPrefixSize(prefix netip.Prefix) any {
+	return bigIntOrInt(new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits())))
+}
+
+type LoxIPAddressIterator struct {
+	next netip.Addr
+	last netip.Addr
+	done bool
+}
+
+func (l *LoxIPAddressIterator) HasNext() bool {
+	return !l.done
+}
+
+func (l *LoxIPAddressIterator) Next() any {
+	addr := l.next
+	if addr == l.last {
+		l.done = true
+	} else {
+		l.next = addr.Next()
+	}
+	return NewLoxIPAddress(addr)
+}
+
+// Returns an iterator over the addresses from first to last inclusive,
+// which is empty if first comes after last
+func NewLoxIPAddressIterator(first netip.Addr, last netip.Addr) *LoxIPAddressIterator {
+	return &LoxIPAddressIterator{first, last, first.Compare(last) > 0}
+}
+
+type LoxIPAddress struct {
+	addr    netip.Addr
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxIPAddress(addr netip.Addr) *LoxIPAddress {
+	return &LoxIPAddress{
+		addr:    addr,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Returns the address that is num addresses after the current address
+func (l *LoxIPAddress) add(num *big.Int) (*LoxIPAddress, error) {
+	sum := ipAddrToBig(l.addr)
+	sum.Add(sum, num)
+	addr, ok := ipAddrFromBig(sum, l.addr.BitLen())
+	if !ok {
+		return nil, loxerror.Error(
+			fmt.Sprintf("IP address arithmetic result is out of range for IPv%v addresses.", l.version()))
+	}
+	return NewLoxIPAddress(addr.WithZone(l.addr.Zone())), nil
+}
+
+func (l *LoxIPAddress) version() int64 {
+	if l.addr.Is4() {
+		return 4
+	}
+	return 6
+}
+
+func (l *LoxIPAddress) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxIPAddress:
+		return l.addr == obj.addr
+	default:
+		return false
+	}
+}
+
+func (l *LoxIPAddress) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	ipAddressFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ipaddress fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	predicateFunc := func(predicate func() bool) (*struct{ ProtoLoxCallable }, error) {
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return predicate(), nil
+		})
+	}
+	switch methodName {
+	case "isGlobalUnicast":
+		return predicateFunc(l.addr.IsGlobalUnicast)
+	case "isLinkLocal":
+		return predicateFunc(func() bool {
+			return l.addr.IsLinkLocalUnicast() || l.addr.IsLinkLocalMulticast()
+		})
+	case "isLoopback":
+		return predicateFunc(l.addr.IsLoopback)
+	case "isMulticast":
+		return predicateFunc(l.addr.IsMulticast)
+	case "isPrivate":
+		return predicateFunc(l.addr.IsPrivate)
+	case "isUnspecified":
+		return predicateFunc(l.addr.IsUnspecified)
+	case "next":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			next := l.addr.Next()
+			if !next.IsValid() {
+				return nil, nil
+			}
+			return NewLoxIPAddress(next), nil
+		})
+	case "prev":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			prev := l.addr.Prev()
+			if !prev.IsValid() {
+				return nil, nil
+			}
+			return NewLoxIPAddress(prev), nil
+		})
+	case "reversePointer":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			bytes := l.addr.AsSlice()
+			labels := make([]string, 0, len(bytes)*2+1)
+			for index := len(bytes) - 1; index >= 0; index-- {
+				if l.addr.Is4() {
+					labels = append(labels, fmt.Sprint(bytes[index]))
+				} else {
+					labels = append(labels, fmt.Sprintf("%x", bytes[index]&0xf), fmt.Sprintf("%x", bytes[index]>>4))
+				}
+			}
+			if l.addr.Is4() {
+				labels = append(labels, "in-addr.arpa")
+			} else {
+				labels = append(labels, "ip6.arpa")
+			}
+			return NewLoxStringQuote(strings.Join(labels, ".")), nil
+		})
+	case "toBuffer":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxBufferFromBytes(l.addr.AsSlice()), nil
+		})
+	case "toInt":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return bigIntOrInt(ipAddrToBig(l.addr)), nil
+		})
+	case "toString":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.addr.String()), nil
+		})
+	case "unmap":
+		return ipAddressFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxIPAddress(l.addr.Unmap()), nil
+		})
+	case "version":
+		return l.version(), nil
+	case "zone":
+		return NewLoxStringQuote(l.addr.Zone()), nil
+	}
+	return nil, loxerror.RuntimeError(name, "IP addresses have no property called '"+methodName+"'.")
+}
+
+func (l *LoxIPAddress) String() string {
+	return fmt.Sprintf("<ipaddress: %v>", l.addr)
+}
+
+func (l *LoxIPAddress) Type() string {
+	return "ipaddress"
+}
+
+type LoxIPNetworkIterator struct {
+	next   *big.Int
+	last   *big.Int
+	step   *big.Int
+	bits   int
+	bitLen int
+}
+
+func (l *LoxIPNetworkIterator) HasNext() bool {
+	return l.next.Cmp(l.last) <= 0
+}
+
+func (l *LoxIPNetworkIterator) Next() any {
+	addr, _ := ipAddrFromBig(l.next, l.bitLen)
+	l.next = new(big.Int).Add(l.next, l.step)
+	return NewLoxIPNetwork(netip.PrefixFrom(addr, l.bits))
+}
+
+type LoxIPNetwork struct {
+	prefix  netip.Prefix
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxIPNetwork(prefix netip.Prefix) *LoxIPNetwork {
+	return &LoxIPNetwork{
+		prefix:  prefix,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Reports whether the current network contains the specified network
+func (l *LoxIPNetwork) containsPrefix(other netip.Prefix) bool {
+	return other.Bits() >= l.prefix.Bits() && l.prefix.Contains(other.Addr())
+}
+
+func (l *LoxIPNetwork) version() int64 {
+	if l.prefix.Addr().Is4() {
+		return 4
+	}
+	return 6
+}
+
+func (l *LoxIPNetwork) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxIPNetwork:
+		return l.prefix == obj.prefix
+	default:
+		return false
+	}
+}
+
+func (l *LoxIPNetwork) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	ipNetworkFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ipnetwork fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Returns the new prefix length from the optional argument, which is
+	//defaultBits if omitted and must be between minBits and maxBits
+	prefixArg := func(args list.List[any], defaultBits int, minBits int, maxBits int) (int, error) {
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+			if defaultBits < minBits || defaultBits > maxBits {
+				return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					fmt.Sprintf("Cannot call 'ipnetwork.%v' on network %v.", methodName, l.prefix))
+			}
+			return defaultBits, nil
+		case 1:
+			bits, ok := args[0].(int64)
+			if !ok {
+				return 0, loxerror.RuntimeError(name,
+					fmt.Sprintf("Argument to 'ipnetwork.%v' must be an integer.", methodName))
+			}
+			if bits < int64(minBits) || bits > int64(maxBits) {
+				return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					fmt.Sprintf("Argument to 'ipnetwork.%v' must be between %v and %v.", methodName, minBits, maxBits))
+			}
+			return int(bits), nil
+		}
+		return 0, loxerror.RuntimeError(name,
+			fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+	}
+	bitLen := l.prefix.Addr().BitLen()
+	switch methodName {
+	case "address":
+		return NewLoxIPAddress(l.prefix.Addr()), nil
+	case "broadcast":
+		return NewLoxIPAddress(ipPrefixLast(l.prefix)), nil
+	case "contains":
+		return ipNetworkFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch arg := args[0].(type) {
+			case *LoxIPAddress:
+				return l.prefix.Contains(arg.addr.WithZone("")), nil
+			case *LoxIPNetwork:
+				return l.containsPrefix(arg.prefix), nil
+			case *LoxString:
+				if addr, err := netip.ParseAddr(arg.str); err == nil {
+					return l.prefix.Contains(addr.WithZone("")), nil
+				}
+				if prefix, err := netip.ParsePrefix(arg.str); err == nil {
+					return l.containsPrefix(prefix.Masked()), nil
+				}
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					fmt.Sprintf("'%v' is not a valid IP address or network.", arg.str))
+			}
+			return nil, loxerror.RuntimeError(name,
+				"Argument to 'ipnetwork.contains' must be an IP address, IP network, or string.")
+		})
+	case "hostmask":
+		hostBits := new(big.Int).Lsh(big.NewInt(1), uint(bitLen-l.prefix.Bits()))
+		hostmask, _ := ipAddrFromBig(hostBits.Sub(hostBits, big.NewInt(1)), bitLen)
+		return NewLoxIPAddress(hostmask), nil
+	case "hosts":
+		return ipNetworkFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			first, last := l.prefix.Addr(), ipPrefixLast(l.prefix)
+			//The network and broadcast addresses of IPv4 networks and the
+			//Subnet-Router anycast address of IPv6 networks are not hosts
+			if l.prefix.Addr().Is4() && l.prefix.Bits() <= 30 {
+				first, last = first.Next(), last.Prev()
+			} else if l.prefix.Addr().Is6() && l.prefix.Bits() <= 126 {
+				first = first.Next()
+			}
+			return NewLoxIterator(NewLoxIPAddressIterator(first, last)), nil
+		})
+	case "netmask":
+		return NewLoxIPAddress(ipMaskAddr(l.prefix.Bits(), bitLen)), nil
+	case "numAddresses":
+		return ipPrefixSize(l.prefix), nil
+	case "overlaps":
+		return ipNetworkFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if other, ok := args[0].(*LoxIPNetwork); ok {
+				return l.prefix.Overlaps(other.prefix), nil
+			}
+			return nil, loxerror.RuntimeError(name,
+				"Argument to 'ipnetwork.overlaps' must be an IP network.")
+		})
+	case "prefix":
+		return int64(l.prefix.Bits()), nil
+	case "subnets":
+		return ipNetworkFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			bits, err := prefixArg(args, l.prefix.Bits()+1, l.prefix.Bits(), bitLen)
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxIterator(&LoxIPNetworkIterator{
+				next:   ipAddrToBig(l.prefix.Addr()),
+				last:   ipAddrToBig(ipPrefixLast(l.prefix)),
+				step:   new(big.Int).Lsh(big.NewInt(1), uint(bitLen-bits)),
+				bits:   bits,
+				bitLen: bitLen,
+			}), nil
+		})
+	case "supernet":
+		return ipNetworkFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			bits, err := prefixArg(args, l.prefix.Bits()-1, 0, l.prefix.Bits())
+			if err != nil {
+				return nil, err
+			}
+			supernet, _ := l.prefix.Addr().Prefix(bits)
+			return NewLoxIPNetwork(supernet), nil
+		})
+	case "toString":
+		return ipNetworkFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.prefix.String()), nil
+		})
+	case "version":
+		return l.version(), nil
+	}
+	return nil, loxerror.RuntimeError(name, "IP networks have no property called '"+methodName+"'.")
+}
+
+func (l *LoxIPNetwork) Iterator() interfaces.Iterator {
+	return NewLoxIPAddressIterator(l.prefix.Addr(), ipPrefixLast(l.prefix))
+}
+
+func (l *LoxIPNetwork) String() string {
+	return fmt.Sprintf("<ipnetwork: %v>", l.prefix)
+}
+
+func (l *LoxIPNetwork) Type() string {
+	return "ipnetwork"
+}
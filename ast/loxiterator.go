@@ -97,6 +97,9 @@ func (l *LoxIterator) Get(name *token.Token) (any, error) {
 	case "next":
 		return iteratorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			if !l.HasNext() {
+				if err := l.Err(); err != nil {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
 				return nil, loxerror.RuntimeError(name, "StopIteration")
 			}
 			return l.Next(), nil
@@ -110,6 +113,9 @@ func (l *LoxIterator) Get(name *token.Token) (any, error) {
 				for l.HasNext() {
 					newList.Add(l.Next())
 				}
+				if err := l.Err(); err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
 				return NewLoxList(newList), nil
 			case 1:
 				if length, ok := args[0].(int64); ok {
@@ -124,6 +130,9 @@ func (l *LoxIterator) Get(name *token.Token) (any, error) {
 						}
 						newList.Add(l.Next())
 					}
+					if err := l.Err(); err != nil {
+						return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					}
 					return NewLoxList(newList), nil
 				}
 				return argMustBeTypeAn("integer")
@@ -144,6 +153,10 @@ func (l *LoxIterator) Next() any {
 	return l.iterator.Next()
 }
 
+func (l *LoxIterator) Err() error {
+	return iteratorErr(l.iterator)
+}
+
 func (l *LoxIterator) Iterator() interfaces.Iterator {
 	return l
 }
@@ -0,0 +1,177 @@
+package ast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Converts the specified value decoded by a JSON decoder that uses
+// json.Number into a Lox value
+func jsonToLox(value any) any {
+	switch value := value.(type) {
+	case json.Number:
+		if num, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return num
+		}
+		if num, ok := new(big.Int).SetString(value.String(), 10); ok {
+			return num
+		}
+		//ParseFloat returns an infinity for numbers that are too large
+		num, _ := strconv.ParseFloat(value.String(), 64)
+		return num
+	case string:
+		return NewLoxStringQuote(value)
+	case []any:
+		elements := list.NewListCap[any](int64(len(value)))
+		for _, element := range value {
+			elements.Add(jsonToLox(element))
+		}
+		return NewLoxList(elements)
+	case *jsonObject:
+		keys := make([]any, len(value.keys))
+		values := make([]any, len(value.values))
+		for index, key := range value.keys {
+			keys[index] = NewLoxStringQuote(key)
+			values[index] = jsonToLox(value.values[index])
+		}
+		return NewLoxDictOrdered(keys, values)
+	}
+	return value
+}
+
+// An io.Reader that reads the strings and buffers produced by a Lox iterator
+type jsonIteratorReader struct {
+	iterator interfaces.Iterator
+	pending  []byte
+}
+
+func (r *jsonIteratorReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if !r.iterator.HasNext() {
+			return 0, io.EOF
+		}
+		switch chunk := r.iterator.Next().(type) {
+		case *LoxString:
+			r.pending = []byte(chunk.str)
+		case *LoxBuffer:
+			r.pending = chunk.bytes()
+		default:
+			return 0, fmt.Errorf("iterator passed to 'JSON.stream' produced a value of type '%v' "+
+				"instead of a string or buffer", getType(chunk))
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// A stream of JSON values returned from JSON.stream that decodes the
+// top-level values of its source one at a time
+type LoxJSONStream struct {
+	decoder *json.Decoder
+	next    any
+	err     error
+	done    bool
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxJSONStream(reader io.Reader) *LoxJSONStream {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	stream := &LoxJSONStream{
+		decoder: decoder,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	stream.advance()
+	return stream
+}
+
+// Decodes the next value of the stream so that it is known whether the
+// stream has another value before it is requested
+func (l *LoxJSONStream) advance() {
+	value, err := decodeOrderedJSON(l.decoder)
+	if err != nil {
+		l.done = true
+		l.next = nil
+		if !errors.Is(err, io.EOF) {
+			l.err = err
+		}
+		return
+	}
+	l.next = jsonToLox(value)
+}
+
+func (l *LoxJSONStream) hasNext() bool {
+	return !l.done
+}
+
+func (l *LoxJSONStream) nextValue() any {
+	value := l.next
+	l.advance()
+	return value
+}
+
+func (l *LoxJSONStream) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	streamFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native JSON stream fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "error":
+		if l.err == nil {
+			return nil, nil
+		}
+		return NewLoxStringQuote(l.err.Error()), nil
+	case "hasNext":
+		return streamFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.hasNext(), nil
+		})
+	case "next":
+		return streamFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.hasNext() {
+				if l.err != nil {
+					return nil, loxerror.RuntimeError(name, l.err.Error())
+				}
+				return nil, loxerror.RuntimeError(name, "JSON stream has no more values.")
+			}
+			return l.nextValue(), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "JSON streams have no property called '"+methodName+"'.")
+}
+
+func (l *LoxJSONStream) Iterator() interfaces.Iterator {
+	iterator := ProtoIterator{}
+	iterator.hasNextMethod = l.hasNext
+	iterator.nextMethod = l.nextValue
+	return iterator
+}
+
+func (l *LoxJSONStream) String() string {
+	return fmt.Sprintf("<JSON stream at %p>", l)
+}
+
+func (l *LoxJSONStream) Type() string {
+	return "JSON stream"
+}
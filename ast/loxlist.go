@@ -3,7 +3,6 @@ package ast
 import (
 	"fmt"
 	"math/rand"
-	"reflect"
 	"slices"
 	"strings"
 
@@ -36,8 +35,12 @@ func ListIndexOutOfRange(index int64) string {
 	return fmt.Sprintf("List index %v out of range.", index)
 }
 
+// Frozen lists can't be modified, so they can be used as dictionary keys
+// and set elements. The key field caches the key of a frozen list
 type LoxList struct {
 	elements list.List[any]
+	frozen   bool
+	key      any
 	methods  map[string]*struct{ ProtoLoxCallable }
 }
 
@@ -71,13 +74,20 @@ func (l *LoxList) Capacity() int64 {
 	return int64(cap(l.elements))
 }
 
-func (l *LoxList) Equals(obj any) bool {
-	switch obj := obj.(type) {
-	case *LoxList:
-		return reflect.DeepEqual(l.elements, obj.elements)
-	default:
+func (l *LoxList) equals(other *LoxList, compared map[valuesEqualPair]bool) bool {
+	if len(l.elements) != len(other.elements) {
 		return false
 	}
+	for i, element := range l.elements {
+		if !valuesEqualCompared(element, other.elements[i], compared) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LoxList) Equals(obj any) bool {
+	return valuesEqual(l, obj)
 }
 
 func (l *LoxList) Get(name *token.Token) (any, error) {
@@ -150,6 +160,13 @@ func (l *LoxList) Get(name *token.Token) (any, error) {
 		errStr := fmt.Sprintf("Argument to 'list.%v' must be a %v.", methodName, theType)
 		return nil, loxerror.RuntimeError(name, errStr)
 	}
+	if l.frozen {
+		switch methodName {
+		case "append", "clear", "extend", "insert", "pop", "remove",
+			"removeAll", "removeAllList", "reverse", "shuffle", "sort":
+			return nil, loxerror.RuntimeError(name, frozenMethodMsg("list", methodName))
+		}
+	}
 	switch methodName {
 	case "all":
 		return listFunc(1, func(i *Interpreter, args list.List[any]) (any, error) {
@@ -354,6 +371,10 @@ func (l *LoxList) Get(name *token.Token) (any, error) {
 			}
 			return NewLoxList(newList), nil
 		})
+	case "freeze":
+		return listFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.freeze(), nil
+		})
 	case "forEach":
 		return listFunc(1, func(i *Interpreter, args list.List[any]) (any, error) {
 			if callback, ok := args[0].(*LoxFunction); ok {
@@ -384,12 +405,16 @@ func (l *LoxList) Get(name *token.Token) (any, error) {
 					index += int64(len(l.elements))
 				}
 				if index < 0 || index > int64(len(l.elements)) {
-					return nil, loxerror.RuntimeError(name, ListIndexOutOfRange(originalIndex))
+					return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name, ListIndexOutOfRange(originalIndex))
 				}
 				l.elements.AddAt(index, args[1])
 				return nil, nil
 			}
-			return nil, loxerror.RuntimeError(name, ListIndexMustBeWholeNum(args[0]))
+			return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, name, ListIndexMustBeWholeNum(args[0]))
+		})
+	case "isFrozen":
+		return listFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.frozen, nil
 		})
 	case "isEmpty":
 		return listFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
@@ -472,11 +497,11 @@ func (l *LoxList) Get(name *token.Token) (any, error) {
 						index += int64(len(l.elements))
 					}
 					if index < 0 || index >= int64(len(l.elements)) {
-						return nil, loxerror.RuntimeError(name, ListIndexOutOfRange(originalIndex))
+						return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name, ListIndexOutOfRange(originalIndex))
 					}
 					return l.elements.RemoveIndex(index), nil
 				}
-				return nil, loxerror.RuntimeError(name, ListIndexMustBeWholeNum(args[0]))
+				return nil, loxerror.RuntimeErrorKind(loxerror.TypeError, name, ListIndexMustBeWholeNum(args[0]))
 			}
 			return nil, loxerror.RuntimeError(name, fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
 		})
@@ -790,7 +815,7 @@ func (l *LoxList) Get(name *token.Token) (any, error) {
 					newIndex += int64(len(l.elements))
 				}
 				if newIndex < 0 || newIndex >= int64(len(l.elements)) {
-					return nil, loxerror.RuntimeError(name, ListIndexOutOfRange(originalNewIndex))
+					return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name, ListIndexOutOfRange(originalNewIndex))
 				}
 				newElement := args[1]
 				newList := list.NewListCap[any](int64(len(l.elements)))
@@ -0,0 +1,260 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const (
+	logLevelDebug    = 10
+	logLevelInfo     = 20
+	logLevelWarning  = 30
+	logLevelError    = 40
+	logLevelCritical = 50
+)
+
+var logLevelNames = map[int64]string{
+	logLevelDebug:    "DEBUG",
+	logLevelInfo:     "INFO",
+	logLevelWarning:  "WARNING",
+	logLevelError:    "ERROR",
+	logLevelCritical: "CRITICAL",
+}
+
+func logLevelName(level int64) string {
+	if name, ok := logLevelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL %v", level)
+}
+
+func logLevelArg(value any) (int64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return value, true
+	case *LoxString:
+		upper := strings.ToUpper(value.str)
+		if upper == "WARN" {
+			return logLevelWarning, true
+		}
+		for level, name := range logLevelNames {
+			if name == upper {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+type logRecord struct {
+	time    time.Time
+	level   int64
+	name    string
+	message string
+	fields  *LoxDict //Nil if the record has no fields
+}
+
+func (r *logRecord) sortedFields() [][2]any {
+	if r.fields == nil {
+		return nil
+	}
+	pairs := make([][2]any, 0, len(r.fields.indexes))
+	it := r.fields.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		pairs = append(pairs, [2]any{getResult(pair[0], pair[0], true), pair[1]})
+	}
+	sort.Slice(pairs, func(a int, b int) bool {
+		return pairs[a][0].(string) < pairs[b][0].(string)
+	})
+	return pairs
+}
+
+func (r *logRecord) toDict() *LoxDict {
+	dict := EmptyLoxDict()
+	dict.setKeyValue(NewLoxStringQuote("level"), r.level)
+	dict.setKeyValue(NewLoxStringQuote("levelName"), NewLoxStringQuote(logLevelName(r.level)))
+	dict.setKeyValue(NewLoxStringQuote("message"), NewLoxStringQuote(r.message))
+	dict.setKeyValue(NewLoxStringQuote("name"), NewLoxStringQuote(r.name))
+	dict.setKeyValue(NewLoxStringQuote("time"), NewLoxDate(r.time))
+	fields := r.fields
+	if fields == nil {
+		fields = EmptyLoxDict()
+	}
+	dict.setKeyValue(NewLoxStringQuote("fields"), fields)
+	return dict
+}
+
+const (
+	logTextFormat     = "{time} [{level}] {name}: {message}"
+	logTextTimeFormat = "2006-01-02 15:04:05.000"
+)
+
+type LoxLogFormatter struct {
+	kind       string
+	template   string
+	timeFormat string
+	fn         *LoxFunction
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxLogTextFormatter(template string, timeFormat string) *LoxLogFormatter {
+	return &LoxLogFormatter{
+		kind:       "text",
+		template:   template,
+		timeFormat: timeFormat,
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func NewLoxLogJSONFormatter(timeFormat string) *LoxLogFormatter {
+	return &LoxLogFormatter{
+		kind:       "json",
+		timeFormat: timeFormat,
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func NewLoxLogFuncFormatter(fn *LoxFunction) *LoxLogFormatter {
+	return &LoxLogFormatter{
+		kind:    "function",
+		fn:      fn,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func logTextValue(value any) string {
+	if loxStr, ok := value.(*LoxString); ok {
+		if loxStr.str == "" || strings.ContainsAny(loxStr.str, " \t\n\"=") {
+			return strconv.Quote(loxStr.str)
+		}
+		return loxStr.str
+	}
+	return getResult(value, value, true)
+}
+
+func logJSONValue(value any) any {
+	goValue := FromLoxValue(value)
+	if _, err := json.Marshal(goValue); err != nil {
+		return getResult(value, value, true)
+	}
+	return goValue
+}
+
+func (l *LoxLogFormatter) format(in *Interpreter, record *logRecord) (string, error) {
+	switch l.kind {
+	case "json":
+		entry := map[string]any{}
+		for _, pair := range record.sortedFields() {
+			entry[pair[0].(string)] = logJSONValue(pair[1])
+		}
+		entry["time"] = record.time.Format(l.timeFormat)
+		entry["level"] = logLevelName(record.level)
+		entry["logger"] = record.name
+		entry["message"] = record.message
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return "", err
+		}
+		return string(line), nil
+	case "function":
+		argList := getArgList(l.fn, 1)
+		argList[0] = record.toDict()
+		result, err := l.fn.call(in, argList)
+		if resultReturn, ok := result.(Return); ok {
+			result = resultReturn.FinalValue
+		} else if err != nil {
+			return "", err
+		}
+		return getResult(result, result, true), nil
+	}
+	var fieldsBuilder strings.Builder
+	for _, pair := range record.sortedFields() {
+		fieldsBuilder.WriteString(" " + pair[0].(string) + "=" + logTextValue(pair[1]))
+	}
+	fieldsStr := strings.TrimPrefix(fieldsBuilder.String(), " ")
+	line := strings.NewReplacer(
+		"{time}", record.time.Format(l.timeFormat),
+		"{level}", logLevelName(record.level),
+		"{name}", record.name,
+		"{message}", record.message,
+		"{fields}", fieldsStr,
+	).Replace(l.template)
+	//Fields are appended to the line if the template doesn't include them
+	if !strings.Contains(l.template, "{fields}") && fieldsStr != "" {
+		line += " " + fieldsStr
+	}
+	return line, nil
+}
+
+func (l *LoxLogFormatter) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	formatterFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native log formatter fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "format":
+		return formatterFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 2 && argsLen != 3 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+			}
+			level, ok := logLevelArg(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'log formatter.format' must be a level integer or level name.")
+			}
+			message, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'log formatter.format' must be a string.")
+			}
+			record := &logRecord{time: time.Now(), level: level, name: "root", message: message.str}
+			if argsLen == 3 {
+				fields, ok := args[2].(*LoxDict)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Third argument to 'log formatter.format' must be a dictionary.")
+				}
+				record.fields = fields
+			}
+			line, err := l.format(in, record)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxStringQuote(line), nil
+		})
+	case "kind":
+		return NewLoxStringQuote(l.kind), nil
+	}
+	return nil, loxerror.RuntimeError(name, "Log formatters have no property called '"+methodName+"'.")
+}
+
+func (l *LoxLogFormatter) String() string {
+	return fmt.Sprintf("<%v log formatter at %p>", l.kind, l)
+}
+
+func (l *LoxLogFormatter) Type() string {
+	return "log formatter"
+}
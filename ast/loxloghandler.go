@@ -0,0 +1,314 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type logOutput interface {
+	write(level int64, line string) error
+	close() error
+}
+
+type logStreamOutput struct {
+	writer io.Writer
+}
+
+func (o *logStreamOutput) write(_ int64, line string) error {
+	_, err := io.WriteString(o.writer, line+"\n")
+	return err
+}
+
+func (o *logStreamOutput) close() error {
+	//Streams are owned by whoever opened them
+	return nil
+}
+
+var logRotationUnits = map[string]struct {
+	suffix   string
+	duration time.Duration
+}{
+	"S":        {"2006-01-02_15-04-05", time.Second},
+	"M":        {"2006-01-02_15-04", time.Minute},
+	"H":        {"2006-01-02_15", time.Hour},
+	"D":        {"2006-01-02", 24 * time.Hour},
+	"midnight": {"2006-01-02", 24 * time.Hour},
+}
+
+type logFileOutput struct {
+	path        string
+	file        *os.File
+	size        int64
+	maxBytes    int64  //Zero if files aren't rotated by size
+	when        string //Empty if files aren't rotated by time
+	interval    int64
+	backupCount int64 //Zero if all rotated files are kept
+	rolloverAt  time.Time
+}
+
+func newLogFileOutput(path string, maxBytes int64, when string, interval int64, backupCount int64) (*logFileOutput, error) {
+	o := &logFileOutput{
+		path:        path,
+		maxBytes:    maxBytes,
+		when:        when,
+		interval:    interval,
+		backupCount: backupCount,
+	}
+	if err := o.open(); err != nil {
+		return nil, err
+	}
+	if when != "" {
+		o.rolloverAt = o.nextRollover(time.Now())
+	}
+	return o, nil
+}
+
+func (o *logFileOutput) open() error {
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	o.file = file
+	o.size = info.Size()
+	return nil
+}
+
+func (o *logFileOutput) nextRollover(now time.Time) time.Time {
+	if o.when == "midnight" {
+		year, month, day := now.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, now.Location()).AddDate(0, 0, int(o.interval))
+	}
+	return now.Add(time.Duration(o.interval) * logRotationUnits[o.when].duration)
+}
+
+func (o *logFileOutput) rotate(now time.Time) error {
+	if err := o.file.Close(); err != nil {
+		return err
+	}
+	if o.when != "" {
+		//The timestamp is the start of the period that the file covers
+		periodStart := o.rolloverAt.Add(-time.Duration(o.interval) * logRotationUnits[o.when].duration)
+		rotatedPath := o.path + "." + periodStart.Format(logRotationUnits[o.when].suffix)
+		if err := os.Rename(o.path, rotatedPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if o.backupCount > 0 {
+			matches, _ := filepath.Glob(o.path + ".*")
+			sort.Strings(matches)
+			for len(matches) > int(o.backupCount) {
+				os.Remove(matches[0])
+				matches = matches[1:]
+			}
+		}
+		for !o.rolloverAt.After(now) {
+			o.rolloverAt = o.nextRollover(o.rolloverAt)
+		}
+	} else if o.backupCount > 0 {
+		for index := o.backupCount - 1; index > 0; index-- {
+			source := fmt.Sprintf("%v.%v", o.path, index)
+			if _, err := os.Stat(source); err == nil {
+				os.Rename(source, fmt.Sprintf("%v.%v", o.path, index+1))
+			}
+		}
+		if err := os.Rename(o.path, o.path+".1"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err := os.Truncate(o.path, 0); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return o.open()
+}
+
+func (o *logFileOutput) write(_ int64, line string) error {
+	if o.file == nil {
+		return os.ErrClosed
+	}
+	line += "\n"
+	now := time.Now()
+	shouldRotate := o.when != "" && !now.Before(o.rolloverAt)
+	if o.maxBytes > 0 && o.size > 0 && o.size+int64(len(line)) > o.maxBytes {
+		shouldRotate = true
+	}
+	if shouldRotate {
+		if err := o.rotate(now); err != nil {
+			return err
+		}
+	}
+	n, err := o.file.WriteString(line)
+	o.size += int64(n)
+	return err
+}
+
+func (o *logFileOutput) close() error {
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+type LoxLogHandler struct {
+	mutex     sync.Mutex
+	kind      string
+	level     int64
+	formatter *LoxLogFormatter
+	output    logOutput
+	fn        *LoxFunction //Called with each record instead of writing to output
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxLogHandler(kind string, output logOutput) *LoxLogHandler {
+	return &LoxLogHandler{
+		kind:      kind,
+		formatter: NewLoxLogTextFormatter(logTextFormat, logTextTimeFormat),
+		output:    output,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func NewLoxLogFuncHandler(fn *LoxFunction) *LoxLogHandler {
+	handler := NewLoxLogHandler("function", nil)
+	handler.fn = fn
+	return handler
+}
+
+func (l *LoxLogHandler) handle(in *Interpreter, record *logRecord) error {
+	//The lock isn't held while Lox functions are called so that they can log
+	l.mutex.Lock()
+	level, formatter := l.level, l.formatter
+	l.mutex.Unlock()
+	if record.level < level {
+		return nil
+	}
+	line, err := formatter.format(in, record)
+	if err != nil {
+		return err
+	}
+	if l.fn != nil {
+		dict := record.toDict()
+		dict.setKeyValue(NewLoxStringQuote("formatted"), NewLoxStringQuote(line))
+		argList := getArgList(l.fn, 1)
+		argList[0] = dict
+		result, err := l.fn.call(in, argList)
+		if _, ok := result.(Return); !ok && err != nil {
+			return err
+		}
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.output.write(record.level, line)
+}
+
+func (l *LoxLogHandler) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	handlerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native log handler fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "close":
+		return handlerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			if l.output == nil {
+				return nil, nil
+			}
+			if err := l.output.close(); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "formatter":
+		return handlerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.formatter, nil
+		})
+	case "kind":
+		return NewLoxStringQuote(l.kind), nil
+	case "level":
+		return handlerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.level, nil
+		})
+	case "setFormatter":
+		return handlerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			formatter, ok := logFormatterArg(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'log handler.setFormatter' must be a log formatter, function, 'text', or 'json'.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.formatter = formatter
+			return nil, nil
+		})
+	case "setLevel":
+		return handlerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			level, ok := logLevelArg(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'log handler.setLevel' must be a level integer or level name.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.level = level
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Log handlers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxLogHandler) String() string {
+	return fmt.Sprintf("<%v log handler at %p>", l.kind, l)
+}
+
+func (l *LoxLogHandler) Type() string {
+	return "log handler"
+}
+
+func logFormatterArg(value any) (*LoxLogFormatter, bool) {
+	switch value := value.(type) {
+	case *LoxLogFormatter:
+		return value, true
+	case *LoxFunction:
+		return NewLoxLogFuncFormatter(value), true
+	case *LoxString:
+		switch strings.ToLower(value.str) {
+		case "text":
+			return NewLoxLogTextFormatter(logTextFormat, logTextTimeFormat), true
+		case "json":
+			return NewLoxLogJSONFormatter(time.RFC3339Nano), true
+		}
+	}
+	return nil, false
+}
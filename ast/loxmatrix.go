@@ -0,0 +1,725 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Pivots with an absolute value at most this are treated as zero when
+// checking whether a matrix is singular and when computing its rank
+const matrixEpsilon = 1e-12
+
+var errMatrixSingular = errors.New("Matrix is singular.")
+
+func matrixNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// A dense matrix of floats stored in row-major order
+type LoxMatrix struct {
+	rows    int
+	cols    int
+	data    []float64
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxMatrix(rows int, cols int) *LoxMatrix {
+	return &LoxMatrix{
+		rows:    rows,
+		cols:    cols,
+		data:    make([]float64, rows*cols),
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func NewLoxMatrixIdentity(n int) *LoxMatrix {
+	matrix := NewLoxMatrix(n, n)
+	for i := 0; i < n; i++ {
+		matrix.data[i*n+i] = 1
+	}
+	return matrix
+}
+
+// Returns the matrix with the rows of the specified list, which must all be
+// lists of numbers with the same length
+func matrixFromList(loxList *LoxList) (*LoxMatrix, error) {
+	rows := len(loxList.elements)
+	if rows == 0 {
+		return nil, errors.New("Matrix must have at least one row.")
+	}
+	var matrix *LoxMatrix
+	for i, element := range loxList.elements {
+		row, ok := element.(*LoxList)
+		if !ok {
+			return nil, errors.New("Matrix rows must be lists.")
+		}
+		if matrix == nil {
+			if len(row.elements) == 0 {
+				return nil, errors.New("Matrix must have at least one column.")
+			}
+			matrix = NewLoxMatrix(rows, len(row.elements))
+		} else if len(row.elements) != matrix.cols {
+			return nil, errors.New("Matrix rows must all have the same length.")
+		}
+		for j, value := range row.elements {
+			num, ok := matrixNumber(value)
+			if !ok {
+				return nil, errors.New("Matrix elements must be integers or floats.")
+			}
+			matrix.data[i*matrix.cols+j] = num
+		}
+	}
+	return matrix, nil
+}
+
+// Returns a column vector with the elements of the specified list
+func matrixVectorFromList(loxList *LoxList) (*LoxMatrix, error) {
+	if len(loxList.elements) == 0 {
+		return nil, errors.New("Vector must have at least one element.")
+	}
+	matrix := NewLoxMatrix(len(loxList.elements), 1)
+	for i, value := range loxList.elements {
+		num, ok := matrixNumber(value)
+		if !ok {
+			return nil, errors.New("Vector elements must be integers or floats.")
+		}
+		matrix.data[i] = num
+	}
+	return matrix, nil
+}
+
+func floatsToLoxList(floats []float64) *LoxList {
+	elements := list.NewListCap[any](int64(len(floats)))
+	for _, f := range floats {
+		elements.Add(f)
+	}
+	return NewLoxList(elements)
+}
+
+func (l *LoxMatrix) copy() *LoxMatrix {
+	matrix := NewLoxMatrix(l.rows, l.cols)
+	copy(matrix.data, l.data)
+	return matrix
+}
+
+func (l *LoxMatrix) shapeStr() string {
+	return fmt.Sprintf("%vx%v", l.rows, l.cols)
+}
+
+func (l *LoxMatrix) sameShape(other *LoxMatrix) error {
+	if l.rows != other.rows || l.cols != other.cols {
+		return fmt.Errorf("Matrix dimensions %v and %v do not match.", l.shapeStr(), other.shapeStr())
+	}
+	return nil
+}
+
+func (l *LoxMatrix) isVector() bool {
+	return l.rows == 1 || l.cols == 1
+}
+
+func (l *LoxMatrix) requireSquare(operation string) error {
+	if l.rows != l.cols {
+		return fmt.Errorf("Cannot %v non-square %v matrix.", operation, l.shapeStr())
+	}
+	return nil
+}
+
+// Returns the result of applying fn to each pair of corresponding elements
+func (l *LoxMatrix) zipWith(other *LoxMatrix, fn func(a float64, b float64) float64) (*LoxMatrix, error) {
+	if err := l.sameShape(other); err != nil {
+		return nil, err
+	}
+	matrix := NewLoxMatrix(l.rows, l.cols)
+	for i, value := range l.data {
+		matrix.data[i] = fn(value, other.data[i])
+	}
+	return matrix, nil
+}
+
+func (l *LoxMatrix) mapValues(fn func(value float64) float64) *LoxMatrix {
+	matrix := NewLoxMatrix(l.rows, l.cols)
+	for i, value := range l.data {
+		matrix.data[i] = fn(value)
+	}
+	return matrix
+}
+
+func (l *LoxMatrix) matmul(other *LoxMatrix) (*LoxMatrix, error) {
+	if l.cols != other.rows {
+		return nil, fmt.Errorf("Cannot multiply %v matrix by %v matrix.", l.shapeStr(), other.shapeStr())
+	}
+	matrix := NewLoxMatrix(l.rows, other.cols)
+	for i := 0; i < l.rows; i++ {
+		for k := 0; k < l.cols; k++ {
+			a := l.data[i*l.cols+k]
+			if a == 0 {
+				continue
+			}
+			for j := 0; j < other.cols; j++ {
+				matrix.data[i*other.cols+j] += a * other.data[k*other.cols+j]
+			}
+		}
+	}
+	return matrix, nil
+}
+
+func (l *LoxMatrix) transpose() *LoxMatrix {
+	matrix := NewLoxMatrix(l.cols, l.rows)
+	for i := 0; i < l.rows; i++ {
+		for j := 0; j < l.cols; j++ {
+			matrix.data[j*l.rows+i] = l.data[i*l.cols+j]
+		}
+	}
+	return matrix
+}
+
+// Computes the LU decomposition of a square matrix with partial pivoting,
+// returning the combined LU matrix, the row permutation, and the sign of
+// the permutation. If the matrix is singular, the returned sign is 0
+func (l *LoxMatrix) lu() ([]float64, []int, float64) {
+	n := l.rows
+	lu := make([]float64, len(l.data))
+	copy(lu, l.data)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sign := 1.0
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i*n+k]) > math.Abs(lu[pivot*n+k]) {
+				pivot = i
+			}
+		}
+		if math.Abs(lu[pivot*n+k]) <= matrixEpsilon {
+			return lu, perm, 0
+		}
+		if pivot != k {
+			for j := 0; j < n; j++ {
+				lu[k*n+j], lu[pivot*n+j] = lu[pivot*n+j], lu[k*n+j]
+			}
+			perm[k], perm[pivot] = perm[pivot], perm[k]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			factor := lu[i*n+k] / lu[k*n+k]
+			lu[i*n+k] = factor
+			for j := k + 1; j < n; j++ {
+				lu[i*n+j] -= factor * lu[k*n+j]
+			}
+		}
+	}
+	return lu, perm, sign
+}
+
+func (l *LoxMatrix) det() (float64, error) {
+	if err := l.requireSquare("take the determinant of"); err != nil {
+		return 0, err
+	}
+	lu, _, sign := l.lu()
+	if sign == 0 {
+		return 0, nil
+	}
+	det := sign
+	for i := 0; i < l.rows; i++ {
+		det *= lu[i*l.rows+i]
+	}
+	return det, nil
+}
+
+// Solves the system l * x = b, where l is square and b has the same number
+// of rows as l
+func (l *LoxMatrix) solve(b *LoxMatrix) (*LoxMatrix, error) {
+	if err := l.requireSquare("solve a system with"); err != nil {
+		return nil, err
+	}
+	if b.rows != l.rows {
+		return nil, fmt.Errorf("Cannot solve a system with %v matrix and %v right-hand side.",
+			l.shapeStr(), b.shapeStr())
+	}
+	n := l.rows
+	lu, perm, sign := l.lu()
+	if sign == 0 {
+		return nil, errMatrixSingular
+	}
+	x := NewLoxMatrix(n, b.cols)
+	for col := 0; col < b.cols; col++ {
+		//Forward substitution with the unit lower triangular matrix
+		for i := 0; i < n; i++ {
+			sum := b.data[perm[i]*b.cols+col]
+			for k := 0; k < i; k++ {
+				sum -= lu[i*n+k] * x.data[k*b.cols+col]
+			}
+			x.data[i*b.cols+col] = sum
+		}
+		//Back substitution with the upper triangular matrix
+		for i := n - 1; i >= 0; i-- {
+			sum := x.data[i*b.cols+col]
+			for k := i + 1; k < n; k++ {
+				sum -= lu[i*n+k] * x.data[k*b.cols+col]
+			}
+			x.data[i*b.cols+col] = sum / lu[i*n+i]
+		}
+	}
+	return x, nil
+}
+
+func (l *LoxMatrix) inverse() (*LoxMatrix, error) {
+	if err := l.requireSquare("invert"); err != nil {
+		return nil, err
+	}
+	return l.solve(NewLoxMatrixIdentity(l.rows))
+}
+
+// Raises a square matrix to an integer power by repeated squaring
+func (l *LoxMatrix) pow(exponent int64) (*LoxMatrix, error) {
+	if err := l.requireSquare("exponentiate"); err != nil {
+		return nil, err
+	}
+	base := l
+	if exponent < 0 {
+		var err error
+		base, err = l.inverse()
+		if err != nil {
+			return nil, err
+		}
+		exponent = -exponent
+	}
+	result := NewLoxMatrixIdentity(l.rows)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			result, _ = result.matmul(base)
+		}
+		base, _ = base.matmul(base)
+		exponent >>= 1
+	}
+	return result, nil
+}
+
+func (l *LoxMatrix) rank() int64 {
+	data := make([]float64, len(l.data))
+	copy(data, l.data)
+	rank := 0
+	for col := 0; col < l.cols && rank < l.rows; col++ {
+		pivot := rank
+		for i := rank + 1; i < l.rows; i++ {
+			if math.Abs(data[i*l.cols+col]) > math.Abs(data[pivot*l.cols+col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(data[pivot*l.cols+col]) <= matrixEpsilon {
+			continue
+		}
+		for j := 0; j < l.cols; j++ {
+			data[rank*l.cols+j], data[pivot*l.cols+j] = data[pivot*l.cols+j], data[rank*l.cols+j]
+		}
+		for i := rank + 1; i < l.rows; i++ {
+			factor := data[i*l.cols+col] / data[rank*l.cols+col]
+			for j := col; j < l.cols; j++ {
+				data[i*l.cols+j] -= factor * data[rank*l.cols+j]
+			}
+		}
+		rank++
+	}
+	return int64(rank)
+}
+
+func (l *LoxMatrix) row(i int) []float64 {
+	return l.data[i*l.cols : (i+1)*l.cols]
+}
+
+func (l *LoxMatrix) toLoxList() *LoxList {
+	rows := list.NewListCap[any](int64(l.rows))
+	for i := 0; i < l.rows; i++ {
+		rows.Add(floatsToLoxList(l.row(i)))
+	}
+	return NewLoxList(rows)
+}
+
+func (l *LoxMatrix) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxMatrix:
+		if l.rows != obj.rows || l.cols != obj.cols {
+			return false
+		}
+		for i, value := range l.data {
+			if value != obj.data[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *LoxMatrix) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	matrixFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native matrix fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'matrix.%v' must be %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	valueError := func(err error) (any, error) {
+		return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name, err.Error())
+	}
+	//Returns the index arguments, checking that they are within bounds
+	indexArgs := func(args list.List[any]) (int, int, error) {
+		row, ok := args[0].(int64)
+		if !ok {
+			return 0, 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("First argument to 'matrix.%v' must be an integer.", methodName))
+		}
+		col, ok := args[1].(int64)
+		if !ok {
+			return 0, 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("Second argument to 'matrix.%v' must be an integer.", methodName))
+		}
+		if row < 0 || row >= int64(l.rows) || col < 0 || col >= int64(l.cols) {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+				fmt.Sprintf("Index (%v, %v) out of range for %v matrix.", row, col, l.shapeStr()))
+		}
+		return int(row), int(col), nil
+	}
+	//Applies fn element-wise with either a matrix of the same shape or a number
+	elementwise := func(fn func(a float64, b float64) float64) (*struct{ ProtoLoxCallable }, error) {
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch arg := args[0].(type) {
+			case *LoxMatrix:
+				result, err := l.zipWith(arg, fn)
+				if err != nil {
+					return valueError(err)
+				}
+				return result, nil
+			case int64, float64:
+				num, _ := matrixNumber(arg)
+				return l.mapValues(func(value float64) float64 {
+					return fn(value, num)
+				}), nil
+			}
+			return argMustBeType("a matrix, integer, or float")
+		})
+	}
+	switch methodName {
+	case "add":
+		return elementwise(func(a float64, b float64) float64 { return a + b })
+	case "col":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			col, ok := args[0].(int64)
+			if !ok {
+				return argMustBeType("an integer")
+			}
+			if col < 0 || col >= int64(l.cols) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+					fmt.Sprintf("Column %v out of range for %v matrix.", col, l.shapeStr()))
+			}
+			values := make([]float64, l.rows)
+			for i := range values {
+				values[i] = l.data[i*l.cols+int(col)]
+			}
+			return floatsToLoxList(values), nil
+		})
+	case "cols":
+		return int64(l.cols), nil
+	case "copy":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.copy(), nil
+		})
+	case "cross":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			other, ok := args[0].(*LoxMatrix)
+			if !ok {
+				return argMustBeType("a matrix")
+			}
+			if !l.isVector() || len(l.data) != 3 || !other.isVector() || len(other.data) != 3 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Cross product is only defined for vectors with 3 elements.")
+			}
+			a, b := l.data, other.data
+			result := NewLoxMatrix(l.rows, l.cols)
+			result.data[0] = a[1]*b[2] - a[2]*b[1]
+			result.data[1] = a[2]*b[0] - a[0]*b[2]
+			result.data[2] = a[0]*b[1] - a[1]*b[0]
+			return result, nil
+		})
+	case "det":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			det, err := l.det()
+			if err != nil {
+				return valueError(err)
+			}
+			return det, nil
+		})
+	case "div":
+		return elementwise(func(a float64, b float64) float64 { return a / b })
+	case "dot":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			other, ok := args[0].(*LoxMatrix)
+			if !ok {
+				return argMustBeType("a matrix")
+			}
+			if len(l.data) != len(other.data) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					fmt.Sprintf("Cannot take dot product of %v and %v matrices.", l.shapeStr(), other.shapeStr()))
+			}
+			sum := 0.0
+			for i, value := range l.data {
+				sum += value * other.data[i]
+			}
+			return sum, nil
+		})
+	case "get":
+		return matrixFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			row, col, err := indexArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return l.data[row*l.cols+col], nil
+		})
+	case "inverse":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			result, err := l.inverse()
+			if err != nil {
+				return valueError(err)
+			}
+			return result, nil
+		})
+	case "isSquare":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.rows == l.cols, nil
+		})
+	case "map":
+		return matrixFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			callback, ok := args[0].(*LoxFunction)
+			if !ok {
+				return argMustBeType("a function")
+			}
+			result := NewLoxMatrix(l.rows, l.cols)
+			argList := getArgList(callback, 3)
+			for i, value := range l.data {
+				argList[0] = value
+				argList[1] = int64(i / l.cols)
+				argList[2] = int64(i % l.cols)
+				mapped, mappedErr := callback.call(in, argList)
+				if mappedReturn, ok := mapped.(Return); ok {
+					mapped = mappedReturn.FinalValue
+				} else if mappedErr != nil {
+					return nil, mappedErr
+				}
+				num, ok := matrixNumber(mapped)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Callback in 'matrix.map' must return an integer or float.")
+				}
+				result.data[i] = num
+			}
+			return result, nil
+		})
+	case "matmul":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			other, ok := args[0].(*LoxMatrix)
+			if !ok {
+				return argMustBeType("a matrix")
+			}
+			result, err := l.matmul(other)
+			if err != nil {
+				return valueError(err)
+			}
+			return result, nil
+		})
+	case "max", "min":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			result := l.data[0]
+			for _, value := range l.data[1:] {
+				if (methodName == "max" && value > result) || (methodName == "min" && value < result) {
+					result = value
+				}
+			}
+			return result, nil
+		})
+	case "mul":
+		return elementwise(func(a float64, b float64) float64 { return a * b })
+	case "norm":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			sum := 0.0
+			for _, value := range l.data {
+				sum += value * value
+			}
+			return math.Sqrt(sum), nil
+		})
+	case "pow":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			exponent, ok := args[0].(int64)
+			if !ok {
+				return argMustBeType("an integer")
+			}
+			result, err := l.pow(exponent)
+			if err != nil {
+				return valueError(err)
+			}
+			return result, nil
+		})
+	case "rank":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.rank(), nil
+		})
+	case "reshape":
+		return matrixFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			rows, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'matrix.reshape' must be an integer.")
+			}
+			cols, ok := args[1].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'matrix.reshape' must be an integer.")
+			}
+			if rows < 1 || cols < 1 || rows > int64(len(l.data))/cols || rows*cols != int64(len(l.data)) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					fmt.Sprintf("Cannot reshape %v matrix to %vx%v.", l.shapeStr(), rows, cols))
+			}
+			result := NewLoxMatrix(int(rows), int(cols))
+			copy(result.data, l.data)
+			return result, nil
+		})
+	case "row":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			row, ok := args[0].(int64)
+			if !ok {
+				return argMustBeType("an integer")
+			}
+			if row < 0 || row >= int64(l.rows) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+					fmt.Sprintf("Row %v out of range for %v matrix.", row, l.shapeStr()))
+			}
+			return floatsToLoxList(l.row(int(row))), nil
+		})
+	case "rows":
+		return int64(l.rows), nil
+	case "set":
+		return matrixFunc(3, func(_ *Interpreter, args list.List[any]) (any, error) {
+			row, col, err := indexArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			num, ok := matrixNumber(args[2])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Third argument to 'matrix.set' must be an integer or float.")
+			}
+			l.data[row*l.cols+col] = num
+			return nil, nil
+		})
+	case "shape":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			shape := list.NewListCap[any](2)
+			shape.Add(int64(l.rows))
+			shape.Add(int64(l.cols))
+			return NewLoxList(shape), nil
+		})
+	case "solve":
+		return matrixFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch arg := args[0].(type) {
+			case *LoxMatrix:
+				result, err := l.solve(arg)
+				if err != nil {
+					return valueError(err)
+				}
+				return result, nil
+			case *LoxList:
+				b, err := matrixVectorFromList(arg)
+				if err != nil {
+					return valueError(err)
+				}
+				result, err := l.solve(b)
+				if err != nil {
+					return valueError(err)
+				}
+				return floatsToLoxList(result.data), nil
+			}
+			return argMustBeType("a matrix or list")
+		})
+	case "sub":
+		return elementwise(func(a float64, b float64) float64 { return a - b })
+	case "sum":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			sum := 0.0
+			for _, value := range l.data {
+				sum += value
+			}
+			return sum, nil
+		})
+	case "toList":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.toLoxList(), nil
+		})
+	case "trace":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.requireSquare("take the trace of"); err != nil {
+				return valueError(err)
+			}
+			trace := 0.0
+			for i := 0; i < l.rows; i++ {
+				trace += l.data[i*l.cols+i]
+			}
+			return trace, nil
+		})
+	case "transpose":
+		return matrixFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.transpose(), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Matrices have no property called '"+methodName+"'.")
+}
+
+func (l *LoxMatrix) String() string {
+	var builder strings.Builder
+	builder.WriteString("matrix([")
+	for i := 0; i < l.rows; i++ {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteByte('[')
+		for j, value := range l.row(i) {
+			if j > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(getResult(value, value, true))
+		}
+		builder.WriteByte(']')
+	}
+	builder.WriteString("])")
+	return builder.String()
+}
+
+func (l *LoxMatrix) Type() string {
+	return "matrix"
+}
@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Calls the specified callback with no arguments and returns its return value
+func callNoArgs(in *Interpreter, callback *LoxFunction) (any, error) {
+	result, err := callback.call(in, getArgList(callback, 0))
+	if resultReturn, ok := result.(Return); ok {
+		return resultReturn.FinalValue, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type LoxMutex struct {
+	mutex        sync.Mutex
+	locked       atomic.Bool
+	methods      map[string]*struct{ ProtoLoxCallable }
+	methodsMutex sync.Mutex
+}
+
+func NewLoxMutex() *LoxMutex {
+	return &LoxMutex{
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxMutex) lock() {
+	l.mutex.Lock()
+	l.locked.Store(true)
+}
+
+func (l *LoxMutex) tryLock() bool {
+	if !l.mutex.TryLock() {
+		return false
+	}
+	l.locked.Store(true)
+	return true
+}
+
+func (l *LoxMutex) unlock() error {
+	if !l.locked.CompareAndSwap(true, false) {
+		return errors.New("Cannot unlock a mutex that is not locked.")
+	}
+	l.mutex.Unlock()
+	return nil
+}
+
+func (l *LoxMutex) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.methodsMutex.Lock()
+	defer l.methodsMutex.Unlock()
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	mutexFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native mutex fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "isLocked":
+		return mutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.locked.Load(), nil
+		})
+	case "lock":
+		return mutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.lock()
+			return nil, nil
+		})
+	case "tryLock":
+		return mutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.tryLock(), nil
+		})
+	case "unlock":
+		return mutexFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.unlock(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "withLock":
+		return mutexFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			callback, ok := args[0].(*LoxFunction)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'mutex.withLock' must be a function.")
+			}
+			l.lock()
+			defer l.unlock()
+			return callNoArgs(in, callback)
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Mutexes have no property called '"+methodName+"'.")
+}
+
+func (l *LoxMutex) String() string {
+	return fmt.Sprintf("<mutex at %p>", l)
+}
+
+func (l *LoxMutex) Type() string {
+	return "mutex"
+}
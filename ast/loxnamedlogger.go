@@ -0,0 +1,325 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var namedLoggers = struct {
+	sync.Mutex
+	loggers map[string]*LoxNamedLogger
+}{loggers: make(map[string]*LoxNamedLogger)}
+
+var rootNamedLogger = newRootNamedLogger()
+
+func newRootNamedLogger() *LoxNamedLogger {
+	root := NewLoxNamedLogger("root", nil)
+	root.level = logLevelWarning
+	root.handlers = []*LoxLogHandler{
+		NewLoxLogHandler("stream", &logStreamOutput{os.Stderr}),
+	}
+	return root
+}
+
+func getNamedLogger(name string) *LoxNamedLogger {
+	if name == "" || name == "root" {
+		return rootNamedLogger
+	}
+	namedLoggers.Lock()
+	defer namedLoggers.Unlock()
+	var getLogger func(name string) *LoxNamedLogger
+	getLogger = func(name string) *LoxNamedLogger {
+		if logger, ok := namedLoggers.loggers[name]; ok {
+			return logger
+		}
+		parent := rootNamedLogger
+		if index := strings.LastIndex(name, "."); index >= 0 {
+			parent = getLogger(name[:index])
+		}
+		logger := NewLoxNamedLogger(name, parent)
+		namedLoggers.loggers[name] = logger
+		return logger
+	}
+	return getLogger(name)
+}
+
+type LoxNamedLogger struct {
+	mutex     sync.Mutex
+	name      string
+	parent    *LoxNamedLogger //Nil for the root logger
+	level     int64           //Zero if the level is inherited from the parent
+	handlers  []*LoxLogHandler
+	propagate bool
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxNamedLogger(name string, parent *LoxNamedLogger) *LoxNamedLogger {
+	return &LoxNamedLogger{
+		name:      name,
+		parent:    parent,
+		handlers:  []*LoxLogHandler{},
+		propagate: true,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxNamedLogger) effectiveLevel() int64 {
+	for logger := l; logger != nil; logger = logger.parent {
+		logger.mutex.Lock()
+		level := logger.level
+		logger.mutex.Unlock()
+		if level != 0 {
+			return level
+		}
+	}
+	return logLevelWarning
+}
+
+func (l *LoxNamedLogger) emit(in *Interpreter, level int64, message string, fields *LoxDict) error {
+	if level < l.effectiveLevel() {
+		return nil
+	}
+	record := &logRecord{
+		time:    time.Now(),
+		level:   level,
+		name:    l.name,
+		message: message,
+		fields:  fields,
+	}
+	for logger := l; logger != nil; logger = logger.parent {
+		logger.mutex.Lock()
+		handlers := append([]*LoxLogHandler{}, logger.handlers...)
+		propagate := logger.propagate
+		logger.mutex.Unlock()
+		for _, handler := range handlers {
+			if err := handler.handle(in, record); err != nil {
+				return err
+			}
+		}
+		if !propagate {
+			break
+		}
+	}
+	return nil
+}
+
+func (l *LoxNamedLogger) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	loggerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native named logger fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Logs the message and optional fields dictionary in args, starting at
+	//the specified index
+	logArgs := func(in *Interpreter, level int64, args list.List[any], index int) (any, error) {
+		argsLen := len(args) - index
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected %v or %v arguments but got %v.", index+1, index+2, len(args)))
+		}
+		message := getResult(args[index], args[index], true)
+		var fields *LoxDict
+		if argsLen == 2 {
+			var ok bool
+			fields, ok = args[index+1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Fields argument to 'named logger.%v' must be a dictionary.", methodName))
+			}
+		}
+		if err := l.emit(in, level, message, fields); err != nil {
+			return nil, loxerror.RuntimeError(name, err.Error())
+		}
+		return nil, nil
+	}
+	levelFunc := func(level int64) (*struct{ ProtoLoxCallable }, error) {
+		return loggerFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return logArgs(in, level, args, 0)
+		})
+	}
+	argMustBeLevel := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Argument to 'named logger.%v' must be a level integer or level name.", methodName))
+	}
+	argMustBeHandler := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Argument to 'named logger.%v' must be a log handler.", methodName))
+	}
+	switch methodName {
+	case "addHandler":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			handler, ok := args[0].(*LoxLogHandler)
+			if !ok {
+				return argMustBeHandler()
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			for _, existing := range l.handlers {
+				if existing == handler {
+					return nil, nil
+				}
+			}
+			l.handlers = append(l.handlers, handler)
+			return nil, nil
+		})
+	case "child":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			suffix, ok := args[0].(*LoxString)
+			if !ok || suffix.str == "" {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'named logger.child' must be a non-empty string.")
+			}
+			if l == rootNamedLogger {
+				return getNamedLogger(suffix.str), nil
+			}
+			return getNamedLogger(l.name + "." + suffix.str), nil
+		})
+	case "clearHandlers":
+		return loggerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.handlers = []*LoxLogHandler{}
+			return nil, nil
+		})
+	case "critical":
+		return levelFunc(logLevelCritical)
+	case "debug":
+		return levelFunc(logLevelDebug)
+	case "effectiveLevel":
+		return loggerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.effectiveLevel(), nil
+		})
+	case "error":
+		return levelFunc(logLevelError)
+	case "handlers":
+		return loggerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			handlersList := list.NewListCap[any](int64(len(l.handlers)))
+			for _, handler := range l.handlers {
+				handlersList.Add(handler)
+			}
+			return NewLoxList(handlersList), nil
+		})
+	case "info":
+		return levelFunc(logLevelInfo)
+	case "isEnabledFor":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			level, ok := logLevelArg(args[0])
+			if !ok {
+				return argMustBeLevel()
+			}
+			return level >= l.effectiveLevel(), nil
+		})
+	case "level":
+		return loggerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			if l.level == 0 {
+				return nil, nil
+			}
+			return l.level, nil
+		})
+	case "log":
+		return loggerFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) == 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Expected 2 or 3 arguments but got 0.")
+			}
+			level, ok := logLevelArg(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'named logger.log' must be a level integer or level name.")
+			}
+			return logArgs(in, level, args, 1)
+		})
+	case "name":
+		return NewLoxStringQuote(l.name), nil
+	case "parent":
+		if l.parent == nil {
+			return nil, nil
+		}
+		return l.parent, nil
+	case "propagates":
+		return loggerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.propagate, nil
+		})
+	case "removeHandler":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			handler, ok := args[0].(*LoxLogHandler)
+			if !ok {
+				return argMustBeHandler()
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			for index, existing := range l.handlers {
+				if existing == handler {
+					l.handlers = append(l.handlers[:index:index], l.handlers[index+1:]...)
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	case "setLevel":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			level := int64(0)
+			if args[0] != nil {
+				var ok bool
+				level, ok = logLevelArg(args[0])
+				if !ok || level <= 0 {
+					return argMustBeLevel()
+				}
+			} else if l == rootNamedLogger {
+				return nil, loxerror.RuntimeError(name,
+					"The level of the root logger cannot be unset.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.level = level
+			return nil, nil
+		})
+	case "setPropagate":
+		return loggerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			propagate, ok := args[0].(bool)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'named logger.setPropagate' must be a boolean.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.propagate = propagate
+			return nil, nil
+		})
+	case "warn", "warning":
+		return levelFunc(logLevelWarning)
+	}
+	return nil, loxerror.RuntimeError(name, "Named loggers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxNamedLogger) String() string {
+	return fmt.Sprintf("<named logger '%v' at %p>", l.name, l)
+}
+
+func (l *LoxNamedLogger) Type() string {
+	return "named logger"
+}
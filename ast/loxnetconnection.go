@@ -0,0 +1,192 @@
+package ast
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+const netDefaultReadSize = 4096
+
+// A network connection returned from net.dial or listener.accept
+type LoxNetConnection struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	closed  bool
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxNetConnection(conn net.Conn) *LoxNetConnection {
+	return &LoxNetConnection{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxNetConnection) close() error {
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.conn.Close()
+}
+
+func (l *LoxNetConnection) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	connFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native net connection fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'net connection.%v' must be a %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	closedErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call 'net connection.%v' on a closed connection.", methodName))
+	}
+	switch methodName {
+	case "close":
+		return connFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.close(); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return connFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.closed, nil
+		})
+	case "localAddress":
+		return NewLoxStringQuote(l.conn.LocalAddr().String()), nil
+	case "read":
+		return connFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			numBytes := int64(netDefaultReadSize)
+			switch len(args) {
+			case 0:
+			case 1:
+				var ok bool
+				numBytes, ok = args[0].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'net connection.read' must be an integer.")
+				}
+				if numBytes <= 0 {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'net connection.read' must be positive.")
+				}
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			if l.closed {
+				return closedErr()
+			}
+			bytes := make([]byte, numBytes)
+			n, err := l.reader.Read(bytes)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, nil
+				}
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxBufferFromBytes(bytes[:n]), nil
+		})
+	case "readAll":
+		return connFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.closed {
+				return closedErr()
+			}
+			bytes, err := io.ReadAll(l.reader)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxBufferFromBytes(bytes), nil
+		})
+	case "readLine":
+		return connFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.closed {
+				return closedErr()
+			}
+			line, err := l.reader.ReadString('\n')
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					return nil, loxerror.RuntimeError(name, err.Error())
+				}
+				if line == "" {
+					return nil, nil
+				}
+			}
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			return NewLoxStringQuote(line), nil
+		})
+	case "remoteAddress":
+		return NewLoxStringQuote(l.conn.RemoteAddr().String()), nil
+	case "setDeadline", "setReadDeadline", "setWriteDeadline":
+		return connFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			deadline, ok := netDeadline(args[0])
+			if !ok {
+				return argMustBeType("number or nil")
+			}
+			if l.closed {
+				return closedErr()
+			}
+			var err error
+			switch methodName {
+			case "setDeadline":
+				err = l.conn.SetDeadline(deadline)
+			case "setReadDeadline":
+				err = l.conn.SetReadDeadline(deadline)
+			case "setWriteDeadline":
+				err = l.conn.SetWriteDeadline(deadline)
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "write":
+		return connFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			data, ok := netData(args[0])
+			if !ok {
+				return argMustBeType("buffer or string")
+			}
+			if l.closed {
+				return closedErr()
+			}
+			n, err := l.conn.Write(data)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return int64(n), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Net connections have no property called '"+methodName+"'.")
+}
+
+func (l *LoxNetConnection) String() string {
+	return fmt.Sprintf("<net connection %v at %p>", l.conn.RemoteAddr(), l)
+}
+
+func (l *LoxNetConnection) Type() string {
+	return "net connection"
+}
@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A listener returned from net.listen that accepts incoming connections
+type LoxNetListener struct {
+	listener net.Listener
+	closed   bool
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxNetListener(listener net.Listener) *LoxNetListener {
+	return &LoxNetListener{
+		listener: listener,
+		methods:  make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxNetListener) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	listenerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native net listener fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "accept":
+		return listenerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.closed {
+				return nil, loxerror.RuntimeError(name,
+					"Cannot accept connections from a closed listener.")
+			}
+			conn, err := l.listener.Accept()
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxNetConnection(conn), nil
+		})
+	case "address":
+		return NewLoxStringQuote(l.listener.Addr().String()), nil
+	case "close":
+		return listenerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.closed {
+				return nil, nil
+			}
+			l.closed = true
+			if err := l.listener.Close(); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return listenerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.closed, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Net listeners have no property called '"+methodName+"'.")
+}
+
+func (l *LoxNetListener) String() string {
+	return fmt.Sprintf("<net listener %v at %p>", l.listener.Addr(), l)
+}
+
+func (l *LoxNetListener) Type() string {
+	return "net listener"
+}
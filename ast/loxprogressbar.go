@@ -0,0 +1,326 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"golang.org/x/term"
+)
+
+type progressOptions struct {
+	file        *os.File
+	label       string
+	width       int
+	fill        string
+	empty       string
+	unit        string
+	minInterval time.Duration
+}
+
+func defaultProgressOptions() progressOptions {
+	return progressOptions{
+		file:        os.Stderr,
+		width:       30,
+		fill:        "█",
+		empty:       "░",
+		unit:        "it",
+		minInterval: 100 * time.Millisecond,
+	}
+}
+
+// Formats the specified number of seconds as "mm:ss", or as "h:mm:ss" if
+// it is at least an hour
+func formatProgressTime(seconds float64) string {
+	total := int64(seconds)
+	hours, minutes, secs := total/3600, total/60%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%v:%02v:%02v", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%02v:%02v", minutes, secs)
+}
+
+type LoxProgressBar struct {
+	mutex      sync.Mutex
+	options    progressOptions
+	current    int64
+	total      int64 //Negative if the total is unknown
+	start      time.Time
+	end        time.Time //The time at which the bar was finished
+	lastDraw   time.Time
+	finished   bool
+	isTerminal bool
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxProgressBar(total int64, options progressOptions) *LoxProgressBar {
+	return &LoxProgressBar{
+		options:    options,
+		total:      total,
+		start:      time.Now(),
+		isTerminal: term.IsTerminal(int(options.file.Fd())),
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Returns the number of seconds since the bar was created, stopping once
+// the bar is finished
+func (l *LoxProgressBar) elapsed() float64 {
+	if l.finished {
+		return l.end.Sub(l.start).Seconds()
+	}
+	return time.Since(l.start).Seconds()
+}
+
+func (l *LoxProgressBar) rate() float64 {
+	elapsed := l.elapsed()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(l.current) / elapsed
+}
+
+// Returns the estimated number of seconds until the bar is complete, which
+// is negative if it can't be estimated
+func (l *LoxProgressBar) eta() float64 {
+	rate := l.rate()
+	if l.total < 0 || rate <= 0 {
+		return -1
+	}
+	return float64(max(l.total-l.current, 0)) / rate
+}
+
+func (l *LoxProgressBar) render() string {
+	var builder strings.Builder
+	if l.options.label != "" {
+		builder.WriteString(l.options.label + " ")
+	}
+	elapsed := formatProgressTime(l.elapsed())
+	rate := fmt.Sprintf("%.2f %v/s", l.rate(), l.options.unit)
+	if l.total < 0 {
+		fmt.Fprintf(&builder, "%v %v [%v, %v]", l.current, l.options.unit, elapsed, rate)
+		return builder.String()
+	}
+	fraction := 1.0
+	if l.total > 0 {
+		fraction = min(float64(l.current)/float64(l.total), 1)
+	}
+	filled := int(fraction * float64(l.options.width))
+	builder.WriteString(strings.Repeat(l.options.fill, filled))
+	builder.WriteString(strings.Repeat(l.options.empty, l.options.width-filled))
+	eta := "--:--"
+	if seconds := l.eta(); seconds >= 0 {
+		eta = formatProgressTime(seconds)
+	}
+	fmt.Fprintf(&builder, " %3v%% %v/%v [%v<%v, %v]",
+		int(fraction*100), l.current, l.total, elapsed, eta, rate)
+	return builder.String()
+}
+
+// Redraws the bar if enough time has passed since it was last drawn or if
+// force is true. Bars that aren't written to a terminal are only drawn once
+// they are finished, since they can't be redrawn in place
+func (l *LoxProgressBar) draw(force bool) {
+	now := time.Now()
+	if !force && now.Sub(l.lastDraw) < l.options.minInterval {
+		return
+	}
+	l.lastDraw = now
+	if l.isTerminal {
+		l.options.file.WriteString("\r\x1b[2K" + l.render())
+		if l.finished {
+			l.options.file.WriteString("\n")
+		}
+	} else if l.finished {
+		l.options.file.WriteString(l.render() + "\n")
+	}
+}
+
+func (l *LoxProgressBar) advance(amount int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.finished {
+		return
+	}
+	l.current += amount
+	l.draw(false)
+}
+
+func (l *LoxProgressBar) finish() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.finished {
+		return
+	}
+	l.finished = true
+	l.end = time.Now()
+	l.draw(true)
+}
+
+func (l *LoxProgressBar) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	barFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native progress bar fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "advance":
+		return barFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			amount := int64(1)
+			switch len(args) {
+			case 0:
+			case 1:
+				num, ok := args[0].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'progress bar.advance' must be an integer.")
+				}
+				amount = num
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			l.advance(amount)
+			return nil, nil
+		})
+	case "current":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.current, nil
+		})
+	case "elapsed":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.elapsed(), nil
+		})
+	case "eta":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			if eta := l.eta(); eta >= 0 {
+				return eta, nil
+			}
+			return nil, nil
+		})
+	case "finish":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.finish()
+			return nil, nil
+		})
+	case "isFinished":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.finished, nil
+		})
+	case "rate":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.rate(), nil
+		})
+	case "set":
+		return barFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			value, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'progress bar.set' must be an integer.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			if !l.finished {
+				l.current = value
+				l.draw(false)
+			}
+			return nil, nil
+		})
+	case "setLabel":
+		return barFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			label, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'progress bar.setLabel' must be a string.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.options.label = label.str
+			if !l.finished {
+				l.draw(true)
+			}
+			return nil, nil
+		})
+	case "string":
+		return barFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return NewLoxStringQuote(l.render()), nil
+		})
+	case "total":
+		if l.total < 0 {
+			return nil, nil
+		}
+		return l.total, nil
+	}
+	return nil, loxerror.RuntimeError(name, "Progress bars have no property called '"+methodName+"'.")
+}
+
+func (l *LoxProgressBar) String() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.total < 0 {
+		return fmt.Sprintf("<progress bar %v at %p>", l.current, l)
+	}
+	return fmt.Sprintf("<progress bar %v/%v at %p>", l.current, l.total, l)
+}
+
+func (l *LoxProgressBar) Type() string {
+	return "progress bar"
+}
+
+// Iterates over the elements of another iterator, advancing a progress bar
+// once each element has been processed, which is when the next element is
+// requested, and finishing the bar once there are no more elements
+type progressIterator struct {
+	iterator interfaces.Iterator
+	bar      *LoxProgressBar
+	started  bool
+}
+
+func (p *progressIterator) HasNext() bool {
+	if p.iterator.HasNext() {
+		return true
+	}
+	if p.started {
+		p.started = false
+		p.bar.advance(1)
+	}
+	p.bar.finish()
+	return false
+}
+
+func (p *progressIterator) Next() any {
+	if p.started {
+		p.bar.advance(1)
+	}
+	p.started = true
+	return p.iterator.Next()
+}
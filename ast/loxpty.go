@@ -0,0 +1,373 @@
+package ast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxPty struct {
+	cmd        *exec.Cmd
+	args       []string
+	terminal   *os.File
+	mutex      sync.Mutex
+	output     []byte        //Output that has been read but not yet consumed
+	eof        bool          //Whether the terminal has no more output
+	notify     chan struct{} //Closed whenever output or eof changes
+	done       chan struct{} //Closed once the process exits
+	properties map[string]any
+}
+
+// Starts a process attached to a new pseudo-terminal with the specified
+// size, and starts reading its output in the background
+func spawnLoxPty(args []string, cwd string, env []string, rows uint16, cols uint16) (*LoxPty, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = cwd
+	cmd.Env = env
+	terminal, err := startPty(cmd, rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	l := &LoxPty{
+		cmd:        cmd,
+		args:       args,
+		terminal:   terminal,
+		notify:     make(chan struct{}),
+		done:       make(chan struct{}),
+		properties: make(map[string]any),
+	}
+	go l.readOutput()
+	go func() {
+		cmd.Wait()
+		close(l.done)
+	}()
+	return l, nil
+}
+
+func (l *LoxPty) readOutput() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := l.terminal.Read(buf)
+		l.mutex.Lock()
+		l.output = append(l.output, buf[:n]...)
+		//Reading from a terminal whose process has exited fails with
+		//EIO on Linux instead of returning EOF
+		if err != nil {
+			l.eof = true
+		}
+		close(l.notify)
+		l.notify = make(chan struct{})
+		l.mutex.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Waits until consume returns true for the unconsumed output, the end of
+// output is reached, or the timeout expires if it is non-negative.
+// Returns true if consume returned true
+func (l *LoxPty) waitForOutput(timeout time.Duration, consume func() bool) bool {
+	var timeoutChan <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+	for {
+		l.mutex.Lock()
+		if consume() {
+			l.mutex.Unlock()
+			return true
+		}
+		if l.eof {
+			l.mutex.Unlock()
+			return false
+		}
+		notify := l.notify
+		l.mutex.Unlock()
+		select {
+		case <-notify:
+		case <-timeoutChan:
+			return false
+		}
+	}
+}
+
+// Returns all unconsumed output, waiting for at least some output to
+// arrive first. Returns nil if the end of output was reached or the
+// timeout expired without any output
+func (l *LoxPty) read(timeout time.Duration) []byte {
+	var data []byte
+	l.waitForOutput(timeout, func() bool {
+		if len(l.output) == 0 {
+			return false
+		}
+		data = l.output
+		l.output = nil
+		return true
+	})
+	return data
+}
+
+// Returns all output up to and including the first match of the specified
+// regular expression, consuming it. Returns nil if the end of output was
+// reached or the timeout expired without a match
+func (l *LoxPty) expect(pattern *regexp.Regexp, timeout time.Duration) []byte {
+	var data []byte
+	l.waitForOutput(timeout, func() bool {
+		loc := pattern.FindIndex(l.output)
+		if loc == nil {
+			return false
+		}
+		data = bytes.Clone(l.output[:loc[1]])
+		l.output = l.output[loc[1]:]
+		return true
+	})
+	return data
+}
+
+func (l *LoxPty) isRunning() bool {
+	select {
+	case <-l.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (l *LoxPty) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if property, ok := l.properties[lexemeName]; ok {
+		return property, nil
+	}
+	ptyField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	ptyFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native pty process fn %v at %p>", lexemeName, s)
+		}
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'pty process.%v' must be a %v.", lexemeName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	//Returns the optional timeout argument of a method, which is negative
+	//if it isn't specified
+	getTimeout := func(args list.List[any], index int) (time.Duration, error) {
+		if len(args) <= index {
+			return -1, nil
+		}
+		timeout, ok := subprocessTimeout(args[index])
+		if !ok {
+			return 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("Timeout argument to 'pty process.%v' must be a number or duration.", lexemeName))
+		}
+		if timeout < 0 {
+			return 0, loxerror.RuntimeError(name,
+				fmt.Sprintf("Timeout argument to 'pty process.%v' cannot be negative.", lexemeName))
+		}
+		return timeout, nil
+	}
+	write := func(data []byte) (any, error) {
+		if _, err := l.terminal.Write(data); err != nil {
+			return nil, loxerror.RuntimeError(name, err.Error())
+		}
+		return nil, nil
+	}
+	switch lexemeName {
+	case "args":
+		argsList := list.NewListCap[any](int64(len(l.args)))
+		for _, arg := range l.args {
+			argsList.Add(NewLoxStringQuote(arg))
+		}
+		return ptyField(NewLoxList(argsList))
+	case "close":
+		return ptyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			err := l.terminal.Close()
+			if err != nil && !errors.Is(err, os.ErrClosed) {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "expect":
+		return ptyFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			var pattern *regexp.Regexp
+			switch arg := args[0].(type) {
+			case *LoxString:
+				pattern = regexp.MustCompile(regexp.QuoteMeta(arg.str))
+			case *LoxRegex:
+				pattern = arg.regex
+			default:
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'pty process.expect' must be a string or regex.")
+			}
+			timeout, err := getTimeout(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			data := l.expect(pattern, timeout)
+			if data == nil {
+				return nil, nil
+			}
+			return NewLoxStringQuote(string(data)), nil
+		})
+	case "isEOF":
+		return ptyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.eof && len(l.output) == 0, nil
+		})
+	case "isRunning":
+		return ptyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isRunning(), nil
+		})
+	case "kill":
+		return ptyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			err := l.cmd.Process.Kill()
+			if err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "pid":
+		return ptyField(int64(l.cmd.Process.Pid))
+	case "read", "readBuf":
+		return ptyFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) > 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			timeout, err := getTimeout(args, 0)
+			if err != nil {
+				return nil, err
+			}
+			data := l.read(timeout)
+			if data == nil {
+				return nil, nil
+			}
+			if lexemeName == "readBuf" {
+				return NewLoxBufferFromBytes(data), nil
+			}
+			return NewLoxStringQuote(string(data)), nil
+		})
+	case "resize":
+		return ptyFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			rows, rowsOk := args[0].(int64)
+			cols, colsOk := args[1].(int64)
+			if !rowsOk || !colsOk {
+				return nil, loxerror.RuntimeError(name,
+					"Arguments to 'pty process.resize' must be integers.")
+			}
+			if rows <= 0 || cols <= 0 || rows > 0xffff || cols > 0xffff {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Arguments to 'pty process.resize' must be between 1 and 65535.")
+			}
+			err := setPtySize(l.terminal, uint16(rows), uint16(cols))
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "sendLine":
+		return ptyFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				return write([]byte(loxStr.str + "\n"))
+			}
+			return argMustBeType("string")
+		})
+	case "signal":
+		return ptyFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch args[0].(type) {
+			case *LoxString, int64:
+			default:
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'pty process.signal' must be a signal name string or number.")
+			}
+			sig, _, ok := lookupSignal(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("pty process.signal: unknown or unsupported signal '%v'.",
+						getResult(args[0], args[0], true)))
+			}
+			err := l.cmd.Process.Signal(sig)
+			if err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "size":
+		return ptyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			rows, cols, err := getPtySize(l.terminal)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxList(list.List[any]{int64(rows), int64(cols)}), nil
+		})
+	case "wait":
+		return ptyFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) > 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			timeout, err := getTimeout(args, 0)
+			if err != nil {
+				return nil, err
+			}
+			if timeout < 0 {
+				<-l.done
+			} else {
+				timer := time.NewTimer(timeout)
+				defer timer.Stop()
+				select {
+				case <-l.done:
+				case <-timer.C:
+					return nil, nil
+				}
+			}
+			return NewLoxSubprocessResult(l.args, l.cmd.ProcessState), nil
+		})
+	case "write":
+		return ptyFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			data, ok := subprocessBytes(args[0])
+			if !ok {
+				return argMustBeType("string or buffer")
+			}
+			return write(data)
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Pty processes have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxPty) String() string {
+	return fmt.Sprintf("<pty process pid=%v at %p>", l.cmd.Process.Pid, l)
+}
+
+func (l *LoxPty) Type() string {
+	return "pty process"
+}
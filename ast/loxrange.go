@@ -42,7 +42,7 @@ func (l *LoxRangeIterator) HasNext() bool {
 func (l *LoxRangeIterator) Next() any {
 	current := l.current
 	l.current += l.theRange.step
-	return current
+	return boxInt(current)
 }
 
 func NewLoxRange(start int64, stop int64, step int64) *LoxRange {
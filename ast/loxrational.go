@@ -0,0 +1,271 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Returns the specified big integer as an integer if it fits in one
+func bigIntOrInt(x *big.Int) any {
+	if x.IsInt64() {
+		return x.Int64()
+	}
+	return x
+}
+
+// Returns the specified value as an exact rational if it is an integer,
+// bigint, rational, or decimal
+func exactRat(value any) (*big.Rat, bool) {
+	switch value := value.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(value), true
+	case *big.Int:
+		return new(big.Rat).SetInt(value), true
+	case *LoxRational:
+		return value.rat, true
+	case *LoxDecimal:
+		return value.rat(), true
+	}
+	return nil, false
+}
+
+func ratFloor(x *big.Rat) *big.Int {
+	//Denominators are always positive, so Euclidean division is floor division
+	return new(big.Int).Div(x.Num(), x.Denom())
+}
+
+func ratCeil(x *big.Rat) *big.Int {
+	return new(big.Int).Neg(ratFloor(new(big.Rat).Neg(x)))
+}
+
+// Rounds half away from zero, like Math.round
+func ratRound(x *big.Rat) *big.Int {
+	half := big.NewRat(1, 2)
+	if x.Sign() < 0 {
+		return ratCeil(new(big.Rat).Sub(x, half))
+	}
+	return ratFloor(new(big.Rat).Add(x, half))
+}
+
+func ratMod(x *big.Rat, y *big.Rat) *big.Rat {
+	//mod(a, b) = a - (b * floor(a / b))
+	quotient := new(big.Rat).SetInt(ratFloor(new(big.Rat).Quo(x, y)))
+	return new(big.Rat).Sub(x, quotient.Mul(quotient, y))
+}
+
+// Returns x raised to the specified integer power, which is false if x is
+// zero and the exponent is negative
+func ratPow(x *big.Rat, exponent int64) (*big.Rat, bool) {
+	if exponent < 0 {
+		if x.Sign() == 0 {
+			return nil, false
+		}
+		x = new(big.Rat).Inv(x)
+		exponent = -exponent
+	}
+	bigExponent := big.NewInt(exponent)
+	num := new(big.Int).Exp(x.Num(), bigExponent, nil)
+	denom := new(big.Int).Exp(x.Denom(), bigExponent, nil)
+	return new(big.Rat).SetFrac(num, denom), true
+}
+
+// Returns the closest rational to x with a denominator of at most maxDenom,
+// using the same algorithm as Python's Fraction.limit_denominator
+func ratLimitDenominator(x *big.Rat, maxDenom *big.Int) *big.Rat {
+	if x.Denom().Cmp(maxDenom) <= 0 {
+		return new(big.Rat).Set(x)
+	}
+	p0, q0 := big.NewInt(0), big.NewInt(1)
+	p1, q1 := big.NewInt(1), big.NewInt(0)
+	n, d := new(big.Int).Set(x.Num()), new(big.Int).Set(x.Denom())
+	for {
+		a := new(big.Int).Div(n, d)
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(maxDenom) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, new(big.Int).Sub(n, new(big.Int).Mul(a, d))
+	}
+	k := new(big.Int).Div(new(big.Int).Sub(maxDenom, q0), q1)
+	bound1 := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)),
+	)
+	bound2 := new(big.Rat).SetFrac(p1, q1)
+	diff1 := new(big.Rat).Abs(new(big.Rat).Sub(bound2, x))
+	diff2 := new(big.Rat).Abs(new(big.Rat).Sub(bound1, x))
+	if diff1.Cmp(diff2) <= 0 {
+		return bound2
+	}
+	return bound1
+}
+
+type LoxRational struct {
+	rat     *big.Rat
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxRational(rat *big.Rat) *LoxRational {
+	return &LoxRational{
+		rat:     rat,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxRational) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case int64, *big.Int, *LoxRational, *LoxDecimal:
+		rat, _ := exactRat(obj)
+		return l.rat.Cmp(rat) == 0
+	case float64:
+		if math.IsNaN(obj) || math.IsInf(obj, 0) {
+			return false
+		}
+		return l.rat.Cmp(new(big.Rat).SetFloat64(obj)) == 0
+	case *big.Float:
+		if obj.IsInf() {
+			return false
+		}
+		rat, _ := obj.Rat(nil)
+		return l.rat.Cmp(rat) == 0
+	default:
+		return false
+	}
+}
+
+func (l *LoxRational) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	rationalFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native rational fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'rational.%v' must be %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	switch methodName {
+	case "abs":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxRational(new(big.Rat).Abs(l.rat)), nil
+		})
+	case "ceil":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return bigIntOrInt(ratCeil(l.rat)), nil
+		})
+	case "denominator":
+		return bigIntOrInt(new(big.Int).Set(l.rat.Denom())), nil
+	case "floatString":
+		return rationalFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if digits, ok := args[0].(int64); ok {
+				if digits < 0 {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+						"Argument to 'rational.floatString' cannot be negative.")
+				}
+				return NewLoxStringQuote(l.rat.FloatString(int(digits))), nil
+			}
+			return argMustBeType("an integer")
+		})
+	case "floor":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return bigIntOrInt(ratFloor(l.rat)), nil
+		})
+	case "isInteger":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.rat.IsInt(), nil
+		})
+	case "limitDenominator":
+		return rationalFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			var maxDenom *big.Int
+			switch arg := args[0].(type) {
+			case int64:
+				maxDenom = big.NewInt(arg)
+			case *big.Int:
+				maxDenom = arg
+			default:
+				return argMustBeType("an integer or bigint")
+			}
+			if maxDenom.Sign() <= 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Argument to 'rational.limitDenominator' must be at least 1.")
+			}
+			return NewLoxRational(ratLimitDenominator(l.rat, maxDenom)), nil
+		})
+	case "numerator":
+		return bigIntOrInt(new(big.Int).Set(l.rat.Num())), nil
+	case "pow":
+		return rationalFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if exponent, ok := args[0].(int64); ok {
+				result, ok := ratPow(l.rat, exponent)
+				if !ok {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, name,
+						"Cannot raise rational 0 to a negative power.")
+				}
+				return NewLoxRational(result), nil
+			}
+			return argMustBeType("an integer")
+		})
+	case "reciprocal":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.rat.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, name,
+					"Cannot take the reciprocal of rational 0.")
+			}
+			return NewLoxRational(new(big.Rat).Inv(l.rat)), nil
+		})
+	case "round":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return bigIntOrInt(ratRound(l.rat)), nil
+		})
+	case "sign":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(l.rat.Sign()), nil
+		})
+	case "toBigFloat":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return new(big.Float).SetRat(l.rat), nil
+		})
+	case "toFloat":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			f, _ := l.rat.Float64()
+			return f, nil
+		})
+	case "toInt":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			//Truncates towards zero
+			return bigIntOrInt(new(big.Int).Quo(l.rat.Num(), l.rat.Denom())), nil
+		})
+	case "toList":
+		return rationalFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			pair := list.NewListCap[any](2)
+			pair.Add(bigIntOrInt(new(big.Int).Set(l.rat.Num())))
+			pair.Add(bigIntOrInt(new(big.Int).Set(l.rat.Denom())))
+			return NewLoxList(pair), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Rationals have no property called '"+methodName+"'.")
+}
+
+func (l *LoxRational) String() string {
+	return l.rat.String()
+}
+
+func (l *LoxRational) Type() string {
+	return "rational"
+}
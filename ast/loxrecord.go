@@ -0,0 +1,252 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/scanner"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Reports whether the specified string is an identifier that isn't a
+// keyword, so that it can be used as the name of a property
+func isIdentifier(str string) bool {
+	sc := scanner.NewScanner(str)
+	if sc.ScanTokens() != nil || len(sc.Tokens) != 2 {
+		return false
+	}
+	first := sc.Tokens[0]
+	return first.TokenType == token.IDENTIFIER && first.Lexeme == str
+}
+
+// A record type created by the record function. Calling a record type
+// with one argument for each of its fields creates a record, which is an
+// immutable value whose fields can be accessed by name or by position
+type LoxRecordType struct {
+	name    string
+	fields  []string
+	indexes map[string]int
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxRecordType(name string, fields []string) *LoxRecordType {
+	indexes := make(map[string]int, len(fields))
+	for index, field := range fields {
+		indexes[field] = index
+	}
+	return &LoxRecordType{
+		name:    name,
+		fields:  fields,
+		indexes: indexes,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxRecordType) arity() int {
+	return len(l.fields)
+}
+
+func (l *LoxRecordType) call(_ *Interpreter, arguments list.List[any]) (any, error) {
+	values := make([]any, len(arguments))
+	copy(values, arguments)
+	return NewLoxRecord(l, values), nil
+}
+
+func (l *LoxRecordType) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	recordTypeFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native record type fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "fields":
+		fields := list.NewListCap[any](int64(len(l.fields)))
+		for _, field := range l.fields {
+			fields.Add(NewLoxStringQuote(field))
+		}
+		return NewLoxList(fields), nil
+	case "fromDict":
+		return recordTypeFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			dict, ok := args[0].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Argument to '%v.fromDict' must be a dictionary.", l.name))
+			}
+			values := make([]any, len(l.fields))
+			for index, field := range l.fields {
+				value, ok := dict.getValueByKey(NewLoxStringQuote(field))
+				if !ok {
+					return nil, loxerror.RuntimeErrorKind(loxerror.KeyError, name,
+						fmt.Sprintf("Missing field '%v' in dictionary passed to '%v.fromDict'.", field, l.name))
+				}
+				values[index] = value
+			}
+			if dict.Length() > int64(len(l.fields)) {
+				for _, entry := range dict.entries() {
+					key, ok := entry.key.(LoxStringStr)
+					if !ok {
+						return nil, loxerror.RuntimeError(name,
+							fmt.Sprintf("Dictionary passed to '%v.fromDict' must only have string keys.", l.name))
+					}
+					if _, ok := l.indexes[key.str]; !ok {
+						return nil, loxerror.RuntimeError(name,
+							fmt.Sprintf("Unknown field '%v' in dictionary passed to '%v.fromDict'.", key.str, l.name))
+					}
+				}
+			}
+			return NewLoxRecord(l, values), nil
+		})
+	case "name":
+		return NewLoxStringQuote(l.name), nil
+	}
+	return nil, loxerror.RuntimeError(name,
+		fmt.Sprintf("Record type '%v' has no property called '%v'.", l.name, methodName))
+}
+
+func (l *LoxRecordType) String() string {
+	return fmt.Sprintf("<record type %v at %p>", l.name, l)
+}
+
+func (l *LoxRecordType) Type() string {
+	return "record type"
+}
+
+type LoxRecord struct {
+	recordType *LoxRecordType
+	values     []any
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxRecord(recordType *LoxRecordType, values []any) *LoxRecord {
+	return &LoxRecord{
+		recordType: recordType,
+		values:     values,
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxRecord) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxRecord:
+		if l.recordType != obj.recordType {
+			return false
+		}
+		for index, value := range l.values {
+			if !valuesEqual(value, obj.values[index]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Fields of records take precedence over their methods, so a method is
+// only found if the record type has no field with the same name
+func (l *LoxRecord) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if index, ok := l.recordType.indexes[methodName]; ok {
+		return l.values[index], nil
+	}
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	recordFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native record fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "toDict":
+		return recordFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			dict := EmptyLoxDict()
+			for index, field := range l.recordType.fields {
+				dict.setKeyValue(NewLoxStringQuote(field), l.values[index])
+			}
+			return dict, nil
+		})
+	case "toList":
+		return recordFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			values := list.NewListCap[any](int64(len(l.values)))
+			for _, value := range l.values {
+				values.Add(value)
+			}
+			return NewLoxList(values), nil
+		})
+	case "with":
+		return recordFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			dict, ok := args[0].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'record.with' must be a dictionary.")
+			}
+			values := make([]any, len(l.values))
+			copy(values, l.values)
+			for _, entry := range dict.entries() {
+				key, ok := entry.key.(LoxStringStr)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Dictionary passed to 'record.with' must only have string keys.")
+				}
+				index, ok := l.recordType.indexes[key.str]
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						fmt.Sprintf("Record type '%v' has no field called '%v'.", l.recordType.name, key.str))
+				}
+				values[index] = entry.value
+			}
+			return NewLoxRecord(l.recordType, values), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name,
+		fmt.Sprintf("Record '%v' has no property called '%v'.", l.recordType.name, methodName))
+}
+
+func (l *LoxRecord) Iterator() interfaces.Iterator {
+	iterator := struct {
+		ProtoIterator
+		index int
+	}{}
+	iterator.hasNextMethod = func() bool {
+		return iterator.index < len(l.values)
+	}
+	iterator.nextMethod = func() any {
+		value := l.values[iterator.index]
+		iterator.index++
+		return value
+	}
+	return iterator
+}
+
+func (l *LoxRecord) Length() int64 {
+	return int64(len(l.values))
+}
+
+func (l *LoxRecord) String() string {
+	return getResult(l, l, true)
+}
+
+func (l *LoxRecord) Type() string {
+	return l.recordType.name
+}
@@ -0,0 +1,306 @@
+package ast
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// rpcValue is the wire representation of a Lox value. Values are tagged
+// with their kind so that integers and floats survive the round trip.
+type rpcValue struct {
+	Kind  string     `json:"k"`
+	Bool  bool       `json:"b,omitempty"`
+	Int   int64      `json:"i,omitempty"`
+	Str   string     `json:"s,omitempty"`
+	Items []rpcValue `json:"l,omitempty"`
+	Keys  []rpcValue `json:"d,omitempty"`
+}
+
+type rpcRequest struct {
+	ID     int64      `json:"id"`
+	Method string     `json:"method"`
+	Args   []rpcValue `json:"args"`
+}
+
+type rpcResponse struct {
+	ID     int64    `json:"id"`
+	Result rpcValue `json:"result"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func loxToRPCValue(value any) (rpcValue, error) {
+	switch value := value.(type) {
+	case nil:
+		return rpcValue{Kind: "nil"}, nil
+	case bool:
+		return rpcValue{Kind: "bool", Bool: value}, nil
+	case int64:
+		return rpcValue{Kind: "int", Int: value}, nil
+	case float64:
+		return rpcValue{Kind: "float", Str: strconv.FormatFloat(value, 'g', -1, 64)}, nil
+	case *LoxString:
+		return rpcValue{Kind: "string", Str: value.str}, nil
+	case LoxStringStr:
+		return rpcValue{Kind: "string", Str: value.str}, nil
+	case *LoxBuffer:
+		bytes := make([]byte, 0, len(value.elements))
+		for _, element := range value.elements {
+			bytes = append(bytes, byte(element.(int64)))
+		}
+		return rpcValue{Kind: "buffer", Str: base64.StdEncoding.EncodeToString(bytes)}, nil
+	case *LoxList:
+		items := make([]rpcValue, 0, len(value.elements))
+		for _, element := range value.elements {
+			item, err := loxToRPCValue(element)
+			if err != nil {
+				return rpcValue{}, err
+			}
+			items = append(items, item)
+		}
+		return rpcValue{Kind: "list", Items: items}, nil
+	case *LoxDict:
+		keys := make([]rpcValue, 0, len(value.indexes))
+		items := make([]rpcValue, 0, len(value.indexes))
+		for _, entry := range value.entries() {
+			key, element := entry.key, entry.value
+			keyValue, err := loxToRPCValue(key)
+			if err != nil {
+				return rpcValue{}, err
+			}
+			item, err := loxToRPCValue(element)
+			if err != nil {
+				return rpcValue{}, err
+			}
+			keys = append(keys, keyValue)
+			items = append(items, item)
+		}
+		return rpcValue{Kind: "dict", Keys: keys, Items: items}, nil
+	}
+	return rpcValue{}, fmt.Errorf("Type '%v' cannot be sent over RPC.", getType(value))
+}
+
+func rpcToLoxValue(value rpcValue) (any, error) {
+	switch value.Kind {
+	case "nil":
+		return nil, nil
+	case "bool":
+		return value.Bool, nil
+	case "int":
+		return value.Int, nil
+	case "float":
+		return strconv.ParseFloat(value.Str, 64)
+	case "string":
+		return NewLoxStringQuote(value.Str), nil
+	case "buffer":
+		bytes, err := base64.StdEncoding.DecodeString(value.Str)
+		if err != nil {
+			return nil, err
+		}
+		buffer := EmptyLoxBufferCap(int64(len(bytes)))
+		for _, b := range bytes {
+			buffer.elements.Add(int64(b))
+		}
+		return buffer, nil
+	case "list":
+		elements := list.NewListCap[any](int64(len(value.Items)))
+		for _, item := range value.Items {
+			element, err := rpcToLoxValue(item)
+			if err != nil {
+				return nil, err
+			}
+			elements.Add(element)
+		}
+		return NewLoxList(elements), nil
+	case "dict":
+		if len(value.Keys) != len(value.Items) {
+			return nil, errors.New("Malformed RPC dictionary.")
+		}
+		dict := EmptyLoxDict()
+		for index, keyValue := range value.Keys {
+			key, err := rpcToLoxValue(keyValue)
+			if err != nil {
+				return nil, err
+			}
+			element, err := rpcToLoxValue(value.Items[index])
+			if err != nil {
+				return nil, err
+			}
+			dict.setKeyValue(key, element)
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("Unknown RPC value kind '%v'.", value.Kind)
+}
+
+type LoxRPCProxy struct {
+	reader  *bufio.Reader
+	writer  io.Writer
+	closer  io.Closer
+	process *exec.Cmd
+	name    string
+	nextID  int64
+	closed  bool
+	mutex   sync.Mutex
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxRPCProxy(reader io.Reader, writer io.Writer, closer io.Closer, name string) *LoxRPCProxy {
+	return &LoxRPCProxy{
+		reader:  bufio.NewReader(reader),
+		writer:  writer,
+		closer:  closer,
+		process: nil,
+		name:    name,
+		nextID:  0,
+		closed:  false,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxRPCProxy) close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	err := l.closer.Close()
+	if l.process != nil {
+		waitErr := l.process.Wait()
+		if err == nil {
+			if _, ok := waitErr.(*exec.ExitError); !ok {
+				err = waitErr
+			}
+		}
+	}
+	return err
+}
+
+func (l *LoxRPCProxy) invoke(method string, args list.List[any]) (any, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil, errors.New("Cannot call method on closed RPC proxy.")
+	}
+	request := rpcRequest{
+		ID:     l.nextID,
+		Method: method,
+		Args:   make([]rpcValue, 0, len(args)),
+	}
+	l.nextID++
+	for _, arg := range args {
+		value, err := loxToRPCValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		request.Args = append(request.Args, value)
+	}
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.writer.Write(append(requestBytes, '\n')); err != nil {
+		return nil, err
+	}
+	var response rpcResponse
+	for {
+		line, err := l.reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("RPC connection closed by remote end.")
+			}
+			return nil, err
+		}
+		if err := json.Unmarshal(line, &response); err != nil {
+			//Output printed by a spawned worker is passed through
+			if l.process != nil {
+				os.Stdout.Write(line)
+				continue
+			}
+			return nil, fmt.Errorf("Malformed RPC response: %v", err)
+		}
+		break
+	}
+	if response.ID != request.ID {
+		return nil, errors.New("RPC response does not match request.")
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return rpcToLoxValue(response.Result)
+}
+
+func (l *LoxRPCProxy) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	proxyFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<rpc proxy fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	invoke := func(in *Interpreter, method string, args list.List[any]) (any, error) {
+		result, err := l.invoke(method, args)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return result, nil
+	}
+	switch methodName {
+	case "call":
+		return proxyFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if len(args) == 0 {
+				return nil, loxerror.RuntimeError(name,
+					"Expected at least 1 argument but got 0.")
+			}
+			method, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'rpc proxy.call' must be a string.")
+			}
+			return invoke(in, method.str, args[1:])
+		})
+	case "close":
+		return proxyFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.close(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return proxyFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.closed, nil
+		})
+	}
+	return proxyFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+		return invoke(in, methodName, args)
+	})
+}
+
+func (l *LoxRPCProxy) String() string {
+	return fmt.Sprintf("<rpc proxy: %v at %p>", l.name, l)
+}
+
+func (l *LoxRPCProxy) Type() string {
+	return "rpc proxy"
+}
@@ -47,6 +47,16 @@ func NewLoxRSA(bitSize int) (*LoxRSA, error) {
 	}, nil
 }
 
+func NewLoxRSAPrivKey(privKey *rsa.PrivateKey) *LoxRSA {
+	return &LoxRSA{
+		privKey:     privKey,
+		pubKey:      privKey.PublicKey,
+		bitSize:     privKey.N.BitLen(),
+		precomputed: false,
+		methods:     make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
 func NewLoxRSAPrivKeyBytes(bytes []byte) (*LoxRSA, error) {
 	privKey, err := x509.ParsePKCS1PrivateKey(bytes)
 	if err != nil {
@@ -54,15 +64,13 @@ func NewLoxRSAPrivKeyBytes(bytes []byte) (*LoxRSA, error) {
 		if err2 != nil {
 			return nil, err2
 		}
-		privKey = privKey2.(*rsa.PrivateKey)
+		var ok bool
+		privKey, ok = privKey2.(*rsa.PrivateKey)
+		if !ok {
+			return nil, loxerror.Error("PKCS8 private key is not an RSA private key.")
+		}
 	}
-	return &LoxRSA{
-		privKey:     privKey,
-		pubKey:      privKey.PublicKey,
-		bitSize:     privKey.N.BitLen(),
-		precomputed: false,
-		methods:     make(map[string]*struct{ ProtoLoxCallable }),
-	}, nil
+	return NewLoxRSAPrivKey(privKey), nil
 }
 
 func NewLoxRSAPrivKeyStr(str string) (*LoxRSA, error) {
@@ -90,7 +98,11 @@ func NewLoxRSAPubKeyBytes(bytes []byte) (*LoxRSA, error) {
 		if err2 != nil {
 			return nil, err2
 		}
-		pubKey = pubKey2.(*rsa.PublicKey)
+		var ok bool
+		pubKey, ok = pubKey2.(*rsa.PublicKey)
+		if !ok {
+			return nil, loxerror.Error("PKIX public key is not an RSA public key.")
+		}
 	}
 	return &LoxRSA{
 		privKey:     nil,
@@ -860,6 +872,24 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 			}
 			return buffer, nil
 		})
+	case "privKeyPEMPKCS1", "privKeyPEM":
+		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.isKeyPair() {
+				return callMustBeKeypair()
+			}
+			return NewLoxStringQuote(encodePEM("RSA PRIVATE KEY", l.encodePrivKeyPKCS1())), nil
+		})
+	case "privKeyPEMPKCS8":
+		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.isKeyPair() {
+				return callMustBeKeypair()
+			}
+			privKey, err := l.encodePrivKeyPKCS8()
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxStringQuote(encodePEM("PRIVATE KEY", privKey)), nil
+		})
 	case "privKeyStrPKCS1", "privKeyStr":
 		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			if !l.isKeyPair() {
@@ -886,6 +916,18 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeTypeAn("rsa keypair or public key")
 		})
+	case "pubKeyPEMPKCS1", "pubKeyPEM":
+		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(encodePEM("RSA PUBLIC KEY", l.encodePubKeyPKCS1())), nil
+		})
+	case "pubKeyPEMPKIX":
+		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			pubKey, err := l.encodePubKeyPKIX()
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxStringQuote(encodePEM("PUBLIC KEY", pubKey)), nil
+		})
 	case "pubKeyPKCS1", "pubKey":
 		return rsaFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			pubKey := l.encodePubKeyPKCS1()
@@ -1355,6 +1397,9 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, pemKeyErr.Error())
 				}
 				sshPrivKey := pem.EncodeToMemory(pemKey)
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				writeErr := os.WriteFile(filepath.Join(path, "id_rsa"), sshPrivKey, 0600)
 				if writeErr != nil {
 					return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -1365,6 +1410,9 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 				return nil, loxerror.RuntimeError(name, sshPubKeyErr.Error())
 			}
 			authorizedKey := ssh.MarshalAuthorizedKey(sshPubKey)
+			if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
 			writeErr := os.WriteFile(filepath.Join(path, "id_rsa.pub"), authorizedKey, 0644)
 			if writeErr != nil {
 				return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -1416,6 +1464,9 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 					return nil, loxerror.RuntimeError(name, pemKeyErr.Error())
 				}
 				sshPrivKey := pem.EncodeToMemory(pemKey)
+				if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+					return nil, sandboxErr
+				}
 				writeErr := os.WriteFile(filepath.Join(path, "id_rsa"), sshPrivKey, 0600)
 				if writeErr != nil {
 					return nil, loxerror.RuntimeError(name, writeErr.Error())
@@ -1426,6 +1477,9 @@ func (l *LoxRSA) Get(name *token.Token) (any, error) {
 				return nil, loxerror.RuntimeError(name, sshPubKeyErr.Error())
 			}
 			authorizedKey := ssh.MarshalAuthorizedKey(sshPubKey)
+			if sandboxErr := checkSandbox(name, "Writing files"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
 			writeErr := os.WriteFile(filepath.Join(path, "id_rsa.pub"), authorizedKey, 0644)
 			if writeErr != nil {
 				return nil, loxerror.RuntimeError(name, writeErr.Error())
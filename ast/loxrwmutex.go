@@ -0,0 +1,154 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxRWMutex struct {
+	mutex        sync.RWMutex
+	locked       atomic.Bool
+	readers      atomic.Int64
+	methods      map[string]*struct{ ProtoLoxCallable }
+	methodsMutex sync.Mutex
+}
+
+func NewLoxRWMutex() *LoxRWMutex {
+	return &LoxRWMutex{
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxRWMutex) lock() {
+	l.mutex.Lock()
+	l.locked.Store(true)
+}
+
+func (l *LoxRWMutex) rLock() {
+	l.mutex.RLock()
+	l.readers.Add(1)
+}
+
+func (l *LoxRWMutex) rUnlock() error {
+	for {
+		readers := l.readers.Load()
+		if readers <= 0 {
+			return errors.New("Cannot read unlock a read-write mutex that is not read locked.")
+		}
+		if l.readers.CompareAndSwap(readers, readers-1) {
+			break
+		}
+	}
+	l.mutex.RUnlock()
+	return nil
+}
+
+func (l *LoxRWMutex) unlock() error {
+	if !l.locked.CompareAndSwap(true, false) {
+		return errors.New("Cannot unlock a read-write mutex that is not locked.")
+	}
+	l.mutex.Unlock()
+	return nil
+}
+
+func (l *LoxRWMutex) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.methodsMutex.Lock()
+	defer l.methodsMutex.Unlock()
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	rwMutexFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native read-write mutex fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	withLock := func(fnName string, lock func(), unlock func() error) (*struct{ ProtoLoxCallable }, error) {
+		return rwMutexFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			callback, ok := args[0].(*LoxFunction)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Argument to 'read-write mutex.%v' must be a function.", fnName))
+			}
+			lock()
+			defer unlock()
+			return callNoArgs(in, callback)
+		})
+	}
+	switch methodName {
+	case "isLocked":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.locked.Load(), nil
+		})
+	case "lock":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.lock()
+			return nil, nil
+		})
+	case "readers":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.readers.Load(), nil
+		})
+	case "rLock":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.rLock()
+			return nil, nil
+		})
+	case "rUnlock":
+		return rwMutexFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.rUnlock(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "tryLock":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.mutex.TryLock() {
+				return false, nil
+			}
+			l.locked.Store(true)
+			return true, nil
+		})
+	case "tryRLock":
+		return rwMutexFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if !l.mutex.TryRLock() {
+				return false, nil
+			}
+			l.readers.Add(1)
+			return true, nil
+		})
+	case "unlock":
+		return rwMutexFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.unlock(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "withLock":
+		return withLock(methodName, l.lock, l.unlock)
+	case "withRLock":
+		return withLock(methodName, l.rLock, l.rUnlock)
+	}
+	return nil, loxerror.RuntimeError(name, "Read-write mutexes have no property called '"+methodName+"'.")
+}
+
+func (l *LoxRWMutex) String() string {
+	return fmt.Sprintf("<read-write mutex at %p>", l)
+}
+
+func (l *LoxRWMutex) Type() string {
+	return "read-write mutex"
+}
@@ -36,8 +36,8 @@ func NewLoxServeMux() *LoxServeMux {
 func (l *LoxServeMux) Handle(pattern string, handler http.Handler) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.handlers[pattern] = handler
 	l.mux.Handle(pattern, handler)
+	l.handlers[pattern] = handler
 }
 
 func (l *LoxServeMux) RemoveHandler(pattern string) {
@@ -12,15 +12,18 @@ import (
 )
 
 func CanBeSetElementCheck(element any) (bool, string) {
-	switch element := element.(type) {
-	case *LoxBuffer, *LoxDeque, *LoxDict, *LoxList, *LoxQueue, *LoxSet:
-		return false, fmt.Sprintf("Type '%v' cannot be used as set element.", getType(element))
+	if unhashable, ok := unhashableValue(element); ok {
+		return false, unhashableValueMsg(element, unhashable, "set element")
 	}
 	return true, ""
 }
 
+// Frozen sets can't be modified, so they can be used as dictionary keys
+// and set elements. The key field caches the key of a frozen set
 type LoxSet struct {
 	elements map[any]bool
+	frozen   bool
+	key      any
 	methods  map[string]*struct{ ProtoLoxCallable }
 }
 
@@ -80,6 +83,12 @@ func (l *LoxSet) Get(name *token.Token) (any, error) {
 		errStr := fmt.Sprintf("Argument to 'set.%v' must be a %v.", methodName, theType)
 		return nil, loxerror.RuntimeError(name, errStr)
 	}
+	if l.frozen {
+		switch methodName {
+		case "add", "clear", "remove":
+			return nil, loxerror.RuntimeError(name, frozenMethodMsg("set", methodName))
+		}
+	}
 	switch methodName {
 	case "add":
 		return setFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
@@ -112,6 +121,10 @@ func (l *LoxSet) Get(name *token.Token) (any, error) {
 			}
 			return newSet, nil
 		})
+	case "freeze":
+		return setFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.freeze(), nil
+		})
 	case "isDisjoint":
 		return setFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if set, ok := args[0].(*LoxSet); ok {
@@ -123,6 +136,10 @@ func (l *LoxSet) Get(name *token.Token) (any, error) {
 		return setFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return l.isEmpty(), nil
 		})
+	case "isFrozen":
+		return setFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.frozen, nil
+		})
 	case "remove":
 		return setFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			ok, errStr := CanBeSetElementCheck(args[0])
@@ -151,7 +168,7 @@ func (l *LoxSet) add(element any) (bool, string) {
 	case *big.Float:
 		theElement = NewLoxBigFloatKey(element)
 	case *LoxString:
-		theElement = LoxStringStr{element.str, element.quote}
+		theElement = element.dictKey()
 	case *LoxRange:
 		theElement = LoxRangeDictSetKey{element.start, element.stop, element.step}
 	default:
@@ -159,7 +176,7 @@ func (l *LoxSet) add(element any) (bool, string) {
 		if !canBeElement {
 			return false, elementErr
 		}
-		theElement = element
+		theElement = dictKey(element)
 	}
 	if l.elements[theElement] {
 		return false, ""
@@ -176,11 +193,11 @@ func (l *LoxSet) contains(element any) bool {
 	case *big.Float:
 		theElement = NewLoxBigFloatKey(element)
 	case *LoxString:
-		theElement = LoxStringStr{element.str, element.quote}
+		theElement = element.dictKey()
 	case *LoxRange:
 		theElement = LoxRangeDictSetKey{element.start, element.stop, element.step}
 	default:
-		theElement = element
+		theElement = dictKey(element)
 	}
 	return l.elements[theElement]
 }
@@ -252,11 +269,11 @@ func (l *LoxSet) remove(element any) bool {
 	case *big.Float:
 		theElement = NewLoxBigFloatKey(element)
 	case *LoxString:
-		theElement = LoxStringStr{element.str, element.quote}
+		theElement = element.dictKey()
 	case *LoxRange:
 		theElement = LoxRangeDictSetKey{element.start, element.stop, element.step}
 	default:
-		theElement = element
+		theElement = dictKey(element)
 	}
 	if l.elements[theElement] {
 		delete(l.elements, theElement)
@@ -294,16 +311,7 @@ func (l *LoxSet) union(other *LoxSet) *LoxSet {
 func (l *LoxSet) Iterator() interfaces.Iterator {
 	elements := list.NewListCap[any](int64(len(l.elements)))
 	for element := range l.elements {
-		switch element := element.(type) {
-		case LoxBigNumKey:
-			elements.Add(element.getBigNum())
-		case LoxStringStr:
-			elements.Add(NewLoxString(element.str, element.quote))
-		case LoxRangeDictSetKey:
-			elements.Add(NewLoxRange(element.start, element.stop, element.step))
-		default:
-			elements.Add(element)
-		}
+		elements.Add(dictKeyValue(element))
 	}
 	return &LoxSetIterator{elements, 0}
 }
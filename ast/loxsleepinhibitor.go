@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/power"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxSleepInhibitor struct {
+	inhibitor power.Inhibitor
+	reason    string
+	released  bool
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxSleepInhibitor(inhibitor power.Inhibitor, reason string) *LoxSleepInhibitor {
+	return &LoxSleepInhibitor{
+		inhibitor: inhibitor,
+		reason:    reason,
+		released:  false,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxSleepInhibitor) release() error {
+	if l.released {
+		return nil
+	}
+	l.released = true
+	return l.inhibitor.Release()
+}
+
+func (l *LoxSleepInhibitor) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	inhibitorFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native sleep inhibitor fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "isReleased":
+		return inhibitorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.released, nil
+		})
+	case "reason":
+		return inhibitorFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.reason), nil
+		})
+	case "release":
+		return inhibitorFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			err := l.release()
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Sleep inhibitors have no property called '"+methodName+"'.")
+}
+
+func (l *LoxSleepInhibitor) String() string {
+	if l.released {
+		return fmt.Sprintf("<released sleep inhibitor at %p>", l)
+	}
+	return fmt.Sprintf("<sleep inhibitor at %p>", l)
+}
+
+func (l *LoxSleepInhibitor) Type() string {
+	return "sleep inhibitor"
+}
@@ -0,0 +1,334 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxSortedList struct {
+	tree      sortedTree
+	compareFn *LoxFunction
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxSortedList(compareFn *LoxFunction) *LoxSortedList {
+	return &LoxSortedList{
+		compareFn: compareFn,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxSortedList) add(in *Interpreter, element any) error {
+	index, err := l.tree.bisect(element, true, l.compareFunc(in))
+	if err != nil {
+		return err
+	}
+	l.tree.insertAt(index, element, nil)
+	return nil
+}
+
+func (l *LoxSortedList) compareFunc(in *Interpreter) sortedCompareFunc {
+	if l.compareFn == nil {
+		return compareNatural
+	}
+	return loxCompareFunc(in, l.compareFn)
+}
+
+func (l *LoxSortedList) indexOf(in *Interpreter, element any) (int, error) {
+	compare := l.compareFunc(in)
+	index, err := l.tree.bisect(element, false, compare)
+	if err != nil {
+		return 0, err
+	}
+	if index == l.tree.len() {
+		return -1, nil
+	}
+	result, err := compare(element, l.tree.at(index).key)
+	if err != nil {
+		return 0, err
+	}
+	if result != 0 {
+		return -1, nil
+	}
+	return index, nil
+}
+
+func (l *LoxSortedList) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxSortedList:
+		if l.tree.len() != obj.tree.len() {
+			return false
+		}
+		otherNodes := obj.tree.nodes()
+		for i, node := range l.tree.nodes() {
+			if !valuesEqual(node.key, otherNodes[i].key) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *LoxSortedList) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	sortedListFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native sorted list fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	elementAt := func(in *Interpreter, getIndex func(sortedCompareFunc) (int, error)) (any, error) {
+		index, err := getIndex(l.compareFunc(in))
+		if err != nil {
+			return nil, sortedCompareError(name, err)
+		}
+		if index < 0 || index >= l.tree.len() {
+			return nil, nil
+		}
+		return l.tree.at(index).key, nil
+	}
+	emptyErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call 'sorted list.%v' on empty sorted list.", methodName))
+	}
+	getIndex := func(arg any) (int, error) {
+		index, ok := arg.(int64)
+		if !ok {
+			return 0, loxerror.RuntimeErrorKind(loxerror.TypeError, name,
+				IndexMustBeWholeNum("Sorted list", arg))
+		}
+		originalIndex := index
+		length := int64(l.tree.len())
+		if index < 0 {
+			index += length
+		}
+		if index < 0 || index >= length {
+			return 0, loxerror.RuntimeErrorKind(loxerror.IndexError, name,
+				fmt.Sprintf("Sorted list index %v out of range.", originalIndex))
+		}
+		return int(index), nil
+	}
+	switch methodName {
+	case "add":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := l.add(in, args[0]); err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return nil, nil
+		})
+	case "addAll":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			iterable, ok := args[0].(interfaces.Iterable)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'sorted list.addAll' must be an iterable.")
+			}
+			it := iterable.Iterator()
+			for it.HasNext() {
+				if err := l.add(in, it.Next()); err != nil {
+					return nil, sortedCompareError(name, err)
+				}
+			}
+			return nil, nil
+		})
+	case "bisectLeft", "bisectRight":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.tree.bisect(args[0], methodName == "bisectRight", l.compareFunc(in))
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return int64(index), nil
+		})
+	case "ceiling":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return elementAt(in, func(compare sortedCompareFunc) (int, error) {
+				return l.tree.bisect(args[0], false, compare)
+			})
+		})
+	case "clear":
+		return sortedListFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.tree.clear()
+			return nil, nil
+		})
+	case "contains":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return index >= 0, nil
+		})
+	case "count":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			start, end, err := l.tree.rangeIndexes(args[0], args[0], l.compareFunc(in))
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return int64(end - start), nil
+		})
+	case "first":
+		return sortedListFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return l.tree.at(0).key, nil
+		})
+	case "floor":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return elementAt(in, func(compare sortedCompareFunc) (int, error) {
+				index, err := l.tree.bisect(args[0], true, compare)
+				return index - 1, err
+			})
+		})
+	case "get":
+		return sortedListFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			index, err := getIndex(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return l.tree.at(index).key, nil
+		})
+	case "higher":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return elementAt(in, func(compare sortedCompareFunc) (int, error) {
+				return l.tree.bisect(args[0], true, compare)
+			})
+		})
+	case "indexOf":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return int64(index), nil
+		})
+	case "irange":
+		return sortedListFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			start, end, err := l.tree.rangeIndexes(args[0], args[1], l.compareFunc(in))
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			elements := list.NewListCap[any](int64(end - start))
+			for index := start; index < end; index++ {
+				elements.Add(l.tree.at(index).key)
+			}
+			return NewLoxList(elements), nil
+		})
+	case "isEmpty":
+		return sortedListFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.tree.len() == 0, nil
+		})
+	case "last":
+		return sortedListFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return l.tree.at(l.tree.len() - 1).key, nil
+		})
+	case "lower":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return elementAt(in, func(compare sortedCompareFunc) (int, error) {
+				index, err := l.tree.bisect(args[0], false, compare)
+				return index - 1, err
+			})
+		})
+	case "pop":
+		return sortedListFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			switch argsLen {
+			case 0, 1:
+				if l.tree.len() == 0 {
+					return nil, loxerror.RuntimeError(name, "Cannot pop from empty sorted list.")
+				}
+				index := l.tree.len() - 1
+				if argsLen == 1 {
+					var err error
+					index, err = getIndex(args[0])
+					if err != nil {
+						return nil, err
+					}
+				}
+				return l.tree.removeAt(index).key, nil
+			}
+			return nil, loxerror.RuntimeError(name, fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		})
+	case "remove":
+		return sortedListFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			if index < 0 {
+				return false, nil
+			}
+			l.tree.removeAt(index)
+			return true, nil
+		})
+	case "toList":
+		return sortedListFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return sortedNodesToList(l.tree.nodes()), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Sorted lists have no property called '"+methodName+"'.")
+}
+
+func (l *LoxSortedList) Iterator() interfaces.Iterator {
+	nodes := l.tree.nodes()
+	iterator := struct {
+		ProtoIterator
+		index int
+	}{}
+	iterator.hasNextMethod = func() bool {
+		return iterator.index < len(nodes)
+	}
+	iterator.nextMethod = func() any {
+		element := nodes[iterator.index].key
+		iterator.index++
+		return element
+	}
+	return iterator
+}
+
+func (l *LoxSortedList) ReverseIterator() interfaces.Iterator {
+	nodes := l.tree.nodes()
+	iterator := struct {
+		ProtoIterator
+		index int
+	}{index: len(nodes) - 1}
+	iterator.hasNextMethod = func() bool {
+		return iterator.index >= 0
+	}
+	iterator.nextMethod = func() any {
+		element := nodes[iterator.index].key
+		iterator.index--
+		return element
+	}
+	return iterator
+}
+
+func (l *LoxSortedList) Length() int64 {
+	return int64(l.tree.len())
+}
+
+func (l *LoxSortedList) String() string {
+	return getResult(l, l, true)
+}
+
+func (l *LoxSortedList) Type() string {
+	return "sorted list"
+}
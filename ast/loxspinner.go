@@ -0,0 +1,230 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"golang.org/x/term"
+)
+
+var spinnerStyles = map[string][]string{
+	"arc":    {"◜", "◠", "◝", "◞", "◡", "◟"},
+	"arrow":  {"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"},
+	"circle": {"◐", "◓", "◑", "◒"},
+	"dots":   {"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	"line":   {"-", "\\", "|", "/"},
+}
+
+type LoxSpinner struct {
+	mutex      sync.Mutex
+	file       *os.File
+	frames     []string
+	frame      int
+	message    string
+	interval   time.Duration
+	isTerminal bool
+	stop       chan struct{} //Nil if the spinner isn't spinning in the background
+	done       chan struct{} //Closed once the background goroutine has stopped
+	methods    map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxSpinner(message string, frames []string, interval time.Duration, file *os.File) *LoxSpinner {
+	return &LoxSpinner{
+		file:       file,
+		frames:     frames,
+		message:    message,
+		interval:   interval,
+		isTerminal: term.IsTerminal(int(file.Fd())),
+		methods:    make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Draws the current frame followed by the message and moves to the next
+// frame. Nothing is drawn if the spinner isn't written to a terminal
+func (l *LoxSpinner) tick() {
+	if !l.isTerminal {
+		return
+	}
+	l.file.WriteString("\r\x1b[2K" + l.frames[l.frame] + " " + l.message)
+	l.frame = (l.frame + 1) % len(l.frames)
+}
+
+func (l *LoxSpinner) start() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.stop != nil {
+		return
+	}
+	if l.isTerminal {
+		l.file.WriteString("\x1b[?25l")
+		termCursorShown = false
+	}
+	l.tick()
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go func(stop chan struct{}, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				l.mutex.Lock()
+				l.tick()
+				l.mutex.Unlock()
+			}
+		}
+	}(l.stop, l.done)
+}
+
+// Stops the spinner and replaces it with the specified symbol and final
+// message, clearing it instead if both are empty
+func (l *LoxSpinner) finish(symbol string, message string) {
+	l.mutex.Lock()
+	stop, done := l.stop, l.done
+	l.stop, l.done = nil, nil
+	l.mutex.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	line := message
+	if symbol != "" {
+		line = symbol + " " + message
+	}
+	if l.isTerminal {
+		l.file.WriteString("\r\x1b[2K\x1b[?25h")
+		termCursorShown = true
+	}
+	if symbol != "" || message != "" {
+		l.file.WriteString(line + "\n")
+	}
+}
+
+func (l *LoxSpinner) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	spinnerFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native spinner fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Returns the final message passed to a method that stops the spinner,
+	//which is the current message if it isn't specified
+	finalMessage := func(in *Interpreter, args list.List[any]) (string, error) {
+		switch len(args) {
+		case 0:
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.message, nil
+		case 1:
+			message, ok := args[0].(*LoxString)
+			if !ok {
+				return "", loxerror.RuntimeError(name,
+					fmt.Sprintf("Argument to 'spinner.%v' must be a string.", methodName))
+			}
+			return message.str, nil
+		}
+		return "", loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+	}
+	symbolFunc := func(symbol string, color string) (*struct{ ProtoLoxCallable }, error) {
+		return spinnerFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			message, err := finalMessage(in, args)
+			if err != nil {
+				return nil, err
+			}
+			styledSymbol := symbol
+			if l.isTerminal {
+				code, _ := termNamedColor(color, false)
+				styledSymbol = termStyled(symbol, []string{code})
+			}
+			l.finish(styledSymbol, message)
+			return nil, nil
+		})
+	}
+	switch methodName {
+	case "fail":
+		return symbolFunc("✖", "red")
+	case "isSpinning":
+		return spinnerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return l.stop != nil, nil
+		})
+	case "message":
+		return spinnerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return NewLoxStringQuote(l.message), nil
+		})
+	case "setMessage":
+		return spinnerFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			message, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'spinner.setMessage' must be a string.")
+			}
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.message = message.str
+			return nil, nil
+		})
+	case "start":
+		return spinnerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.start()
+			return l, nil
+		})
+	case "stop":
+		return spinnerFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			message := ""
+			if len(args) > 0 {
+				var err error
+				message, err = finalMessage(in, args)
+				if err != nil {
+					return nil, err
+				}
+			}
+			l.finish("", message)
+			return nil, nil
+		})
+	case "succeed":
+		return symbolFunc("✔", "green")
+	case "tick":
+		return spinnerFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.tick()
+			return nil, nil
+		})
+	case "warn":
+		return symbolFunc("⚠", "yellow")
+	}
+	return nil, loxerror.RuntimeError(name, "Spinners have no property called '"+methodName+"'.")
+}
+
+func (l *LoxSpinner) String() string {
+	return fmt.Sprintf("<spinner at %p>", l)
+}
+
+func (l *LoxSpinner) Type() string {
+	return "spinner"
+}
@@ -0,0 +1,109 @@
+package ast
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/syscalls"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxStatResult struct {
+	info       fs.FileInfo
+	stat       syscalls.StatResult
+	properties map[string]any
+}
+
+func NewLoxStatResult(info fs.FileInfo) *LoxStatResult {
+	return &LoxStatResult{
+		info:       info,
+		stat:       syscalls.Stat(info),
+		properties: make(map[string]any),
+	}
+}
+
+func (l *LoxStatResult) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if property, ok := l.properties[lexemeName]; ok {
+		return property, nil
+	}
+	statField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	statFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native stat result fn %v at %p>", lexemeName, s)
+		}
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = s
+		}
+		return s, nil
+	}
+	idField := func(id int64) (any, error) {
+		if !l.stat.HasIDs {
+			return statField(nil)
+		}
+		return statField(id)
+	}
+	switch lexemeName {
+	case "atime":
+		if !l.stat.HasTimes {
+			return statField(nil)
+		}
+		return statField(NewLoxDate(l.stat.Atime))
+	case "ctime":
+		if !l.stat.HasTimes {
+			return statField(nil)
+		}
+		return statField(NewLoxDate(l.stat.Ctime))
+	case "dev":
+		return idField(l.stat.Dev)
+	case "gid":
+		return idField(l.stat.Gid)
+	case "inode":
+		return idField(l.stat.Ino)
+	case "isDir":
+		return statFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.info.IsDir(), nil
+		})
+	case "isFile":
+		return statFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.info.Mode().IsRegular(), nil
+		})
+	case "isSymlink":
+		return statFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.info.Mode()&fs.ModeSymlink != 0, nil
+		})
+	case "mode":
+		return statField(int64(l.info.Mode().Perm()))
+	case "modeString":
+		return statField(NewLoxStringQuote(l.info.Mode().String()))
+	case "mtime":
+		return statField(NewLoxDate(l.info.ModTime()))
+	case "name":
+		return statField(NewLoxStringQuote(l.info.Name()))
+	case "nlink":
+		return idField(l.stat.Nlink)
+	case "size":
+		return statField(l.info.Size())
+	case "uid":
+		return idField(l.stat.Uid)
+	}
+	return nil, loxerror.RuntimeError(name, "Stat results have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxStatResult) String() string {
+	return fmt.Sprintf("<stat result: %v at %p>", l.info.Name(), l)
+}
+
+func (l *LoxStatResult) Type() string {
+	return "stat result"
+}
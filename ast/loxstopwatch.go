@@ -17,6 +17,8 @@ type LoxStopwatch struct {
 	startTime time.Time
 	stopTime  time.Time
 	started   bool
+	laps      []time.Duration
+	lapTotal  time.Duration //Time of the stopwatch when the last lap ended
 	methods   map[string]*struct{ ProtoLoxCallable }
 }
 
@@ -37,6 +39,15 @@ func (l *LoxStopwatch) currentTime() time.Duration {
 	return l.stopTime.Sub(l.startTime)
 }
 
+// Ends the current lap and returns its duration
+func (l *LoxStopwatch) lap() time.Duration {
+	current := l.currentTime()
+	lapTime := current - l.lapTotal
+	l.laps = append(l.laps, lapTime)
+	l.lapTotal = current
+	return lapTime
+}
+
 func (l *LoxStopwatch) start() {
 	if l.started {
 		return
@@ -58,6 +69,8 @@ func (l *LoxStopwatch) reset() {
 	l.startTime = zeroTime
 	l.stopTime = zeroTime
 	l.started = false
+	l.laps = nil
+	l.lapTotal = 0
 }
 
 func (l *LoxStopwatch) stop() {
@@ -95,7 +108,7 @@ func (l *LoxStopwatch) Get(name *token.Token) (any, error) {
 		return s, nil
 	}
 	switch methodName {
-	case "duration":
+	case "duration", "elapsed":
 		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxDuration(l.currentTime()), nil
 		})
@@ -107,6 +120,22 @@ func (l *LoxStopwatch) Get(name *token.Token) (any, error) {
 		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return l.isZero(), nil
 		})
+	case "isRunning":
+		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.started, nil
+		})
+	case "lap":
+		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxDuration(l.lap()), nil
+		})
+	case "laps":
+		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			laps := list.NewListCap[any](int64(len(l.laps)))
+			for _, lapTime := range l.laps {
+				laps.Add(NewLoxDuration(lapTime))
+			}
+			return NewLoxList(laps), nil
+		})
 	case "microseconds":
 		return stopwatchFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return l.currentTime().Microseconds(), nil
@@ -6,8 +6,11 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
+	"unsafe"
 
 	"github.com/AlanLuu/lox/interfaces"
 	"github.com/AlanLuu/lox/list"
@@ -16,10 +19,27 @@ import (
 	"github.com/AlanLuu/lox/util"
 )
 
+// Strings created by concatenation that are at least this many bytes long
+// share storage with spare capacity, so that repeatedly appending to a
+// string takes amortized linear time instead of quadratic time
+const loxStringConcatThreshold = 1024
+
+// The storage of strings created by concatenation. Only the string whose
+// length equals the length of the data can append to the data in place,
+// since the bytes after every other string in the storage are already part
+// of a longer string
+type loxStringStorage struct {
+	data []byte
+	mu   sync.Mutex
+}
+
 type LoxString struct {
-	str     string
-	quote   byte
-	methods map[string]*struct{ ProtoLoxCallable }
+	str      string
+	quote    byte
+	storage  *loxStringStorage
+	key      atomic.Value
+	interned bool
+	methods  map[string]*struct{ ProtoLoxCallable }
 }
 
 type LoxStringIterator struct {
@@ -34,14 +54,19 @@ func (l *LoxStringIterator) HasNext() bool {
 func (l *LoxStringIterator) Next() any {
 	c := []rune(l.loxStr.str)[l.index]
 	l.index++
+	if c < utf8.RuneSelf {
+		if c == '\'' {
+			return InternLoxString("'", '"')
+		}
+		return InternLoxString(string(c), '\'')
+	}
 	return NewLoxStringQuote(string(c))
 }
 
 func NewLoxString(str string, quote byte) *LoxString {
 	return &LoxString{
-		str:     str,
-		quote:   quote,
-		methods: make(map[string]*struct{ ProtoLoxCallable }),
+		str:   str,
+		quote: quote,
 	}
 }
 
@@ -74,6 +99,70 @@ func StringIndexOutOfRange(index int64) string {
 	return fmt.Sprintf("String index %v out of range.", index)
 }
 
+// Returns the specified string with each character converted to the same
+// form as every other character that it is equal to when ignoring case,
+// which doesn't depend on the locale
+func caseFold(str string) string {
+	return strings.Map(func(c rune) rune {
+		return unicode.ToLower(unicode.ToUpper(c))
+	}, str)
+}
+
+// Returns the words in the specified string, which are runs of letters,
+// digits, and combining marks, along with any apostrophes between them
+func stringWords(str string) []string {
+	isWordChar := func(c rune) bool {
+		return unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsMark(c)
+	}
+	var words []string
+	runes := []rune(str)
+	start := -1
+	for i, c := range runes {
+		switch {
+		case isWordChar(c):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0 && (c == '\'' || c == '\u2019') &&
+			i+1 < len(runes) && isWordChar(runes[i+1]):
+		case start >= 0:
+			words = append(words, string(runes[start:i]))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// Returns a new string with the specified string appended to this string,
+// which is quoted with double quotes if either string contains a single quote
+func (l *LoxString) concat(str string) *LoxString {
+	quote := l.quote
+	if strings.Contains(str, "'") {
+		quote = '"'
+	}
+	newLen := len(l.str) + len(str)
+	if newLen < loxStringConcatThreshold {
+		return NewLoxString(l.str+str, quote)
+	}
+	storage := l.storage
+	if storage != nil {
+		storage.mu.Lock()
+		defer storage.mu.Unlock()
+	}
+	if storage == nil || len(storage.data) != len(l.str) || cap(storage.data) < newLen {
+		data := make([]byte, len(l.str), newLen*2)
+		copy(data, l.str)
+		storage = &loxStringStorage{data: data}
+	}
+	storage.data = append(storage.data, str...)
+	result := NewLoxString(unsafe.String(unsafe.SliceData(storage.data), newLen), quote)
+	result.storage = storage
+	return result
+}
+
 func (l *LoxString) NewLoxString(str string) *LoxString {
 	return NewLoxString(str, l.quote)
 }
@@ -81,12 +170,24 @@ func (l *LoxString) NewLoxString(str string) *LoxString {
 func (l *LoxString) Equals(obj any) bool {
 	switch obj := obj.(type) {
 	case *LoxString:
-		return l.str == obj.str
+		return l == obj || l.str == obj.str
 	default:
 		return false
 	}
 }
 
+// Returns the key that represents this string in dictionaries and sets.
+// The key is only boxed the first time that it's needed, so that using the
+// same string as a key repeatedly doesn't allocate
+func (l *LoxString) dictKey() any {
+	if key := l.key.Load(); key != nil {
+		return key
+	}
+	var key any = LoxStringStr{l.str, l.quote}
+	l.key.Store(key)
+	return key
+}
+
 func (l *LoxString) Get(name *token.Token) (any, error) {
 	methodName := name.Lexeme
 	if method, ok := l.methods[methodName]; ok {
@@ -99,6 +200,14 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 		s.stringMethod = func() string {
 			return fmt.Sprintf("<native string fn %v at %p>", methodName, s)
 		}
+		//Interned strings are shared between threads, so their
+		//methods aren't cached to avoid concurrent writes to the map
+		if l.interned {
+			return s, nil
+		}
+		if l.methods == nil {
+			l.methods = make(map[string]*struct{ ProtoLoxCallable })
+		}
 		if _, ok := l.methods[methodName]; !ok {
 			l.methods[methodName] = s
 		}
@@ -119,15 +228,16 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 		}
 
 		useDoubleQuote := false
-		padStrLen := int64(len(padStr))
-		if padStrLen > 0 {
-			offset := finalStrLen - int64(len(initialStr))
+		padRunes := []rune(padStr)
+		padRunesLen := int64(len(padRunes))
+		if padRunesLen > 0 {
+			offset := finalStrLen - int64(utf8.RuneCountInString(initialStr))
 			for i := int64(0); i < offset; i++ {
-				b := padStr[i%padStrLen]
-				if !useDoubleQuote && b == '\'' {
+				c := padRunes[i%padRunesLen]
+				if !useDoubleQuote && c == '\'' {
 					useDoubleQuote = true
 				}
-				builder.WriteByte(b)
+				builder.WriteRune(c)
 			}
 		}
 		if padAtStart {
@@ -176,6 +286,35 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 			newStr := strings.ToUpper(string(runes[0])) + strings.ToLower(string(runes[1:]))
 			return NewLoxString(newStr, l.quote), nil
 		})
+	case "casefold":
+		return strFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxString(caseFold(l.str), l.quote), nil
+		})
+	case "center":
+		return strFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			finalStrLen, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name, "First argument to 'string.center' must be an integer.")
+			}
+			var padStr any = " "
+			if argsLen == 2 {
+				padStr = args[1]
+			}
+			//Any odd padding character goes at the end
+			strLen := l.Length()
+			leftLen := strLen + max(finalStrLen-strLen, 0)/2
+			leftPadded, leftDoubleQuote := padString(l.str, leftLen, padStr, true)
+			paddedStr, rightDoubleQuote := padString(leftPadded, finalStrLen, padStr, false)
+			if leftDoubleQuote || rightDoubleQuote {
+				return NewLoxString(paddedStr, '"'), nil
+			}
+			return NewLoxString(paddedStr, l.quote), nil
+		})
 	case "compare":
 		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if loxStr, ok := args[0].(*LoxString); ok {
@@ -183,6 +322,13 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeType("string")
 		})
+	case "compareIgnoreCase":
+		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				return int64(strings.Compare(caseFold(l.str), caseFold(loxStr.str))), nil
+			}
+			return argMustBeType("string")
+		})
 	case "contains":
 		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if loxStr, ok := args[0].(*LoxString); ok {
@@ -271,6 +417,20 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 			}
 			return nil, loxerror.RuntimeError(name, "First argument to 'string.padStart' must be an integer.")
 		})
+	case "removePrefix":
+		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				return NewLoxString(strings.TrimPrefix(l.str, loxStr.str), l.quote), nil
+			}
+			return argMustBeType("string")
+		})
+	case "removeSuffix":
+		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				return NewLoxString(strings.TrimSuffix(l.str, loxStr.str), l.quote), nil
+			}
+			return argMustBeType("string")
+		})
 	case "replace":
 		return strFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if firstStr, firstStrOk := args[0].(*LoxString); firstStrOk {
@@ -401,6 +561,40 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 			}
 			return argMustBeType("string")
 		})
+	case "splitLines":
+		return strFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			keepEnds := false
+			switch argsLen := len(args); argsLen {
+			case 0:
+			case 1:
+				var ok bool
+				keepEnds, ok = args[0].(bool)
+				if !ok {
+					return argMustBeType("boolean")
+				}
+			default:
+				return nil, loxerror.RuntimeError(name, fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+			linesList := list.NewList[any]()
+			for str := l.str; str != ""; {
+				index := strings.IndexAny(str, "\r\n")
+				if index < 0 {
+					linesList.Add(NewLoxStringQuote(str))
+					break
+				}
+				end := index + 1
+				if str[index] == '\r' && end < len(str) && str[end] == '\n' {
+					end++
+				}
+				if keepEnds {
+					linesList.Add(NewLoxStringQuote(str[:end]))
+				} else {
+					linesList.Add(NewLoxStringQuote(str[:index]))
+				}
+				str = str[end:]
+			}
+			return NewLoxList(linesList), nil
+		})
 	case "startsWith":
 		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if loxStr, ok := args[0].(*LoxString); ok {
@@ -552,10 +746,57 @@ func (l *LoxString) Get(name *token.Token) (any, error) {
 			}
 			return newSet, nil
 		})
+	case "translate":
+		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			tableDict, ok := args[0].(*LoxDict)
+			if !ok {
+				return argMustBeType("dictionary")
+			}
+			//Characters mapped to nil are removed from the string
+			table := make(map[rune]*string)
+			it := tableDict.Iterator()
+			for it.HasNext() {
+				pair := it.Next().(*LoxList).elements
+				key, ok := pair[0].(*LoxString)
+				if !ok || utf8.RuneCountInString(key.str) != 1 {
+					return nil, loxerror.RuntimeError(name,
+						"Keys of dictionary argument to 'string.translate' must be single-character strings.")
+				}
+				c, _ := utf8.DecodeRuneInString(key.str)
+				switch value := pair[1].(type) {
+				case *LoxString:
+					table[c] = &value.str
+				case nil:
+					table[c] = nil
+				default:
+					return nil, loxerror.RuntimeError(name,
+						"Values of dictionary argument to 'string.translate' must be strings or nil.")
+				}
+			}
+			var builder strings.Builder
+			for _, c := range l.str {
+				replacement, ok := table[c]
+				switch {
+				case !ok:
+					builder.WriteRune(c)
+				case replacement != nil:
+					builder.WriteString(*replacement)
+				}
+			}
+			return NewLoxStringQuote(builder.String()), nil
+		})
 	case "upper":
 		return strFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxString(strings.ToUpper(l.str), l.quote), nil
 		})
+	case "words":
+		return strFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			wordsList := list.NewList[any]()
+			for _, word := range stringWords(l.str) {
+				wordsList.Add(NewLoxStringQuote(word))
+			}
+			return NewLoxList(wordsList), nil
+		})
 	case "zfill":
 		return strFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
 			if finalStrLen, ok := args[0].(int64); ok {
@@ -0,0 +1,122 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxStringBuilder struct {
+	builder strings.Builder
+	length  int64
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxStringBuilder() *LoxStringBuilder {
+	return &LoxStringBuilder{
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+// Appends the specified value to the builder, where values that aren't
+// strings are appended in the same form that they are printed in
+func (l *LoxStringBuilder) append(value any) {
+	var str string
+	switch value := value.(type) {
+	case *LoxString:
+		str = value.str
+	default:
+		str = getResult(value, value, true)
+	}
+	l.builder.WriteString(str)
+	l.length += int64(utf8.RuneCountInString(str))
+}
+
+func (l *LoxStringBuilder) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	builderFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native string builder fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "append":
+		return builderFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			for _, arg := range args {
+				l.append(arg)
+			}
+			return l, nil
+		})
+	case "appendLine":
+		return builderFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			for _, arg := range args {
+				l.append(arg)
+			}
+			l.builder.WriteByte('\n')
+			l.length++
+			return l, nil
+		})
+	case "clear":
+		return builderFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.builder.Reset()
+			l.length = 0
+			return nil, nil
+		})
+	case "grow":
+		return builderFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			size, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'string builder.grow' must be an integer.")
+			}
+			if size < 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, name,
+					"Argument to 'string builder.grow' cannot be negative.")
+			}
+			if limitErr := checkSizeLimit(in.callToken, size); limitErr != nil {
+				return nil, limitErr
+			}
+			l.builder.Grow(int(size))
+			return nil, nil
+		})
+	case "isEmpty":
+		return builderFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.length == 0, nil
+		})
+	case "toString":
+		return builderFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.builder.String()), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "String builders have no property called '"+methodName+"'.")
+}
+
+func (l *LoxStringBuilder) Capacity() int64 {
+	return int64(l.builder.Cap())
+}
+
+func (l *LoxStringBuilder) Length() int64 {
+	return l.length
+}
+
+func (l *LoxStringBuilder) String() string {
+	return l.builder.String()
+}
+
+func (l *LoxStringBuilder) Type() string {
+	return "string builder"
+}
@@ -0,0 +1,414 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/ast/filemode"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxSubprocessResult struct {
+	args       []string
+	state      *os.ProcessState
+	stdout     any //nil if stdout wasn't captured
+	stderr     any //nil if stderr wasn't captured
+	timedOut   bool
+	properties map[string]any
+}
+
+func NewLoxSubprocessResult(args []string, state *os.ProcessState) *LoxSubprocessResult {
+	return &LoxSubprocessResult{
+		args:       args,
+		state:      state,
+		properties: make(map[string]any),
+	}
+}
+
+func (l *LoxSubprocessResult) success() bool {
+	return !l.timedOut && l.state.Success()
+}
+
+// Returns a message describing why the subprocess failed, or an empty
+// string if it succeeded
+func (l *LoxSubprocessResult) failure() string {
+	command := strings.Join(l.args, " ")
+	switch {
+	case l.timedOut:
+		return fmt.Sprintf("Command '%v' timed out.", command)
+	case !l.state.Success():
+		return fmt.Sprintf("Command '%v' failed: %v.", command, l.state)
+	}
+	return ""
+}
+
+func (l *LoxSubprocessResult) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if property, ok := l.properties[lexemeName]; ok {
+		return property, nil
+	}
+	resultField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	resultFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native subprocess result fn %v at %p>", lexemeName, s)
+		}
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = s
+		}
+		return s, nil
+	}
+	switch lexemeName {
+	case "args":
+		argsList := list.NewListCap[any](int64(len(l.args)))
+		for _, arg := range l.args {
+			argsList.Add(NewLoxStringQuote(arg))
+		}
+		return resultField(NewLoxList(argsList))
+	case "check":
+		return resultFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if message := l.failure(); message != "" {
+				return nil, loxerror.RuntimeError(in.callToken, message)
+			}
+			return l, nil
+		})
+	case "exitCode":
+		return resultField(int64(l.state.ExitCode()))
+	case "pid":
+		return resultField(int64(l.state.Pid()))
+	case "status":
+		return resultField(NewLoxProcessResult(l.state))
+	case "stderr":
+		return resultField(l.stderr)
+	case "stdout":
+		return resultField(l.stdout)
+	case "success":
+		return resultField(l.success())
+	case "timedOut":
+		return resultField(l.timedOut)
+	}
+	return nil, loxerror.RuntimeError(name, "Subprocess results have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxSubprocessResult) String() string {
+	return fmt.Sprintf("<subprocess result: exit code %v at %p>", l.state.ExitCode(), l)
+}
+
+func (l *LoxSubprocessResult) Type() string {
+	return "subprocess result"
+}
+
+type LoxSubprocess struct {
+	cmd        *exec.Cmd
+	args       []string
+	binary     bool
+	stdin      *LoxFile //nil if stdin isn't a pipe
+	stdout     *LoxFile //nil if stdout isn't a pipe
+	stderr     *LoxFile //nil if stderr isn't a pipe
+	done       chan struct{}
+	properties map[string]any
+}
+
+// Starts a subprocess in the background, where each stream that is a pipe
+// can be written to or read from using the returned subprocess object
+func spawnLoxSubprocess(args []string, options *subprocessOptions) (*LoxSubprocess, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = options.cwd
+	cmd.Env = options.environ()
+	l := &LoxSubprocess{
+		cmd:        cmd,
+		args:       args,
+		binary:     options.binary,
+		done:       make(chan struct{}),
+		properties: make(map[string]any),
+	}
+
+	//The ends of each pipe that the subprocess uses, which are closed in
+	//this process once the subprocess starts
+	var childFiles []*os.File
+	closeAll := func() {
+		for _, file := range childFiles {
+			file.Close()
+		}
+		for _, loxFile := range []*LoxFile{l.stdin, l.stdout, l.stderr} {
+			if loxFile != nil {
+				loxFile.file.Close()
+			}
+		}
+	}
+	pipe := func(isStdin bool, parentFile **LoxFile) (*os.File, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		file, childFile, mode := r, w, filemode.READ
+		if isStdin {
+			file, childFile, mode = w, r, filemode.WRITE
+		}
+		childFiles = append(childFiles, childFile)
+		*parentFile = &LoxFile{
+			file:       file,
+			name:       file.Name(),
+			mode:       mode,
+			isBinary:   options.binary,
+			stat:       nil,
+			properties: make(map[string]any),
+		}
+		return childFile, nil
+	}
+
+	var err error
+	if options.stdin == "pipe" {
+		cmd.Stdin, err = pipe(true, &l.stdin)
+	} else if stdin := options.streamFile(options.stdin, os.Stdin); stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if err == nil {
+		if options.stdout == "pipe" {
+			cmd.Stdout, err = pipe(false, &l.stdout)
+		} else if stdout := options.streamFile(options.stdout, os.Stdout); stdout != nil {
+			cmd.Stdout = stdout
+		}
+	}
+	if err == nil {
+		switch {
+		case options.stderr == "stdout":
+			cmd.Stderr = cmd.Stdout
+		case options.stderr == "pipe":
+			cmd.Stderr, err = pipe(false, &l.stderr)
+		default:
+			if stderr := options.streamFile(options.stderr, os.Stderr); stderr != nil {
+				cmd.Stderr = stderr
+			}
+		}
+	}
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	for _, file := range childFiles {
+		file.Close()
+	}
+
+	//The streams of the subprocess are all files, so waiting on it doesn't
+	//depend on anything reading from or writing to them
+	go func() {
+		cmd.Wait()
+		close(l.done)
+	}()
+	return l, nil
+}
+
+func (l *LoxSubprocess) isRunning() bool {
+	select {
+	case <-l.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (l *LoxSubprocess) result() *LoxSubprocessResult {
+	return NewLoxSubprocessResult(l.args, l.cmd.ProcessState)
+}
+
+// Writes the specified input to stdin if it is a pipe, closes stdin, reads
+// all output from stdout and stderr if they are pipes, and waits for the
+// subprocess to exit
+func (l *LoxSubprocess) communicate(input []byte) *LoxSubprocessResult {
+	var wg sync.WaitGroup
+	if l.stdin != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if len(input) > 0 {
+				l.stdin.file.Write(input)
+			}
+			l.stdin.file.Close()
+		}()
+	}
+	var stdout, stderr []byte
+	readAll := func(loxFile *LoxFile, output *[]byte) {
+		if loxFile == nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			*output, _ = io.ReadAll(loxFile.file)
+			loxFile.file.Close()
+		}()
+	}
+	readAll(l.stdout, &stdout)
+	readAll(l.stderr, &stderr)
+	wg.Wait()
+	<-l.done
+
+	result := l.result()
+	if l.stdout != nil {
+		result.stdout = subprocessOutput(stdout, l.binary)
+	}
+	if l.stderr != nil {
+		result.stderr = subprocessOutput(stderr, l.binary)
+	}
+	return result
+}
+
+func (l *LoxSubprocess) Get(name *token.Token) (any, error) {
+	lexemeName := name.Lexeme
+	if property, ok := l.properties[lexemeName]; ok {
+		return property, nil
+	}
+	subprocessField := func(field any) (any, error) {
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = field
+		}
+		return field, nil
+	}
+	subprocessFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native subprocess fn %v at %p>", lexemeName, s)
+		}
+		if _, ok := l.properties[lexemeName]; !ok {
+			l.properties[lexemeName] = s
+		}
+		return s, nil
+	}
+	streamField := func(loxFile *LoxFile) (any, error) {
+		if loxFile == nil {
+			return subprocessField(nil)
+		}
+		return subprocessField(loxFile)
+	}
+	switch lexemeName {
+	case "args":
+		argsList := list.NewListCap[any](int64(len(l.args)))
+		for _, arg := range l.args {
+			argsList.Add(NewLoxStringQuote(arg))
+		}
+		return subprocessField(NewLoxList(argsList))
+	case "communicate":
+		return subprocessFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			var input []byte
+			switch len(args) {
+			case 0:
+			case 1:
+				if args[0] == nil {
+					break
+				}
+				var ok bool
+				input, ok = subprocessBytes(args[0])
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'subprocess.communicate' must be a string, buffer, or nil.")
+				}
+				if len(input) > 0 && l.stdin == nil {
+					return nil, loxerror.RuntimeError(name,
+						"Cannot send input to subprocess whose stdin is not a pipe.")
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			return l.communicate(input), nil
+		})
+	case "isRunning":
+		return subprocessFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isRunning(), nil
+		})
+	case "kill":
+		return subprocessFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			err := l.cmd.Process.Kill()
+			if err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "pid":
+		return subprocessField(int64(l.cmd.Process.Pid))
+	case "signal":
+		return subprocessFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			switch args[0].(type) {
+			case *LoxString, int64:
+			default:
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'subprocess.signal' must be a signal name string or number.")
+			}
+			sig, _, ok := lookupSignal(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("subprocess.signal: unknown or unsupported signal '%v'.",
+						getResult(args[0], args[0], true)))
+			}
+			err := l.cmd.Process.Signal(sig)
+			if err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "stderr":
+		return streamField(l.stderr)
+	case "stdin":
+		return streamField(l.stdin)
+	case "stdout":
+		return streamField(l.stdout)
+	case "wait":
+		return subprocessFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			switch len(args) {
+			case 0:
+				<-l.done
+			case 1:
+				timeout, ok := subprocessTimeout(args[0])
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'subprocess.wait' must be a number or duration.")
+				}
+				if timeout < 0 {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'subprocess.wait' cannot be negative.")
+				}
+				timer := time.NewTimer(timeout)
+				defer timer.Stop()
+				select {
+				case <-l.done:
+				case <-timer.C:
+					return nil, nil
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			return l.result(), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Subprocesses have no property called '"+lexemeName+"'.")
+}
+
+func (l *LoxSubprocess) String() string {
+	return fmt.Sprintf("<subprocess pid=%v at %p>", l.cmd.Process.Pid, l)
+}
+
+func (l *LoxSubprocess) Type() string {
+	return "subprocess"
+}
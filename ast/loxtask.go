@@ -0,0 +1,123 @@
+package ast
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/env"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxTask struct {
+	done      chan struct{}
+	result    any
+	err       error
+	cancelled atomic.Bool
+	group     *LoxTaskGroup
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+// Returns a copy of the interpreter for running Lox code on another
+// goroutine, so that the goroutine's function calls do not swap out the
+// caller's current environment. Environments start locking their variables
+// from this point on, since they can now be shared between goroutines.
+func (i *Interpreter) newTaskInterpreter() *Interpreter {
+	env.EnableLocking()
+	taskIn := *i
+	taskIn.profileStack = nil
+	taskIn.callDepth = 0
+	return &taskIn
+}
+
+// Starts calling the specified callable with the specified arguments on a
+// new goroutine using a copy of the interpreter.
+func NewLoxTask(in *Interpreter, callable LoxCallable, args list.List[any], group *LoxTaskGroup) *LoxTask {
+	task := &LoxTask{
+		done:    make(chan struct{}),
+		group:   group,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	taskIn := in.newTaskInterpreter()
+	go func() {
+		defer close(task.done)
+		result, err := callable.call(taskIn, args)
+		if resultReturn, ok := result.(Return); ok {
+			task.result = resultReturn.FinalValue
+		} else if err != nil {
+			task.err = err
+			if group != nil {
+				group.fail(err)
+			}
+		} else {
+			task.result = result
+		}
+	}()
+	return task
+}
+
+func (l *LoxTask) await() (any, error) {
+	<-l.done
+	return l.result, l.err
+}
+
+func (l *LoxTask) isCancelled() bool {
+	return l.cancelled.Load() || (l.group != nil && l.group.isCancelled())
+}
+
+func (l *LoxTask) isDone() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *LoxTask) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	taskFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native task fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "await", "join":
+		return taskFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.await()
+		})
+	case "cancel":
+		return taskFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.cancelled.Store(true)
+			return nil, nil
+		})
+	case "isCancelled":
+		return taskFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isCancelled(), nil
+		})
+	case "isDone":
+		return taskFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isDone(), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Tasks have no property called '"+methodName+"'.")
+}
+
+func (l *LoxTask) String() string {
+	return fmt.Sprintf("<task at %p>", l)
+}
+
+func (l *LoxTask) Type() string {
+	return "task"
+}
@@ -0,0 +1,153 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxTaskGroup struct {
+	tasks     []*LoxTask
+	firstErr  error
+	cancelled atomic.Bool
+	exited    bool
+	mutex     sync.Mutex
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxTaskGroup() *LoxTaskGroup {
+	return &LoxTaskGroup{
+		tasks:   []*LoxTask{},
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxTaskGroup) cancel() {
+	l.cancelled.Store(true)
+}
+
+// Records the specified error as the reason the group failed if no other
+// error has been recorded yet, and cancels all tasks in the group.
+func (l *LoxTaskGroup) fail(err error) {
+	l.mutex.Lock()
+	if l.firstErr == nil {
+		l.firstErr = err
+	}
+	l.mutex.Unlock()
+	l.cancel()
+}
+
+func (l *LoxTaskGroup) isCancelled() bool {
+	return l.cancelled.Load()
+}
+
+func (l *LoxTaskGroup) spawn(in *Interpreter, callable LoxCallable, args list.List[any]) (*LoxTask, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.exited {
+		return nil, errors.New("Cannot spawn task in a task group that has already exited.")
+	}
+	if l.isCancelled() {
+		return nil, errors.New("Cannot spawn task in a cancelled task group.")
+	}
+	task := NewLoxTask(in, callable, args, l)
+	l.tasks = append(l.tasks, task)
+	return task, nil
+}
+
+// Waits for every task in the group to finish, including tasks spawned by
+// other tasks while waiting, then prevents any more tasks from being
+// spawned. Returns the results of the tasks in the order they were spawned.
+func (l *LoxTaskGroup) wait() (list.List[any], error) {
+	for index := 0; ; index++ {
+		l.mutex.Lock()
+		if index >= len(l.tasks) {
+			l.exited = true
+			l.mutex.Unlock()
+			break
+		}
+		task := l.tasks[index]
+		l.mutex.Unlock()
+		task.await()
+	}
+	if l.firstErr != nil {
+		return nil, l.firstErr
+	}
+	results := list.NewListCap[any](int64(len(l.tasks)))
+	for _, task := range l.tasks {
+		results.Add(task.result)
+	}
+	return results, nil
+}
+
+func (l *LoxTaskGroup) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	taskGroupFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native task group fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "cancel":
+		return taskGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.cancel()
+			return nil, nil
+		})
+	case "isCancelled":
+		return taskGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isCancelled(), nil
+		})
+	case "size":
+		return taskGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return int64(len(l.tasks)), nil
+		})
+	case "spawn":
+		return taskGroupFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			callable, callArgs, err := taskSpawnArgs(in, "task group.spawn", args)
+			if err != nil {
+				return nil, err
+			}
+			task, err := l.spawn(in, callable, callArgs)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return task, nil
+		})
+	case "tasks":
+		return taskGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			tasks := list.NewListCap[any](int64(len(l.tasks)))
+			for _, task := range l.tasks {
+				tasks.Add(task)
+			}
+			return NewLoxList(tasks), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Task groups have no property called '"+methodName+"'.")
+}
+
+func (l *LoxTaskGroup) String() string {
+	return fmt.Sprintf("<task group at %p>", l)
+}
+
+func (l *LoxTaskGroup) Type() string {
+	return "task group"
+}
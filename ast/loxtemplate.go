@@ -0,0 +1,159 @@
+package ast
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The methods shared by text and HTML templates
+type templateExecutor interface {
+	Execute(w io.Writer, data any) error
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+type LoxTemplate struct {
+	executor templateExecutor
+	names    []string
+	isHTML   bool
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+// Parses the specified template source, where HTML templates escape the
+// values that they render based on where they appear in the HTML
+func NewLoxTemplate(source string, isHTML bool) (*LoxTemplate, error) {
+	result := &LoxTemplate{
+		isHTML:  isHTML,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+	if isHTML {
+		tmpl, err := htmltemplate.New("template").Option("missingkey=error").Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tmpl.Templates() {
+			result.names = append(result.names, t.Name())
+		}
+		result.executor = tmpl
+	} else {
+		tmpl, err := texttemplate.New("template").Option("missingkey=error").Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tmpl.Templates() {
+			result.names = append(result.names, t.Name())
+		}
+		result.executor = tmpl
+	}
+	slices.Sort(result.names)
+	return result, nil
+}
+
+// Renders the template with the specified name, or the main template if the
+// name is empty, using the specified Lox value as the data
+func (l *LoxTemplate) render(name string, data any) (string, error) {
+	var builder strings.Builder
+	var err error
+	if name == "" {
+		err = l.executor.Execute(&builder, FromLoxValue(data))
+	} else {
+		err = l.executor.ExecuteTemplate(&builder, name, FromLoxValue(data))
+	}
+	if err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func (l *LoxTemplate) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	templateFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native template fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "isHTML":
+		return l.isHTML, nil
+	case "render":
+		return templateFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			var data any
+			switch argsLen := len(args); argsLen {
+			case 0:
+			case 1:
+				data = args[0]
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+			result, err := l.render("", data)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(result), nil
+		})
+	case "renderTemplate":
+		return templateFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			templateName, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'template.renderTemplate' must be a string.")
+			}
+			if !slices.Contains(l.names, templateName.str) {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Template '%v' is not defined.", templateName.str))
+			}
+			var data any
+			if argsLen == 2 {
+				data = args[1]
+			}
+			result, err := l.render(templateName.str, data)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(result), nil
+		})
+	case "templates":
+		return templateFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			names := list.NewListCap[any](int64(len(l.names)))
+			for _, templateName := range l.names {
+				names.Add(NewLoxStringQuote(templateName))
+			}
+			return NewLoxList(names), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Templates have no property called '"+methodName+"'.")
+}
+
+func (l *LoxTemplate) String() string {
+	if l.isHTML {
+		return fmt.Sprintf("<HTML template at %p>", l)
+	}
+	return fmt.Sprintf("<template at %p>", l)
+}
+
+func (l *LoxTemplate) Type() string {
+	return "template"
+}
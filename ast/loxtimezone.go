@@ -0,0 +1,218 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	_ "time/tzdata" //Embeds the IANA time zone database
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var timezoneOffsetRegex = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})?$`)
+
+// Returns the time zone with the specified name, which is either an IANA
+// time zone name such as "America/New_York", "UTC", "Local", or a UTC
+// offset such as "+05:30" or "-0800"
+func loadTimezone(name string) (*time.Location, error) {
+	if match := timezoneOffsetRegex.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes := 0
+		if match[3] != "" {
+			minutes, _ = strconv.Atoi(match[3])
+		}
+		if hours > 23 || minutes > 59 {
+			return nil, fmt.Errorf("invalid UTC offset '%v'", name)
+		}
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(fmt.Sprintf("%v%02v:%02v", match[1], hours, minutes), offset), nil
+	}
+	if name == "Z" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// Returns the time zone specified by either a timezone object or a time
+// zone name passed to the function with the specified name
+func timezoneArg(callToken *token.Token, fnName string, value any) (*time.Location, error) {
+	switch value := value.(type) {
+	case *LoxTimezone:
+		return value.location, nil
+	case *LoxString:
+		location, err := loadTimezone(value.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(callToken, err.Error())
+		}
+		return location, nil
+	}
+	return nil, loxerror.RuntimeError(callToken,
+		fmt.Sprintf("Time zone argument to '%v' must be a timezone or a string.", fnName))
+}
+
+type LoxTimezone struct {
+	location *time.Location
+	methods  map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxTimezone(location *time.Location) *LoxTimezone {
+	return &LoxTimezone{
+		location: location,
+		methods:  make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxTimezone) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxTimezone:
+		return l.location.String() == obj.location.String()
+	default:
+		return false
+	}
+}
+
+func (l *LoxTimezone) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	timezoneFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native timezone fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	//Returns the date argument converted to this time zone
+	dateArg := func(arg any) (time.Time, error) {
+		if loxDate, ok := arg.(*LoxDate); ok {
+			return loxDate.date.In(l.location), nil
+		}
+		return time.Time{}, loxerror.RuntimeError(name,
+			fmt.Sprintf("Argument to 'timezone.%v' must be a date.", methodName))
+	}
+	switch methodName {
+	case "abbreviation":
+		return timezoneFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			abbreviation, _ := date.Zone()
+			return NewLoxStringQuote(abbreviation), nil
+		})
+	case "date":
+		return timezoneFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen < 3 || argsLen > 7 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 3 to 7 arguments but got %v.", argsLen))
+			}
+			values := [7]int{}
+			for index, arg := range args {
+				value, ok := arg.(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Arguments to 'timezone.date' must be integers.")
+				}
+				values[index] = int(value)
+			}
+			return NewLoxDate(time.Date(
+				values[0], time.Month(values[1]), values[2],
+				values[3], values[4], values[5], values[6],
+				l.location,
+			)), nil
+		})
+	case "isDST":
+		return timezoneFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return date.IsDST(), nil
+		})
+	case "name":
+		return NewLoxStringQuote(l.location.String()), nil
+	case "nextTransition":
+		return timezoneFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, end := date.ZoneBounds()
+			if end.IsZero() {
+				return nil, nil
+			}
+			return NewLoxDate(end), nil
+		})
+	case "now":
+		return timezoneFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxDate(time.Now().In(l.location)), nil
+		})
+	case "offset":
+		return timezoneFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, offset := date.Zone()
+			return int64(offset), nil
+		})
+	case "parse":
+		return timezoneFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			if _, ok := args[0].(*LoxString); !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'timezone.parse' must be a string.")
+			}
+			if _, ok := args[1].(*LoxString); !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'timezone.parse' must be a string.")
+			}
+			layout := args[0].(*LoxString).str
+			dateStr := args[1].(*LoxString).str
+			var date time.Time
+			var err error
+			if isStrftimeFormat(layout) {
+				date, err = strptime(dateStr, layout, l.location)
+			} else {
+				date, err = time.ParseInLocation(layout, dateStr, l.location)
+			}
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return NewLoxDate(date), nil
+		})
+	case "previousTransition":
+		return timezoneFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			date, err := dateArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			start, _ := date.ZoneBounds()
+			if start.IsZero() {
+				return nil, nil
+			}
+			return NewLoxDate(start), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Timezones have no property called '"+methodName+"'.")
+}
+
+func (l *LoxTimezone) String() string {
+	return fmt.Sprintf("<timezone: %v>", l.location)
+}
+
+func (l *LoxTimezone) Type() string {
+	return "timezone"
+}
@@ -0,0 +1,124 @@
+package ast
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// A TLS certificate along with its private key
+type LoxTLSCertificate struct {
+	cert    tls.Certificate
+	leaf    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func NewLoxTLSCertificate(certPEM []byte, keyPEM []byte) (*LoxTLSCertificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &LoxTLSCertificate{
+		cert:    cert,
+		leaf:    leaf,
+		certPEM: certPEM,
+		keyPEM:  keyPEM,
+	}, nil
+}
+
+// Generates a self-signed certificate for the specified host names and IP
+// addresses that is valid for the specified number of days
+func NewLoxTLSSelfSigned(hosts []string, validDays int64) (*LoxTLSCertificate, error) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	notBefore := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Lox self-signed certificate"}},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(time.Duration(validDays) * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	if len(hosts) > 0 {
+		template.Subject.CommonName = hosts[0]
+	}
+	certDER, err := x509.CreateCertificate(crand.Reader, template, template, &privKey.PublicKey, privKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoxTLSCertificate(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	)
+}
+
+func (l *LoxTLSCertificate) Get(name *token.Token) (any, error) {
+	switch name.Lexeme {
+	case "certPEM":
+		return NewLoxBufferFromBytes(l.certPEM), nil
+	case "hosts":
+		hosts := list.NewList[any]()
+		for _, dnsName := range l.leaf.DNSNames {
+			hosts.Add(NewLoxStringQuote(dnsName))
+		}
+		for _, ip := range l.leaf.IPAddresses {
+			hosts.Add(NewLoxStringQuote(ip.String()))
+		}
+		return NewLoxList(hosts), nil
+	case "keyPEM":
+		return NewLoxBufferFromBytes(l.keyPEM), nil
+	case "notAfter":
+		return l.leaf.NotAfter.Unix(), nil
+	case "notBefore":
+		return l.leaf.NotBefore.Unix(), nil
+	case "subject":
+		return NewLoxStringQuote(l.leaf.Subject.String()), nil
+	}
+	return nil, loxerror.RuntimeError(name,
+		"TLS certificates have no property called '"+name.Lexeme+"'.")
+}
+
+func (l *LoxTLSCertificate) String() string {
+	return fmt.Sprintf("<tls certificate %v at %p>", l.leaf.Subject, l)
+}
+
+func (l *LoxTLSCertificate) Type() string {
+	return "tls certificate"
+}
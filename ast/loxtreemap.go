@@ -0,0 +1,307 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxTreeMap struct {
+	tree      sortedTree
+	compareFn *LoxFunction
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxTreeMap(compareFn *LoxFunction) *LoxTreeMap {
+	return &LoxTreeMap{
+		compareFn: compareFn,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxTreeMap) compareFunc(in *Interpreter) sortedCompareFunc {
+	if l.compareFn == nil {
+		return compareNatural
+	}
+	return loxCompareFunc(in, l.compareFn)
+}
+
+func (l *LoxTreeMap) indexOf(in *Interpreter, key any) (int, error) {
+	compare := l.compareFunc(in)
+	index, err := l.tree.bisect(key, false, compare)
+	if err != nil {
+		return 0, err
+	}
+	if index == l.tree.len() {
+		return -1, nil
+	}
+	result, err := compare(key, l.tree.at(index).key)
+	if err != nil {
+		return 0, err
+	}
+	if result != 0 {
+		return -1, nil
+	}
+	return index, nil
+}
+
+func (l *LoxTreeMap) set(in *Interpreter, key any, value any) error {
+	compare := l.compareFunc(in)
+	index, err := l.tree.bisect(key, false, compare)
+	if err != nil {
+		return err
+	}
+	if index < l.tree.len() {
+		node := l.tree.at(index)
+		result, err := compare(key, node.key)
+		if err != nil {
+			return err
+		}
+		if result == 0 {
+			node.value = value
+			return nil
+		}
+	}
+	l.tree.insertAt(index, key, value)
+	return nil
+}
+
+func (l *LoxTreeMap) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxTreeMap:
+		if l.tree.len() != obj.tree.len() {
+			return false
+		}
+		otherNodes := obj.tree.nodes()
+		for i, node := range l.tree.nodes() {
+			if !valuesEqual(node.key, otherNodes[i].key) ||
+				!valuesEqual(node.value, otherNodes[i].value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *LoxTreeMap) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	treeMapFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native tree map fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	keyAt := func(in *Interpreter, getIndex func(sortedCompareFunc) (int, error)) (any, error) {
+		index, err := getIndex(l.compareFunc(in))
+		if err != nil {
+			return nil, sortedCompareError(name, err)
+		}
+		if index < 0 || index >= l.tree.len() {
+			return nil, nil
+		}
+		return l.tree.at(index).key, nil
+	}
+	emptyErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call 'tree map.%v' on empty tree map.", methodName))
+	}
+	popPair := func(index int) *LoxList {
+		node := l.tree.removeAt(index)
+		pair := list.NewListCap[any](2)
+		pair.Add(node.key)
+		pair.Add(node.value)
+		return NewLoxList(pair)
+	}
+	switch methodName {
+	case "ceilingKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return keyAt(in, func(compare sortedCompareFunc) (int, error) {
+				return l.tree.bisect(args[0], false, compare)
+			})
+		})
+	case "clear":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.tree.clear()
+			return nil, nil
+		})
+	case "containsKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return index >= 0, nil
+		})
+	case "firstKey":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return l.tree.at(0).key, nil
+		})
+	case "floorKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return keyAt(in, func(compare sortedCompareFunc) (int, error) {
+				index, err := l.tree.bisect(args[0], true, compare)
+				return index - 1, err
+			})
+		})
+	case "get":
+		return treeMapFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 1 && argsLen != 2 {
+				return nil, loxerror.RuntimeError(name, fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+			}
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			if index < 0 {
+				if argsLen == 2 {
+					return args[1], nil
+				}
+				return nil, nil
+			}
+			return l.tree.at(index).value, nil
+		})
+	case "higherKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return keyAt(in, func(compare sortedCompareFunc) (int, error) {
+				return l.tree.bisect(args[0], true, compare)
+			})
+		})
+	case "irange":
+		return treeMapFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			start, end, err := l.tree.rangeIndexes(args[0], args[1], l.compareFunc(in))
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			keys := list.NewListCap[any](int64(end - start))
+			for index := start; index < end; index++ {
+				keys.Add(l.tree.at(index).key)
+			}
+			return NewLoxList(keys), nil
+		})
+	case "isEmpty":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.tree.len() == 0, nil
+		})
+	case "keys":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return sortedNodesToList(l.tree.nodes()), nil
+		})
+	case "lastKey":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return l.tree.at(l.tree.len() - 1).key, nil
+		})
+	case "lowerKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			return keyAt(in, func(compare sortedCompareFunc) (int, error) {
+				index, err := l.tree.bisect(args[0], false, compare)
+				return index - 1, err
+			})
+		})
+	case "popFirst":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return popPair(0), nil
+		})
+	case "popLast":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.tree.len() == 0 {
+				return emptyErr()
+			}
+			return popPair(l.tree.len() - 1), nil
+		})
+	case "removeKey":
+		return treeMapFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			index, err := l.indexOf(in, args[0])
+			if err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			if index < 0 {
+				return nil, nil
+			}
+			return l.tree.removeAt(index).value, nil
+		})
+	case "set":
+		return treeMapFunc(2, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := l.set(in, args[0], args[1]); err != nil {
+				return nil, sortedCompareError(name, err)
+			}
+			return nil, nil
+		})
+	case "toDict":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			dict := EmptyLoxDict()
+			for _, node := range l.tree.nodes() {
+				if ok, errStr := CanBeDictKeyCheck(node.key); !ok {
+					return nil, loxerror.RuntimeError(name, errStr)
+				}
+				dict.setKeyValue(node.key, node.value)
+			}
+			return dict, nil
+		})
+	case "values":
+		return treeMapFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			nodes := l.tree.nodes()
+			values := list.NewListCap[any](int64(len(nodes)))
+			for _, node := range nodes {
+				values.Add(node.value)
+			}
+			return NewLoxList(values), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Tree maps have no property called '"+methodName+"'.")
+}
+
+func (l *LoxTreeMap) Iterator() interfaces.Iterator {
+	nodes := l.tree.nodes()
+	iterator := struct {
+		ProtoIterator
+		index int
+	}{}
+	iterator.hasNextMethod = func() bool {
+		return iterator.index < len(nodes)
+	}
+	iterator.nextMethod = func() any {
+		node := nodes[iterator.index]
+		iterator.index++
+		pair := list.NewListCap[any](2)
+		pair.Add(node.key)
+		pair.Add(node.value)
+		return NewLoxList(pair)
+	}
+	return iterator
+}
+
+func (l *LoxTreeMap) Length() int64 {
+	return int64(l.tree.len())
+}
+
+func (l *LoxTreeMap) String() string {
+	return getResult(l, l, true)
+}
+
+func (l *LoxTreeMap) Type() string {
+	return "tree map"
+}
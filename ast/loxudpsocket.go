@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// The largest possible size of a UDP packet
+const udpMaxPacketSize = 65535
+
+// A UDP socket returned from net.bindUDP that sends and receives packets
+type LoxUDPSocket struct {
+	conn    *net.UDPConn
+	closed  bool
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxUDPSocket(conn *net.UDPConn) *LoxUDPSocket {
+	return &LoxUDPSocket{
+		conn:    conn,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxUDPSocket) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	socketFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native udp socket fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	closedErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call 'udp socket.%v' on a closed socket.", methodName))
+	}
+	switch methodName {
+	case "address":
+		return NewLoxStringQuote(l.conn.LocalAddr().String()), nil
+	case "close":
+		return socketFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if l.closed {
+				return nil, nil
+			}
+			l.closed = true
+			if err := l.conn.Close(); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	case "isClosed":
+		return socketFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.closed, nil
+		})
+	case "recvFrom":
+		return socketFunc(-1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			numBytes := int64(udpMaxPacketSize)
+			switch len(args) {
+			case 0:
+			case 1:
+				var ok bool
+				numBytes, ok = args[0].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'udp socket.recvFrom' must be an integer.")
+				}
+				if numBytes <= 0 {
+					return nil, loxerror.RuntimeError(name,
+						"Argument to 'udp socket.recvFrom' must be positive.")
+				}
+			default:
+				return nil, loxerror.RuntimeError(name,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			if l.closed {
+				return closedErr()
+			}
+			bytes := make([]byte, numBytes)
+			n, addr, err := l.conn.ReadFromUDP(bytes)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			result := list.NewListCap[any](2)
+			result.Add(NewLoxBufferFromBytes(bytes[:n]))
+			result.Add(NewLoxStringQuote(addr.String()))
+			return NewLoxList(result), nil
+		})
+	case "sendTo":
+		return socketFunc(2, func(_ *Interpreter, args list.List[any]) (any, error) {
+			data, ok := netData(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"First argument to 'udp socket.sendTo' must be a buffer or string.")
+			}
+			address, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Second argument to 'udp socket.sendTo' must be a string.")
+			}
+			if l.closed {
+				return closedErr()
+			}
+			udpAddr, resolveErr := net.ResolveUDPAddr("udp", address.str)
+			if resolveErr != nil {
+				return nil, loxerror.RuntimeError(name, resolveErr.Error())
+			}
+			n, err := l.conn.WriteToUDP(data, udpAddr)
+			if err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return int64(n), nil
+		})
+	case "setDeadline":
+		return socketFunc(1, func(_ *Interpreter, args list.List[any]) (any, error) {
+			deadline, ok := netDeadline(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(name,
+					"Argument to 'udp socket.setDeadline' must be a number or nil.")
+			}
+			if l.closed {
+				return closedErr()
+			}
+			if err := l.conn.SetDeadline(deadline); err != nil {
+				return nil, loxerror.RuntimeError(name, err.Error())
+			}
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "UDP sockets have no property called '"+methodName+"'.")
+}
+
+func (l *LoxUDPSocket) String() string {
+	return fmt.Sprintf("<udp socket %v at %p>", l.conn.LocalAddr(), l)
+}
+
+func (l *LoxUDPSocket) Type() string {
+	return "udp socket"
+}
@@ -0,0 +1,201 @@
+package ast
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/google/uuid"
+)
+
+// Crockford's base32 alphabet, which ULID strings are encoded with
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// The largest timestamp in milliseconds that can be stored in a ULID
+const ulidMaxTime = 1<<48 - 1
+
+type ulid [16]byte
+
+var ulidMu sync.Mutex
+var lastULID ulid
+
+// Returns a new ULID with the specified timestamp in milliseconds. If the
+// timestamp is not after the timestamp of the last ULID returned from this
+// function, the new ULID is the last ULID plus one, so that ULIDs generated
+// within the same millisecond are in increasing order
+func newULIDMonotonic(ms uint64) (ulid, error) {
+	ulidMu.Lock()
+	defer ulidMu.Unlock()
+	if ms <= lastULID.timestamp() {
+		next := lastULID
+		for index := len(next) - 1; index >= 6; index-- {
+			next[index]++
+			if next[index] != 0 {
+				lastULID = next
+				return next, nil
+			}
+		}
+		return ulid{}, loxerror.Error("ULID random component overflowed within the same millisecond.")
+	}
+	newULID, err := newULIDAt(ms)
+	if err != nil {
+		return ulid{}, err
+	}
+	lastULID = newULID
+	return newULID, nil
+}
+
+// Returns a new ULID with the specified timestamp in milliseconds and a
+// random component
+func newULIDAt(ms uint64) (ulid, error) {
+	var newULID ulid
+	if _, err := crand.Read(newULID[6:]); err != nil {
+		return ulid{}, err
+	}
+	newULID.setTimestamp(ms)
+	return newULID, nil
+}
+
+func parseULID(str string) (ulid, error) {
+	var result ulid
+	if len(str) != 26 {
+		return result, loxerror.Error(
+			fmt.Sprintf("invalid ULID length: %v", len(str)))
+	}
+	//26 characters hold 130 bits, so the first character must fit in 3 bits
+	//for the ULID to fit in 128 bits
+	var bits, numBits uint
+	resultIndex := len(result) - 1
+	for index := len(str) - 1; index >= 0; index-- {
+		value := strings.IndexByte(ulidAlphabet, upperASCII(str[index]))
+		if value < 0 || (index == 0 && value > 7) {
+			return ulid{}, loxerror.Error(
+				fmt.Sprintf("invalid ULID character '%c' at index %v", str[index], index))
+		}
+		bits |= uint(value) << numBits
+		numBits += 5
+		for numBits >= 8 && resultIndex >= 0 {
+			result[resultIndex] = byte(bits)
+			resultIndex--
+			bits >>= 8
+			numBits -= 8
+		}
+	}
+	return result, nil
+}
+
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func (u *ulid) setTimestamp(ms uint64) {
+	for index := 5; index >= 0; index-- {
+		u[index] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// Returns the timestamp of the ULID in milliseconds since the Unix epoch
+func (u ulid) timestamp() uint64 {
+	var ms uint64
+	for _, b := range u[:6] {
+		ms = ms<<8 | uint64(b)
+	}
+	return ms
+}
+
+func (u ulid) String() string {
+	var result [26]byte
+	var bits, numBits uint
+	byteIndex := len(u) - 1
+	for index := len(result) - 1; index >= 0; index-- {
+		if numBits < 5 && byteIndex >= 0 {
+			bits |= uint(u[byteIndex]) << numBits
+			byteIndex--
+			numBits += 8
+		}
+		result[index] = ulidAlphabet[bits&31]
+		bits >>= 5
+		numBits -= min(numBits, 5)
+	}
+	return string(result[:])
+}
+
+type LoxULID struct {
+	ulid    ulid
+	methods map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxULID(theULID ulid) *LoxULID {
+	return &LoxULID{
+		ulid:    theULID,
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxULID) Equals(obj any) bool {
+	switch obj := obj.(type) {
+	case *LoxULID:
+		return l.ulid == obj.ulid
+	default:
+		return false
+	}
+}
+
+func (l *LoxULID) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	ulidFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ulid fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "bytes":
+		return ulidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxBufferFromBytes(l.ulid[:]), nil
+		})
+	case "date":
+		return ulidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxDate(time.UnixMilli(int64(l.ulid.timestamp()))), nil
+		})
+	case "string":
+		return ulidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxStringQuote(l.ulid.String()), nil
+		})
+	case "timestamp":
+		return ulidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return int64(l.ulid.timestamp()), nil
+		})
+	case "toUUID":
+		return ulidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxUUID(uuid.UUID(l.ulid)), nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "ULIDs have no property called '"+methodName+"'.")
+}
+
+func (l *LoxULID) String() string {
+	return fmt.Sprintf("<ULID id=%v>", l.ulid)
+}
+
+func (l *LoxULID) Type() string {
+	return "ulid"
+}
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
@@ -54,6 +55,13 @@ func NewLoxUUIDV4Random() (*LoxUUID, error) {
 	}, nil
 }
 
+// Returns the time encoded in the UUID in milliseconds since the Unix epoch,
+// which is only meaningful for time-based UUIDs
+func (l *LoxUUID) unixMillis() int64 {
+	sec, nsec := l.uuid.Time().UnixTime()
+	return sec*1000 + nsec/int64(time.Millisecond)
+}
+
 func (l *LoxUUID) Equals(obj any) bool {
 	switch obj := obj.(type) {
 	case *LoxUUID:
@@ -100,6 +108,14 @@ func (l *LoxUUID) Get(name *token.Token) (any, error) {
 			}
 			return int64(l.uuid.ClockSequence()), nil
 		})
+	case "date":
+		return uuidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if _, ok := TimeBasedUUIDs[l.uuid.Version()]; !ok {
+				return nil, loxerror.RuntimeError(name,
+					"uuid.date: current UUID must be a version 1, 2, 6, or 7 UUID.")
+			}
+			return NewLoxDate(time.UnixMilli(l.unixMillis())), nil
+		})
 	case "string":
 		return uuidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxStringQuote(l.uuid.String()), nil
@@ -112,6 +128,14 @@ func (l *LoxUUID) Get(name *token.Token) (any, error) {
 			}
 			return int64(l.uuid.Time()), nil
 		})
+	case "timestamp":
+		return uuidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			if _, ok := TimeBasedUUIDs[l.uuid.Version()]; !ok {
+				return nil, loxerror.RuntimeError(name,
+					"uuid.timestamp: current UUID must be a version 1, 2, 6, or 7 UUID.")
+			}
+			return l.unixMillis(), nil
+		})
 	case "urn":
 		return uuidFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 			return NewLoxStringQuote(l.uuid.URN()), nil
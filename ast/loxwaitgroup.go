@@ -0,0 +1,99 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type LoxWaitGroup struct {
+	waitGroup    sync.WaitGroup
+	count        atomic.Int64
+	methods      map[string]*struct{ ProtoLoxCallable }
+	methodsMutex sync.Mutex
+}
+
+func NewLoxWaitGroup() *LoxWaitGroup {
+	return &LoxWaitGroup{
+		methods: make(map[string]*struct{ ProtoLoxCallable }),
+	}
+}
+
+func (l *LoxWaitGroup) add(delta int64) error {
+	for {
+		count := l.count.Load()
+		if count+delta < 0 {
+			return errors.New("Wait group counter cannot be negative.")
+		}
+		if l.count.CompareAndSwap(count, count+delta) {
+			break
+		}
+	}
+	l.waitGroup.Add(int(delta))
+	return nil
+}
+
+func (l *LoxWaitGroup) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	l.methodsMutex.Lock()
+	defer l.methodsMutex.Unlock()
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	waitGroupFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native wait group fn %v at %p>", methodName, s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	switch methodName {
+	case "add":
+		return waitGroupFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			delta, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'wait group.add' must be an integer.")
+			}
+			if err := l.add(delta); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "count":
+		return waitGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.count.Load(), nil
+		})
+	case "done":
+		return waitGroupFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.add(-1); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "wait":
+		return waitGroupFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			l.waitGroup.Wait()
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Wait groups have no property called '"+methodName+"'.")
+}
+
+func (l *LoxWaitGroup) String() string {
+	return fmt.Sprintf("<wait group at %p>", l)
+}
+
+func (l *LoxWaitGroup) Type() string {
+	return "wait group"
+}
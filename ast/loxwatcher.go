@@ -0,0 +1,314 @@
+package ast
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/fsnotify/fsnotify"
+)
+
+// The names of the kinds of events that watchers report, in the order
+// that they are reported when a single change has multiple kinds
+var watchEventOps = []struct {
+	op   fsnotify.Op
+	name string
+}{
+	{fsnotify.Create, "create"},
+	{fsnotify.Write, "modify"},
+	{fsnotify.Remove, "delete"},
+	{fsnotify.Rename, "rename"},
+	{fsnotify.Chmod, "chmod"},
+}
+
+type LoxWatcher struct {
+	watcher   *fsnotify.Watcher
+	recursive bool
+	pending   []*LoxDict
+	isClosed  bool
+	methods   map[string]*struct{ ProtoLoxCallable }
+}
+
+func NewLoxWatcher(recursive bool) (*LoxWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &LoxWatcher{
+		watcher:   watcher,
+		recursive: recursive,
+		methods:   make(map[string]*struct{ ProtoLoxCallable }),
+	}, nil
+}
+
+// Starts watching the specified path, along with all of its subdirectories
+// if the watcher is recursive
+func (l *LoxWatcher) add(path string) error {
+	addPath := func(path string) error {
+		if err := l.watcher.Add(path); err != nil {
+			return fmt.Errorf("watch %v: %w", path, err)
+		}
+		return nil
+	}
+	if !l.recursive {
+		return addPath(path)
+	}
+	return filepath.WalkDir(path, func(subPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if subPath == path || d.IsDir() {
+			return addPath(subPath)
+		}
+		return nil
+	})
+}
+
+func (l *LoxWatcher) close() error {
+	if l.isClosed {
+		return nil
+	}
+	l.isClosed = true
+	l.pending = nil
+	return l.watcher.Close()
+}
+
+// Converts a filesystem event into dictionaries with the keys "path" and
+// "op", one for each kind of change in the event
+func (l *LoxWatcher) queueEvent(event fsnotify.Event) {
+	for _, eventOp := range watchEventOps {
+		if !event.Has(eventOp.op) {
+			continue
+		}
+		dict := EmptyLoxDict()
+		dict.setKeyValue(NewLoxString("path", '\''), NewLoxStringQuote(event.Name))
+		dict.setKeyValue(NewLoxString("op", '\''), NewLoxStringQuote(eventOp.name))
+		l.pending = append(l.pending, dict)
+	}
+	if l.recursive && event.Has(fsnotify.Create) {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			l.add(event.Name)
+		}
+	}
+}
+
+// Returns the next event, blocking until one arrives or until the timeout
+// expires if it is non-negative, and returns nil if the timeout expires or
+// if the watcher is closed
+func (l *LoxWatcher) nextEvent(timeout time.Duration) (*LoxDict, error) {
+	var timeoutChan <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+	for len(l.pending) == 0 {
+		if l.isClosed {
+			return nil, nil
+		}
+		select {
+		case event, ok := <-l.watcher.Events:
+			if !ok {
+				return nil, nil
+			}
+			l.queueEvent(event)
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return nil, nil
+			}
+			return nil, err
+		case <-timeoutChan:
+			return nil, nil
+		}
+	}
+	event := l.pending[0]
+	l.pending = l.pending[1:]
+	return event, nil
+}
+
+// Returns an iterator that blocks until the next event arrives, skipping
+// over any errors, and ends once the watcher is closed
+func (l *LoxWatcher) eventIterator() interfaces.Iterator {
+	var next *LoxDict
+	advance := func() {
+		for next == nil && !l.isClosed {
+			event, err := l.nextEvent(-1)
+			if err == nil && event == nil {
+				return
+			}
+			next = event
+		}
+	}
+	return ProtoIterator{
+		hasNextMethod: func() bool {
+			advance()
+			return next != nil
+		},
+		nextMethod: func() any {
+			advance()
+			event := next
+			next = nil
+			return event
+		},
+	}
+}
+
+func (l *LoxWatcher) Get(name *token.Token) (any, error) {
+	methodName := name.Lexeme
+	if method, ok := l.methods[methodName]; ok {
+		return method, nil
+	}
+	watcherFunc := func(arity int, method func(*Interpreter, list.List[any]) (any, error)) (*struct{ ProtoLoxCallable }, error) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native watcher fn %v at %p>", methodName, &s)
+		}
+		if _, ok := l.methods[methodName]; !ok {
+			l.methods[methodName] = s
+		}
+		return s, nil
+	}
+	argMustBeType := func(theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'watcher.%v' must be a %v.", methodName, theType)
+		return nil, loxerror.RuntimeError(name, errStr)
+	}
+	watcherClosedErr := func() (any, error) {
+		return nil, loxerror.RuntimeError(name,
+			fmt.Sprintf("Cannot call 'watcher.%v' on closed watcher.", methodName))
+	}
+	switch methodName {
+	case "add":
+		return watcherFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if _, ok := args[0].(*LoxString); !ok {
+				return argMustBeType("string")
+			}
+			if l.isClosed {
+				return watcherClosedErr()
+			}
+			if err := l.add(args[0].(*LoxString).str); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return l, nil
+		})
+	case "close":
+		return watcherFunc(0, func(in *Interpreter, _ list.List[any]) (any, error) {
+			if err := l.close(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "events":
+		return watcherFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return NewLoxIterator(l.eventIterator()), nil
+		})
+	case "isClosed":
+		return watcherFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			return l.isClosed, nil
+		})
+	case "next":
+		return watcherFunc(-1, func(in *Interpreter, args list.List[any]) (any, error) {
+			timeout := time.Duration(-1)
+			switch len(args) {
+			case 0:
+			case 1:
+				switch arg := args[0].(type) {
+				case int64:
+					timeout = time.Duration(arg) * time.Second
+				case float64:
+					timeout = time.Duration(arg * float64(time.Second))
+				case *LoxDuration:
+					timeout = arg.duration
+				default:
+					return argMustBeType("number or duration")
+				}
+				if timeout < 0 {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Argument to 'watcher.next' cannot be negative.")
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			event, err := l.nextEvent(timeout)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			if event == nil {
+				return nil, nil
+			}
+			return event, nil
+		})
+	case "paths":
+		return watcherFunc(0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			paths := list.NewList[any]()
+			if !l.isClosed {
+				watchList := l.watcher.WatchList()
+				slices.Sort(watchList)
+				for _, path := range watchList {
+					paths.Add(NewLoxStringQuote(path))
+				}
+			}
+			return NewLoxList(paths), nil
+		})
+	case "remove":
+		return watcherFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if _, ok := args[0].(*LoxString); !ok {
+				return argMustBeType("string")
+			}
+			if l.isClosed {
+				return watcherClosedErr()
+			}
+			if err := l.watcher.Remove(args[0].(*LoxString).str); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		})
+	case "run":
+		return watcherFunc(1, func(in *Interpreter, args list.List[any]) (any, error) {
+			callback, ok := args[0].(*LoxFunction)
+			if !ok {
+				return argMustBeType("function")
+			}
+			argList := getArgList(callback, 1)
+			defer argList.Clear()
+			for !l.isClosed {
+				event, err := l.nextEvent(-1)
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				if event == nil {
+					break
+				}
+				argList[0] = event
+				result, resultErr := callback.call(in, argList)
+				if resultReturn, ok := result.(Return); ok {
+					result = resultReturn.FinalValue
+				} else if resultErr != nil {
+					return nil, resultErr
+				}
+				if result == false {
+					break
+				}
+			}
+			return nil, nil
+		})
+	}
+	return nil, loxerror.RuntimeError(name, "Watchers have no property called '"+methodName+"'.")
+}
+
+func (l *LoxWatcher) String() string {
+	return fmt.Sprintf("<watcher at %p>", l)
+}
+
+func (l *LoxWatcher) Type() string {
+	return "watcher"
+}
@@ -0,0 +1,957 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type markdownOptions struct {
+	fencedCode bool
+	hardWraps  bool
+	headingIDs bool
+	sanitize   bool
+	tables     bool
+}
+
+func defaultMarkdownOptions() markdownOptions {
+	return markdownOptions{
+		fencedCode: true,
+		tables:     true,
+	}
+}
+
+type markdownLinkDef struct {
+	dest  string
+	title string
+}
+
+type markdownInlineNode struct {
+	text     string
+	delim    byte
+	count    int
+	length   int
+	canOpen  bool
+	canClose bool
+}
+
+func (n *markdownInlineNode) html() string {
+	if n.delim != 0 {
+		return strings.Repeat(string(n.delim), n.count)
+	}
+	return n.text
+}
+
+type markdownRenderer struct {
+	options  markdownOptions
+	links    map[string]markdownLinkDef
+	ids      map[string]int
+	checkbox string
+	out      *strings.Builder
+}
+
+var (
+	mdATXHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*))?$`)
+	mdAutolink       = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\x00-\x20]*)>`)
+	mdBlockquote     = regexp.MustCompile(`^ {0,3}> ?`)
+	mdEmailAutolink  = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+	mdEntity         = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	mdFence          = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	mdHTMLBlock      = regexp.MustCompile(`^ {0,3}<(?:/?[A-Za-z][A-Za-z0-9-]*(?:[ \t/>]|$)|!--|\?|![A-Za-z])`)
+	mdInlineHTML     = regexp.MustCompile(`^(?:<[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[A-Za-z][A-Za-z0-9-]*\s*>|<!--[\s\S]*?-->)`)
+	mdLinkDef        = regexp.MustCompile(`^ {0,3}\[((?:[^\]\\]|\\.)+)\]:[ \t]*<?([^ \t<>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+	mdListItem       = regexp.MustCompile(`^( {0,3})([-+*]|[0-9]{1,9}[.)])(?:( +)(.*))?$`)
+	mdSetext         = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdTableDelimiter = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdTag            = regexp.MustCompile(`<[^>]*>`)
+	mdTaskItem       = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	mdThematicBreak  = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+)
+
+var markdownEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+)
+
+func markdownToHTML(source string, options markdownOptions) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(source, "\n")
+	for index, line := range lines {
+		lines[index] = markdownExpandTabs(line)
+	}
+	r := &markdownRenderer{
+		options: options,
+		links:   make(map[string]markdownLinkDef),
+		ids:     make(map[string]int),
+		out:     &strings.Builder{},
+	}
+	r.renderBlocks(r.collectLinkDefs(lines), false)
+	return r.out.String()
+}
+
+func markdownExpandTabs(line string) string {
+	if !strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+		return line
+	}
+	var builder strings.Builder
+	column := 0
+	for index, c := range line {
+		switch c {
+		case ' ':
+			builder.WriteByte(' ')
+			column++
+		case '\t':
+			spaces := 4 - column%4
+			builder.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		default:
+			builder.WriteString(line[index:])
+			return builder.String()
+		}
+	}
+	return builder.String()
+}
+
+func markdownIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func markdownIsBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func markdownIsPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func markdownNormalizeLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), " ")
+}
+
+func markdownUnescape(str string) string {
+	if !strings.Contains(str, "\\") {
+		return str
+	}
+	var builder strings.Builder
+	for index := 0; index < len(str); index++ {
+		if str[index] == '\\' && index+1 < len(str) && markdownIsPunct(str[index+1]) {
+			index++
+		}
+		builder.WriteByte(str[index])
+	}
+	return builder.String()
+}
+
+func markdownCodeSpan(str string) (string, int) {
+	run := len(str) - len(strings.TrimLeft(str, "`"))
+	for index := run; index < len(str); {
+		if str[index] != '`' {
+			index++
+			continue
+		}
+		end := index
+		for end < len(str) && str[end] == '`' {
+			end++
+		}
+		if end-index == run {
+			content := strings.ReplaceAll(str[run:index], "\n", " ")
+			if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' &&
+				strings.Trim(content, " ") != "" {
+				content = content[1 : len(content)-1]
+			}
+			return content, end
+		}
+		index = end
+	}
+	return "", 0
+}
+
+func markdownSplitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for index := 0; index < len(line); index++ {
+		switch {
+		case line[index] == '\\' && index+1 < len(line) && line[index+1] == '|':
+			cell.WriteByte('|')
+			index++
+		case line[index] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[index])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func (r *markdownRenderer) linkURL(dest string, image bool) string {
+	if r.options.sanitize {
+		lower := strings.ToLower(strings.TrimSpace(dest))
+		if colon := strings.IndexByte(lower, ':'); colon >= 0 && !strings.ContainsAny(lower[:colon], "/?#") {
+			switch scheme := lower[:colon]; scheme {
+			case "javascript", "vbscript", "file":
+				return "#"
+			case "data":
+				if !image || !strings.HasPrefix(lower, "data:image/") {
+					return "#"
+				}
+			}
+		}
+	}
+	var builder strings.Builder
+	for index := 0; index < len(dest); index++ {
+		c := dest[index]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			strings.IndexByte("-._~:/?#[]@!$&'()*+,;=%", c) >= 0:
+			builder.WriteByte(c)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
+
+func (r *markdownRenderer) collectLinkDefs(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	fence := ""
+	canDefine := true
+	for _, line := range lines {
+		if fence != "" {
+			trimmed := strings.TrimSpace(line)
+			if markdownIndent(line) <= 3 && strings.HasPrefix(trimmed, fence) &&
+				strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			result = append(result, line)
+			continue
+		}
+		if r.options.fencedCode {
+			if match := mdFence.FindStringSubmatch(line); match != nil {
+				fence = match[2]
+				result = append(result, line)
+				continue
+			}
+		}
+		if canDefine {
+			if match := mdLinkDef.FindStringSubmatch(line); match != nil {
+				label := markdownNormalizeLabel(match[1])
+				if _, ok := r.links[label]; !ok {
+					r.links[label] = markdownLinkDef{
+						dest:  markdownUnescape(match[2]),
+						title: markdownUnescape(match[3] + match[4] + match[5]),
+					}
+				}
+				continue
+			}
+		}
+		canDefine = markdownIsBlank(line)
+		result = append(result, line)
+	}
+	return result
+}
+
+func (r *markdownRenderer) isBlockStart(line string) bool {
+	if mdATXHeading.MatchString(line) || mdThematicBreak.MatchString(line) ||
+		mdBlockquote.MatchString(line) {
+		return true
+	}
+	if r.options.fencedCode && mdFence.MatchString(line) {
+		return true
+	}
+	if !r.options.sanitize && mdHTMLBlock.MatchString(line) {
+		return true
+	}
+	//Only non-empty bullet list items and ordered list items starting at 1
+	//can interrupt a paragraph
+	if match := mdListItem.FindStringSubmatch(line); match != nil && strings.TrimSpace(match[4]) != "" {
+		return !strings.HasSuffix(match[2], ".") && !strings.HasSuffix(match[2], ")") ||
+			strings.TrimLeft(match[2], "0") == "1"+match[2][len(match[2])-1:]
+	}
+	return false
+}
+
+func (r *markdownRenderer) isTableStart(lines []string, index int) bool {
+	if index+1 >= len(lines) || !strings.Contains(lines[index], "|") ||
+		!strings.Contains(lines[index+1], "|") || !mdTableDelimiter.MatchString(lines[index+1]) {
+		return false
+	}
+	return len(markdownSplitRow(lines[index])) == len(markdownSplitRow(lines[index+1]))
+}
+
+func (r *markdownRenderer) renderBlocks(lines []string, tight bool) (bool, bool) {
+	first, firstBare, lastBare := true, false, false
+	for index := 0; index < len(lines); {
+		line := lines[index]
+		if markdownIsBlank(line) {
+			index++
+			continue
+		}
+		bare := false
+		switch {
+		case r.options.fencedCode && mdFence.MatchString(line):
+			index = r.renderFencedCode(lines, index)
+		case markdownIndent(line) >= 4:
+			index = r.renderIndentedCode(lines, index)
+		case mdATXHeading.MatchString(line):
+			r.renderATXHeading(line)
+			index++
+		case mdThematicBreak.MatchString(line):
+			r.out.WriteString("<hr />\n")
+			index++
+		case mdBlockquote.MatchString(line):
+			index = r.renderBlockquote(lines, index)
+		case mdListItem.MatchString(line):
+			index = r.renderList(lines, index)
+		case !r.options.sanitize && mdHTMLBlock.MatchString(line):
+			index = r.renderHTMLBlock(lines, index)
+		case r.options.tables && r.isTableStart(lines, index):
+			index = r.renderTable(lines, index)
+		default:
+			index, bare = r.renderParagraph(lines, index, tight)
+		}
+		if first {
+			firstBare = bare
+			first = false
+		}
+		lastBare = bare
+	}
+	return firstBare, lastBare
+}
+
+func (r *markdownRenderer) renderATXHeading(line string) {
+	match := mdATXHeading.FindStringSubmatch(line)
+	content := strings.TrimSpace(match[2])
+	//Remove the optional closing sequence of #s
+	if trimmed := strings.TrimRight(content, "#"); trimmed == "" {
+		content = ""
+	} else if strings.HasSuffix(trimmed, " ") || strings.HasSuffix(trimmed, "\t") {
+		content = strings.TrimSpace(trimmed)
+	}
+	r.writeHeading(len(match[1]), content)
+}
+
+func (r *markdownRenderer) renderBlockquote(lines []string, start int) int {
+	var quoteLines []string
+	index := start
+	for ; index < len(lines); index++ {
+		line := lines[index]
+		if loc := mdBlockquote.FindStringIndex(line); loc != nil {
+			quoteLines = append(quoteLines, line[loc[1]:])
+			continue
+		}
+		//Lazy continuation lines continue the paragraph inside the quote
+		if markdownIsBlank(line) || markdownIsBlank(quoteLines[len(quoteLines)-1]) ||
+			r.isBlockStart(line) {
+			break
+		}
+		quoteLines = append(quoteLines, line)
+	}
+	r.out.WriteString("<blockquote>\n")
+	r.renderBlocks(quoteLines, false)
+	r.out.WriteString("</blockquote>\n")
+	return index
+}
+
+func (r *markdownRenderer) renderFencedCode(lines []string, start int) int {
+	match := mdFence.FindStringSubmatch(lines[start])
+	indent, fence, info := len(match[1]), match[2], match[3]
+	var code strings.Builder
+	index := start + 1
+	for ; index < len(lines); index++ {
+		line := lines[index]
+		trimmed := strings.TrimSpace(line)
+		if markdownIndent(line) <= 3 && strings.HasPrefix(trimmed, fence) &&
+			strings.Trim(trimmed, fence[:1]) == "" {
+			index++
+			break
+		}
+		code.WriteString(line[min(indent, markdownIndent(line)):])
+		code.WriteByte('\n')
+	}
+	r.out.WriteString("<pre><code")
+	if fields := strings.Fields(markdownUnescape(info)); len(fields) > 0 {
+		fmt.Fprintf(r.out, ` class="language-%v"`, markdownEscaper.Replace(fields[0]))
+	}
+	r.out.WriteString(">" + markdownEscaper.Replace(code.String()) + "</code></pre>\n")
+	return index
+}
+
+func (r *markdownRenderer) renderHTMLBlock(lines []string, start int) int {
+	index := start
+	for ; index < len(lines) && !markdownIsBlank(lines[index]); index++ {
+		r.out.WriteString(lines[index])
+		r.out.WriteByte('\n')
+	}
+	return index
+}
+
+func (r *markdownRenderer) renderIndentedCode(lines []string, start int) int {
+	end := start
+	for index := start; index < len(lines); index++ {
+		if markdownIsBlank(lines[index]) {
+			continue
+		}
+		if markdownIndent(lines[index]) < 4 {
+			break
+		}
+		end = index + 1
+	}
+	var code strings.Builder
+	for _, line := range lines[start:end] {
+		if len(line) > 4 {
+			code.WriteString(line[4:])
+		}
+		code.WriteByte('\n')
+	}
+	r.out.WriteString("<pre><code>" + markdownEscaper.Replace(code.String()) + "</code></pre>\n")
+	return end
+}
+
+func (r *markdownRenderer) renderList(lines []string, start int) int {
+	firstMatch := mdListItem.FindStringSubmatch(lines[start])
+	marker := firstMatch[2]
+	delim := marker[len(marker)-1]
+	ordered := delim == '.' || delim == ')'
+	var items [][]string
+	index := start
+	for index < len(lines) {
+		match := mdListItem.FindStringSubmatch(lines[index])
+		if match == nil || match[2][len(match[2])-1] != delim || mdThematicBreak.MatchString(lines[index]) {
+			break
+		}
+		//The content of the item starts after the marker and up to 4 spaces,
+		//and following lines indented that far belong to the item
+		width := len(match[1]) + len(match[2])
+		content := match[4]
+		switch spaces := len(match[3]); {
+		case spaces == 0 || strings.TrimSpace(content) == "":
+			width++
+		case spaces > 4:
+			width++
+			content = strings.Repeat(" ", spaces-1) + content
+		default:
+			width += spaces
+		}
+		itemLines := []string{content}
+		for index++; index < len(lines); index++ {
+			line := lines[index]
+			switch {
+			case markdownIsBlank(line):
+				itemLines = append(itemLines, "")
+				continue
+			case markdownIndent(line) >= width:
+				itemLines = append(itemLines, line[width:])
+				continue
+			case itemLines[len(itemLines)-1] != "" && !r.isBlockStart(line) &&
+				!mdListItem.MatchString(line):
+				itemLines = append(itemLines, line)
+				continue
+			}
+			break
+		}
+		items = append(items, itemLines)
+	}
+
+	//A list is loose if any of its items are separated by blank lines or
+	//contain blocks separated by blank lines
+	loose := false
+	for itemIndex, itemLines := range items {
+		end := len(itemLines)
+		for end > 0 && itemLines[end-1] == "" {
+			end--
+		}
+		if end < len(itemLines) && itemIndex < len(items)-1 {
+			loose = true
+		}
+		inSublist := false
+		for lineIndex := 1; lineIndex < end; lineIndex++ {
+			line := itemLines[lineIndex]
+			if line == "" || markdownIndent(line) > 0 {
+				continue
+			}
+			isItem := mdListItem.MatchString(line)
+			if itemLines[lineIndex-1] == "" && (!isItem || !inSublist) {
+				loose = true
+			}
+			inSublist = isItem
+		}
+		items[itemIndex] = itemLines[:end]
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if num, _ := strconv.Atoi(marker[:len(marker)-1]); num != 1 {
+			fmt.Fprintf(r.out, "<ol start=\"%v\">\n", num)
+		} else {
+			r.out.WriteString("<ol>\n")
+		}
+	} else {
+		r.out.WriteString("<ul>\n")
+	}
+	for _, itemLines := range items {
+		r.renderListItem(itemLines, !loose)
+	}
+	fmt.Fprintf(r.out, "</%v>\n", tag)
+	return index
+}
+
+func (r *markdownRenderer) renderListItem(lines []string, tight bool) {
+	if len(lines) > 0 {
+		if match := mdTaskItem.FindStringSubmatch(lines[0]); match != nil {
+			if match[1] == " " {
+				r.checkbox = `<input type="checkbox" disabled="" /> `
+			} else {
+				r.checkbox = `<input type="checkbox" checked="" disabled="" /> `
+			}
+			lines[0] = lines[0][len(match[0]):]
+		}
+	}
+	out := r.out
+	r.out = &strings.Builder{}
+	firstBare, lastBare := r.renderBlocks(lines, tight)
+	content := r.out.String()
+	r.out = out
+	r.checkbox = ""
+	if lastBare {
+		content = strings.TrimSuffix(content, "\n")
+	}
+	if content == "" || firstBare {
+		r.out.WriteString("<li>" + content + "</li>\n")
+	} else {
+		r.out.WriteString("<li>\n" + content + "</li>\n")
+	}
+}
+
+func (r *markdownRenderer) renderParagraph(lines []string, start int, tight bool) (int, bool) {
+	var paraLines []string
+	index := start
+	for ; index < len(lines); index++ {
+		line := lines[index]
+		if markdownIsBlank(line) {
+			break
+		}
+		if len(paraLines) > 0 {
+			if match := mdSetext.FindStringSubmatch(line); match != nil {
+				level := 2
+				if match[1][0] == '=' {
+					level = 1
+				}
+				r.writeHeading(level, strings.TrimSpace(strings.Join(paraLines, "\n")))
+				return index + 1, false
+			}
+			if r.isBlockStart(line) {
+				break
+			}
+		}
+		paraLines = append(paraLines, strings.TrimLeft(line, " "))
+	}
+	inline := r.checkbox + r.renderInline(strings.TrimRight(strings.Join(paraLines, "\n"), " "))
+	r.checkbox = ""
+	if tight {
+		r.out.WriteString(inline + "\n")
+		return index, true
+	}
+	r.out.WriteString("<p>" + inline + "</p>\n")
+	return index, false
+}
+
+func (r *markdownRenderer) renderTable(lines []string, start int) int {
+	header := markdownSplitRow(lines[start])
+	aligns := make([]string, len(header))
+	for cellIndex, cell := range markdownSplitRow(lines[start+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns[cellIndex] = ` align="center"`
+		case left:
+			aligns[cellIndex] = ` align="left"`
+		case right:
+			aligns[cellIndex] = ` align="right"`
+		}
+	}
+	writeRow := func(cells []string, tag string) {
+		r.out.WriteString("<tr>\n")
+		for cellIndex, align := range aligns {
+			cell := ""
+			if cellIndex < len(cells) {
+				cell = r.renderInline(cells[cellIndex])
+			}
+			fmt.Fprintf(r.out, "<%v%v>%v</%v>\n", tag, align, cell, tag)
+		}
+		r.out.WriteString("</tr>\n")
+	}
+	r.out.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	r.out.WriteString("</thead>\n")
+	index := start + 2
+	for ; index < len(lines); index++ {
+		line := lines[index]
+		if markdownIsBlank(line) || r.isBlockStart(line) {
+			break
+		}
+		if index == start+2 {
+			r.out.WriteString("<tbody>\n")
+		}
+		writeRow(markdownSplitRow(line), "td")
+	}
+	if index > start+2 {
+		r.out.WriteString("</tbody>\n")
+	}
+	r.out.WriteString("</table>\n")
+	return index
+}
+
+func (r *markdownRenderer) writeHeading(level int, text string) {
+	inline := r.renderInline(text)
+	if !r.options.headingIDs {
+		fmt.Fprintf(r.out, "<h%v>%v</h%v>\n", level, inline, level)
+		return
+	}
+	//Heading IDs are generated from the text of the heading in the same way
+	//as GitHub, where duplicate IDs get a numbered suffix
+	var slug strings.Builder
+	plain := strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`).
+		Replace(mdTag.ReplaceAllString(inline, ""))
+	for _, c := range strings.ToLower(plain) {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_':
+			slug.WriteRune(c)
+		case c == ' ':
+			slug.WriteByte('-')
+		}
+	}
+	id := slug.String()
+	if id == "" {
+		id = "section"
+	}
+	if count := r.ids[id]; count > 0 {
+		r.ids[id]++
+		id = fmt.Sprintf("%v-%v", id, count)
+	} else {
+		r.ids[id] = 1
+	}
+	fmt.Fprintf(r.out, "<h%v id=\"%v\">%v</h%v>\n", level, markdownEscaper.Replace(id), inline, level)
+}
+
+func (r *markdownRenderer) parseLink(text string, start int, image bool) (string, int) {
+	closeIndex := -1
+	depth := 0
+loop:
+	for index := start; index < len(text); index++ {
+		switch text[index] {
+		case '\\':
+			index++
+		case '`':
+			if _, length := markdownCodeSpan(text[index:]); length > 0 {
+				index += length - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeIndex = index
+				break loop
+			}
+		}
+	}
+	if closeIndex < 0 {
+		return "", -1
+	}
+	label := text[start+1 : closeIndex]
+	dest, title, end, ok := markdownInlineLink(text, closeIndex+1)
+	if !ok {
+		ref := label
+		end = closeIndex + 1
+		if strings.HasPrefix(text[end:], "[") {
+			if refEnd := strings.IndexByte(text[end:], ']'); refEnd >= 0 {
+				if refLabel := text[end+1 : end+refEnd]; refLabel != "" {
+					ref = refLabel
+				}
+				end += refEnd + 1
+			}
+		}
+		def, found := r.links[markdownNormalizeLabel(ref)]
+		if !found {
+			return "", -1
+		}
+		dest, title = def.dest, def.title
+	}
+	titleAttr := ""
+	if title != "" {
+		titleAttr = ` title="` + markdownEscaper.Replace(title) + `"`
+	}
+	if image {
+		alt := mdTag.ReplaceAllString(r.renderInline(label), "")
+		return fmt.Sprintf(`<img src="%v" alt="%v"%v />`,
+			markdownEscaper.Replace(r.linkURL(dest, true)), alt, titleAttr), end
+	}
+	return fmt.Sprintf(`<a href="%v"%v>%v</a>`,
+		markdownEscaper.Replace(r.linkURL(dest, false)), titleAttr, r.renderInline(label)), end
+}
+
+func markdownInlineLink(text string, start int) (string, string, int, bool) {
+	if start >= len(text) || text[start] != '(' {
+		return "", "", 0, false
+	}
+	skipSpaces := func(index int) int {
+		for index < len(text) && (text[index] == ' ' || text[index] == '\n') {
+			index++
+		}
+		return index
+	}
+	index := skipSpaces(start + 1)
+	destStart := index
+	dest := ""
+	if index < len(text) && text[index] == '<' {
+		closeIndex := strings.IndexAny(text[index:], ">\n")
+		if closeIndex < 0 || text[index+closeIndex] != '>' {
+			return "", "", 0, false
+		}
+		dest = text[index+1 : index+closeIndex]
+		index += closeIndex + 1
+	} else {
+		depth := 0
+	loop:
+		for ; index < len(text); index++ {
+			switch c := text[index]; {
+			case c == '\\' && index+1 < len(text):
+				index++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c <= ' ':
+				break loop
+			}
+		}
+		dest = text[destStart:index]
+	}
+	title := ""
+	afterDest := index
+	index = skipSpaces(index)
+	if index < len(text) && index > afterDest && strings.IndexByte(`"'(`, text[index]) >= 0 {
+		closeChar := text[index]
+		if closeChar == '(' {
+			closeChar = ')'
+		}
+		closeIndex := -1
+		for titleIndex := index + 1; titleIndex < len(text); titleIndex++ {
+			if text[titleIndex] == '\\' {
+				titleIndex++
+			} else if text[titleIndex] == closeChar {
+				closeIndex = titleIndex
+				break
+			}
+		}
+		if closeIndex < 0 {
+			return "", "", 0, false
+		}
+		title = markdownUnescape(text[index+1 : closeIndex])
+		index = skipSpaces(closeIndex + 1)
+	}
+	if index >= len(text) || text[index] != ')' {
+		return "", "", 0, false
+	}
+	return markdownUnescape(dest), title, index + 1, true
+}
+
+func (r *markdownRenderer) renderInline(text string) string {
+	var nodes []*markdownInlineNode
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, &markdownInlineNode{text: buf.String()})
+			buf.Reset()
+		}
+	}
+	for index := 0; index < len(text); {
+		c := text[index]
+		switch c {
+		case '\\':
+			if index+1 < len(text) {
+				if next := text[index+1]; next == '\n' {
+					buf.WriteString("<br />\n")
+					index += 2
+					continue
+				} else if markdownIsPunct(next) {
+					buf.WriteString(markdownEscaper.Replace(string(next)))
+					index += 2
+					continue
+				}
+			}
+			buf.WriteByte('\\')
+			index++
+		case '`':
+			if code, length := markdownCodeSpan(text[index:]); length > 0 {
+				buf.WriteString("<code>" + markdownEscaper.Replace(code) + "</code>")
+				index += length
+			} else {
+				run := len(text[index:]) - len(strings.TrimLeft(text[index:], "`"))
+				buf.WriteString(text[index : index+run])
+				index += run
+			}
+		case '*', '_', '~':
+			run := len(text[index:]) - len(strings.TrimLeft(text[index:], string(c)))
+			if c == '~' && run != 2 {
+				buf.WriteString(text[index : index+run])
+				index += run
+				continue
+			}
+			flush()
+			before, after := ' ', ' '
+			if index > 0 {
+				before, _ = utf8.DecodeLastRuneInString(text[:index])
+			}
+			if index+run < len(text) {
+				after, _ = utf8.DecodeRuneInString(text[index+run:])
+			}
+			isPunct := func(c rune) bool {
+				return unicode.IsPunct(c) || unicode.IsSymbol(c)
+			}
+			leftFlanking := !unicode.IsSpace(after) &&
+				(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+			rightFlanking := !unicode.IsSpace(before) &&
+				(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+			node := &markdownInlineNode{delim: c, count: run, length: run}
+			if c == '_' {
+				node.canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+				node.canClose = rightFlanking && (!leftFlanking || isPunct(after))
+			} else {
+				node.canOpen = leftFlanking
+				node.canClose = rightFlanking
+			}
+			nodes = append(nodes, node)
+			index += run
+		case '!', '[':
+			linkStart := index
+			if c == '!' {
+				linkStart++
+			}
+			if linkStart < len(text) && text[linkStart] == '[' {
+				if html, end := r.parseLink(text, linkStart, c == '!'); end >= 0 {
+					buf.WriteString(html)
+					index = end
+					continue
+				}
+			}
+			buf.WriteByte(c)
+			index++
+		case '<':
+			if match := mdAutolink.FindStringSubmatch(text[index:]); match != nil {
+				fmt.Fprintf(&buf, `<a href="%v">%v</a>`,
+					markdownEscaper.Replace(r.linkURL(match[1], false)), markdownEscaper.Replace(match[1]))
+				index += len(match[0])
+			} else if match := mdEmailAutolink.FindStringSubmatch(text[index:]); match != nil {
+				fmt.Fprintf(&buf, `<a href="mailto:%v">%v</a>`,
+					markdownEscaper.Replace(match[1]), markdownEscaper.Replace(match[1]))
+				index += len(match[0])
+			} else if match := mdInlineHTML.FindString(text[index:]); match != "" && !r.options.sanitize {
+				buf.WriteString(match)
+				index += len(match)
+			} else {
+				buf.WriteString("&lt;")
+				index++
+			}
+		case '&':
+			if match := mdEntity.FindString(text[index:]); match != "" {
+				buf.WriteString(match)
+				index += len(match)
+			} else {
+				buf.WriteString("&amp;")
+				index++
+			}
+		case '>', '"':
+			buf.WriteString(markdownEscaper.Replace(string(c)))
+			index++
+		case '\n':
+			//Two or more spaces at the end of a line make a hard line break
+			line := buf.String()
+			trimmed := strings.TrimRight(line, " ")
+			buf.Reset()
+			buf.WriteString(trimmed)
+			if len(line)-len(trimmed) >= 2 || r.options.hardWraps {
+				buf.WriteString("<br />\n")
+			} else {
+				buf.WriteByte('\n')
+			}
+			for index++; index < len(text) && text[index] == ' '; index++ {
+			}
+		default:
+			buf.WriteByte(c)
+			index++
+		}
+	}
+	flush()
+
+	var result strings.Builder
+	for _, node := range markdownEmphasis(nodes) {
+		result.WriteString(node.html())
+	}
+	return result.String()
+}
+
+func markdownEmphasis(nodes []*markdownInlineNode) []*markdownInlineNode {
+	for closerIndex := 0; closerIndex < len(nodes); closerIndex++ {
+		closer := nodes[closerIndex]
+		if closer.delim == 0 || !closer.canClose {
+			continue
+		}
+		for closer.count > 0 {
+			openerIndex := closerIndex - 1
+			for ; openerIndex >= 0; openerIndex-- {
+				opener := nodes[openerIndex]
+				if opener.delim != closer.delim || !opener.canOpen || opener.count == 0 {
+					continue
+				}
+				//If either delimiter can both open and close, the sum of
+				//their lengths can't be a multiple of 3 unless both are
+				if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 &&
+					(opener.length%3 != 0 || closer.length%3 != 0) {
+					continue
+				}
+				break
+			}
+			if openerIndex < 0 {
+				break
+			}
+			opener := nodes[openerIndex]
+			use, tag := 1, "em"
+			switch {
+			case closer.delim == '~':
+				use, tag = 2, "del"
+			case opener.count >= 2 && closer.count >= 2:
+				use, tag = 2, "strong"
+			}
+			opener.count -= use
+			closer.count -= use
+			var inner strings.Builder
+			for _, node := range nodes[openerIndex+1 : closerIndex] {
+				inner.WriteString(node.html())
+			}
+			emphasis := &markdownInlineNode{
+				text: fmt.Sprintf("<%v>%v</%v>", tag, inner.String(), tag),
+			}
+			nodes = append(nodes[:openerIndex+1],
+				append([]*markdownInlineNode{emphasis}, nodes[closerIndex:]...)...)
+			closerIndex = openerIndex + 2
+		}
+	}
+	return nodes
+}
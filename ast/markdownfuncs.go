@@ -0,0 +1,117 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func parseMarkdownOptions(callToken *token.Token, fnName string, optionsDict *LoxDict, options *markdownOptions) error {
+	return forEachOption(optionsDict, func(key string, value any) error {
+		var field *bool
+		switch key {
+		case "fencedCode":
+			field = &options.fencedCode
+		case "hardWraps":
+			field = &options.hardWraps
+		case "headingIDs":
+			field = &options.headingIDs
+		case "sanitize":
+			field = &options.sanitize
+		case "tables":
+			field = &options.tables
+		default:
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+		}
+		boolValue, ok := value.(bool)
+		if !ok {
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Option '%v' in '%v' must be a boolean.", key, fnName))
+		}
+		*field = boolValue
+		return nil
+	})
+}
+
+func (i *Interpreter) defineMarkdownFuncs() {
+	className := "markdown"
+	markdownClass := NewLoxClass(className, nil, false)
+	markdownFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native markdown fn %v at %p>", name, &s)
+		}
+		markdownClass.classProperties[name] = s
+	}
+	//Returns the string argument and the Markdown options in the optional
+	//dictionary argument after it
+	getArgs := func(callToken *token.Token, fnName string, args list.List[any]) (string, markdownOptions, error) {
+		options := defaultMarkdownOptions()
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return "", options, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		loxStr, ok := args[0].(*LoxString)
+		if !ok {
+			return "", options, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First argument to '%v' must be a string.", fnName))
+		}
+		if argsLen == 2 {
+			optionsDict, ok := args[1].(*LoxDict)
+			if !ok {
+				return "", options, loxerror.RuntimeError(callToken,
+					fmt.Sprintf("Second argument to '%v' must be a dictionary.", fnName))
+			}
+			if err := parseMarkdownOptions(callToken, fnName, optionsDict, &options); err != nil {
+				return "", options, err
+			}
+		}
+		return loxStr.str, options, nil
+	}
+
+	markdownFunc("escape", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			var builder strings.Builder
+			for _, c := range loxStr.str {
+				if c < 128 && markdownIsPunct(byte(c)) {
+					builder.WriteByte('\\')
+				}
+				builder.WriteRune(c)
+			}
+			return NewLoxStringQuote(builder.String()), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			"Argument to 'markdown.escape' must be a string.")
+	})
+	markdownFunc("fileToHTML", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		path, options, err := getArgs(in.callToken, "markdown.fileToHTML", args)
+		if err != nil {
+			return nil, err
+		}
+		if sandboxErr := checkSandbox(in.callToken, "Reading files"); sandboxErr != nil {
+			return nil, sandboxErr
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxStringQuote(markdownToHTML(string(data), options)), nil
+	})
+	markdownFunc("toHTML", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		source, options, err := getArgs(in.callToken, "markdown.toHTML", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(markdownToHTML(source, options)), nil
+	})
+
+	i.globals.Define(className, markdownClass)
+}
@@ -3,10 +3,12 @@ package ast
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
 	"github.com/AlanLuu/lox/util"
 )
 
@@ -65,6 +67,29 @@ func (i *Interpreter) defineMathFuncs() {
 				fmt.Sprintf("First argument to 'Math.%v' must be an integer or float.", name))
 		})
 	}
+	//Converts the arguments of a number theory method to bigints, also
+	//returning whether any of the arguments were bigints
+	intArgs := func(callToken *token.Token, name string, args list.List[any]) ([]*big.Int, bool, error) {
+		ordinals := []string{"First", "Second", "Third"}
+		nums := make([]*big.Int, len(args))
+		anyBig := false
+		for index, arg := range args {
+			num, isBig, ok := mathIntArg(arg)
+			if !ok {
+				var errStr string
+				if len(args) == 1 {
+					errStr = fmt.Sprintf("Argument to 'Math.%v' must be an integer or bigint.", name)
+				} else {
+					errStr = fmt.Sprintf("%v argument to 'Math.%v' must be an integer or bigint.",
+						ordinals[index], name)
+				}
+				return nil, false, loxerror.RuntimeError(callToken, errStr)
+			}
+			nums[index] = num
+			anyBig = anyBig || isBig
+		}
+		return nums, anyBig, nil
+	}
 	zeroArgFuncs := map[string]func() float64{
 		"random": rand.Float64,
 	}
@@ -167,6 +192,22 @@ func (i *Interpreter) defineMathFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			"First argument to 'Math.dim' must be an integer or float.")
 	})
+	mathFunc("factorize", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "factorize", args)
+		if err != nil {
+			return nil, err
+		}
+		if nums[0].Sign() <= 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Argument to 'Math.factorize' must be positive.")
+		}
+		factors := mathFactorize(nums[0])
+		factorsList := list.NewListCap[any](int64(len(factors)))
+		for _, factor := range factors {
+			factorsList.Add(mathIntResult(factor, anyBig))
+		}
+		return NewLoxList(factorsList), nil
+	})
 	mathFunc("floor", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		switch num := args[0].(type) {
 		case int64:
@@ -177,6 +218,45 @@ func (i *Interpreter) defineMathFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			"Argument to 'Math.floor' must be an integer or float.")
 	})
+	mathFunc("gcd", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "gcd", args)
+		if err != nil {
+			return nil, err
+		}
+		return mathIntResult(new(big.Int).GCD(nil, nil, nums[0], nums[1]), anyBig), nil
+	})
+	mathFunc("isPrime", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, _, err := intArgs(in.callToken, "isPrime", args)
+		if err != nil {
+			return nil, err
+		}
+		return nums[0].Sign() > 0 && mathIsPrime(nums[0]), nil
+	})
+	mathFunc("isqrt", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "isqrt", args)
+		if err != nil {
+			return nil, err
+		}
+		if nums[0].Sign() < 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Argument to 'Math.isqrt' cannot be negative.")
+		}
+		return mathIntResult(new(big.Int).Sqrt(nums[0]), anyBig), nil
+	})
+	mathFunc("lcm", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "lcm", args)
+		if err != nil {
+			return nil, err
+		}
+		if nums[0].Sign() == 0 || nums[1].Sign() == 0 {
+			return mathIntResult(new(big.Int), anyBig), nil
+		}
+		//lcm(a, b) = |a * b| / gcd(a, b)
+		result := new(big.Int).Mul(nums[0], nums[1])
+		result.Abs(result)
+		result.Quo(result, new(big.Int).GCD(nil, nil, nums[0], nums[1]))
+		return mathIntResult(result, anyBig), nil
+	})
 	mathFunc("max", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		fun := math.Max
 		secondArgMsg := "Second argument to 'Math.max' must be an integer or float."
@@ -231,6 +311,55 @@ func (i *Interpreter) defineMathFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			"First argument to 'Math.min' must be an integer or float.")
 	})
+	mathFunc("modInverse", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "modInverse", args)
+		if err != nil {
+			return nil, err
+		}
+		if nums[1].Sign() <= 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Second argument to 'Math.modInverse' must be positive.")
+		}
+		if nums[1].Cmp(big.NewInt(1)) == 0 {
+			return mathIntResult(new(big.Int), anyBig), nil
+		}
+		result := new(big.Int).ModInverse(nums[0], nums[1])
+		if result == nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("%v has no inverse modulo %v.", nums[0], nums[1]))
+		}
+		return mathIntResult(result, anyBig), nil
+	})
+	mathFunc("modPow", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "modPow", args)
+		if err != nil {
+			return nil, err
+		}
+		base, exponent, modulus := nums[0], nums[1], nums[2]
+		if modulus.Sign() <= 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Third argument to 'Math.modPow' must be positive.")
+		}
+		if exponent.Sign() < 0 {
+			//A negative exponent raises the inverse of the base to the
+			//absolute value of the exponent
+			base = new(big.Int).ModInverse(base, modulus)
+			if base == nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("%v has no inverse modulo %v.", nums[0], modulus))
+			}
+			exponent = new(big.Int).Neg(exponent)
+		}
+		result := new(big.Int).Exp(base, exponent, modulus)
+		return mathIntResult(result.Mod(result, modulus), anyBig), nil
+	})
+	mathFunc("nextPrime", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		nums, anyBig, err := intArgs(in.callToken, "nextPrime", args)
+		if err != nil {
+			return nil, err
+		}
+		return mathIntResult(mathNextPrime(nums[0]), anyBig), nil
+	})
 	mathFunc("round", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		switch num := args[0].(type) {
 		case int64:
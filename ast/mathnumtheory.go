@@ -0,0 +1,128 @@
+package ast
+
+import (
+	"math/big"
+	"slices"
+)
+
+// Number of Miller-Rabin rounds used when testing bigints for primality, in
+// addition to the Baillie-PSW test that big.Int.ProbablyPrime always performs
+const mathPrimeRounds = 20
+
+// Returns the specified integer or bigint as a bigint, along with whether
+// the value was originally a bigint
+func mathIntArg(arg any) (*big.Int, bool, bool) {
+	switch arg := arg.(type) {
+	case int64:
+		return big.NewInt(arg), false, true
+	case *big.Int:
+		return arg, true, true
+	}
+	return nil, false, false
+}
+
+// Returns x as a bigint if any of the arguments were bigints, otherwise as an
+// integer if it fits in one
+func mathIntResult(x *big.Int, anyBig bool) any {
+	if anyBig {
+		return x
+	}
+	return bigIntOrInt(x)
+}
+
+// Reports whether n is prime. The result is always correct for values below
+// 2^64 and correct with overwhelming probability for larger values
+func mathIsPrime(n *big.Int) bool {
+	if n.IsUint64() {
+		return n.ProbablyPrime(0)
+	}
+	return n.ProbablyPrime(mathPrimeRounds)
+}
+
+// Returns the smallest prime that is greater than n
+func mathNextPrime(n *big.Int) *big.Int {
+	two := big.NewInt(2)
+	if n.Cmp(two) < 0 {
+		return two
+	}
+	candidate := new(big.Int).Add(n, big.NewInt(1))
+	if candidate.Bit(0) == 0 {
+		if candidate.Cmp(two) == 0 {
+			return candidate
+		}
+		candidate.Add(candidate, big.NewInt(1))
+	}
+	for !mathIsPrime(candidate) {
+		candidate.Add(candidate, two)
+	}
+	return candidate
+}
+
+// Returns a nontrivial factor of the odd composite number n using Pollard's
+// rho algorithm
+func mathPollardRho(n *big.Int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		bigC := big.NewInt(c)
+		next := func(v *big.Int) {
+			v.Mul(v, v)
+			v.Add(v, bigC)
+			v.Mod(v, n)
+		}
+		x, y, d := big.NewInt(2), big.NewInt(2), big.NewInt(1)
+		for d.Cmp(one) == 0 {
+			next(x)
+			next(y)
+			next(y)
+			d.Sub(x, y)
+			d.Abs(d)
+			d.GCD(nil, nil, d, n)
+		}
+		//A factor equal to n means the cycle was found without splitting n,
+		//so try again with a different polynomial
+		if d.Cmp(n) != 0 {
+			return d
+		}
+	}
+}
+
+// Returns the prime factors of n in ascending order, where each factor is
+// repeated as many times as it divides n and n is positive
+func mathFactorize(n *big.Int) []*big.Int {
+	factors := []*big.Int{}
+	n = new(big.Int).Set(n)
+	quotient, remainder := new(big.Int), new(big.Int)
+	//Remove small factors by trial division before using Pollard's rho
+	for p := int64(2); p < 1000; p++ {
+		bigP := big.NewInt(p)
+		if new(big.Int).Mul(bigP, bigP).Cmp(n) > 0 {
+			break
+		}
+		for {
+			quotient.QuoRem(n, bigP, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			factors = append(factors, bigP)
+			n.Set(quotient)
+		}
+	}
+	var split func(n *big.Int)
+	split = func(n *big.Int) {
+		if n.Cmp(big.NewInt(1)) == 0 {
+			return
+		}
+		if mathIsPrime(n) {
+			factors = append(factors, n)
+			return
+		}
+		d := mathPollardRho(n)
+		split(d)
+		split(new(big.Int).Quo(n, d))
+	}
+	split(n)
+	slices.SortFunc(factors, func(a *big.Int, b *big.Int) int {
+		return a.Cmp(b)
+	})
+	return factors
+}
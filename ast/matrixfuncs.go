@@ -0,0 +1,135 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineMatrixFuncs() {
+	className := "matrix"
+	matrixClass := NewLoxClass(className, nil, false)
+	matrixFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native matrix fn %v at %p>", name, &s)
+		}
+		matrixClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'matrix.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	//Returns the dimension arguments, which must be positive integers
+	dimensionArgs := func(callToken *token.Token, name string, args list.List[any]) (int, int, error) {
+		rows, ok := args[0].(int64)
+		if !ok {
+			return 0, 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First argument to 'matrix.%v' must be an integer.", name))
+		}
+		cols, ok := args[1].(int64)
+		if !ok {
+			return 0, 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Second argument to 'matrix.%v' must be an integer.", name))
+		}
+		if rows < 1 || cols < 1 {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Matrix dimensions in 'matrix.%v' must be at least 1.", name))
+		}
+		if rows > math.MaxInt64/8/cols {
+			return 0, 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("Matrix dimensions in 'matrix.%v' are too large.", name))
+		}
+		if limitErr := checkSizeLimit(callToken, rows*cols*8); limitErr != nil {
+			return 0, 0, limitErr
+		}
+		return int(rows), int(cols), nil
+	}
+	filled := func(callToken *token.Token, name string, args list.List[any], value float64) (any, error) {
+		rows, cols, err := dimensionArgs(callToken, name, args)
+		if err != nil {
+			return nil, err
+		}
+		matrix := NewLoxMatrix(rows, cols)
+		if value != 0 {
+			for index := range matrix.data {
+				matrix.data[index] = value
+			}
+		}
+		return matrix, nil
+	}
+
+	matrixFunc("diagonal", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxList, ok := args[0].(*LoxList); ok {
+			vector, err := matrixVectorFromList(loxList)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+			}
+			n := vector.rows
+			matrix := NewLoxMatrix(n, n)
+			for index, value := range vector.data {
+				matrix.data[index*n+index] = value
+			}
+			return matrix, nil
+		}
+		return argMustBeType(in.callToken, "diagonal", "list")
+	})
+	matrixFunc("fill", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		value, ok := matrixNumber(args[2])
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Third argument to 'matrix.fill' must be an integer or float.")
+		}
+		return filled(in.callToken, "fill", args, value)
+	})
+	matrixFunc("identity", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if n, ok := args[0].(int64); ok {
+			if n < 1 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					"Argument to 'matrix.identity' must be at least 1.")
+			}
+			if n > math.MaxInt64/8/n {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					"Argument to 'matrix.identity' is too large.")
+			}
+			if limitErr := checkSizeLimit(in.callToken, n*n*8); limitErr != nil {
+				return nil, limitErr
+			}
+			return NewLoxMatrixIdentity(int(n)), nil
+		}
+		return argMustBeType(in.callToken, "identity", "integer")
+	})
+	matrixFunc("new", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxList, ok := args[0].(*LoxList); ok {
+			matrix, err := matrixFromList(loxList)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+			}
+			return matrix, nil
+		}
+		return argMustBeType(in.callToken, "new", "list")
+	})
+	matrixFunc("ones", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		return filled(in.callToken, "ones", args, 1)
+	})
+	matrixFunc("vector", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxList, ok := args[0].(*LoxList); ok {
+			vector, err := matrixVectorFromList(loxList)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+			}
+			return vector, nil
+		}
+		return argMustBeType(in.callToken, "vector", "list")
+	})
+	matrixFunc("zeros", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		return filled(in.callToken, "zeros", args, 0)
+	})
+
+	i.globals.Define(className, matrixClass)
+}
@@ -0,0 +1,215 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Returns the file extension of the specified file name or extension,
+// including the leading dot
+func mimeExtension(name string) string {
+	if !strings.Contains(name, ".") {
+		return "." + name
+	}
+	return filepath.Ext(name)
+}
+
+// Returns a dictionary of the specified MIME headers, where each value is a
+// list of strings
+func mimeHeaderDict(header map[string][]string) *LoxDict {
+	dict := EmptyLoxDict()
+	for key, values := range header {
+		valuesList := list.NewListCap[any](int64(len(values)))
+		for _, value := range values {
+			valuesList.Add(NewLoxStringQuote(value))
+		}
+		dict.setKeyValue(NewLoxString(key, '\''), NewLoxList(valuesList))
+	}
+	return dict
+}
+
+// Adds the specified value to the list stored under the specified key in
+// the dictionary, creating the list if it doesn't exist
+func mimeDictAppend(dict *LoxDict, key string, value any) {
+	loxKey := NewLoxString(key, '\'')
+	if existing, ok := dict.getValueByKey(loxKey); ok {
+		existing.(*LoxList).elements.Add(value)
+		return
+	}
+	values := list.NewListCap[any](1)
+	values.Add(value)
+	dict.setKeyValue(loxKey, NewLoxList(values))
+}
+
+// Parses the specified multipart body into a dictionary of its form fields
+// and a dictionary of its file parts
+func mimeParseMultipart(body []byte, boundary string) (*LoxDict, *LoxDict, error) {
+	fields, files := EmptyLoxDict(), EmptyLoxDict()
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if part.FileName() == "" {
+			mimeDictAppend(fields, part.FormName(), NewLoxStringQuote(string(data)))
+			continue
+		}
+		contentType := part.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		file := EmptyLoxDict()
+		file.setKeyValue(NewLoxString("contentType", '\''), NewLoxStringQuote(contentType))
+		file.setKeyValue(NewLoxString("data", '\''), NewLoxBufferFromBytes(data))
+		file.setKeyValue(NewLoxString("filename", '\''), NewLoxStringQuote(part.FileName()))
+		file.setKeyValue(NewLoxString("headers", '\''), mimeHeaderDict(part.Header))
+		mimeDictAppend(files, part.FormName(), file)
+	}
+	return fields, files, nil
+}
+
+func (i *Interpreter) defineMimeFuncs() {
+	className := "mime"
+	mimeClass := NewLoxClass(className, nil, false)
+	mimeFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native mime fn %v at %p>", name, &s)
+		}
+		mimeClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'mime.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	mimeFunc("buildMultipart", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		fieldsDict, ok := args[0].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'mime.buildMultipart' must be a dictionary.")
+		}
+		form := url.Values{}
+		if !httpStringListDict(fieldsDict, form.Add) {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Fields dictionary in 'mime.buildMultipart' must only have strings or lists of strings.")
+		}
+		files := EmptyLoxDict()
+		if argsLen == 2 {
+			files, ok = args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'mime.buildMultipart' must be a dictionary.")
+			}
+		}
+		body, contentType, err := httpMultipartBody(in.callToken, "mime.buildMultipart", files, form)
+		if err != nil {
+			return nil, err
+		}
+		result := EmptyLoxDict()
+		result.setKeyValue(NewLoxString("body", '\''), NewLoxBufferFromBytes(body))
+		result.setKeyValue(NewLoxString("contentType", '\''), NewLoxStringQuote(contentType))
+		return result, nil
+	})
+	mimeFunc("extensionsByType", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			extensions, err := mime.ExtensionsByType(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+			}
+			slices.Sort(extensions)
+			extensionsList := list.NewListCap[any](int64(len(extensions)))
+			for _, extension := range extensions {
+				extensionsList.Add(NewLoxString(extension, '\''))
+			}
+			return NewLoxList(extensionsList), nil
+		}
+		return argMustBeType(in.callToken, "extensionsByType", "string")
+	})
+	mimeFunc("parseMultipart", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		var body []byte
+		switch arg := args[0].(type) {
+		case *LoxBuffer:
+			body = arg.bytes()
+		case *LoxString:
+			body = []byte(arg.str)
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'mime.parseMultipart' must be a buffer or string.")
+		}
+		contentType, ok := args[1].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'mime.parseMultipart' must be a string.")
+		}
+		//The second argument is either a Content-Type header value or the
+		//boundary itself
+		boundary := contentType.str
+		if mediaType, params, err := mime.ParseMediaType(contentType.str); err == nil &&
+			strings.HasPrefix(mediaType, "multipart/") {
+			boundary, ok = params["boundary"]
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					"Multipart content type in 'mime.parseMultipart' has no boundary.")
+			}
+		}
+		fields, files, err := mimeParseMultipart(body, boundary)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("Failed to parse multipart body: %v", err))
+		}
+		result := EmptyLoxDict()
+		result.setKeyValue(NewLoxString("fields", '\''), fields)
+		result.setKeyValue(NewLoxString("files", '\''), files)
+		return result, nil
+	})
+	mimeFunc("sniff", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch arg := args[0].(type) {
+		case *LoxBuffer:
+			return NewLoxStringQuote(http.DetectContentType(arg.bytes())), nil
+		case *LoxString:
+			return NewLoxStringQuote(http.DetectContentType([]byte(arg.str))), nil
+		}
+		return argMustBeType(in.callToken, "sniff", "buffer or string")
+	})
+	mimeFunc("typeByExtension", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			mimeType := mime.TypeByExtension(mimeExtension(loxStr.str))
+			if mimeType == "" {
+				return nil, nil
+			}
+			return NewLoxStringQuote(mimeType), nil
+		}
+		return argMustBeType(in.callToken, "typeByExtension", "string")
+	})
+
+	i.globals.Define(className, mimeClass)
+}
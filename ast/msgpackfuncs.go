@@ -0,0 +1,463 @@
+package ast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+// The MessagePack extension type used for timestamps, which is -1 as a
+// signed byte
+const msgpackTimestampExt = 0xff
+
+// The maximum nesting depth of arrays and maps that msgpack.decode accepts
+const msgpackMaxDepth = 10000
+
+type msgpackEncoder struct {
+	buf  []byte
+	seen map[any]bool
+}
+
+func (e *msgpackEncoder) writeUint(prefix byte, value uint64, size int) {
+	e.buf = append(e.buf, prefix)
+	switch size {
+	case 1:
+		e.buf = append(e.buf, byte(value))
+	case 2:
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(value))
+	case 4:
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(value))
+	case 8:
+		e.buf = binary.BigEndian.AppendUint64(e.buf, value)
+	}
+}
+
+func (e *msgpackEncoder) writeInt(value int64) {
+	switch {
+	case value >= 0 && value <= 0x7f:
+		e.buf = append(e.buf, byte(value))
+	case value < 0 && value >= -32:
+		e.buf = append(e.buf, byte(value))
+	case value > 0:
+		switch {
+		case value <= math.MaxUint8:
+			e.writeUint(0xcc, uint64(value), 1)
+		case value <= math.MaxUint16:
+			e.writeUint(0xcd, uint64(value), 2)
+		case value <= math.MaxUint32:
+			e.writeUint(0xce, uint64(value), 4)
+		default:
+			e.writeUint(0xcf, uint64(value), 8)
+		}
+	case value >= math.MinInt8 && value <= math.MaxInt8:
+		e.writeUint(0xd0, uint64(value), 1)
+	case value >= math.MinInt16 && value <= math.MaxInt16:
+		e.writeUint(0xd1, uint64(value), 2)
+	case value >= math.MinInt32 && value <= math.MaxInt32:
+		e.writeUint(0xd2, uint64(value), 4)
+	default:
+		e.writeUint(0xd3, uint64(value), 8)
+	}
+}
+
+// Writes the header of a value whose length is stored using one of the
+// specified prefixes for 8-bit, 16-bit, and 32-bit lengths
+func (e *msgpackEncoder) writeLen(length int, prefix8 byte, prefix16 byte, prefix32 byte) error {
+	switch {
+	case length <= math.MaxUint8 && prefix8 != 0:
+		e.writeUint(prefix8, uint64(length), 1)
+	case length <= math.MaxUint16:
+		e.writeUint(prefix16, uint64(length), 2)
+	case length <= math.MaxUint32:
+		e.writeUint(prefix32, uint64(length), 4)
+	default:
+		return loxerror.Error("Value is too large to be encoded as MessagePack.")
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encode(value any) error {
+	switch value := value.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if value {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case int64:
+		e.writeInt(value)
+	case float64:
+		e.writeUint(0xcb, math.Float64bits(value), 8)
+	case *big.Int:
+		switch {
+		case value.IsInt64():
+			e.writeInt(value.Int64())
+		case value.IsUint64():
+			e.writeUint(0xcf, value.Uint64(), 8)
+		default:
+			return loxerror.Error("Bigint is too large to be encoded as MessagePack.")
+		}
+	case *LoxString:
+		return e.encodeStr(value.str)
+	case LoxStringStr:
+		return e.encodeStr(value.str)
+	case *LoxBuffer:
+		bytes := value.bytes()
+		if err := e.writeLen(len(bytes), 0xc4, 0xc5, 0xc6); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, bytes...)
+	case *LoxDate:
+		e.encodeTimestamp(value.date)
+	case *LoxList:
+		if e.seen[value] {
+			return loxerror.Error("Cannot encode self-referential list as MessagePack.")
+		}
+		e.seen[value] = true
+		defer delete(e.seen, value)
+		length := len(value.elements)
+		if length <= 15 {
+			e.buf = append(e.buf, 0x90|byte(length))
+		} else if err := e.writeLen(length, 0, 0xdc, 0xdd); err != nil {
+			return err
+		}
+		for _, element := range value.elements {
+			if err := e.encode(element); err != nil {
+				return err
+			}
+		}
+	case *LoxDict:
+		if e.seen[value] {
+			return loxerror.Error("Cannot encode self-referential dictionary as MessagePack.")
+		}
+		e.seen[value] = true
+		defer delete(e.seen, value)
+		length := len(value.indexes)
+		if length <= 15 {
+			e.buf = append(e.buf, 0x80|byte(length))
+		} else if err := e.writeLen(length, 0, 0xde, 0xdf); err != nil {
+			return err
+		}
+		it := value.Iterator()
+		for it.HasNext() {
+			pair := it.Next().(*LoxList).elements
+			if err := e.encode(pair[0]); err != nil {
+				return err
+			}
+			if err := e.encode(pair[1]); err != nil {
+				return err
+			}
+		}
+	default:
+		return loxerror.Error(fmt.Sprintf("Type '%v' cannot be encoded as MessagePack.", getType(value)))
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeStr(str string) error {
+	length := len(str)
+	if length <= 31 {
+		e.buf = append(e.buf, 0xa0|byte(length))
+	} else if err := e.writeLen(length, 0xd9, 0xda, 0xdb); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, str...)
+	return nil
+}
+
+// Writes the specified time using the timestamp extension type, picking
+// the smallest of its three formats that can hold the time
+func (e *msgpackEncoder) encodeTimestamp(t time.Time) {
+	seconds := t.Unix()
+	nanos := uint64(t.Nanosecond())
+	switch {
+	case seconds>>34 == 0 && nanos == 0:
+		e.buf = append(e.buf, 0xd6, msgpackTimestampExt)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(seconds))
+	case seconds>>34 == 0:
+		e.buf = append(e.buf, 0xd7, msgpackTimestampExt)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, nanos<<34|uint64(seconds))
+	default:
+		e.buf = append(e.buf, 0xc7, 12, msgpackTimestampExt)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(nanos))
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(seconds))
+	}
+}
+
+// The number of bytes used to store the length of each MessagePack format
+// that has a variable length
+var msgpackLengthSizes = map[byte]int{
+	0xc4: 1, 0xc5: 2, 0xc6: 4,
+	0xc7: 1, 0xc8: 2, 0xc9: 4,
+	0xd9: 1, 0xda: 2, 0xdb: 4,
+	0xdc: 2, 0xdd: 4,
+	0xde: 2, 0xdf: 4,
+}
+
+type msgpackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+var errMsgpackEOF = loxerror.Error("Unexpected end of MessagePack data.")
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errMsgpackEOF
+	}
+	bytes := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return bytes, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	bytes, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(bytes[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bytes)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bytes)), nil
+	}
+	return binary.BigEndian.Uint64(bytes), nil
+}
+
+func (d *msgpackDecoder) decode() (any, error) {
+	prefixBytes, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	prefix := prefixBytes[0]
+	switch {
+	case prefix <= 0x7f:
+		return int64(prefix), nil
+	case prefix >= 0xe0:
+		return int64(int8(prefix)), nil
+	case prefix&0xf0 == 0x80:
+		return d.decodeMap(int(prefix & 0x0f))
+	case prefix&0xf0 == 0x90:
+		return d.decodeArray(int(prefix & 0x0f))
+	case prefix&0xe0 == 0xa0:
+		return d.decodeStr(int(prefix & 0x1f))
+	}
+	length := 0
+	if size, ok := msgpackLengthSizes[prefix]; ok {
+		value, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		if value > uint64(len(d.data)) {
+			return nil, errMsgpackEOF
+		}
+		length = int(value)
+	}
+	switch prefix {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		bytes, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxBufferFromBytes(bytes), nil
+	case 0xc7, 0xc8, 0xc9:
+		return d.decodeExt(length)
+	case 0xca:
+		bits, err := d.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(bits))), nil
+	case 0xcb:
+		bits, err := d.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		value, err := d.readUint(1 << (prefix - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if value > math.MaxInt64 {
+			return new(big.Int).SetUint64(value), nil
+		}
+		return int64(value), nil
+	case 0xd0:
+		value, err := d.readUint(1)
+		return int64(int8(value)), err
+	case 0xd1:
+		value, err := d.readUint(2)
+		return int64(int16(value)), err
+	case 0xd2:
+		value, err := d.readUint(4)
+		return int64(int32(value)), err
+	case 0xd3:
+		value, err := d.readUint(8)
+		return int64(value), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (prefix - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		return d.decodeStr(length)
+	case 0xdc, 0xdd:
+		return d.decodeArray(length)
+	case 0xde, 0xdf:
+		return d.decodeMap(length)
+	}
+	return nil, loxerror.Error(fmt.Sprintf("Invalid MessagePack type byte 0x%02x.", prefix))
+}
+
+func (d *msgpackDecoder) decodeStr(length int) (any, error) {
+	bytes, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoxStringQuote(string(bytes)), nil
+}
+
+func (d *msgpackDecoder) enter() error {
+	d.depth++
+	if d.depth > msgpackMaxDepth {
+		return loxerror.Error("MessagePack data is nested too deeply.")
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) decodeArray(length int) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	elements := list.NewListCap[any](int64(min(length, len(d.data)-d.pos)))
+	for index := 0; index < length; index++ {
+		element, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		elements.Add(element)
+	}
+	return NewLoxList(elements), nil
+}
+
+func (d *msgpackDecoder) decodeMap(length int) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	dict := EmptyLoxDict()
+	for index := 0; index < length; index++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if ok, errStr := CanBeDictKeyCheck(key); !ok {
+			return nil, loxerror.Error(errStr)
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		dict.setKeyValue(key, value)
+	}
+	return dict, nil
+}
+
+func (d *msgpackDecoder) decodeExt(length int) (any, error) {
+	extType, err := d.readUint(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+	if extType != msgpackTimestampExt {
+		return nil, loxerror.Error(fmt.Sprintf("Unsupported MessagePack extension type %v.", int8(extType)))
+	}
+	switch length {
+	case 4:
+		return NewLoxDate(time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC()), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return NewLoxDate(time.Unix(int64(value&(1<<34-1)), int64(value>>34)).UTC()), nil
+	case 12:
+		nanos := binary.BigEndian.Uint32(data)
+		seconds := int64(binary.BigEndian.Uint64(data[4:]))
+		return NewLoxDate(time.Unix(seconds, int64(nanos)).UTC()), nil
+	}
+	return nil, loxerror.Error("Invalid MessagePack timestamp.")
+}
+
+func (i *Interpreter) defineMsgpackFuncs() {
+	className := "msgpack"
+	msgpackClass := NewLoxClass(className, nil, false)
+	msgpackFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native msgpack fn %v at %p>", name, &s)
+		}
+		msgpackClass.classProperties[name] = s
+	}
+
+	msgpackFunc("decode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		buffer, ok := args[0].(*LoxBuffer)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'msgpack.decode' must be a buffer.")
+		}
+		decoder := &msgpackDecoder{data: buffer.bytes()}
+		result, err := decoder.decode()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		if decoder.pos != len(decoder.data) {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Unexpected data after the end of the MessagePack value.")
+		}
+		return result, nil
+	})
+	msgpackFunc("decodeAll", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		buffer, ok := args[0].(*LoxBuffer)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'msgpack.decodeAll' must be a buffer.")
+		}
+		decoder := &msgpackDecoder{data: buffer.bytes()}
+		results := list.NewList[any]()
+		for decoder.pos < len(decoder.data) {
+			result, err := decoder.decode()
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			results.Add(result)
+		}
+		return NewLoxList(results), nil
+	})
+	msgpackFunc("encode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		encoder := &msgpackEncoder{seen: make(map[any]bool)}
+		if err := encoder.encode(args[0]); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxBufferFromBytes(encoder.buf), nil
+	})
+
+	i.globals.Define(className, msgpackClass)
+}
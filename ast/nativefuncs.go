@@ -1,9 +1,7 @@
 package ast
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"math/big"
 	"os"
 	"strconv"
@@ -17,19 +15,8 @@ import (
 	"github.com/AlanLuu/lox/loxerror"
 	"github.com/AlanLuu/lox/scanner"
 	"github.com/AlanLuu/lox/util"
-	"github.com/chzyer/readline"
-	"github.com/mattn/go-isatty"
 )
 
-var inputSc *bufio.Scanner
-var inputReadline *readline.Instance
-
-func CloseInputFuncReadline() {
-	if inputReadline != nil {
-		inputReadline.Close()
-	}
-}
-
 func (i *Interpreter) defineNativeFuncs() {
 	nativeFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
 		s := &struct{ ProtoLoxCallable }{}
@@ -142,6 +129,9 @@ func (i *Interpreter) defineNativeFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Argument to 'BufferCap' cannot be negative.")
 			}
+			if limitErr := checkSizeLimit(in.callToken, capacity*8); limitErr != nil {
+				return nil, limitErr
+			}
 			return EmptyLoxBufferCap(capacity), nil
 		}
 		return nil, loxerror.RuntimeError(in.callToken,
@@ -153,6 +143,9 @@ func (i *Interpreter) defineNativeFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Argument to 'BufferZero' cannot be negative.")
 			}
+			if limitErr := checkSizeLimit(in.callToken, size*8); limitErr != nil {
+				return nil, limitErr
+			}
 			buffer := EmptyLoxBufferCap(size)
 			for index := int64(0); index < size; index++ {
 				buffer.add(int64(0))
@@ -184,6 +177,9 @@ func (i *Interpreter) defineNativeFuncs() {
 	nativeFunc("clock", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 		return float64(time.Now().UnixMilli()) / 1000, nil
 	})
+	nativeFunc("deepEquals", 2, func(_ *Interpreter, args list.List[any]) (any, error) {
+		return deepEquals(args[0], args[1], make(map[valuesEqualPair]bool)), nil
+	})
 	nativeFunc("Deque", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		deque := NewLoxDeque()
 		for _, element := range args {
@@ -258,6 +254,9 @@ func (i *Interpreter) defineNativeFuncs() {
 		}
 		return args[0], nil
 	})
+	nativeFunc("getRecursionLimit", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return recursionLimit.Load(), nil
+	})
 	nativeFunc("hex", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if num, ok := args[0].(int64); ok {
 			return numToBaseStr(num, "0x", 16)
@@ -265,53 +264,6 @@ func (i *Interpreter) defineNativeFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			"Argument to 'hex' must be an integer.")
 	})
-	nativeFunc("input", -1, func(in *Interpreter, args list.List[any]) (any, error) {
-		var prompt any = ""
-		argsLen := len(args)
-		switch argsLen {
-		case 0:
-		case 1:
-			prompt = args[0]
-		default:
-			return nil, loxerror.RuntimeError(in.callToken,
-				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
-		}
-
-		var userInput string
-		fd := os.Stdin.Fd()
-		if isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd) {
-			if inputReadline == nil {
-				inputReadline, _ = readline.NewEx(&readline.Config{
-					Prompt:          getResult(prompt, prompt, true),
-					InterruptPrompt: "^C",
-				})
-			} else {
-				inputReadline.SetPrompt(getResult(prompt, prompt, true))
-			}
-
-			var readError error
-			userInput, readError = inputReadline.Readline()
-			switch readError {
-			case readline.ErrInterrupt:
-				return nil, loxerror.RuntimeError(in.callToken, "Keyboard interrupt")
-			case io.EOF:
-				return nil, nil
-			}
-		} else {
-			if inputSc == nil {
-				inputSc = bufio.NewScanner(os.Stdin)
-			}
-			if !inputSc.Scan() {
-				return nil, nil
-			}
-			userInput = inputSc.Text()
-		}
-
-		if strings.Contains(userInput, "'") {
-			return NewLoxString(userInput, '"'), nil
-		}
-		return NewLoxString(userInput, '\''), nil
-	})
 	nativeFunc("iterator", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if element, ok := args[0].(interfaces.Iterable); ok {
 			return NewLoxIterator(element.Iterator()), nil
@@ -332,6 +284,9 @@ func (i *Interpreter) defineNativeFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Argument to 'List' cannot be negative.")
 			}
+			if limitErr := checkSizeLimit(in.callToken, size*8); limitErr != nil {
+				return nil, limitErr
+			}
 			lst := list.NewListCap[any](size)
 			for index := int64(0); index < size; index++ {
 				lst.Add(nil)
@@ -347,6 +302,9 @@ func (i *Interpreter) defineNativeFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Argument to 'ListCap' cannot be negative.")
 			}
+			if limitErr := checkSizeLimit(in.callToken, capacity*8); limitErr != nil {
+				return nil, limitErr
+			}
 			return NewLoxList(list.NewListCap[any](capacity)), nil
 		}
 		return nil, loxerror.RuntimeError(in.callToken,
@@ -370,6 +328,9 @@ func (i *Interpreter) defineNativeFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Argument to 'ListZero' cannot be negative.")
 			}
+			if limitErr := checkSizeLimit(in.callToken, size*8); limitErr != nil {
+				return nil, limitErr
+			}
 			lst := list.NewListCap[any](size)
 			for index := int64(0); index < size; index++ {
 				lst.Add(int64(0))
@@ -459,6 +420,42 @@ func (i *Interpreter) defineNativeFuncs() {
 				fmt.Sprintf("Expected 1, 2, or 3 arguments but got %v.", argsLen))
 		}
 	})
+	nativeFunc("record", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		name, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'record' must be a string.")
+		}
+		if !isIdentifier(name.str) {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("Record name '%v' must be a valid identifier.", name.str))
+		}
+		fieldsList, ok := args[1].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'record' must be a list.")
+		}
+		fields := make([]string, 0, len(fieldsList.elements))
+		seen := make(map[string]bool, len(fieldsList.elements))
+		for _, element := range fieldsList.elements {
+			field, ok := element.(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Field names passed to 'record' must be strings.")
+			}
+			if !isIdentifier(field.str) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("Field name '%v' must be a valid identifier.", field.str))
+			}
+			if seen[field.str] {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("Duplicate field name '%v'.", field.str))
+			}
+			seen[field.str] = true
+			fields = append(fields, field.str)
+		}
+		return NewLoxRecordType(name.str, fields), nil
+	})
 	nativeFunc("repeatFunc", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(int64); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -507,6 +504,19 @@ func (i *Interpreter) defineNativeFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			fmt.Sprintf("Type '%v' is not iterable.", getType(args[0])))
 	})
+	nativeFunc("setRecursionLimit", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		limit, ok := args[0].(int64)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'setRecursionLimit' must be an integer.")
+		}
+		if limit < 1 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'setRecursionLimit' must be at least 1.")
+		}
+		recursionLimit.Store(limit)
+		return nil, nil
+	})
 	nativeFunc("sleep", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		switch seconds := args[0].(type) {
 		case int64:
@@ -520,6 +530,50 @@ func (i *Interpreter) defineNativeFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			"Argument to 'sleep' must be an integer or float.")
 	})
+	nativeFunc("SortedList", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		var iterable interfaces.Iterable
+		var compareFn *LoxFunction
+		if argsLen > 0 {
+			if callback, ok := args[argsLen-1].(*LoxFunction); ok {
+				compareFn = callback
+				args = args[:argsLen-1]
+			}
+		}
+		if len(args) == 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'SortedList' must be a function.")
+		}
+		if len(args) == 1 {
+			var ok bool
+			iterable, ok = args[0].(interfaces.Iterable)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Type '%v' is not iterable.", getType(args[0])))
+			}
+		}
+		sortedList := NewLoxSortedList(compareFn)
+		if iterable != nil {
+			it := iterable.Iterator()
+			for it.HasNext() {
+				if err := sortedList.add(in, it.Next()); err != nil {
+					return nil, sortedCompareError(in.callToken, err)
+				}
+			}
+		}
+		return sortedList, nil
+	})
+	nativeFunc("StringBuilder", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		builder := NewLoxStringBuilder()
+		for _, arg := range args {
+			builder.append(arg)
+		}
+		return builder, nil
+	})
 	nativeFunc("sum", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if element, ok := args[0].(interfaces.Iterable); ok {
 			sum := &LoxInternalSum{int64(0)}
@@ -535,6 +589,25 @@ func (i *Interpreter) defineNativeFuncs() {
 		return nil, loxerror.RuntimeError(in.callToken,
 			fmt.Sprintf("Type '%v' is not iterable.", getType(args[0])))
 	})
+	nativeFunc("taskgroup", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		callback, ok := args[0].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'taskgroup' must be a function.")
+		}
+		group := NewLoxTaskGroup()
+		argList := getArgList(callback, 1)
+		argList[0] = group
+		result, resultErr := callback.call(in, argList)
+		if _, ok := result.(Return); !ok && resultErr != nil {
+			group.fail(resultErr)
+		}
+		results, waitErr := group.wait()
+		if waitErr != nil {
+			return nil, waitErr
+		}
+		return NewLoxList(results), nil
+	})
 	nativeFunc("threadFunc", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(int64); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -558,10 +631,11 @@ func (i *Interpreter) defineNativeFuncs() {
 			callbackChan := make(chan struct{}, times)
 			callback := args[1].(*LoxFunction)
 			for i := int64(0); i < times; i++ {
+				threadIn := in.newTaskInterpreter()
 				go func(num int64) {
 					argList := getArgList(callback, 1)
 					argList[0] = num
-					result, resultErr := callback.call(in, argList)
+					result, resultErr := callback.call(threadIn, argList)
 					if resultErr != nil && result == nil {
 						errorChan <- errStruct{resultErr, num}
 					} else {
@@ -633,10 +707,11 @@ func (i *Interpreter) defineNativeFuncs() {
 			threadNum := int64(1)
 			for i := int64(0); i < times; i++ {
 				for _, callback := range callbacks {
+					threadIn := in.newTaskInterpreter()
 					go func(num int64) {
 						argList := getArgList(callback, 1)
 						argList[0] = num
-						result, resultErr := callback.call(in, argList)
+						result, resultErr := callback.call(threadIn, argList)
 						if resultErr != nil && result == nil {
 							errorChan <- errStruct{resultErr, num}
 						} else {
@@ -663,7 +738,54 @@ func (i *Interpreter) defineNativeFuncs() {
 		callbacks.Clear()
 		return nil, nil
 	})
+	nativeFunc("TreeMap", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		var compareFn *LoxFunction
+		if argsLen > 0 {
+			if callback, ok := args[argsLen-1].(*LoxFunction); ok {
+				compareFn = callback
+				args = args[:argsLen-1]
+			}
+		}
+		if len(args) == 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'TreeMap' must be a function.")
+		}
+		treeMap := NewLoxTreeMap(compareFn)
+		if len(args) == 1 {
+			switch source := args[0].(type) {
+			case *LoxDict, *LoxTreeMap:
+				it := source.(interfaces.Iterable).Iterator()
+				for it.HasNext() {
+					pair := it.Next().(*LoxList).elements
+					if err := treeMap.set(in, pair[0], pair[1]); err != nil {
+						return nil, sortedCompareError(in.callToken, err)
+					}
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'TreeMap' must be a dictionary, tree map, or function.")
+			}
+		}
+		return treeMap, nil
+	})
 	nativeFunc("type", 1, func(_ *Interpreter, args list.List[any]) (any, error) {
 		return NewLoxString(getType(args[0]), '\''), nil
 	})
+
+	//Native functions that can be called with named arguments, along with
+	//the names of their parameters
+	nativeParamNames := map[string][]string{
+		"repeatFunc": {"times", "callback"},
+		"sleep":      {"seconds"},
+		"threadFunc": {"numThreads", "callback"},
+	}
+	for name, paramNames := range nativeParamNames {
+		function, _ := i.globals.GetFromStr(name)
+		function.(*struct{ ProtoLoxCallable }).paramNames = paramNames
+	}
 }
@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"sync"
+)
+
+var nativeModules struct {
+	mutex   sync.Mutex
+	defines []func(*Interpreter)
+}
+
+// RegisterNativeModule registers a function that defines a native module on
+// every interpreter created afterwards.
+func RegisterNativeModule(define func(*Interpreter)) {
+	nativeModules.mutex.Lock()
+	defer nativeModules.mutex.Unlock()
+	nativeModules.defines = append(nativeModules.defines, define)
+}
+
+// NewNativeClass returns a class with the specified name and properties.
+func NewNativeClass(name string, properties map[string]any) (*LoxClass, error) {
+	class := NewLoxClass(name, nil, false)
+	for propertyName, property := range properties {
+		loxValue, err := ToLoxValue(property)
+		if err != nil {
+			return nil, err
+		}
+		class.classProperties[propertyName] = loxValue
+	}
+	return class, nil
+}
+
+func (i *Interpreter) defineNativeModules() {
+	nativeModules.mutex.Lock()
+	defines := append([]func(*Interpreter){}, nativeModules.defines...)
+	nativeModules.mutex.Unlock()
+	for _, define := range defines {
+		define(i)
+	}
+}
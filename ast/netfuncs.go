@@ -0,0 +1,124 @@
+package ast
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Converts the specified number of seconds from now into a deadline for a
+// network operation, where nil means that there is no deadline
+func netDeadline(arg any) (time.Time, bool) {
+	switch seconds := arg.(type) {
+	case nil:
+		return time.Time{}, true
+	case int64:
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	case float64:
+		return time.Now().Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	return time.Time{}, false
+}
+
+// Returns the bytes of the specified string or buffer that is being sent
+// over the network
+func netData(arg any) ([]byte, bool) {
+	switch arg := arg.(type) {
+	case *LoxBuffer:
+		return arg.bytes(), true
+	case *LoxString:
+		return []byte(arg.str), true
+	}
+	return nil, false
+}
+
+func (i *Interpreter) defineNetFuncs() {
+	className := "net"
+	netClass := NewLoxClass(className, nil, false)
+	netFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native net fn %v at %p>", name, &s)
+		}
+		netClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'net.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	netFunc("bindUDP", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		address, ok := args[0].(*LoxString)
+		if !ok {
+			return argMustBeType(in.callToken, "bindUDP", "string")
+		}
+		udpAddr, resolveErr := net.ResolveUDPAddr("udp", address.str)
+		if resolveErr != nil {
+			return nil, loxerror.RuntimeError(in.callToken, resolveErr.Error())
+		}
+		conn, err := net.ListenUDP("udp", udpAddr)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxUDPSocket(conn), nil
+	})
+	netFunc("dial", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		network, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'net.dial' must be a string.")
+		}
+		address, ok := args[1].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'net.dial' must be a string.")
+		}
+		var timeout time.Duration
+		if argsLen == 3 {
+			switch seconds := args[2].(type) {
+			case int64:
+				timeout = time.Duration(seconds) * time.Second
+			case float64:
+				timeout = time.Duration(seconds * float64(time.Second))
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'net.dial' must be an integer or float.")
+			}
+		}
+		conn, err := net.DialTimeout(network.str, address.str, timeout)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxNetConnection(conn), nil
+	})
+	netFunc("listen", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		network, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'net.listen' must be a string.")
+		}
+		address, ok := args[1].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'net.listen' must be a string.")
+		}
+		listener, err := net.Listen(network.str, address.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxNetListener(listener), nil
+	})
+
+	i.globals.Define(className, netClass)
+}
@@ -0,0 +1,129 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/ocr"
+)
+
+func ocrResultToDict(result *ocr.Result) *LoxDict {
+	setStr := func(dict *LoxDict, key string, value any) {
+		dict.setKeyValue(NewLoxString(key, '\''), value)
+	}
+	words := list.NewListCap[any](int64(len(result.Words)))
+	for _, word := range result.Words {
+		wordDict := EmptyLoxDict()
+		setStr(wordDict, "text", NewLoxStringQuote(word.Text))
+		setStr(wordDict, "confidence", word.Confidence)
+		setStr(wordDict, "left", word.Left)
+		setStr(wordDict, "top", word.Top)
+		setStr(wordDict, "width", word.Width)
+		setStr(wordDict, "height", word.Height)
+		words.Add(wordDict)
+	}
+	dict := EmptyLoxDict()
+	setStr(dict, "text", NewLoxStringQuote(result.Text))
+	setStr(dict, "confidence", result.Confidence)
+	setStr(dict, "words", NewLoxList(words))
+	return dict
+}
+
+func (i *Interpreter) defineOCRFuncs() {
+	className := "ocr"
+	ocrClass := NewLoxClass(className, nil, false)
+	ocrFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ocr fn %v at %p>", name, &s)
+		}
+		ocrClass.classProperties[name] = s
+	}
+
+	var backend *LoxFunction
+	ocrFunc("backend", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		if backend == nil {
+			return nil, nil
+		}
+		return backend, nil
+	})
+	ocrFunc("setBackend", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch arg := args[0].(type) {
+		case *LoxFunction:
+			backend = arg
+		case nil:
+			backend = nil
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'ocr.setBackend' must be a function or nil.")
+		}
+		return nil, nil
+	})
+	ocrFunc("text", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		lang := "eng"
+		switch argsLen {
+		case 1:
+		case 2:
+			langStr, ok := args[1].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'ocr.text' must be a string.")
+			}
+			lang = langStr.str
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+
+		var path string
+		switch arg := args[0].(type) {
+		case *LoxString:
+			path = arg.str
+		case *LoxBuffer:
+			bytes := make([]byte, 0, len(arg.elements))
+			for _, element := range arg.elements {
+				bytes = append(bytes, byte(element.(int64)))
+			}
+			tempFile, err := os.CreateTemp("", "lox.ocr.*")
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			defer os.Remove(tempFile.Name())
+			_, err = tempFile.Write(bytes)
+			tempFile.Close()
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			path = tempFile.Name()
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'ocr.text' must be a buffer or string.")
+		}
+
+		if backend != nil {
+			argList := getArgList(backend, 2)
+			argList[0] = NewLoxStringQuote(path)
+			argList[1] = NewLoxStringQuote(lang)
+			result, resultErr := backend.call(in, argList)
+			if resultReturn, ok := result.(Return); ok {
+				return resultReturn.FinalValue, nil
+			} else if resultErr != nil {
+				return nil, resultErr
+			}
+			return result, nil
+		}
+
+		result, err := ocr.Tesseract(path, lang)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return ocrResultToDict(result), nil
+	})
+
+	i.globals.Define(className, ocrClass)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/AlanLuu/lox/bignum/bigint"
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/power"
 	"github.com/AlanLuu/lox/syscalls"
 	"github.com/AlanLuu/lox/syscalls/linuxsyscalls"
 	"github.com/AlanLuu/lox/token"
@@ -27,8 +28,25 @@ import (
 	"github.com/mattn/go-isatty"
 )
 
+func scriptArgs() []string {
+	if util.ScriptArgs != nil {
+		return util.ScriptArgs
+	}
+	return flag.Args()
+}
+
+func exitLox(exitCode int) {
+	CloseInputFuncReadline()
+	RestoreTerminal()
+	power.ReleaseAll()
+	if err := StopProfiler(); err != nil {
+		loxerror.PrintErrorObject(err)
+	}
+	os.Exit(exitCode)
+}
+
 func cmdArgsToLoxList() *LoxList {
-	args := flag.Args()
+	args := scriptArgs()
 	argvList := list.NewListCap[any](int64(len(args)) + 1)
 	execPath, err := os.Executable()
 	if err == nil {
@@ -81,7 +99,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.Chdir(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -100,7 +118,7 @@ func (i *Interpreter) defineOSFuncs() {
 		mode := args[1].(int64)
 		err := os.Chmod(file, os.FileMode(mode))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -126,7 +144,7 @@ func (i *Interpreter) defineOSFuncs() {
 		gid := int(args[2].(int64))
 		err := os.Chown(file, uid, gid)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -134,7 +152,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := syscalls.Chroot(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -148,7 +166,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if fd, ok := args[0].(int64); ok {
 			err := syscalls.Close(int(fd))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -183,7 +201,7 @@ func (i *Interpreter) defineOSFuncs() {
 		sourceStr := args[0].(*LoxString).str
 		sourceStat, sourceStatErr := os.Stat(sourceStr)
 		if sourceStatErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, sourceStatErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, sourceStatErr)
 		}
 		if sourceStat.IsDir() {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -193,39 +211,24 @@ func (i *Interpreter) defineOSFuncs() {
 		destStr := args[1].(*LoxString).str
 		destStat, destStatErr := os.Stat(destStr)
 		if destStatErr == nil && destStat.IsDir() {
-			var pathSep string
-			if strings.Contains(sourceStr, "/") {
-				pathSep = "/"
-			} else if util.IsWindows() && strings.Contains(sourceStr, "\\") {
-				pathSep = "\\"
-			}
-			if len(pathSep) > 0 {
-				splitList := strings.Split(sourceStr, pathSep)
-				index := len(splitList) - 1
-				for index > 0 && splitList[index] == "" {
-					index--
-				}
-				destStr = filepath.Join(destStr, splitList[index])
-			} else {
-				destStr = filepath.Join(destStr, sourceStr)
-			}
+			destStr = filepath.Join(destStr, filepath.Base(sourceStr))
 		}
 
 		source, sourceErr := os.Open(sourceStr)
 		if sourceErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, sourceErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, sourceErr)
 		}
 		defer source.Close()
 
 		dest, destErr := os.OpenFile(destStr, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
 		if destErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, destErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, destErr)
 		}
 		defer dest.Close()
 
 		numBytes, copyErr := io.Copy(dest, source)
 		if copyErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, copyErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, copyErr)
 		}
 		return numBytes, nil
 	})
@@ -233,7 +236,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if fd, ok := args[0].(int64); ok {
 			newFd, err := syscalls.Dup(int(fd))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return int64(newFd), nil
 		}
@@ -252,7 +255,7 @@ func (i *Interpreter) defineOSFuncs() {
 		newfd := args[1].(int64)
 		err := syscalls.Dup2(int(oldfd), int(newfd))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return newfd, nil
 	})
@@ -371,7 +374,7 @@ func (i *Interpreter) defineOSFuncs() {
 		path := args[0].(*LoxString).str
 		err := syscalls.Execve(path, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -481,14 +484,14 @@ func (i *Interpreter) defineOSFuncs() {
 		file := args[0].(*LoxString).str
 		err := syscalls.Execvpe(file, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
 	osFunc("executable", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		exePath, err := os.Executable()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(exePath), nil
 	})
@@ -520,7 +523,7 @@ func (i *Interpreter) defineOSFuncs() {
 		path := args[0].(*LoxString).str
 		err := syscalls.Execv(path, argv)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -582,7 +585,7 @@ func (i *Interpreter) defineOSFuncs() {
 		path := args[0].(*LoxString).str
 		err := syscalls.Execve(path, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -614,7 +617,7 @@ func (i *Interpreter) defineOSFuncs() {
 		file := args[0].(*LoxString).str
 		err := syscalls.Execvp(file, argv)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -676,7 +679,7 @@ func (i *Interpreter) defineOSFuncs() {
 		file := args[0].(*LoxString).str
 		err := syscalls.Execvpe(file, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -694,7 +697,7 @@ func (i *Interpreter) defineOSFuncs() {
 				if !homeIsSet || util.IsWindows() {
 					currentUser, err := user.Current()
 					if err != nil {
-						return nil, loxerror.RuntimeError(in.callToken, err.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 					}
 					homeDir = currentUser.HomeDir
 				}
@@ -724,8 +727,7 @@ func (i *Interpreter) defineOSFuncs() {
 			return nil, loxerror.RuntimeError(in.callToken,
 				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
 		}
-		CloseInputFuncReadline()
-		os.Exit(exitCode)
+		exitLox(exitCode)
 		return nil, nil
 	})
 	osFunc("fallocate", 2, func(in *Interpreter, args list.List[any]) (any, error) {
@@ -810,12 +812,12 @@ func (i *Interpreter) defineOSFuncs() {
 					//Check if the file can actually be written to
 					_, testWriteErr := file.WriteString("")
 					if testWriteErr != nil {
-						return nil, loxerror.RuntimeError(in.callToken, testWriteErr.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, testWriteErr)
 					}
 
 					stat, statErr := file.Stat()
 					if statErr != nil {
-						return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 					}
 					statSize := stat.Size()
 					if size > statSize {
@@ -831,12 +833,12 @@ func (i *Interpreter) defineOSFuncs() {
 				//Check if the file can actually be written to
 				_, testWriteErr := file.WriteString("")
 				if testWriteErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, testWriteErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, testWriteErr)
 				}
 
 				stat, statErr := file.Stat()
 				if statErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 				}
 				statSize := stat.Size()
 				if size > statSize {
@@ -857,28 +859,28 @@ func (i *Interpreter) defineOSFuncs() {
 					case arg.mode == filemode.READ_WRITE:
 						stat, statErr := arg.file.Stat()
 						if statErr != nil {
-							return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+							return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 						}
 						statSize := stat.Size()
 						if size > statSize {
 							originalOffset, seekErr1 := arg.file.Seek(0, io.SeekStart)
 							if seekErr1 != nil {
-								return nil, loxerror.RuntimeError(in.callToken, seekErr1.Error())
+								return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr1)
 							}
 							_, seekErr2 := arg.file.Seek(0, io.SeekEnd)
 							if seekErr2 != nil {
-								return nil, loxerror.RuntimeError(in.callToken, seekErr2.Error())
+								return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr2)
 							}
 							_, err = arg.file.Write(make([]byte, size-statSize))
 							_, seekErr3 := arg.file.Seek(originalOffset, io.SeekStart)
 							if seekErr3 != nil {
-								return nil, loxerror.RuntimeError(in.callToken, seekErr3.Error())
+								return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr3)
 							}
 						}
 					default:
 						stat, statErr := arg.file.Stat()
 						if statErr != nil {
-							return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+							return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 						}
 						statSize := stat.Size()
 						if size > statSize {
@@ -889,28 +891,28 @@ func (i *Interpreter) defineOSFuncs() {
 			case arg.mode == filemode.READ_WRITE:
 				stat, statErr := arg.file.Stat()
 				if statErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 				}
 				statSize := stat.Size()
 				if size > statSize {
 					originalOffset, seekErr1 := arg.file.Seek(0, io.SeekStart)
 					if seekErr1 != nil {
-						return nil, loxerror.RuntimeError(in.callToken, seekErr1.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr1)
 					}
 					_, seekErr2 := arg.file.Seek(0, io.SeekEnd)
 					if seekErr2 != nil {
-						return nil, loxerror.RuntimeError(in.callToken, seekErr2.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr2)
 					}
 					_, err = arg.file.Write(make([]byte, size-statSize))
 					_, seekErr3 := arg.file.Seek(originalOffset, io.SeekStart)
 					if seekErr3 != nil {
-						return nil, loxerror.RuntimeError(in.callToken, seekErr3.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, seekErr3)
 					}
 				}
 			default:
 				stat, statErr := arg.file.Stat()
 				if statErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 				}
 				statSize := stat.Size()
 				if size > statSize {
@@ -924,7 +926,7 @@ func (i *Interpreter) defineOSFuncs() {
 				0666,
 			)
 			if fileErr != nil {
-				return nil, loxerror.RuntimeError(in.callToken, fileErr.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, fileErr)
 			}
 			defer file.Close()
 			if util.IsLinux() {
@@ -932,7 +934,7 @@ func (i *Interpreter) defineOSFuncs() {
 				if err != nil {
 					stat, statErr := file.Stat()
 					if statErr != nil {
-						return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+						return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 					}
 					statSize := stat.Size()
 					if size > statSize {
@@ -942,7 +944,7 @@ func (i *Interpreter) defineOSFuncs() {
 			} else {
 				stat, statErr := file.Stat()
 				if statErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, statErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, statErr)
 				}
 				statSize := stat.Size()
 				if size > statSize {
@@ -952,7 +954,7 @@ func (i *Interpreter) defineOSFuncs() {
 		}
 
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -960,7 +962,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if fd, ok := args[0].(int64); ok {
 			err := syscalls.Fchdir(int(fd))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -979,7 +981,7 @@ func (i *Interpreter) defineOSFuncs() {
 		mode := uint32(args[1].(int64))
 		err := syscalls.Fchmod(fd, mode)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1001,7 +1003,7 @@ func (i *Interpreter) defineOSFuncs() {
 		gid := int(args[2].(int64))
 		err := syscalls.Fchown(fd, uid, gid)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1034,7 +1036,7 @@ func (i *Interpreter) defineOSFuncs() {
 		argv0 := args[0].(*LoxString).str
 		pid, err := syscalls.ForkExecFd(argv0, argv)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(pid), nil
 	})
@@ -1096,7 +1098,7 @@ func (i *Interpreter) defineOSFuncs() {
 		argv0 := args[0].(*LoxString).str
 		pid, err := syscalls.ForkExecveFd(argv0, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(pid), nil
 	})
@@ -1129,7 +1131,7 @@ func (i *Interpreter) defineOSFuncs() {
 		argv0 := args[0].(*LoxString).str
 		pid, err := syscalls.ForkExecvpFd(argv0, argv)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(pid), nil
 	})
@@ -1191,7 +1193,7 @@ func (i *Interpreter) defineOSFuncs() {
 		argv0 := args[0].(*LoxString).str
 		pid, err := syscalls.ForkExecvpeFd(argv0, argv, envp)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(pid), nil
 	})
@@ -1199,7 +1201,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if fd, ok := args[0].(int64); ok {
 			err := syscalls.Fsync(int(fd))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1218,7 +1220,7 @@ func (i *Interpreter) defineOSFuncs() {
 		size := args[1].(int64)
 		err := syscalls.Ftruncate(fd, size)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1244,7 +1246,7 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("getcwd", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(cwd), nil
 	})
@@ -1294,7 +1296,7 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("getgroups", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		groups, err := syscalls.Getgroups()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		groupsList := list.NewListCap[any](int64(len(groups)))
 		for _, group := range groups {
@@ -1342,13 +1344,13 @@ func (i *Interpreter) defineOSFuncs() {
 		}
 		if !util.IsLinux() {
 			_, err := linuxsyscalls.Getrandom(nil, 0)
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		inputBufferLen := len(inputBuffer.elements)
 		bytes := make([]byte, inputBufferLen)
 		n, err := linuxsyscalls.Getrandom(bytes, flags)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		for i := 0; i < inputBufferLen; i++ {
 			inputBuffer.elements[i] = int64(bytes[i])
@@ -1359,7 +1361,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if pid, ok := args[0].(int64); ok {
 			sid, err := syscalls.Getsid(int(pid))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return int64(sid), nil
 		}
@@ -1368,6 +1370,35 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("getuid", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 		return int64(os.Getuid()), nil
 	})
+	osFunc("getxattr", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'os.getxattr' must be a string.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'os.getxattr' must be a string.")
+		}
+		value, err := syscalls.Getxattr(args[0].(*LoxString).str, args[1].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return NewLoxBufferFromBytes(value), nil
+	})
+	osFunc("glob", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return argMustBeType(in.callToken, "glob", "string")
+		}
+		matches, err := osGlob(args[0].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		matchesList := list.NewListCap[any](int64(len(matches)))
+		for _, match := range matches {
+			matchesList.Add(NewLoxStringQuote(match))
+		}
+		return NewLoxList(matchesList), nil
+	})
 	if util.IsLinux() {
 		osClass.classProperties["GRND_INSECURE"] = int64(linuxsyscalls.GRND_INSECURE)
 		osClass.classProperties["GRND_NONBLOCK"] = int64(linuxsyscalls.GRND_NONBLOCK)
@@ -1376,10 +1407,25 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("hostname", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		hostname, err := os.Hostname()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(hostname), nil
 	})
+	osFunc("idleTime", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		idleTime, err := power.IdleTime()
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return NewLoxDuration(idleTime), nil
+	})
+	osFunc("ignoreSignal", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		sig, name, err := signalArg(in.callToken, "ignoreSignal", args[0])
+		if err != nil {
+			return nil, err
+		}
+		setSignalHandler(in, sig, name, nil)
+		return nil, nil
+	})
 	osFunc("isatty", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if num, ok := args[0].(int64); ok {
 			fd := uintptr(num)
@@ -1405,7 +1451,7 @@ func (i *Interpreter) defineOSFuncs() {
 						return nil, loxerror.RuntimeError(in.callToken,
 							fmt.Sprintf(noSuchProcessMsg, pid))
 					}
-					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 				}
 
 				/*
@@ -1430,7 +1476,7 @@ func (i *Interpreter) defineOSFuncs() {
 				}
 
 				if err != nil {
-					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 				}
 				return nil, nil
 			} else if argsLen == 2 {
@@ -1466,7 +1512,7 @@ func (i *Interpreter) defineOSFuncs() {
 		gid := int(args[2].(int64))
 		err := os.Lchown(link, uid, gid)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1483,7 +1529,7 @@ func (i *Interpreter) defineOSFuncs() {
 		linkName := args[1].(*LoxString).str
 		err := os.Link(target, linkName)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1520,15 +1566,39 @@ func (i *Interpreter) defineOSFuncs() {
 		}
 		err := fs.WalkDir(dir, ".", dirFunc)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxList(dirList), nil
 	})
+	osFunc("listxattr", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return argMustBeType(in.callToken, "listxattr", "string")
+		}
+		names, err := syscalls.Listxattr(args[0].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		namesList := list.NewListCap[any](int64(len(names)))
+		for _, name := range names {
+			namesList.Add(NewLoxStringQuote(name))
+		}
+		return NewLoxList(namesList), nil
+	})
+	osFunc("lstat", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return argMustBeType(in.callToken, "lstat", "string")
+		}
+		info, err := os.Lstat(args[0].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return NewLoxStatResult(info), nil
+	})
 	osFunc("mkdir", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.Mkdir(loxStr.str, 0777)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1538,7 +1608,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.MkdirAll(loxStr.str, 0777)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1548,7 +1618,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := syscalls.Mkfifo(loxStr.str, 0666)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1571,7 +1641,7 @@ func (i *Interpreter) defineOSFuncs() {
 		}
 		tempFile, err := os.CreateTemp(dir, "lox.tmp.")
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return &LoxFile{
 			file:       tempFile,
@@ -1599,7 +1669,7 @@ func (i *Interpreter) defineOSFuncs() {
 		}
 		tempFile, err := os.CreateTemp(dir, "lox.tmp.")
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return &LoxFile{
 			file:       tempFile,
@@ -1614,6 +1684,19 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("numCPU", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
 		return int64(runtime.NumCPU()), nil
 	})
+	osFunc("onSignal", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		callback, ok := args[1].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'os.onSignal' must be a function.")
+		}
+		sig, name, err := signalArg(in.callToken, "onSignal", args[0])
+		if err != nil {
+			return nil, err
+		}
+		setSignalHandler(in, sig, name, callback)
+		return nil, nil
+	})
 	osFunc("open", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(*LoxString); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -1627,7 +1710,7 @@ func (i *Interpreter) defineOSFuncs() {
 		mode := args[1].(*LoxString).str
 		loxFile, loxFileErr := NewLoxFileModeStr(path, mode)
 		if loxFileErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, loxFileErr.Error())
+			return nil, loxerror.RuntimeErrorKind(loxerror.IOError, in.callToken, loxFileErr.Error())
 		}
 		return loxFile, nil
 	})
@@ -1635,7 +1718,7 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("pipe", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		r, w, err := os.Pipe()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		files := list.NewListCap[any](2)
 		files.Add(&LoxFile{
@@ -1659,7 +1742,7 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("pipeBin", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		r, w, err := os.Pipe()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		files := list.NewListCap[any](2)
 		files.Add(&LoxFile{
@@ -1680,6 +1763,16 @@ func (i *Interpreter) defineOSFuncs() {
 		})
 		return NewLoxList(files), nil
 	})
+	osFunc("preventSleep", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			inhibitor, err := power.PreventSleep(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+			}
+			return NewLoxSleepInhibitor(inhibitor, loxStr.str), nil
+		}
+		return argMustBeType(in.callToken, "preventSleep", "string")
+	})
 	osFunc("read", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(int64); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -1695,14 +1788,14 @@ func (i *Interpreter) defineOSFuncs() {
 		bytes := make([]byte, numBytes)
 		_, err := syscalls.Read(int(fd), bytes)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 
 		buffer := EmptyLoxBufferCap(int64(len(bytes)))
 		for _, element := range bytes {
 			bufErr := buffer.add(int64(element))
 			if bufErr != nil {
-				return nil, loxerror.RuntimeError(in.callToken, bufErr.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, bufErr)
 			}
 		}
 		return buffer, nil
@@ -1711,7 +1804,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			bytes, err := os.ReadFile(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return NewLoxStringQuote(string(bytes)), nil
 		}
@@ -1721,13 +1814,13 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			bytes, err := os.ReadFile(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			loxBuffer := EmptyLoxBufferCap(int64(len(bytes)))
 			for _, element := range bytes {
 				addErr := loxBuffer.add(int64(element))
 				if addErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, addErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, addErr)
 				}
 			}
 			return loxBuffer, nil
@@ -1738,17 +1831,28 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			dest, err := os.Readlink(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return NewLoxStringQuote(dest), nil
 		}
 		return argMustBeType(in.callToken, "readLink", "string")
 	})
+	osFunc("reboot", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if !util.UnsafeMode {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot call 'os.reboot' in non-unsafe mode.")
+		}
+		err := power.Reboot()
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return nil, nil
+	})
 	osFunc("remove", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.Remove(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1758,12 +1862,27 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.RemoveAll(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
 		return argMustBeType(in.callToken, "removeAll", "string")
 	})
+	osFunc("removexattr", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'os.removexattr' must be a string.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'os.removexattr' must be a string.")
+		}
+		err := syscalls.Removexattr(args[0].(*LoxString).str, args[1].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return nil, nil
+	})
 	osFunc("rename", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(*LoxString); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -1777,18 +1896,57 @@ func (i *Interpreter) defineOSFuncs() {
 		newPath := args[1].(*LoxString).str
 		err := os.Rename(oldPath, newPath)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
 	osClass.classProperties["SEEK_SET"] = int64(0)
 	osClass.classProperties["SEEK_CUR"] = int64(1)
 	osClass.classProperties["SEEK_END"] = int64(2)
+	osFunc("resetSignal", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		sig, _, err := signalArg(in.callToken, "resetSignal", args[0])
+		if err != nil {
+			return nil, err
+		}
+		resetSignal(sig)
+		return nil, nil
+	})
+	osFunc("scandir", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var path string
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+			path = "."
+		case 1:
+			if _, ok := args[0].(*LoxString); !ok {
+				return argMustBeType(in.callToken, "scandir", "string")
+			}
+			path = args[0].(*LoxString).str
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		dir, err := os.Open(path)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		info, err := dir.Stat()
+		if err != nil {
+			dir.Close()
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		if !info.IsDir() {
+			dir.Close()
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("os.scandir: '%v' is not a directory.", path))
+		}
+		return NewLoxIterator(dirEntryIterator(dir, path)), nil
+	})
 	osFunc("setegid", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		if egid, ok := args[0].(int64); ok {
 			err := syscalls.Setegid(int(egid))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1807,7 +1965,7 @@ func (i *Interpreter) defineOSFuncs() {
 		value := args[1].(*LoxString).str
 		err := os.Setenv(key, value)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1815,7 +1973,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if euid, ok := args[0].(int64); ok {
 			err := syscalls.Seteuid(int(euid))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1825,7 +1983,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if gid, ok := args[0].(int64); ok {
 			err := syscalls.Setgid(int(gid))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1846,7 +2004,7 @@ func (i *Interpreter) defineOSFuncs() {
 			}
 			err := syscalls.Setgroups(gids)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -1865,7 +2023,7 @@ func (i *Interpreter) defineOSFuncs() {
 		egid := args[1].(int64)
 		err := syscalls.Setregid(int(rgid), int(egid))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1887,7 +2045,7 @@ func (i *Interpreter) defineOSFuncs() {
 		sgid := args[2].(int64)
 		err := linuxsyscalls.Setresgid(int(rgid), int(egid), int(sgid))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1909,7 +2067,7 @@ func (i *Interpreter) defineOSFuncs() {
 		suid := args[2].(int64)
 		err := linuxsyscalls.Setresuid(int(ruid), int(euid), int(suid))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1926,14 +2084,14 @@ func (i *Interpreter) defineOSFuncs() {
 		euid := args[1].(int64)
 		err := syscalls.Setreuid(int(ruid), int(euid))
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
 	osFunc("setsid", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		pid, err := syscalls.Setsid()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(pid), nil
 	})
@@ -1941,18 +2099,75 @@ func (i *Interpreter) defineOSFuncs() {
 		if uid, ok := args[0].(int64); ok {
 			err := syscalls.Setuid(int(uid))
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
 		return argMustBeTypeAn(in.callToken, "setuid", "integer")
 	})
+	osFunc("setxattr", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'os.setxattr' must be a string.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'os.setxattr' must be a string.")
+		}
+		var value []byte
+		switch arg := args[2].(type) {
+		case *LoxBuffer:
+			value = arg.bytes()
+		case *LoxString:
+			value = []byte(arg.str)
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Third argument to 'os.setxattr' must be a buffer or string.")
+		}
+		err := syscalls.Setxattr(args[0].(*LoxString).str, args[1].(*LoxString).str, value)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return nil, nil
+	})
+	osFunc("shutdown", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if !util.UnsafeMode {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot call 'os.shutdown' in non-unsafe mode.")
+		}
+		err := power.Shutdown()
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return nil, nil
+	})
 	osClass.classProperties["stderr"] = stdStream(os.Stderr, filemode.WRITE, false)
 	osClass.classProperties["stdin"] = stdStream(os.Stdin, filemode.READ, false)
 	osClass.classProperties["stdout"] = stdStream(os.Stdout, filemode.WRITE, false)
 	osClass.classProperties["stderrBin"] = stdStream(os.Stderr, filemode.WRITE, true)
 	osClass.classProperties["stdinBin"] = stdStream(os.Stdin, filemode.READ, true)
 	osClass.classProperties["stdoutBin"] = stdStream(os.Stdout, filemode.WRITE, true)
+	osFunc("stat", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return argMustBeType(in.callToken, "stat", "string")
+		}
+		info, err := os.Stat(args[0].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return NewLoxStatResult(info), nil
+	})
+	osFunc("suspend", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if !util.UnsafeMode {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot call 'os.suspend' in non-unsafe mode.")
+		}
+		err := power.Suspend()
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		return nil, nil
+	})
 	osFunc("symlink", 2, func(in *Interpreter, args list.List[any]) (any, error) {
 		if _, ok := args[0].(*LoxString); !ok {
 			return nil, loxerror.RuntimeError(in.callToken,
@@ -1966,7 +2181,7 @@ func (i *Interpreter) defineOSFuncs() {
 		linkName := args[1].(*LoxString).str
 		err := os.Symlink(target, linkName)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -1993,7 +2208,7 @@ func (i *Interpreter) defineOSFuncs() {
 				if exitErr, ok := err.(*exec.ExitError); ok {
 					return int64(exitErr.ExitCode()), nil
 				} else {
-					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 				}
 			}
 			return int64(0), nil
@@ -2010,17 +2225,17 @@ func (i *Interpreter) defineOSFuncs() {
 		path := args[1].(*LoxString).str
 		file, openErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
 		if openErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, openErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, openErr)
 		}
 		defer file.Close()
 
 		_, writeErr := file.WriteString(elementStr)
 		if writeErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, writeErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, writeErr)
 		}
 		_, writeErr = os.Stdout.WriteString(elementStr)
 		if writeErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, writeErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, writeErr)
 		}
 		if util.StdinFromTerminal() && []rune(elementStr)[len(elementStr)-1] != '\n' {
 			fmt.Println()
@@ -2037,17 +2252,17 @@ func (i *Interpreter) defineOSFuncs() {
 		path := args[1].(*LoxString).str
 		file, openErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 		if openErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, openErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, openErr)
 		}
 		defer file.Close()
 
 		_, writeErr := file.WriteString(elementStr)
 		if writeErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, writeErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, writeErr)
 		}
 		_, writeErr = os.Stdout.WriteString(elementStr)
 		if writeErr != nil {
-			return nil, loxerror.RuntimeError(in.callToken, writeErr.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, writeErr)
 		}
 		if util.StdinFromTerminal() && []rune(elementStr)[len(elementStr)-1] != '\n' {
 			fmt.Println()
@@ -2061,7 +2276,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			file, fileErr := os.Create(loxStr.str)
 			if fileErr != nil {
-				return nil, loxerror.RuntimeError(in.callToken, fileErr.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, fileErr)
 			}
 			file.Close()
 			return nil, nil
@@ -2081,7 +2296,7 @@ func (i *Interpreter) defineOSFuncs() {
 		size := args[1].(int64)
 		err := os.Truncate(path, size)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -2098,7 +2313,7 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("uname", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		result, err := syscalls.Uname()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		dict := EmptyLoxDict()
 		setDict := func(key string, value string) {
@@ -2115,7 +2330,7 @@ func (i *Interpreter) defineOSFuncs() {
 		if loxStr, ok := args[0].(*LoxString); ok {
 			err := os.Unsetenv(loxStr.str)
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 			return nil, nil
 		}
@@ -2131,11 +2346,11 @@ func (i *Interpreter) defineOSFuncs() {
 			for i := int64(0); i < numBytes; i++ {
 				numBig, numErr := crand.Int(crand.Reader, bigint.TwoFiveSix)
 				if numErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, numErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, numErr)
 				}
 				addErr := buffer.add(numBig.Int64())
 				if addErr != nil {
-					return nil, loxerror.RuntimeError(in.callToken, addErr.Error())
+					return nil, loxerror.RuntimeErrorIO(in.callToken, addErr)
 				}
 			}
 			return buffer, nil
@@ -2146,28 +2361,28 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("userCacheDir", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		cacheDir, err := os.UserCacheDir()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(cacheDir), nil
 	})
 	osFunc("userConfigDir", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		configDir, err := os.UserConfigDir()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(configDir), nil
 	})
 	osFunc("userHomeDir", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return NewLoxStringQuote(homeDir), nil
 	})
 	osFunc("username", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		currentUser, err := user.Current()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		username := currentUser.Username
 		if util.IsWindows() && strings.Contains(username, "\\") {
@@ -2179,13 +2394,38 @@ func (i *Interpreter) defineOSFuncs() {
 	osFunc("wait", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		pid, waitStatus, err := syscalls.Wait()
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		l := list.NewListCap[any](2)
 		l.Add(int64(pid))
 		l.Add(NewLoxWaitStatus(waitStatus))
 		return NewLoxList(l), nil
 	})
+	osFunc("walk", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var root string
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+			root = "."
+		case 1:
+			if _, ok := args[0].(*LoxString); !ok {
+				return argMustBeType(in.callToken, "walk", "string")
+			}
+			root = args[0].(*LoxString).str
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
+		}
+		if !info.IsDir() {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("os.walk: '%v' is not a directory.", root))
+		}
+		return NewLoxIterator(osWalkIterator(root)), nil
+	})
 	osFunc("whoami", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
 		var cmd *exec.Cmd
 		if util.IsWindows() {
@@ -2203,7 +2443,7 @@ func (i *Interpreter) defineOSFuncs() {
 			cmd = exec.Command(whoamiPath)
 			whoamiBytes, err = cmd.Output()
 			if err != nil {
-				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 			}
 		}
 		whoami := strings.TrimRightFunc(string(whoamiBytes), unicode.IsSpace)
@@ -2228,7 +2468,7 @@ func (i *Interpreter) defineOSFuncs() {
 
 		numBytesWritten, err := syscalls.Write(int(fd), bytes)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return int64(numBytesWritten), nil
 	})
@@ -2245,7 +2485,7 @@ func (i *Interpreter) defineOSFuncs() {
 		data := args[1].(*LoxString).str
 		err := os.WriteFile(name, []byte(data), 0666)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -2268,7 +2508,7 @@ func (i *Interpreter) defineOSFuncs() {
 
 		err := os.WriteFile(name, data, 0666)
 		if err != nil {
-			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			return nil, loxerror.RuntimeErrorIO(in.callToken, err)
 		}
 		return nil, nil
 	})
@@ -0,0 +1,198 @@
+package ast
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/util"
+)
+
+func globHasMeta(pattern string) bool {
+	magicChars := "*?["
+	if !util.IsWindows() {
+		magicChars = "*?[\\"
+	}
+	return strings.ContainsAny(pattern, magicChars)
+}
+
+func globJoin(dir string, name string) string {
+	if dir == "" {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+func globReadDir(dir string) []fs.DirEntry {
+	if dir == "" {
+		dir = "."
+	}
+	entries, _ := os.ReadDir(dir)
+	return entries
+}
+
+func globIsDir(path string) bool {
+	if path == "" {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Adds the paths under dir that match the pattern elements in parts to
+// results, where an element of "**" matches zero or more directories
+func globMatch(dir string, parts []string, results map[string]struct{}) {
+	if len(parts) == 0 {
+		if dir != "" {
+			results[dir] = struct{}{}
+		}
+		return
+	}
+	part, rest := parts[0], parts[1:]
+	switch {
+	case part == "**":
+		root := dir
+		if root == "" {
+			root = "."
+		}
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if dir == "" && path == "." {
+				path = ""
+			}
+			if len(rest) == 0 {
+				if path != dir {
+					results[path] = struct{}{}
+				}
+			} else if d.IsDir() {
+				globMatch(path, rest, results)
+			}
+			return nil
+		})
+	case globHasMeta(part):
+		for _, entry := range globReadDir(dir) {
+			if matched, _ := filepath.Match(part, entry.Name()); !matched {
+				continue
+			}
+			path := globJoin(dir, entry.Name())
+			if len(rest) == 0 {
+				results[path] = struct{}{}
+			} else if globIsDir(path) {
+				globMatch(path, rest, results)
+			}
+		}
+	default:
+		path := globJoin(dir, part)
+		if len(rest) == 0 {
+			if _, err := os.Lstat(path); err == nil {
+				results[path] = struct{}{}
+			}
+		} else if globIsDir(path) {
+			globMatch(path, rest, results)
+		}
+	}
+}
+
+// Returns the sorted names of all files and directories matching the
+// specified pattern, which uses the syntax of filepath.Match along with
+// "**" elements that match zero or more directories
+func osGlob(pattern string) ([]string, error) {
+	//Absolute patterns are matched starting from the root directory
+	dir := filepath.VolumeName(pattern)
+	rest := pattern[len(dir):]
+	if rest != "" && os.IsPathSeparator(rest[0]) {
+		dir += string(filepath.Separator)
+	}
+	parts := strings.FieldsFunc(rest, func(r rune) bool {
+		return os.IsPathSeparator(uint8(r))
+	})
+	for _, part := range parts {
+		if _, err := filepath.Match(part, ""); err != nil {
+			return nil, err
+		}
+	}
+	if !slices.Contains(parts, "**") {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(matches)
+		return matches, nil
+	}
+
+	results := make(map[string]struct{})
+	globMatch(dir, parts, results)
+	matches := make([]string, 0, len(results))
+	for match := range results {
+		matches = append(matches, match)
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// Returns an iterator that lazily walks the directory tree rooted at the
+// specified directory from the top down, yielding a list of the form
+// [dirPath, dirNames, fileNames] for each directory. Subdirectories that are
+// removed from dirNames before the next directory is requested are not walked
+func osWalkIterator(root string) interfaces.Iterator {
+	stack := []string{root}
+	var previous *LoxList
+	var previousDir string
+	var next *LoxList
+	advance := func() {
+		if previous != nil {
+			dirNames := previous.elements
+			for i := len(dirNames) - 1; i >= 0; i-- {
+				if name, ok := dirNames[i].(*LoxString); ok {
+					stack = append(stack, filepath.Join(previousDir, name.str))
+				}
+			}
+			previous = nil
+		}
+		for next == nil && len(stack) > 0 {
+			dir := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			dirNames := list.NewList[any]()
+			fileNames := list.NewList[any]()
+			for _, entry := range entries {
+				if entry.IsDir() {
+					dirNames.Add(NewLoxStringQuote(entry.Name()))
+				} else {
+					fileNames.Add(NewLoxStringQuote(entry.Name()))
+				}
+			}
+			next = NewLoxList(list.List[any]{
+				NewLoxStringQuote(dir),
+				NewLoxList(dirNames),
+				NewLoxList(fileNames),
+			})
+			previousDir = dir
+		}
+	}
+	return ProtoIterator{
+		hasNextMethod: func() bool {
+			advance()
+			return next != nil
+		},
+		nextMethod: func() any {
+			advance()
+			triple := next
+			previous = triple.elements[1].(*LoxList)
+			next = nil
+			return triple
+		},
+	}
+}
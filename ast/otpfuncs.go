@@ -0,0 +1,340 @@
+package ast
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+type otpOptions struct {
+	algorithm string
+	digits    int64
+	period    int64
+	time      int64
+	window    int64
+}
+
+func otpDecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+func otpHashFunc(algorithm string) (func() hash.Hash, bool) {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New, true
+	case "SHA256":
+		return sha256.New, true
+	case "SHA512":
+		return sha512.New, true
+	}
+	return nil, false
+}
+
+// otpGenerate implements the HOTP algorithm from RFC 4226.
+func otpGenerate(key []byte, counter uint64, options otpOptions) string {
+	hashFunc, _ := otpHashFunc(options.algorithm)
+	mac := hmac.New(hashFunc, key)
+	counterBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(counterBytes, counter)
+	mac.Write(counterBytes)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(math.Pow10(int(options.digits)))
+	return fmt.Sprintf("%0*d", options.digits, code%mod)
+}
+
+func otpCodesEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func (i *Interpreter) defineOTPFuncs() {
+	className := "otp"
+	otpClass := NewLoxClass(className, nil, false)
+	otpFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native otp fn %v at %p>", name, &s)
+		}
+		otpClass.classProperties[name] = s
+	}
+	ordinals := []string{"First", "Second", "Third", "Fourth", "Fifth"}
+	checkArgsLen := func(in *Interpreter, args list.List[any], min int, max int) error {
+		argsLen := len(args)
+		if argsLen < min || argsLen > max {
+			var expected string
+			if max-min == 1 {
+				expected = fmt.Sprintf("%v or %v", min, max)
+			} else {
+				expected = fmt.Sprintf("%v", min)
+			}
+			return loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected %v arguments but got %v.", expected, argsLen))
+		}
+		return nil
+	}
+	stringArg := func(in *Interpreter, args list.List[any], index int, name string) (string, error) {
+		if loxStr, ok := args[index].(*LoxString); ok {
+			return loxStr.str, nil
+		}
+		return "", loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("%v argument to 'otp.%v' must be a string.", ordinals[index], name))
+	}
+	secretArg := func(in *Interpreter, args list.List[any], index int, name string) ([]byte, error) {
+		secret, err := stringArg(in, args, index, name)
+		if err != nil {
+			return nil, err
+		}
+		key, err := otpDecodeSecret(secret)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Invalid base32 secret passed to 'otp.%v'.", name))
+		}
+		return key, nil
+	}
+	optionsArg := func(in *Interpreter, args list.List[any], index int, name string) (otpOptions, error) {
+		options := otpOptions{
+			algorithm: "SHA1",
+			digits:    6,
+			period:    30,
+			time:      time.Now().Unix(),
+			window:    1,
+		}
+		if index >= len(args) {
+			return options, nil
+		}
+		dict, ok := args[index].(*LoxDict)
+		if !ok {
+			return options, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("%v argument to 'otp.%v' must be a dictionary.", ordinals[index], name))
+		}
+		intOption := func(key string, dest *int64, min int64) error {
+			value, ok := dict.getValueByKey(NewLoxString(key, '\''))
+			if !ok {
+				return nil
+			}
+			num, ok := value.(int64)
+			if !ok {
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option '%v' in 'otp.%v' must be an integer.", key, name))
+			}
+			if num < min {
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option '%v' in 'otp.%v' cannot be less than %v.", key, name, min))
+			}
+			*dest = num
+			return nil
+		}
+		if err := intOption("digits", &options.digits, 1); err != nil {
+			return options, err
+		}
+		if options.digits > 10 {
+			return options, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Option 'digits' in 'otp.%v' cannot be greater than 10.", name))
+		}
+		if err := intOption("period", &options.period, 1); err != nil {
+			return options, err
+		}
+		if err := intOption("time", &options.time, 0); err != nil {
+			return options, err
+		}
+		if err := intOption("window", &options.window, 0); err != nil {
+			return options, err
+		}
+		if value, ok := dict.getValueByKey(NewLoxString("algorithm", '\'')); ok {
+			loxStr, ok := value.(*LoxString)
+			if !ok {
+				return options, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option 'algorithm' in 'otp.%v' must be a string.", name))
+			}
+			algorithm := strings.ToUpper(strings.ReplaceAll(loxStr.str, "-", ""))
+			if _, ok := otpHashFunc(algorithm); !ok {
+				return options, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Unknown algorithm '%v' in 'otp.%v'.", loxStr.str, name))
+			}
+			options.algorithm = algorithm
+		}
+		return options, nil
+	}
+	counterArg := func(in *Interpreter, args list.List[any], index int, name string) (uint64, error) {
+		if counter, ok := args[index].(int64); ok && counter >= 0 {
+			return uint64(counter), nil
+		}
+		return 0, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("%v argument to 'otp.%v' must be a non-negative integer.", ordinals[index], name))
+	}
+
+	otpFunc("generateSecret", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 0, 1); err != nil {
+			return nil, err
+		}
+		numBytes := int64(20)
+		if len(args) == 1 {
+			num, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'otp.generateSecret' must be an integer.")
+			}
+			if num < 1 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'otp.generateSecret' must be at least 1.")
+			}
+			numBytes = num
+		}
+		bytes := make([]byte, numBytes)
+		if _, err := crand.Read(bytes); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes)
+		return NewLoxStringQuote(secret), nil
+	})
+	otpFunc("hotp", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 2, 3); err != nil {
+			return nil, err
+		}
+		key, err := secretArg(in, args, 0, "hotp")
+		if err != nil {
+			return nil, err
+		}
+		counter, err := counterArg(in, args, 1, "hotp")
+		if err != nil {
+			return nil, err
+		}
+		options, err := optionsArg(in, args, 2, "hotp")
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(otpGenerate(key, counter, options)), nil
+	})
+	otpFunc("provisioningURI", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 3, 4); err != nil {
+			return nil, err
+		}
+		secret, err := stringArg(in, args, 0, "provisioningURI")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := otpDecodeSecret(secret); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Invalid base32 secret passed to 'otp.provisioningURI'.")
+		}
+		accountName, err := stringArg(in, args, 1, "provisioningURI")
+		if err != nil {
+			return nil, err
+		}
+		issuer, err := stringArg(in, args, 2, "provisioningURI")
+		if err != nil {
+			return nil, err
+		}
+		options, err := optionsArg(in, args, 3, "provisioningURI")
+		if err != nil {
+			return nil, err
+		}
+		label := accountName
+		if len(issuer) > 0 {
+			label = issuer + ":" + accountName
+		}
+		query := url.Values{}
+		query.Set("secret", strings.ToUpper(strings.ReplaceAll(secret, " ", "")))
+		if len(issuer) > 0 {
+			query.Set("issuer", issuer)
+		}
+		query.Set("algorithm", options.algorithm)
+		query.Set("digits", fmt.Sprint(options.digits))
+		query.Set("period", fmt.Sprint(options.period))
+		uri := url.URL{
+			Scheme:   "otpauth",
+			Host:     "totp",
+			Path:     "/" + label,
+			RawQuery: query.Encode(),
+		}
+		return NewLoxStringQuote(uri.String()), nil
+	})
+	otpFunc("totp", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 1, 2); err != nil {
+			return nil, err
+		}
+		key, err := secretArg(in, args, 0, "totp")
+		if err != nil {
+			return nil, err
+		}
+		options, err := optionsArg(in, args, 1, "totp")
+		if err != nil {
+			return nil, err
+		}
+		counter := uint64(options.time / options.period)
+		return NewLoxStringQuote(otpGenerate(key, counter, options)), nil
+	})
+	otpFunc("verifyHOTP", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 3, 4); err != nil {
+			return nil, err
+		}
+		code, err := stringArg(in, args, 0, "verifyHOTP")
+		if err != nil {
+			return nil, err
+		}
+		key, err := secretArg(in, args, 1, "verifyHOTP")
+		if err != nil {
+			return nil, err
+		}
+		counter, err := counterArg(in, args, 2, "verifyHOTP")
+		if err != nil {
+			return nil, err
+		}
+		options, err := optionsArg(in, args, 3, "verifyHOTP")
+		if err != nil {
+			return nil, err
+		}
+		for c := counter; c <= counter+uint64(options.window); c++ {
+			if otpCodesEqual(code, otpGenerate(key, c, options)) {
+				return int64(c), nil
+			}
+		}
+		return nil, nil
+	})
+	otpFunc("verifyTOTP", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if err := checkArgsLen(in, args, 2, 3); err != nil {
+			return nil, err
+		}
+		code, err := stringArg(in, args, 0, "verifyTOTP")
+		if err != nil {
+			return nil, err
+		}
+		key, err := secretArg(in, args, 1, "verifyTOTP")
+		if err != nil {
+			return nil, err
+		}
+		options, err := optionsArg(in, args, 2, "verifyTOTP")
+		if err != nil {
+			return nil, err
+		}
+		counter := options.time / options.period
+		valid := false
+		for c := counter - options.window; c <= counter+options.window; c++ {
+			if c >= 0 && otpCodesEqual(code, otpGenerate(key, uint64(c), options)) {
+				valid = true
+			}
+		}
+		return valid, nil
+	})
+
+	i.globals.Define(className, otpClass)
+}
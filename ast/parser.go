@@ -1,8 +1,11 @@
 package ast
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strings"
+	"unicode"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
@@ -10,14 +13,128 @@ import (
 	"github.com/AlanLuu/lox/util"
 )
 
+// Maps each compound assignment operator to the binary operator that it
+// applies before assigning
+var compoundAssignOperators = map[token.TokenType]token.TokenType{
+	token.AMPERSAND_EQUAL:      token.AMPERSAND,
+	token.CARET_EQUAL:          token.CARET,
+	token.DOUBLE_GREATER_EQUAL: token.DOUBLE_GREATER,
+	token.DOUBLE_LESS_EQUAL:    token.DOUBLE_LESS,
+	token.MINUS_EQUAL:          token.MINUS,
+	token.PERCENT_EQUAL:        token.PERCENT,
+	token.PIPE_EQUAL:           token.PIPE,
+	token.PLUS_EQUAL:           token.PLUS,
+	token.SLASH_EQUAL:          token.SLASH,
+	token.STAR_EQUAL:           token.STAR,
+}
+
+var compoundAssignTokens = []token.TokenType{
+	token.AMPERSAND_EQUAL,
+	token.CARET_EQUAL,
+	token.DOUBLE_GREATER_EQUAL,
+	token.DOUBLE_LESS_EQUAL,
+	token.MINUS_EQUAL,
+	token.PERCENT_EQUAL,
+	token.PIPE_EQUAL,
+	token.PLUS_EQUAL,
+	token.SLASH_EQUAL,
+	token.STAR_EQUAL,
+}
+
+// Reports whether the specified expression is a property or index access
+// using "?.", which cannot be assigned to
+func isOptionalAccess(expr Expr) bool {
+	switch expr := expr.(type) {
+	case Get:
+		return expr.Optional
+	case Index:
+		return expr.Optional
+	}
+	return false
+}
+
+type parsedComment struct {
+	token *token.Token
+	next  int
+}
+
+// The last member of every class body only holds the comments before the
+// closing brace
+type classMember struct {
+	comments list.List[Stmt]
+	name     string
+	isStatic bool
+	isField  bool
+	method   int //Index in the class's list of methods
+}
+
 type Parser struct {
-	tokens    list.List[*token.Token]
-	current   int
-	loopDepth int
+	tokens     list.List[*token.Token]
+	current    int
+	loopDepth  int
+	blockDepth int
+	errors     []error
+
+	//Only used when parsing source code for formatting
+	formatting   bool
+	comments     []parsedComment
+	nextComment  int
+	classMembers map[*token.Token][]classMember
 }
 
 func NewParser(tokens list.List[*token.Token]) *Parser {
-	return &Parser{tokens, 0, 0}
+	return &Parser{
+		tokens: tokens,
+		errors: []error{},
+	}
+}
+
+func newFormatParser(tokens list.List[*token.Token]) *Parser {
+	p := NewParser(list.NewListCap[*token.Token](int64(len(tokens))))
+	p.formatting = true
+	p.classMembers = make(map[*token.Token][]classMember)
+	for _, theToken := range tokens {
+		if theToken.TokenType == token.COMMENT {
+			p.comments = append(p.comments, parsedComment{theToken, len(p.tokens)})
+		} else {
+			p.tokens.Add(theToken)
+		}
+	}
+	return p
+}
+
+func (p *Parser) addComments(statements *list.List[Stmt], atStart bool) {
+	if !p.formatting {
+		return
+	}
+	lastLine := 0
+	if p.current > 0 {
+		lastLine = p.previous().Line
+	}
+	addBlankLine := func(line int) {
+		if !atStart && line-lastLine > 1 {
+			statements.Add(BlankLine{})
+		}
+		atStart = false
+	}
+	for ; p.nextComment < len(p.comments); p.nextComment++ {
+		comment := p.comments[p.nextComment]
+		if comment.next > p.current {
+			break
+		}
+		text := strings.TrimRightFunc(comment.token.Lexeme, unicode.IsSpace)
+		if comment.next == p.current && p.current > 0 &&
+			comment.token.Line == p.previous().Line {
+			statements.Add(Comment{Text: text, Trailing: true})
+		} else {
+			addBlankLine(comment.token.Line)
+			statements.Add(Comment{Text: text})
+		}
+		lastLine = comment.token.Line
+	}
+	if !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		addBlankLine(p.peek().Line)
+	}
 }
 
 func (p *Parser) advance() *token.Token {
@@ -61,7 +178,7 @@ func (p *Parser) assertStatement() (Stmt, error) {
 }
 
 func (p *Parser) assignment() (Expr, error) {
-	expr, exprErr := p.or()
+	expr, exprErr := p.nullCoalesce()
 	if exprErr != nil {
 		return nil, exprErr
 	}
@@ -72,9 +189,12 @@ func (p *Parser) assignment() (Expr, error) {
 		if valueErr != nil {
 			return nil, valueErr
 		}
+		if isOptionalAccess(expr) {
+			return nil, p.error(equals, "Invalid assignment target.")
+		}
 		switch expr := expr.(type) {
 		case Variable:
-			return Assign{Name: expr.Name, Value: value}, nil
+			return Assign{Name: expr.Name, Value: value, Resolution: &Resolution{}}, nil
 		case Get:
 			return Set{
 				Object: expr.Object,
@@ -92,6 +212,50 @@ func (p *Parser) assignment() (Expr, error) {
 		return nil, p.error(equals, "Invalid assignment target.")
 	}
 
+	if p.match(compoundAssignTokens...) {
+		compound := p.previous()
+		value, valueErr := p.assignment()
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		if isOptionalAccess(expr) {
+			return nil, p.error(compound, "Invalid assignment target.")
+		}
+		//"a op= b" is desugared into "a = a op b", and assignments to
+		//properties and indexes evaluate the target of "a" only once
+		operator := token.NewToken(
+			compoundAssignOperators[compound.TokenType],
+			strings.TrimSuffix(compound.Lexeme, "="),
+			nil,
+			compound.Line,
+			0,
+		)
+		binary := Binary{
+			Left:     expr,
+			Operator: operator,
+			Right:    value,
+			Compound: true,
+		}
+		switch expr := expr.(type) {
+		case Variable:
+			return Assign{Name: expr.Name, Value: binary, Resolution: &Resolution{}}, nil
+		case Get:
+			return Set{
+				Object: expr.Object,
+				Name:   expr.Name,
+				Value:  binary,
+			}, nil
+		case Index:
+			set := Set{
+				Object: expr,
+				Name:   expr.Bracket,
+				Value:  binary,
+			}
+			return SetObject{set}, nil
+		}
+		return nil, p.error(compound, "Invalid assignment target.")
+	}
+
 	return expr, nil
 }
 
@@ -176,15 +340,22 @@ func (p *Parser) bitwiseXor() (Expr, error) {
 }
 
 func (p *Parser) block() (list.List[Stmt], error) {
+	p.blockDepth++
+	defer func() {
+		p.blockDepth--
+	}()
 	statements := list.NewList[Stmt]()
 	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		p.addComments(&statements, len(statements) == 0)
 		declaration, declarationErr := p.declaration()
 		if declarationErr != nil {
-			statements.Clear()
-			return statements, declarationErr
+			//The error has already been recorded, so keep parsing
+			//the rest of the block to find more errors
+			continue
 		}
 		statements.Add(declaration)
 	}
+	p.addComments(&statements, len(statements) == 0)
 	_, consumeErr := p.consume(token.RIGHT_BRACE, "Expected '}' after block.")
 	if consumeErr != nil {
 		statements.Clear()
@@ -202,7 +373,7 @@ func (p *Parser) breakStatement() (Stmt, error) {
 	if consumeErr != nil {
 		return nil, consumeErr
 	}
-	return Break{}, nil
+	return Break{Keyword: breakToken}, nil
 }
 
 func (p *Parser) call() (Expr, error) {
@@ -223,56 +394,26 @@ func (p *Parser) call() (Expr, error) {
 				return nil, nameErr
 			}
 			expr = Get{Object: expr, Name: name}
-		} else if p.match(token.LEFT_BRACKET) {
-			if p.match(token.COLON) {
-				var indexEnd Expr
-				if p.peek().TokenType != token.RIGHT_BRACKET {
-					var indexEndErr error
-					indexEnd, indexEndErr = p.expression()
-					if indexEndErr != nil {
-						return nil, indexEndErr
-					}
-				}
-				rightBracket, rightBracketErr := p.consume(token.RIGHT_BRACKET, "Expected ']' after index.")
-				if rightBracketErr != nil {
-					return nil, rightBracketErr
-				}
-				expr = Index{
-					IndexElement: expr,
-					Bracket:      rightBracket,
-					Index:        nil,
-					IndexEnd:     indexEnd,
-					IsSlice:      true,
+		} else if p.match(token.QUESTION_DOT) {
+			if p.match(token.LEFT_BRACKET) {
+				indexExpr, indexExprErr := p.finishIndex(expr, true)
+				if indexExprErr != nil {
+					return nil, indexExprErr
 				}
+				expr = indexExpr
 				continue
 			}
-			index, indexErr := p.expression()
-			if indexErr != nil {
-				return nil, indexErr
-			}
-			var indexEnd Expr
-			isSlice := false
-			if p.match(token.COLON) {
-				isSlice = true
-				if p.peek().TokenType != token.RIGHT_BRACKET {
-					var indexEndErr error
-					indexEnd, indexEndErr = p.expression()
-					if indexEndErr != nil {
-						return nil, indexEndErr
-					}
-				}
-			}
-			rightBracket, rightBracketErr := p.consume(token.RIGHT_BRACKET, "Expected ']' after index.")
-			if rightBracketErr != nil {
-				return nil, rightBracketErr
+			name, nameErr := p.consume(token.IDENTIFIER, "Expected property name after '?.'.")
+			if nameErr != nil {
+				return nil, nameErr
 			}
-			expr = Index{
-				IndexElement: expr,
-				Bracket:      rightBracket,
-				Index:        index,
-				IndexEnd:     indexEnd,
-				IsSlice:      isSlice,
+			expr = Get{Object: expr, Name: name, Optional: true}
+		} else if p.match(token.LEFT_BRACKET) {
+			indexExpr, indexExprErr := p.finishIndex(expr, false)
+			if indexExprErr != nil {
+				return nil, indexExprErr
 			}
+			expr = indexExpr
 		} else {
 			break
 		}
@@ -287,6 +428,13 @@ func (p *Parser) check(tokenType token.TokenType) bool {
 	return p.peek().TokenType == tokenType
 }
 
+func (p *Parser) checkNext(tokenType token.TokenType) bool {
+	if p.isAtEnd() || p.current+1 >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[p.current+1].TokenType == tokenType
+}
+
 func (p *Parser) classDeclaration(canInstantiate bool) (Stmt, error) {
 	className, classNameErr := p.consume(token.IDENTIFIER, "Expected class name.")
 	if classNameErr != nil {
@@ -299,7 +447,7 @@ func (p *Parser) classDeclaration(canInstantiate bool) (Stmt, error) {
 		if superClassNameErr != nil {
 			return nil, superClassNameErr
 		}
-		superClass = &Variable{p.previous()}
+		superClass = &Variable{Name: p.previous(), Resolution: &Resolution{}}
 	}
 
 	_, leftBraceErr := p.consume(token.LEFT_BRACE, "Expected '{' before class body.")
@@ -311,7 +459,12 @@ func (p *Parser) classDeclaration(canInstantiate bool) (Stmt, error) {
 	classMethods := list.NewList[Function]()
 	classFields := make(map[string]Expr)
 	instanceFields := make(map[string]Expr)
+	members := []classMember{}
 	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		member := classMember{}
+		if p.formatting {
+			p.addComments(&member.comments, len(members) == 0)
+		}
 		isStatic := false
 		if p.match(token.STATIC) {
 			isStatic = true
@@ -320,7 +473,10 @@ func (p *Parser) classDeclaration(canInstantiate bool) (Stmt, error) {
 		if nameErr != nil {
 			return nil, nameErr
 		}
+		member.name = name.Lexeme
+		member.isStatic = isStatic
 		if p.match(token.EQUAL) {
+			member.isField = true
 			expr, exprErr := p.expression()
 			if exprErr != nil {
 				return nil, exprErr
@@ -344,11 +500,19 @@ func (p *Parser) classDeclaration(canInstantiate bool) (Stmt, error) {
 				return nil, methodErr
 			}
 			if isStatic {
+				member.method = len(classMethods)
 				classMethods.Add(Function{Name: name, Function: method})
 			} else {
+				member.method = len(methods)
 				methods.Add(Function{Name: name, Function: method})
 			}
 		}
+		members = append(members, member)
+	}
+	if p.formatting {
+		member := classMember{}
+		p.addComments(&member.comments, len(members) == 0)
+		p.classMembers[className] = append(members, member)
 	}
 
 	_, rightBraceErr := p.consume(token.RIGHT_BRACE, "Expected '}' after class body.")
@@ -402,7 +566,7 @@ func (p *Parser) continueStatement() (Stmt, error) {
 	if consumeErr != nil {
 		return nil, consumeErr
 	}
-	return Continue{}, nil
+	return Continue{Keyword: continueToken}, nil
 }
 
 func (p *Parser) declaration() (Stmt, error) {
@@ -427,6 +591,7 @@ func (p *Parser) declaration() (Stmt, error) {
 		value, err = p.statement(false)
 	}
 	if err != nil {
+		p.errors = append(p.errors, err)
 		p.synchronize()
 		return nil, err
 	}
@@ -572,7 +737,7 @@ func (p *Parser) error(theToken *token.Token, message string) error {
 	} else {
 		theError = loxerror.GiveError(theToken.Line, " at '"+theToken.Lexeme+"'", message)
 	}
-	return theError
+	return loxerror.TokenError(theToken, theError.Error())
 }
 
 func (p *Parser) expression() (Expr, error) {
@@ -661,7 +826,28 @@ func (p *Parser) factor() (Expr, error) {
 func (p *Parser) finishCall(callee Expr) (Expr, error) {
 	arguments := list.NewList[Expr]()
 	if !p.check(token.RIGHT_PAREN) {
+		namedArgs := make(map[string]bool)
 		for cond := true; cond; cond = p.match(token.COMMA) {
+			if p.check(token.IDENTIFIER) && p.checkNext(token.COLON) {
+				name := p.advance()
+				p.advance()
+				if namedArgs[name.Lexeme] {
+					arguments.Clear()
+					return nil, p.error(name, "Duplicate named argument '"+name.Lexeme+"'.")
+				}
+				namedArgs[name.Lexeme] = true
+				value, valueErr := p.expression()
+				if valueErr != nil {
+					arguments.Clear()
+					return nil, valueErr
+				}
+				arguments.Add(NamedArgument{Name: name, Value: value})
+				continue
+			}
+			if len(namedArgs) > 0 {
+				arguments.Clear()
+				return nil, p.error(p.peek(), "Positional argument can't follow a named argument.")
+			}
 			spread := p.match(token.ELLIPSIS)
 			expr, exprErr := p.expression()
 			if exprErr != nil {
@@ -686,6 +872,59 @@ func (p *Parser) finishCall(callee Expr) (Expr, error) {
 	}, nil
 }
 
+func (p *Parser) finishIndex(expr Expr, optional bool) (Expr, error) {
+	if p.match(token.COLON) {
+		var indexEnd Expr
+		if p.peek().TokenType != token.RIGHT_BRACKET {
+			var indexEndErr error
+			indexEnd, indexEndErr = p.expression()
+			if indexEndErr != nil {
+				return nil, indexEndErr
+			}
+		}
+		rightBracket, rightBracketErr := p.consume(token.RIGHT_BRACKET, "Expected ']' after index.")
+		if rightBracketErr != nil {
+			return nil, rightBracketErr
+		}
+		return Index{
+			IndexElement: expr,
+			Bracket:      rightBracket,
+			Index:        nil,
+			IndexEnd:     indexEnd,
+			IsSlice:      true,
+			Optional:     optional,
+		}, nil
+	}
+	index, indexErr := p.expression()
+	if indexErr != nil {
+		return nil, indexErr
+	}
+	var indexEnd Expr
+	isSlice := false
+	if p.match(token.COLON) {
+		isSlice = true
+		if p.peek().TokenType != token.RIGHT_BRACKET {
+			var indexEndErr error
+			indexEnd, indexEndErr = p.expression()
+			if indexEndErr != nil {
+				return nil, indexEndErr
+			}
+		}
+	}
+	rightBracket, rightBracketErr := p.consume(token.RIGHT_BRACKET, "Expected ']' after index.")
+	if rightBracketErr != nil {
+		return nil, rightBracketErr
+	}
+	return Index{
+		IndexElement: expr,
+		Bracket:      rightBracket,
+		Index:        index,
+		IndexEnd:     indexEnd,
+		IsSlice:      isSlice,
+		Optional:     optional,
+	}, nil
+}
+
 func (p *Parser) forStatement() (Stmt, error) {
 	p.loopDepth++
 	defer func() {
@@ -830,6 +1069,7 @@ func (p *Parser) functionBody(kind string, funcHasName bool) (FunctionExpr, erro
 		}()
 		p.loopDepth = 0
 	}
+	start := p.previous()
 	emptyFuncNode := FunctionExpr{}
 	if funcHasName {
 		_, leftParenErr := p.consume(token.LEFT_PAREN, fmt.Sprintf("Expected '(' after %v name.", kind))
@@ -849,25 +1089,45 @@ func (p *Parser) functionBody(kind string, funcHasName bool) (FunctionExpr, erro
 	}
 
 	parameters := list.NewList[*token.Token]()
+	defaults := list.NewList[Expr]()
 	varArgPos := -1
 	if !p.check(token.RIGHT_PAREN) {
 		varArgPosCount := 0
+		hasDefault := false
 		for cond := true; cond; cond = p.match(token.COMMA) {
 			if len(parameters) >= 255 {
 				loxerror.PrintErrorObject(p.error(p.peek(), "Can't have more than 255 parameters."))
 			}
+			isVarArg := false
 			if p.match(token.ELLIPSIS) {
 				if varArgPos >= 0 {
 					return emptyFuncNode, p.error(p.peek(), fmt.Sprintf("Can't have multiple varargs in %v.", kind))
 				}
 				varArgPos = varArgPosCount
+				isVarArg = true
 			}
 			paramName, paramNameErr := p.consume(token.IDENTIFIER, "Expected parameter name.")
 			if paramNameErr != nil {
 				parameters.Clear()
 				return emptyFuncNode, paramNameErr
 			}
+			var defaultValue Expr
+			if p.match(token.EQUAL) {
+				if isVarArg {
+					return emptyFuncNode, p.error(paramName, "Varargs parameter can't have a default value.")
+				}
+				var defaultValueErr error
+				defaultValue, defaultValueErr = p.expression()
+				if defaultValueErr != nil {
+					return emptyFuncNode, defaultValueErr
+				}
+				hasDefault = true
+			} else if hasDefault && !isVarArg && varArgPos < 0 {
+				return emptyFuncNode, p.error(paramName,
+					"Parameter without a default value can't follow a parameter with a default value.")
+			}
 			parameters.Add(paramName)
+			defaults.Add(defaultValue)
 			varArgPosCount++
 		}
 	}
@@ -904,8 +1164,10 @@ func (p *Parser) functionBody(kind string, funcHasName bool) (FunctionExpr, erro
 	}
 	return FunctionExpr{
 		Params:    parameters,
+		Defaults:  defaults,
 		Body:      block,
 		VarArgPos: varArgPos,
+		Start:     start,
 	}, nil
 }
 
@@ -1028,6 +1290,26 @@ func (p *Parser) match(tokenTypes ...token.TokenType) bool {
 	return false
 }
 
+func (p *Parser) nullCoalesce() (Expr, error) {
+	expr, exprErr := p.or()
+	if exprErr != nil {
+		return nil, exprErr
+	}
+	for p.match(token.DOUBLE_QUESTION) {
+		operator := p.previous()
+		right, orErr := p.or()
+		if orErr != nil {
+			return nil, orErr
+		}
+		expr = Logical{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr, nil
+}
+
 func (p *Parser) or() (Expr, error) {
 	expr, exprErr := p.and()
 	if exprErr != nil {
@@ -1051,15 +1333,28 @@ func (p *Parser) or() (Expr, error) {
 func (p *Parser) Parse() (list.List[Stmt], error) {
 	statements := list.NewList[Stmt]()
 	for !p.isAtEnd() {
+		p.addComments(&statements, len(statements) == 0)
 		statement, err := p.declaration()
 		if err != nil {
-			return statements, err
+			//The error has already been recorded, so keep parsing
+			//the rest of the tokens to find more errors
+			continue
 		}
 		statements.Add(statement)
 	}
+	p.addComments(&statements, len(statements) == 0)
+	if len(p.errors) > 0 {
+		return statements, errors.Join(p.errors...)
+	}
 	return statements, nil
 }
 
+// Returns every syntax error that was found by the last call to Parse, in
+// the order that they appear in the source code
+func (p *Parser) Errors() []error {
+	return p.errors
+}
+
 func (p *Parser) peek() *token.Token {
 	return p.tokens[p.current]
 }
@@ -1081,7 +1376,8 @@ func (p *Parser) primary() (Expr, error) {
 	case p.match(token.NIL):
 		return Literal{Value: nil}, nil
 	case p.match(token.NUMBER):
-		return Literal{Value: p.previous().Literal}, nil
+		previous := p.previous()
+		return Literal{Value: previous.Literal, Lexeme: previous.Lexeme}, nil
 	case p.match(token.BIG_NUMBER):
 		previous := p.previous()
 		return BigNum{
@@ -1092,7 +1388,7 @@ func (p *Parser) primary() (Expr, error) {
 		previous := p.previous()
 		return String{Str: previous.Literal.(string), Quote: previous.Quote}, nil
 	case p.match(token.IDENTIFIER):
-		return Variable{Name: p.previous()}, nil
+		return Variable{Name: p.previous(), Resolution: &Resolution{}}, nil
 	case p.match(token.FUN):
 		return p.functionBody("function", false)
 	case p.match(token.LEFT_BRACE):
@@ -1109,9 +1405,9 @@ func (p *Parser) primary() (Expr, error) {
 		if methodErr != nil {
 			return nil, methodErr
 		}
-		return Super{Keyword: keyword, Method: method}, nil
+		return Super{Keyword: keyword, Method: method, Resolution: &Resolution{}}, nil
 	case p.match(token.THIS):
-		return This{Keyword: p.previous()}, nil
+		return This{Keyword: p.previous(), Resolution: &Resolution{}}, nil
 	case p.match(token.LEFT_PAREN):
 		expr, expressionErr := p.expression()
 		if expressionErr != nil {
@@ -1231,7 +1527,13 @@ func (p *Parser) statement(alwaysBlock bool) (Stmt, error) {
 	return p.expressionStatement()
 }
 
+// Skips tokens until the start of the next statement after a syntax error,
+// so that parsing can continue and find more errors. The closing brace of
+// the block being parsed is not skipped, so that the block can still end.
 func (p *Parser) synchronize() {
+	if p.blockDepth > 0 && p.check(token.RIGHT_BRACE) {
+		return
+	}
 	p.advance()
 	for !p.isAtEnd() {
 		if p.previous().TokenType == token.SEMICOLON {
@@ -1239,6 +1541,10 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().TokenType {
+		case token.RIGHT_BRACE:
+			if p.blockDepth > 0 {
+				return
+			}
 		case token.CLASS:
 			fallthrough
 		case token.FUN:
@@ -1284,11 +1590,14 @@ func (p *Parser) tryCatchFinallyStatement() (Stmt, error) {
 		return nil, tryBlockListErr
 	}
 
-	var catchBlockList list.List[Stmt] = nil
-	var catchName *token.Token
-	foundCatchBlock := false
-	if p.match(token.CATCH) {
-		foundCatchBlock = true
+	catchClauses := list.NewList[CatchClause]()
+	for p.match(token.CATCH) {
+		catchToken := p.previous()
+		if len(catchClauses) > 0 && catchClauses[len(catchClauses)-1].ErrorClass == nil {
+			return nil, p.error(catchToken, "Catch clause without an error class must be the last catch clause.")
+		}
+		var catchName *token.Token
+		var errorClass *Variable
 		leftBraceErrMsg := "Expected '(' or '{' after 'catch'."
 		if p.match(token.LEFT_PAREN) {
 			var catchNameErr error
@@ -1296,7 +1605,16 @@ func (p *Parser) tryCatchFinallyStatement() (Stmt, error) {
 			if catchNameErr != nil {
 				return nil, catchNameErr
 			}
-			_, rightParenErr := p.consume(token.RIGHT_PAREN, "Expected ')' after identifier name.")
+			rightParenErrMsg := "Expected ')' after identifier name."
+			if p.match(token.COLON) {
+				errorClassName, errorClassErr := p.consume(token.IDENTIFIER, "Expected error class name after ':'.")
+				if errorClassErr != nil {
+					return nil, errorClassErr
+				}
+				errorClass = &Variable{Name: errorClassName, Resolution: &Resolution{}}
+				rightParenErrMsg = "Expected ')' after error class name."
+			}
+			_, rightParenErr := p.consume(token.RIGHT_PAREN, rightParenErrMsg)
 			if rightParenErr != nil {
 				return nil, rightParenErr
 			}
@@ -1306,52 +1624,37 @@ func (p *Parser) tryCatchFinallyStatement() (Stmt, error) {
 		if leftBraceErr != nil {
 			return nil, leftBraceErr
 		}
-		var catchBlockListErr error
-		catchBlockList, catchBlockListErr = p.block()
+		catchBlockList, catchBlockListErr := p.block()
 		if catchBlockListErr != nil {
 			return nil, catchBlockListErr
 		}
+		catchClauses.Add(CatchClause{
+			Name:       catchName,
+			ErrorClass: errorClass,
+			Block:      Block{Statements: catchBlockList},
+		})
 	}
 
-	var finallyBlockList list.List[Stmt] = nil
-	foundFinallyBlock := false
+	var finallyBlock Stmt = nil
 	if p.match(token.FINALLY) {
-		foundFinallyBlock = true
 		_, leftBraceErr = p.consume(token.LEFT_BRACE, "Expected '{' after 'finally'.")
 		if leftBraceErr != nil {
 			return nil, leftBraceErr
 		}
-		var finallyBlockListErr error
-		finallyBlockList, finallyBlockListErr = p.block()
+		finallyBlockList, finallyBlockListErr := p.block()
 		if finallyBlockListErr != nil {
 			return nil, finallyBlockListErr
 		}
+		finallyBlock = Block{Statements: finallyBlockList}
 	}
 
-	if !foundCatchBlock && !foundFinallyBlock {
+	if len(catchClauses) == 0 && finallyBlock == nil {
 		return nil, p.error(p.peek(), "Expected 'catch' or 'finally' after try block.")
 	}
-	if !foundCatchBlock {
-		return TryCatchFinally{
-			Block{Statements: tryBlockList},
-			catchName,
-			nil,
-			Block{Statements: finallyBlockList},
-		}, nil
-	}
-	if !foundFinallyBlock {
-		return TryCatchFinally{
-			Block{Statements: tryBlockList},
-			catchName,
-			Block{Statements: catchBlockList},
-			nil,
-		}, nil
-	}
 	return TryCatchFinally{
 		Block{Statements: tryBlockList},
-		catchName,
-		Block{Statements: catchBlockList},
-		Block{Statements: finallyBlockList},
+		catchClauses,
+		finallyBlock,
 	}, nil
 }
 
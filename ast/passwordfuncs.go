@@ -0,0 +1,134 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/password"
+	"github.com/AlanLuu/lox/token"
+)
+
+func crackTimeDisplay(seconds float64) string {
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"century", 100 * 365 * 24 * 60 * 60},
+		{"year", 365 * 24 * 60 * 60},
+		{"month", 31 * 24 * 60 * 60},
+		{"day", 24 * 60 * 60},
+		{"hour", 60 * 60},
+		{"minute", 60},
+		{"second", 1},
+	}
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= units[0].seconds {
+		return "centuries"
+	}
+	for _, unit := range units[1:] {
+		if seconds >= unit.seconds {
+			amount := int64(math.Round(seconds / unit.seconds))
+			if amount == 1 {
+				return fmt.Sprintf("1 %v", unit.name)
+			}
+			return fmt.Sprintf("%v %vs", amount, unit.name)
+		}
+	}
+	return "less than a second"
+}
+
+func (i *Interpreter) definePasswordFuncs() {
+	className := "password"
+	passwordClass := NewLoxClass(className, nil, false)
+	passwordFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native password fn %v at %p>", name, &s)
+		}
+		passwordClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'password.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	passwordFunc("breachCount", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			if sandboxErr := checkSandbox(in.callToken, "Network access"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
+			count, err := password.BreachCount(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return count, nil
+		}
+		return argMustBeType(in.callToken, "breachCount", "string")
+	})
+	passwordFunc("isBreached", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			if sandboxErr := checkSandbox(in.callToken, "Network access"); sandboxErr != nil {
+				return nil, sandboxErr
+			}
+			count, err := password.BreachCount(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return count > 0, nil
+		}
+		return argMustBeType(in.callToken, "isBreached", "string")
+	})
+	passwordFunc("strength", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'password.strength' must be a string.")
+		}
+		var userInputs []string
+		if argsLen == 2 {
+			inputsList, ok := args[1].(*LoxList)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'password.strength' must be a list.")
+			}
+			for _, element := range inputsList.elements {
+				loxStr, ok := element.(*LoxString)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"List passed to 'password.strength' must only contain strings.")
+				}
+				userInputs = append(userInputs, loxStr.str)
+			}
+		}
+
+		result := password.Strength(args[0].(*LoxString).str, userInputs)
+		setStr := func(dict *LoxDict, key string, value any) {
+			dict.setKeyValue(NewLoxString(key, '\''), value)
+		}
+		suggestions := list.NewListCap[any](int64(len(result.Suggestions)))
+		for _, suggestion := range result.Suggestions {
+			suggestions.Add(NewLoxStringQuote(suggestion))
+		}
+		dict := EmptyLoxDict()
+		setStr(dict, "score", result.Score)
+		setStr(dict, "guesses", result.Guesses)
+		setStr(dict, "guessesLog10", math.Log10(result.Guesses))
+		setStr(dict, "crackTimeSeconds", result.CrackTime)
+		setStr(dict, "crackTimeDisplay", NewLoxStringQuote(crackTimeDisplay(result.CrackTime)))
+		setStr(dict, "warning", NewLoxStringQuote(result.Warning))
+		setStr(dict, "suggestions", NewLoxList(suggestions))
+		return dict, nil
+	})
+
+	i.globals.Define(className, passwordClass)
+}
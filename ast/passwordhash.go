@@ -0,0 +1,216 @@
+package ast
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The parameters of an scrypt or argon2id password hash, where n, r, and p
+// are only used by scrypt and memory, iterations, and parallelism are only
+// used by argon2id
+type passwordHashParams struct {
+	n           int
+	r           int
+	p           int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	keyLen      int
+	saltLen     int
+}
+
+var defaultScryptParams = passwordHashParams{
+	n:       1 << 15,
+	r:       8,
+	p:       1,
+	keyLen:  32,
+	saltLen: 16,
+}
+
+var defaultArgon2Params = passwordHashParams{
+	memory:      64 * 1024,
+	iterations:  3,
+	parallelism: 4,
+	keyLen:      32,
+	saltLen:     16,
+}
+
+// Sets the parameters from the specified dictionary of options, where the
+// allowed option names depend on whether the hash is scrypt or argon2id
+func (p *passwordHashParams) setOptions(options *LoxDict, isScrypt bool) error {
+	return forEachOption(options, func(key string, value any) error {
+		intValue, ok := value.(int64)
+		if !ok {
+			return loxerror.Error(fmt.Sprintf("Password hash option '%v' must be an integer.", key))
+		}
+		inRange := func(min int64, max int64) error {
+			if intValue < min || intValue > max {
+				return loxerror.Error(fmt.Sprintf(
+					"Password hash option '%v' must be between %v and %v.", key, min, max))
+			}
+			return nil
+		}
+		var err error
+		switch {
+		case key == "keyLen":
+			err = inRange(16, 1024)
+			p.keyLen = int(intValue)
+		case key == "saltLen":
+			err = inRange(8, 1024)
+			p.saltLen = int(intValue)
+		case isScrypt && key == "n":
+			if intValue < 2 || bits.OnesCount64(uint64(intValue)) != 1 {
+				return loxerror.Error("Password hash option 'n' must be a power of 2 greater than 1.")
+			}
+			err = inRange(2, 1<<30)
+			p.n = int(intValue)
+		case isScrypt && key == "r":
+			err = inRange(1, 1<<20)
+			p.r = int(intValue)
+		case isScrypt && key == "p":
+			err = inRange(1, 1<<20)
+			p.p = int(intValue)
+		case !isScrypt && key == "memory":
+			err = inRange(8, 1<<32-1)
+			p.memory = uint32(intValue)
+		case !isScrypt && key == "iterations":
+			err = inRange(1, 1<<32-1)
+			p.iterations = uint32(intValue)
+		case !isScrypt && key == "parallelism":
+			err = inRange(1, 255)
+			p.parallelism = uint8(intValue)
+		default:
+			return loxerror.Error(fmt.Sprintf("Unknown password hash option '%v'.", key))
+		}
+		return err
+	})
+}
+
+func passwordHashSalt(saltLen int) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(crand.Reader, salt); err != nil {
+		return nil, loxerror.Error("Failed to generate random salt.")
+	}
+	return salt, nil
+}
+
+var passwordHashEncoding = base64.RawStdEncoding
+
+// Returns the scrypt hash of the password in the PHC string format, such as
+// $scrypt$ln=15,r=8,p=1$<salt>$<hash>
+func scryptHash(password []byte, params passwordHashParams) (string, error) {
+	salt, err := passwordHashSalt(params.saltLen)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key(password, salt, params.n, params.r, params.p, params.keyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%v,r=%v,p=%v$%v$%v",
+		bits.TrailingZeros(uint(params.n)), params.r, params.p,
+		passwordHashEncoding.EncodeToString(salt),
+		passwordHashEncoding.EncodeToString(key),
+	), nil
+}
+
+// Returns the argon2id hash of the password in the PHC string format, such
+// as $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func argon2Hash(password []byte, params passwordHashParams) (string, error) {
+	salt, err := passwordHashSalt(params.saltLen)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey(password, salt, params.iterations, params.memory, params.parallelism, uint32(params.keyLen))
+	return fmt.Sprintf("$argon2id$v=%v$m=%v,t=%v,p=%v$%v$%v",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		passwordHashEncoding.EncodeToString(salt),
+		passwordHashEncoding.EncodeToString(key),
+	), nil
+}
+
+// Splits a PHC string into its parameters, salt, and hash, where the
+// parameters are returned as a map of names to integers
+func parsePHCString(hash string, algorithm string, hasVersion bool) (map[string]uint64, []byte, []byte, error) {
+	invalidErr := loxerror.Error(fmt.Sprintf("Invalid %v hash.", algorithm))
+	parts := strings.Split(hash, "$")
+	numParts := 5
+	if hasVersion {
+		numParts = 6
+	}
+	if len(parts) != numParts || parts[0] != "" || parts[1] != algorithm {
+		return nil, nil, nil, invalidErr
+	}
+	if hasVersion {
+		if parts[2] != fmt.Sprintf("v=%v", argon2.Version) {
+			return nil, nil, nil, loxerror.Error(fmt.Sprintf("Unsupported %v version.", algorithm))
+		}
+		parts = append(parts[:2], parts[3:]...)
+	}
+	params := make(map[string]uint64)
+	for _, param := range strings.Split(parts[2], ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, nil, nil, invalidErr
+		}
+		num, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, nil, nil, invalidErr
+		}
+		params[name] = num
+	}
+	salt, err := passwordHashEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, nil, nil, invalidErr
+	}
+	key, err := passwordHashEncoding.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, invalidErr
+	}
+	return params, salt, key, nil
+}
+
+// Returns whether the password matches the specified scrypt hash
+func scryptVerify(password []byte, hash string) (bool, error) {
+	params, salt, key, err := parsePHCString(hash, "scrypt", false)
+	if err != nil {
+		return false, err
+	}
+	ln, lnOk := params["ln"]
+	r, rOk := params["r"]
+	p, pOk := params["p"]
+	if !lnOk || !rOk || !pOk || ln < 1 || ln > 30 {
+		return false, loxerror.Error("Invalid scrypt hash.")
+	}
+	computed, err := scrypt.Key(password, salt, 1<<ln, int(r), int(p), len(key))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
+
+// Returns whether the password matches the specified argon2id hash
+func argon2Verify(password []byte, hash string) (bool, error) {
+	params, salt, key, err := parsePHCString(hash, "argon2id", true)
+	if err != nil {
+		return false, err
+	}
+	m, mOk := params["m"]
+	t, tOk := params["t"]
+	p, pOk := params["p"]
+	if !mOk || !tOk || !pOk || t < 1 || p < 1 || p > 255 {
+		return false, loxerror.Error("Invalid argon2id hash.")
+	}
+	computed := argon2.IDKey(password, salt, uint32(t), uint32(m), uint8(p), uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
@@ -0,0 +1,428 @@
+package ast
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+// Lexical path operations for a specific path style, which work the same
+// way as the functions in path/filepath do on that style's operating system
+type lexicalPath struct {
+	windows bool
+}
+
+func (p lexicalPath) sep() byte {
+	if p.windows {
+		return '\\'
+	}
+	return '/'
+}
+
+func (p lexicalPath) listSep() string {
+	if p.windows {
+		return ";"
+	}
+	return ":"
+}
+
+func (p lexicalPath) isSep(c byte) bool {
+	return c == '/' || (p.windows && c == '\\')
+}
+
+func (p lexicalPath) sameWord(a, b string) bool {
+	if p.windows {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// Returns the length of the leading volume name of a path, which is either
+// a drive letter such as "C:" or a UNC prefix such as "\\host\share" for
+// Windows paths and is always empty for POSIX paths
+func (p lexicalPath) volumeNameLen(path string) int {
+	if !p.windows || len(path) < 2 {
+		return 0
+	}
+	c := path[0]
+	if path[1] == ':' && ('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+		return 2
+	}
+	if !p.isSep(path[0]) || !p.isSep(path[1]) {
+		return 0
+	}
+	//Device paths such as \\.\COM1 and \\?\C:
+	if len(path) >= 4 && (path[2] == '.' || path[2] == '?') && p.isSep(path[3]) {
+		n := 4
+		for n < len(path) && !p.isSep(path[n]) {
+			n++
+		}
+		return n
+	}
+	//UNC paths such as \\host\share
+	n := 2
+	for n < len(path) && !p.isSep(path[n]) {
+		n++
+	}
+	if n == 2 {
+		return 0
+	}
+	if n < len(path) {
+		n++
+		for n < len(path) && !p.isSep(path[n]) {
+			n++
+		}
+	}
+	return n
+}
+
+func (p lexicalPath) volumeName(path string) string {
+	return path[:p.volumeNameLen(path)]
+}
+
+func (p lexicalPath) fromSlash(path string) string {
+	if p.windows {
+		return strings.ReplaceAll(path, "/", "\\")
+	}
+	return path
+}
+
+func (p lexicalPath) clean(path string) string {
+	originalPath := path
+	volLen := p.volumeNameLen(path)
+	path = path[volLen:]
+	if path == "" {
+		if volLen > 1 && p.isSep(originalPath[0]) && p.isSep(originalPath[1]) {
+			return p.fromSlash(originalPath)
+		}
+		return originalPath + "."
+	}
+	rooted := p.isSep(path[0])
+
+	n := len(path)
+	out := make([]byte, 0, n)
+	r, dotdot := 0, 0
+	if rooted {
+		out = append(out, p.sep())
+		r, dotdot = 1, 1
+	}
+	for r < n {
+		switch {
+		case p.isSep(path[r]):
+			r++
+		case path[r] == '.' && (r+1 == n || p.isSep(path[r+1])):
+			r++
+		case path[r] == '.' && path[r+1] == '.' && (r+2 == n || p.isSep(path[r+2])):
+			r += 2
+			switch {
+			case len(out) > dotdot:
+				w := len(out) - 1
+				for w > dotdot && !p.isSep(out[w]) {
+					w--
+				}
+				out = out[:w]
+			case !rooted:
+				if len(out) > 0 {
+					out = append(out, p.sep())
+				}
+				out = append(out, '.', '.')
+				dotdot = len(out)
+			}
+		default:
+			if rooted && len(out) != 1 || !rooted && len(out) != 0 {
+				out = append(out, p.sep())
+			}
+			for ; r < n && !p.isSep(path[r]); r++ {
+				out = append(out, path[r])
+			}
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, '.')
+	}
+	return p.fromSlash(originalPath[:volLen] + string(out))
+}
+
+func (p lexicalPath) isUNC(path string) bool {
+	return p.volumeNameLen(path) > 2 && p.isSep(path[0])
+}
+
+func (p lexicalPath) join(elems ...string) string {
+	for i, elem := range elems {
+		if elem == "" {
+			continue
+		}
+		elems = elems[i:]
+		sep := string(p.sep())
+		if p.windows && len(elems[0]) == 2 && elems[0][1] == ':' {
+			//Joining "C:" and "a" results in "C:a", not "C:\a"
+			j := 1
+			for j < len(elems) && elems[j] == "" {
+				j++
+			}
+			return p.clean(elems[0] + strings.Join(elems[j:], sep))
+		}
+		joined := p.clean(strings.Join(elems, sep))
+		if !p.windows || !p.isUNC(joined) {
+			return joined
+		}
+		//Don't create a UNC path out of elements that aren't one
+		head := p.clean(elems[0])
+		if p.isUNC(head) {
+			return joined
+		}
+		tail := p.clean(strings.Join(elems[1:], sep))
+		if head[len(head)-1] == p.sep() {
+			return head + tail
+		}
+		return head + sep + tail
+	}
+	return ""
+}
+
+func (p lexicalPath) split(path string) (string, string) {
+	vol := p.volumeName(path)
+	i := len(path) - 1
+	for i >= len(vol) && !p.isSep(path[i]) {
+		i--
+	}
+	return path[:i+1], path[i+1:]
+}
+
+func (p lexicalPath) base(path string) string {
+	if path == "" {
+		return "."
+	}
+	for len(path) > 0 && p.isSep(path[len(path)-1]) {
+		path = path[:len(path)-1]
+	}
+	path = path[p.volumeNameLen(path):]
+	i := len(path) - 1
+	for i >= 0 && !p.isSep(path[i]) {
+		i--
+	}
+	if i >= 0 {
+		path = path[i+1:]
+	}
+	if path == "" {
+		return string(p.sep())
+	}
+	return path
+}
+
+func (p lexicalPath) dir(path string) string {
+	vol := p.volumeName(path)
+	i := len(path) - 1
+	for i >= len(vol) && !p.isSep(path[i]) {
+		i--
+	}
+	dir := p.clean(path[len(vol) : i+1])
+	if dir == "." && len(vol) > 2 {
+		return vol
+	}
+	return vol + dir
+}
+
+func (p lexicalPath) ext(path string) string {
+	for i := len(path) - 1; i >= 0 && !p.isSep(path[i]); i-- {
+		if path[i] == '.' {
+			return path[i:]
+		}
+	}
+	return ""
+}
+
+func (p lexicalPath) isAbs(path string) bool {
+	if !p.windows {
+		return strings.HasPrefix(path, "/")
+	}
+	l := p.volumeNameLen(path)
+	if l == 0 {
+		return false
+	}
+	if p.isSep(path[0]) && p.isSep(path[1]) {
+		return true
+	}
+	path = path[l:]
+	return path != "" && p.isSep(path[0])
+}
+
+func (p lexicalPath) rel(basePath, targetPath string) (string, error) {
+	baseVol := p.volumeName(basePath)
+	targetVol := p.volumeName(targetPath)
+	base := p.clean(basePath)
+	target := p.clean(targetPath)
+	if p.sameWord(target, base) {
+		return ".", nil
+	}
+	base = base[len(baseVol):]
+	target = target[len(targetVol):]
+	if base == "." {
+		base = ""
+	} else if base == "" && p.volumeNameLen(baseVol) > 2 {
+		base = string(p.sep())
+	}
+
+	sep := p.sep()
+	baseSlashed := len(base) > 0 && base[0] == sep
+	targetSlashed := len(target) > 0 && target[0] == sep
+	if baseSlashed != targetSlashed || !p.sameWord(baseVol, targetVol) {
+		return "", fmt.Errorf("Cannot make '%v' relative to '%v'.", targetPath, basePath)
+	}
+	bl, tl := len(base), len(target)
+	var b0, bi, t0, ti int
+	for {
+		for bi < bl && base[bi] != sep {
+			bi++
+		}
+		for ti < tl && target[ti] != sep {
+			ti++
+		}
+		if !p.sameWord(target[t0:ti], base[b0:bi]) {
+			break
+		}
+		if bi < bl {
+			bi++
+		}
+		if ti < tl {
+			ti++
+		}
+		b0 = bi
+		t0 = ti
+	}
+	if base[b0:bi] == ".." {
+		return "", fmt.Errorf("Cannot make '%v' relative to '%v'.", targetPath, basePath)
+	}
+	if b0 == bl {
+		return target[t0:], nil
+	}
+	var builder strings.Builder
+	builder.WriteString("..")
+	for i := strings.Count(base[b0:bl], string(sep)); i > 0; i-- {
+		builder.WriteByte(sep)
+		builder.WriteString("..")
+	}
+	if t0 != tl {
+		builder.WriteByte(sep)
+		builder.WriteString(target[t0:])
+	}
+	return p.clean(builder.String()), nil
+}
+
+func (i *Interpreter) definePathFuncs() {
+	className := "path"
+	pathClass := i.newPathClass(className, lexicalPath{windows: util.IsWindows()}, true)
+	pathClass.classProperties["posix"] = i.newPathClass(className+".posix", lexicalPath{windows: false}, !util.IsWindows())
+	pathClass.classProperties["windows"] = i.newPathClass(className+".windows", lexicalPath{windows: true}, util.IsWindows())
+	i.globals.Define(className, pathClass)
+}
+
+// Creates a class with methods that manipulate paths of the specified style,
+// where path.abs is only defined if the style is that of the current
+// operating system, since it depends on the current working directory
+func (i *Interpreter) newPathClass(className string, p lexicalPath, isNative bool) *LoxClass {
+	pathClass := NewLoxClass(className, nil, false)
+	pathFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native %v fn %v at %p>", className, name, &s)
+		}
+		pathClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to '%v.%v' must be a %v.", className, name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	stringFunc := func(name string, method func(string) any) {
+		pathFunc(name, 1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				return method(loxStr.str), nil
+			}
+			return argMustBeType(in.callToken, name, "string")
+		})
+	}
+
+	pathClass.classProperties["listSep"] = NewLoxStringQuote(p.listSep())
+	pathClass.classProperties["sep"] = NewLoxStringQuote(string(p.sep()))
+
+	if isNative {
+		pathFunc("abs", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if _, ok := args[0].(*LoxString); !ok {
+				return argMustBeType(in.callToken, "abs", "string")
+			}
+			if err := checkSandbox(in.callToken, "Getting the current working directory"); err != nil {
+				return nil, err
+			}
+			absPath, err := filepath.Abs(args[0].(*LoxString).str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(absPath), nil
+		})
+	}
+	stringFunc("basename", func(path string) any {
+		return NewLoxStringQuote(p.base(path))
+	})
+	stringFunc("clean", func(path string) any {
+		return NewLoxStringQuote(p.clean(path))
+	})
+	stringFunc("dirname", func(path string) any {
+		return NewLoxStringQuote(p.dir(path))
+	})
+	stringFunc("ext", func(path string) any {
+		return NewLoxStringQuote(p.ext(path))
+	})
+	stringFunc("isAbs", func(path string) any {
+		return p.isAbs(path)
+	})
+	pathFunc("join", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		elems := make([]string, 0, len(args))
+		for _, arg := range args {
+			loxStr, ok := arg.(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Arguments to '%v.join' must be strings.", className))
+			}
+			elems = append(elems, loxStr.str)
+		}
+		return NewLoxStringQuote(p.join(elems...)), nil
+	})
+	pathFunc("rel", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("First argument to '%v.rel' must be a string.", className))
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Second argument to '%v.rel' must be a string.", className))
+		}
+		relPath, err := p.rel(args[0].(*LoxString).str, args[1].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+		}
+		return NewLoxStringQuote(relPath), nil
+	})
+	stringFunc("split", func(path string) any {
+		dir, file := p.split(path)
+		return NewLoxList(list.List[any]{NewLoxStringQuote(dir), NewLoxStringQuote(file)})
+	})
+	stringFunc("splitExt", func(path string) any {
+		ext := p.ext(path)
+		return NewLoxList(list.List[any]{
+			NewLoxStringQuote(path[:len(path)-len(ext)]),
+			NewLoxStringQuote(ext),
+		})
+	})
+	stringFunc("volumeName", func(path string) any {
+		return NewLoxStringQuote(p.volumeName(path))
+	})
+
+	return pathClass
+}
@@ -0,0 +1,28 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package ast
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin opens the Go plugin at the specified path and registers the
+// native module defined by its exported LoxRegister function, which must
+// have the type func(*ast.Interpreter).
+func LoadPlugin(path string) error {
+	p, openErr := plugin.Open(path)
+	if openErr != nil {
+		return openErr
+	}
+	symbol, lookupErr := p.Lookup("LoxRegister")
+	if lookupErr != nil {
+		return fmt.Errorf("plugin '%v' does not export a LoxRegister function", path)
+	}
+	define, ok := symbol.(func(*Interpreter))
+	if !ok {
+		return fmt.Errorf("LoxRegister in plugin '%v' must have the type func(*ast.Interpreter)", path)
+	}
+	RegisterNativeModule(define)
+	return nil
+}
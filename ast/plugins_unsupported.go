@@ -0,0 +1,11 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package ast
+
+import "errors"
+
+// LoadPlugin returns an error, since Go plugins are not supported on this
+// platform or in builds without cgo.
+func LoadPlugin(path string) error {
+	return errors.New("plugins are not supported on this platform")
+}
@@ -0,0 +1,331 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlanLuu/lox/profile"
+	"github.com/AlanLuu/lox/token"
+)
+
+const profileInterval = time.Millisecond
+
+// The profiler that records which Lox functions are running, or nil if
+// profiling is disabled
+var activeProfiler atomic.Pointer[profiler]
+
+type profileFunction struct {
+	index int
+	name  string
+	line  int
+	calls atomic.Int64
+}
+
+// The Lox functions that an interpreter is currently running, starting with
+// the outermost one. Only the main interpreter's stack includes the top
+// level of the program.
+type profileStack struct {
+	mutex  sync.Mutex
+	frames []*profileFunction
+	isMain bool
+}
+
+func (s *profileStack) pop() {
+	s.mutex.Lock()
+	s.frames = s.frames[:len(s.frames)-1]
+	s.mutex.Unlock()
+}
+
+type profileSample struct {
+	stack      []*profileFunction
+	count      int64
+	nanos      int64
+	allocBytes int64
+}
+
+type profiler struct {
+	mutex      sync.Mutex
+	functions  map[*token.Token]*profileFunction
+	funcList   []*profileFunction
+	topLevel   *profileFunction
+	stacks     []*profileStack
+	samples    map[string]*profileSample
+	start      time.Time
+	stop       chan struct{}
+	done       chan struct{}
+	writer     io.Writer
+	outputPath string
+}
+
+// Starts sampling which Lox functions are running every millisecond. When
+// the profiler is stopped, a table of the time spent in each function is
+// written to the specified writer, and a profile that can be read by pprof
+// is written to the specified path if it isn't empty.
+func StartProfiler(writer io.Writer, outputPath string) {
+	p := &profiler{
+		functions:  make(map[*token.Token]*profileFunction),
+		samples:    make(map[string]*profileSample),
+		start:      time.Now(),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		writer:     writer,
+		outputPath: outputPath,
+	}
+	p.topLevel = p.newFunction("<top level>", 0)
+	activeProfiler.Store(p)
+	go p.run()
+}
+
+// Stops the profiler if it is running and writes its results
+func StopProfiler() error {
+	p := activeProfiler.Swap(nil)
+	if p == nil {
+		return nil
+	}
+	close(p.stop)
+	<-p.done
+	p.writeTable()
+	if p.outputPath != "" {
+		return p.writeProfile()
+	}
+	return nil
+}
+
+func (p *profiler) newFunction(name string, line int) *profileFunction {
+	function := &profileFunction{index: len(p.funcList), name: name, line: line}
+	p.funcList = append(p.funcList, function)
+	return function
+}
+
+func (p *profiler) newStack(isMain bool) *profileStack {
+	stack := &profileStack{isMain: isMain}
+	p.mutex.Lock()
+	p.stacks = append(p.stacks, stack)
+	p.mutex.Unlock()
+	return stack
+}
+
+// Records that the specified interpreter started calling the specified
+// function and returns the stack that the function was pushed onto
+func (p *profiler) enter(in *Interpreter, f *LoxFunction) *profileStack {
+	start := f.declaration.Start
+	p.mutex.Lock()
+	function, ok := p.functions[start]
+	if !ok {
+		name, line := f.name, 0
+		if name == "" {
+			name = "<anonymous>"
+		}
+		if start != nil {
+			line = start.Line
+		}
+		function = p.newFunction(name, line)
+		p.functions[start] = function
+	}
+	p.mutex.Unlock()
+	function.calls.Add(1)
+
+	if in.profileStack == nil {
+		in.profileStack = p.newStack(false)
+	}
+	stack := in.profileStack
+	stack.mutex.Lock()
+	stack.frames = append(stack.frames, function)
+	stack.mutex.Unlock()
+	return stack
+}
+
+func (p *profiler) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(profileInterval)
+	defer ticker.Stop()
+	allocs := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	readAllocs := func() int64 {
+		metrics.Read(allocs)
+		if allocs[0].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return int64(allocs[0].Value.Uint64())
+	}
+	last, lastAllocs := time.Now(), readAllocs()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			currentAllocs := readAllocs()
+			p.sample(now.Sub(last), currentAllocs-lastAllocs)
+			last, lastAllocs = now, currentAllocs
+		}
+	}
+}
+
+// Records the functions that every interpreter is running. Each running
+// interpreter is charged the time since the last sample, and the memory
+// allocated since then is split between them.
+func (p *profiler) sample(elapsed time.Duration, allocBytes int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stacks := [][]*profileFunction{}
+	hasMain := false
+	for _, stack := range p.stacks {
+		stack.mutex.Lock()
+		var frames []*profileFunction
+		if stack.isMain {
+			hasMain = true
+			frames = append([]*profileFunction{p.topLevel}, stack.frames...)
+		} else if len(stack.frames) > 0 {
+			frames = append([]*profileFunction{}, stack.frames...)
+		}
+		stack.mutex.Unlock()
+		if frames != nil {
+			stacks = append(stacks, frames)
+		}
+	}
+	if !hasMain {
+		stacks = append(stacks, []*profileFunction{p.topLevel})
+	}
+	for _, frames := range stacks {
+		var key strings.Builder
+		for _, function := range frames {
+			key.WriteString(strconv.Itoa(function.index))
+			key.WriteByte(',')
+		}
+		sample, ok := p.samples[key.String()]
+		if !ok {
+			sample = &profileSample{stack: frames}
+			p.samples[key.String()] = sample
+		}
+		sample.count++
+		sample.nanos += elapsed.Nanoseconds()
+		sample.allocBytes += allocBytes / int64(len(stacks))
+	}
+}
+
+func formatProfileBytes(numBytes int64) string {
+	switch {
+	case numBytes >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(numBytes)/(1<<30))
+	case numBytes >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(numBytes)/(1<<20))
+	case numBytes >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(numBytes)/(1<<10))
+	}
+	return fmt.Sprintf("%vB", numBytes)
+}
+
+// Writes a table of the number of calls, the time spent in each function
+// itself and including the functions that it called, and the memory
+// allocated by each function itself, sorted by the time spent in each
+// function itself
+func (p *profiler) writeTable() {
+	type row struct {
+		function   *profileFunction
+		selfNanos  int64
+		totalNanos int64
+		allocBytes int64
+	}
+	rows := make([]*row, len(p.funcList))
+	for index, function := range p.funcList {
+		rows[index] = &row{function: function}
+	}
+	var totalNanos, numSamples int64
+	for _, sample := range p.samples {
+		totalNanos += sample.nanos
+		numSamples += sample.count
+		leaf := rows[sample.stack[len(sample.stack)-1].index]
+		leaf.selfNanos += sample.nanos
+		leaf.allocBytes += sample.allocBytes
+		counted := make(map[int]bool, len(sample.stack))
+		for _, function := range sample.stack {
+			if !counted[function.index] {
+				counted[function.index] = true
+				rows[function.index].totalNanos += sample.nanos
+			}
+		}
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].selfNanos != rows[b].selfNanos {
+			return rows[a].selfNanos > rows[b].selfNanos
+		}
+		return rows[a].totalNanos > rows[b].totalNanos
+	})
+
+	percent := func(nanos int64) string {
+		if totalNanos == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(nanos)/float64(totalNanos)*100)
+	}
+	millis := func(nanos int64) string {
+		return fmt.Sprintf("%.2fms", float64(nanos)/float64(time.Millisecond))
+	}
+	fmt.Fprintf(p.writer, "Profiled %v with %v samples.\n",
+		time.Since(p.start).Round(time.Millisecond), numSamples)
+	fmt.Fprintf(p.writer, "%-30v %10v %12v %7v %12v %7v %10v\n",
+		"FUNCTION", "CALLS", "SELF", "SELF%", "TOTAL", "TOTAL%", "ALLOC")
+	for _, row := range rows {
+		function := row.function
+		calls := function.calls.Load()
+		if calls == 0 && row.totalNanos == 0 {
+			continue
+		}
+		name := function.name
+		if function.line > 0 {
+			name = fmt.Sprintf("%v (line %v)", name, function.line)
+		}
+		fmt.Fprintf(p.writer, "%-30v %10v %12v %7v %12v %7v %10v\n",
+			name, calls, millis(row.selfNanos), percent(row.selfNanos),
+			millis(row.totalNanos), percent(row.totalNanos),
+			formatProfileBytes(row.allocBytes))
+	}
+}
+
+func (p *profiler) writeProfile() error {
+	prof := &profile.Profile{
+		SampleTypes: []profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Start:      p.start,
+		Duration:   time.Since(p.start),
+		PeriodType: profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     profileInterval.Nanoseconds(),
+	}
+	for _, function := range p.funcList {
+		prof.Functions = append(prof.Functions, profile.Function{
+			Name: function.name,
+			Line: int64(function.line),
+		})
+	}
+	for _, sample := range p.samples {
+		stack := make([]int, len(sample.stack))
+		for index, function := range sample.stack {
+			stack[len(stack)-1-index] = function.index
+		}
+		prof.Samples = append(prof.Samples, profile.Sample{
+			Stack:  stack,
+			Values: []int64{sample.count, sample.allocBytes, sample.nanos},
+		})
+	}
+
+	file, err := os.Create(p.outputPath)
+	if err != nil {
+		return err
+	}
+	writeErr := prof.Write(file)
+	closeErr := file.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
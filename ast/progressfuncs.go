@@ -0,0 +1,265 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Returns the file that a progress bar or spinner writes to, which is
+// specified by either "stdout" or "stderr"
+func progressStream(value any) (*os.File, bool) {
+	if stream, ok := value.(*LoxString); ok {
+		switch stream.str {
+		case "stdout":
+			return os.Stdout, true
+		case "stderr":
+			return os.Stderr, true
+		}
+	}
+	return nil, false
+}
+
+// Sets the fields of options from the specified dictionary of progress
+// bar options
+func parseProgressOptions(callToken *token.Token, fnName string, optionsDict *LoxDict, options *progressOptions) error {
+	optionErr := func(key string, theType string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Option '%v' in '%v' must be %v.", key, fnName, theType))
+	}
+	return forEachOption(optionsDict, func(key string, value any) error {
+		switch key {
+		case "empty", "fill":
+			str, ok := value.(*LoxString)
+			if !ok || str.str == "" {
+				return optionErr(key, "a non-empty string")
+			}
+			if key == "empty" {
+				options.empty = str.str
+			} else {
+				options.fill = str.str
+			}
+		case "label", "unit":
+			str, ok := value.(*LoxString)
+			if !ok {
+				return optionErr(key, "a string")
+			}
+			if key == "label" {
+				options.label = str.str
+			} else {
+				options.unit = str.str
+			}
+		case "minInterval":
+			interval, ok := subprocessTimeout(value)
+			if !ok || interval < 0 {
+				return optionErr(key, "a non-negative number or duration")
+			}
+			options.minInterval = interval
+		case "stream":
+			file, ok := progressStream(value)
+			if !ok {
+				return optionErr(key, "either 'stdout' or 'stderr'")
+			}
+			options.file = file
+		case "width":
+			width, ok := value.(int64)
+			if !ok || width < 1 {
+				return optionErr(key, "a positive integer")
+			}
+			options.width = int(width)
+		default:
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Unknown option '%v' in '%v'.", key, fnName))
+		}
+		return nil
+	})
+}
+
+func (i *Interpreter) defineProgressFuncs() {
+	className := "progress"
+	progressClass := NewLoxClass(className, nil, false)
+	progressFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native progress fn %v at %p>", name, &s)
+		}
+		progressClass.classProperties[name] = s
+	}
+	//Returns the progress bar options in the optional dictionary argument
+	//at the specified index
+	getOptions := func(callToken *token.Token, fnName string, args list.List[any], index int) (progressOptions, error) {
+		options := defaultProgressOptions()
+		if len(args) <= index {
+			return options, nil
+		}
+		optionsDict, ok := args[index].(*LoxDict)
+		if !ok {
+			return options, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Options argument to '%v' must be a dictionary.", fnName))
+		}
+		err := parseProgressOptions(callToken, fnName, optionsDict, &options)
+		return options, err
+	}
+	newBar := func(total int64, options progressOptions) *LoxProgressBar {
+		bar := NewLoxProgressBar(total, options)
+		bar.mutex.Lock()
+		bar.draw(true)
+		bar.mutex.Unlock()
+		return bar
+	}
+
+	progressFunc("bar", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		total := int64(-1)
+		if argsLen > 0 {
+			switch arg := args[0].(type) {
+			case int64:
+				if arg < 0 {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+						"Total passed to 'progress.bar' cannot be negative.")
+				}
+				total = arg
+			case nil:
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'progress.bar' must be an integer or nil.")
+			}
+		}
+		options, err := getOptions(in.callToken, "progress.bar", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return newBar(total, options), nil
+	})
+	progressFunc("iter", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		iterable, ok := args[0].(interfaces.Iterable)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Type '%v' is not iterable.", getType(args[0])))
+		}
+		options, err := getOptions(in.callToken, "progress.iter", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		total := int64(-1)
+		if length, ok := args[0].(interfaces.Length); ok {
+			total = length.Length()
+		}
+		return NewLoxIterator(&progressIterator{
+			iterator: iterable.Iterator(),
+			bar:      newBar(total, options),
+		}), nil
+	})
+	progressFunc("spinner", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		message := ""
+		if argsLen > 0 {
+			messageStr, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'progress.spinner' must be a string.")
+			}
+			message = messageStr.str
+		}
+		frames := spinnerStyles["dots"]
+		interval := 80 * time.Millisecond
+		file := os.Stderr
+		if argsLen == 2 {
+			optionsDict, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'progress.spinner' must be a dictionary.")
+			}
+			optionErr := func(key string, theType string) error {
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option '%v' in '%v' must be %v.", key, "progress.spinner", theType))
+			}
+			err := forEachOption(optionsDict, func(key string, value any) error {
+				switch key {
+				case "frames":
+					framesList, ok := value.(*LoxList)
+					if !ok || len(framesList.elements) == 0 {
+						return optionErr(key, "a non-empty list of strings")
+					}
+					frames = make([]string, 0, len(framesList.elements))
+					for _, element := range framesList.elements {
+						frame, ok := element.(*LoxString)
+						if !ok {
+							return optionErr(key, "a non-empty list of strings")
+						}
+						frames = append(frames, frame.str)
+					}
+				case "interval":
+					duration, ok := subprocessTimeout(value)
+					if !ok || duration <= 0 {
+						return optionErr(key, "a positive number or duration")
+					}
+					interval = duration
+				case "stream":
+					streamFile, ok := progressStream(value)
+					if !ok {
+						return optionErr(key, "either 'stdout' or 'stderr'")
+					}
+					file = streamFile
+				case "style":
+					style, ok := value.(*LoxString)
+					if ok {
+						frames, ok = spinnerStyles[style.str]
+					}
+					if !ok {
+						styles := make([]string, 0, len(spinnerStyles))
+						for style := range spinnerStyles {
+							styles = append(styles, "'"+style+"'")
+						}
+						sort.Strings(styles)
+						return optionErr(key, "one of "+strings.Join(styles, ", "))
+					}
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown option '%v' in '%v'.", key, "progress.spinner"))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewLoxSpinner(message, frames, interval, file), nil
+	})
+	progressFunc("styles", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		styles := make([]string, 0, len(spinnerStyles))
+		for style := range spinnerStyles {
+			styles = append(styles, style)
+		}
+		sort.Strings(styles)
+		stylesList := list.NewListCap[any](int64(len(styles)))
+		for _, style := range styles {
+			stylesList.Add(NewLoxStringQuote(style))
+		}
+		return NewLoxList(stylesList), nil
+	})
+
+	i.globals.Define(className, progressClass)
+}
@@ -0,0 +1,24 @@
+//go:build !js
+
+package ast
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/creack/pty"
+)
+
+// Starts the specified command attached to a new pseudo-terminal with the
+// specified size, returning the controlling side of the pseudo-terminal
+func startPty(cmd *exec.Cmd, rows uint16, cols uint16) (*os.File, error) {
+	return pty.StartWithSize(cmd, &pty.Winsize{Rows: rows, Cols: cols})
+}
+
+func getPtySize(terminal *os.File) (int, int, error) {
+	return pty.Getsize(terminal)
+}
+
+func setPtySize(terminal *os.File, rows uint16, cols uint16) error {
+	return pty.Setsize(terminal, &pty.Winsize{Rows: rows, Cols: cols})
+}
@@ -0,0 +1,21 @@
+package ast
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errPtyUnsupported = errors.New("Pseudo-terminals are unsupported on this platform.")
+
+func startPty(cmd *exec.Cmd, rows uint16, cols uint16) (*os.File, error) {
+	return nil, errPtyUnsupported
+}
+
+func getPtySize(terminal *os.File) (int, int, error) {
+	return 0, 0, errPtyUnsupported
+}
+
+func setPtySize(terminal *os.File, rows uint16, cols uint16) error {
+	return errPtyUnsupported
+}
@@ -2,7 +2,9 @@ package ast
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"unicode/utf8"
 
 	"github.com/AlanLuu/lox/interfaces"
@@ -19,6 +21,95 @@ func (r LoxRand) String() string {
 	return "private field"
 }
 
+func (r LoxRand) randFloat() float64 {
+	if r.rand != nil {
+		return r.rand.Float64()
+	}
+	return rand.Float64()
+}
+
+func (r LoxRand) randNorm() float64 {
+	if r.rand != nil {
+		return r.rand.NormFloat64()
+	}
+	return rand.NormFloat64()
+}
+
+func (r LoxRand) randExp() float64 {
+	if r.rand != nil {
+		return r.rand.ExpFloat64()
+	}
+	return rand.ExpFloat64()
+}
+
+func (r LoxRand) randShuffle(n int, swap func(i int, j int)) {
+	if r.rand != nil {
+		r.rand.Shuffle(n, swap)
+	} else {
+		rand.Shuffle(n, swap)
+	}
+}
+
+// Returns the number of successes in n independent trials that each succeed
+// with probability p, using the same algorithm as Python's
+// random.binomialvariate
+func (r LoxRand) binomial(n int64, p float64) int64 {
+	if n == 0 || p == 0 {
+		return 0
+	}
+	if p == 1 {
+		return n
+	}
+	if p > 0.5 {
+		return n - r.binomial(n, 1-p)
+	}
+	fn := float64(n)
+	if fn*p < 10 {
+		//Count the trials between successes, which are geometrically distributed
+		c := math.Log(1 - p)
+		var x int64
+		y := 0.0
+		for {
+			y += math.Floor(math.Log(r.randFloat())/c) + 1
+			if y > fn {
+				return x
+			}
+			x++
+		}
+	}
+	//BTRS algorithm from Hormann's "The generation of binomial random variates"
+	q := 1 - p
+	spq := math.Sqrt(fn * p * q)
+	b := 1.15 + 2.53*spq
+	a := -0.0873 + 0.0248*b + 0.01*p
+	c := fn*p + 0.5
+	vr := 0.92 - 4.2/b
+	alpha := (2.83 + 5.1/b) * spq
+	lpq := math.Log(p / q)
+	m := math.Floor((fn + 1) * p)
+	lgammaMFirst, _ := math.Lgamma(m + 1)
+	lgammaMSecond, _ := math.Lgamma(fn - m + 1)
+	h := lgammaMFirst + lgammaMSecond
+	for {
+		u := r.randFloat() - 0.5
+		v := r.randFloat()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + c)
+		if k < 0 || k > fn {
+			continue
+		}
+		if us >= 0.07 && v <= vr {
+			return int64(k)
+		}
+		v = math.Log(v * alpha / (a/(us*us) + b))
+		lgammaKFirst, _ := math.Lgamma(k + 1)
+		lgammaKSecond, _ := math.Lgamma(fn - k + 1)
+		if v <= h-lgammaKFirst-lgammaKSecond+(k-m)*lpq {
+			return int64(k)
+		}
+	}
+}
+
 func (i *Interpreter) defineRandFuncs() {
 	className := "Rand"
 	randClass := NewLoxClass(className, nil, true)
@@ -95,6 +186,55 @@ func (i *Interpreter) defineRandFuncs() {
 		}
 	}
 
+	sequenceElement := func(arg any, index int) any {
+		switch arg := arg.(type) {
+		case *LoxBuffer:
+			return arg.elements[index]
+		case *LoxList:
+			return arg.elements[index]
+		case *LoxRange:
+			return arg.get(int64(index))
+		case *LoxString:
+			return NewLoxStringQuote(string([]rune(arg.str)[index]))
+		default:
+			return loxerror.Error(
+				fmt.Sprintf("Cannot get random element from type '%v'.", getType(arg)),
+			)
+		}
+	}
+	//Returns the running totals of the specified list of weights
+	cumulativeWeights := func(callToken *token.Token, weights *LoxList, sequenceLen int64) ([]float64, error) {
+		if int64(len(weights.elements)) != sequenceLen {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				"Number of weights in 'Rand().choices' must be equal to the length of the sequence.")
+		}
+		cumWeights := make([]float64, len(weights.elements))
+		total := 0.0
+		for index, element := range weights.elements {
+			var weight float64
+			switch element := element.(type) {
+			case int64:
+				weight = float64(element)
+			case float64:
+				weight = element
+			default:
+				return nil, loxerror.RuntimeError(callToken,
+					"Weights in 'Rand().choices' must be integers or floats.")
+			}
+			if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					"Weights in 'Rand().choices' must be finite and non-negative.")
+			}
+			total += weight
+			cumWeights[index] = total
+		}
+		if total <= 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				"Total of weights in 'Rand().choices' must be greater than 0.")
+		}
+		return cumWeights, nil
+	}
+
 	randStr := "randObj"
 	randInstanceFunc("init", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		argsLen := len(args) - 1
@@ -116,6 +256,38 @@ func (i *Interpreter) defineRandFuncs() {
 	})
 
 	randFieldTypeErrMsg := "'Rand().rand' field is not the correct type."
+	randInstanceFunc("binomial", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		instance := args[0].(*LoxInstance)
+		switch randStruct := instance.fields[randStr].(type) {
+		case LoxRand:
+			n, ok := args[1].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'Rand().binomial' must be an integer.")
+			}
+			if n < 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'Rand().binomial' cannot be negative.")
+			}
+			var p float64
+			switch arg := args[2].(type) {
+			case int64:
+				p = float64(arg)
+			case float64:
+				p = arg
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'Rand().binomial' must be an integer or float.")
+			}
+			if !(p >= 0 && p <= 1) {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'Rand().binomial' must be between 0 and 1.")
+			}
+			return randStruct.binomial(n, p), nil
+		default:
+			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
+		}
+	})
 	randInstanceFunc("choice", 1, func(in *Interpreter, args list.List[any]) (any, error) {
 		instance := args[0].(*LoxInstance)
 		switch randStruct := instance.fields[randStr].(type) {
@@ -129,10 +301,15 @@ func (i *Interpreter) defineRandFuncs() {
 			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
 		}
 	})
-	randInstanceFunc("choices", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+	randInstanceFunc("choices", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		instance := args[0].(*LoxInstance)
 		switch randStruct := instance.fields[randStr].(type) {
 		case LoxRand:
+			argsLen := len(args) - 1
+			if argsLen != 2 && argsLen != 3 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+			}
 			if _, ok := args[2].(int64); !ok {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Second argument to 'Rand().choices' must be an integer.")
@@ -143,6 +320,39 @@ func (i *Interpreter) defineRandFuncs() {
 					"Second argument to 'Rand().choices' cannot be negative.")
 			}
 			arg := args[1]
+			if argsLen == 3 {
+				weights, ok := args[3].(*LoxList)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Third argument to 'Rand().choices' must be a list.")
+				}
+				sequence, ok := arg.(interfaces.Length)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Cannot get random element from type '%v'.", getType(arg)))
+				}
+				cumWeights, err := cumulativeWeights(in.callToken, weights, sequence.Length())
+				if err != nil {
+					return nil, err
+				}
+				total := cumWeights[len(cumWeights)-1]
+				choices := list.NewListCap[any](numChoices)
+				for i := int64(0); i < numChoices; i++ {
+					//Find the first running total that is greater than the random value
+					target := randStruct.randFloat() * total
+					index := sort.Search(len(cumWeights), func(j int) bool {
+						return cumWeights[j] > target
+					})
+					index = min(index, len(cumWeights)-1)
+					element := sequenceElement(arg, index)
+					if err, ok := element.(error); ok {
+						choices.Clear()
+						return nil, loxerror.RuntimeError(in.callToken, err.Error())
+					}
+					choices.Add(element)
+				}
+				return NewLoxList(choices), nil
+			}
 			choices := list.NewListCap[any](numChoices)
 			for i := int64(0); i < numChoices; i++ {
 				element, err := randElement(randStruct, arg)
@@ -157,6 +367,77 @@ func (i *Interpreter) defineRandFuncs() {
 			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
 		}
 	})
+	randInstanceFunc("exponential", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		instance := args[0].(*LoxInstance)
+		switch randStruct := instance.fields[randStr].(type) {
+		case LoxRand:
+			argsLen := len(args) - 1
+			switch argsLen {
+			case 0:
+				return randStruct.randExp(), nil
+			case 1:
+				var rate float64
+				switch arg := args[1].(type) {
+				case int64:
+					rate = float64(arg)
+				case float64:
+					rate = arg
+				default:
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Argument to 'Rand().exponential' must be an integer or float.")
+				}
+				if !(rate > 0) {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Argument to 'Rand().exponential' must be greater than 0.")
+				}
+				return randStruct.randExp() / rate, nil
+			default:
+				return nil, loxerror.RuntimeError(in.callToken, fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
+		}
+	})
+	randInstanceFunc("gauss", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		instance := args[0].(*LoxInstance)
+		switch randStruct := instance.fields[randStr].(type) {
+		case LoxRand:
+			argsLen := len(args) - 1
+			switch argsLen {
+			case 0:
+				return randStruct.randNorm(), nil
+			case 2:
+				var mean, stddev float64
+				switch arg := args[1].(type) {
+				case int64:
+					mean = float64(arg)
+				case float64:
+					mean = arg
+				default:
+					return nil, loxerror.RuntimeError(in.callToken,
+						"First argument to 'Rand().gauss' must be an integer or float.")
+				}
+				switch arg := args[2].(type) {
+				case int64:
+					stddev = float64(arg)
+				case float64:
+					stddev = arg
+				default:
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Second argument to 'Rand().gauss' must be an integer or float.")
+				}
+				if stddev < 0 {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Second argument to 'Rand().gauss' cannot be negative.")
+				}
+				return randStruct.randNorm()*stddev + mean, nil
+			default:
+				return nil, loxerror.RuntimeError(in.callToken, fmt.Sprintf("Expected 0 or 2 arguments but got %v.", argsLen))
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
+		}
+	})
 	randInstanceFunc("perm", -1, func(in *Interpreter, args list.List[any]) (any, error) {
 		instance := args[0].(*LoxInstance)
 		switch randStruct := instance.fields[randStr].(type) {
@@ -485,22 +766,6 @@ func (i *Interpreter) defineRandFuncs() {
 				return nil, loxerror.RuntimeError(in.callToken,
 					"Second argument to 'Rand().sample' cannot be greater than the first argument's length.")
 			}
-			getIndex := func(index int) any {
-				switch arg := arg.(type) {
-				case *LoxBuffer:
-					return arg.elements[index]
-				case *LoxList:
-					return arg.elements[index]
-				case *LoxRange:
-					return arg.get(int64(index))
-				case *LoxString:
-					return NewLoxStringQuote(string([]rune(arg.str)[index]))
-				default:
-					return loxerror.Error(
-						fmt.Sprintf("Cannot get random element from type '%v'.", getType(arg)),
-					)
-				}
-			}
 			samples := list.NewListCap[any](numSamples)
 			var randIndexes []int
 			if randStruct.rand != nil {
@@ -509,7 +774,7 @@ func (i *Interpreter) defineRandFuncs() {
 				randIndexes = rand.Perm(int(argLen))
 			}
 			for i := int64(0); i < numSamples; i++ {
-				element := getIndex(randIndexes[i])
+				element := sequenceElement(arg, randIndexes[i])
 				if i == 0 {
 					switch element := element.(type) {
 					case error:
@@ -524,6 +789,35 @@ func (i *Interpreter) defineRandFuncs() {
 			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
 		}
 	})
+	randInstanceFunc("seed", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		instance := args[0].(*LoxInstance)
+		switch instance.fields[randStr].(type) {
+		case LoxRand:
+			if seed, ok := args[1].(int64); ok {
+				instance.fields[randStr] = LoxRand{rand.New(rand.NewSource(seed))}
+				return nil, nil
+			}
+			return argMustBeTypeAn(in.callToken, "seed", "integer")
+		default:
+			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
+		}
+	})
+	randInstanceFunc("shuffle", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		instance := args[0].(*LoxInstance)
+		switch randStruct := instance.fields[randStr].(type) {
+		case LoxRand:
+			if loxList, ok := args[1].(*LoxList); ok {
+				randStruct.randShuffle(len(loxList.elements), func(a int, b int) {
+					loxList.elements[a], loxList.elements[b] = loxList.elements[b], loxList.elements[a]
+				})
+				return nil, nil
+			}
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'Rand().shuffle' must be a list.")
+		default:
+			return nil, loxerror.RuntimeError(in.callToken, randFieldTypeErrMsg)
+		}
+	})
 
 	i.globals.Define(className, randClass)
 }
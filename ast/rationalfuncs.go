@@ -0,0 +1,88 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineRationalFuncs() {
+	className := "rational"
+	rationalClass := NewLoxClass(className, nil, false)
+	rationalFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native rational fn %v at %p>", name, &s)
+		}
+		rationalClass.classProperties[name] = s
+	}
+	//Converts the specified value to a rational exactly
+	toRat := func(callToken *token.Token, value any) (*big.Rat, error) {
+		switch value := value.(type) {
+		case int64, *big.Int, *LoxRational, *LoxDecimal:
+			rat, _ := exactRat(value)
+			return new(big.Rat).Set(rat), nil
+		case float64:
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					fmt.Sprintf("Cannot convert %v to rational.", getResult(value, value, true)))
+			}
+			return new(big.Rat).SetFloat64(value), nil
+		case *big.Float:
+			if value.IsInf() {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					"Cannot convert infinite bigfloat to rational.")
+			}
+			rat, _ := value.Rat(nil)
+			return rat, nil
+		case *LoxString:
+			rat, ok := new(big.Rat).SetString(strings.TrimSpace(value.str))
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+					fmt.Sprintf("Failed to convert '%v' to rational.", value.str))
+			}
+			return rat, nil
+		}
+		return nil, loxerror.RuntimeError(callToken,
+			"Argument to 'rational.new' must be an integer, bigint, float, bigfloat, rational, decimal, or string.")
+	}
+
+	rationalFunc("new", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		switch argsLen {
+		case 1:
+			rat, err := toRat(in.callToken, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return NewLoxRational(rat), nil
+		case 2:
+			numerator, ok := exactRat(args[0])
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'rational.new' must be an integer, bigint, rational, or decimal.")
+			}
+			denominator, ok := exactRat(args[1])
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'rational.new' must be an integer, bigint, rational, or decimal.")
+			}
+			if denominator.Sign() == 0 {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ZeroDivisionError, in.callToken,
+					"Rational denominator cannot be 0.")
+			}
+			return NewLoxRational(new(big.Rat).Quo(numerator, denominator)), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+	})
+
+	i.globals.Define(className, rationalClass)
+}
@@ -10,35 +10,63 @@ import (
 	"github.com/AlanLuu/lox/token"
 )
 
+// A variable declared in a scope along with the slot that it will occupy in
+// the environment created for that scope at runtime
+type scopeVariable struct {
+	defined bool
+	slot    int
+
+	//Only used when linting
+	name        *token.Token
+	used        bool
+	checkUnused bool
+	callable    *lintCallable
+}
+
 type Resolver struct {
 	Interpreter     *Interpreter
-	Scopes          list.List[map[string]bool]
+	Scopes          list.List[map[string]*scopeVariable]
 	CurrentFunction functiontype.FunctionType
 	CurrentClass    classtype.ClassType
+
+	//Only used when linting
+	lint     bool
+	warnings []lintWarning
+	globals  map[string]*scopeVariable
+	assigned map[string]bool
+	refs     []*token.Token
+	calls    []lintCallSite
+	imports  bool
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
 	return &Resolver{
 		Interpreter:     interpreter,
-		Scopes:          list.NewList[map[string]bool](),
+		Scopes:          list.NewList[map[string]*scopeVariable](),
 		CurrentFunction: functiontype.NONE,
 		CurrentClass:    classtype.NONE,
 	}
 }
 
 func (r *Resolver) beginScope() {
-	r.Scopes.Add(make(map[string]bool))
+	r.Scopes.Add(make(map[string]*scopeVariable))
 }
 
 func (r *Resolver) declare(name *token.Token) error {
 	if r.Scopes.IsEmpty() {
+		if r.lint {
+			r.declareGlobal(name)
+		}
 		return nil
 	}
 	scope := r.Scopes.Peek()
 	if _, ok := scope[name.Lexeme]; ok {
 		return loxerror.RuntimeError(name, "Already a variable with this name in this scope.")
 	}
-	scope[name.Lexeme] = false
+	if r.lint {
+		r.checkShadowed(name)
+	}
+	scope[name.Lexeme] = &scopeVariable{defined: false, slot: len(scope), name: name}
 	return nil
 }
 
@@ -46,20 +74,34 @@ func (r *Resolver) define(name *token.Token) {
 	if r.Scopes.IsEmpty() {
 		return
 	}
+	r.defineStr(name.Lexeme)
+}
+
+func (r *Resolver) defineStr(name string) {
 	scope := r.Scopes.Peek()
-	scope[name.Lexeme] = true
+	if variable, ok := scope[name]; ok {
+		variable.defined = true
+	} else {
+		scope[name] = &scopeVariable{defined: true, slot: len(scope)}
+	}
 }
 
 func (r *Resolver) endScope() {
-	r.Scopes.Pop()
+	scope := r.Scopes.Pop()
+	if r.lint {
+		r.checkUnused(scope)
+	}
 }
 
 func (r *Resolver) Resolve(statements list.List[Stmt]) error {
-	for _, stmt := range statements {
+	for index, stmt := range statements {
 		resolveErr := r.resolveStmt(stmt)
 		if resolveErr != nil {
 			return resolveErr
 		}
+		if r.lint && index < len(statements)-1 {
+			r.checkUnreachable(stmt)
+		}
 	}
 	return nil
 }
@@ -90,6 +132,8 @@ func (r *Resolver) resolveExpr(expr Expr) error {
 		return nil
 	case Logical:
 		return r.visitLogicalExpr(expr)
+	case NamedArgument:
+		return r.visitNamedArgumentExpr(expr)
 	case Set:
 		return r.visitSetExpr(expr)
 	case SetObject:
@@ -171,24 +215,40 @@ func (r *Resolver) resolveFunction(fnExpr FunctionExpr, fnType functiontype.Func
 
 	r.beginScope()
 	defer r.endScope()
-	for _, param := range fnExpr.Params {
+	for index, param := range fnExpr.Params {
 		declareErr := r.declare(param)
 		if declareErr != nil {
 			return declareErr
 		}
+		if index < len(fnExpr.Defaults) && fnExpr.Defaults[index] != nil {
+			resolveErr := r.resolveExpr(fnExpr.Defaults[index])
+			if resolveErr != nil {
+				return resolveErr
+			}
+		}
 		r.define(param)
 	}
 	return r.Resolve(fnExpr.Body)
 }
 
-func (r *Resolver) resolveLocal(expr Expr, name *token.Token) {
+// Resolves the specified variable name and returns the local variable that
+// it refers to, or nil if it refers to a global variable
+func (r *Resolver) resolveLocal(resolution *Resolution, name *token.Token) *scopeVariable {
 	for i := len(r.Scopes) - 1; i >= 0; i-- {
 		scope := r.Scopes[i]
-		if _, ok := scope[name.Lexeme]; ok {
-			r.Interpreter.Resolve(expr, len(r.Scopes)-1-i)
-			return
+		if variable, ok := scope[name.Lexeme]; ok {
+			if resolution != nil {
+				resolution.Depth = len(r.Scopes) - 1 - i
+				resolution.Slot = variable.slot
+				resolution.IsLocal = true
+			}
+			return variable
 		}
 	}
+	if resolution != nil {
+		resolution.IsLocal = false
+	}
+	return nil
 }
 
 func (r *Resolver) visitAssertStmt(stmt Assert) error {
@@ -200,7 +260,10 @@ func (r *Resolver) visitAssignExpr(expr Assign) error {
 	if resolveErr != nil {
 		return resolveErr
 	}
-	r.resolveLocal(expr, expr.Name)
+	variable := r.resolveLocal(expr.Resolution, expr.Name)
+	if r.lint {
+		r.lintAssign(variable, expr.Name)
+	}
 	return nil
 }
 
@@ -229,6 +292,9 @@ func (r *Resolver) visitCallExpr(expr Call) error {
 			return resolveErr
 		}
 	}
+	if r.lint {
+		r.lintCall(expr)
+	}
 	return nil
 }
 
@@ -253,6 +319,9 @@ func (r *Resolver) visitClassStmt(stmt Class) error {
 	if declareErr != nil {
 		return declareErr
 	}
+	if r.lint {
+		r.declareCallable(stmt.Name, classCallable(stmt))
+	}
 
 	r.define(stmt.Name)
 	if stmt.SuperClass != nil {
@@ -265,10 +334,10 @@ func (r *Resolver) visitClassStmt(stmt Class) error {
 			return resolveErr
 		}
 		r.beginScope()
-		r.Scopes.Peek()["super"] = true
+		r.defineStr("super")
 	}
 	r.beginScope()
-	r.Scopes.Peek()["this"] = true
+	r.defineStr("this")
 	for _, method := range stmt.Methods {
 		declaration := functiontype.METHOD
 		if method.Name.Lexeme == "init" {
@@ -318,6 +387,9 @@ func (r *Resolver) visitEnumStmt(stmt Enum) error {
 	if declareErr != nil {
 		return declareErr
 	}
+	if r.lint {
+		r.declareCallable(stmt.Name, nil)
+	}
 	r.define(stmt.Name)
 	return nil
 }
@@ -359,7 +431,7 @@ func (r *Resolver) visitForEachStmt(stmt ForEach) error {
 	}
 	r.define(stmt.VariableName)
 
-	visitVariableNameErr := r.visitVariableExpr(Variable{stmt.VariableName})
+	visitVariableNameErr := r.visitVariableExpr(Variable{Name: stmt.VariableName})
 	if visitVariableNameErr != nil {
 		return visitVariableNameErr
 	}
@@ -381,6 +453,9 @@ func (r *Resolver) visitFunctionStmt(stmt Function) error {
 	if declareErr != nil {
 		return declareErr
 	}
+	if r.lint {
+		r.declareCallable(stmt.Name, functionCallable(stmt.Function))
+	}
 	r.define(stmt.Name)
 	return r.resolveFunction(stmt.Function, functiontype.FUNCTION)
 }
@@ -394,6 +469,10 @@ func (r *Resolver) visitGroupingExpr(expr Grouping) error {
 }
 
 func (r *Resolver) visitImportStmt(stmt Import) error {
+	if r.lint {
+		//Imports without a namespace can define any global variable
+		r.imports = r.imports || stmt.ImportNamespace == ""
+	}
 	return r.resolveExpr(stmt.ImportFile)
 }
 
@@ -449,6 +528,10 @@ func (r *Resolver) visitLogicalExpr(expr Logical) error {
 	return r.resolveExpr(expr.Right)
 }
 
+func (r *Resolver) visitNamedArgumentExpr(expr NamedArgument) error {
+	return r.resolveExpr(expr.Value)
+}
+
 func (r *Resolver) visitPrintStmt(stmt Print) error {
 	return r.resolveExpr(stmt.Expression)
 }
@@ -492,7 +575,7 @@ func (r *Resolver) visitSuperExpr(expr Super) error {
 	} else if r.CurrentClass != classtype.SUBCLASS {
 		return loxerror.RuntimeError(expr.Keyword, "Can't use 'super' in a class with no superclass.")
 	}
-	r.resolveLocal(expr, expr.Keyword)
+	r.resolveLocal(expr.Resolution, expr.Keyword)
 	return nil
 }
 
@@ -516,7 +599,7 @@ func (r *Resolver) visitThisExpr(expr This) error {
 	if r.CurrentClass == classtype.NONE {
 		return loxerror.RuntimeError(expr.Keyword, "Can't use 'this' outside of a class.")
 	}
-	r.resolveLocal(expr, expr.Keyword)
+	r.resolveLocal(expr.Resolution, expr.Keyword)
 	return nil
 }
 
@@ -526,20 +609,26 @@ func (r *Resolver) visitTryCatchFinallyStmt(stmt TryCatchFinally) error {
 		return resolveErr
 	}
 
-	if stmt.CatchBlock != nil {
+	for _, catchClause := range stmt.CatchClauses {
+		if catchClause.ErrorClass != nil {
+			resolveErr = r.visitVariableExpr(*catchClause.ErrorClass)
+			if resolveErr != nil {
+				return resolveErr
+			}
+		}
 		r.beginScope()
-		if stmt.CatchName != nil {
-			declareErr := r.declare(stmt.CatchName)
+		if catchClause.Name != nil {
+			declareErr := r.declare(catchClause.Name)
 			if declareErr != nil {
 				return declareErr
 			}
-			r.define(stmt.CatchName)
-			visitCatchNameErr := r.visitVariableExpr(Variable{stmt.CatchName})
+			r.define(catchClause.Name)
+			visitCatchNameErr := r.visitVariableExpr(Variable{Name: catchClause.Name})
 			if visitCatchNameErr != nil {
 				return visitCatchNameErr
 			}
 		}
-		resolveErr = r.Resolve(stmt.CatchBlock.(Block).Statements)
+		resolveErr = r.Resolve(catchClause.Block.(Block).Statements)
 		if resolveErr != nil {
 			return resolveErr
 		}
@@ -570,6 +659,13 @@ func (r *Resolver) visitVarStmt(stmt Var) error {
 			return resolveErr
 		}
 	}
+	if r.lint {
+		var callable *lintCallable
+		if function, ok := stmt.Initializer.(FunctionExpr); ok {
+			callable = functionCallable(function)
+		}
+		r.declareCallable(stmt.Name, callable)
+	}
 	r.define(stmt.Name)
 	return nil
 }
@@ -577,12 +673,19 @@ func (r *Resolver) visitVarStmt(stmt Var) error {
 func (r *Resolver) visitVariableExpr(expr Variable) error {
 	if !r.Scopes.IsEmpty() {
 		scopes := r.Scopes.Peek()
-		value, ok := scopes[expr.Name.Lexeme]
-		if ok && !value {
+		variable, ok := scopes[expr.Name.Lexeme]
+		if ok && !variable.defined {
 			return loxerror.RuntimeError(expr.Name, "Can't read local variable in its own initializer.")
 		}
 	}
-	r.resolveLocal(expr, expr.Name)
+	variable := r.resolveLocal(expr.Resolution, expr.Name)
+	if r.lint {
+		if variable != nil {
+			variable.used = true
+		} else {
+			r.refs = append(r.refs, expr.Name)
+		}
+	}
 	return nil
 }
 
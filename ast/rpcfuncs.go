@@ -0,0 +1,314 @@
+package ast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+type rpcServer struct {
+	object  any
+	stopped bool
+}
+
+var currentRPCServer *rpcServer
+
+func (s *rpcServer) handle(in *Interpreter, line []byte) rpcResponse {
+	var request rpcRequest
+	if err := json.Unmarshal(line, &request); err != nil {
+		return rpcResponse{ID: -1, Error: "Malformed RPC request: " + err.Error()}
+	}
+	response := rpcResponse{ID: request.ID, Result: rpcValue{Kind: "nil"}}
+	errResponse := func(err error) rpcResponse {
+		msg := err.Error()
+		if index := strings.LastIndex(msg, "\n"); index > 0 {
+			msg = msg[:index]
+		}
+		response.Error = msg
+		return response
+	}
+
+	var method any
+	switch object := s.object.(type) {
+	case *LoxDict:
+		value, ok := object.getValueByKey(NewLoxStringQuote(request.Method))
+		if !ok {
+			return errResponse(fmt.Errorf("Unknown RPC method '%v'.", request.Method))
+		}
+		method = value
+	case LoxObject:
+		nameToken := token.NewToken(token.IDENTIFIER, request.Method, nil, in.callToken.Line, 0)
+		value, err := object.Get(nameToken)
+		if err != nil {
+			return errResponse(fmt.Errorf("Unknown RPC method '%v'.", request.Method))
+		}
+		method = value
+	}
+	callable, ok := method.(LoxCallable)
+	if !ok {
+		return errResponse(fmt.Errorf("RPC property '%v' is not a method.", request.Method))
+	}
+
+	args := list.NewListCap[any](int64(len(request.Args)))
+	for _, arg := range request.Args {
+		value, err := rpcToLoxValue(arg)
+		if err != nil {
+			return errResponse(err)
+		}
+		args.Add(value)
+	}
+	arity := callable.arity()
+	if arity >= 0 && len(args) != arity {
+		return errResponse(fmt.Errorf("Expected %v arguments but got %v.", arity, len(args)))
+	}
+	if builtin, ok := callable.(LoxBuiltInProtoCallable); ok {
+		args.AddAt(0, builtin.instance)
+	}
+
+	result, err := callable.call(in, args)
+	if resultReturn, ok := result.(Return); ok {
+		result = resultReturn.FinalValue
+	} else if err != nil {
+		return errResponse(err)
+	}
+	value, err := loxToRPCValue(result)
+	if err != nil {
+		return errResponse(err)
+	}
+	response.Result = value
+	return response
+}
+
+func (s *rpcServer) run(fn func() error) error {
+	previous := currentRPCServer
+	currentRPCServer = s
+	defer func() {
+		currentRPCServer = previous
+	}()
+	return fn()
+}
+
+func rpcCanServe(object any) bool {
+	switch object.(type) {
+	case *LoxDict, *LoxInstance, *LoxClass:
+		return true
+	}
+	return false
+}
+
+func (i *Interpreter) defineRPCFuncs() {
+	className := "rpc"
+	rpcClass := NewLoxClass(className, nil, false)
+	rpcFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native rpc fn %v at %p>", name, &s)
+		}
+		rpcClass.classProperties[name] = s
+	}
+	objectErrMsg := "must be a class, instance, or dictionary."
+
+	rpcFunc("connect", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'rpc.connect' must be a string.")
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'rpc.connect' must be a string.")
+		}
+		network := args[0].(*LoxString).str
+		address := args[1].(*LoxString).str
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxRPCProxy(conn, conn, conn, network+" "+address), nil
+	})
+	rpcFunc("serve", 3, func(in *Interpreter, args list.List[any]) (any, error) {
+		if !rpcCanServe(args[0]) {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'rpc.serve' "+objectErrMsg)
+		}
+		if _, ok := args[1].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'rpc.serve' must be a string.")
+		}
+		if _, ok := args[2].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Third argument to 'rpc.serve' must be a string.")
+		}
+		network := args[1].(*LoxString).str
+		address := args[2].(*LoxString).str
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		defer listener.Close()
+
+		type connRequest struct {
+			line     []byte
+			response chan rpcResponse
+			written  chan struct{}
+		}
+		requests := make(chan connRequest)
+		acceptErr := make(chan error, 1)
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					acceptErr <- err
+					return
+				}
+				go func() {
+					defer conn.Close()
+					reader := bufio.NewReader(conn)
+					responses := make(chan rpcResponse)
+					written := make(chan struct{})
+					for {
+						line, err := reader.ReadBytes('\n')
+						if err != nil {
+							return
+						}
+						select {
+						case requests <- connRequest{line, responses, written}:
+						case <-done:
+							return
+						}
+						responseBytes, _ := json.Marshal(<-responses)
+						_, writeErr := conn.Write(append(responseBytes, '\n'))
+						written <- struct{}{}
+						if writeErr != nil {
+							return
+						}
+					}
+				}()
+			}
+		}()
+
+		server := &rpcServer{object: args[0]}
+		serveErr := server.run(func() error {
+			for !server.stopped {
+				select {
+				case request := <-requests:
+					request.response <- server.handle(in, request.line)
+					<-request.written
+				case err := <-acceptErr:
+					return err
+				}
+			}
+			return nil
+		})
+		if serveErr != nil {
+			return nil, loxerror.RuntimeError(in.callToken, serveErr.Error())
+		}
+		return nil, nil
+	})
+	rpcFunc("serveStdio", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if !rpcCanServe(args[0]) {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'rpc.serveStdio' "+objectErrMsg)
+		}
+		server := &rpcServer{object: args[0]}
+		reader := bufio.NewReader(os.Stdin)
+		serveErr := server.run(func() error {
+			for !server.stopped {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				responseBytes, _ := json.Marshal(server.handle(in, line))
+				if _, err := os.Stdout.Write(append(responseBytes, '\n')); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if serveErr != nil {
+			return nil, loxerror.RuntimeError(in.callToken, serveErr.Error())
+		}
+		return nil, nil
+	})
+	rpcFunc("spawn", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'rpc.spawn' must be a string.")
+		}
+		cmdArgs := []string{}
+		if util.UnsafeMode {
+			cmdArgs = append(cmdArgs, "--unsafe")
+		}
+		if util.DisableLoxCode {
+			cmdArgs = append(cmdArgs, "--disable-loxcode")
+		}
+		cmdArgs = append(cmdArgs, args[0].(*LoxString).str)
+		if argsLen == 2 {
+			scriptArgs, ok := args[1].(*LoxList)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'rpc.spawn' must be a list.")
+			}
+			for _, element := range scriptArgs.elements {
+				loxStr, ok := element.(*LoxString)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"List passed to 'rpc.spawn' must only contain strings.")
+				}
+				cmdArgs = append(cmdArgs, loxStr.str)
+			}
+		}
+
+		exePath, err := os.Executable()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		cmd := exec.Command(exePath, cmdArgs...)
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		proxy := NewLoxRPCProxy(stdout, stdin, stdin, strings.Join(cmdArgs, " "))
+		proxy.process = cmd
+		return proxy, nil
+	})
+	rpcFunc("stop", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if currentRPCServer == nil {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot call 'rpc.stop' outside of an RPC server.")
+		}
+		currentRPCServer.stopped = true
+		return nil, nil
+	})
+
+	i.globals.Define(className, rpcClass)
+}
@@ -0,0 +1,196 @@
+package ast
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+// Built-in classes that can't be used in sandbox mode
+var sandboxedClasses = []string{
+	"dns",
+	"http",
+	"net",
+	"ocr",
+	"os",
+	"process",
+	"rpc",
+	"screen",
+	"smtp",
+	"subprocess",
+	"sysinfo",
+	"tar",
+	"tls",
+	"unsafe",
+	"watch",
+	"webbrowser",
+	"windows",
+	"zip",
+}
+
+// Limits is a set of resource limits, where a limit of 0 means no limit.
+type Limits struct {
+	//The maximum amount of time that the program can run for
+	Timeout time.Duration
+	//The maximum number of bytes that can be allocated in total
+	MaxAlloc int64
+	//The maximum call depth in each thread
+	MaxDepth int
+}
+
+type limitsState struct {
+	Limits
+	exceeded atomic.Pointer[string]
+	stop     chan struct{}
+}
+
+var activeLimits atomic.Pointer[limitsState]
+
+const limitsInterval = 5 * time.Millisecond
+
+// SetLimits starts enforcing the specified resource limits on all interpreters.
+func SetLimits(limits Limits) {
+	state := &limitsState{Limits: limits, stop: make(chan struct{})}
+	if previous := activeLimits.Swap(state); previous != nil {
+		close(previous.stop)
+	}
+	if limits.Timeout > 0 || limits.MaxAlloc > 0 {
+		go state.watch()
+	}
+}
+
+// ClearLimits stops enforcing the limits set by SetLimits.
+func ClearLimits() {
+	if previous := activeLimits.Swap(nil); previous != nil {
+		close(previous.stop)
+	}
+}
+
+func (s *limitsState) watch() {
+	allocs := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	readAllocs := func() int64 {
+		metrics.Read(allocs)
+		if allocs[0].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return int64(allocs[0].Value.Uint64())
+	}
+	start, startAllocs := time.Now(), readAllocs()
+	ticker := time.NewTicker(limitsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			var message string
+			switch {
+			case s.Timeout > 0 && now.Sub(start) > s.Timeout:
+				message = fmt.Sprintf("Time limit of %v exceeded.", s.Timeout)
+			case s.MaxAlloc > 0 && readAllocs()-startAllocs > s.MaxAlloc:
+				message = fmt.Sprintf("Memory limit of %v bytes exceeded.", s.MaxAlloc)
+			}
+			if message != "" {
+				s.exceeded.Store(&message)
+				return
+			}
+		}
+	}
+}
+
+func limitExceeded() string {
+	state := activeLimits.Load()
+	if state == nil {
+		return ""
+	}
+	if message := state.exceeded.Load(); message != nil {
+		return *message
+	}
+	return ""
+}
+
+func limitError(theToken *token.Token, message string) error {
+	if theToken == nil {
+		return loxerror.Error(message)
+	}
+	return loxerror.RuntimeError(theToken, message)
+}
+
+func (i *Interpreter) isInterrupted() bool {
+	if signalsPending.Load() && i.signalErr == nil {
+		i.signalErr = i.runSignalHandlers()
+	}
+	return i.signalErr != nil || i.interrupted.Load() || limitExceeded() != ""
+}
+
+func (i *Interpreter) interruptError(loopToken *token.Token) error {
+	if err := i.signalErr; err != nil {
+		i.signalErr = nil
+		return err
+	}
+	if message := limitExceeded(); message != "" {
+		return loxerror.RuntimeError(loopToken, message)
+	}
+	//The Ctrl+C has been handled by this loop
+	i.interrupted.Store(false)
+	return loxerror.RuntimeError(loopToken, "loop interrupted")
+}
+
+const defaultRecursionLimit = 10000
+
+var recursionLimit atomic.Int64
+
+func init() {
+	recursionLimit.Store(defaultRecursionLimit)
+}
+
+func (i *Interpreter) enterCall() error {
+	state := activeLimits.Load()
+	if i.callDepth >= int(recursionLimit.Load()) ||
+		(state != nil && state.MaxDepth > 0 && i.callDepth >= state.MaxDepth) {
+		return loxerror.RuntimeErrorKind(loxerror.RecursionError, i.callToken,
+			"Maximum recursion depth exceeded.")
+	}
+	if state == nil {
+		return nil
+	}
+	if message := state.exceeded.Load(); message != nil {
+		return limitError(i.callToken, *message)
+	}
+	return nil
+}
+
+func checkSizeLimit(callToken *token.Token, numBytes int64) error {
+	state := activeLimits.Load()
+	if state == nil || state.MaxAlloc <= 0 {
+		return nil
+	}
+	if numBytes < 0 || numBytes > state.MaxAlloc {
+		return limitError(callToken,
+			fmt.Sprintf("Memory limit of %v bytes exceeded.", state.MaxAlloc))
+	}
+	return nil
+}
+
+func checkSandbox(theToken *token.Token, operation string) error {
+	if util.SandboxMode {
+		return limitError(theToken, fmt.Sprintf("%v is not allowed in sandbox mode.", operation))
+	}
+	return nil
+}
+
+func (i *Interpreter) defineSandbox() {
+	if !util.SandboxMode {
+		return
+	}
+	for _, name := range sandboxedClasses {
+		class := NewLoxClass(name, nil, false)
+		class.sandboxed = true
+		i.globals.Define(name, class)
+	}
+}
@@ -0,0 +1,471 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type schedulerJob struct {
+	id        int64
+	callback  *LoxFunction
+	priority  int64
+	interval  time.Duration
+	periodic  bool
+	nextRun   time.Time
+	skipped   int64
+	runs      int64
+	totalTime time.Duration
+	cancelled bool
+}
+
+type scheduler struct {
+	jobs           map[int64]*schedulerJob
+	nextID         int64
+	fairness       int64
+	onError        *LoxFunction
+	running        bool
+	stopped        bool
+	wake           chan struct{}
+	mutex          sync.Mutex
+	completed      int64
+	errors         int64
+	fairnessBoosts int64
+	maxLateness    time.Duration
+	totalLateness  time.Duration
+	priorityRuns   map[int64]int64
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		jobs:         make(map[int64]*schedulerJob),
+		nextID:       1,
+		fairness:     10,
+		wake:         make(chan struct{}, 1),
+		priorityRuns: make(map[int64]int64),
+	}
+}
+
+func (s *scheduler) add(callback *LoxFunction, delay time.Duration, periodic bool, priority int64) int64 {
+	s.mutex.Lock()
+	id := s.nextID
+	s.nextID++
+	s.jobs[id] = &schedulerJob{
+		id:       id,
+		callback: callback,
+		priority: priority,
+		interval: delay,
+		periodic: periodic,
+		nextRun:  time.Now().Add(delay),
+	}
+	s.mutex.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return id
+}
+
+func (s *scheduler) cancel(id int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return false
+	}
+	job.cancelled = true
+	delete(s.jobs, id)
+	return true
+}
+
+// Picks the next job to run out of all jobs that are due. Jobs with higher
+// priorities are picked first, except that a due job that has been passed
+// over s.fairness times in a row is picked regardless of its priority.
+// Returns nil and the time of the earliest upcoming job if no jobs are due.
+func (s *scheduler) pick(now time.Time) (*schedulerJob, time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var chosen *schedulerJob
+	var earliest time.Time
+	ready := []*schedulerJob{}
+	for _, job := range s.jobs {
+		if job.nextRun.After(now) {
+			if earliest.IsZero() || job.nextRun.Before(earliest) {
+				earliest = job.nextRun
+			}
+			continue
+		}
+		ready = append(ready, job)
+	}
+	if len(ready) == 0 {
+		return nil, earliest
+	}
+	sort.Slice(ready, func(a, b int) bool {
+		if !ready[a].nextRun.Equal(ready[b].nextRun) {
+			return ready[a].nextRun.Before(ready[b].nextRun)
+		}
+		return ready[a].id < ready[b].id
+	})
+	boosted := false
+	if s.fairness > 0 {
+		for _, job := range ready {
+			if job.skipped >= s.fairness {
+				chosen = job
+				boosted = true
+				break
+			}
+		}
+	}
+	if chosen == nil {
+		chosen = ready[0]
+		for _, job := range ready[1:] {
+			if job.priority > chosen.priority {
+				chosen = job
+			}
+		}
+	}
+	for _, job := range ready {
+		if job != chosen {
+			job.skipped++
+		}
+	}
+	if boosted {
+		for _, job := range ready {
+			if job != chosen && job.priority > chosen.priority {
+				s.fairnessBoosts++
+				break
+			}
+		}
+	}
+	chosen.skipped = 0
+	return chosen, earliest
+}
+
+func (s *scheduler) runJob(in *Interpreter, job *schedulerJob) error {
+	start := time.Now()
+	lateness := start.Sub(job.nextRun)
+	argList := getArgList(job.callback, 1)
+	argList[0] = job.id
+	result, resultErr := job.callback.call(in, argList)
+	elapsed := time.Since(start)
+
+	s.mutex.Lock()
+	job.runs++
+	job.totalTime += elapsed
+	s.completed++
+	s.priorityRuns[job.priority]++
+	s.totalLateness += lateness
+	if lateness > s.maxLateness {
+		s.maxLateness = lateness
+	}
+	if !job.cancelled {
+		if job.periodic {
+			job.nextRun = job.nextRun.Add(job.interval)
+			if now := time.Now(); job.nextRun.Before(now) {
+				job.nextRun = now
+			}
+		} else {
+			delete(s.jobs, job.id)
+		}
+	}
+	s.mutex.Unlock()
+
+	if resultErr != nil && result == nil {
+		s.mutex.Lock()
+		s.errors++
+		onError := s.onError
+		s.mutex.Unlock()
+		if onError == nil {
+			s.cancel(job.id)
+			return resultErr
+		}
+		msg := resultErr.Error()
+		if index := strings.LastIndex(msg, "\n"); index > 0 {
+			msg = msg[:index]
+		}
+		errArgList := getArgList(onError, 2)
+		errArgList[0] = NewLoxStringQuote(msg)
+		errArgList[1] = job.id
+		result, resultErr := onError.call(in, errArgList)
+		if resultErr != nil && result == nil {
+			return resultErr
+		}
+	}
+	return nil
+}
+
+func (s *scheduler) runPending(in *Interpreter) (int64, error) {
+	count := int64(0)
+	now := time.Now()
+	for !s.stopped {
+		job, _ := s.pick(now)
+		if job == nil {
+			break
+		}
+		count++
+		if err := s.runJob(in, job); err != nil {
+			return count, err
+		}
+		//Periodic jobs that are due again are left for the next call
+		if job.periodic && !job.nextRun.After(now) {
+			s.mutex.Lock()
+			job.nextRun = now.Add(time.Nanosecond)
+			s.mutex.Unlock()
+		}
+	}
+	return count, nil
+}
+
+func (s *scheduler) run(in *Interpreter) error {
+	s.running = true
+	s.stopped = false
+	defer func() {
+		s.running = false
+		s.stopped = false
+	}()
+	for !s.stopped {
+		job, earliest := s.pick(time.Now())
+		if job != nil {
+			if err := s.runJob(in, job); err != nil {
+				return err
+			}
+			continue
+		}
+		if earliest.IsZero() {
+			return nil
+		}
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		}
+	}
+	return nil
+}
+
+func (s *scheduler) stats() *LoxDict {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	setStr := func(dict *LoxDict, key string, value any) {
+		dict.setKeyValue(NewLoxString(key, '\''), value)
+	}
+	durationMs := func(duration time.Duration) float64 {
+		return float64(duration) / float64(time.Millisecond)
+	}
+
+	ids := make([]int64, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		return ids[a] < ids[b]
+	})
+	jobs := list.NewListCap[any](int64(len(ids)))
+	now := time.Now()
+	for _, id := range ids {
+		job := s.jobs[id]
+		jobDict := EmptyLoxDict()
+		setStr(jobDict, "id", job.id)
+		setStr(jobDict, "priority", job.priority)
+		setStr(jobDict, "periodic", job.periodic)
+		setStr(jobDict, "runs", job.runs)
+		setStr(jobDict, "totalTimeMs", durationMs(job.totalTime))
+		if job.runs > 0 {
+			setStr(jobDict, "averageTimeMs", durationMs(job.totalTime)/float64(job.runs))
+		} else {
+			setStr(jobDict, "averageTimeMs", 0.0)
+		}
+		setStr(jobDict, "nextRunMs", math.Max(durationMs(job.nextRun.Sub(now)), 0))
+		jobs.Add(jobDict)
+	}
+
+	priorityRuns := EmptyLoxDict()
+	for priority, runs := range s.priorityRuns {
+		priorityRuns.setKeyValue(priority, runs)
+	}
+
+	result := EmptyLoxDict()
+	setStr(result, "running", s.running)
+	setStr(result, "pending", int64(len(s.jobs)))
+	setStr(result, "completed", s.completed)
+	setStr(result, "errors", s.errors)
+	setStr(result, "fairness", s.fairness)
+	setStr(result, "fairnessBoosts", s.fairnessBoosts)
+	setStr(result, "maxLatenessMs", durationMs(s.maxLateness))
+	if s.completed > 0 {
+		setStr(result, "averageLatenessMs", durationMs(s.totalLateness)/float64(s.completed))
+	} else {
+		setStr(result, "averageLatenessMs", 0.0)
+	}
+	setStr(result, "runsByPriority", priorityRuns)
+	setStr(result, "jobs", NewLoxList(jobs))
+	return result
+}
+
+func (i *Interpreter) defineSchedulerFuncs() {
+	className := "scheduler"
+	schedulerClass := NewLoxClass(className, nil, false)
+	schedulerFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native scheduler fn %v at %p>", name, &s)
+		}
+		schedulerClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'scheduler.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	sched := newScheduler()
+	addJob := func(name string, periodic bool) {
+		schedulerFunc(name, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			argsLen := len(args)
+			if argsLen != 2 && argsLen != 3 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+			}
+			var delay time.Duration
+			switch arg := args[0].(type) {
+			case int64:
+				delay = time.Duration(arg) * time.Millisecond
+			case float64:
+				delay = time.Duration(arg * float64(time.Millisecond))
+			case *LoxDuration:
+				delay = arg.duration
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'scheduler.%v' must be an integer, float, or duration.", name))
+			}
+			if delay < 0 || (periodic && delay == 0) {
+				var errMsg string
+				if periodic {
+					errMsg = "First argument to 'scheduler.%v' must be positive."
+				} else {
+					errMsg = "First argument to 'scheduler.%v' cannot be negative."
+				}
+				return nil, loxerror.RuntimeError(in.callToken, fmt.Sprintf(errMsg, name))
+			}
+			callback, ok := args[1].(*LoxFunction)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Second argument to 'scheduler.%v' must be a function.", name))
+			}
+			priority := int64(0)
+			if argsLen == 3 {
+				priority, ok = args[2].(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Third argument to 'scheduler.%v' must be an integer.", name))
+				}
+			}
+			return sched.add(callback, delay, periodic, priority), nil
+		})
+	}
+
+	addJob("after", false)
+	schedulerFunc("cancel", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if id, ok := args[0].(int64); ok {
+			return sched.cancel(id), nil
+		}
+		return argMustBeType(in.callToken, "cancel", "integer")
+	})
+	addJob("every", true)
+	schedulerFunc("fairness", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return sched.fairness, nil
+	})
+	schedulerFunc("isRunning", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return sched.running, nil
+	})
+	schedulerFunc("onError", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch arg := args[0].(type) {
+		case *LoxFunction:
+			sched.mutex.Lock()
+			sched.onError = arg
+			sched.mutex.Unlock()
+		case nil:
+			sched.mutex.Lock()
+			sched.onError = nil
+			sched.mutex.Unlock()
+		default:
+			return argMustBeType(in.callToken, "onError", "function or nil")
+		}
+		return nil, nil
+	})
+	schedulerFunc("run", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if sched.running {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Scheduler is already running.")
+		}
+		return nil, sched.run(in)
+	})
+	schedulerFunc("runPending", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if sched.running {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot call 'scheduler.runPending' while the scheduler is running.")
+		}
+		count, err := sched.runPending(in)
+		sched.stopped = false
+		if err != nil {
+			return nil, err
+		}
+		return count, nil
+	})
+	schedulerFunc("setFairness", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if fairness, ok := args[0].(int64); ok {
+			if fairness < 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'scheduler.setFairness' cannot be negative.")
+			}
+			sched.mutex.Lock()
+			sched.fairness = fairness
+			sched.mutex.Unlock()
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "setFairness", "integer")
+	})
+	schedulerFunc("stats", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return sched.stats(), nil
+	})
+	schedulerFunc("stop", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		sched.stopped = true
+		select {
+		case sched.wake <- struct{}{}:
+		default:
+		}
+		return nil, nil
+	})
+	schedulerFunc("task", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		callback, ok := args[0].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'scheduler.task' must be a function.")
+		}
+		priority := int64(0)
+		if argsLen == 2 {
+			priority, ok = args[1].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'scheduler.task' must be an integer.")
+			}
+		}
+		return sched.add(callback, 0, false, priority), nil
+	})
+
+	i.globals.Define(className, schedulerClass)
+}
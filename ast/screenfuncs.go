@@ -0,0 +1,86 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/screen"
+)
+
+func (i *Interpreter) defineScreenFuncs() {
+	className := "screen"
+	screenClass := NewLoxClass(className, nil, false)
+	screenFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native screen fn %v at %p>", name, &s)
+		}
+		screenClass.classProperties[name] = s
+	}
+
+	screenFunc("capture", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		var region *screen.Region
+		switch argsLen {
+		case 0:
+		case 1:
+			regionList, ok := args[0].(*LoxList)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'screen.capture' must be a list.")
+			}
+			if len(regionList.elements) != 4 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Region list passed to 'screen.capture' must have exactly 4 elements.")
+			}
+			values := [4]int64{}
+			for index, element := range regionList.elements {
+				num, ok := element.(int64)
+				if !ok {
+					return nil, loxerror.RuntimeError(in.callToken,
+						"Region list passed to 'screen.capture' must only contain integers.")
+				}
+				values[index] = num
+			}
+			region = &screen.Region{
+				X:      values[0],
+				Y:      values[1],
+				Width:  values[2],
+				Height: values[3],
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+
+		bytes, err := screen.Capture(region)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		buffer := EmptyLoxBufferCap(int64(len(bytes)))
+		for _, element := range bytes {
+			bufErr := buffer.add(int64(element))
+			if bufErr != nil {
+				return nil, loxerror.RuntimeError(in.callToken, bufErr.Error())
+			}
+		}
+		return buffer, nil
+	})
+	screenFunc("commands", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		commands := screen.Commands(nil, "<file>")
+		outerList := list.NewListCap[any](int64(len(commands)))
+		for _, command := range commands {
+			innerList := list.NewListCap[any](int64(len(command)))
+			for _, str := range command {
+				innerList.Add(NewLoxStringQuote(str))
+			}
+			outerList.Add(NewLoxList(innerList))
+		}
+		return NewLoxList(outerList), nil
+	})
+
+	i.globals.Define(className, screenClass)
+}
@@ -0,0 +1,156 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/syscalls"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+type loxSignalHandler struct {
+	name     string
+	channel  chan os.Signal
+	in       *Interpreter
+	callback *LoxFunction //nil if the signal is ignored
+	pending  bool
+}
+
+var (
+	signalHandlersMutex sync.Mutex
+	signalHandlers      = make(map[syscall.Signal]*loxSignalHandler)
+	//Whether any handler has a signal that hasn't been handled yet
+	signalsPending atomic.Bool
+)
+
+func lookupSignal(value any) (syscall.Signal, string, bool) {
+	switch value := value.(type) {
+	case *LoxString:
+		name := strings.ToUpper(value.str)
+		if !strings.HasPrefix(name, "SIG") {
+			name = "SIG" + name
+		}
+		sig, ok := syscalls.Signals[name]
+		return sig, name, ok
+	case int64:
+		for name, sig := range syscalls.Signals {
+			if int64(sig) == value {
+				return sig, name, true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+func isSignalTrapped(sig syscall.Signal) bool {
+	signalHandlersMutex.Lock()
+	defer signalHandlersMutex.Unlock()
+	_, ok := signalHandlers[sig]
+	return ok
+}
+
+func resetSignal(sig syscall.Signal) {
+	signalHandlersMutex.Lock()
+	defer signalHandlersMutex.Unlock()
+	if handler, ok := signalHandlers[sig]; ok {
+		signal.Stop(handler.channel)
+		close(handler.channel)
+		delete(signalHandlers, sig)
+	}
+}
+
+func setSignalHandler(in *Interpreter, sig syscall.Signal, name string, callback *LoxFunction) {
+	signalHandlersMutex.Lock()
+	defer signalHandlersMutex.Unlock()
+	if handler, ok := signalHandlers[sig]; ok {
+		handler.in = in
+		handler.callback = callback
+		handler.pending = false
+		return
+	}
+	handler := &loxSignalHandler{
+		name:     name,
+		channel:  make(chan os.Signal, 1),
+		in:       in,
+		callback: callback,
+	}
+	signalHandlers[sig] = handler
+	signal.Notify(handler.channel, sig)
+	go func() {
+		for range handler.channel {
+			signalHandlersMutex.Lock()
+			if handler.callback != nil {
+				//Exit on a second Ctrl+C in case the program is stuck
+				if handler.pending && sig == syscall.SIGINT && util.StdinFromTerminal() {
+					exitLox(130)
+				}
+				handler.pending = true
+				signalsPending.Store(true)
+			}
+			signalHandlersMutex.Unlock()
+		}
+	}()
+}
+
+func (i *Interpreter) runSignalHandlers() error {
+	if i.inSignalHandler {
+		return nil
+	}
+	signalHandlersMutex.Lock()
+	var handlers []*loxSignalHandler
+	stillPending := false
+	for _, handler := range signalHandlers {
+		if !handler.pending {
+			continue
+		}
+		if handler.in != i {
+			stillPending = true
+			continue
+		}
+		handler.pending = false
+		handlers = append(handlers, handler)
+	}
+	signalsPending.Store(stillPending)
+	signalHandlersMutex.Unlock()
+
+	i.inSignalHandler = true
+	defer func() {
+		i.inSignalHandler = false
+	}()
+	for _, handler := range handlers {
+		callback := handler.callback
+		if callback == nil {
+			continue
+		}
+		argList := getArgList(callback, 1)
+		argList[0] = NewLoxStringQuote(handler.name)
+		result, err := callback.call(i, argList)
+		argList.Clear()
+		if _, ok := result.(Return); !ok && err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func signalArg(callToken *token.Token, fnName string, value any) (syscall.Signal, string, error) {
+	switch value.(type) {
+	case *LoxString, int64:
+	default:
+		return 0, "", loxerror.RuntimeError(callToken,
+			fmt.Sprintf("First argument to 'os.%v' must be a signal name string or number.", fnName))
+	}
+	sig, name, ok := lookupSignal(value)
+	if !ok {
+		return 0, "", loxerror.RuntimeError(callToken,
+			fmt.Sprintf("os.%v: unknown or unsupported signal '%v'.", fnName, getResult(value, value, true)))
+	}
+	return sig, name, nil
+}
@@ -0,0 +1,597 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+// An attachment of an email message
+type smtpAttachment struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// An email message built from the dictionary passed to smtp.format or
+// smtp.send
+type smtpMessage struct {
+	from        string
+	to          []string
+	cc          []string
+	bcc         []string
+	subject     string
+	text        string
+	html        string
+	headers     map[string]string
+	attachments []smtpAttachment
+}
+
+// The settings of the SMTP server passed to smtp.send
+type smtpServer struct {
+	host               string
+	port               int64
+	username           string
+	password           string
+	auth               string
+	startTLS           *bool
+	implicitTLS        bool
+	timeout            time.Duration
+	insecureSkipVerify bool
+}
+
+// An implementation of the LOGIN authentication mechanism, which net/smtp
+// does not provide
+type smtpLoginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *smtpLoginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		isLocalhost := server.Name == "localhost" ||
+			server.Name == "127.0.0.1" || server.Name == "::1"
+		if !isLocalhost {
+			return "", nil, errors.New("unencrypted connection")
+		}
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *smtpLoginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected server challenge '%v'", fromServer)
+}
+
+// Returns the addresses in the specified value, which is either a string or
+// a list of strings
+func smtpAddresses(value any) ([]string, bool) {
+	switch value := value.(type) {
+	case *LoxString:
+		return []string{value.str}, true
+	case *LoxList:
+		addresses := make([]string, 0, len(value.elements))
+		for _, element := range value.elements {
+			address, ok := element.(*LoxString)
+			if !ok {
+				return nil, false
+			}
+			addresses = append(addresses, address.str)
+		}
+		return addresses, true
+	}
+	return nil, false
+}
+
+// Returns the plain email addresses of the specified address strings, which
+// may include display names, such as "Name <user@example.com>"
+func smtpParseAddresses(addresses []string) ([]string, error) {
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%v': %v", address, err)
+		}
+		result = append(result, parsed.Address)
+	}
+	return result, nil
+}
+
+func smtpBoundary() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return "lox-" + hex.EncodeToString(bytes)
+}
+
+// Writes the specified data to the specified buffer encoded in base64 with
+// lines of at most 76 characters
+func smtpWriteBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// Writes the specified text to the specified buffer encoded in
+// quoted-printable with CRLF line endings
+func smtpWriteQuotedPrintable(buf *bytes.Buffer, text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\n", "\r\n")
+	writer := quotedprintable.NewWriter(buf)
+	writer.Write([]byte(text))
+	writer.Close()
+	buf.WriteString("\r\n")
+}
+
+func smtpWriteTextPart(buf *bytes.Buffer, contentType string, text string) {
+	buf.WriteString("Content-Type: " + contentType + "; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	smtpWriteQuotedPrintable(buf, text)
+}
+
+// Writes the body of the specified message, which is either plain text,
+// HTML, or both as alternatives, to the specified buffer along with the
+// headers that describe it
+func smtpWriteBody(buf *bytes.Buffer, msg *smtpMessage) {
+	switch {
+	case msg.html == "":
+		smtpWriteTextPart(buf, "text/plain", msg.text)
+	case msg.text == "":
+		smtpWriteTextPart(buf, "text/html", msg.html)
+	default:
+		boundary := smtpBoundary()
+		buf.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n\r\n")
+		buf.WriteString("--" + boundary + "\r\n")
+		smtpWriteTextPart(buf, "text/plain", msg.text)
+		buf.WriteString("--" + boundary + "\r\n")
+		smtpWriteTextPart(buf, "text/html", msg.html)
+		buf.WriteString("--" + boundary + "--\r\n")
+	}
+}
+
+// Returns the raw contents of the specified message in MIME format. Bcc
+// recipients are never included in the headers
+func (msg *smtpMessage) bytes() []byte {
+	var buf bytes.Buffer
+	writeHeader := func(key string, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", msg.from)
+	if len(msg.to) > 0 {
+		writeHeader("To", strings.Join(msg.to, ", "))
+	}
+	if len(msg.cc) > 0 {
+		writeHeader("Cc", strings.Join(msg.cc, ", "))
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.subject))
+	headerKeys := make([]string, 0, len(msg.headers))
+	for key := range msg.headers {
+		headerKeys = append(headerKeys, key)
+	}
+	sort.Strings(headerKeys)
+	for _, key := range headerKeys {
+		writeHeader(key, mime.QEncoding.Encode("utf-8", msg.headers[key]))
+	}
+	if _, ok := msg.headers["Date"]; !ok {
+		writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	}
+	writeHeader("MIME-Version", "1.0")
+	if len(msg.attachments) == 0 {
+		smtpWriteBody(&buf, msg)
+		return buf.Bytes()
+	}
+	boundary := smtpBoundary()
+	buf.WriteString("Content-Type: multipart/mixed; boundary=\"" + boundary + "\"\r\n\r\n")
+	buf.WriteString("--" + boundary + "\r\n")
+	smtpWriteBody(&buf, msg)
+	for _, attachment := range msg.attachments {
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: " + attachment.contentType + "\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+		buf.WriteString("Content-Disposition: " +
+			mime.FormatMediaType("attachment", map[string]string{"filename": attachment.name}) + "\r\n\r\n")
+		smtpWriteBase64(&buf, attachment.data)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes()
+}
+
+// Sends the specified message to the specified SMTP server
+func (server *smtpServer) send(msg *smtpMessage) error {
+	from, err := smtpParseAddresses([]string{msg.from})
+	if err != nil {
+		return err
+	}
+	recipients := make([]string, 0, len(msg.to)+len(msg.cc)+len(msg.bcc))
+	recipients = append(recipients, msg.to...)
+	recipients = append(recipients, msg.cc...)
+	recipients = append(recipients, msg.bcc...)
+	recipients, err = smtpParseAddresses(recipients)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return errors.New("email message must have at least one recipient")
+	}
+
+	address := net.JoinHostPort(server.host, strconv.FormatInt(server.port, 10))
+	tlsConfig := &tls.Config{
+		ServerName:         server.host,
+		InsecureSkipVerify: server.insecureSkipVerify,
+	}
+	dialer := &net.Dialer{Timeout: server.timeout}
+	var conn net.Conn
+	if server.implicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	if server.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(server.timeout))
+	}
+	client, err := smtp.NewClient(conn, server.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !server.implicitTLS {
+		supported, _ := client.Extension("STARTTLS")
+		if server.startTLS != nil && *server.startTLS && !supported {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if supported && (server.startTLS == nil || *server.startTLS) {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	var auth smtp.Auth
+	switch server.auth {
+	case "plain":
+		auth = smtp.PlainAuth("", server.username, server.password, server.host)
+	case "login":
+		auth = &smtpLoginAuth{server.username, server.password, server.host}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from[0]); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg.bytes()); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (i *Interpreter) defineSMTPFuncs() {
+	className := "smtp"
+	smtpClass := NewLoxClass(className, nil, false)
+	smtpFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native smtp fn %v at %p>", name, &s)
+		}
+		smtpClass.classProperties[name] = s
+	}
+	optionErr := func(in *Interpreter, fnName string, key string, theType string) error {
+		return loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Option '%v' in 'smtp.%v' must be %v.", key, fnName, theType))
+	}
+	unknownOptionErr := func(in *Interpreter, fnName string, key string) error {
+		return loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Unknown option '%v' in 'smtp.%v'.", key, fnName))
+	}
+	parseAttachment := func(in *Interpreter, fnName string, value any) (smtpAttachment, error) {
+		attachment := smtpAttachment{}
+		dict, ok := value.(*LoxDict)
+		if !ok {
+			return attachment, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Attachments in 'smtp.%v' must be dictionaries.", fnName))
+		}
+		hasData := false
+		err := forEachOption(dict, func(key string, value any) error {
+			switch key {
+			case "contentType":
+				contentType, ok := value.(*LoxString)
+				if !ok {
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Attachment option '%v' in 'smtp.%v' must be a string.", key, fnName))
+				}
+				attachment.contentType = contentType.str
+			case "data":
+				data, ok := netData(value)
+				if !ok {
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Attachment option '%v' in 'smtp.%v' must be a buffer or string.", key, fnName))
+				}
+				attachment.data = data
+				hasData = true
+			case "name":
+				attachmentName, ok := value.(*LoxString)
+				if !ok {
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Attachment option '%v' in 'smtp.%v' must be a string.", key, fnName))
+				}
+				attachment.name = attachmentName.str
+			default:
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Unknown attachment option '%v' in 'smtp.%v'.", key, fnName))
+			}
+			return nil
+		})
+		if err != nil {
+			return attachment, err
+		}
+		if attachment.name == "" || !hasData {
+			return attachment, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Attachments in 'smtp.%v' must have a name and data.", fnName))
+		}
+		if attachment.contentType == "" {
+			attachment.contentType = mime.TypeByExtension(filepath.Ext(attachment.name))
+			if attachment.contentType == "" {
+				attachment.contentType = "application/octet-stream"
+			}
+		}
+		return attachment, nil
+	}
+	parseMessage := func(in *Interpreter, fnName string, dict *LoxDict) (*smtpMessage, error) {
+		msg := &smtpMessage{headers: make(map[string]string)}
+		err := forEachOption(dict, func(key string, value any) error {
+			switch key {
+			case "attachments":
+				attachments, ok := value.(*LoxList)
+				if !ok {
+					return optionErr(in, fnName, key, "a list")
+				}
+				for _, element := range attachments.elements {
+					attachment, err := parseAttachment(in, fnName, element)
+					if err != nil {
+						return err
+					}
+					msg.attachments = append(msg.attachments, attachment)
+				}
+			case "bcc", "cc", "to":
+				addresses, ok := smtpAddresses(value)
+				if !ok {
+					return optionErr(in, fnName, key, "a string or a list of strings")
+				}
+				switch key {
+				case "bcc":
+					msg.bcc = addresses
+				case "cc":
+					msg.cc = addresses
+				case "to":
+					msg.to = addresses
+				}
+			case "from", "html", "subject", "text":
+				str, ok := value.(*LoxString)
+				if !ok {
+					return optionErr(in, fnName, key, "a string")
+				}
+				switch key {
+				case "from":
+					msg.from = str.str
+				case "html":
+					msg.html = str.str
+				case "subject":
+					msg.subject = str.str
+				case "text":
+					msg.text = str.str
+				}
+			case "headers":
+				headers, ok := value.(*LoxDict)
+				if !ok {
+					return optionErr(in, fnName, key, "a dictionary")
+				}
+				return forEachOption(headers, func(headerKey string, headerValue any) error {
+					headerStr, ok := headerValue.(*LoxString)
+					if !ok {
+						return loxerror.RuntimeError(in.callToken,
+							fmt.Sprintf("Header values in 'smtp.%v' must be strings.", fnName))
+					}
+					if strings.ContainsAny(headerKey, "\r\n:") ||
+						strings.ContainsAny(headerStr.str, "\r\n") {
+						return loxerror.RuntimeError(in.callToken,
+							fmt.Sprintf("Invalid header '%v' in 'smtp.%v'.", headerKey, fnName))
+					}
+					msg.headers[textproto.CanonicalMIMEHeaderKey(headerKey)] = headerStr.str
+					return nil
+				})
+			default:
+				return unknownOptionErr(in, fnName, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if msg.from == "" {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Email message passed to 'smtp.%v' must have a 'from' address.", fnName))
+		}
+		for _, address := range [][]string{{msg.from}, msg.to, msg.cc, msg.bcc} {
+			if _, err := smtpParseAddresses(address); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		}
+		return msg, nil
+	}
+	parseServer := func(in *Interpreter, dict *LoxDict) (*smtpServer, error) {
+		server := &smtpServer{port: 587, timeout: 30 * time.Second}
+		err := forEachOption(dict, func(key string, value any) error {
+			switch key {
+			case "auth":
+				auth, ok := value.(*LoxString)
+				if !ok {
+					return optionErr(in, "send", key, "a string")
+				}
+				switch auth.str {
+				case "login", "none", "plain":
+					server.auth = auth.str
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown SMTP authentication type '%v'.", auth.str))
+				}
+			case "host", "password", "username":
+				str, ok := value.(*LoxString)
+				if !ok {
+					return optionErr(in, "send", key, "a string")
+				}
+				switch key {
+				case "host":
+					server.host = str.str
+				case "password":
+					server.password = str.str
+				case "username":
+					server.username = str.str
+				}
+			case "insecureSkipVerify", "startTLS", "tls":
+				b, ok := value.(bool)
+				if !ok {
+					return optionErr(in, "send", key, "a boolean")
+				}
+				switch key {
+				case "insecureSkipVerify":
+					server.insecureSkipVerify = b
+				case "startTLS":
+					server.startTLS = &b
+				case "tls":
+					server.implicitTLS = b
+				}
+			case "port":
+				port, ok := value.(int64)
+				if !ok {
+					return optionErr(in, "send", key, "an integer")
+				}
+				if port <= 0 || port > 65535 {
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Invalid SMTP port %v.", port))
+				}
+				server.port = port
+			case "timeout":
+				switch seconds := value.(type) {
+				case int64:
+					server.timeout = time.Duration(seconds) * time.Second
+				case float64:
+					server.timeout = time.Duration(seconds * float64(time.Second))
+				default:
+					return optionErr(in, "send", key, "an integer or float")
+				}
+			default:
+				return unknownOptionErr(in, "send", key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if server.host == "" {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Server passed to 'smtp.send' must have a 'host'.")
+		}
+		if server.auth == "" {
+			if server.username != "" {
+				server.auth = "plain"
+			} else {
+				server.auth = "none"
+			}
+		}
+		return server, nil
+	}
+
+	smtpFunc("format", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		dict, ok := args[0].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'smtp.format' must be a dictionary.")
+		}
+		msg, err := parseMessage(in, "format", dict)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxStringQuote(string(msg.bytes())), nil
+	})
+	smtpFunc("send", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		serverDict, ok := args[0].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'smtp.send' must be a dictionary.")
+		}
+		messageDict, ok := args[1].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'smtp.send' must be a dictionary.")
+		}
+		server, err := parseServer(in, serverDict)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := parseMessage(in, "send", messageDict)
+		if err != nil {
+			return nil, err
+		}
+		if err := server.send(msg); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return nil, nil
+	})
+
+	i.globals.Define(className, smtpClass)
+}
@@ -0,0 +1,304 @@
+package ast
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type sortedTreeNode struct {
+	key    any
+	value  any
+	left   *sortedTreeNode
+	right  *sortedTreeNode
+	height int
+	size   int //Number of nodes in this subtree, used to look up nodes by index
+}
+
+func (n *sortedTreeNode) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *sortedTreeNode) getSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *sortedTreeNode) update() {
+	n.height = 1 + max(n.left.getHeight(), n.right.getHeight())
+	n.size = 1 + n.left.getSize() + n.right.getSize()
+}
+
+func (n *sortedTreeNode) rotateLeft() *sortedTreeNode {
+	right := n.right
+	n.right = right.left
+	right.left = n
+	n.update()
+	right.update()
+	return right
+}
+
+func (n *sortedTreeNode) rotateRight() *sortedTreeNode {
+	left := n.left
+	n.left = left.right
+	left.right = n
+	n.update()
+	left.update()
+	return left
+}
+
+func (n *sortedTreeNode) balance() *sortedTreeNode {
+	n.update()
+	switch factor := n.left.getHeight() - n.right.getHeight(); {
+	case factor > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case factor < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+func (n *sortedTreeNode) insertAt(index int, node *sortedTreeNode) *sortedTreeNode {
+	if n == nil {
+		return node
+	}
+	if leftSize := n.left.getSize(); index <= leftSize {
+		n.left = n.left.insertAt(index, node)
+	} else {
+		n.right = n.right.insertAt(index-leftSize-1, node)
+	}
+	return n.balance()
+}
+
+func (n *sortedTreeNode) removeAt(index int) (*sortedTreeNode, *sortedTreeNode) {
+	var removed *sortedTreeNode
+	switch leftSize := n.left.getSize(); {
+	case index < leftSize:
+		n.left, removed = n.left.removeAt(index)
+	case index > leftSize:
+		n.right, removed = n.right.removeAt(index - leftSize - 1)
+	default:
+		if n.left == nil {
+			return n.right, n
+		}
+		if n.right == nil {
+			return n.left, n
+		}
+		right, successor := n.right.removeAt(0)
+		successor.left = n.left
+		successor.right = right
+		return successor.balance(), n
+	}
+	return n.balance(), removed
+}
+
+// Comparing keys can call Lox functions that fail, so the tree never compares
+// keys itself and callers find the index of a key with bisect before changing it
+type sortedTree struct {
+	root *sortedTreeNode
+}
+
+func (t *sortedTree) len() int {
+	return t.root.getSize()
+}
+
+func (t *sortedTree) at(index int) *sortedTreeNode {
+	node := t.root
+	for node != nil {
+		leftSize := node.left.getSize()
+		switch {
+		case index < leftSize:
+			node = node.left
+		case index > leftSize:
+			index -= leftSize + 1
+			node = node.right
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+func (t *sortedTree) bisect(key any, after bool, compare sortedCompareFunc) (int, error) {
+	index := 0
+	node := t.root
+	for node != nil {
+		result, err := compare(key, node.key)
+		if err != nil {
+			return 0, err
+		}
+		if result < 0 || (result == 0 && !after) {
+			node = node.left
+		} else {
+			index += node.left.getSize() + 1
+			node = node.right
+		}
+	}
+	return index, nil
+}
+
+func (t *sortedTree) clear() {
+	t.root = nil
+}
+
+func (t *sortedTree) insertAt(index int, key any, value any) {
+	t.root = t.root.insertAt(index, &sortedTreeNode{
+		key:    key,
+		value:  value,
+		height: 1,
+		size:   1,
+	})
+}
+
+func (t *sortedTree) nodes() []*sortedTreeNode {
+	nodes := make([]*sortedTreeNode, 0, t.len())
+	stack := []*sortedTreeNode{}
+	node := t.root
+	for node != nil || len(stack) > 0 {
+		for node != nil {
+			stack = append(stack, node)
+			node = node.left
+		}
+		node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, node)
+		node = node.right
+	}
+	return nodes
+}
+
+func (t *sortedTree) removeAt(index int) *sortedTreeNode {
+	var removed *sortedTreeNode
+	t.root, removed = t.root.removeAt(index)
+	return removed
+}
+
+func (t *sortedTree) rangeIndexes(lower any, upper any, compare sortedCompareFunc) (int, int, error) {
+	start, end := 0, t.len()
+	var err error
+	if lower != nil {
+		start, err = t.bisect(lower, false, compare)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if upper != nil {
+		end, err = t.bisect(upper, true, compare)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, max(start, end), nil
+}
+
+type sortedCompareFunc func(a any, b any) (int, error)
+
+func compareNatural(a any, b any) (int, error) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, b), nil
+		case float64:
+			return cmp.Compare(float64(a), b), nil
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, float64(b)), nil
+		case float64:
+			return cmp.Compare(a, b), nil
+		}
+	case *LoxString:
+		if b, ok := b.(*LoxString); ok {
+			return strings.Compare(a.str, b.str), nil
+		}
+	}
+	first, firstOk := sortedBigFloat(a)
+	second, secondOk := sortedBigFloat(b)
+	if firstOk && secondOk {
+		return first.Cmp(second), nil
+	}
+	return 0, loxerror.Error(fmt.Sprintf("Cannot compare types '%v' and '%v'.", getType(a), getType(b)))
+}
+
+func sortedBigFloat(value any) (*big.Float, bool) {
+	switch value := value.(type) {
+	case int64:
+		return new(big.Float).SetInt64(value), true
+	case float64:
+		if math.IsNaN(value) {
+			return nil, false
+		}
+		return big.NewFloat(value), true
+	case *big.Int:
+		return new(big.Float).SetInt(value), true
+	case *big.Float:
+		return value, true
+	}
+	return nil, false
+}
+
+func loxCompareFunc(in *Interpreter, callback *LoxFunction) sortedCompareFunc {
+	argList := getArgList(callback, 2)
+	return func(a any, b any) (int, error) {
+		argList[0] = a
+		argList[1] = b
+		result, resultErr := callback.call(in, argList)
+		if resultReturn, ok := result.(Return); ok {
+			result = resultReturn.FinalValue
+		} else if resultErr != nil {
+			return 0, sortedCallbackError{resultErr}
+		}
+		switch result := result.(type) {
+		case int64:
+			return cmp.Compare(result, 0), nil
+		case float64:
+			return cmp.Compare(result, 0), nil
+		}
+		return 0, loxerror.Error(fmt.Sprintf(
+			"Comparison function must return a number, not a value of type '%v'.", getType(result)))
+	}
+}
+
+type sortedCallbackError struct {
+	err error
+}
+
+func (e sortedCallbackError) Error() string {
+	return e.err.Error()
+}
+
+func sortedCompareError(name *token.Token, err error) error {
+	var callbackErr sortedCallbackError
+	if errors.As(err, &callbackErr) {
+		return callbackErr.err
+	}
+	return loxerror.RuntimeError(name, err.Error())
+}
+
+func sortedNodesToList(nodes []*sortedTreeNode) *LoxList {
+	elements := list.NewListCap[any](int64(len(nodes)))
+	for _, node := range nodes {
+		elements.Add(node.key)
+	}
+	return NewLoxList(elements)
+}
@@ -0,0 +1,308 @@
+package ast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Sizes in bytes of each struct format character
+var structCodeSizes = map[byte]int{
+	'x': 1,
+	'c': 1,
+	'b': 1,
+	'B': 1,
+	'?': 1,
+	'h': 2,
+	'H': 2,
+	'i': 4,
+	'I': 4,
+	'l': 4,
+	'L': 4,
+	'q': 8,
+	'Q': 8,
+	'f': 4,
+	'd': 8,
+	's': 1,
+}
+
+type structField struct {
+	code byte
+	//For 's', the length of the string in bytes, otherwise the number of
+	//times the field is repeated
+	count int
+}
+
+type structByteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type structFormat struct {
+	order  structByteOrder
+	fields []structField
+	size   int
+}
+
+func parseStructFormat(format string) (*structFormat, error) {
+	result := &structFormat{order: binary.NativeEndian}
+	rest := format
+	if len(rest) > 0 {
+		switch rest[0] {
+		case '@', '=':
+			rest = rest[1:]
+		case '<':
+			result.order = binary.LittleEndian
+			rest = rest[1:]
+		case '>', '!':
+			result.order = binary.BigEndian
+			rest = rest[1:]
+		}
+	}
+	for len(rest) > 0 {
+		c := rest[0]
+		if unicode.IsSpace(rune(c)) {
+			rest = rest[1:]
+			continue
+		}
+		count := 1
+		if c >= '0' && c <= '9' {
+			digitsEnd := strings.IndexFunc(rest, func(r rune) bool {
+				return r < '0' || r > '9'
+			})
+			if digitsEnd < 0 {
+				return nil, loxerror.Error("Repeat count given without format character in struct format.")
+			}
+			var parseErr error
+			count, parseErr = strconv.Atoi(rest[:digitsEnd])
+			if parseErr != nil {
+				return nil, loxerror.Error("Invalid repeat count in struct format.")
+			}
+			rest = rest[digitsEnd:]
+			c = rest[0]
+		}
+		codeSize, ok := structCodeSizes[c]
+		if !ok {
+			return nil, loxerror.Error(fmt.Sprintf("Bad character '%c' in struct format.", c))
+		}
+		if count > math.MaxInt32/codeSize || result.size > math.MaxInt32-count*codeSize {
+			return nil, loxerror.Error("Struct format size is too large.")
+		}
+		if count > 0 || c == 's' {
+			result.fields = append(result.fields, structField{c, count})
+		}
+		result.size += count * codeSize
+		rest = rest[1:]
+	}
+	return result, nil
+}
+
+// Returns the number of values that are packed into or unpacked from the
+// current format
+func (s *structFormat) numValues() int {
+	num := 0
+	for _, field := range s.fields {
+		switch field.code {
+		case 'x':
+		case 's':
+			num++
+		default:
+			num += field.count
+		}
+	}
+	return num
+}
+
+// Returns the minimum and maximum values of the specified integer format
+// character
+func structIntRange(code byte) (*big.Int, *big.Int) {
+	numBits := uint(structCodeSizes[code] * 8)
+	one := big.NewInt(1)
+	if code >= 'a' && code <= 'z' {
+		limit := new(big.Int).Lsh(one, numBits-1)
+		return new(big.Int).Neg(limit), limit.Sub(limit, one)
+	}
+	limit := new(big.Int).Lsh(one, numBits)
+	return new(big.Int), limit.Sub(limit, one)
+}
+
+func (s *structFormat) pack(callToken *token.Token, values []any) ([]byte, error) {
+	numValues := s.numValues()
+	if len(values) != numValues {
+		valuesStr := "values"
+		if numValues == 1 {
+			valuesStr = "value"
+		}
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Struct format requires %v %v to pack but got %v.", numValues, valuesStr, len(values)))
+	}
+	result := make([]byte, 0, s.size)
+	valueIndex := 0
+	nextValue := func() any {
+		value := values[valueIndex]
+		valueIndex++
+		return value
+	}
+	for _, field := range s.fields {
+		switch field.code {
+		case 'x':
+			result = append(result, make([]byte, field.count)...)
+			continue
+		case 's':
+			var bytes []byte
+			switch value := nextValue().(type) {
+			case *LoxString:
+				bytes = []byte(value.str)
+			case *LoxBuffer:
+				bytes = value.bytes()
+			default:
+				return nil, loxerror.RuntimeError(callToken,
+					"Value for struct format 's' must be a string or buffer.")
+			}
+			//Strings are truncated or padded with null bytes to the field length
+			padded := make([]byte, field.count)
+			copy(padded, bytes)
+			result = append(result, padded...)
+			continue
+		}
+		for range field.count {
+			value := nextValue()
+			switch field.code {
+			case 'c':
+				str, ok := value.(*LoxString)
+				if !ok || len(str.str) != 1 {
+					return nil, loxerror.RuntimeError(callToken,
+						"Value for struct format 'c' must be a string of length 1.")
+				}
+				result = append(result, str.str[0])
+			case '?':
+				boolean, ok := value.(bool)
+				if !ok {
+					return nil, loxerror.RuntimeError(callToken,
+						"Value for struct format '?' must be a boolean.")
+				}
+				if boolean {
+					result = append(result, 1)
+				} else {
+					result = append(result, 0)
+				}
+			case 'f', 'd':
+				var num float64
+				switch value := value.(type) {
+				case int64:
+					num = float64(value)
+				case float64:
+					num = value
+				default:
+					return nil, loxerror.RuntimeError(callToken,
+						fmt.Sprintf("Value for struct format '%c' must be an integer or float.", field.code))
+				}
+				if field.code == 'f' {
+					if !math.IsInf(num, 0) && math.IsInf(float64(float32(num)), 0) {
+						return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+							"Float is too large to pack with struct format 'f'.")
+					}
+					result = s.order.AppendUint32(result, math.Float32bits(float32(num)))
+				} else {
+					result = s.order.AppendUint64(result, math.Float64bits(num))
+				}
+			default:
+				var num *big.Int
+				switch value := value.(type) {
+				case int64:
+					num = big.NewInt(value)
+				case *big.Int:
+					num = value
+				default:
+					return nil, loxerror.RuntimeError(callToken,
+						fmt.Sprintf("Value for struct format '%c' must be an integer or bigint.", field.code))
+				}
+				min, max := structIntRange(field.code)
+				if num.Cmp(min) < 0 || num.Cmp(max) > 0 {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+						fmt.Sprintf("Value %v out of range for struct format '%c', which must be between %v and %v.",
+							num, field.code, min, max))
+				}
+				//Two's complement representation of the value as an unsigned integer
+				var bits uint64
+				if num.Sign() < 0 {
+					bits = uint64(num.Int64())
+				} else {
+					bits = num.Uint64()
+				}
+				switch structCodeSizes[field.code] {
+				case 1:
+					result = append(result, byte(bits))
+				case 2:
+					result = s.order.AppendUint16(result, uint16(bits))
+				case 4:
+					result = s.order.AppendUint32(result, uint32(bits))
+				case 8:
+					result = s.order.AppendUint64(result, bits)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Unpacks the values of the current format from the start of the specified
+// bytes, which must contain at least as many bytes as the size of the format
+func (s *structFormat) unpack(bytes []byte) list.List[any] {
+	result := list.NewListCap[any](int64(s.numValues()))
+	pos := 0
+	for _, field := range s.fields {
+		switch field.code {
+		case 'x':
+			pos += field.count
+			continue
+		case 's':
+			result.Add(NewLoxStringQuote(string(bytes[pos : pos+field.count])))
+			pos += field.count
+			continue
+		}
+		for range field.count {
+			switch field.code {
+			case 'c':
+				result.Add(NewLoxStringQuote(string(bytes[pos : pos+1])))
+			case '?':
+				result.Add(bytes[pos] != 0)
+			case 'b':
+				result.Add(int64(int8(bytes[pos])))
+			case 'B':
+				result.Add(int64(bytes[pos]))
+			case 'h':
+				result.Add(int64(int16(s.order.Uint16(bytes[pos:]))))
+			case 'H':
+				result.Add(int64(s.order.Uint16(bytes[pos:])))
+			case 'i', 'l':
+				result.Add(int64(int32(s.order.Uint32(bytes[pos:]))))
+			case 'I', 'L':
+				result.Add(int64(s.order.Uint32(bytes[pos:])))
+			case 'q':
+				result.Add(int64(s.order.Uint64(bytes[pos:])))
+			case 'Q':
+				num := s.order.Uint64(bytes[pos:])
+				if num > math.MaxInt64 {
+					result.Add(new(big.Int).SetUint64(num))
+				} else {
+					result.Add(int64(num))
+				}
+			case 'f':
+				result.Add(float64(math.Float32frombits(s.order.Uint32(bytes[pos:]))))
+			case 'd':
+				result.Add(math.Float64frombits(s.order.Uint64(bytes[pos:])))
+			}
+			pos += structCodeSizes[field.code]
+		}
+	}
+	return result
+}
@@ -0,0 +1,183 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineStructFuncs() {
+	className := "struct"
+	structClass := NewLoxClass(className, nil, false)
+	structFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native struct fn %v at %p>", name, &s)
+		}
+		structClass.classProperties[name] = s
+	}
+	formatArg := func(callToken *token.Token, name string, arg any) (*structFormat, error) {
+		formatStr, ok := arg.(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("First argument to 'struct.%v' must be a string.", name))
+		}
+		format, err := parseStructFormat(formatStr.str)
+		if err != nil {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken, err.Error())
+		}
+		return format, nil
+	}
+	bufferArg := func(callToken *token.Token, name string, arg any) (*LoxBuffer, error) {
+		buffer, ok := arg.(*LoxBuffer)
+		if !ok {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Second argument to 'struct.%v' must be a buffer.", name))
+		}
+		return buffer, nil
+	}
+	offsetArg := func(callToken *token.Token, name string, arg any, format *structFormat, buffer *LoxBuffer) (int, error) {
+		offset, ok := arg.(int64)
+		if !ok {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Third argument to 'struct.%v' must be an integer.", name))
+		}
+		bufferLen := int64(len(buffer.elements))
+		if offset < 0 {
+			offset += bufferLen
+		}
+		if offset < 0 || offset+int64(format.size) > bufferLen {
+			return 0, loxerror.RuntimeErrorKind(loxerror.ValueError, callToken,
+				fmt.Sprintf("'struct.%v' requires %v bytes at offset %v, but the buffer has a length of %v.",
+					name, format.size, offset, bufferLen))
+		}
+		return int(offset), nil
+	}
+
+	structFunc("calcsize", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'struct.calcsize' must be a string.")
+		}
+		format, err := formatArg(in.callToken, "calcsize", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return int64(format.size), nil
+	})
+	structFunc("pack", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) < 1 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Expected at least 1 argument but got 0.")
+		}
+		format, err := formatArg(in.callToken, "pack", args[0])
+		if err != nil {
+			return nil, err
+		}
+		if limitErr := checkSizeLimit(in.callToken, int64(format.size)); limitErr != nil {
+			return nil, limitErr
+		}
+		bytes, err := format.pack(in.callToken, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxBufferFromBytes(bytes), nil
+	})
+	structFunc("packInto", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) < 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected at least 3 arguments but got %v.", len(args)))
+		}
+		format, err := formatArg(in.callToken, "packInto", args[0])
+		if err != nil {
+			return nil, err
+		}
+		buffer, err := bufferArg(in.callToken, "packInto", args[1])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := offsetArg(in.callToken, "packInto", args[2], format, buffer)
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := format.pack(in.callToken, args[3:])
+		if err != nil {
+			return nil, err
+		}
+		for index, b := range bytes {
+			buffer.elements[offset+index] = int64(b)
+		}
+		return nil, nil
+	})
+	structFunc("unpack", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		format, err := formatArg(in.callToken, "unpack", args[0])
+		if err != nil {
+			return nil, err
+		}
+		buffer, err := bufferArg(in.callToken, "unpack", args[1])
+		if err != nil {
+			return nil, err
+		}
+		if len(buffer.elements) != format.size {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("'struct.unpack' requires a buffer of length %v but got a buffer of length %v.",
+					format.size, len(buffer.elements)))
+		}
+		return NewLoxList(format.unpack(buffer.bytes())), nil
+	})
+	structFunc("unpackAll", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		format, err := formatArg(in.callToken, "unpackAll", args[0])
+		if err != nil {
+			return nil, err
+		}
+		buffer, err := bufferArg(in.callToken, "unpackAll", args[1])
+		if err != nil {
+			return nil, err
+		}
+		if format.size == 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"'struct.unpackAll' cannot be used with a format of size 0.")
+		}
+		if len(buffer.elements)%format.size != 0 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("'struct.unpackAll' requires a buffer whose length is a multiple of %v but got a buffer of length %v.",
+					format.size, len(buffer.elements)))
+		}
+		bytes := buffer.bytes()
+		records := list.NewListCap[any](int64(len(bytes) / format.size))
+		for pos := 0; pos < len(bytes); pos += format.size {
+			records.Add(NewLoxList(format.unpack(bytes[pos:])))
+		}
+		return NewLoxList(records), nil
+	})
+	structFunc("unpackFrom", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		format, err := formatArg(in.callToken, "unpackFrom", args[0])
+		if err != nil {
+			return nil, err
+		}
+		buffer, err := bufferArg(in.callToken, "unpackFrom", args[1])
+		if err != nil {
+			return nil, err
+		}
+		var offsetValue any = int64(0)
+		if argsLen == 3 {
+			offsetValue = args[2]
+		}
+		offset, err := offsetArg(in.callToken, "unpackFrom", offsetValue, format, buffer)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxList(format.unpack(buffer.bytes()[offset:])), nil
+	})
+
+	i.globals.Define(className, structClass)
+}
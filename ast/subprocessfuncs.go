@@ -0,0 +1,441 @@
+package ast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"github.com/AlanLuu/lox/util"
+)
+
+// The options that can be passed to subprocess.run and subprocess.spawn,
+// where each stream is either a string such as "pipe" or a file object
+type subprocessOptions struct {
+	cwd      string
+	env      []string
+	clearEnv bool
+	input    []byte
+	hasInput bool
+	stdin    any
+	stdout   any
+	stderr   any
+	timeout  time.Duration
+	check    bool
+	binary   bool
+}
+
+// Returns the command and arguments specified by the first argument to
+// subprocess.run or subprocess.spawn, where a string is run by the shell
+func subprocessArgs(callToken *token.Token, funcName string, value any) ([]string, error) {
+	switch value := value.(type) {
+	case *LoxString:
+		if util.IsWindows() {
+			return []string{"cmd", "/c", value.str}, nil
+		}
+		return []string{"sh", "-c", value.str}, nil
+	case *LoxList:
+		if len(value.elements) == 0 {
+			return nil, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("List argument to '%v' must not be empty.", funcName))
+		}
+		cmdArgs := make([]string, 0, len(value.elements))
+		for _, element := range value.elements {
+			loxStr, ok := element.(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(callToken,
+					fmt.Sprintf("List argument to '%v' must only have strings.", funcName))
+			}
+			cmdArgs = append(cmdArgs, loxStr.str)
+		}
+		return cmdArgs, nil
+	}
+	return nil, loxerror.RuntimeError(callToken,
+		fmt.Sprintf("First argument to '%v' must be a list or string.", funcName))
+}
+
+// Returns the contents of a string or buffer as bytes
+func subprocessBytes(value any) ([]byte, bool) {
+	switch value := value.(type) {
+	case *LoxString:
+		return []byte(value.str), true
+	case *LoxBuffer:
+		return value.bytes(), true
+	}
+	return nil, false
+}
+
+// Returns the captured output of a subprocess as a string, or as a buffer
+// if the subprocess is in binary mode
+func subprocessOutput(data []byte, binary bool) any {
+	if binary {
+		return NewLoxBufferFromBytes(data)
+	}
+	return NewLoxStringQuote(string(data))
+}
+
+// Returns a duration from a number of seconds or a duration object
+func subprocessTimeout(value any) (time.Duration, bool) {
+	switch value := value.(type) {
+	case int64:
+		return time.Duration(value) * time.Second, true
+	case float64:
+		return time.Duration(value * float64(time.Second)), true
+	case *LoxDuration:
+		return value.duration, true
+	}
+	return 0, false
+}
+
+// Returns the environment variables in a dictionary of strings to strings
+// in the form "key=value"
+func subprocessEnv(value any) ([]string, bool) {
+	envDict, ok := value.(*LoxDict)
+	if !ok {
+		return nil, false
+	}
+	env := make([]string, 0, len(envDict.indexes))
+	it := envDict.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		envKey, keyOk := pair[0].(*LoxString)
+		envValue, valueOk := pair[1].(*LoxString)
+		if !keyOk || !valueOk {
+			return nil, false
+		}
+		env = append(env, envKey.str+"="+envValue.str)
+	}
+	return env, true
+}
+
+// Parses the dictionary of options passed to subprocess.run or
+// subprocess.spawn, where isRun is true for subprocess.run
+func parseSubprocessOptions(callToken *token.Token, funcName string, isRun bool, args list.List[any]) (*subprocessOptions, error) {
+	options := &subprocessOptions{
+		stdin:   "inherit",
+		stdout:  "pipe",
+		stderr:  "pipe",
+		timeout: -1,
+	}
+	if !isRun {
+		options.stdin = "pipe"
+	}
+	if len(args) < 2 {
+		return options, nil
+	}
+	optionsDict, ok := args[1].(*LoxDict)
+	if !ok {
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Second argument to '%v' must be a dictionary.", funcName))
+	}
+	optionErr := func(key string, theType string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Option '%v' in '%v' must be %v.", key, funcName, theType))
+	}
+	streamOption := func(key string, value any, allowed ...string) (any, error) {
+		switch value := value.(type) {
+		case *LoxFile:
+			return value, nil
+		case *LoxString:
+			for _, name := range allowed {
+				if value.str == name {
+					return value.str, nil
+				}
+			}
+		}
+		quoted := make([]string, 0, len(allowed))
+		for _, name := range allowed {
+			quoted = append(quoted, "'"+name+"'")
+		}
+		return nil, optionErr(key, "a file or one of "+strings.Join(quoted, ", "))
+	}
+	err := forEachOption(optionsDict, func(key string, value any) error {
+		var err error
+		switch key {
+		case "check", "input", "timeout":
+			//These options only apply to processes that are waited on
+			//immediately
+			if !isRun {
+				return loxerror.RuntimeError(callToken,
+					fmt.Sprintf("Unknown option '%v' in '%v'.", key, funcName))
+			}
+		}
+		switch key {
+		case "binary":
+			binary, ok := value.(bool)
+			if !ok {
+				return optionErr(key, "a boolean")
+			}
+			options.binary = binary
+		case "check":
+			check, ok := value.(bool)
+			if !ok {
+				return optionErr(key, "a boolean")
+			}
+			options.check = check
+		case "clearEnv":
+			clearEnv, ok := value.(bool)
+			if !ok {
+				return optionErr(key, "a boolean")
+			}
+			options.clearEnv = clearEnv
+		case "cwd":
+			cwd, ok := value.(*LoxString)
+			if !ok {
+				return optionErr(key, "a string")
+			}
+			options.cwd = cwd.str
+		case "env":
+			env, ok := subprocessEnv(value)
+			if !ok {
+				return optionErr(key, "a dictionary of strings to strings")
+			}
+			options.env = env
+		case "input":
+			input, ok := subprocessBytes(value)
+			if !ok {
+				return optionErr(key, "a string or buffer")
+			}
+			options.input = input
+			options.hasInput = true
+		case "stderr":
+			options.stderr, err = streamOption(key, value, "pipe", "inherit", "devnull", "stdout")
+		case "stdin":
+			if isRun {
+				options.stdin, err = streamOption(key, value, "inherit", "devnull")
+			} else {
+				options.stdin, err = streamOption(key, value, "pipe", "inherit", "devnull")
+			}
+		case "stdout":
+			options.stdout, err = streamOption(key, value, "pipe", "inherit", "devnull")
+		case "timeout":
+			timeout, ok := subprocessTimeout(value)
+			if !ok {
+				return optionErr(key, "a number or duration")
+			}
+			if timeout < 0 {
+				return loxerror.RuntimeError(callToken,
+					fmt.Sprintf("Option '%v' in '%v' cannot be negative.", key, funcName))
+			}
+			options.timeout = timeout
+		default:
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Unknown option '%v' in '%v'.", key, funcName))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// Returns the environment of a subprocess, or nil if the subprocess
+// inherits the environment of the current process unchanged
+func (o *subprocessOptions) environ() []string {
+	if o.env == nil && !o.clearEnv {
+		return nil
+	}
+	env := []string{}
+	if !o.clearEnv {
+		env = os.Environ()
+	}
+	return append(env, o.env...)
+}
+
+// Returns the file that a subprocess stream that isn't a pipe refers to,
+// where nil refers to the null device
+func (o *subprocessOptions) streamFile(stream any, std *os.File) *os.File {
+	switch stream := stream.(type) {
+	case *LoxFile:
+		return stream.file
+	case string:
+		if stream == "inherit" {
+			return std
+		}
+	}
+	return nil
+}
+
+func (i *Interpreter) defineSubprocessFuncs() {
+	className := "subprocess"
+	subprocessClass := NewLoxClass(className, nil, false)
+	subprocessFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native subprocess fn %v at %p>", name, &s)
+		}
+		subprocessClass.classProperties[name] = s
+	}
+	argsLenErr := func(callToken *token.Token, argsLen int) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+	}
+
+	subprocessFunc("run", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argsLenErr(in.callToken, argsLen)
+		}
+		cmdArgs, err := subprocessArgs(in.callToken, "subprocess.run", args[0])
+		if err != nil {
+			return nil, err
+		}
+		options, err := parseSubprocessOptions(in.callToken, "subprocess.run", true, args)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx := context.Background()
+		if options.timeout >= 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, options.timeout)
+			defer cancel()
+		}
+		cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
+		cmd.Dir = options.cwd
+		cmd.Env = options.environ()
+		cmd.WaitDelay = time.Second
+		if options.hasInput {
+			cmd.Stdin = bytes.NewReader(options.input)
+		} else if stdin := options.streamFile(options.stdin, os.Stdin); stdin != nil {
+			cmd.Stdin = stdin
+		}
+
+		var stdout, stderr *bytes.Buffer
+		if options.stdout == "pipe" {
+			stdout = &bytes.Buffer{}
+			cmd.Stdout = stdout
+		} else if file := options.streamFile(options.stdout, os.Stdout); file != nil {
+			cmd.Stdout = file
+		}
+		switch {
+		case options.stderr == "stdout":
+			cmd.Stderr = cmd.Stdout
+		case options.stderr == "pipe":
+			stderr = &bytes.Buffer{}
+			cmd.Stderr = stderr
+		default:
+			if file := options.streamFile(options.stderr, os.Stderr); file != nil {
+				cmd.Stderr = file
+			}
+		}
+
+		//A non-nil process state means that the process ran, even if it
+		//exited unsuccessfully
+		if err := cmd.Run(); err != nil && cmd.ProcessState == nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		result := NewLoxSubprocessResult(cmdArgs, cmd.ProcessState)
+		result.timedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+		if stdout != nil {
+			result.stdout = subprocessOutput(stdout.Bytes(), options.binary)
+		}
+		if stderr != nil {
+			result.stderr = subprocessOutput(stderr.Bytes(), options.binary)
+		}
+		if options.check {
+			if message := result.failure(); message != "" {
+				return nil, loxerror.RuntimeError(in.callToken, message)
+			}
+		}
+		return result, nil
+	})
+	subprocessFunc("spawn", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argsLenErr(in.callToken, argsLen)
+		}
+		cmdArgs, err := subprocessArgs(in.callToken, "subprocess.spawn", args[0])
+		if err != nil {
+			return nil, err
+		}
+		options, err := parseSubprocessOptions(in.callToken, "subprocess.spawn", false, args)
+		if err != nil {
+			return nil, err
+		}
+		subprocess, err := spawnLoxSubprocess(cmdArgs, options)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return subprocess, nil
+	})
+
+	subprocessFunc("spawnPty", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, argsLenErr(in.callToken, argsLen)
+		}
+		cmdArgs, err := subprocessArgs(in.callToken, "subprocess.spawnPty", args[0])
+		if err != nil {
+			return nil, err
+		}
+		options := &subprocessOptions{}
+		rows, cols := int64(24), int64(80)
+		if argsLen == 2 {
+			optionsDict, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'subprocess.spawnPty' must be a dictionary.")
+			}
+			optionErr := func(key string, theType string) error {
+				return loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Option '%v' in '%v' must be %v.", key, "subprocess.spawnPty", theType))
+			}
+			err := forEachOption(optionsDict, func(key string, value any) error {
+				switch key {
+				case "clearEnv":
+					clearEnv, ok := value.(bool)
+					if !ok {
+						return optionErr(key, "a boolean")
+					}
+					options.clearEnv = clearEnv
+				case "cols", "rows":
+					size, ok := value.(int64)
+					if !ok || size <= 0 || size > 0xffff {
+						return optionErr(key, "an integer between 1 and 65535")
+					}
+					if key == "cols" {
+						cols = size
+					} else {
+						rows = size
+					}
+				case "cwd":
+					cwd, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(key, "a string")
+					}
+					options.cwd = cwd.str
+				case "env":
+					env, ok := subprocessEnv(value)
+					if !ok {
+						return optionErr(key, "a dictionary of strings to strings")
+					}
+					options.env = env
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown option '%v' in '%v'.", key, "subprocess.spawnPty"))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		ptyProcess, err := spawnLoxPty(cmdArgs, options.cwd, options.environ(), uint16(rows), uint16(cols))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return ptyProcess, nil
+	})
+	i.globals.Define(className, subprocessClass)
+}
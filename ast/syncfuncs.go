@@ -0,0 +1,49 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+func (i *Interpreter) defineSyncFuncs() {
+	className := "sync"
+	syncClass := NewLoxClass(className, nil, false)
+	syncFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native sync fn %v at %p>", name, &s)
+		}
+		syncClass.classProperties[name] = s
+	}
+
+	syncFunc("atomicInt", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch len(args) {
+		case 0:
+			return NewLoxAtomicInt(0), nil
+		case 1:
+			value, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'sync.atomicInt' must be an integer.")
+			}
+			return NewLoxAtomicInt(value), nil
+		}
+		return nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+	})
+	syncFunc("mutex", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxMutex(), nil
+	})
+	syncFunc("rwMutex", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxRWMutex(), nil
+	})
+	syncFunc("waitGroup", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return NewLoxWaitGroup(), nil
+	})
+
+	i.globals.Define(className, syncClass)
+}
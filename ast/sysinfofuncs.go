@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/sysinfo"
+)
+
+func (i *Interpreter) defineSysInfoFuncs() {
+	className := "sysinfo"
+	sysInfoClass := NewLoxClass(className, nil, false)
+	sysInfoFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native sysinfo fn %v at %p>", name, &s)
+		}
+		sysInfoClass.classProperties[name] = s
+	}
+
+	setStr := func(dict *LoxDict, key string, value any) {
+		dict.setKeyValue(NewLoxString(key, '\''), value)
+	}
+	usageDict := func(total uint64, free uint64, available uint64, unused uint64) *LoxDict {
+		dict := EmptyLoxDict()
+		setStr(dict, "total", uintToLoxValue(total))
+		setStr(dict, "free", uintToLoxValue(free))
+		setStr(dict, "available", uintToLoxValue(available))
+		used := uint64(0)
+		if total > unused {
+			used = total - unused
+		}
+		setStr(dict, "used", uintToLoxValue(used))
+		return dict
+	}
+
+	sysInfoFunc("bootTime", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		bootTime, err := sysinfo.BootTime()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxDate(bootTime), nil
+	})
+	sysInfoFunc("cpuCount", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return int64(runtime.NumCPU()), nil
+	})
+	sysInfoFunc("diskUsage", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if _, ok := args[0].(*LoxString); !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'sysinfo.diskUsage' must be a string.")
+		}
+		disk, err := sysinfo.DiskUsage(args[0].(*LoxString).str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return usageDict(disk.Total, disk.Free, disk.Available, disk.Free), nil
+	})
+	sysInfoFunc("loadAverage", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		loads, err := sysinfo.LoadAverage()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxList(list.List[any]{loads[0], loads[1], loads[2]}), nil
+	})
+	sysInfoFunc("memory", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		memory, err := sysinfo.ReadMemory()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return usageDict(memory.Total, memory.Free, memory.Available, memory.Available), nil
+	})
+	sysInfoFunc("rss", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		pid := os.Getpid()
+		argsLen := len(args)
+		switch argsLen {
+		case 0:
+		case 1:
+			pidArg, ok := args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'sysinfo.rss' must be an integer.")
+			}
+			pid = int(pidArg)
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", argsLen))
+		}
+		rss, err := sysinfo.ProcessRSS(pid)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return uintToLoxValue(rss), nil
+	})
+	sysInfoFunc("sensors", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		sensors, err := sysinfo.ReadSensors()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		temperatures := list.NewListCap[any](int64(len(sensors.Temperatures)))
+		for _, temperature := range sensors.Temperatures {
+			dict := EmptyLoxDict()
+			setStr(dict, "name", NewLoxStringQuote(temperature.Name))
+			setStr(dict, "label", NewLoxStringQuote(temperature.Label))
+			setStr(dict, "celsius", temperature.Celsius)
+			temperatures.Add(dict)
+		}
+
+		fans := list.NewListCap[any](int64(len(sensors.Fans)))
+		for _, fan := range sensors.Fans {
+			dict := EmptyLoxDict()
+			setStr(dict, "name", NewLoxStringQuote(fan.Name))
+			setStr(dict, "label", NewLoxStringQuote(fan.Label))
+			setStr(dict, "rpm", fan.RPM)
+			fans.Add(dict)
+		}
+
+		batteries := list.NewListCap[any](int64(len(sensors.Batteries)))
+		for _, battery := range sensors.Batteries {
+			dict := EmptyLoxDict()
+			setStr(dict, "name", NewLoxStringQuote(battery.Name))
+			setStr(dict, "status", NewLoxStringQuote(battery.Status))
+			setStr(dict, "percent", battery.Percent)
+			if battery.Health >= 0 {
+				setStr(dict, "health", battery.Health)
+			} else {
+				setStr(dict, "health", nil)
+			}
+			batteries.Add(dict)
+		}
+
+		result := EmptyLoxDict()
+		setStr(result, "temperatures", NewLoxList(temperatures))
+		setStr(result, "fans", NewLoxList(fans))
+		setStr(result, "batteries", NewLoxList(batteries))
+		return result, nil
+	})
+	sysInfoFunc("uptime", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		uptime, err := sysinfo.Uptime()
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxDuration(uptime), nil
+	})
+
+	i.globals.Define(className, sysInfoClass)
+}
@@ -0,0 +1,247 @@
+package ast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+// Checks the arguments of a function that spawns a task, which take the form
+// (callback, [arg1, arg2, ..., argN]), and returns the callback along with the
+// arguments to call it with
+func taskSpawnArgs(in *Interpreter, fnName string, args list.List[any]) (LoxCallable, list.List[any], error) {
+	argsLen := len(args)
+	if argsLen == 0 {
+		return nil, nil, loxerror.RuntimeError(in.callToken,
+			"Expected at least 1 argument but got 0.")
+	}
+	callable, ok := args[0].(LoxCallable)
+	if !ok {
+		return nil, nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("First argument to '%v' must be a function.", fnName))
+	}
+	callArgs := list.NewListCap[any](int64(argsLen - 1))
+	for _, arg := range args[1:] {
+		callArgs.Add(arg)
+	}
+	arity := callable.arity()
+	if arity >= 0 && len(callArgs) != arity {
+		return nil, nil, loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected %v arguments but got %v.", arity, len(callArgs)))
+	}
+	if builtin, ok := callable.(LoxBuiltInProtoCallable); ok {
+		callArgs.AddAt(0, builtin.instance)
+	}
+	return callable, callArgs, nil
+}
+
+// Waits for all of the specified tasks to finish and returns a list of
+// their results in the same order, or the first error in that order if any
+// of the tasks threw an error
+func awaitTasks(tasks []*LoxTask) (any, error) {
+	results := list.NewListCap[any](int64(len(tasks)))
+	var firstErr error
+	for _, task := range tasks {
+		result, err := task.await()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		results.Add(result)
+	}
+	if firstErr != nil {
+		results.Clear()
+		return nil, firstErr
+	}
+	return NewLoxList(results), nil
+}
+
+func (i *Interpreter) defineTaskFuncs() {
+	className := "task"
+	taskClass := NewLoxClass(className, nil, false)
+	taskFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native task fn %v at %p>", name, &s)
+		}
+		taskClass.classProperties[name] = s
+	}
+	tasksArg := func(in *Interpreter, fnName string, arg any) ([]*LoxTask, error) {
+		loxList, ok := arg.(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Argument to 'task.%v' must be a list.", fnName))
+		}
+		tasks := make([]*LoxTask, 0, len(loxList.elements))
+		for _, element := range loxList.elements {
+			task, ok := element.(*LoxTask)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("All elements in list argument to 'task.%v' must be tasks.", fnName))
+			}
+			tasks = append(tasks, task)
+		}
+		return tasks, nil
+	}
+
+	taskFunc("all", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		tasks, err := tasksArg(in, "all", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return awaitTasks(tasks)
+	})
+	taskFunc("channel", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var capacity int64 = 0
+		switch len(args) {
+		case 0:
+		case 1:
+			var ok bool
+			capacity, ok = args[0].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'task.channel' must be an integer.")
+			}
+			if capacity < 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Argument to 'task.channel' cannot be negative.")
+			}
+		default:
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+		}
+		return NewLoxChannel(capacity), nil
+	})
+	taskFunc("map", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		iterable, ok := args[0].(interfaces.Iterable)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'task.map' must be an iterable.")
+		}
+		callback, ok := args[1].(*LoxFunction)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'task.map' must be a function.")
+		}
+		var limit int64 = 0
+		if argsLen == 3 {
+			limit, ok = args[2].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'task.map' must be an integer.")
+			}
+			if limit <= 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'task.map' must be at least 1.")
+			}
+		}
+
+		var semaphore chan struct{}
+		if limit > 0 {
+			semaphore = make(chan struct{}, limit)
+		}
+		tasks := []*LoxTask{}
+		it := iterable.Iterator()
+		for index := int64(0); it.HasNext(); index++ {
+			argList := getArgList(callback, 2)
+			argList[0] = it.Next()
+			argList[1] = index
+			if semaphore != nil {
+				semaphore <- struct{}{}
+			}
+			task := NewLoxTask(in, callback, argList, nil)
+			if semaphore != nil {
+				go func() {
+					<-task.done
+					<-semaphore
+				}()
+			}
+			tasks = append(tasks, task)
+		}
+		if err := iteratorErr(it); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+
+		return awaitTasks(tasks)
+	})
+	taskFunc("race", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		tasks, err := tasksArg(in, "race", args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(tasks) == 0 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"List argument to 'task.race' cannot be empty.")
+		}
+		finished := make(chan *LoxTask, len(tasks))
+		for _, task := range tasks {
+			go func(task *LoxTask) {
+				<-task.done
+				finished <- task
+			}(task)
+		}
+		return (<-finished).await()
+	})
+	taskFunc("select", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		loxList, ok := args[0].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'task.select' must be a list.")
+		}
+		channels := make([]*LoxChannel, 0, len(loxList.elements))
+		for _, element := range loxList.elements {
+			channel, ok := element.(*LoxChannel)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"All elements in list argument to 'task.select' must be channels.")
+			}
+			channels = append(channels, channel)
+		}
+		var timeout time.Duration = -1
+		if argsLen == 2 {
+			switch arg := args[1].(type) {
+			case int64:
+				timeout = time.Duration(arg) * time.Second
+			case float64:
+				timeout = time.Duration(arg * float64(time.Second))
+			case *LoxDuration:
+				timeout = arg.duration
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'task.select' must be an integer, float, or duration.")
+			}
+			if timeout < 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'task.select' cannot be negative.")
+			}
+		}
+		channel, value := selectChannels(channels, timeout)
+		if channel == nil {
+			return nil, nil
+		}
+		return NewLoxList(list.List[any]{channel, value}), nil
+	})
+	taskFunc("spawn", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		callable, callArgs, err := taskSpawnArgs(in, "task.spawn", args)
+		if err != nil {
+			return nil, err
+		}
+		return NewLoxTask(in, callable, callArgs, nil), nil
+	})
+
+	i.globals.Define(className, taskClass)
+}
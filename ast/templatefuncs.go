@@ -0,0 +1,64 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+func (i *Interpreter) defineTemplateFuncs() {
+	className := "template"
+	templateClass := NewLoxClass(className, nil, false)
+	templateFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native template fn %v at %p>", name, &s)
+		}
+		templateClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'template.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	compile := func(name string, isHTML bool) func(*Interpreter, list.List[any]) (any, error) {
+		return func(in *Interpreter, args list.List[any]) (any, error) {
+			if loxStr, ok := args[0].(*LoxString); ok {
+				tmpl, err := NewLoxTemplate(loxStr.str, isHTML)
+				if err != nil {
+					return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+				}
+				return tmpl, nil
+			}
+			return argMustBeType(in.callToken, name, "string")
+		}
+	}
+	render := func(name string, isHTML bool) func(*Interpreter, list.List[any]) (any, error) {
+		return func(in *Interpreter, args list.List[any]) (any, error) {
+			loxStr, ok := args[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'template.%v' must be a string.", name))
+			}
+			tmpl, err := NewLoxTemplate(loxStr.str, isHTML)
+			if err != nil {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken, err.Error())
+			}
+			result, err := tmpl.render("", args[1])
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxStringQuote(result), nil
+		}
+	}
+
+	templateFunc("compile", 1, compile("compile", false))
+	templateFunc("compileHTML", 1, compile("compileHTML", true))
+	templateFunc("render", 2, render("render", false))
+	templateFunc("renderHTML", 2, render("renderHTML", true))
+
+	i.globals.Define(className, templateClass)
+}
@@ -0,0 +1,322 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+	"golang.org/x/term"
+)
+
+var termStyleCodes = map[string]int{
+	"bold":          1,
+	"dim":           2,
+	"italic":        3,
+	"underline":     4,
+	"blink":         5,
+	"inverse":       7,
+	"hidden":        8,
+	"strikethrough": 9,
+}
+
+var termColorNames = []string{
+	"black",
+	"red",
+	"green",
+	"yellow",
+	"blue",
+	"magenta",
+	"cyan",
+	"white",
+}
+
+// Matches CSI escape sequences, such as color and cursor movement codes,
+// and OSC escape sequences, such as hyperlinks and window titles
+var termEscapeRegex = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+var (
+	//The state of the terminal before raw mode was enabled, which is nil
+	//if raw mode isn't enabled
+	termRawState    *term.State
+	termCursorShown = true
+)
+
+// Returns the SGR parameters for the specified named color, such as "red",
+// "brightRed", or "gray", as a foreground or background color
+func termNamedColor(name string, background bool) (string, bool) {
+	base := 30
+	if background {
+		base = 40
+	}
+	if name == "gray" || name == "grey" {
+		return strconv.Itoa(base + 60), true
+	}
+	if after, ok := strings.CutPrefix(name, "bright"); ok && after != "" {
+		base += 60
+		name = strings.ToLower(after[:1]) + after[1:]
+	}
+	for index, colorName := range termColorNames {
+		if name == colorName {
+			return strconv.Itoa(base + index), true
+		}
+	}
+	return "", false
+}
+
+// Returns the SGR parameters for the specified style name, which is either
+// a text style such as "bold", a foreground color such as "red", or a
+// background color such as "bgRed"
+func termStyleCode(name string) (string, bool) {
+	if code, ok := termStyleCodes[name]; ok {
+		return strconv.Itoa(code), true
+	}
+	if after, ok := strings.CutPrefix(name, "bg"); ok && after != "" {
+		return termNamedColor(strings.ToLower(after[:1])+after[1:], true)
+	}
+	return termNamedColor(name, false)
+}
+
+// Returns the SGR parameters for a color that is either a color name, an
+// integer from 0 to 255 in the 256-color palette, or a color object
+func termColorCode(value any, background bool) (string, bool) {
+	prefix := "38"
+	if background {
+		prefix = "48"
+	}
+	switch value := value.(type) {
+	case *LoxString:
+		return termNamedColor(value.str, background)
+	case int64:
+		if value < 0 || value > 255 {
+			return "", false
+		}
+		return fmt.Sprintf("%v;5;%v", prefix, value), true
+	case *LoxColor:
+		return fmt.Sprintf("%v;2;%v;%v;%v", prefix, value.r, value.g, value.b), true
+	}
+	return "", false
+}
+
+func termStyled(text string, codes []string) string {
+	if len(codes) == 0 {
+		return text
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"
+}
+
+func termWrite(sequence string) {
+	os.Stdout.WriteString(sequence)
+}
+
+// Restores the terminal to the state that it was in before Lox code
+// enabled raw mode or hid the cursor
+func RestoreTerminal() {
+	if termRawState != nil {
+		term.Restore(int(os.Stdin.Fd()), termRawState)
+		termRawState = nil
+	}
+	if !termCursorShown {
+		termWrite("\x1b[?25h")
+		termCursorShown = true
+	}
+}
+
+func (i *Interpreter) defineTermFuncs() {
+	className := "term"
+	termClass := NewLoxClass(className, nil, false)
+	termFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native term fn %v at %p>", name, &s)
+		}
+		termClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'term.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	escapeFunc := func(name string, sequence string) {
+		termFunc(name, 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+			termWrite(sequence)
+			return nil, nil
+		})
+	}
+	moveFunc := func(name string, direction byte) {
+		termFunc(name, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			amount := int64(1)
+			switch len(args) {
+			case 0:
+			case 1:
+				num, ok := args[0].(int64)
+				if !ok {
+					return argMustBeType(in.callToken, name, "integer")
+				}
+				amount = num
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Expected 0 or 1 arguments but got %v.", len(args)))
+			}
+			if amount > 0 {
+				termWrite(fmt.Sprintf("\x1b[%v%c", amount, direction))
+			}
+			return nil, nil
+		})
+	}
+	colorFunc := func(name string, background bool) {
+		termFunc(name, 2, func(in *Interpreter, args list.List[any]) (any, error) {
+			if _, ok := args[0].(*LoxString); !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'term.%v' must be a string.", name))
+			}
+			code, ok := termColorCode(args[1], background)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("Second argument to 'term.%v' must be a color name, "+
+						"an integer from 0 to 255, or a color object.", name))
+			}
+			return NewLoxStringQuote(termStyled(args[0].(*LoxString).str, []string{code})), nil
+		})
+	}
+	enableRawMode := func(callToken *token.Token) error {
+		if termRawState != nil {
+			return nil
+		}
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			return loxerror.RuntimeError(callToken, err.Error())
+		}
+		termRawState = state
+		return nil
+	}
+
+	colorFunc("bg", true)
+	escapeFunc("clear", "\x1b[2J\x1b[H")
+	escapeFunc("clearLine", "\x1b[2K\r")
+	escapeFunc("clearToEnd", "\x1b[0J")
+	colorFunc("fg", false)
+	termFunc("hideCursor", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		termWrite("\x1b[?25l")
+		termCursorShown = false
+		return nil, nil
+	})
+	termFunc("isRawMode", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return termRawState != nil, nil
+	})
+	termFunc("isTerminal", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return term.IsTerminal(int(os.Stdout.Fd())), nil
+	})
+	moveFunc("moveDown", 'B')
+	moveFunc("moveLeft", 'D')
+	moveFunc("moveRight", 'C')
+	termFunc("moveTo", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		row, rowOk := args[0].(int64)
+		col, colOk := args[1].(int64)
+		if !rowOk || !colOk {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Arguments to 'term.moveTo' must be integers.")
+		}
+		if row < 1 || col < 1 {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				"Arguments to 'term.moveTo' must be at least 1.")
+		}
+		termWrite(fmt.Sprintf("\x1b[%v;%vH", row, col))
+		return nil, nil
+	})
+	moveFunc("moveUp", 'A')
+	termFunc("rawMode", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		enabled, ok := args[0].(bool)
+		if !ok {
+			return argMustBeType(in.callToken, "rawMode", "boolean")
+		}
+		if enabled {
+			return nil, enableRawMode(in.callToken)
+		}
+		if termRawState != nil {
+			err := term.Restore(int(os.Stdin.Fd()), termRawState)
+			termRawState = nil
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		}
+		return nil, nil
+	})
+	termFunc("readKey", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		if termRawState == nil {
+			if err := enableRawMode(in.callToken); err != nil {
+				return nil, err
+			}
+			defer func() {
+				term.Restore(int(os.Stdin.Fd()), termRawState)
+				termRawState = nil
+			}()
+		}
+		//A single read returns all bytes of a key that sends an escape
+		//sequence, such as an arrow key
+		buf := make([]byte, 16)
+		n, err := os.Stdin.Read(buf)
+		if n == 0 {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return nil, nil
+		}
+		return NewLoxStringQuote(string(buf[:n])), nil
+	})
+	escapeFunc("restoreCursor", "\x1b8")
+	escapeFunc("saveCursor", "\x1b7")
+	termFunc("showCursor", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		termWrite("\x1b[?25h")
+		termCursorShown = true
+		return nil, nil
+	})
+	termFunc("size", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxList(list.List[any]{int64(rows), int64(cols)}), nil
+	})
+	termFunc("strip", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			return NewLoxStringQuote(termEscapeRegex.ReplaceAllString(loxStr.str, "")), nil
+		}
+		return argMustBeType(in.callToken, "strip", "string")
+	})
+	termFunc("style", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if len(args) == 0 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Expected at least 1 argument but got 0.")
+		}
+		text, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'term.style' must be a string.")
+		}
+		codes := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			styleStr, ok := arg.(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Style arguments to 'term.style' must be strings.")
+			}
+			code, ok := termStyleCode(styleStr.str)
+			if !ok {
+				return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+					fmt.Sprintf("Unknown style '%v' in 'term.style'.", styleStr.str))
+			}
+			codes = append(codes, code)
+		}
+		return NewLoxStringQuote(termStyled(text.str, codes)), nil
+	})
+
+	i.globals.Define(className, termClass)
+}
@@ -0,0 +1,342 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+type testCase struct {
+	name     string
+	function LoxCallable
+}
+
+// The test cases registered with test.case that haven't been run yet,
+// along with the number of test cases that have passed and failed so far
+type testSuite struct {
+	mutex    sync.Mutex
+	cases    []testCase
+	setup    LoxCallable
+	teardown LoxCallable
+	passed   int
+	failed   int
+}
+
+// Calls the specified callable with the specified arguments and returns
+// its return value
+func callTestFunction(in *Interpreter, callable LoxCallable, args ...any) (any, error) {
+	argList := list.NewListCap[any](int64(len(args) + 1))
+	if builtin, ok := callable.(LoxBuiltInProtoCallable); ok {
+		argList.Add(builtin.instance)
+	}
+	for _, arg := range args {
+		argList.Add(arg)
+	}
+	result, err := callable.call(in, argList)
+	if resultReturn, ok := result.(Return); ok {
+		return resultReturn.FinalValue, nil
+	}
+	return result, err
+}
+
+// Runs the test cases registered with test.case that haven't been run yet,
+// calling the setup function before each one and the teardown function
+// after each one, and writes whether each test case passed or failed to the
+// specified writer. Returns the total number of test cases that have passed
+// and failed in this interpreter, including ones that were run by earlier
+// calls to test.run.
+func (i *Interpreter) RunTests(writer io.Writer) (int, int) {
+	suite := i.tests
+	suite.mutex.Lock()
+	cases := suite.cases
+	suite.cases = nil
+	setup, teardown := suite.setup, suite.teardown
+	suite.mutex.Unlock()
+
+	passed, failed := 0, 0
+	for _, theCase := range cases {
+		var err error
+		if setup != nil {
+			_, err = callTestFunction(i, setup)
+		}
+		if err == nil {
+			_, err = callTestFunction(i, theCase.function)
+			if teardown != nil {
+				_, teardownErr := callTestFunction(i, teardown)
+				if err == nil {
+					err = teardownErr
+				}
+			}
+		}
+		if err == nil {
+			passed++
+			fmt.Fprintf(writer, "PASS %v\n", theCase.name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(writer, "FAIL %v\n", theCase.name)
+		errStr := strings.TrimRight(err.Error()+"\n"+loxerror.Snippet(err), "\n")
+		for _, line := range strings.Split(errStr, "\n") {
+			fmt.Fprintf(writer, "    %v\n", line)
+		}
+	}
+
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+	suite.passed += passed
+	suite.failed += failed
+	return suite.passed, suite.failed
+}
+
+func (i *Interpreter) defineTestFuncs() {
+	className := "test"
+	testClass := NewLoxClass(className, nil, false)
+	testFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native test fn %v at %p>", name, &s)
+		}
+		testClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'test.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+	assertionError := func(callToken *token.Token, message string) (any, error) {
+		return nil, loxerror.RuntimeErrorKind(loxerror.AssertionError, callToken, message)
+	}
+	//Returns the optional message argument of an assertion, or the specified
+	//default message if it wasn't passed
+	assertMessage := func(in *Interpreter, name string, args list.List[any], numArgs int, defaultMsg func() string) (string, error) {
+		argsLen := len(args)
+		switch argsLen {
+		case numArgs:
+			return defaultMsg(), nil
+		case numArgs + 1:
+			if message, ok := args[numArgs].(*LoxString); ok {
+				return message.str, nil
+			}
+			return "", loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Last argument to 'test.%v' must be a string.", name))
+		}
+		return "", loxerror.RuntimeError(in.callToken,
+			fmt.Sprintf("Expected %v or %v arguments but got %v.", numArgs, numArgs+1, argsLen))
+	}
+	//Returns the specified function if it takes no arguments
+	testFunction := func(in *Interpreter, name string, arg any) (LoxCallable, error) {
+		callable, ok := arg.(LoxCallable)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Function argument to 'test.%v' must be a function.", name))
+		}
+		if arity := callable.arity(); arity > 0 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Function argument to 'test.%v' must take 0 arguments.", name))
+		}
+		return callable, nil
+	}
+	isEqual := func(in *Interpreter, left any, right any) bool {
+		result, _ := in.visitBinaryExpr(Binary{
+			Left: Literal{Value: left},
+			Operator: &token.Token{
+				TokenType: token.EQUAL_EQUAL,
+				Lexeme:    "==",
+			},
+			Right: Literal{Value: right},
+		})
+		return result == true
+	}
+	valueStr := func(value any) string {
+		return getResult(value, value, false)
+	}
+
+	suite := &testSuite{}
+	i.tests = suite
+
+	testFunc("assertClose", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		numbers := make([]float64, argsLen)
+		for index, arg := range args {
+			switch arg := arg.(type) {
+			case int64:
+				numbers[index] = float64(arg)
+			case float64:
+				numbers[index] = arg
+			default:
+				return argMustBeType(in.callToken, "assertClose", "integer or float")
+			}
+		}
+		tolerance := 1e-9
+		if argsLen == 3 {
+			tolerance = numbers[2]
+			if tolerance < 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Tolerance argument to 'test.assertClose' cannot be negative.")
+			}
+		}
+		if math.Abs(numbers[0]-numbers[1]) > tolerance {
+			return assertionError(in.callToken, fmt.Sprintf("Expected %v to be within %v of %v.",
+				valueStr(args[0]), valueStr(tolerance), valueStr(args[1])))
+		}
+		return nil, nil
+	})
+	testFunc("assertEqual", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		message, messageErr := assertMessage(in, "assertEqual", args, 2, func() string {
+			return fmt.Sprintf("Expected %v but got %v.", valueStr(args[1]), valueStr(args[0]))
+		})
+		if messageErr != nil {
+			return nil, messageErr
+		}
+		if !isEqual(in, args[0], args[1]) {
+			return assertionError(in.callToken, message)
+		}
+		return nil, nil
+	})
+	testFunc("assertFalse", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		message, messageErr := assertMessage(in, "assertFalse", args, 1, func() string {
+			return fmt.Sprintf("Expected %v to be falsy.", valueStr(args[0]))
+		})
+		if messageErr != nil {
+			return nil, messageErr
+		}
+		if in.isTruthy(args[0]) {
+			return assertionError(in.callToken, message)
+		}
+		return nil, nil
+	})
+	testFunc("assertNotEqual", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		message, messageErr := assertMessage(in, "assertNotEqual", args, 2, func() string {
+			return fmt.Sprintf("Expected a value other than %v.", valueStr(args[1]))
+		})
+		if messageErr != nil {
+			return nil, messageErr
+		}
+		if isEqual(in, args[0], args[1]) {
+			return assertionError(in.callToken, message)
+		}
+		return nil, nil
+	})
+	testFunc("assertThrows", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		callable, callableErr := testFunction(in, "assertThrows", args[0])
+		if callableErr != nil {
+			return nil, callableErr
+		}
+		var errorClass *LoxClass
+		if argsLen == 2 {
+			class, ok := args[1].(*LoxClass)
+			if !ok || !in.isErrorClass(class) {
+				return argMustBeType(in.callToken, "assertThrows", "error class")
+			}
+			errorClass = class
+		}
+		callToken := in.callToken
+		_, err := callTestFunction(in, callable)
+		if err == nil {
+			if errorClass != nil {
+				return assertionError(callToken,
+					fmt.Sprintf("Expected function to throw %v.", errorClass.name))
+			}
+			return assertionError(callToken, "Expected function to throw an error.")
+		}
+		if errorClass != nil {
+			if thrownClass := in.errorClassOf(err); !isSubclassOf(thrownClass, errorClass) {
+				return assertionError(callToken,
+					fmt.Sprintf("Expected function to throw %v but it threw %v instead.",
+						errorClass.name, thrownClass.name))
+			}
+		}
+		return in.caughtErrorValue(err, errorClass != nil), nil
+	})
+	testFunc("assertTrue", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		message, messageErr := assertMessage(in, "assertTrue", args, 1, func() string {
+			return fmt.Sprintf("Expected %v to be truthy.", valueStr(args[0]))
+		})
+		if messageErr != nil {
+			return nil, messageErr
+		}
+		if !in.isTruthy(args[0]) {
+			return assertionError(in.callToken, message)
+		}
+		return nil, nil
+	})
+	testFunc("case", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		name, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'test.case' must be a string.")
+		}
+		callable, callableErr := testFunction(in, "case", args[1])
+		if callableErr != nil {
+			return nil, callableErr
+		}
+		suite.mutex.Lock()
+		suite.cases = append(suite.cases, testCase{name.str, callable})
+		suite.mutex.Unlock()
+		return nil, nil
+	})
+	testFunc("fail", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		message, messageErr := assertMessage(in, "fail", args, 0, func() string {
+			return "Test failed."
+		})
+		if messageErr != nil {
+			return nil, messageErr
+		}
+		return assertionError(in.callToken, message)
+	})
+	testFunc("run", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		suite.mutex.Lock()
+		passedBefore, failedBefore := suite.passed, suite.failed
+		suite.mutex.Unlock()
+		passed, failed := in.RunTests(os.Stdout)
+		fmt.Printf("%v passed, %v failed\n", passed-passedBefore, failed-failedBefore)
+		return failed == failedBefore, nil
+	})
+	testFunc("setup", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var callable LoxCallable
+		if args[0] != nil {
+			var callableErr error
+			callable, callableErr = testFunction(in, "setup", args[0])
+			if callableErr != nil {
+				return nil, callableErr
+			}
+		}
+		suite.mutex.Lock()
+		suite.setup = callable
+		suite.mutex.Unlock()
+		return nil, nil
+	})
+	testFunc("teardown", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var callable LoxCallable
+		if args[0] != nil {
+			var callableErr error
+			callable, callableErr = testFunction(in, "teardown", args[0])
+			if callableErr != nil {
+				return nil, callableErr
+			}
+		}
+		suite.mutex.Lock()
+		suite.teardown = callable
+		suite.mutex.Unlock()
+		return nil, nil
+	})
+
+	i.globals.Define(className, testClass)
+}
@@ -0,0 +1,80 @@
+package ast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Reference point for time.monotonic and time.monotonicNs, which carries a
+// monotonic clock reading that is unaffected by changes to the system clock
+var monotonicStart = time.Now()
+
+func (i *Interpreter) defineTimeFuncs() {
+	className := "time"
+	timeClass := NewLoxClass(className, nil, false)
+	timeFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native time fn %v at %p>", name, &s)
+		}
+		timeClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'time.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	timeFunc("measure", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if callback, ok := args[0].(*LoxFunction); ok {
+			argList := getArgList(callback, 0)
+			start := time.Now()
+			result, resultErr := callback.call(in, argList)
+			elapsed := time.Since(start)
+			if resultErr != nil && result == nil {
+				return nil, resultErr
+			}
+			return NewLoxDuration(elapsed), nil
+		}
+		return argMustBeType(in.callToken, "measure", "function")
+	})
+	timeFunc("monotonic", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return time.Since(monotonicStart).Seconds(), nil
+	})
+	timeFunc("monotonicNs", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		return time.Since(monotonicStart).Nanoseconds(), nil
+	})
+	timeFunc("sleepUntil", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		switch deadline := args[0].(type) {
+		case *LoxDate:
+			//Sleep in bounded steps so that changes to the system clock
+			//while sleeping are taken into account
+			for {
+				wait := time.Until(deadline.date)
+				if wait <= 0 {
+					return nil, nil
+				}
+				time.Sleep(min(wait, time.Second))
+			}
+		case int64:
+			time.Sleep(time.Until(monotonicStart.Add(time.Duration(deadline) * time.Second)))
+			return nil, nil
+		case float64:
+			time.Sleep(time.Until(monotonicStart.Add(time.Duration(deadline * float64(time.Second)))))
+			return nil, nil
+		}
+		return argMustBeType(in.callToken, "sleepUntil", "date, integer, or float")
+	})
+	timeFunc("startStopwatch", 0, func(_ *Interpreter, _ list.List[any]) (any, error) {
+		stopwatch := NewLoxStopwatch()
+		stopwatch.start()
+		return stopwatch, nil
+	})
+
+	i.globals.Define(className, timeClass)
+}
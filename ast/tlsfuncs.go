@@ -0,0 +1,257 @@
+package ast
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+var tlsClientAuthTypes = map[string]tls.ClientAuthType{
+	"none":             tls.NoClientCert,
+	"request":          tls.RequestClientCert,
+	"require":          tls.RequireAnyClientCert,
+	"verifyIfGiven":    tls.VerifyClientCertIfGiven,
+	"requireAndVerify": tls.RequireAndVerifyClientCert,
+}
+
+// Adds the certificates in the specified value, which is a TLS certificate,
+// a buffer of PEM data, or a list of either, to the specified pool
+func tlsAddCertsToPool(pool *x509.CertPool, value any) bool {
+	switch value := value.(type) {
+	case *LoxTLSCertificate:
+		pool.AddCert(value.leaf)
+		return true
+	case *LoxBuffer:
+		return pool.AppendCertsFromPEM(value.bytes())
+	case *LoxList:
+		for _, element := range value.elements {
+			if !tlsAddCertsToPool(pool, element) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (i *Interpreter) defineTLSFuncs() {
+	className := "tls"
+	tlsClass := NewLoxClass(className, nil, false)
+	tlsFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native tls fn %v at %p>", name, &s)
+		}
+		tlsClass.classProperties[name] = s
+	}
+	optionErr := func(callToken *token.Token, fnName string, key string, theType string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Option '%v' in 'tls.%v' must be %v.", key, fnName, theType))
+	}
+	unknownOptionErr := func(callToken *token.Token, fnName string, key string) error {
+		return loxerror.RuntimeError(callToken,
+			fmt.Sprintf("Unknown option '%v' in 'tls.%v'.", key, fnName))
+	}
+	caCertsType := "a TLS certificate, a buffer, or a list of them"
+
+	tlsFunc("certificate", 2, func(in *Interpreter, args list.List[any]) (any, error) {
+		var pemData [2][]byte
+		for index, arg := range args {
+			switch arg := arg.(type) {
+			case *LoxBuffer:
+				pemData[index] = arg.bytes()
+			case *LoxString:
+				bytes, err := os.ReadFile(arg.str)
+				if err != nil {
+					return nil, loxerror.RuntimeError(in.callToken, err.Error())
+				}
+				pemData[index] = bytes
+			default:
+				ordinal := "First"
+				if index == 1 {
+					ordinal = "Second"
+				}
+				return nil, loxerror.RuntimeError(in.callToken,
+					ordinal+" argument to 'tls.certificate' must be a buffer or string.")
+			}
+		}
+		cert, err := NewLoxTLSCertificate(pemData[0], pemData[1])
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return cert, nil
+	})
+	tlsFunc("dial", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		address, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'tls.dial' must be a string.")
+		}
+		config := &tls.Config{}
+		dialer := &net.Dialer{}
+		if argsLen == 2 {
+			options, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'tls.dial' must be a dictionary.")
+			}
+			optionsErr := forEachOption(options, func(key string, value any) error {
+				switch key {
+				case "caCerts":
+					config.RootCAs = x509.NewCertPool()
+					if !tlsAddCertsToPool(config.RootCAs, value) {
+						return optionErr(in.callToken, "dial", key, caCertsType)
+					}
+				case "certificate":
+					cert, ok := value.(*LoxTLSCertificate)
+					if !ok {
+						return optionErr(in.callToken, "dial", key, "a TLS certificate")
+					}
+					config.Certificates = []tls.Certificate{cert.cert}
+				case "insecureSkipVerify":
+					skip, ok := value.(bool)
+					if !ok {
+						return optionErr(in.callToken, "dial", key, "a boolean")
+					}
+					config.InsecureSkipVerify = skip
+				case "serverName":
+					serverName, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(in.callToken, "dial", key, "a string")
+					}
+					config.ServerName = serverName.str
+				case "timeout":
+					switch seconds := value.(type) {
+					case int64:
+						dialer.Timeout = time.Duration(seconds) * time.Second
+					case float64:
+						dialer.Timeout = time.Duration(seconds * float64(time.Second))
+					default:
+						return optionErr(in.callToken, "dial", key, "an integer or float")
+					}
+				default:
+					return unknownOptionErr(in.callToken, "dial", key)
+				}
+				return nil
+			})
+			if optionsErr != nil {
+				return nil, optionsErr
+			}
+		}
+		conn, err := tls.DialWithDialer(dialer, "tcp", address.str, config)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxNetConnection(conn), nil
+	})
+	tlsFunc("selfSigned", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 1 && argsLen != 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 1 or 2 arguments but got %v.", argsLen))
+		}
+		hostsList, ok := args[0].(*LoxList)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'tls.selfSigned' must be a list.")
+		}
+		hosts := make([]string, 0, len(hostsList.elements))
+		for _, element := range hostsList.elements {
+			host, ok := element.(*LoxString)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"List argument to 'tls.selfSigned' must only have strings.")
+			}
+			hosts = append(hosts, host.str)
+		}
+		validDays := int64(365)
+		if argsLen == 2 {
+			validDays, ok = args[1].(int64)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'tls.selfSigned' must be an integer.")
+			}
+			if validDays <= 0 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'tls.selfSigned' must be positive.")
+			}
+		}
+		cert, err := NewLoxTLSSelfSigned(hosts, validDays)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return cert, nil
+	})
+	tlsFunc("wrapServer", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		argsLen := len(args)
+		if argsLen != 2 && argsLen != 3 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 2 or 3 arguments but got %v.", argsLen))
+		}
+		listener, ok := args[0].(*LoxNetListener)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"First argument to 'tls.wrapServer' must be a net listener.")
+		}
+		cert, ok := args[1].(*LoxTLSCertificate)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Second argument to 'tls.wrapServer' must be a TLS certificate.")
+		}
+		config := &tls.Config{Certificates: []tls.Certificate{cert.cert}}
+		if argsLen == 3 {
+			options, ok := args[2].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Third argument to 'tls.wrapServer' must be a dictionary.")
+			}
+			optionsErr := forEachOption(options, func(key string, value any) error {
+				switch key {
+				case "clientAuth":
+					authStr, ok := value.(*LoxString)
+					if !ok {
+						return optionErr(in.callToken, "wrapServer", key, "a string")
+					}
+					authType, ok := tlsClientAuthTypes[authStr.str]
+					if !ok {
+						return loxerror.RuntimeError(in.callToken,
+							fmt.Sprintf("Unknown client authentication type '%v'.", authStr.str))
+					}
+					config.ClientAuth = authType
+				case "clientCAs":
+					config.ClientCAs = x509.NewCertPool()
+					if !tlsAddCertsToPool(config.ClientCAs, value) {
+						return optionErr(in.callToken, "wrapServer", key, caCertsType)
+					}
+				default:
+					return unknownOptionErr(in.callToken, "wrapServer", key)
+				}
+				return nil
+			})
+			if optionsErr != nil {
+				return nil, optionsErr
+			}
+		}
+		if listener.closed {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Cannot wrap a closed net listener in 'tls.wrapServer'.")
+		}
+		return NewLoxNetListener(tls.NewListener(listener.listener, config)), nil
+	})
+
+	i.globals.Define(className, tlsClass)
+}
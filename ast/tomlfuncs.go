@@ -0,0 +1,207 @@
+package ast
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/BurntSushi/toml"
+)
+
+// Converts the specified value decoded from TOML into a Lox value. Datetimes
+// become Date objects, and order maps the path of each key to its position
+// in the document so that tables keep the order of their keys
+func tomlToLox(value any, path toml.Key, order map[string]int) any {
+	switch value := value.(type) {
+	case string:
+		return NewLoxStringQuote(value)
+	case time.Time:
+		return NewLoxDate(value)
+	case []any:
+		elements := list.NewListCap[any](int64(len(value)))
+		for _, element := range value {
+			elements.Add(tomlToLox(element, path, order))
+		}
+		return NewLoxList(elements)
+	case []map[string]any:
+		elements := list.NewListCap[any](int64(len(value)))
+		for _, element := range value {
+			elements.Add(tomlToLox(element, path, order))
+		}
+		return NewLoxList(elements)
+	case map[string]any:
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		position := func(name string) int {
+			if index, ok := order[append(path[:len(path):len(path)], name).String()]; ok {
+				return index
+			}
+			//Keys of tables inside arrays aren't in the metadata
+			return len(order)
+		}
+		slices.SortFunc(names, func(a string, b string) int {
+			return cmp.Or(cmp.Compare(position(a), position(b)), strings.Compare(a, b))
+		})
+		keys := make([]any, len(names))
+		values := make([]any, len(names))
+		for index, name := range names {
+			keys[index] = NewLoxStringQuote(name)
+			values[index] = tomlToLox(value[name], append(path[:len(path):len(path)], name), order)
+		}
+		return NewLoxDictOrdered(keys, values)
+	}
+	return value
+}
+
+// Converts the specified Lox value into a value that can be encoded as TOML
+func tomlFromLox(value any, seen map[any]bool) (any, error) {
+	switch value := value.(type) {
+	case bool, int64, float64:
+		return value, nil
+	case *big.Int:
+		if !value.IsInt64() {
+			return nil, loxerror.Error("Bigint is too large to be serialized as TOML.")
+		}
+		return value.Int64(), nil
+	case *LoxString:
+		return value.str, nil
+	case LoxStringStr:
+		return value.str, nil
+	case *LoxDate:
+		return value.date, nil
+	case *LoxList:
+		if seen[value] {
+			return nil, loxerror.Error("Cannot serialize self-referential list as TOML.")
+		}
+		seen[value] = true
+		defer delete(seen, value)
+		allDicts := len(value.elements) > 0
+		elements := make([]any, 0, len(value.elements))
+		for _, element := range value.elements {
+			if _, ok := element.(*LoxDict); !ok {
+				allDicts = false
+			}
+			converted, err := tomlFromLox(element, seen)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, converted)
+		}
+		if allDicts {
+			tables := make([]map[string]any, 0, len(elements))
+			for _, element := range elements {
+				tables = append(tables, element.(map[string]any))
+			}
+			return tables, nil
+		}
+		return elements, nil
+	case *LoxDict:
+		if seen[value] {
+			return nil, loxerror.Error("Cannot serialize self-referential dictionary as TOML.")
+		}
+		seen[value] = true
+		defer delete(seen, value)
+		table := make(map[string]any, len(value.indexes))
+		it := value.Iterator()
+		for it.HasNext() {
+			pair := it.Next().(*LoxList).elements
+			key, ok := pair[0].(*LoxString)
+			if !ok {
+				return nil, loxerror.Error("TOML table keys must be strings.")
+			}
+			if pair[1] == nil {
+				continue
+			}
+			converted, err := tomlFromLox(pair[1], seen)
+			if err != nil {
+				return nil, err
+			}
+			table[key.str] = converted
+		}
+		return table, nil
+	case nil:
+		return nil, loxerror.Error("Type 'nil' cannot be serialized as TOML.")
+	}
+	return nil, loxerror.Error(
+		fmt.Sprintf("Type '%v' cannot be serialized as TOML.", getType(value)))
+}
+
+func (i *Interpreter) defineTOMLFuncs() {
+	className := "toml"
+	tomlClass := NewLoxClass(className, nil, false)
+	tomlFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native toml fn %v at %p>", name, &s)
+		}
+		tomlClass.classProperties[name] = s
+	}
+	parse := func(in *Interpreter, data string) (any, error) {
+		result := make(map[string]any)
+		metaData, err := toml.Decode(data, &result)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		order := make(map[string]int)
+		for index, key := range metaData.Keys() {
+			if _, ok := order[key.String()]; !ok {
+				order[key.String()] = index
+			}
+		}
+		return tomlToLox(result, nil, order), nil
+	}
+
+	tomlFunc("parse", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		tomlStr, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'toml.parse' must be a string.")
+		}
+		return parse(in, tomlStr.str)
+	})
+	tomlFunc("parseFile", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		path, ok := args[0].(*LoxString)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'toml.parseFile' must be a string.")
+		}
+		if sandboxErr := checkSandbox(in.callToken, "Reading files"); sandboxErr != nil {
+			return nil, sandboxErr
+		}
+		data, err := os.ReadFile(path.str)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return parse(in, string(data))
+	})
+	tomlFunc("stringify", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		dict, ok := args[0].(*LoxDict)
+		if !ok {
+			return nil, loxerror.RuntimeError(in.callToken,
+				"Argument to 'toml.stringify' must be a dictionary.")
+		}
+		table, err := tomlFromLox(dict, make(map[any]bool))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		var builder strings.Builder
+		encoder := toml.NewEncoder(&builder)
+		encoder.Indent = ""
+		if err := encoder.Encode(table); err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxStringQuote(builder.String()), nil
+	})
+
+	i.globals.Define(className, tomlClass)
+}
@@ -1,6 +1,12 @@
 package ast
 
-import "github.com/AlanLuu/lox/list"
+import (
+	"reflect"
+
+	"github.com/AlanLuu/lox/interfaces"
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
 
 func getArgList(callback *LoxFunction, numArgs int) list.List[any] {
 	argList := list.NewListLen[any](int64(numArgs))
@@ -12,3 +18,72 @@ func getArgList(callback *LoxFunction, numArgs int) list.List[any] {
 	}
 	return argList
 }
+
+// Calls the specified function with each key and value of the specified
+// dictionary of options, whose keys must be strings
+func forEachOption(dict *LoxDict, fn func(key string, value any) error) error {
+	it := dict.Iterator()
+	for it.HasNext() {
+		pair := it.Next().(*LoxList).elements
+		key, ok := pair[0].(*LoxString)
+		if !ok {
+			return loxerror.Error("Option names must be strings.")
+		}
+		if err := fn(key.str, pair[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports whether the two specified values are equal, using the Equals
+// method of values that define one, since values such as strings also hold
+// cached state that reflect.DeepEqual would compare
+func valuesEqual(first any, second any) bool {
+	return valuesEqualCompared(first, second, nil)
+}
+
+// A pair of lists or dictionaries that are being compared by
+// valuesEqualCompared
+type valuesEqualPair struct {
+	first  any
+	second any
+}
+
+// Reports whether the two specified values are equal like valuesEqual,
+// where compared holds the pairs of lists and dictionaries that are
+// currently being compared. A pair that is reached again while it's being
+// compared is treated as equal, so that comparing lists and dictionaries
+// that contain themselves doesn't recurse forever
+func valuesEqualCompared(first any, second any, compared map[valuesEqualPair]bool) bool {
+	if first == second {
+		return true
+	}
+	switch first.(type) {
+	case *LoxDict, *LoxList:
+		pair := valuesEqualPair{first, second}
+		if compared[pair] {
+			return true
+		}
+		if compared == nil {
+			compared = make(map[valuesEqualPair]bool)
+		}
+		compared[pair] = true
+		defer delete(compared, pair)
+	}
+	switch first := first.(type) {
+	case *LoxDict:
+		second, ok := second.(*LoxDict)
+		return ok && first.equals(second, compared)
+	case *LoxList:
+		second, ok := second.(*LoxList)
+		return ok && first.equals(second, compared)
+	}
+	if equatable, ok := first.(interfaces.Equatable); ok {
+		return equatable.Equals(second)
+	}
+	if equatable, ok := second.(interfaces.Equatable); ok {
+		return equatable.Equals(first)
+	}
+	return reflect.DeepEqual(first, second)
+}
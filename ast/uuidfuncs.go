@@ -2,6 +2,7 @@ package ast
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/AlanLuu/lox/list"
 	"github.com/AlanLuu/lox/loxerror"
@@ -151,3 +152,84 @@ func (i *Interpreter) defineUUIDFuncs() {
 
 	i.globals.Define(className, uuidClass)
 }
+
+func (i *Interpreter) defineULIDFuncs() {
+	className := "ULID"
+	ulidClass := NewLoxClass(className, nil, false)
+	ulidFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native ULID fn %v at %p>", name, &s)
+		}
+		ulidClass.classProperties[name] = s
+	}
+	argMustBeType := func(callToken *token.Token, name string, theType string) (any, error) {
+		errStr := fmt.Sprintf("Argument to 'ULID.%v' must be a %v.", name, theType)
+		return nil, loxerror.RuntimeError(callToken, errStr)
+	}
+
+	ulidFunc("fromBytes", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if buffer, ok := args[0].(*LoxBuffer); ok {
+			if len(buffer.elements) != 16 {
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("invalid ULID (got %v bytes)", len(buffer.elements)))
+			}
+			return NewLoxULID(ulid(buffer.bytes())), nil
+		}
+		return argMustBeType(in.callToken, "fromBytes", "buffer")
+	})
+	ulidFunc("fromUUID", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxUUID, ok := args[0].(*LoxUUID); ok {
+			return NewLoxULID(ulid(loxUUID.uuid)), nil
+		}
+		return argMustBeType(in.callToken, "fromUUID", "UUID")
+	})
+	ulidFunc("new", 0, func(in *Interpreter, _ list.List[any]) (any, error) {
+		newULID, err := newULIDMonotonic(uint64(time.Now().UnixMilli()))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxULID(newULID), nil
+	})
+	ulidFunc("newAt", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var ms int64
+		switch arg := args[0].(type) {
+		case int64:
+			ms = arg
+		case *LoxDate:
+			ms = arg.date.UnixMilli()
+		default:
+			return argMustBeType(in.callToken, "newAt", "date or integer")
+		}
+		if ms < 0 || ms > ulidMaxTime {
+			return nil, loxerror.RuntimeErrorKind(loxerror.ValueError, in.callToken,
+				fmt.Sprintf("ULID timestamp must be between 0 and %v milliseconds.", int64(ulidMaxTime)))
+		}
+		newULID, err := newULIDAt(uint64(ms))
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		return NewLoxULID(newULID), nil
+	})
+	ulidFunc("parse", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			newULID, err := parseULID(loxStr.str)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxULID(newULID), nil
+		}
+		return argMustBeType(in.callToken, "parse", "string")
+	})
+	ulidFunc("validate", 1, func(in *Interpreter, args list.List[any]) (any, error) {
+		if loxStr, ok := args[0].(*LoxString); ok {
+			_, err := parseULID(loxStr.str)
+			return err == nil, nil
+		}
+		return argMustBeType(in.callToken, "validate", "string")
+	})
+
+	i.globals.Define(className, ulidClass)
+}
@@ -0,0 +1,88 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+func (i *Interpreter) defineWatchFuncs() {
+	className := "watch"
+	watchClass := NewLoxClass(className, nil, false)
+	watchFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native watch fn %v at %p>", name, &s)
+		}
+		watchClass.classProperties[name] = s
+	}
+
+	watchFunc("watcher", -1, func(in *Interpreter, args list.List[any]) (any, error) {
+		var paths []string
+		recursive := false
+		argsLen := len(args)
+		if argsLen > 2 {
+			return nil, loxerror.RuntimeError(in.callToken,
+				fmt.Sprintf("Expected 0, 1, or 2 arguments but got %v.", argsLen))
+		}
+		if argsLen >= 1 {
+			switch arg := args[0].(type) {
+			case *LoxString:
+				paths = append(paths, arg.str)
+			case *LoxList:
+				for _, element := range arg.elements {
+					loxStr, ok := element.(*LoxString)
+					if !ok {
+						return nil, loxerror.RuntimeError(in.callToken,
+							"All elements in list argument to 'watch.watcher' must be strings.")
+					}
+					paths = append(paths, loxStr.str)
+				}
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					"First argument to 'watch.watcher' must be a string or list.")
+			}
+		}
+		if argsLen == 2 {
+			options, ok := args[1].(*LoxDict)
+			if !ok {
+				return nil, loxerror.RuntimeError(in.callToken,
+					"Second argument to 'watch.watcher' must be a dictionary.")
+			}
+			err := forEachOption(options, func(key string, value any) error {
+				switch key {
+				case "recursive":
+					recursiveValue, ok := value.(bool)
+					if !ok {
+						return loxerror.RuntimeError(in.callToken,
+							fmt.Sprintf("Option '%v' in '%v' must be %v.", key, "watch.watcher", "a boolean"))
+					}
+					recursive = recursiveValue
+				default:
+					return loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Unknown option '%v' in '%v'.", key, "watch.watcher"))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		watcher, err := NewLoxWatcher(recursive)
+		if err != nil {
+			return nil, loxerror.RuntimeError(in.callToken, err.Error())
+		}
+		for _, path := range paths {
+			if err := watcher.add(path); err != nil {
+				watcher.close()
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+		}
+		return watcher, nil
+	})
+
+	i.globals.Define(className, watchClass)
+}
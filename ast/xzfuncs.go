@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"io"
+
+	"github.com/ulikunitz/xz"
+)
+
+// The dictionary sizes used by the xz command-line tool for the compression
+// presets 0 through 9
+var xzDictCaps = [...]int{
+	256 << 10,
+	1 << 20,
+	2 << 20,
+	4 << 20,
+	4 << 20,
+	8 << 20,
+	8 << 20,
+	16 << 20,
+	32 << 20,
+	64 << 20,
+}
+
+func (i *Interpreter) defineXzFuncs() {
+	i.defineCompressionClass(compressionFormat{
+		name:         "xz",
+		minLevel:     0,
+		maxLevel:     len(xzDictCaps) - 1,
+		defaultLevel: 6,
+		newReader: func(reader io.Reader) (io.ReadCloser, error) {
+			xzReader, err := xz.NewReader(reader)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(xzReader), nil
+		},
+		newWriter: func(writer io.Writer, level int) (io.WriteCloser, error) {
+			return xz.WriterConfig{DictCap: xzDictCaps[level]}.NewWriter(writer)
+		},
+	})
+}
@@ -0,0 +1,236 @@
+package ast
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/AlanLuu/lox/list"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/token"
+)
+
+// Either zlib-wrapped data or raw DEFLATE data without a header or checksum
+type zlibStreamKind struct {
+	name      string
+	newReader func(reader io.Reader, dict []byte) (io.ReadCloser, error)
+	newWriter func(writer io.Writer, level int, dict []byte) (io.WriteCloser, error)
+}
+
+var zlibWrappedKind = zlibStreamKind{
+	name: "zlib",
+	newReader: func(reader io.Reader, dict []byte) (io.ReadCloser, error) {
+		return zlib.NewReaderDict(reader, dict)
+	},
+	newWriter: func(writer io.Writer, level int, dict []byte) (io.WriteCloser, error) {
+		return zlib.NewWriterLevelDict(writer, level, dict)
+	},
+}
+
+var zlibRawKind = zlibStreamKind{
+	name: "deflate",
+	newReader: func(reader io.Reader, dict []byte) (io.ReadCloser, error) {
+		return flate.NewReaderDict(reader, dict), nil
+	},
+	newWriter: func(writer io.Writer, level int, dict []byte) (io.WriteCloser, error) {
+		return flate.NewWriterDict(writer, level, dict)
+	},
+}
+
+func defineZlibFields(zlibClass *LoxClass) {
+	zlibFields := map[string]int64{
+		"bestCompression":    zlib.BestCompression,
+		"bestSpeed":          zlib.BestSpeed,
+		"defaultCompression": zlib.DefaultCompression,
+		"huffmanOnly":        zlib.HuffmanOnly,
+		"noCompression":      zlib.NoCompression,
+		"USE_BUFFER":         COMPRESSION_USE_BUFFER,
+	}
+	for key, value := range zlibFields {
+		zlibClass.classProperties[key] = value
+	}
+}
+
+func (i *Interpreter) defineZlibFuncs() {
+	className := "zlib"
+	zlibClass := NewLoxClass(className, nil, false)
+	zlibFunc := func(name string, arity int, method func(*Interpreter, list.List[any]) (any, error)) {
+		s := &struct{ ProtoLoxCallable }{}
+		s.arityMethod = func() int { return arity }
+		s.callMethod = method
+		s.stringMethod = func() string {
+			return fmt.Sprintf("<native zlib fn %v at %p>", name, &s)
+		}
+		zlibClass.classProperties[name] = s
+	}
+	argPositions := []string{"First", "Second", "Third"}
+	checkArgsLen := func(callToken *token.Token, args list.List[any], minArgs int, maxArgs int) error {
+		argsLen := len(args)
+		if argsLen < minArgs || argsLen > maxArgs {
+			return loxerror.RuntimeError(callToken,
+				fmt.Sprintf("Expected %v to %v arguments but got %v.", minArgs, maxArgs, argsLen))
+		}
+		return nil
+	}
+	level := func(callToken *token.Token, fnName string, args list.List[any], index int) (int, error) {
+		if index >= len(args) {
+			return zlib.DefaultCompression, nil
+		}
+		level, ok := args[index].(int64)
+		if !ok {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("%v argument to 'zlib.%v' must be an integer.", argPositions[index], fnName))
+		}
+		if level < zlib.HuffmanOnly || level > zlib.BestCompression {
+			return 0, loxerror.RuntimeError(callToken,
+				fmt.Sprintf("zlib compression level must be between %v and %v.",
+					zlib.HuffmanOnly, zlib.BestCompression))
+		}
+		return int(level), nil
+	}
+	dictionary := func(callToken *token.Token, fnName string, args list.List[any], index int) ([]byte, error) {
+		if index >= len(args) {
+			return nil, nil
+		}
+		switch arg := args[index].(type) {
+		case nil:
+			return nil, nil
+		case *LoxBuffer:
+			return arg.bytes(), nil
+		case *LoxString:
+			return []byte(arg.str), nil
+		}
+		return nil, loxerror.RuntimeError(callToken,
+			fmt.Sprintf("%v argument to 'zlib.%v' must be a buffer, string, or nil.", argPositions[index], fnName))
+	}
+	compressFunc := func(fnName string, kind zlibStreamKind) {
+		zlibFunc(fnName, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := checkArgsLen(in.callToken, args, 1, 3); err != nil {
+				return nil, err
+			}
+			source, err := compressionSource(in.callToken, "zlib."+fnName, "First", args[0])
+			if err != nil {
+				return nil, err
+			}
+			compressionLevel, err := level(in.callToken, fnName, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			dict, err := dictionary(in.callToken, fnName, args, 2)
+			if err != nil {
+				return nil, err
+			}
+			bytesBuffer := new(bytes.Buffer)
+			writer, err := kind.newWriter(bytesBuffer, compressionLevel, dict)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			if _, err := io.Copy(writer, source); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			if err := writer.Close(); err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxBufferFromBytes(bytesBuffer.Bytes()), nil
+		})
+	}
+	decompressFunc := func(fnName string, kind zlibStreamKind) {
+		zlibFunc(fnName, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := checkArgsLen(in.callToken, args, 1, 2); err != nil {
+				return nil, err
+			}
+			source, err := compressedSource(in.callToken, "zlib."+fnName, args[0])
+			if err != nil {
+				return nil, err
+			}
+			dict, err := dictionary(in.callToken, fnName, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			reader, err := kind.newReader(source, dict)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxBufferFromBytes(data), nil
+		})
+	}
+	readerFunc := func(fnName string, kind zlibStreamKind) {
+		zlibFunc(fnName, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := checkArgsLen(in.callToken, args, 1, 2); err != nil {
+				return nil, err
+			}
+			source, err := compressedSource(in.callToken, "zlib."+fnName, args[0])
+			if err != nil {
+				return nil, err
+			}
+			dict, err := dictionary(in.callToken, fnName, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			reader, err := kind.newReader(source, dict)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxCompressionReader(kind.name, reader), nil
+		})
+	}
+	writerFunc := func(fnName string, kind zlibStreamKind) {
+		zlibFunc(fnName, -1, func(in *Interpreter, args list.List[any]) (any, error) {
+			if err := checkArgsLen(in.callToken, args, 1, 3); err != nil {
+				return nil, err
+			}
+			var destination io.Writer
+			var bytesBuffer *bytes.Buffer
+			switch arg := args[0].(type) {
+			case *LoxFile:
+				if !arg.isWrite() && !arg.isAppend() {
+					return nil, loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Cannot create %v writer for file not in write or append mode.", kind.name))
+				}
+				destination = arg.file
+			case int64:
+				if arg != COMPRESSION_USE_BUFFER {
+					return nil, loxerror.RuntimeError(in.callToken,
+						fmt.Sprintf("Integer argument to 'zlib.%v' must be equal to the field 'zlib.USE_BUFFER'.", fnName))
+				}
+				bytesBuffer = new(bytes.Buffer)
+				destination = bytesBuffer
+			default:
+				return nil, loxerror.RuntimeError(in.callToken,
+					fmt.Sprintf("First argument to 'zlib.%v' must be a file or the field 'zlib.USE_BUFFER'.", fnName))
+			}
+			compressionLevel, err := level(in.callToken, fnName, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			dict, err := dictionary(in.callToken, fnName, args, 2)
+			if err != nil {
+				return nil, err
+			}
+			writer, err := kind.newWriter(destination, compressionLevel, dict)
+			if err != nil {
+				return nil, loxerror.RuntimeError(in.callToken, err.Error())
+			}
+			return NewLoxCompressionWriter(kind.name, writer, bytesBuffer), nil
+		})
+	}
+
+	defineZlibFields(zlibClass)
+	compressFunc("compress", zlibWrappedKind)
+	compressFunc("deflate", zlibRawKind)
+	decompressFunc("decompress", zlibWrappedKind)
+	decompressFunc("inflate", zlibRawKind)
+	readerFunc("rawReader", zlibRawKind)
+	writerFunc("rawWriter", zlibRawKind)
+	readerFunc("reader", zlibWrappedKind)
+	writerFunc("writer", zlibWrappedKind)
+
+	i.globals.Define(className, zlibClass)
+}
@@ -0,0 +1,29 @@
+package ast
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func (i *Interpreter) defineZstdFuncs() {
+	i.defineCompressionClass(compressionFormat{
+		name:         "zstd",
+		minLevel:     1,
+		maxLevel:     22,
+		defaultLevel: 3,
+		newReader: func(reader io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+		newWriter: func(writer io.Writer, level int) (io.WriteCloser, error) {
+			return zstd.NewWriter(writer,
+				zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+				zstd.WithEncoderConcurrency(1),
+			)
+		},
+	})
+}
@@ -0,0 +1,162 @@
+package benchsuite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BaselineName is the name of the baseline file that is looked for in a
+// benchmark directory if no other baseline file is specified.
+const BaselineName = "baseline.json"
+
+type Result struct {
+	Name     string  `json:"name"`
+	Runs     int     `json:"runs"`
+	MinMs    float64 `json:"minMs"`
+	MedianMs float64 `json:"medianMs"`
+	MeanMs   float64 `json:"meanMs"`
+}
+
+type Report struct {
+	LoxVersion string   `json:"loxVersion"`
+	GOOS       string   `json:"goos"`
+	GOARCH     string   `json:"goarch"`
+	Date       string   `json:"date"`
+	Results    []Result `json:"results"`
+}
+
+type Comparison struct {
+	Name       string
+	CurrentMs  float64
+	BaselineMs float64
+	HasBase    bool
+	Change     float64 //Percentage change from the baseline
+	Regressed  bool
+}
+
+// Find returns the paths of all Lox files in the specified directory,
+// sorted by name.
+func Find(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".lox" {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no benchmarks found in directory '%v'", dir)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Run runs the benchmark at the specified path the specified number of
+// times, each in a new process started from the interpreter executable
+// at exePath with the specified arguments before the benchmark path.
+func Run(exePath string, exeArgs []string, path string, runs int) (Result, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	result := Result{Name: name, Runs: runs}
+	if runs <= 0 {
+		return result, errors.New("number of runs must be positive")
+	}
+	durations := make([]float64, 0, runs)
+	for i := 0; i < runs; i++ {
+		var stderr bytes.Buffer
+		cmd := exec.Command(exePath, append(exeArgs, path)...)
+		cmd.Stdout = io.Discard
+		cmd.Stderr = &stderr
+		start := time.Now()
+		err := cmd.Run()
+		elapsed := time.Since(start)
+		if err != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return result, fmt.Errorf("benchmark '%v' failed: %v", name, msg)
+		}
+		durations = append(durations, float64(elapsed)/float64(time.Millisecond))
+	}
+
+	sort.Float64s(durations)
+	total := 0.0
+	for _, duration := range durations {
+		total += duration
+	}
+	result.MinMs = durations[0]
+	if runs%2 == 0 {
+		result.MedianMs = (durations[runs/2-1] + durations[runs/2]) / 2
+	} else {
+		result.MedianMs = durations[runs/2]
+	}
+	result.MeanMs = total / float64(runs)
+	return result, nil
+}
+
+func NewReport(loxVersion string, results []Result) *Report {
+	return &Report{
+		LoxVersion: loxVersion,
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		Date:       time.Now().UTC().Format(time.RFC3339),
+		Results:    results,
+	}
+}
+
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid benchmark report '%v': %v", path, err)
+	}
+	return report, nil
+}
+
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Compare compares the median times of the current results against the
+// baseline results with the same names. A benchmark has regressed if its
+// median time is more than threshold percent slower than its baseline.
+func Compare(current *Report, baseline *Report, threshold float64) []Comparison {
+	baselineResults := make(map[string]Result)
+	if baseline != nil {
+		for _, result := range baseline.Results {
+			baselineResults[result.Name] = result
+		}
+	}
+	comparisons := make([]Comparison, 0, len(current.Results))
+	for _, result := range current.Results {
+		comparison := Comparison{Name: result.Name, CurrentMs: result.MedianMs}
+		if baseResult, ok := baselineResults[result.Name]; ok && baseResult.MedianMs > 0 {
+			comparison.HasBase = true
+			comparison.BaselineMs = baseResult.MedianMs
+			comparison.Change = (result.MedianMs - baseResult.MedianMs) / baseResult.MedianMs * 100
+			comparison.Regressed = comparison.Change > threshold
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
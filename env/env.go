@@ -1,30 +1,52 @@
 package env
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/AlanLuu/lox/loxerror"
 	"github.com/AlanLuu/lox/token"
 )
 
+// Environments with more variables than this also keep a map from variable
+// names to slots so that lookups by name stay fast, which matters mostly
+// for the global environment
+const indexThreshold = 16
+
+// Set once Lox code starts running on more than one goroutine, after which
+// every environment locks its variables when they are accessed. Programs
+// that never run Lox code concurrently do not pay for the locking.
+var locking atomic.Bool
+
+// Makes every environment lock its variables when they are accessed. This
+// must be called before Lox code starts running on another goroutine.
+func EnableLocking() {
+	locking.Store(true)
+}
+
+// Variables are stored in slots in the order that they are defined, which
+// is the same order that the resolver assigns slots to local variables
 type Environment struct {
-	values    map[string]any
+	names     []string
+	values    []any
+	index     map[string]int
 	enclosing *Environment
+	mutex     sync.RWMutex
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
-		values:    make(map[string]any),
 		enclosing: nil,
 	}
 }
 
 func NewEnvironmentEnclosing(enclosing *Environment) *Environment {
 	return &Environment{
-		values:    make(map[string]any),
 		enclosing: enclosing,
 	}
 }
 
-func (e *Environment) ancestor(distance int) *Environment {
+func (e *Environment) Ancestor(distance int) *Environment {
 	environment := e
 	for i := 0; i < distance; i++ {
 		environment = environment.enclosing
@@ -32,50 +54,118 @@ func (e *Environment) ancestor(distance int) *Environment {
 	return environment
 }
 
+func (e *Environment) find(name string) int {
+	if e.index != nil {
+		slot, ok := e.index[name]
+		if !ok {
+			return -1
+		}
+		return slot
+	}
+	for slot, slotName := range e.names {
+		if slotName == name {
+			return slot
+		}
+	}
+	return -1
+}
+
+// Returns the slot of the specified variable in this environment, using
+// the slot computed by the resolver if it still holds that variable. The
+// resolver's slot can be out of date if variables were defined in this
+// environment in an order that the resolver could not see, such as by an
+// import statement
+func (e *Environment) slotOf(slot int, name string) int {
+	if slot >= 0 && slot < len(e.names) && e.names[slot] == name {
+		return slot
+	}
+	return e.find(name)
+}
+
 func (e *Environment) Assign(name *token.Token, value any) error {
 	for tempE := e; tempE != nil; tempE = tempE.enclosing {
-		_, ok := tempE.values[name.Lexeme]
-		if ok {
-			tempE.values[name.Lexeme] = value
+		if tempE.assign(-1, name.Lexeme, value) {
 			return nil
 		}
 	}
-	return loxerror.RuntimeError(name, "undefined variable '"+name.Lexeme+"'.")
+	return loxerror.RuntimeErrorKind(loxerror.NameError, name, "undefined variable '"+name.Lexeme+"'.")
+}
+
+// Assigns to the specified variable in this environment only, returning
+// false if the variable is not defined in this environment
+func (e *Environment) assign(slot int, name string, value any) bool {
+	if locking.Load() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+	}
+	slot = e.slotOf(slot, name)
+	if slot < 0 {
+		return false
+	}
+	e.values[slot] = value
+	return true
 }
 
 func (e *Environment) AssignAt(distance int, name *token.Token, value any) {
-	e.ancestor(distance).Assign(name, value)
+	e.Ancestor(distance).Assign(name, value)
+}
+
+func (e *Environment) AssignSlot(distance int, slot int, name *token.Token, value any) error {
+	environment := e.Ancestor(distance)
+	if !environment.assign(slot, name.Lexeme, value) {
+		return environment.Assign(name, value)
+	}
+	return nil
 }
 
 func (e *Environment) Define(name string, value any) {
-	e.values[name] = value
+	if locking.Load() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+	}
+	slot := e.find(name)
+	if slot >= 0 {
+		e.values[slot] = value
+		return
+	}
+	e.names = append(e.names, name)
+	e.values = append(e.values, value)
+	if e.index != nil {
+		e.index[name] = len(e.names) - 1
+	} else if len(e.names) > indexThreshold {
+		e.index = make(map[string]int, len(e.names))
+		for slot, slotName := range e.names {
+			e.index[slotName] = slot
+		}
+	}
 }
 
 func (e *Environment) Get(name *token.Token) (any, error) {
 	for tempE := e; tempE != nil; tempE = tempE.enclosing {
-		value, ok := tempE.values[name.Lexeme]
+		value, ok := tempE.lookupSlot(-1, name.Lexeme)
 		if ok {
 			return value, nil
 		}
 	}
-	return nil, loxerror.RuntimeError(name, "undefined variable '"+name.Lexeme+"'.")
+	return nil, loxerror.RuntimeErrorKind(loxerror.NameError, name, "undefined variable '"+name.Lexeme+"'.")
 }
 
 func (e *Environment) GetAt(distance int, name *token.Token) (any, error) {
-	value, ok := e.ancestor(distance).values[name.Lexeme]
+	value, ok := e.Ancestor(distance).Lookup(name.Lexeme)
 	if ok {
 		return value, nil
 	}
-	return nil, loxerror.RuntimeError(name, "undefined variable '"+name.Lexeme+"'.")
+	return nil, loxerror.RuntimeErrorKind(loxerror.NameError, name, "undefined variable '"+name.Lexeme+"'.")
 }
 
 func (e *Environment) GetAtStr(distance int, name string) any {
-	return e.ancestor(distance).values[name]
+	value, _ := e.Ancestor(distance).Lookup(name)
+	return value
 }
 
 func (e *Environment) GetFromStr(name string) (any, error) {
 	for tempE := e; tempE != nil; tempE = tempE.enclosing {
-		value, ok := tempE.values[name]
+		value, ok := tempE.Lookup(name)
 		if ok {
 			return value, nil
 		}
@@ -83,6 +173,40 @@ func (e *Environment) GetFromStr(name string) (any, error) {
 	return nil, loxerror.Error("undefined variable '" + name + "'.")
 }
 
+func (e *Environment) GetSlot(distance int, slot int, name *token.Token) (any, error) {
+	value, ok := e.Ancestor(distance).lookupSlot(slot, name.Lexeme)
+	if !ok {
+		return nil, loxerror.RuntimeErrorKind(loxerror.NameError, name, "undefined variable '"+name.Lexeme+"'.")
+	}
+	return value, nil
+}
+
+// Looks up the specified variable in this environment only
+func (e *Environment) Lookup(name string) (any, bool) {
+	return e.lookupSlot(-1, name)
+}
+
+func (e *Environment) lookupSlot(slot int, name string) (any, bool) {
+	if locking.Load() {
+		e.mutex.RLock()
+		defer e.mutex.RUnlock()
+	}
+	slot = e.slotOf(slot, name)
+	if slot < 0 {
+		return nil, false
+	}
+	return e.values[slot], true
+}
+
+// Returns a new map of the variables defined in this environment
 func (e *Environment) Values() map[string]any {
-	return e.values
+	if locking.Load() {
+		e.mutex.RLock()
+		defer e.mutex.RUnlock()
+	}
+	values := make(map[string]any, len(e.names))
+	for slot, name := range e.names {
+		values[name] = e.values[slot]
+	}
+	return values
 }
@@ -17,6 +17,15 @@ type Iterable interface {
 	Iterator() Iterator
 }
 
+// IteratorErr is an Iterator over a source that can fail, such as a
+// database cursor or a network stream. Once HasNext returns false, Err
+// returns the error that ended the iteration early, or nil if the source
+// was fully consumed.
+type IteratorErr interface {
+	Iterator
+	Err() error
+}
+
 type LazyType interface {
 	LazyTypeEval() error
 }
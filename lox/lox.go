@@ -0,0 +1,100 @@
+// Package lox provides a simple API for embedding the Lox interpreter in Go
+// programs, which can run Lox code, call functions defined in Lox code, and
+// register Go functions that Lox code can call.
+package lox
+
+import (
+	"fmt"
+
+	"github.com/AlanLuu/lox/ast"
+	"github.com/AlanLuu/lox/scanner"
+)
+
+// Interpreter is a Lox interpreter whose global variables persist between
+// calls to Eval.
+type Interpreter struct {
+	interpreter *ast.Interpreter
+}
+
+// New returns a new interpreter with all of the built-in classes and
+// functions defined.
+func New() *Interpreter {
+	return &Interpreter{interpreter: ast.NewInterpreter()}
+}
+
+// AST returns the underlying interpreter from the ast package, for Go
+// programs that need more control over how code is run.
+func (l *Interpreter) AST() *ast.Interpreter {
+	return l.interpreter
+}
+
+// Eval runs the specified Lox source code in the global scope and returns
+// the value of the last statement if it is an expression statement, or nil
+// otherwise. The value is converted using ast.FromLoxValue.
+func (l *Interpreter) Eval(source string) (any, error) {
+	sc := scanner.NewScanner(source)
+	scanErr := sc.ScanTokens()
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	parser := ast.NewParser(sc.Tokens)
+	exprList, parseErr := parser.Parse()
+	defer exprList.Clear()
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	resolver := ast.NewResolver(l.interpreter)
+	resolverErr := resolver.Resolve(exprList)
+	if resolverErr != nil {
+		return nil, resolverErr
+	}
+
+	value, valueErr := l.interpreter.InterpretReturnLast(exprList)
+	if valueErr != nil {
+		return nil, valueErr
+	}
+	return ast.FromLoxValue(value), nil
+}
+
+// Call calls the function or class stored in the global variable with the
+// specified name with the specified arguments and returns its result. The
+// arguments are converted using ast.ToLoxValue and the result is converted
+// using ast.FromLoxValue.
+func (l *Interpreter) Call(fnName string, args ...any) (any, error) {
+	result, err := l.interpreter.CallGlobal(fnName, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ast.FromLoxValue(result), nil
+}
+
+// Get returns the value of the global variable with the specified name,
+// converted using ast.FromLoxValue.
+func (l *Interpreter) Get(name string) (any, error) {
+	value, ok := l.interpreter.LookupGlobal(name)
+	if !ok {
+		return nil, fmt.Errorf("undefined variable '%v'", name)
+	}
+	return ast.FromLoxValue(value), nil
+}
+
+// Set defines a global variable with the specified name and value, which
+// is converted using ast.ToLoxValue.
+func (l *Interpreter) Set(name string, value any) error {
+	return l.interpreter.DefineGlobal(name, value)
+}
+
+// RegisterFunc defines a global function with the specified name that
+// calls the specified Go function. The function must be called with exactly
+// arity arguments, or with any number of arguments if arity is -1. The
+// arguments are converted using ast.FromLoxValue and the return value is
+// converted using ast.ToLoxValue. If fn returns an error, it is thrown as a
+// runtime error in the Lox code that called the function.
+func (l *Interpreter) RegisterFunc(name string, arity int, fn func(args []any) (any, error)) error {
+	if arity < -1 {
+		return fmt.Errorf("invalid arity %v for function '%v'", arity, name)
+	}
+	return l.interpreter.DefineGlobal(name, ast.NewGoFunction(name, arity, fn))
+}
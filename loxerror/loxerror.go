@@ -3,7 +3,11 @@ package loxerror
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/AlanLuu/lox/token"
 )
@@ -17,14 +21,173 @@ func GiveError(line int, where string, message string) error {
 	return errors.New(errorMsg)
 }
 
+// Returns a warning with the specified message about the specified token,
+// which does not stop the program from running
+func Warning(theToken *token.Token, message string) error {
+	return TokenError(theToken, fmt.Sprintf("[line %v] Warning: %v", theToken.Line, message))
+}
+
 func RuntimeError(theToken *token.Token, message string) error {
-	errorStr := message + "\n[line " + fmt.Sprint(theToken.Line) + "]"
-	return errors.New(errorStr)
+	err := TokenError(theToken, message).(*positionError)
+	err.isRuntime = true
+	return err
+}
+
+// An error that remembers where in the source code it occurred, so that
+// the line of source code can be shown when the error is printed
+type positionError struct {
+	source  *token.Source
+	line    int
+	column  int
+	length  int
+	message string
+	//Runtime errors have their line number added to their message
+	isRuntime bool
+}
+
+func (e *positionError) Error() string {
+	if e.isRuntime {
+		return e.message + "\n[line " + fmt.Sprint(e.line) + "]"
+	}
+	return e.message
+}
+
+// Returns an error with the specified message that occurred at the
+// specified line and column of the source code and spans the specified
+// number of characters
+func PositionError(source *token.Source, line int, column int, length int, message string) error {
+	return &positionError{
+		source:  source,
+		line:    line,
+		column:  column,
+		length:  length,
+		message: message,
+	}
+}
+
+// Returns an error with the specified message that occurred at the
+// specified token
+func TokenError(theToken *token.Token, message string) error {
+	source := theToken.Source
+	if strings.Contains(theToken.Lexeme, "\n") {
+		//The line of a token that spans multiple lines is the last
+		//line, but its column is on the first line
+		source = nil
+	}
+	return PositionError(source, theToken.Line, theToken.Column,
+		utf8.RuneCountInString(theToken.Lexeme), message)
+}
+
+// Returns the line of source code where the specified error occurred,
+// with the characters where it occurred underlined, or an empty string if
+// the position of the error is not known
+func Snippet(e error) string {
+	var posErr *positionError
+	if !errors.As(e, &posErr) || posErr.source == nil || posErr.column < 1 {
+		return ""
+	}
+	line, ok := posErr.source.Line(posErr.line)
+	if !ok {
+		return ""
+	}
+	lineRunes := []rune(line)
+	if posErr.column > len(lineRunes)+1 {
+		return ""
+	}
+	var padding strings.Builder
+	for _, c := range lineRunes[:posErr.column-1] {
+		if c == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+	length := min(max(posErr.length, 1), max(len(lineRunes)-posErr.column+1, 1))
+	lineNumStr := strconv.Itoa(posErr.line)
+	gutter := strings.Repeat(" ", len(lineNumStr))
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%v--> line %v, column %v\n", gutter, posErr.line, posErr.column)
+	fmt.Fprintf(&builder, "%v |\n", gutter)
+	fmt.Fprintf(&builder, "%v | %v\n", lineNumStr, line)
+	fmt.Fprintf(&builder, "%v | %v^%v\n", gutter, padding.String(), strings.Repeat("~", length-1))
+	return builder.String()
+}
+
+// Names of the built-in error classes that runtime errors can belong to
+// other than Error, which every runtime error belongs to
+const (
+	AssertionError    = "AssertionError"
+	AuthError         = "AuthError"
+	IOError           = "IOError"
+	IndexError        = "IndexError"
+	KeyError          = "KeyError"
+	NameError         = "NameError"
+	RecursionError    = "RecursionError"
+	TypeError         = "TypeError"
+	ValueError        = "ValueError"
+	ZeroDivisionError = "ZeroDivisionError"
+)
+
+// A runtime error that belongs to one of the built-in error classes, so
+// that it can be caught by a catch clause for that class
+type KindError struct {
+	Kind    string
+	Message string //Without the line number, which is only added when printing
+	err     error
+}
+
+func (e *KindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *KindError) Unwrap() error {
+	return e.err
+}
+
+func RuntimeErrorKind(kind string, theToken *token.Token, message string) error {
+	return &KindError{
+		Kind:    kind,
+		Message: message,
+		err:     RuntimeError(theToken, message),
+	}
+}
+
+// Returns a runtime error with the message of the specified error, which
+// belongs to IOError if the error came from the file system
+func RuntimeErrorIO(theToken *token.Token, err error) error {
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	if errors.As(err, &pathErr) || errors.As(err, &linkErr) || errors.As(err, &syscallErr) {
+		return RuntimeErrorKind(IOError, theToken, err.Error())
+	}
+	return RuntimeError(theToken, err.Error())
+}
+
+// Returns the message of the specified error without the line number that
+// is added to the messages of runtime errors
+func Message(e error) string {
+	var kindErr *KindError
+	if errors.As(e, &kindErr) {
+		return kindErr.Message
+	}
+	var posErr *positionError
+	if errors.As(e, &posErr) {
+		return posErr.message
+	}
+	return e.Error()
 }
 
 func PrintErrorObject(e error) {
+	if joined, ok := e.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			PrintErrorObject(err)
+		}
+		return
+	}
 	if len(e.Error()) > 0 {
 		fmt.Fprintf(os.Stderr, "%v\n", e.Error())
+		fmt.Fprint(os.Stderr, Snippet(e))
 	}
 }
 
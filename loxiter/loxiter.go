@@ -0,0 +1,191 @@
+// Package loxiter contains helpers for Go programs that embed the Lox
+// interpreter and want to hand their own data sources to Lox code as
+// iterators, without first copying the data into Lox lists.
+//
+// Every iterator returned by this package converts the values it produces
+// into Lox values using ast.ToLoxValue, and implements
+// interfaces.IteratorErr so that errors from the underlying source, or
+// values that cannot be converted, are thrown as runtime errors in the Lox
+// code that is iterating over them.
+package loxiter
+
+import (
+	"github.com/AlanLuu/lox/ast"
+	"github.com/AlanLuu/lox/interfaces"
+)
+
+// Func produces the next value from a source. It returns false if the
+// source has no more values, and a non-nil error if the source failed.
+type Func func() (value any, ok bool, err error)
+
+type funcIterator struct {
+	next    Func
+	value   any
+	fetched bool
+	done    bool
+	err     error
+}
+
+// FromFunc returns an iterator that calls next to produce each value.
+// Once next returns false or an error, it is not called again.
+func FromFunc(next Func) interfaces.IteratorErr {
+	return &funcIterator{next: next}
+}
+
+func (f *funcIterator) HasNext() bool {
+	if f.done {
+		return false
+	}
+	if !f.fetched {
+		value, ok, err := f.next()
+		if err == nil && ok {
+			value, err = ast.ToLoxValue(value)
+		}
+		if err != nil || !ok {
+			f.done = true
+			f.err = err
+			return false
+		}
+		f.value = value
+		f.fetched = true
+	}
+	return true
+}
+
+func (f *funcIterator) Next() any {
+	if !f.HasNext() {
+		return nil
+	}
+	f.fetched = false
+	value := f.value
+	f.value = nil
+	return value
+}
+
+func (f *funcIterator) Err() error {
+	return f.err
+}
+
+// FromSlice returns an iterator over the elements of a slice.
+func FromSlice[T any](values []T) interfaces.IteratorErr {
+	index := 0
+	return FromFunc(func() (any, bool, error) {
+		if index >= len(values) {
+			return nil, false, nil
+		}
+		value := values[index]
+		index++
+		return value, true, nil
+	})
+}
+
+// FromChannel returns an iterator over the values received from a
+// channel, which ends when the channel is closed.
+func FromChannel[T any](ch <-chan T) interfaces.IteratorErr {
+	return FromFunc(func() (any, bool, error) {
+		value, ok := <-ch
+		return value, ok, nil
+	})
+}
+
+// Map returns an iterator over the results of calling fn on each value
+// of the specified iterator.
+func Map(iterator interfaces.Iterator, fn func(any) (any, error)) interfaces.IteratorErr {
+	return FromFunc(func() (any, bool, error) {
+		if !iterator.HasNext() {
+			return nil, false, Err(iterator)
+		}
+		value, err := fn(iterator.Next())
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	})
+}
+
+// Filter returns an iterator over the values of the specified iterator
+// for which fn returns true.
+func Filter(iterator interfaces.Iterator, fn func(any) (bool, error)) interfaces.IteratorErr {
+	return FromFunc(func() (any, bool, error) {
+		for iterator.HasNext() {
+			value := iterator.Next()
+			keep, err := fn(value)
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				return value, true, nil
+			}
+		}
+		return nil, false, Err(iterator)
+	})
+}
+
+// Take returns an iterator over at most the first n values of the
+// specified iterator.
+func Take(iterator interfaces.Iterator, n int) interfaces.IteratorErr {
+	count := 0
+	return FromFunc(func() (any, bool, error) {
+		if count >= n {
+			return nil, false, nil
+		}
+		if !iterator.HasNext() {
+			return nil, false, Err(iterator)
+		}
+		count++
+		return iterator.Next(), true, nil
+	})
+}
+
+// Chain returns an iterator over the values of each of the specified
+// iterators in order.
+func Chain(iterators ...interfaces.Iterator) interfaces.IteratorErr {
+	index := 0
+	return FromFunc(func() (any, bool, error) {
+		for index < len(iterators) {
+			iterator := iterators[index]
+			if iterator.HasNext() {
+				return iterator.Next(), true, nil
+			}
+			if err := Err(iterator); err != nil {
+				return nil, false, err
+			}
+			index++
+		}
+		return nil, false, nil
+	})
+}
+
+// Err returns the error that ended the specified iterator early, or nil
+// if the iterator cannot fail or has not failed.
+func Err(iterator interfaces.Iterator) error {
+	if iteratorErr, ok := iterator.(interfaces.IteratorErr); ok {
+		return iteratorErr.Err()
+	}
+	return nil
+}
+
+type iterable struct {
+	name    string
+	factory func() interfaces.Iterator
+}
+
+// NewIterable returns a value that can be iterated over any number of
+// times in Lox code, such as in a foreach loop. Each iteration calls
+// factory to start a new iterator. The name is used when the value is
+// printed and as its type name.
+func NewIterable(name string, factory func() interfaces.Iterator) interfaces.Iterable {
+	return &iterable{name: name, factory: factory}
+}
+
+func (i *iterable) Iterator() interfaces.Iterator {
+	return i.factory()
+}
+
+func (i *iterable) String() string {
+	return "<iterable " + i.name + ">"
+}
+
+func (i *iterable) Type() string {
+	return i.name
+}
@@ -1,3 +1,5 @@
+//go:build !js
+
 package main
 
 import (
@@ -7,10 +9,15 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/AlanLuu/lox/ast"
+	"github.com/AlanLuu/lox/benchsuite"
 	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/power"
+	"github.com/AlanLuu/lox/project"
 	"github.com/AlanLuu/lox/scanner"
 	"github.com/AlanLuu/lox/util"
 	"github.com/chzyer/readline"
@@ -22,20 +29,101 @@ const NEXT_LINE_PROMPT = "... "
 //go:embed loxcode/*
 var loxCodeFS embed.FS
 
+// A flag that can be specified more than once, collecting every value
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// The paths of the Go plugins passed with --plugin
+var pluginPaths stringListFlag
+
+// A number of bytes that can end with one of the suffixes K, M, or G
+type byteSizeFlag int64
+
+func (b *byteSizeFlag) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSizeFlag) Set(value string) error {
+	number, multiplier := value, int64(1)
+	for suffix, suffixMultiplier := range map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30} {
+		if strings.HasSuffix(strings.ToUpper(value), suffix) {
+			number, multiplier = value[:len(value)-1], suffixMultiplier
+		}
+	}
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid size '%v'", value)
+	}
+	*b = byteSizeFlag(size * multiplier)
+	return nil
+}
+
 func usageFunc(writer io.Writer) func() {
 	return func() {
 		usage :=
 			`Usage: lox [OPTIONS] [FILE]
+       lox [OPTIONS] run [PROJECT] [ARGS...]
+       lox [OPTIONS] benchsuite [BENCHSUITE OPTIONS] [DIR]
+       lox fmt [-w] [FILE...]
+       lox [OPTIONS] test [DIR]
 
 OPTIONS:
 	-c <code>
 		Execute Lox code from command line argument
 	--disable-loxcode, -dl
 		Disable execution of all Lox files that are bundled inside this interpreter executable
+	--lint
+		Check FILE for possible mistakes without running it, exiting with status 1 if any are found
+	--max-alloc <size>
+		Stop the program with an error once it has allocated more than the specified number of bytes in total. The size can end with K, M, or G
+	--max-depth <n>
+		Stop the program with an error if more than n Lox function calls are running at the same time
+	--plugin <file>
+		Load a Go plugin that defines native modules before running any Lox code. Can be specified more than once
+	--profile
+		Sample which Lox functions are running and print a table of the time spent in each one to standard error on exit
+	--profile-output <file>
+		Same as --profile, but also write the samples to the specified file in the format read by pprof
+	--record <file>
+		Save all successfully executed statements in interactive mode to the specified file
+	--sandbox
+		Disable the os, process, unsafe, and other classes that can access files, the network, or other processes, as well as import statements
+	--timeout <duration>
+		Stop the program with an error once it has run for longer than the specified duration, such as 500ms or 10s
 	--unsafe
 		Enable unsafe mode, allowing access to functions that can potentially crash this interpreter
 	-h, --help
 		Print this usage message and exit
+
+COMMANDS:
+	benchsuite [BENCHSUITE OPTIONS] [DIR]
+		Run every Lox file in the DIR directory as a benchmark and compare the results against a stored baseline, exiting with status 1 if any benchmark regressed. DIR defaults to "benchmarks"
+	fmt [-w] [FILE...]
+		Print each Lox FILE in its canonical format, or standard input if no files are given. With -w, rewrite each FILE in place instead
+	run [PROJECT] [ARGS...]
+		Run the project described by the lox.json or lox.toml manifest in the PROJECT directory, which defaults to the current directory
+	test [DIR]
+		Run the test cases in every file ending in _test.lox in the DIR directory and its subdirectories, exiting with status 1 if any of them failed. DIR defaults to the current directory
+
+BENCHSUITE OPTIONS:
+	--baseline <file>
+		Compare against the specified baseline file instead of baseline.json in DIR
+	--output <file>
+		Save the results of this run to the specified file
+	--runs <n>
+		Run each benchmark n times and use the median time, default 5
+	--save
+		Save the results of this run as the new baseline
+	--threshold <percent>
+		Treat benchmarks that are more than this percent slower than the baseline as regressions, default 10
 `
 		fmt.Fprint(writer, usage)
 	}
@@ -138,7 +226,411 @@ func processFile(filePath string) error {
 	return nil
 }
 
-func interactiveMode() int {
+type sessionRecorder struct {
+	file *os.File
+}
+
+func (r *sessionRecorder) isRecording() bool {
+	return r.file != nil
+}
+
+func (r *sessionRecorder) start(path string) error {
+	if r.isRecording() {
+		if err := r.stop(); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	return nil
+}
+
+func (r *sessionRecorder) stop() error {
+	if !r.isRecording() {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *sessionRecorder) record(program string) error {
+	if !r.isRecording() {
+		return nil
+	}
+	program = strings.TrimSpace(program)
+	if len(program) == 0 {
+		return nil
+	}
+	//Expressions typed into the REPL don't require semicolons
+	if lastChar := program[len(program)-1]; lastChar != ';' && lastChar != '}' {
+		program += ";"
+	}
+	_, err := r.file.WriteString(program + "\n")
+	return err
+}
+
+func (r *sessionRecorder) command(userInput string) {
+	fields := strings.Fields(userInput)
+	switch fields[0] {
+	case ":record":
+		if len(fields) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: :record <file>")
+			return
+		}
+		if err := r.start(fields[1]); err != nil {
+			loxerror.PrintErrorObject(err)
+			return
+		}
+		fmt.Printf("Recording session to '%v'.\n", fields[1])
+	case ":stop":
+		if !r.isRecording() {
+			fmt.Fprintln(os.Stderr, "Not currently recording a session.")
+			return
+		}
+		fileName := r.file.Name()
+		if err := r.stop(); err != nil {
+			loxerror.PrintErrorObject(err)
+			return
+		}
+		fmt.Printf("Stopped recording session to '%v'.\n", fileName)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown REPL command '%v'.\n", fields[0])
+	}
+}
+
+func runProject(args []string) int {
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+		args = args[1:]
+	}
+	manifestPath, findErr := project.Find(projectPath)
+	if findErr != nil {
+		loxerror.PrintErrorObject(findErr)
+		return 1
+	}
+	manifest, loadErr := project.Load(manifestPath)
+	if loadErr != nil {
+		loxerror.PrintErrorObject(loadErr)
+		return 1
+	}
+	versionErr := project.CheckVersion(manifest.Lox, util.Version)
+	if versionErr != nil {
+		loxerror.PrintErrorObject(versionErr)
+		return 1
+	}
+
+	assets, assetsErr := manifest.AssetFiles()
+	if assetsErr != nil {
+		loxerror.PrintErrorObject(assetsErr)
+		return 1
+	}
+	ast.SetProjectAssets(assets)
+
+	entryPath := manifest.EntryPath()
+	util.ImportPaths = append([]string{manifest.Dir}, manifest.ResolvedImportPaths()...)
+	util.ScriptArgs = append([]string{entryPath}, args...)
+	possibleError := processFile(entryPath)
+	if possibleError != nil {
+		loxerror.PrintErrorObject(possibleError)
+		return 1
+	}
+	return 0
+}
+
+func runBenchSuite(args []string) int {
+	flags := flag.NewFlagSet("benchsuite", flag.ContinueOnError)
+	flags.Usage = usageFunc(os.Stderr)
+	var (
+		baselinePath = flags.String("baseline", "", "")
+		outputPath   = flags.String("output", "", "")
+		runs         = flags.Int("runs", 5, "")
+		save         = flags.Bool("save", false, "")
+		threshold    = flags.Float64("threshold", 10, "")
+	)
+	if flags.Parse(args) != nil {
+		return 2
+	}
+	dir := "benchmarks"
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	if *baselinePath == "" {
+		*baselinePath = filepath.Join(dir, benchsuite.BaselineName)
+	}
+
+	paths, findErr := benchsuite.Find(dir)
+	if findErr != nil {
+		loxerror.PrintErrorObject(findErr)
+		return 1
+	}
+	exePath, exeErr := os.Executable()
+	if exeErr != nil {
+		loxerror.PrintErrorObject(exeErr)
+		return 1
+	}
+	exeArgs := []string{}
+	if util.UnsafeMode {
+		exeArgs = append(exeArgs, "--unsafe")
+	}
+	if util.DisableLoxCode {
+		exeArgs = append(exeArgs, "--disable-loxcode")
+	}
+	if util.SandboxMode {
+		exeArgs = append(exeArgs, "--sandbox")
+	}
+	for _, pluginPath := range pluginPaths {
+		exeArgs = append(exeArgs, "--plugin", pluginPath)
+	}
+
+	var baseline *benchsuite.Report
+	if _, statErr := os.Stat(*baselinePath); statErr == nil {
+		var loadErr error
+		baseline, loadErr = benchsuite.LoadReport(*baselinePath)
+		if loadErr != nil {
+			loxerror.PrintErrorObject(loadErr)
+			return 1
+		}
+	}
+
+	results := make([]benchsuite.Result, 0, len(paths))
+	for _, path := range paths {
+		result, runErr := benchsuite.Run(exePath, exeArgs, path, *runs)
+		if runErr != nil {
+			loxerror.PrintErrorObject(runErr)
+			return 1
+		}
+		results = append(results, result)
+	}
+	report := benchsuite.NewReport(util.Version, results)
+
+	fmt.Printf("%-20v %12v %12v %9v\n", "BENCHMARK", "MEDIAN", "BASELINE", "CHANGE")
+	regressions := 0
+	for _, comparison := range benchsuite.Compare(report, baseline, *threshold) {
+		baselineStr, changeStr := "-", "-"
+		if comparison.HasBase {
+			baselineStr = fmt.Sprintf("%.2fms", comparison.BaselineMs)
+			changeStr = fmt.Sprintf("%+.1f%%", comparison.Change)
+		}
+		fmt.Printf("%-20v %12v %12v %9v", comparison.Name,
+			fmt.Sprintf("%.2fms", comparison.CurrentMs), baselineStr, changeStr)
+		if comparison.Regressed {
+			fmt.Print("  REGRESSED")
+			regressions++
+		}
+		fmt.Println()
+	}
+
+	if *outputPath != "" {
+		if saveErr := report.Save(*outputPath); saveErr != nil {
+			loxerror.PrintErrorObject(saveErr)
+			return 1
+		}
+	}
+	if *save {
+		if saveErr := report.Save(*baselinePath); saveErr != nil {
+			loxerror.PrintErrorObject(saveErr)
+			return 1
+		}
+		fmt.Printf("Saved results as baseline '%v'.\n", *baselinePath)
+		return 0
+	}
+	if baseline == nil {
+		fmt.Printf("No baseline found at '%v'. Use --save to create one.\n", *baselinePath)
+		return 0
+	}
+	if regressions > 0 {
+		fmt.Fprintf(os.Stderr, "%v benchmark(s) regressed by more than %v%%.\n",
+			regressions, *threshold)
+		return 1
+	}
+	return 0
+}
+
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.Usage = usageFunc(os.Stderr)
+	write := flags.Bool("w", false, "")
+	if flags.Parse(args) != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "Cannot use -w when formatting standard input.")
+			return 2
+		}
+		program, readErr := io.ReadAll(os.Stdin)
+		if readErr != nil {
+			loxerror.PrintErrorObject(readErr)
+			return 1
+		}
+		formatted, formatErr := ast.Format(string(program))
+		if formatErr != nil {
+			loxerror.PrintErrorObject(formatErr)
+			return 1
+		}
+		fmt.Print(formatted)
+		return 0
+	}
+
+	exitCode := 0
+	for _, path := range flags.Args() {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			loxerror.PrintErrorObject(statErr)
+			exitCode = 1
+			continue
+		}
+		program, readErr := os.ReadFile(path)
+		if readErr != nil {
+			loxerror.PrintErrorObject(readErr)
+			exitCode = 1
+			continue
+		}
+		formatted, formatErr := ast.Format(string(program))
+		if formatErr != nil {
+			loxerror.PrintErrorObject(formatErr)
+			exitCode = 1
+			continue
+		}
+		if !*write {
+			fmt.Print(formatted)
+		} else if formatted != string(program) {
+			writeErr := os.WriteFile(path, []byte(formatted), info.Mode().Perm())
+			if writeErr != nil {
+				loxerror.PrintErrorObject(writeErr)
+				exitCode = 1
+			}
+		}
+	}
+	return exitCode
+}
+
+func lintFile(filePath string) (int, error) {
+	program, readErr := os.ReadFile(filePath)
+	if readErr != nil {
+		return 0, readErr
+	}
+	sc := scanner.NewScanner(string(program))
+	scanErr := sc.ScanTokens()
+	if scanErr != nil {
+		return 0, scanErr
+	}
+	parser := ast.NewParser(sc.Tokens)
+	exprList, parseErr := parser.Parse()
+	defer exprList.Clear()
+	if parseErr != nil {
+		return 0, parseErr
+	}
+
+	interpreter := ast.NewInterpreter()
+	runLoxCodeErr := runLoxCode(interpreter)
+	if runLoxCodeErr != nil {
+		return 0, runLoxCodeErr
+	}
+	resolver := ast.NewResolver(interpreter)
+	warnings, lintErr := resolver.Lint(exprList)
+	if lintErr != nil {
+		return 0, lintErr
+	}
+	for _, warning := range warnings {
+		loxerror.PrintErrorObject(warning)
+	}
+	return len(warnings), nil
+}
+
+func runLint(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Expected at least one file to lint.")
+		return 2
+	}
+	exitCode := 0
+	for _, filePath := range args {
+		numWarnings, lintErr := lintFile(filePath)
+		if lintErr != nil {
+			loxerror.PrintErrorObject(lintErr)
+			exitCode = 1
+		} else if numWarnings > 0 {
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// Runs the specified test file and then the test cases that it registered,
+// returning the number of test cases that passed and failed
+func runTestFile(filePath string) (int, int, error) {
+	program, readErr := os.ReadFile(filePath)
+	if readErr != nil {
+		return 0, 0, readErr
+	}
+	sc := scanner.NewScanner(string(program))
+	interpreter := ast.NewInterpreter()
+	runLoxCodeErr := runLoxCode(interpreter)
+	if runLoxCodeErr != nil {
+		return 0, 0, runLoxCodeErr
+	}
+	resultError := run(sc, interpreter)
+	if resultError != nil {
+		return 0, 0, resultError
+	}
+	passed, failed := interpreter.RunTests(os.Stdout)
+	return passed, failed, nil
+}
+
+func runTests(args []string) int {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	paths := []string{}
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), "_test.lox") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		loxerror.PrintErrorObject(walkErr)
+		return 1
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "No test files found in '%v'.\n", dir)
+		return 1
+	}
+
+	totalPassed, totalFailed, fileErrors := 0, 0, 0
+	for _, path := range paths {
+		fmt.Println(path)
+		passed, failed, testErr := runTestFile(path)
+		totalPassed += passed
+		totalFailed += failed
+		if testErr != nil {
+			fmt.Println("ERROR")
+			loxerror.PrintErrorObject(testErr)
+			fileErrors++
+		}
+	}
+	fmt.Printf("\n%v passed, %v failed", totalPassed, totalFailed)
+	if fileErrors > 0 {
+		fmt.Printf(", %v file(s) with errors", fileErrors)
+	}
+	fmt.Println()
+	if totalFailed > 0 || fileErrors > 0 {
+		return 1
+	}
+	return 0
+}
+
+func interactiveMode(recordPath string) int {
 	l, _ := readline.NewEx(&readline.Config{
 		Prompt:          PROMPT,
 		InterruptPrompt: "^C",
@@ -152,6 +644,14 @@ func interactiveMode() int {
 		return 1
 	}
 	if util.StdinFromTerminal() {
+		recorder := &sessionRecorder{}
+		defer recorder.stop()
+		if recordPath != "" {
+			if err := recorder.start(recordPath); err != nil {
+				loxerror.PrintErrorObject(err)
+				return 1
+			}
+		}
 		numSpacesIndent := 2
 	outer:
 		for {
@@ -179,6 +679,10 @@ func interactiveMode() int {
 					continue
 				}
 				userInput = strings.TrimSpace(userInput)
+				if scopeLevel == 0 && strings.HasPrefix(userInput, ":") {
+					recorder.command(userInput)
+					continue
+				}
 				program.WriteString(userInput)
 				leftBraceCount, rightBraceCount := util.CountBraces(userInput)
 				scopeLevel += (leftBraceCount - rightBraceCount)
@@ -192,6 +696,8 @@ func interactiveMode() int {
 			resultError := run(sc, interpreter)
 			if resultError != nil {
 				loxerror.PrintErrorObject(resultError)
+			} else if recordErr := recorder.record(program.String()); recordErr != nil {
+				loxerror.PrintErrorObject(recordErr)
 			}
 		}
 	} else {
@@ -217,10 +723,20 @@ func main() {
 		exprCLine       = flag.String("c", "", "")
 		disableLoxCode  = flag.Bool("disable-loxcode", false, "")
 		disableLoxCode2 = flag.Bool("dl", false, "")
+		lint            = flag.Bool("lint", false, "")
+		profile         = flag.Bool("profile", false, "")
+		profileOutput   = flag.String("profile-output", "", "")
+		recordPath      = flag.String("record", "", "")
+		sandbox         = flag.Bool("sandbox", false, "")
+		timeout         = flag.Duration("timeout", 0, "")
+		maxDepth        = flag.Int("max-depth", 0, "")
 		unsafe          = flag.Bool("unsafe", false, "")
 		helpFlag1       = flag.Bool("h", false, "")
 		helpFlag2       = flag.Bool("help", false, "")
 	)
+	var maxAlloc byteSizeFlag
+	flag.Var(&maxAlloc, "max-alloc", "")
+	flag.Var(&pluginPaths, "plugin", "")
 	flag.Usage = usageFunc(os.Stderr)
 	flag.Parse()
 	if *helpFlag1 || *helpFlag2 {
@@ -230,7 +746,25 @@ func main() {
 
 	args := flag.Args()
 	util.DisableLoxCode = *disableLoxCode || *disableLoxCode2
-	util.UnsafeMode = *unsafe
+	ast.SetEmbeddedAssets(loxCodeFS)
+	util.SandboxMode = *sandbox
+	util.UnsafeMode = *unsafe && !*sandbox
+	for _, pluginPath := range pluginPaths {
+		if pluginErr := ast.LoadPlugin(pluginPath); pluginErr != nil {
+			loxerror.PrintErrorObject(pluginErr)
+			os.Exit(1)
+		}
+	}
+	if *timeout > 0 || maxAlloc > 0 || *maxDepth > 0 {
+		ast.SetLimits(ast.Limits{
+			Timeout:  *timeout,
+			MaxAlloc: int64(maxAlloc),
+			MaxDepth: *maxDepth,
+		})
+	}
+	if *profile || *profileOutput != "" {
+		ast.StartProfiler(os.Stderr, *profileOutput)
+	}
 	exitCode := 0
 	if *exprCLine != "" {
 		sc := scanner.NewScanner(*exprCLine)
@@ -246,6 +780,16 @@ func main() {
 			loxerror.PrintErrorObject(runLoxCodeErr)
 			exitCode = 1
 		}
+	} else if *lint {
+		exitCode = runLint(args)
+	} else if len(args) > 0 && args[0] == "benchsuite" {
+		exitCode = runBenchSuite(args[1:])
+	} else if len(args) > 0 && args[0] == "fmt" {
+		exitCode = runFmt(args[1:])
+	} else if len(args) > 0 && args[0] == "run" {
+		exitCode = runProject(args[1:])
+	} else if len(args) > 0 && args[0] == "test" {
+		exitCode = runTests(args[1:])
 	} else if len(args) > 0 && args[0] != "-" {
 		possibleError := processFile(args[0])
 		if possibleError != nil {
@@ -254,9 +798,15 @@ func main() {
 		}
 	} else {
 		util.InteractiveMode = true
-		exitCode = interactiveMode()
+		exitCode = interactiveMode(*recordPath)
 	}
 
 	ast.CloseInputFuncReadline()
+	ast.RestoreTerminal()
+	power.ReleaseAll()
+	if profileErr := ast.StopProfiler(); profileErr != nil {
+		loxerror.PrintErrorObject(profileErr)
+		exitCode = 1
+	}
 	os.Exit(exitCode)
 }
@@ -0,0 +1,103 @@
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Word is a single word recognized by tesseract along with its bounding
+// box and confidence score, which ranges from 0 to 100.
+type Word struct {
+	Text       string
+	Confidence float64
+	Left       int64
+	Top        int64
+	Width      int64
+	Height     int64
+}
+
+type Result struct {
+	Text       string
+	Confidence float64
+	Words      []Word
+}
+
+var TesseractCommand = "tesseract"
+
+// Tesseract runs tesseract on the image file at the specified path
+// using the specified language and parses its TSV output.
+func Tesseract(path string, lang string) (*Result, error) {
+	if _, err := exec.LookPath(TesseractCommand); err != nil {
+		return nil, errors.New("tesseract is not installed or could not be found")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(TesseractCommand, path, "stdout", "-l", lang, "tsv")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderrStr := strings.TrimSpace(stderr.String()); len(stderrStr) > 0 {
+			return nil, errors.New(stderrStr)
+		}
+		return nil, err
+	}
+	return parseTSV(stdout.String())
+}
+
+func parseTSV(tsv string) (*Result, error) {
+	result := &Result{}
+	var textBuilder strings.Builder
+	var confidenceSum float64
+	lastLineKey := ""
+	lines := strings.Split(strings.TrimRight(tsv, "\r\n"), "\n")
+	for index, line := range lines {
+		if index == 0 {
+			continue //header
+		}
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 12 {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if len(text) == 0 {
+			continue
+		}
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil || confidence < 0 {
+			continue
+		}
+		nums := [4]int64{}
+		for i := range nums {
+			nums[i], err = strconv.ParseInt(fields[6+i], 10, 64)
+			if err != nil {
+				return nil, errors.New("malformed tesseract output")
+			}
+		}
+		lineKey := strings.Join(fields[1:5], ".")
+		if lastLineKey != "" {
+			if lineKey != lastLineKey {
+				textBuilder.WriteByte('\n')
+			} else {
+				textBuilder.WriteByte(' ')
+			}
+		}
+		lastLineKey = lineKey
+		textBuilder.WriteString(text)
+		confidenceSum += confidence
+		result.Words = append(result.Words, Word{
+			Text:       text,
+			Confidence: confidence,
+			Left:       nums[0],
+			Top:        nums[1],
+			Width:      nums[2],
+			Height:     nums[3],
+		})
+	}
+	result.Text = textBuilder.String()
+	if len(result.Words) > 0 {
+		result.Confidence = confidenceSum / float64(len(result.Words))
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var RangeAPIURL = "https://api.pwnedpasswords.com/range/"
+
+// BreachCount returns the number of times the specified password appears
+// in the Have I Been Pwned breach corpus. Only the first five characters
+// of the password's SHA-1 hash are sent over the network.
+func BreachCount(pw string) (int64, error) {
+	sum := sha1.Sum([]byte(pw))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequest("GET", RangeAPIURL+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "lox")
+	client := &http.Client{Timeout: 30 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breach check failed with status %v", res.Status)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineSuffix, countStr, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+		count, err := strconv.ParseInt(countStr, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return count, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,343 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// commonPasswords is a list of frequently used passwords ordered by
+// how commonly they are used, from most to least common.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234",
+	"111111", "1234567", "dragon", "123123", "baseball", "abc123", "football",
+	"monkey", "letmein", "696969", "shadow", "master", "666666", "qwertyuiop",
+	"123321", "mustang", "1234567890", "michael", "654321", "superman",
+	"1qaz2wsx", "7777777", "121212", "000000", "qazwsx", "123qwe", "killer",
+	"trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter", "buster",
+	"soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou",
+	"2000", "charlie", "robert", "thomas", "hockey", "ranger", "daniel",
+	"starwars", "klaster", "112233", "george", "computer", "michelle",
+	"jessica", "pepper", "1111", "zxcvbn", "555555", "11111111", "131313",
+	"freedom", "777777", "pass", "maggie", "159753", "aaaaaa", "ginger",
+	"princess", "joshua", "cheese", "amanda", "summer", "love", "ashley",
+	"nicole", "chelsea", "biteme", "matthew", "access", "yankees", "987654321",
+	"dallas", "austin", "thunder", "taylor", "matrix", "admin", "welcome",
+	"login", "passw0rd", "password1", "qwerty123", "hello", "secret", "root",
+	"changeme", "default", "guest", "test", "abcdef", "abcd1234",
+}
+
+// commonWords is a small dictionary of common English words that often
+// appear inside passwords.
+var commonWords = []string{
+	"love", "baby", "angel", "happy", "money", "lucky", "smile", "family",
+	"friend", "house", "summer", "winter", "spring", "autumn", "flower",
+	"sunny", "cookie", "coffee", "orange", "purple", "yellow", "silver",
+	"golden", "dragon", "tiger", "monkey", "horse", "kitty", "puppy", "apple",
+	"banana", "cherry", "music", "pizza", "water", "fire", "earth", "star",
+	"moon", "blue", "black", "white", "green", "red", "pink", "magic",
+	"hello", "world", "super", "power", "king", "queen", "prince", "princess",
+	"admin", "user", "secret", "pass", "word", "letmein", "welcome", "login",
+	"qwerty", "computer", "internet", "game", "player", "soccer", "football",
+	"hockey", "baseball", "jesus", "christ", "heaven", "forever", "change",
+}
+
+var keyboardRows = []string{
+	"1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"qazwsxedcrfvtgbyhnujmik,ol.p;/",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik,9ol.0p;/",
+}
+
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i',
+	'!': 'i', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't',
+	'2': 'z',
+}
+
+// maxMatchLength is the number of characters at the start of a password
+// that are checked for patterns. Any characters after that are treated
+// as random characters.
+const maxMatchLength = 100
+
+type Result struct {
+	Score       int64
+	Guesses     float64
+	CrackTime   float64
+	Warning     string
+	Suggestions []string
+}
+
+type segment struct {
+	end     int
+	guesses float64
+	kind    string
+	leet    bool
+}
+
+func unleet(str string) (string, bool) {
+	changed := false
+	runes := []rune(str)
+	for i, r := range runes {
+		if sub, ok := leetSubstitutions[r]; ok {
+			runes[i] = sub
+			changed = true
+		}
+	}
+	return string(runes), changed
+}
+
+func cardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r):
+		return 26
+	case unicode.IsUpper(r):
+		return 26
+	case r < 128:
+		return 33
+	default:
+		return 100
+	}
+}
+
+func rankIn(words []string, word string) int {
+	for index, w := range words {
+		if w == word {
+			return index + 1
+		}
+	}
+	return 0
+}
+
+func capitalizationFactor(original string) float64 {
+	upper := 0
+	lower := 0
+	for _, r := range original {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0:
+		return 2
+	case upper == 1 && unicode.IsUpper([]rune(original)[0]):
+		return 2
+	default:
+		return math.Max(2, float64(upper+lower))
+	}
+}
+
+// matchesAt returns all pattern matches starting at the specified index.
+func matchesAt(runes []rune, lower []rune, unleeted []rune, start int, userInputs []string) []segment {
+	n := len(runes)
+	var matches []segment
+	consider := func(seg segment) {
+		matches = append(matches, seg)
+	}
+
+	//dictionary words, including leet variants
+	for end := n; end-start >= 3; end-- {
+		word := string(lower[start:end])
+		leetWord := string(unleeted[start:end])
+		factor := capitalizationFactor(string(runes[start:end]))
+		leetFactor := 1.0
+		if leetWord != word {
+			leetFactor = 2
+		}
+		for _, input := range userInputs {
+			if input == word || input == leetWord {
+				consider(segment{end, 2 * factor * leetFactor, "user", leetFactor > 1})
+			}
+		}
+		if rank := rankIn(commonPasswords, word); rank > 0 {
+			consider(segment{end, float64(rank) * factor, "common", false})
+		} else if rank := rankIn(commonPasswords, leetWord); rank > 0 {
+			consider(segment{end, float64(rank) * factor * leetFactor, "common", true})
+		}
+		if end-start >= 4 {
+			if rank := rankIn(commonWords, word); rank > 0 {
+				consider(segment{end, float64(rank+len(commonPasswords)) * factor, "word", false})
+			} else if rank := rankIn(commonWords, leetWord); rank > 0 {
+				consider(segment{end, float64(rank+len(commonPasswords)) * factor * leetFactor, "word", true})
+			}
+		}
+	}
+
+	//repeated characters
+	end := start + 1
+	for end < n && runes[end] == runes[start] {
+		end++
+	}
+	if end-start >= 3 {
+		consider(segment{end, cardinality(runes[start]) * float64(end-start), "repeat", false})
+	}
+
+	//sequences such as abc or 987
+	if start+2 < n {
+		delta := lower[start+1] - lower[start]
+		if delta == 1 || delta == -1 {
+			end := start + 2
+			for end < n && lower[end]-lower[end-1] == delta {
+				end++
+			}
+			if end-start >= 3 {
+				guesses := cardinality(runes[start]) * float64(end-start)
+				if delta < 0 {
+					guesses *= 2
+				}
+				consider(segment{end, guesses, "sequence", false})
+			}
+		}
+	}
+
+	//keyboard patterns
+	for _, row := range keyboardRows {
+		for end := n; end-start >= 4; end-- {
+			pattern := string(lower[start:end])
+			if strings.Contains(row, pattern) || strings.Contains(reverse(row), pattern) {
+				consider(segment{end, 100 * float64(end-start), "keyboard", false})
+				break
+			}
+		}
+	}
+
+	//years from 1900 to 2099
+	if start+4 <= n {
+		year := string(runes[start : start+4])
+		if (strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) &&
+			unicode.IsDigit(runes[start+2]) && unicode.IsDigit(runes[start+3]) {
+			consider(segment{start + 4, 200, "year", false})
+		}
+	}
+
+	return matches
+}
+
+func reverse(str string) string {
+	runes := []rune(str)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// Strength estimates the strength of the specified password in a manner
+// similar to zxcvbn, by splitting the password into the cheapest sequence
+// of recognizable patterns and multiplying the number of guesses needed
+// for each one.
+func Strength(pw string, userInputs []string) *Result {
+	lowerInputs := make([]string, 0, len(userInputs))
+	for _, input := range userInputs {
+		if len(input) > 0 {
+			lowerInputs = append(lowerInputs, strings.ToLower(input))
+		}
+	}
+	runes := []rune(pw)
+	lower := []rune(strings.ToLower(pw))
+	unleetedStr, _ := unleet(strings.ToLower(pw))
+	unleeted := []rune(unleetedStr)
+	n := len(runes)
+
+	//minGuesses[i] is the minimum number of guesses for runes[:i]
+	minGuesses := make([]float64, n+1)
+	kinds := make([]map[string]bool, n+1)
+	kinds[0] = map[string]bool{}
+	for i := 1; i <= n; i++ {
+		minGuesses[i] = math.Inf(1)
+	}
+	minGuesses[0] = 1
+	for i := 0; i < n; i++ {
+		if math.IsInf(minGuesses[i], 1) {
+			continue
+		}
+		relax := func(end int, guesses float64, kind string, leet bool) {
+			total := minGuesses[i] * guesses
+			if total < minGuesses[end] {
+				minGuesses[end] = total
+				newKinds := make(map[string]bool, len(kinds[i])+1)
+				for k := range kinds[i] {
+					newKinds[k] = true
+				}
+				newKinds[kind] = true
+				if leet {
+					newKinds["leet"] = true
+				}
+				kinds[end] = newKinds
+			}
+		}
+		relax(i+1, cardinality(runes[i]), "bruteforce", false)
+		if i < maxMatchLength {
+			matchEnd := min(n, maxMatchLength)
+			for _, seg := range matchesAt(runes[:matchEnd], lower[:matchEnd], unleeted[:matchEnd], i, lowerInputs) {
+				relax(seg.end, seg.guesses, seg.kind, seg.leet)
+			}
+		}
+	}
+
+	guesses := math.Max(1, minGuesses[n])
+	result := &Result{
+		Guesses:   guesses,
+		CrackTime: guesses / 1e4,
+	}
+	switch {
+	case guesses < 1e3:
+		result.Score = 0
+	case guesses < 1e6:
+		result.Score = 1
+	case guesses < 1e8:
+		result.Score = 2
+	case guesses < 1e10:
+		result.Score = 3
+	default:
+		result.Score = 4
+	}
+
+	usedKinds := kinds[n]
+	if usedKinds == nil {
+		usedKinds = map[string]bool{}
+	}
+	switch {
+	case result.Score >= 3:
+	case n == 0:
+		result.Warning = "The password is empty."
+	case usedKinds["common"]:
+		result.Warning = "This is a commonly used password."
+	case usedKinds["user"]:
+		result.Warning = "The password contains personal information."
+	case usedKinds["word"]:
+		result.Warning = "A word by itself is easy to guess."
+	case usedKinds["keyboard"]:
+		result.Warning = "Keyboard patterns are easy to guess."
+	case usedKinds["repeat"]:
+		result.Warning = "Repeated characters are easy to guess."
+	case usedKinds["sequence"]:
+		result.Warning = "Sequences like abc or 6543 are easy to guess."
+	case usedKinds["year"]:
+		result.Warning = "Recent years are easy to guess."
+	}
+	if result.Score < 3 {
+		result.Suggestions = append(result.Suggestions,
+			"Use a few words, avoid common phrases.")
+		if n < 12 {
+			result.Suggestions = append(result.Suggestions,
+				"Use a longer password.")
+		}
+		if usedKinds["leet"] {
+			result.Suggestions = append(result.Suggestions,
+				"Predictable substitutions like '@' instead of 'a' don't help very much.")
+		}
+		if strings.ToLower(pw) == pw && n > 0 {
+			result.Suggestions = append(result.Suggestions,
+				"Capitalize some letters, but not only the first one.")
+		}
+	}
+	return result
+}
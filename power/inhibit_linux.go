@@ -0,0 +1,14 @@
+package power
+
+import (
+	"os"
+	"syscall"
+)
+
+func inhibitorSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+}
+
+func killProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package power
+
+import (
+	"os"
+	"syscall"
+)
+
+func inhibitorSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+func killProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
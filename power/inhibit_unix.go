@@ -0,0 +1,16 @@
+//go:build unix && !linux
+
+package power
+
+import (
+	"os"
+	"syscall"
+)
+
+func inhibitorSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,59 @@
+package power
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Inhibitor represents an active request that prevents the system
+// from sleeping until it is released.
+type Inhibitor interface {
+	Release() error
+}
+
+func unsupported(name string) error {
+	return errors.New("'" + name + "' is unsupported on " + runtime.GOOS + ".")
+}
+
+// runFirst runs each command in order until one of them succeeds.
+func runFirst(cmds [][]string) error {
+	var lastErr error
+	for _, args := range cmds {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			lastErr = err
+			continue
+		}
+		output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		outputStr := strings.TrimSpace(string(output))
+		if len(outputStr) > 0 {
+			lastErr = errors.New(outputStr)
+		} else {
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no command available")
+	}
+	return lastErr
+}
+
+func Reboot() error {
+	return runFirst(rebootCommands())
+}
+
+func Shutdown() error {
+	return runFirst(shutdownCommands())
+}
+
+func Suspend() error {
+	cmds := suspendCommands()
+	if len(cmds) == 0 {
+		return unsupported("os.suspend")
+	}
+	return runFirst(cmds)
+}
@@ -0,0 +1,160 @@
+//go:build !windows
+
+package power
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type processInhibitor struct {
+	cmd *exec.Cmd
+}
+
+var activeInhibitors = struct {
+	sync.Mutex
+	inhibitors map[*processInhibitor]bool
+}{inhibitors: make(map[*processInhibitor]bool)}
+
+func (p *processInhibitor) Release() error {
+	activeInhibitors.Lock()
+	delete(activeInhibitors.inhibitors, p)
+	activeInhibitors.Unlock()
+	if p.cmd.Process == nil {
+		return nil
+	}
+	//Kill the whole process group so that children such as "sleep" exit too
+	killErr := killProcessGroup(p.cmd.Process)
+	p.cmd.Wait()
+	return killErr
+}
+
+// ReleaseAll releases every inhibitor that hasn't been released yet, which
+// is done when Lox exits since the processes holding them would outlive it.
+func ReleaseAll() {
+	activeInhibitors.Lock()
+	inhibitors := make([]*processInhibitor, 0, len(activeInhibitors.inhibitors))
+	for inhibitor := range activeInhibitors.inhibitors {
+		inhibitors = append(inhibitors, inhibitor)
+	}
+	activeInhibitors.Unlock()
+	for _, inhibitor := range inhibitors {
+		inhibitor.Release()
+	}
+}
+
+func rebootCommands() [][]string {
+	switch runtime.GOOS {
+	case "linux":
+		return [][]string{
+			{"systemctl", "reboot"},
+			{"shutdown", "-r", "now"},
+			{"reboot"},
+		}
+	default:
+		return [][]string{
+			{"shutdown", "-r", "now"},
+			{"reboot"},
+		}
+	}
+}
+
+func shutdownCommands() [][]string {
+	switch runtime.GOOS {
+	case "linux":
+		return [][]string{
+			{"systemctl", "poweroff"},
+			{"shutdown", "-h", "now"},
+			{"poweroff"},
+		}
+	default:
+		return [][]string{
+			{"shutdown", "-h", "now"},
+			{"poweroff"},
+		}
+	}
+}
+
+func suspendCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pmset", "sleepnow"}}
+	case "linux":
+		return [][]string{
+			{"systemctl", "suspend"},
+			{"loginctl", "suspend"},
+		}
+	case "freebsd":
+		return [][]string{{"zzz"}}
+	}
+	return nil
+}
+
+func IdleTime() (time.Duration, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		output, err := exec.Command("ioreg", "-c", "IOHIDSystem").Output()
+		if err != nil {
+			return 0, err
+		}
+		match := regexp.MustCompile(`"HIDIdleTime"\s*=\s*(\d+)`).FindSubmatch(output)
+		if match == nil {
+			return 0, errors.New("could not determine idle time")
+		}
+		nanoseconds, err := strconv.ParseInt(string(match[1]), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(nanoseconds), nil
+	default:
+		if os.Getenv("DISPLAY") == "" {
+			return 0, errors.New("could not determine idle time: no display available")
+		}
+		output, err := exec.Command("xprintidle").Output()
+		if err != nil {
+			return 0, fmt.Errorf("could not determine idle time: %v", err)
+		}
+		milliseconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	}
+}
+
+func PreventSleep(reason string) (Inhibitor, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("caffeinate", "-i", "-s")
+	case "linux":
+		cmd = exec.Command(
+			"systemd-inhibit",
+			"--what=sleep:idle",
+			"--who=lox",
+			"--why="+reason,
+			"--mode=block",
+			"sleep",
+			"infinity",
+		)
+	default:
+		return nil, unsupported("os.preventSleep")
+	}
+	cmd.SysProcAttr = inhibitorSysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	inhibitor := &processInhibitor{cmd}
+	activeInhibitors.Lock()
+	activeInhibitors.inhibitors[inhibitor] = true
+	activeInhibitors.Unlock()
+	return inhibitor, nil
+}
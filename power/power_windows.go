@@ -0,0 +1,96 @@
+package power
+
+import (
+	"runtime"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	esContinuous     = 0x80000000
+	esSystemRequired = 0x00000001
+)
+
+var (
+	kernel32                    = windows.NewLazySystemDLL("kernel32.dll")
+	user32                      = windows.NewLazySystemDLL("user32.dll")
+	procGetTickCount            = kernel32.NewProc("GetTickCount")
+	procSetThreadExecutionState = kernel32.NewProc("SetThreadExecutionState")
+	procGetLastInputInfo        = user32.NewProc("GetLastInputInfo")
+)
+
+type lastInputInfo struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+type executionStateInhibitor struct {
+	release chan struct{}
+	done    chan struct{}
+}
+
+func (e *executionStateInhibitor) Release() error {
+	select {
+	case <-e.done:
+		return nil
+	default:
+	}
+	close(e.release)
+	<-e.done
+	return nil
+}
+
+// ReleaseAll does nothing on Windows, since the execution state set by
+// PreventSleep is reset when the process exits.
+func ReleaseAll() {}
+
+func rebootCommands() [][]string {
+	return [][]string{{"shutdown", "/r", "/t", "0"}}
+}
+
+func shutdownCommands() [][]string {
+	return [][]string{{"shutdown", "/s", "/t", "0"}}
+}
+
+func suspendCommands() [][]string {
+	return [][]string{{"rundll32.exe", "powrprof.dll,SetSuspendState", "0,1,0"}}
+}
+
+func IdleTime() (time.Duration, error) {
+	info := lastInputInfo{}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+	ret, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return 0, err
+	}
+	tickCount, _, _ := procGetTickCount.Call()
+	return time.Duration(uint32(tickCount)-info.dwTime) * time.Millisecond, nil
+}
+
+func PreventSleep(reason string) (Inhibitor, error) {
+	inhibitor := &executionStateInhibitor{
+		release: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		//SetThreadExecutionState applies to the calling thread only
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(inhibitor.done)
+		ret, _, err := procSetThreadExecutionState.Call(esContinuous | esSystemRequired)
+		if ret == 0 {
+			errChan <- err
+			return
+		}
+		errChan <- nil
+		<-inhibitor.release
+		procSetThreadExecutionState.Call(esContinuous)
+	}()
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+	return inhibitor, nil
+}
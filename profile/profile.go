@@ -0,0 +1,191 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// ValueType describes what the values of a sample measure, such as
+// "samples" and "count" or "cpu" and "nanoseconds".
+type ValueType struct {
+	Type string
+	Unit string
+}
+
+type Function struct {
+	Name string
+	Line int64
+}
+
+// Sample is a call stack along with one value for each sample type of the
+// profile. Stack holds indexes into the functions of the profile, starting
+// with the function that was running when the sample was taken.
+type Sample struct {
+	Stack  []int
+	Values []int64
+}
+
+// Profile is a profile that can be written in the format read by pprof.
+type Profile struct {
+	SampleTypes []ValueType
+	Functions   []Function
+	Samples     []Sample
+	Start       time.Time
+	Duration    time.Duration
+	PeriodType  ValueType
+	Period      int64
+}
+
+// Field numbers of the messages in pprof's profile.proto
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID        = 1
+	functionName      = 2
+	functionStartLine = 5
+)
+
+// A minimal encoder for the protocol buffer wire format
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) varint(x uint64) {
+	for x >= 0x80 {
+		e.buf = append(e.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	e.buf = append(e.buf, byte(x))
+}
+
+func (e *encoder) key(field int, wireType int) {
+	e.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) int64(field int, x int64) {
+	if x == 0 {
+		return
+	}
+	e.key(field, 0)
+	e.varint(uint64(x))
+}
+
+func (e *encoder) bytes(field int, b []byte) {
+	e.key(field, 2)
+	e.varint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) message(field int, fn func(*encoder)) {
+	var inner encoder
+	fn(&inner)
+	e.bytes(field, inner.buf)
+}
+
+func (e *encoder) packed(field int, xs []uint64) {
+	if len(xs) == 0 {
+		return
+	}
+	var inner encoder
+	for _, x := range xs {
+		inner.varint(x)
+	}
+	e.bytes(field, inner.buf)
+}
+
+// Write writes the profile to the specified writer as a gzipped protocol
+// buffer, which is the format that pprof reads.
+func (p *Profile) Write(w io.Writer) error {
+	strings := []string{""}
+	stringIndexes := map[string]int64{"": 0}
+	stringIndex := func(str string) int64 {
+		if index, ok := stringIndexes[str]; ok {
+			return index
+		}
+		index := int64(len(strings))
+		strings = append(strings, str)
+		stringIndexes[str] = index
+		return index
+	}
+	valueType := func(field int, valueType ValueType) func(*encoder) {
+		typeIndex, unitIndex := stringIndex(valueType.Type), stringIndex(valueType.Unit)
+		return func(e *encoder) {
+			e.message(field, func(e *encoder) {
+				e.int64(valueTypeType, typeIndex)
+				e.int64(valueTypeUnit, unitIndex)
+			})
+		}
+	}
+
+	var e encoder
+	for _, sampleType := range p.SampleTypes {
+		valueType(profileSampleType, sampleType)(&e)
+	}
+	for _, sample := range p.Samples {
+		e.message(profileSample, func(e *encoder) {
+			locationIDs := make([]uint64, len(sample.Stack))
+			for index, function := range sample.Stack {
+				locationIDs[index] = uint64(function) + 1
+			}
+			e.packed(sampleLocationID, locationIDs)
+			values := make([]uint64, len(sample.Values))
+			for index, value := range sample.Values {
+				values[index] = uint64(value)
+			}
+			e.packed(sampleValue, values)
+		})
+	}
+	//Each function has a single location with the same ID
+	for index, function := range p.Functions {
+		id := int64(index) + 1
+		e.message(profileLocation, func(e *encoder) {
+			e.int64(locationID, id)
+			e.message(locationLine, func(e *encoder) {
+				e.int64(lineFunctionID, id)
+				e.int64(lineLine, function.Line)
+			})
+		})
+	}
+	for index, function := range p.Functions {
+		id, nameIndex := int64(index)+1, stringIndex(function.Name)
+		e.message(profileFunction, func(e *encoder) {
+			e.int64(functionID, id)
+			e.int64(functionName, nameIndex)
+			e.int64(functionStartLine, function.Line)
+		})
+	}
+	e.int64(profileTimeNanos, p.Start.UnixNano())
+	e.int64(profileDurationNanos, p.Duration.Nanoseconds())
+	valueType(profilePeriodType, p.PeriodType)(&e)
+	e.int64(profilePeriod, p.Period)
+	for _, str := range strings {
+		e.bytes(profileStringTable, []byte(str))
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	if _, err := gzipWriter.Write(e.buf); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
@@ -0,0 +1,217 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ManifestNames lists the manifest file names that are searched for
+// in a project directory, in order of preference.
+var ManifestNames = []string{"lox.json", "lox.toml"}
+
+type Manifest struct {
+	Name        string   `json:"name" toml:"name"`
+	Version     string   `json:"version" toml:"version"`
+	Entry       string   `json:"entry" toml:"entry"`
+	ImportPaths []string `json:"importPaths" toml:"importPaths"`
+	Lox         string   `json:"lox" toml:"lox"`
+	Assets      []string `json:"assets" toml:"assets"`
+
+	//Dir is the absolute path of the directory containing the manifest
+	Dir string `json:"-" toml:"-"`
+}
+
+// Find returns the path of the manifest file for the specified path, which
+// is either a manifest file itself or a directory containing one.
+func Find(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range ManifestNames {
+		manifestPath := filepath.Join(path, name)
+		if _, err := os.Stat(manifestPath); err == nil {
+			return manifestPath, nil
+		}
+	}
+	return "", fmt.Errorf("could not find %v in directory '%v'",
+		strings.Join(ManifestNames, " or "), path)
+}
+
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	switch filepath.Ext(path) {
+	case ".json":
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest '%v': %v", path, err)
+		}
+	case ".toml":
+		metadata, err := toml.Decode(string(data), manifest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest '%v': %v", path, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("invalid manifest '%v': unknown field %q",
+				path, undecoded[0].String())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format '%v'", path)
+	}
+	if manifest.Entry == "" {
+		return nil, fmt.Errorf("manifest '%v' does not specify an entry file", path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Dir = filepath.Dir(absPath)
+	return manifest, nil
+}
+
+func (m *Manifest) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.Dir, path)
+}
+
+func (m *Manifest) EntryPath() string {
+	return m.resolve(m.Entry)
+}
+
+func (m *Manifest) ResolvedImportPaths() []string {
+	paths := make([]string, 0, len(m.ImportPaths))
+	for _, path := range m.ImportPaths {
+		paths = append(paths, m.resolve(path))
+	}
+	return paths
+}
+
+// AssetFiles returns a map of asset names, relative to the manifest
+// directory, to their absolute paths. Asset entries may be glob patterns
+// or directories, which include all files inside them.
+func (m *Manifest) AssetFiles() (map[string]string, error) {
+	files := make(map[string]string)
+	for _, pattern := range m.Assets {
+		matches, err := filepath.Glob(m.resolve(pattern))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset '%v' does not exist", pattern)
+		}
+		for _, match := range matches {
+			walkErr := filepath.WalkDir(match, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				rel, err := filepath.Rel(m.Dir, path)
+				if err != nil {
+					return err
+				}
+				files[filepath.ToSlash(rel)] = path
+				return nil
+			})
+			if walkErr != nil {
+				return nil, walkErr
+			}
+		}
+	}
+	return files, nil
+}
+
+func parseVersion(version string) ([3]int, error) {
+	result := [3]int{}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return result, fmt.Errorf("invalid version '%v'", version)
+	}
+	for i, part := range parts {
+		num, err := strconv.Atoi(part)
+		if err != nil || num < 0 {
+			return result, fmt.Errorf("invalid version '%v'", version)
+		}
+		result[i] = num
+	}
+	return result, nil
+}
+
+func compareVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// CheckVersion checks whether the specified interpreter version satisfies
+// the constraint, which is a version optionally prefixed by one of the
+// operators >=, >, <=, <, or =. A version without an operator is treated
+// as a minimum version. Multiple constraints can be separated by commas.
+func CheckVersion(constraint string, version string) error {
+	if strings.TrimSpace(constraint) == "" {
+		return nil
+	}
+	current, err := parseVersion(version)
+	if err != nil {
+		return err
+	}
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		op := ">="
+		for _, possibleOp := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(part, possibleOp) {
+				op = possibleOp
+				part = part[len(possibleOp):]
+				break
+			}
+		}
+		required, err := parseVersion(part)
+		if err != nil {
+			return err
+		}
+		cmp := compareVersions(current, required)
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return errors.New("this project requires Lox version " +
+				strings.TrimSpace(constraint) + " but the current version is " + version)
+		}
+	}
+	return nil
+}
@@ -66,6 +66,13 @@ type Scanner struct {
 	startIndex   int
 	currentIndex int
 	lineNum      int
+	lineStart    int
+	startColumn  int
+	source       *token.Source
+
+	//If true, comments are kept as COMMENT tokens instead of being
+	//skipped, which is used when formatting source code
+	KeepComments bool
 }
 
 func NewScanner(source string) *Scanner {
@@ -87,7 +94,22 @@ func (sc *Scanner) advance() rune {
 
 func (sc *Scanner) addToken(tokenType token.TokenType, literal any, quote byte) {
 	text := string(sc.sourceRunes[sc.startIndex:sc.currentIndex])
-	sc.Tokens.Add(token.NewToken(tokenType, text, literal, sc.lineNum, quote))
+	newToken := token.NewToken(tokenType, text, literal, sc.lineNum, quote)
+	newToken.Column = sc.startColumn
+	newToken.Source = sc.source
+	sc.Tokens.Add(newToken)
+}
+
+// Returns an error at the character that is currently being scanned
+func (sc *Scanner) error(message string) error {
+	return loxerror.PositionError(sc.source, sc.lineNum, sc.currentIndex-sc.lineStart, 1,
+		loxerror.GiveError(sc.lineNum, "", message).Error())
+}
+
+// Records that a new line starts at the specified index
+func (sc *Scanner) newLine(lineStart int) {
+	sc.lineNum++
+	sc.lineStart = lineStart
 }
 
 func (sc *Scanner) handleNumber() error {
@@ -132,7 +154,7 @@ func (sc *Scanner) handleNumber() error {
 	numHasDot := false
 	if sc.peek() == '.' {
 		unexpectedDotIn := func(numType string) error {
-			return loxerror.GiveError(sc.lineNum, "", "Unexpected '.' in "+numType)
+			return sc.error("Unexpected '.' in " + numType)
 		}
 		switch {
 		case isBinaryNum:
@@ -185,7 +207,7 @@ func (sc *Scanner) handleNumber() error {
 
 	numStr := string(sc.sourceRunes[sc.startIndex:sc.currentIndex])
 	invalidLiteral := func(numType string) error {
-		return loxerror.GiveError(sc.lineNum, "", "Invalid "+numType+" literal")
+		return sc.error("Invalid " + numType + " literal")
 	}
 	if bigNum {
 		tokenStr := numStr[:len(numStr)-1]
@@ -237,14 +259,14 @@ func (sc *Scanner) handleIdentifier() {
 
 func (sc *Scanner) handleString(quote rune) error {
 	unclosedStringErr := func() error {
-		return loxerror.GiveError(sc.lineNum, "", "Unclosed string")
+		return sc.error("Unclosed string")
 	}
 	var builder strings.Builder
 	var tokenQuote byte = '\''
 	foundBackslash := false
 	for foundBackslash || (sc.peek() != quote && !sc.isAtEnd()) {
 		if sc.peek() == '\n' {
-			sc.lineNum++
+			sc.newLine(sc.currentIndex + 1)
 		}
 		if tokenQuote != '"' && sc.peek() == '\'' {
 			tokenQuote = '"'
@@ -257,8 +279,7 @@ func (sc *Scanner) handleString(quote rune) error {
 		} else if foundBackslash {
 			escapeChar, ok := escapeChars[currentChar]
 			if !ok {
-				return loxerror.GiveError(sc.lineNum, "",
-					"Unknown escape character '"+string(currentChar)+"'.")
+				return sc.error("Unknown escape character '" + string(currentChar) + "'.")
 			}
 			builder.WriteRune(escapeChar)
 			foundBackslash = false
@@ -366,30 +387,54 @@ func (sc *Scanner) scanToken() error {
 			addToken(token.DOT)
 		}
 	case '?':
-		addToken(token.QUESTION)
+		if sc.match('?') { //handle "??"
+			addToken(token.DOUBLE_QUESTION)
+		} else if sc.match('.') { //handle "?."
+			addToken(token.QUESTION_DOT)
+		} else {
+			addToken(token.QUESTION)
+		}
 	case '&':
 		if sc.match('&') { //handle "&&"
 			addToken(token.AND)
+		} else if sc.match('=') { //handle "&="
+			addToken(token.AMPERSAND_EQUAL)
 		} else {
 			addToken(token.AMPERSAND)
 		}
 	case '|':
 		if sc.match('|') { //handle "||"
 			addToken(token.OR)
+		} else if sc.match('=') { //handle "|="
+			addToken(token.PIPE_EQUAL)
 		} else {
 			addToken(token.PIPE)
 		}
 	case '^':
-		addToken(token.CARET)
+		if sc.match('=') { //handle "^="
+			addToken(token.CARET_EQUAL)
+		} else {
+			addToken(token.CARET)
+		}
 	case '-':
-		addToken(token.MINUS)
+		if sc.match('=') { //handle "-="
+			addToken(token.MINUS_EQUAL)
+		} else {
+			addToken(token.MINUS)
+		}
 	case '+':
-		addToken(token.PLUS)
+		if sc.match('=') { //handle "+="
+			addToken(token.PLUS_EQUAL)
+		} else {
+			addToken(token.PLUS)
+		}
 	case ';':
 		addToken(token.SEMICOLON)
 	case '*':
 		if sc.match('*') {
 			addToken(token.DOUBLE_STAR)
+		} else if sc.match('=') { //handle "*="
+			addToken(token.STAR_EQUAL)
 		} else {
 			addToken(token.STAR)
 		}
@@ -413,7 +458,11 @@ func (sc *Scanner) scanToken() error {
 		if sc.match('=') { //handle "<="
 			addToken(token.LESS_EQUAL)
 		} else if sc.match('<') { //handle "<<"
-			addToken(token.DOUBLE_LESS)
+			if sc.match('=') { //handle "<<="
+				addToken(token.DOUBLE_LESS_EQUAL)
+			} else {
+				addToken(token.DOUBLE_LESS)
+			}
 		} else {
 			addToken(token.LESS)
 		}
@@ -421,7 +470,11 @@ func (sc *Scanner) scanToken() error {
 		if sc.match('=') { //handle ">="
 			addToken(token.GREATER_EQUAL)
 		} else if sc.match('>') { //handle ">>"
-			addToken(token.DOUBLE_GREATER)
+			if sc.match('=') { //handle ">>="
+				addToken(token.DOUBLE_GREATER_EQUAL)
+			} else {
+				addToken(token.DOUBLE_GREATER)
+			}
 		} else {
 			addToken(token.GREATER)
 		}
@@ -430,14 +483,23 @@ func (sc *Scanner) scanToken() error {
 			for sc.peek() != '\n' && !sc.isAtEnd() {
 				sc.currentIndex++
 			}
+			if sc.KeepComments {
+				addToken(token.COMMENT)
+			}
+		} else if sc.match('=') { //handle "/="
+			addToken(token.SLASH_EQUAL)
 		} else {
 			addToken(token.SLASH)
 		}
 	case '%':
-		addToken(token.PERCENT)
+		if sc.match('=') { //handle "%="
+			addToken(token.PERCENT_EQUAL)
+		} else {
+			addToken(token.PERCENT)
+		}
 
 	case '\n':
-		sc.lineNum++
+		sc.newLine(sc.currentIndex)
 
 	case ' ':
 	case '\r':
@@ -459,13 +521,14 @@ func (sc *Scanner) scanToken() error {
 			sc.handleIdentifier()
 		default:
 			unexpectedChar := "Unexpected character '" + string(c) + "'."
-			return loxerror.GiveError(sc.lineNum, "", unexpectedChar)
+			return sc.error(unexpectedChar)
 		}
 	}
 	return nil
 }
 
 func (sc *Scanner) ScanTokens() error {
+	sc.source = token.NewSource(string(sc.sourceRunes))
 	source := &sc.sourceRunes
 	if sc.sourceLen > 1 && (*source)[0] == '#' && (*source)[1] == '!' {
 		//Ignore line with "#!" (Unix shebang) at beginning of first line
@@ -475,18 +538,29 @@ func (sc *Scanner) ScanTokens() error {
 	}
 	for !sc.isAtEnd() {
 		sc.startIndex = sc.currentIndex
+		sc.startColumn = sc.startIndex - sc.lineStart + 1
 		scanTokenErr := sc.scanToken()
 		if scanTokenErr != nil {
 			return scanTokenErr
 		}
 	}
 	var eofLineNum int
+	var eofColumn int
 	if sc.Tokens.IsEmpty() {
 		eofLineNum = sc.lineNum
+		eofColumn = sc.currentIndex - sc.lineStart + 1
 	} else {
-		eofLineNum = sc.Tokens.Peek().Line
+		//Errors at the end of the file are shown right after the last token
+		lastToken := sc.Tokens.Peek()
+		eofLineNum = lastToken.Line
+		if !strings.Contains(lastToken.Lexeme, "\n") {
+			eofColumn = lastToken.Column + utf8.RuneCountInString(lastToken.Lexeme)
+		}
 	}
-	sc.Tokens.Add(token.NewToken(token.EOF, "", nil, eofLineNum, 0))
+	eofToken := token.NewToken(token.EOF, "", nil, eofLineNum, 0)
+	eofToken.Column = eofColumn
+	eofToken.Source = sc.source
+	sc.Tokens.Add(eofToken)
 	return nil
 }
 
@@ -0,0 +1,130 @@
+package screen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Region is a rectangular area of the screen in pixels. The origin is the
+// top-left corner of the virtual screen that spans all monitors.
+type Region struct {
+	X, Y, Width, Height int64
+}
+
+const windowsCaptureScript = `
+Add-Type -AssemblyName System.Windows.Forms
+Add-Type -AssemblyName System.Drawing
+$bounds = [System.Windows.Forms.SystemInformation]::VirtualScreen
+$x = %v; $y = %v; $w = %v; $h = %v
+if ($w -le 0 -or $h -le 0) { $x = $bounds.X; $y = $bounds.Y; $w = $bounds.Width; $h = $bounds.Height }
+$bitmap = New-Object System.Drawing.Bitmap $w, $h
+$graphics = [System.Drawing.Graphics]::FromImage($bitmap)
+$graphics.CopyFromScreen($x, $y, 0, 0, $bitmap.Size)
+$bitmap.Save('%v', [System.Drawing.Imaging.ImageFormat]::Png)
+$graphics.Dispose()
+$bitmap.Dispose()
+`
+
+// Commands returns a list of possible commands to use to capture the
+// screen into the specified PNG file. If region is nil, the entire
+// screen across all monitors is captured.
+func Commands(region *Region, file string) [][]string {
+	var cmds [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		if region != nil {
+			cmds = append(cmds, []string{
+				"screencapture", "-x", "-t", "png",
+				fmt.Sprintf("-R%v,%v,%v,%v", region.X, region.Y, region.Width, region.Height),
+				file,
+			})
+		} else {
+			cmds = append(cmds, []string{"screencapture", "-x", "-t", "png", file})
+		}
+	case "windows":
+		var x, y, w, h int64
+		if region != nil {
+			x, y, w, h = region.X, region.Y, region.Width, region.Height
+		}
+		script := fmt.Sprintf(windowsCaptureScript, x, y, w, h, strings.ReplaceAll(file, "'", "''"))
+		cmds = append(cmds, []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			if region != nil {
+				cmds = append(cmds, []string{
+					"grim", "-g",
+					fmt.Sprintf("%v,%v %vx%v", region.X, region.Y, region.Width, region.Height),
+					file,
+				})
+			} else {
+				cmds = append(cmds, []string{"grim", file})
+			}
+		}
+		if os.Getenv("DISPLAY") != "" {
+			if region != nil {
+				geometry := fmt.Sprintf("%vx%v+%v+%v", region.Width, region.Height, region.X, region.Y)
+				cmds = append(cmds,
+					[]string{"maim", "-g", geometry, file},
+					[]string{"import", "-window", "root", "-crop", geometry, file},
+				)
+			} else {
+				cmds = append(cmds,
+					[]string{"maim", file},
+					[]string{"import", "-window", "root", file},
+					[]string{"scrot", "-o", file},
+				)
+			}
+		}
+	}
+	return cmds
+}
+
+// Capture captures the screen and returns the resulting PNG data.
+func Capture(region *Region) ([]byte, error) {
+	if region != nil && (region.Width <= 0 || region.Height <= 0) {
+		return nil, errors.New("region width and height must be positive")
+	}
+	tempFile, err := os.CreateTemp("", "lox.screen.*.png")
+	if err != nil {
+		return nil, err
+	}
+	fileName := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(fileName)
+
+	cmds := Commands(region, fileName)
+	if len(cmds) == 0 {
+		return nil, errors.New("no display available to capture")
+	}
+	var lastErr error
+	for _, args := range cmds {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			lastErr = err
+			continue
+		}
+		output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			if outputStr := strings.TrimSpace(string(output)); len(outputStr) > 0 {
+				lastErr = errors.New(outputStr)
+			} else {
+				lastErr = err
+			}
+			continue
+		}
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(data) == 0 {
+			lastErr = errors.New("screen capture produced no data")
+			continue
+		}
+		return data, nil
+	}
+	return nil, lastErr
+}
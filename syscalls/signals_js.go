@@ -0,0 +1,9 @@
+package syscalls
+
+import "syscall"
+
+// The signals that can be handled by Lox code
+var Signals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+}
@@ -0,0 +1,24 @@
+//go:build !windows && !js
+
+package syscalls
+
+import "syscall"
+
+// The signals that can be handled by Lox code, which excludes signals such
+// as SIGKILL and SIGSTOP that can't be caught
+var Signals = map[string]syscall.Signal{
+	"SIGALRM":  syscall.SIGALRM,
+	"SIGCHLD":  syscall.SIGCHLD,
+	"SIGCONT":  syscall.SIGCONT,
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGPIPE":  syscall.SIGPIPE,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGTSTP":  syscall.SIGTSTP,
+	"SIGTTIN":  syscall.SIGTTIN,
+	"SIGTTOU":  syscall.SIGTTOU,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGWINCH": syscall.SIGWINCH,
+}
@@ -0,0 +1,11 @@
+package syscalls
+
+import "syscall"
+
+// The signals that can be handled by Lox code. On Windows, SIGINT is sent
+// when Ctrl+C or Ctrl+Break is pressed, and SIGTERM is sent when the console
+// window is closed or when the user logs off or the system shuts down
+var Signals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+}
@@ -0,0 +1,18 @@
+package syscalls
+
+import "time"
+
+// Information about a file that isn't available from fs.FileInfo on every
+// operating system, where the Has fields are false if the corresponding
+// information is unavailable on the current operating system
+type StatResult struct {
+	Atime    time.Time
+	Ctime    time.Time
+	HasTimes bool
+	Uid      int64
+	Gid      int64
+	Nlink    int64
+	Dev      int64
+	Ino      int64
+	HasIDs   bool
+}
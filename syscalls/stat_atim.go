@@ -0,0 +1,27 @@
+//go:build linux || openbsd || dragonfly || solaris
+
+package syscalls
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+func Stat(info fs.FileInfo) StatResult {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return StatResult{}
+	}
+	return StatResult{
+		Atime:    time.Unix(stat.Atim.Unix()),
+		Ctime:    time.Unix(stat.Ctim.Unix()),
+		HasTimes: true,
+		Uid:      int64(stat.Uid),
+		Gid:      int64(stat.Gid),
+		Nlink:    int64(stat.Nlink),
+		Dev:      int64(stat.Dev),
+		Ino:      int64(stat.Ino),
+		HasIDs:   true,
+	}
+}
@@ -0,0 +1,27 @@
+//go:build darwin || freebsd || netbsd
+
+package syscalls
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+func Stat(info fs.FileInfo) StatResult {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return StatResult{}
+	}
+	return StatResult{
+		Atime:    time.Unix(stat.Atimespec.Unix()),
+		Ctime:    time.Unix(stat.Ctimespec.Unix()),
+		HasTimes: true,
+		Uid:      int64(stat.Uid),
+		Gid:      int64(stat.Gid),
+		Nlink:    int64(stat.Nlink),
+		Dev:      int64(stat.Dev),
+		Ino:      int64(stat.Ino),
+		HasIDs:   true,
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !openbsd && !dragonfly && !solaris && !darwin && !freebsd && !netbsd && !windows
+
+package syscalls
+
+import "io/fs"
+
+func Stat(info fs.FileInfo) StatResult {
+	return StatResult{}
+}
@@ -0,0 +1,19 @@
+package syscalls
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+func Stat(info fs.FileInfo) StatResult {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return StatResult{}
+	}
+	return StatResult{
+		Atime:    time.Unix(0, data.LastAccessTime.Nanoseconds()),
+		Ctime:    time.Unix(0, data.CreationTime.Nanoseconds()),
+		HasTimes: true,
+	}
+}
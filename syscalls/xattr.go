@@ -0,0 +1,74 @@
+//go:build linux || darwin
+
+package syscalls
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Calls the specified function with a buffer that is large enough to hold
+// its result, retrying if the result grew between the two calls
+func xattrBuffer(fn func([]byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := fn(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return []byte{}, nil
+		}
+		buf := make([]byte, size)
+		size, err = fn(buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	}
+}
+
+func Getxattr(path string, name string) ([]byte, error) {
+	value, err := xattrBuffer(func(buf []byte) (int, error) {
+		return unix.Getxattr(path, name, buf)
+	})
+	if err != nil {
+		return nil, &os.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	return value, nil
+}
+
+func Listxattr(path string) ([]string, error) {
+	buf, err := xattrBuffer(func(buf []byte) (int, error) {
+		return unix.Listxattr(path, buf)
+	})
+	if err != nil {
+		return nil, &os.PathError{Op: "listxattr", Path: path, Err: err}
+	}
+	names := []string{}
+	for _, name := range bytes.Split(buf, []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+func Removexattr(path string, name string) error {
+	if err := unix.Removexattr(path, name); err != nil {
+		return &os.PathError{Op: "removexattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+func Setxattr(path string, name string, value []byte) error {
+	if err := unix.Setxattr(path, name, value, 0); err != nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build !linux && !darwin
+
+package syscalls
+
+import (
+	"runtime"
+
+	"github.com/AlanLuu/lox/loxerror"
+)
+
+func xattrUnsupported(name string) error {
+	return loxerror.Error("'os." + name + "' is unsupported on " + runtime.GOOS + ".")
+}
+
+func Getxattr(path string, name string) ([]byte, error) {
+	return nil, xattrUnsupported("getxattr")
+}
+
+func Listxattr(path string) ([]string, error) {
+	return nil, xattrUnsupported("listxattr")
+}
+
+func Removexattr(path string, name string) error {
+	return xattrUnsupported("removexattr")
+}
+
+func Setxattr(path string, name string, value []byte) error {
+	return xattrUnsupported("setxattr")
+}
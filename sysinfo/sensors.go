@@ -0,0 +1,30 @@
+package sysinfo
+
+// Temperature is a temperature reading in degrees Celsius.
+type Temperature struct {
+	Name    string
+	Label   string
+	Celsius float64
+}
+
+// Fan is a fan speed reading in revolutions per minute.
+type Fan struct {
+	Name  string
+	Label string
+	RPM   int64
+}
+
+// Battery describes the state of a battery. Health is the current full
+// charge capacity as a percentage of the design capacity, or -1 if unknown.
+type Battery struct {
+	Name    string
+	Status  string
+	Percent float64
+	Health  float64
+}
+
+type Sensors struct {
+	Temperatures []Temperature
+	Fans         []Fan
+	Batteries    []Battery
+}
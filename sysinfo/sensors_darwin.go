@@ -0,0 +1,35 @@
+package sysinfo
+
+import (
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var pmsetBatteryRegex = regexp.MustCompile(`-(\S+)\s.*?(\d+)%;\s*([^;]+);`)
+
+func ReadSensors() (*Sensors, error) {
+	sensors := &Sensors{}
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return sensors, nil
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		match := pmsetBatteryRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		percent, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		sensors.Batteries = append(sensors.Batteries, Battery{
+			Name:    match[1],
+			Status:  strings.TrimSpace(match[3]),
+			Percent: percent,
+			Health:  -1,
+		})
+	}
+	return sensors, nil
+}
@@ -0,0 +1,131 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func readTrimmed(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+func readInt(path string) (int64, bool) {
+	str, ok := readTrimmed(path)
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+func globSorted(pattern string) []string {
+	matches, _ := filepath.Glob(pattern)
+	sort.Strings(matches)
+	return matches
+}
+
+func readHwmon(sensors *Sensors) {
+	for _, dir := range globSorted("/sys/class/hwmon/hwmon*") {
+		name, ok := readTrimmed(filepath.Join(dir, "name"))
+		if !ok {
+			name = filepath.Base(dir)
+		}
+		for _, input := range globSorted(filepath.Join(dir, "temp*_input")) {
+			milliCelsius, ok := readInt(input)
+			if !ok {
+				continue
+			}
+			prefix := strings.TrimSuffix(input, "_input")
+			label, ok := readTrimmed(prefix + "_label")
+			if !ok {
+				label = filepath.Base(prefix)
+			}
+			sensors.Temperatures = append(sensors.Temperatures, Temperature{
+				Name:    name,
+				Label:   label,
+				Celsius: float64(milliCelsius) / 1000,
+			})
+		}
+		for _, input := range globSorted(filepath.Join(dir, "fan*_input")) {
+			rpm, ok := readInt(input)
+			if !ok {
+				continue
+			}
+			prefix := strings.TrimSuffix(input, "_input")
+			label, ok := readTrimmed(prefix + "_label")
+			if !ok {
+				label = filepath.Base(prefix)
+			}
+			sensors.Fans = append(sensors.Fans, Fan{
+				Name:  name,
+				Label: label,
+				RPM:   rpm,
+			})
+		}
+	}
+}
+
+func readThermalZones(sensors *Sensors) {
+	for _, dir := range globSorted("/sys/class/thermal/thermal_zone*") {
+		milliCelsius, ok := readInt(filepath.Join(dir, "temp"))
+		if !ok {
+			continue
+		}
+		zoneType, ok := readTrimmed(filepath.Join(dir, "type"))
+		if !ok {
+			zoneType = "thermal"
+		}
+		sensors.Temperatures = append(sensors.Temperatures, Temperature{
+			Name:    zoneType,
+			Label:   filepath.Base(dir),
+			Celsius: float64(milliCelsius) / 1000,
+		})
+	}
+}
+
+func readBatteries(sensors *Sensors) {
+	for _, dir := range globSorted("/sys/class/power_supply/*") {
+		supplyType, ok := readTrimmed(filepath.Join(dir, "type"))
+		if !ok || supplyType != "Battery" {
+			continue
+		}
+		battery := Battery{
+			Name:   filepath.Base(dir),
+			Status: "Unknown",
+			Health: -1,
+		}
+		if status, ok := readTrimmed(filepath.Join(dir, "status")); ok {
+			battery.Status = status
+		}
+		if capacity, ok := readInt(filepath.Join(dir, "capacity")); ok {
+			battery.Percent = float64(capacity)
+		}
+		for _, prefix := range []string{"energy", "charge"} {
+			full, ok1 := readInt(filepath.Join(dir, prefix+"_full"))
+			design, ok2 := readInt(filepath.Join(dir, prefix+"_full_design"))
+			if ok1 && ok2 && design > 0 {
+				battery.Health = float64(full) / float64(design) * 100
+				break
+			}
+		}
+		sensors.Batteries = append(sensors.Batteries, battery)
+	}
+}
+
+func ReadSensors() (*Sensors, error) {
+	sensors := &Sensors{}
+	readHwmon(sensors)
+	readThermalZones(sensors)
+	readBatteries(sensors)
+	return sensors, nil
+}
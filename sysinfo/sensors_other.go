@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package sysinfo
+
+func ReadSensors() (*Sensors, error) {
+	return &Sensors{}, nil
+}
@@ -0,0 +1,50 @@
+package sysinfo
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                 = windows.NewLazySystemDLL("kernel32.dll")
+	procGetSystemPowerStatus = kernel32.NewProc("GetSystemPowerStatus")
+)
+
+type systemPowerStatus struct {
+	acLineStatus        byte
+	batteryFlag         byte
+	batteryLifePercent  byte
+	systemStatusFlag    byte
+	batteryLifeTime     uint32
+	batteryFullLifeTime uint32
+}
+
+func ReadSensors() (*Sensors, error) {
+	sensors := &Sensors{}
+	status := systemPowerStatus{}
+	ret, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return nil, err
+	}
+	//128 means no system battery, 255 means unknown status
+	if status.batteryFlag&128 != 0 || status.batteryFlag == 255 {
+		return sensors, nil
+	}
+	battery := Battery{
+		Name:   "battery",
+		Status: "Discharging",
+		Health: -1,
+	}
+	if status.batteryLifePercent != 255 {
+		battery.Percent = float64(status.batteryLifePercent)
+	}
+	switch {
+	case status.batteryFlag&8 != 0:
+		battery.Status = "Charging"
+	case status.acLineStatus == 1:
+		battery.Status = "Not charging"
+	}
+	sensors.Batteries = append(sensors.Batteries, battery)
+	return sensors, nil
+}
@@ -0,0 +1,27 @@
+package sysinfo
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Memory describes the physical memory of the system in bytes. Available
+// is the amount of memory that can be used by new processes without
+// swapping, which includes memory used by caches that can be reclaimed.
+type Memory struct {
+	Total     uint64
+	Free      uint64
+	Available uint64
+}
+
+// Disk describes the disk usage of a filesystem in bytes. Available is the
+// amount of free space that unprivileged users can use.
+type Disk struct {
+	Total     uint64
+	Free      uint64
+	Available uint64
+}
+
+func unsupported(name string) error {
+	return fmt.Errorf("'sysinfo.%v' is unsupported on %v.", name, runtime.GOOS)
+}
@@ -0,0 +1,110 @@
+package sysinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	vmStatPageSizeRegex = regexp.MustCompile(`page size of (\d+) bytes`)
+	vmStatLineRegex     = regexp.MustCompile(`^(.+):\s+(\d+)\.?$`)
+)
+
+func LoadAverage() ([3]float64, error) {
+	var loads [3]float64
+	//struct loadavg { fixpt_t ldavg[3]; long fscale; }
+	data, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return loads, err
+	}
+	if len(data) < 24 {
+		return loads, fmt.Errorf("unexpected size of vm.loadavg")
+	}
+	scale := float64(binary.LittleEndian.Uint64(data[16:24]))
+	for i := range loads {
+		loads[i] = float64(binary.LittleEndian.Uint32(data[i*4:])) / scale
+	}
+	return loads, nil
+}
+
+func ReadMemory() (Memory, error) {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return Memory{}, err
+	}
+	output, err := exec.Command("vm_stat").Output()
+	if err != nil {
+		return Memory{}, err
+	}
+	pageSize := uint64(os.Getpagesize())
+	if match := vmStatPageSizeRegex.FindSubmatch(output); match != nil {
+		if size, err := strconv.ParseUint(string(match[1]), 10, 64); err == nil {
+			pageSize = size
+		}
+	}
+	pages := make(map[string]uint64)
+	for _, line := range strings.Split(string(output), "\n") {
+		match := vmStatLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if count, err := strconv.ParseUint(match[2], 10, 64); err == nil {
+			pages[match[1]] = count
+		}
+	}
+	free := pages["Pages free"] + pages["Pages speculative"]
+	return Memory{
+		Total:     total,
+		Free:      free * pageSize,
+		Available: (free + pages["Pages inactive"] + pages["Pages purgeable"]) * pageSize,
+	}, nil
+}
+
+func BootTime() (time.Time, error) {
+	bootTime, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(bootTime.Unix()), nil
+}
+
+func Uptime() (time.Duration, error) {
+	bootTime, err := BootTime()
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(bootTime), nil
+}
+
+func ProcessRSS(pid int) (uint64, error) {
+	output, err := exec.Command("ps", "-o", "rss=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, fmt.Errorf("no process with pid %v", pid)
+	}
+	kilobytes, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return kilobytes * 1024, nil
+}
+
+func DiskUsage(path string) (Disk, error) {
+	stat := unix.Statfs_t{}
+	if err := unix.Statfs(path, &stat); err != nil {
+		return Disk{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	blockSize := uint64(stat.Bsize)
+	return Disk{
+		Total:     stat.Blocks * blockSize,
+		Free:      stat.Bfree * blockSize,
+		Available: stat.Bavail * blockSize,
+	}, nil
+}
@@ -0,0 +1,29 @@
+//go:build !linux && !darwin && !windows
+
+package sysinfo
+
+import "time"
+
+func LoadAverage() ([3]float64, error) {
+	return [3]float64{}, unsupported("loadAverage")
+}
+
+func ReadMemory() (Memory, error) {
+	return Memory{}, unsupported("memory")
+}
+
+func Uptime() (time.Duration, error) {
+	return 0, unsupported("uptime")
+}
+
+func BootTime() (time.Time, error) {
+	return time.Time{}, unsupported("bootTime")
+}
+
+func ProcessRSS(pid int) (uint64, error) {
+	return 0, unsupported("rss")
+}
+
+func DiskUsage(path string) (Disk, error) {
+	return Disk{}, unsupported("diskUsage")
+}
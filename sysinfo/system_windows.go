@@ -0,0 +1,112 @@
+package sysinfo
+
+import (
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procGetTickCount64          = kernel32.NewProc("GetTickCount64")
+	procGlobalMemoryStatusEx    = kernel32.NewProc("GlobalMemoryStatusEx")
+	procK32GetProcessMemoryInfo = kernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+type memoryStatusEx struct {
+	length               uint32
+	memoryLoad           uint32
+	totalPhys            uint64
+	availPhys            uint64
+	totalPageFile        uint64
+	availPageFile        uint64
+	totalVirtual         uint64
+	availVirtual         uint64
+	availExtendedVirtual uint64
+}
+
+type processMemoryCounters struct {
+	cb                         uint32
+	pageFaultCount             uint32
+	peakWorkingSetSize         uintptr
+	workingSetSize             uintptr
+	quotaPeakPagedPoolUsage    uintptr
+	quotaPagedPoolUsage        uintptr
+	quotaPeakNonPagedPoolUsage uintptr
+	quotaNonPagedPoolUsage     uintptr
+	pagefileUsage              uintptr
+	peakPagefileUsage          uintptr
+}
+
+func LoadAverage() ([3]float64, error) {
+	return [3]float64{}, unsupported("loadAverage")
+}
+
+func ReadMemory() (Memory, error) {
+	status := memoryStatusEx{}
+	status.length = uint32(unsafe.Sizeof(status))
+	ret, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return Memory{}, err
+	}
+	return Memory{
+		Total:     status.totalPhys,
+		Free:      status.availPhys,
+		Available: status.availPhys,
+	}, nil
+}
+
+func Uptime() (time.Duration, error) {
+	low, high, _ := procGetTickCount64.Call()
+	milliseconds := uint64(low)
+	//On 32-bit systems, the high 32 bits of the result are in a separate register
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		milliseconds |= uint64(high) << 32
+	}
+	return time.Duration(milliseconds) * time.Millisecond, nil
+}
+
+func BootTime() (time.Time, error) {
+	uptime, err := Uptime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-uptime).Truncate(time.Second), nil
+}
+
+func ProcessRSS(pid int) (uint64, error) {
+	handle, err := windows.OpenProcess(
+		windows.PROCESS_QUERY_LIMITED_INFORMATION|windows.PROCESS_VM_READ,
+		false,
+		uint32(pid),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(handle)
+	counters := processMemoryCounters{}
+	counters.cb = uint32(unsafe.Sizeof(counters))
+	ret, _, err := procK32GetProcessMemoryInfo.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&counters)),
+		uintptr(counters.cb),
+	)
+	if ret == 0 {
+		return 0, err
+	}
+	return uint64(counters.workingSetSize), nil
+}
+
+func DiskUsage(path string) (Disk, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Disk{}, err
+	}
+	disk := Disk{}
+	err = windows.GetDiskFreeSpaceEx(pathPtr, &disk.Available, &disk.Total, &disk.Free)
+	if err != nil {
+		return Disk{}, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: path, Err: err}
+	}
+	return disk, nil
+}
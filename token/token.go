@@ -1,6 +1,10 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
 
 type TokenType int
 
@@ -22,6 +26,7 @@ const (
 	DOT
 	DOUBLE_GREATER
 	DOUBLE_LESS
+	DOUBLE_QUESTION
 	DOUBLE_STAR
 	ELLIPSIS
 	MINUS
@@ -29,6 +34,7 @@ const (
 	PIPE
 	PLUS
 	QUESTION
+	QUESTION_DOT
 	SEMICOLON
 	SLASH
 	STAR
@@ -44,6 +50,18 @@ const (
 	LESS
 	LESS_EQUAL
 
+	//Compound assignment operators
+	AMPERSAND_EQUAL
+	CARET_EQUAL
+	DOUBLE_GREATER_EQUAL
+	DOUBLE_LESS_EQUAL
+	MINUS_EQUAL
+	PERCENT_EQUAL
+	PIPE_EQUAL
+	PLUS_EQUAL
+	SLASH_EQUAL
+	STAR_EQUAL
+
 	//Names, strings, and numbers
 	IDENTIFIER
 	STRING
@@ -85,6 +103,9 @@ const (
 	VAR
 	WHILE
 
+	//Comment token, only produced when the scanner keeps comments
+	COMMENT
+
 	//EOF token
 	EOF
 )
@@ -107,6 +128,7 @@ var tokenArr = [...]string{
 	"DOT",
 	"DOUBLE_GREATER",
 	"DOUBLE_LESS",
+	"DOUBLE_QUESTION",
 	"DOUBLE_STAR",
 	"ELLIPSIS",
 	"MINUS",
@@ -114,6 +136,7 @@ var tokenArr = [...]string{
 	"PIPE",
 	"PLUS",
 	"QUESTION",
+	"QUESTION_DOT",
 	"SEMICOLON",
 	"SLASH",
 	"STAR",
@@ -129,6 +152,18 @@ var tokenArr = [...]string{
 	"LESS",
 	"LESS_EQUAL",
 
+	//Compound assignment operators
+	"AMPERSAND_EQUAL",
+	"CARET_EQUAL",
+	"DOUBLE_GREATER_EQUAL",
+	"DOUBLE_LESS_EQUAL",
+	"MINUS_EQUAL",
+	"PERCENT_EQUAL",
+	"PIPE_EQUAL",
+	"PLUS_EQUAL",
+	"SLASH_EQUAL",
+	"STAR_EQUAL",
+
 	//Names, strings, and numbers
 	"IDENTIFIER",
 	"STRING",
@@ -170,16 +205,48 @@ var tokenArr = [...]string{
 	"VAR",
 	"WHILE",
 
+	//Comment token, only produced when the scanner keeps comments
+	"COMMENT",
+
 	//EOF token
 	"EOF",
 }
 
+// The source code that tokens were scanned from, which is kept so that
+// errors can show the line of source code where they occurred
+type Source struct {
+	text  string
+	lines []string
+	once  sync.Once
+}
+
+func NewSource(text string) *Source {
+	return &Source{text: text}
+}
+
+// Returns the specified line of source code, where the first line is 1
+func (s *Source) Line(line int) (string, bool) {
+	s.once.Do(func() {
+		s.lines = strings.Split(s.text, "\n")
+	})
+	if line < 1 || line > len(s.lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(s.lines[line-1], "\r"), true
+}
+
 type Token struct {
 	TokenType
 	Lexeme  string
 	Literal any
 	Line    int
 	Quote   byte
+
+	//Column of the first character of the token, where the first column
+	//is 1, and the source code that the token was scanned from. These are
+	//only set for tokens created by the scanner.
+	Column int
+	Source *Source
 }
 
 func NewToken(tokenType TokenType, lexeme string, literal any, line int, quote byte) *Token {
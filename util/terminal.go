@@ -0,0 +1,14 @@
+//go:build !js
+
+package util
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+func StdinFromTerminal() bool {
+	fd := os.Stdin.Fd()
+	return InteractiveMode && (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd))
+}
@@ -0,0 +1,7 @@
+package util
+
+// In a browser, code is only run in interactive mode when it is typed into
+// the page, which acts as the terminal
+func StdinFromTerminal() bool {
+	return InteractiveMode
+}
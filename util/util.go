@@ -1,19 +1,26 @@
 package util
 
 import (
-	"os"
 	"runtime"
 	"strconv"
-
-	"github.com/mattn/go-isatty"
 )
 
+// Version can be overridden at build time using
+// -ldflags "-X github.com/AlanLuu/lox/util.Version=<version>"
+var Version = "0.1.0"
+
 var (
 	DisableLoxCode  = false
 	InteractiveMode = false
+	SandboxMode     = false
 	UnsafeMode      = false
 )
 
+var (
+	ImportPaths []string
+	ScriptArgs  []string
+)
+
 func CountBraces(s string) (int, int) {
 	var quoteChr rune = 0
 	var prevChr rune = 0
@@ -72,8 +79,3 @@ func IsLinux() bool {
 func IsWindows() bool {
 	return runtime.GOOS == "windows"
 }
-
-func StdinFromTerminal() bool {
-	fd := os.Stdin.Fd()
-	return InteractiveMode && (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd))
-}
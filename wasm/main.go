@@ -0,0 +1,88 @@
+//go:build js && wasm
+
+// Command wasm is this interpreter compiled to WebAssembly, which lets Lox
+// code run in a web browser. It defines the following JavaScript functions
+// on the global object:
+//   - loxEval(source), which runs the specified Lox source code and returns
+//     a promise that resolves to true if the code ran without errors and
+//     false otherwise. The values of expression statements are printed like
+//     in the REPL, and global variables persist between calls
+//   - loxReset(), which replaces the interpreter with a new one, discarding
+//     all global variables that were defined by earlier calls to loxEval
+//
+// Output and errors are written to standard output and standard error,
+// which the page that loads this module can capture by replacing
+// globalThis.fs.writeSync before running it.
+package main
+
+import (
+	"sync"
+	"syscall/js"
+
+	"github.com/AlanLuu/lox/ast"
+	"github.com/AlanLuu/lox/loxerror"
+	"github.com/AlanLuu/lox/scanner"
+	"github.com/AlanLuu/lox/util"
+)
+
+func run(source string, interpreter *ast.Interpreter) error {
+	sc := scanner.NewScanner(source)
+	scanErr := sc.ScanTokens()
+	if scanErr != nil {
+		return scanErr
+	}
+
+	parser := ast.NewParser(sc.Tokens)
+	exprList, parseErr := parser.Parse()
+	defer exprList.Clear()
+	if parseErr != nil {
+		return parseErr
+	}
+
+	resolver := ast.NewResolver(interpreter)
+	resolverErr := resolver.Resolve(exprList)
+	if resolverErr != nil {
+		return resolverErr
+	}
+
+	return interpreter.Interpret(exprList, false)
+}
+
+func main() {
+	util.InteractiveMode = true
+	var mutex sync.Mutex
+	interpreter := ast.NewInterpreter()
+
+	js.Global().Set("loxEval", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return js.Global().Get("Promise").Call("reject",
+				js.Global().Get("TypeError").New("loxEval expects a string"))
+		}
+		source := args[0].String()
+		//Lox code can wait on timers and tasks, which must not happen in
+		//the JavaScript callback itself
+		executor := js.FuncOf(func(this js.Value, promiseArgs []js.Value) any {
+			resolve := promiseArgs[0]
+			go func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				err := run(source, interpreter)
+				if err != nil {
+					loxerror.PrintErrorObject(err)
+				}
+				resolve.Invoke(err == nil)
+			}()
+			return nil
+		})
+		defer executor.Release()
+		return js.Global().Get("Promise").New(executor)
+	}))
+	js.Global().Set("loxReset", js.FuncOf(func(this js.Value, args []js.Value) any {
+		mutex.Lock()
+		defer mutex.Unlock()
+		interpreter = ast.NewInterpreter()
+		return nil
+	}))
+
+	select {}
+}